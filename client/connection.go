@@ -14,6 +14,7 @@ import (
 
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/signing"
 	"github.com/lxc/lxd/shared/simplestreams"
 )
 
@@ -58,6 +59,10 @@ type ConnectionArgs struct {
 	// Caching support for image servers
 	CachePath   string
 	CacheExpiry time.Duration
+
+	// Trusted image signing keys (PEM encoded Ed25519 public keys or armored GPG public keys).
+	// When set, the signatures of the simplestreams index and products are verified.
+	ImageTrustKeys string
 }
 
 // ConnectLXD lets you connect to a remote LXD daemon over HTTPs.
@@ -168,6 +173,58 @@ func ConnectLXDUnix(path string, args *ConnectionArgs) (InstanceServer, error) {
 	return &server, nil
 }
 
+// ConnectLXDSSH lets you connect to a remote LXD daemon over SSH.
+//
+// The URL takes the form ssh://[user@]host[:port][/path/to/unix.socket]. The local ssh client is
+// used to run "lxd sshproxy" on the remote host which forwards the connection to the LXD unix socket
+// (the default one unless a path is provided). A new SSH connection is spawned for every connection
+// to the API, the use of SSH connection sharing (ControlMaster) is recommended.
+func ConnectLXDSSH(sshURL string, args *ConnectionArgs) (InstanceServer, error) {
+	logger.Debugf("Connecting to a remote LXD over SSH")
+
+	// Use empty args if not specified
+	if args == nil {
+		args = &ConnectionArgs{}
+	}
+
+	u, err := url.Parse(sshURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "ssh" {
+		return nil, fmt.Errorf("Invalid SSH URL %q", sshURL)
+	}
+
+	// Initialize the client struct
+	server := ProtocolLXD{
+		httpHost:      "http://ssh.socket",
+		httpProtocol:  "ssh",
+		httpUserAgent: args.UserAgent,
+		chConnected:   make(chan struct{}, 1),
+	}
+
+	// Setup the HTTP client
+	httpClient, err := sshHTTPClient(args.HTTPClient, u)
+	if err != nil {
+		return nil, err
+	}
+	server.http = httpClient
+
+	// Test the connection and seed the server information
+	if !args.SkipGetServer {
+		serverStatus, _, err := server.GetServer()
+		if err != nil {
+			return nil, err
+		}
+
+		// Record the server certificate
+		server.httpCertificate = serverStatus.Environment.Certificate
+	}
+
+	return &server, nil
+}
+
 // ConnectPublicLXD lets you connect to a remote public LXD daemon over HTTPs.
 //
 // Unless the remote server is trusted by the system CA, the remote certificate must be provided (TLSServerCert).
@@ -212,6 +269,16 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 	ssClient := simplestreams.NewClient(url, *httpClient, args.UserAgent)
 	server.ssClient = ssClient
 
+	// Setup signature verification
+	if args.ImageTrustKeys != "" {
+		keyring, err := signing.ParseKeyring(args.ImageTrustKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		ssClient.SetKeyring(keyring)
+	}
+
 	// Setup the cache
 	if args.CachePath != "" {
 		if !shared.PathExists(args.CachePath) {
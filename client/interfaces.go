@@ -187,6 +187,7 @@ type InstanceServer interface {
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
+	WaitInstance(name string, condition string, timeout int) (err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
@@ -220,6 +221,7 @@ type InstanceServer interface {
 	GetNetworks() (networks []api.Network, err error)
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
+	GetNetworkAllocations(allProjects bool) (allocations []api.NetworkAllocation, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
@@ -235,6 +237,31 @@ type InstanceServer interface {
 	RenameNetworkACL(name string, acl api.NetworkACLPost) (err error)
 	DeleteNetworkACL(name string) (err error)
 
+	// Network address set functions ("network_address_set" API extension)
+	GetNetworkAddressSetNames() (names []string, err error)
+	GetNetworkAddressSets() (addressSets []api.NetworkAddressSet, err error)
+	GetNetworkAddressSet(name string) (addressSet *api.NetworkAddressSet, ETag string, err error)
+	CreateNetworkAddressSet(addressSet api.NetworkAddressSetsPost) (err error)
+	UpdateNetworkAddressSet(name string, addressSet api.NetworkAddressSetPut, ETag string) (err error)
+	RenameNetworkAddressSet(name string, addressSet api.NetworkAddressSetPost) (err error)
+	DeleteNetworkAddressSet(name string) (err error)
+
+	// Network load balancer functions ("network_load_balancer" API extension)
+	GetNetworkLoadBalancerAddresses(networkName string) (listenAddresses []string, err error)
+	GetNetworkLoadBalancers(networkName string) (loadBalancers []api.NetworkLoadBalancer, err error)
+	GetNetworkLoadBalancer(networkName string, listenAddress string) (loadBalancer *api.NetworkLoadBalancer, ETag string, err error)
+	CreateNetworkLoadBalancer(networkName string, loadBalancer api.NetworkLoadBalancersPost) (listenAddress string, err error)
+	UpdateNetworkLoadBalancer(networkName string, listenAddress string, loadBalancer api.NetworkLoadBalancerPut, ETag string) (err error)
+	DeleteNetworkLoadBalancer(networkName string, listenAddress string) (err error)
+
+	// Network DHCP reservation functions ("network_reservations" API extension)
+	GetNetworkReservationHwaddrs(networkName string) (hwaddrs []string, err error)
+	GetNetworkReservations(networkName string) (reservations []api.NetworkReservation, err error)
+	GetNetworkReservation(networkName string, hwaddr string) (reservation *api.NetworkReservation, ETag string, err error)
+	CreateNetworkReservation(networkName string, reservation api.NetworkReservationsPost) (err error)
+	UpdateNetworkReservation(networkName string, hwaddr string, reservation api.NetworkReservationPut, ETag string) (err error)
+	DeleteNetworkReservation(networkName string, hwaddr string) (err error)
+
 	// Operation functions
 	GetOperationUUIDs() (uuids []string, err error)
 	GetOperations() (operations []api.Operation, err error)
@@ -444,6 +471,10 @@ type StoragePoolVolumeCopyArgs struct {
 
 	// API extension: storage_api_volume_snapshots
 	VolumeOnly bool
+
+	// API extension: migration_resume
+	// Resume an interrupted copy, reusing what was already transferred
+	Resume bool
 }
 
 // The StoragePoolVolumeMoveArgs struct is used to pass additional options
@@ -491,6 +522,10 @@ type InstanceCopyArgs struct {
 	// API extension: container_incremental_copy
 	// Perform an incremental copy
 	Refresh bool
+
+	// API extension: migration_resume
+	// Resume an interrupted copy, reusing what was already transferred
+	Resume bool
 }
 
 // The InstanceSnapshotCopyArgs struct is used to pass additional options during instance copy.
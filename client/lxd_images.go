@@ -372,6 +372,18 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 		}
 	}
 
+	if image.Signature != "" {
+		if !r.HasExtension("image_signatures") {
+			return nil, fmt.Errorf("The server is missing the required \"image_signatures\" API extension")
+		}
+	}
+
+	if image.Source != nil && image.Source.Type == "build" {
+		if !r.HasExtension("image_builds") {
+			return nil, fmt.Errorf("The server is missing the required \"image_builds\" API extension")
+		}
+	}
+
 	// Send the JSON based request
 	if args == nil {
 		op, _, err := r.queryOperation("POST", "/images", image, "")
@@ -495,6 +507,10 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 		req.Header.Set("X-LXD-properties", imgProps.Encode())
 	}
 
+	if image.Signature != "" {
+		req.Header.Set("X-LXD-signature", image.Signature)
+	}
+
 	// Set the user agent
 	if image.Source != nil && image.Source.Fingerprint != "" && image.Source.Secret != "" && image.Source.Mode == "push" {
 		// Set fingerprint
@@ -658,6 +674,10 @@ func (r *ProtocolLXD) CopyImage(source ImageServer, image api.Image, args *Image
 		imagesPost.Properties = image.Properties
 		imagesPost.Public = args.Public
 
+		if r.HasExtension("image_signatures") {
+			imagesPost.Signature = image.Signature
+		}
+
 		// Receive token from target server. This token is later passed to the source which will use
 		// it, together with the URL and certificate, to connect to the target.
 		tokenOp, err := r.CreateImage(imagesPost, nil)
@@ -739,6 +759,10 @@ func (r *ProtocolLXD) CopyImage(source ImageServer, image api.Image, args *Image
 		imagePost := api.ImagesPost{}
 		imagePost.Public = args.Public
 
+		if r.HasExtension("image_signatures") {
+			imagePost.Signature = image.Signature
+		}
+
 		if args.CopyAliases {
 			imagePost.Aliases = image.Aliases
 			if args.Aliases != nil {
@@ -835,6 +859,12 @@ func (r *ProtocolLXD) CopyImage(source ImageServer, image api.Image, args *Image
 
 // UpdateImage updates the image definition
 func (r *ProtocolLXD) UpdateImage(fingerprint string, image api.ImagePut, ETag string) error {
+	if image.Signature != "" {
+		if !r.HasExtension("image_signatures") {
+			return fmt.Errorf("The server is missing the required \"image_signatures\" API extension")
+		}
+	}
+
 	// Send the request
 	_, _, err := r.query("PUT", fmt.Sprintf("/images/%s", url.PathEscape(fingerprint)), image, ETag)
 	if err != nil {
@@ -425,6 +425,16 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 			}
 		}
 
+		if args.Resume {
+			if !r.HasExtension("migration_resume") {
+				return nil, fmt.Errorf("The target server is missing the required \"migration_resume\" API extension")
+			}
+
+			if !source.HasExtension("migration_resume") {
+				return nil, fmt.Errorf("The source server is missing the required \"migration_resume\" API extension")
+			}
+		}
+
 		// Allow overriding the target name
 		if args.Name != "" {
 			req.Name = args.Name
@@ -434,6 +444,7 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 		req.Source.InstanceOnly = args.InstanceOnly
 		req.Source.ContainerOnly = args.InstanceOnly // For legacy servers.
 		req.Source.Refresh = args.Refresh
+		req.Source.Resume = args.Resume
 	}
 
 	if req.Source.Live {
@@ -1565,6 +1576,29 @@ func (r *ProtocolLXD) UpdateInstanceState(name string, state api.InstanceStatePu
 	return op, nil
 }
 
+// WaitInstance waits for the instance to reach the provided condition (or timeout).
+func (r *ProtocolLXD) WaitInstance(name string, condition string, timeout int) error {
+	if !r.HasExtension("instance_wait") {
+		return fmt.Errorf("The server is missing the required \"instance_wait\" API extension")
+	}
+
+	path, v, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	v.Set("condition", condition)
+	v.Set("timeout", fmt.Sprintf("%d", timeout))
+
+	// Send the request
+	_, _, err = r.query("GET", fmt.Sprintf("%s/%s/wait?%s", path, url.PathEscape(name), v.Encode()), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetInstanceLogfiles returns a list of logfiles for the instance.
 func (r *ProtocolLXD) GetInstanceLogfiles(name string) ([]string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
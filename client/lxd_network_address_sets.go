@@ -0,0 +1,127 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkAddressSetNames returns a list of network address set names.
+func (r *ProtocolLXD) GetNetworkAddressSetNames() ([]string, error) {
+	if !r.HasExtension("network_address_set") {
+		return nil, fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	urls := []string{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", "/network-address-sets", nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	names := []string{}
+	for _, url := range urls {
+		fields := strings.Split(url, "/network-address-sets/")
+		names = append(names, fields[len(fields)-1])
+	}
+
+	return names, nil
+}
+
+// GetNetworkAddressSets returns a list of Network address set structs.
+func (r *ProtocolLXD) GetNetworkAddressSets() ([]api.NetworkAddressSet, error) {
+	if !r.HasExtension("network_address_set") {
+		return nil, fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	addressSets := []api.NetworkAddressSet{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", "/network-address-sets?recursion=1", nil, "", &addressSets)
+	if err != nil {
+		return nil, err
+	}
+
+	return addressSets, nil
+}
+
+// GetNetworkAddressSet returns a Network address set entry for the provided name.
+func (r *ProtocolLXD) GetNetworkAddressSet(name string) (*api.NetworkAddressSet, string, error) {
+	if !r.HasExtension("network_address_set") {
+		return nil, "", fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	addressSet := api.NetworkAddressSet{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/network-address-sets/%s", url.PathEscape(name)), nil, "", &addressSet)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &addressSet, etag, nil
+}
+
+// CreateNetworkAddressSet defines a new network address set using the provided struct.
+func (r *ProtocolLXD) CreateNetworkAddressSet(addressSet api.NetworkAddressSetsPost) error {
+	if !r.HasExtension("network_address_set") {
+		return fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", "/network-address-sets", addressSet, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkAddressSet updates the network address set to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkAddressSet(name string, addressSet api.NetworkAddressSetPut, ETag string) error {
+	if !r.HasExtension("network_address_set") {
+		return fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("PUT", fmt.Sprintf("/network-address-sets/%s", url.PathEscape(name)), addressSet, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameNetworkAddressSet renames an existing network address set entry.
+func (r *ProtocolLXD) RenameNetworkAddressSet(name string, addressSet api.NetworkAddressSetPost) error {
+	if !r.HasExtension("network_address_set") {
+		return fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", fmt.Sprintf("/network-address-sets/%s", url.PathEscape(name)), addressSet, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkAddressSet deletes an existing network address set.
+func (r *ProtocolLXD) DeleteNetworkAddressSet(name string) error {
+	if !r.HasExtension("network_address_set") {
+		return fmt.Errorf(`The server is missing the required "network_address_set" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("DELETE", fmt.Sprintf("/network-address-sets/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package lxd
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkAllocations returns a list of the addresses allocated to instances and networks. If allProjects is
+// true, the allocations of all projects are returned.
+func (r *ProtocolLXD) GetNetworkAllocations(allProjects bool) ([]api.NetworkAllocation, error) {
+	if !r.HasExtension("network_allocations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	allocations := []api.NetworkAllocation{}
+
+	path := "/network-allocations"
+	if allProjects {
+		path += "?all-projects=true"
+	}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", path, nil, "", &allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
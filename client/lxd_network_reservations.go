@@ -0,0 +1,117 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkReservationHwaddrs returns a list of network DHCP reservation hardware addresses.
+func (r *ProtocolLXD) GetNetworkReservationHwaddrs(networkName string) ([]string, error) {
+	if !r.HasExtension("network_reservations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_reservations" API extension`)
+	}
+
+	urls := []string{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/reservations", url.PathEscape(networkName)), nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	hwaddrs := []string{}
+	for _, u := range urls {
+		fields := strings.Split(u, "/reservations/")
+		hwaddr, err := url.PathUnescape(fields[len(fields)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		hwaddrs = append(hwaddrs, hwaddr)
+	}
+
+	return hwaddrs, nil
+}
+
+// GetNetworkReservations returns a list of network DHCP reservation structs.
+func (r *ProtocolLXD) GetNetworkReservations(networkName string) ([]api.NetworkReservation, error) {
+	if !r.HasExtension("network_reservations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_reservations" API extension`)
+	}
+
+	reservations := []api.NetworkReservation{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/reservations?recursion=1", url.PathEscape(networkName)), nil, "", &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetNetworkReservation returns a network DHCP reservation entry for the provided network and hardware address.
+func (r *ProtocolLXD) GetNetworkReservation(networkName string, hwaddr string) (*api.NetworkReservation, string, error) {
+	if !r.HasExtension("network_reservations") {
+		return nil, "", fmt.Errorf(`The server is missing the required "network_reservations" API extension`)
+	}
+
+	reservation := api.NetworkReservation{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/reservations/%s", url.PathEscape(networkName), url.PathEscape(hwaddr)), nil, "", &reservation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &reservation, etag, nil
+}
+
+// CreateNetworkReservation defines a new network DHCP reservation using the provided struct.
+func (r *ProtocolLXD) CreateNetworkReservation(networkName string, reservation api.NetworkReservationsPost) error {
+	if !r.HasExtension("network_reservations") {
+		return fmt.Errorf(`The server is missing the required "network_reservations" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", fmt.Sprintf("/networks/%s/reservations", url.PathEscape(networkName)), reservation, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkReservation updates the network DHCP reservation to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkReservation(networkName string, hwaddr string, reservation api.NetworkReservationPut, ETag string) error {
+	if !r.HasExtension("network_reservations") {
+		return fmt.Errorf(`The server is missing the required "network_reservations" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("PUT", fmt.Sprintf("/networks/%s/reservations/%s", url.PathEscape(networkName), url.PathEscape(hwaddr)), reservation, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkReservation deletes an existing network DHCP reservation.
+func (r *ProtocolLXD) DeleteNetworkReservation(networkName string, hwaddr string) error {
+	if !r.HasExtension("network_reservations") {
+		return fmt.Errorf(`The server is missing the required "network_reservations" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("DELETE", fmt.Sprintf("/networks/%s/reservations/%s", url.PathEscape(networkName), url.PathEscape(hwaddr)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -399,6 +399,16 @@ func (r *ProtocolLXD) CopyStoragePoolVolume(pool string, source InstanceServer,
 		return nil, fmt.Errorf("The target server is missing the required \"storage_api_volume_snapshots\" API extension")
 	}
 
+	if args != nil && args.Resume {
+		if !r.HasExtension("migration_resume") {
+			return nil, fmt.Errorf("The target server is missing the required \"migration_resume\" API extension")
+		}
+
+		if !source.HasExtension("migration_resume") {
+			return nil, fmt.Errorf("The source server is missing the required \"migration_resume\" API extension")
+		}
+	}
+
 	req := api.StorageVolumesPost{
 		Name: args.Name,
 		Type: volume.Type,
@@ -407,6 +417,7 @@ func (r *ProtocolLXD) CopyStoragePoolVolume(pool string, source InstanceServer,
 			Type:       "copy",
 			Pool:       sourcePool,
 			VolumeOnly: args.VolumeOnly,
+			Resume:     args.Resume,
 		},
 	}
 	req.Config = volume.Config
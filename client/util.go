@@ -3,11 +3,14 @@ package lxd
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/lxc/lxd/shared"
 )
@@ -138,6 +141,151 @@ func unixHTTPClient(client *http.Client, path string) (*http.Client, error) {
 	return client, nil
 }
 
+// sshCommandArgs returns the arguments of the ssh command running the LXD proxy on the host of the SSH URL,
+// along with the destination (host or user@host) it connects to.
+func sshCommandArgs(sshURL *url.URL) ([]string, string, error) {
+	host := sshURL.Hostname()
+	if host == "" {
+		return nil, "", fmt.Errorf("Missing host in SSH URL %q", sshURL.String())
+	}
+
+	// Don't let the destination be interpreted as an option by ssh.
+	if strings.HasPrefix(host, "-") {
+		return nil, "", fmt.Errorf("Invalid host in SSH URL %q", sshURL.String())
+	}
+
+	if sshURL.User != nil && sshURL.User.Username() != "" {
+		if strings.HasPrefix(sshURL.User.Username(), "-") {
+			return nil, "", fmt.Errorf("Invalid user in SSH URL %q", sshURL.String())
+		}
+
+		host = fmt.Sprintf("%s@%s", sshURL.User.Username(), host)
+	}
+
+	sshArgs := []string{"-T", "-e", "none"}
+	if sshURL.Port() != "" {
+		sshArgs = append(sshArgs, "-p", sshURL.Port())
+	}
+
+	remoteCmd := "lxd sshproxy"
+	if sshURL.Path != "" && sshURL.Path != "/" {
+		remoteCmd = fmt.Sprintf("%s '%s'", remoteCmd, strings.Replace(sshURL.Path, "'", `'\''`, -1))
+	}
+
+	sshArgs = append(sshArgs, "--", host, remoteCmd)
+
+	return sshArgs, host, nil
+}
+
+func sshHTTPClient(client *http.Client, sshURL *url.URL) (*http.Client, error) {
+	// Build the SSH command running the LXD proxy on the remote host.
+	sshArgs, host, err := sshCommandArgs(sshURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup a dialer spawning a new SSH connection for every connection.
+	sshDial := func(network, addr string) (net.Conn, error) {
+		cmd := exec.Command("ssh", sshArgs...)
+		cmd.Stderr = os.Stderr
+
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+
+		err = cmd.Start()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to start SSH: %v", err)
+		}
+
+		return &sshConn{cmd: cmd, stdin: stdin, stdout: stdout, host: host}, nil
+	}
+
+	// Define the http transport
+	transport := &http.Transport{
+		Dial: sshDial,
+	}
+
+	// Define the http client
+	if client == nil {
+		client = &http.Client{}
+	}
+	client.Transport = transport
+
+	// Setup redirect policy
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// Replicate the headers
+		req.Header = via[len(via)-1].Header
+
+		return nil
+	}
+
+	return client, nil
+}
+
+// sshConn is a net.Conn backed by the standard input and output of an SSH process.
+type sshConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	host   string
+}
+
+func (c *sshConn) Read(b []byte) (int, error) {
+	return c.stdout.Read(b)
+}
+
+func (c *sshConn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+func (c *sshConn) Close() error {
+	c.stdin.Close()
+
+	// Don't wait for the remote side to notice the connection went away.
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+
+	return nil
+}
+
+func (c *sshConn) LocalAddr() net.Addr {
+	return sshAddr("local")
+}
+
+func (c *sshConn) RemoteAddr() net.Addr {
+	return sshAddr(c.host)
+}
+
+// Deadlines aren't supported on the SSH process pipes.
+func (c *sshConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *sshConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *sshConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type sshAddr string
+
+func (a sshAddr) Network() string {
+	return "ssh"
+}
+
+func (a sshAddr) String() string {
+	return string(a)
+}
+
 // remoteOperationResult used for storing the error that occurred for a particular remote URL.
 type remoteOperationResult struct {
 	URL   string
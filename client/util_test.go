@@ -0,0 +1,58 @@
+package lxd
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHCommandArgs(t *testing.T) {
+	tests := []struct {
+		url  string
+		args []string
+		host string
+	}{
+		{
+			url:  "ssh://server",
+			args: []string{"-T", "-e", "none", "--", "server", "lxd sshproxy"},
+			host: "server",
+		},
+		{
+			url:  "ssh://admin@server:2222",
+			args: []string{"-T", "-e", "none", "-p", "2222", "--", "admin@server", "lxd sshproxy"},
+			host: "admin@server",
+		},
+		{
+			url:  "ssh://server/var/snap/lxd/common/lxd/unix.socket",
+			args: []string{"-T", "-e", "none", "--", "server", "lxd sshproxy '/var/snap/lxd/common/lxd/unix.socket'"},
+			host: "server",
+		},
+		{
+			url:  "ssh://server/tmp/it's.socket",
+			args: []string{"-T", "-e", "none", "--", "server", `lxd sshproxy '/tmp/it'\''s.socket'`},
+			host: "server",
+		},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		require.NoError(t, err)
+
+		args, host, err := sshCommandArgs(u)
+		require.NoError(t, err, test.url)
+		assert.Equal(t, test.args, args, test.url)
+		assert.Equal(t, test.host, host, test.url)
+	}
+}
+
+func TestSSHCommandArgs_Invalid(t *testing.T) {
+	for _, sshURL := range []string{"ssh:///unix.socket", "ssh://-oProxyCommand=id", "ssh://-oProxyCommand@server"} {
+		u, err := url.Parse(sshURL)
+		require.NoError(t, err)
+
+		_, _, err = sshCommandArgs(u)
+		assert.Error(t, err, sshURL)
+	}
+}
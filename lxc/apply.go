@@ -0,0 +1,867 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+// applyManifest is the desired state of a LXD server.
+type applyManifest struct {
+	Projects       []api.ProjectsPost     `yaml:"projects"`
+	StoragePools   []api.StoragePoolsPost `yaml:"storage_pools"`
+	NetworkACLs    []applyNetworkACL      `yaml:"network_acls"`
+	Networks       []applyNetwork         `yaml:"networks"`
+	StorageVolumes []applyStorageVolume   `yaml:"storage_volumes"`
+	Profiles       []applyProfile         `yaml:"profiles"`
+	Instances      []applyInstance        `yaml:"instances"`
+}
+
+type applyNetworkACL struct {
+	api.NetworkACLsPost `yaml:",inline"`
+	Project             string `yaml:"project"`
+}
+
+type applyNetwork struct {
+	api.NetworksPost `yaml:",inline"`
+	Project          string `yaml:"project"`
+}
+
+type applyStorageVolume struct {
+	api.StorageVolumesPost `yaml:",inline"`
+	Pool                   string `yaml:"pool"`
+	Project                string `yaml:"project"`
+}
+
+type applyProfile struct {
+	api.ProfilesPost `yaml:",inline"`
+	Project          string `yaml:"project"`
+}
+
+type applyInstance struct {
+	api.InstancesPost `yaml:",inline"`
+	Project           string `yaml:"project"`
+}
+
+// applyState is the current state of a LXD server, keyed the same way as the changes of the plan.
+type applyState struct {
+	projects  map[string]api.Project
+	pools     map[string]api.StoragePool
+	acls      map[string]api.NetworkACL
+	networks  map[string]api.Network
+	volumes   map[string]api.StorageVolume
+	profiles  map[string]api.Profile
+	instances map[string]api.Instance
+}
+
+// applyChange is a single change of the plan needed to reach the desired state.
+type applyChange struct {
+	action string
+	kind   string
+	key    string
+	run    func(d lxd.InstanceServer) error
+}
+
+// Entity kinds in the order they must be created in (and the reverse of the order they must be deleted in).
+var applyKinds = []string{"project", "storage pool", "network ACL", "network", "storage volume", "profile", "instance"}
+
+type cmdApply struct {
+	global *cmdGlobal
+
+	flagFile   string
+	flagPrune  bool
+	flagDryRun bool
+}
+
+func (c *cmdApply) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("apply", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Apply a desired state to a server")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Apply a desired state to a server
+
+The manifest lists the projects, storage pools, network ACLs, networks, custom storage volumes,
+profiles and instances the server should have. The changes needed to reach that state are printed
+and then applied.
+
+Entities that exist on the server but aren't in the manifest are only deleted when --prune is passed.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc apply -f manifest.yaml
+    Create and update the entities listed in manifest.yaml.
+
+lxc apply -f manifest.yaml --prune --dry-run
+    Show the changes (including deletions) needed for the server to match manifest.yaml.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFile, "file", "f", "", i18n.G("Manifest file to apply (- for stdin)")+"``")
+	cmd.Flags().BoolVar(&c.flagPrune, "prune", false, i18n.G("Delete the entities that aren't in the manifest"))
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes that would be made"))
+
+	return cmd
+}
+
+func (c *cmdApply) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagFile == "" {
+		return fmt.Errorf(i18n.G("A manifest file must be specified with --file"))
+	}
+
+	if c.global.flagProject != "" {
+		return fmt.Errorf(i18n.G("--project cannot be used with the apply command"))
+	}
+
+	// Parse remote
+	remote := conf.DefaultRemote
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Read the manifest
+	var contents []byte
+	if c.flagFile == "-" {
+		contents, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		contents, err = ioutil.ReadFile(c.flagFile)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	manifest := applyManifest{}
+	err = yaml.UnmarshalStrict(contents, &manifest)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed parsing manifest: %v"), err)
+	}
+
+	err = manifest.normalize()
+	if err != nil {
+		return err
+	}
+
+	// Compute the plan
+	state, err := applyLoadState(resource.server, &manifest)
+	if err != nil {
+		return err
+	}
+
+	changes, err := applyPlan(&manifest, state, c.flagPrune)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		if !c.global.flagQuiet {
+			fmt.Println(i18n.G("The server already matches the manifest"))
+		}
+
+		return nil
+	}
+
+	symbols := map[string]string{"create": "+", "update": "~", "delete": "-"}
+	for _, change := range changes {
+		fmt.Printf("%s %s %s\n", symbols[change.action], change.kind, change.key)
+	}
+
+	if c.flagDryRun {
+		return nil
+	}
+
+	// Apply the plan
+	for _, change := range changes {
+		err = change.run(resource.server)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed to %s %s %s: %v"), change.action, change.kind, change.key, err)
+		}
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Applied %d changes")+"\n", len(changes))
+	}
+
+	return nil
+}
+
+// normalize fills in the defaults of the manifest and checks it doesn't list the same entity twice.
+func (m *applyManifest) normalize() error {
+	seen := map[string]bool{}
+	check := func(kind string, key string) error {
+		if seen[kind+" "+key] {
+			return fmt.Errorf(i18n.G("The manifest lists %s %s more than once"), kind, key)
+		}
+
+		seen[kind+" "+key] = true
+		return nil
+	}
+
+	project := func(name string) string {
+		if name == "" {
+			return "default"
+		}
+
+		return name
+	}
+
+	for _, p := range m.Projects {
+		err := check("project", p.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range m.StoragePools {
+		err := check("storage pool", p.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range m.NetworkACLs {
+		m.NetworkACLs[i].Project = project(m.NetworkACLs[i].Project)
+		err := check("network ACL", applyKey(m.NetworkACLs[i].Project, m.NetworkACLs[i].Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range m.Networks {
+		m.Networks[i].Project = project(m.Networks[i].Project)
+		err := check("network", applyKey(m.Networks[i].Project, m.Networks[i].Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range m.StorageVolumes {
+		v := &m.StorageVolumes[i]
+		v.Project = project(v.Project)
+		if v.Type == "" {
+			v.Type = "custom"
+		}
+
+		if v.Type != "custom" {
+			return fmt.Errorf(i18n.G("Only custom storage volumes can be listed in the manifest"))
+		}
+
+		if v.Pool == "" {
+			return fmt.Errorf(i18n.G("Storage volume %q has no pool"), v.Name)
+		}
+
+		err := check("storage volume", applyKey(v.Project, v.Pool, v.Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range m.Profiles {
+		m.Profiles[i].Project = project(m.Profiles[i].Project)
+		err := check("profile", applyKey(m.Profiles[i].Project, m.Profiles[i].Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range m.Instances {
+		inst := &m.Instances[i]
+		inst.Project = project(inst.Project)
+		if inst.Profiles == nil {
+			inst.Profiles = []string{"default"}
+		}
+
+		if inst.Source.Type == "" {
+			inst.Source.Type = "none"
+		}
+
+		err := check("instance", applyKey(inst.Project, inst.Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// featureProject returns the project the entities of a feature (e.g. "networks") of the project belong to.
+// Projects that don't have the feature enabled use the entities of the default project.
+func (m *applyManifest) featureProject(state *applyState, projectName string, feature string) string {
+	if projectName == "default" {
+		return projectName
+	}
+
+	for _, p := range m.Projects {
+		if p.Name == projectName {
+			if shared.IsTrue(p.Config[fmt.Sprintf("features.%s", feature)]) {
+				return projectName
+			}
+
+			return "default"
+		}
+	}
+
+	p, ok := state.projects[projectName]
+	if ok && shared.IsTrue(p.Config[fmt.Sprintf("features.%s", feature)]) {
+		return projectName
+	}
+
+	return "default"
+}
+
+// applyKey returns the key of an entity from its project and name.
+func applyKey(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// applyLoadState returns the current state of the server for the projects it has and those in the manifest.
+func applyLoadState(d lxd.InstanceServer, manifest *applyManifest) (*applyState, error) {
+	state := &applyState{
+		projects:  map[string]api.Project{},
+		pools:     map[string]api.StoragePool{},
+		acls:      map[string]api.NetworkACL{},
+		networks:  map[string]api.Network{},
+		volumes:   map[string]api.StorageVolume{},
+		profiles:  map[string]api.Profile{},
+		instances: map[string]api.Instance{},
+	}
+
+	projects, err := d.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range projects {
+		state.projects[p.Name] = p
+	}
+
+	pools, err := d.GetStoragePools()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range pools {
+		state.pools[p.Name] = p
+	}
+
+	for _, p := range projects {
+		pd := d.UseProject(p.Name)
+
+		// Entities of features the project doesn't have are those of the default project.
+		if manifest.featureProject(state, p.Name, "networks") == p.Name {
+			acls, err := pd.GetNetworkACLs()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, acl := range acls {
+				state.acls[applyKey(p.Name, acl.Name)] = acl
+			}
+
+			networks, err := pd.GetNetworks()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, network := range networks {
+				if !network.Managed {
+					continue
+				}
+
+				state.networks[applyKey(p.Name, network.Name)] = network
+			}
+		}
+
+		if manifest.featureProject(state, p.Name, "storage.volumes") == p.Name {
+			for _, pool := range pools {
+				volumes, err := pd.GetStoragePoolVolumes(pool.Name)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, volume := range volumes {
+					if volume.Type != "custom" || strings.Contains(volume.Name, "/") {
+						continue
+					}
+
+					state.volumes[applyKey(p.Name, pool.Name, volume.Name)] = volume
+				}
+			}
+		}
+
+		if manifest.featureProject(state, p.Name, "profiles") == p.Name {
+			profiles, err := pd.GetProfiles()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, profile := range profiles {
+				state.profiles[applyKey(p.Name, profile.Name)] = profile
+			}
+		}
+
+		instances, err := pd.GetInstances(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inst := range instances {
+			state.instances[applyKey(p.Name, inst.Name)] = inst
+		}
+	}
+
+	return state, nil
+}
+
+// applyConfigEqual returns whether the current config of an entity of the given kind matches the desired one.
+// Keys that are only in the current config are ignored if the server may have filled them in itself (see
+// applyConfigKeyGenerated), otherwise they must match too.
+func applyConfigEqual(kind string, current map[string]string, desired map[string]string) bool {
+	for k, v := range desired {
+		if !applyConfigValueEqual(kind, k, current[k], v) {
+			return false
+		}
+	}
+
+	for k := range current {
+		if applyConfigKeyGenerated(kind, k) {
+			continue
+		}
+
+		_, ok := desired[k]
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyConfigValueEqual returns whether the current value of a config key matches the desired one.
+// Network addresses set to "auto" are replaced by the generated subnet, which then counts as a match.
+func applyConfigValueEqual(kind string, key string, current string, desired string) bool {
+	if kind == "network" && desired == "auto" && shared.StringInSlice(key, []string{"ipv4.address", "ipv6.address"}) {
+		return current != "" && current != "none"
+	}
+
+	return current == desired
+}
+
+// applyConfigKeyInternal returns whether the config key is set by LXD rather than the user.
+func applyConfigKeyInternal(key string) bool {
+	return strings.HasPrefix(key, "volatile.") || strings.HasPrefix(key, "image.")
+}
+
+// applyConfigKeyGenerated returns whether the config key of an entity of the given kind may be filled in by the
+// server when the user doesn't set it. Such keys are kept as they are unless the manifest sets them.
+func applyConfigKeyGenerated(kind string, key string) bool {
+	if applyConfigKeyInternal(key) {
+		return true
+	}
+
+	switch kind {
+	case "storage pool", "storage volume":
+		// The storage drivers fill in most of their config.
+		return true
+	case "project":
+		return strings.HasPrefix(key, "features.")
+	case "network":
+		return shared.StringInSlice(key, []string{"ipv4.address", "ipv4.nat", "ipv6.address", "ipv6.nat"})
+	}
+
+	return false
+}
+
+// applyConfigMerge returns the config to update an entity of the given kind with.
+// The desired config is applied on top of the keys of the current config that the server may have filled in.
+func applyConfigMerge(kind string, current map[string]string, desired map[string]string) map[string]string {
+	config := map[string]string{}
+	for k, v := range current {
+		if applyConfigKeyGenerated(kind, k) {
+			config[k] = v
+		}
+	}
+
+	for k, v := range desired {
+		// Don't regenerate addresses which were already generated.
+		if applyConfigValueEqual(kind, k, current[k], v) {
+			v = current[k]
+		}
+
+		config[k] = v
+	}
+
+	return config
+}
+
+// applyEqual returns whether two values are equal, treating nil and empty maps and slices as equal.
+func applyEqual(a interface{}, b interface{}) bool {
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+
+	if (va.Kind() == reflect.Map || va.Kind() == reflect.Slice) && va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// applyPlan returns the changes needed for the server to reach the desired state of the manifest.
+// Deletions are only planned if prune is true. The changes are ordered so that entities are created before the
+// entities using them, and deleted after them.
+func applyPlan(manifest *applyManifest, state *applyState, prune bool) ([]applyChange, error) {
+	changes := map[string][]applyChange{}
+	deletes := map[string][]applyChange{}
+
+	add := func(action string, kind string, key string, run func(d lxd.InstanceServer) error) {
+		change := applyChange{action: action, kind: kind, key: key, run: run}
+		if action == "delete" {
+			deletes[kind] = append(deletes[kind], change)
+		} else {
+			changes[kind] = append(changes[kind], change)
+		}
+	}
+
+	// Projects.
+	desiredProjects := map[string]bool{}
+	for _, p := range manifest.Projects {
+		p := p
+		desiredProjects[p.Name] = true
+
+		current, ok := state.projects[p.Name]
+		if !ok {
+			add("create", "project", p.Name, func(d lxd.InstanceServer) error {
+				return d.CreateProject(p)
+			})
+
+			continue
+		}
+
+		if !applyConfigEqual("project", current.Config, p.Config) || current.Description != p.Description {
+			put := api.ProjectPut{
+				Config:      applyConfigMerge("project", current.Config, p.Config),
+				Description: p.Description,
+			}
+
+			add("update", "project", p.Name, func(d lxd.InstanceServer) error {
+				return d.UpdateProject(p.Name, put, "")
+			})
+		}
+	}
+
+	for name := range state.projects {
+		name := name
+		if name != "default" && !desiredProjects[name] {
+			add("delete", "project", name, func(d lxd.InstanceServer) error {
+				return d.DeleteProject(name)
+			})
+		}
+	}
+
+	// Storage pools.
+	desiredPools := map[string]bool{}
+	for _, p := range manifest.StoragePools {
+		p := p
+		desiredPools[p.Name] = true
+
+		current, ok := state.pools[p.Name]
+		if !ok {
+			add("create", "storage pool", p.Name, func(d lxd.InstanceServer) error {
+				return d.CreateStoragePool(p)
+			})
+
+			continue
+		}
+
+		if p.Driver != "" && p.Driver != current.Driver {
+			return nil, fmt.Errorf(i18n.G("Storage pool %q uses driver %q which can't be changed to %q"), p.Name, current.Driver, p.Driver)
+		}
+
+		if !applyConfigEqual("storage pool", current.Config, p.Config) || current.Description != p.Description {
+			put := api.StoragePoolPut{
+				Config:      applyConfigMerge("storage pool", current.Config, p.Config),
+				Description: p.Description,
+			}
+
+			add("update", "storage pool", p.Name, func(d lxd.InstanceServer) error {
+				return d.UpdateStoragePool(p.Name, put, "")
+			})
+		}
+	}
+
+	for name := range state.pools {
+		name := name
+		if !desiredPools[name] {
+			add("delete", "storage pool", name, func(d lxd.InstanceServer) error {
+				return d.DeleteStoragePool(name)
+			})
+		}
+	}
+
+	// Network ACLs.
+	desiredACLs := map[string]bool{}
+	for _, acl := range manifest.NetworkACLs {
+		acl := acl
+		project := manifest.featureProject(state, acl.Project, "networks")
+		key := applyKey(project, acl.Name)
+		desiredACLs[key] = true
+
+		current, ok := state.acls[key]
+		if !ok {
+			add("create", "network ACL", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).CreateNetworkACL(acl.NetworkACLsPost)
+			})
+
+			continue
+		}
+
+		if !applyConfigEqual("network ACL", current.Config, acl.Config) || current.Description != acl.Description || !applyEqual(current.Ingress, acl.Ingress) || !applyEqual(current.Egress, acl.Egress) {
+			add("update", "network ACL", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).UpdateNetworkACL(acl.Name, acl.NetworkACLPut, "")
+			})
+		}
+	}
+
+	for key, acl := range state.acls {
+		key := key
+		name := acl.Name
+		if !desiredACLs[key] {
+			add("delete", "network ACL", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(strings.SplitN(key, "/", 2)[0]).DeleteNetworkACL(name)
+			})
+		}
+	}
+
+	// Networks.
+	desiredNetworks := map[string]bool{}
+	for _, network := range manifest.Networks {
+		network := network
+		project := manifest.featureProject(state, network.Project, "networks")
+		key := applyKey(project, network.Name)
+		desiredNetworks[key] = true
+
+		current, ok := state.networks[key]
+		if !ok {
+			add("create", "network", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).CreateNetwork(network.NetworksPost)
+			})
+
+			continue
+		}
+
+		if network.Type != "" && network.Type != current.Type {
+			return nil, fmt.Errorf(i18n.G("Network %q is of type %q which can't be changed to %q"), key, current.Type, network.Type)
+		}
+
+		if !applyConfigEqual("network", current.Config, network.Config) || current.Description != network.Description {
+			put := api.NetworkPut{
+				Config:      applyConfigMerge("network", current.Config, network.Config),
+				Description: network.Description,
+			}
+
+			add("update", "network", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).UpdateNetwork(network.Name, put, "")
+			})
+		}
+	}
+
+	for key, network := range state.networks {
+		key := key
+		name := network.Name
+		if !desiredNetworks[key] {
+			add("delete", "network", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(strings.SplitN(key, "/", 2)[0]).DeleteNetwork(name)
+			})
+		}
+	}
+
+	// Storage volumes.
+	desiredVolumes := map[string]bool{}
+	for _, volume := range manifest.StorageVolumes {
+		volume := volume
+		project := manifest.featureProject(state, volume.Project, "storage.volumes")
+		key := applyKey(project, volume.Pool, volume.Name)
+		desiredVolumes[key] = true
+
+		current, ok := state.volumes[key]
+		if !ok {
+			add("create", "storage volume", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).CreateStoragePoolVolume(volume.Pool, volume.StorageVolumesPost)
+			})
+
+			continue
+		}
+
+		if !applyConfigEqual("storage volume", current.Config, volume.Config) || current.Description != volume.Description {
+			put := api.StorageVolumePut{
+				Config:      applyConfigMerge("storage volume", current.Config, volume.Config),
+				Description: volume.Description,
+			}
+
+			add("update", "storage volume", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).UpdateStoragePoolVolume(volume.Pool, "custom", volume.Name, put, "")
+			})
+		}
+	}
+
+	for key, volume := range state.volumes {
+		key := key
+		name := volume.Name
+		if !desiredVolumes[key] {
+			fields := strings.SplitN(key, "/", 3)
+			add("delete", "storage volume", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(fields[0]).DeleteStoragePoolVolume(fields[1], "custom", name)
+			})
+		}
+	}
+
+	// Profiles.
+	desiredProfiles := map[string]bool{}
+	for _, profile := range manifest.Profiles {
+		profile := profile
+		project := manifest.featureProject(state, profile.Project, "profiles")
+		key := applyKey(project, profile.Name)
+		desiredProfiles[key] = true
+
+		current, ok := state.profiles[key]
+		if !ok && profile.Name != "default" {
+			add("create", "profile", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).CreateProfile(profile.ProfilesPost)
+			})
+
+			continue
+		}
+
+		// The default profile of new projects is created along with the project.
+		if !ok || !applyConfigEqual("profile", current.Config, profile.Config) || current.Description != profile.Description || !applyEqual(current.Devices, profile.Devices) {
+			add("update", "profile", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(project).UpdateProfile(profile.Name, profile.ProfilePut, "")
+			})
+		}
+	}
+
+	for key, profile := range state.profiles {
+		key := key
+		name := profile.Name
+		if name != "default" && !desiredProfiles[key] {
+			add("delete", "profile", key, func(d lxd.InstanceServer) error {
+				return d.UseProject(strings.SplitN(key, "/", 2)[0]).DeleteProfile(name)
+			})
+		}
+	}
+
+	// Instances.
+	desiredInstances := map[string]bool{}
+	for _, inst := range manifest.Instances {
+		inst := inst
+		key := applyKey(inst.Project, inst.Name)
+		desiredInstances[key] = true
+
+		current, ok := state.instances[key]
+		if !ok {
+			add("create", "instance", key, func(d lxd.InstanceServer) error {
+				op, err := d.UseProject(inst.Project).CreateInstance(inst.InstancesPost)
+				if err != nil {
+					return err
+				}
+
+				return op.Wait()
+			})
+
+			continue
+		}
+
+		if inst.Type != "" && inst.Type != api.InstanceType(current.Type) {
+			return nil, fmt.Errorf(i18n.G("Instance %q is of type %q which can't be changed to %q"), key, current.Type, inst.Type)
+		}
+
+		if !applyConfigEqual("instance", current.Config, inst.Config) || current.Description != inst.Description || current.Ephemeral != inst.Ephemeral || !applyEqual(current.Devices, inst.Devices) || !applyEqual(current.Profiles, inst.Profiles) {
+			put := current.Writable()
+			put.Config = applyConfigMerge("instance", current.Config, inst.Config)
+			put.Devices = inst.Devices
+			put.Profiles = inst.Profiles
+			put.Ephemeral = inst.Ephemeral
+			put.Description = inst.Description
+
+			add("update", "instance", key, func(d lxd.InstanceServer) error {
+				op, err := d.UseProject(inst.Project).UpdateInstance(inst.Name, put, "")
+				if err != nil {
+					return err
+				}
+
+				return op.Wait()
+			})
+		}
+	}
+
+	for key, inst := range state.instances {
+		key := key
+		inst := inst
+		if !desiredInstances[key] {
+			project := strings.SplitN(key, "/", 2)[0]
+			add("delete", "instance", key, func(d lxd.InstanceServer) error {
+				d = d.UseProject(project)
+
+				// Running instances are stopped first, as deleting them was explicitly requested.
+				if inst.StatusCode != 0 && inst.StatusCode != api.Stopped {
+					op, err := d.UpdateInstanceState(inst.Name, api.InstanceStatePut{Action: "stop", Timeout: -1, Force: true}, "")
+					if err != nil {
+						return err
+					}
+
+					err = op.Wait()
+					if err != nil {
+						return err
+					}
+				}
+
+				op, err := d.DeleteInstance(inst.Name)
+				if err != nil {
+					return err
+				}
+
+				return op.Wait()
+			})
+		}
+	}
+
+	// Order the changes, sorting by key within each kind so that the plan is stable.
+	plan := []applyChange{}
+	for _, kind := range applyKinds {
+		sort.SliceStable(changes[kind], func(i, j int) bool { return changes[kind][i].key < changes[kind][j].key })
+		plan = append(plan, changes[kind]...)
+	}
+
+	if prune {
+		for i := len(applyKinds) - 1; i >= 0; i-- {
+			kind := applyKinds[i]
+			sort.SliceStable(deletes[kind], func(i, j int) bool { return deletes[kind][i].key < deletes[kind][j].key })
+			plan = append(plan, deletes[kind]...)
+		}
+	}
+
+	return plan, nil
+}
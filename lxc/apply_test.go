@@ -0,0 +1,245 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v2"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/shared/api"
+)
+
+type applyTestSuite struct {
+	suite.Suite
+}
+
+func TestApplyTestSuite(t *testing.T) {
+	suite.Run(t, new(applyTestSuite))
+}
+
+// applyTestState returns a server state with a project, a network and a running instance.
+func applyTestState() *applyState {
+	state := &applyState{
+		projects: map[string]api.Project{
+			"default": {Name: "default"},
+			"old":     {Name: "old"},
+		},
+		pools:    map[string]api.StoragePool{},
+		acls:     map[string]api.NetworkACL{},
+		networks: map[string]api.Network{},
+		volumes:  map[string]api.StorageVolume{},
+		profiles: map[string]api.Profile{
+			"default/default": {Name: "default"},
+		},
+		instances: map[string]api.Instance{},
+	}
+
+	state.networks["default/lxdbr0"] = api.Network{
+		Name:       "lxdbr0",
+		Type:       "bridge",
+		Managed:    true,
+		NetworkPut: api.NetworkPut{Config: map[string]string{"ipv4.address": "10.0.0.1/24", "ipv4.nat": "true"}},
+	}
+
+	state.instances["default/c1"] = api.Instance{
+		Name:       "c1",
+		Type:       "container",
+		StatusCode: api.Running,
+		InstancePut: api.InstancePut{
+			Config:   map[string]string{"limits.cpu": "1", "volatile.eth0.hwaddr": "00:16:3e:00:00:01"},
+			Profiles: []string{"default"},
+		},
+	}
+
+	return state
+}
+
+func (s *applyTestSuite) plan(manifestYAML string, prune bool) []string {
+	manifest := applyManifest{}
+	s.Require().NoError(yaml.UnmarshalStrict([]byte(manifestYAML), &manifest))
+	s.Require().NoError(manifest.normalize())
+
+	changes, err := applyPlan(&manifest, applyTestState(), prune)
+	s.Require().NoError(err)
+
+	plan := []string{}
+	for _, change := range changes {
+		plan = append(plan, change.action+" "+change.kind+" "+change.key)
+	}
+
+	return plan
+}
+
+// Entities that already match the manifest aren't changed, and volatile keys are ignored.
+func (s *applyTestSuite) Test_applyPlan_noChanges() {
+	plan := s.plan(`
+networks:
+- name: lxdbr0
+  type: bridge
+  config:
+    ipv4.address: 10.0.0.1/24
+    ipv4.nat: "true"
+instances:
+- name: c1
+  config:
+    limits.cpu: "1"
+`, false)
+
+	s.Empty(plan)
+}
+
+// Changes are ordered by dependency and deletions are only planned when pruning.
+func (s *applyTestSuite) Test_applyPlan_order() {
+	manifest := `
+projects:
+- name: web
+  config:
+    features.profiles: "true"
+profiles:
+- name: web
+  project: web
+networks:
+- name: lxdbr0
+  type: bridge
+  config:
+    ipv4.address: 10.0.1.1/24
+instances:
+- name: c1
+  config:
+    limits.cpu: "2"
+- name: c2
+  project: web
+  profiles: [web]
+`
+
+	s.Equal([]string{
+		"create project web",
+		"update network default/lxdbr0",
+		"create profile web/web",
+		"update instance default/c1",
+		"create instance web/c2",
+	}, s.plan(manifest, false))
+
+	s.Equal([]string{
+		"create project web",
+		"update network default/lxdbr0",
+		"create profile web/web",
+		"update instance default/c1",
+		"create instance web/c2",
+		"delete project old",
+	}, s.plan(manifest, true))
+}
+
+// Pruning an empty manifest deletes everything but the default project and profile.
+func (s *applyTestSuite) Test_applyPlan_prune() {
+	s.Equal([]string{
+		"delete instance default/c1",
+		"delete network default/lxdbr0",
+		"delete project old",
+	}, s.plan("", true))
+}
+
+// The same entity can't be listed twice.
+func (s *applyTestSuite) Test_applyManifest_duplicate() {
+	manifest := applyManifest{}
+	s.Require().NoError(yaml.UnmarshalStrict([]byte(`
+instances:
+- name: c1
+- name: c1
+  project: default
+`), &manifest))
+
+	s.Error(manifest.normalize())
+}
+
+// applyTestServer is a fake server which fills in config keys on creation the way LXD does.
+type applyTestServer struct {
+	lxd.InstanceServer
+
+	project string
+	state   *applyState
+}
+
+func (d *applyTestServer) UseProject(name string) lxd.InstanceServer {
+	return &applyTestServer{project: name, state: d.state}
+}
+
+func (d *applyTestServer) CreateProject(p api.ProjectsPost) error {
+	config := map[string]string{"features.images": "true", "features.profiles": "true", "features.storage.volumes": "true", "features.networks": "false"}
+	for k, v := range p.Config {
+		config[k] = v
+	}
+
+	d.state.projects[p.Name] = api.Project{Name: p.Name, ProjectPut: api.ProjectPut{Config: config, Description: p.Description}}
+	d.state.profiles[applyKey(p.Name, "default")] = api.Profile{Name: "default"}
+
+	return nil
+}
+
+func (d *applyTestServer) UpdateProject(name string, p api.ProjectPut, ETag string) error {
+	d.state.projects[name] = api.Project{Name: name, ProjectPut: p}
+	return nil
+}
+
+func (d *applyTestServer) CreateNetwork(n api.NetworksPost) error {
+	config := map[string]string{"ipv4.address": "auto", "ipv4.nat": "true", "ipv6.address": "auto", "ipv6.nat": "true"}
+	for k, v := range n.Config {
+		config[k] = v
+	}
+
+	return d.UpdateNetwork(n.Name, api.NetworkPut{Config: config, Description: n.Description}, "")
+}
+
+func (d *applyTestServer) UpdateNetwork(name string, n api.NetworkPut, ETag string) error {
+	// Generate a new subnet whenever an address is set to auto.
+	for _, k := range []string{"ipv4.address", "ipv6.address"} {
+		if n.Config[k] == "auto" {
+			n.Config[k] = "generated-" + strings.TrimSuffix(k, ".address")
+		}
+	}
+
+	d.state.networks[applyKey(d.project, name)] = api.Network{Name: name, Type: "bridge", Managed: true, NetworkPut: n}
+	return nil
+}
+
+// Applying the same manifest twice doesn't change anything the second time, even with keys filled in by the server.
+func (s *applyTestSuite) Test_applyPlan_idempotent() {
+	manifest := applyManifest{}
+	s.Require().NoError(yaml.UnmarshalStrict([]byte(`
+projects:
+- name: web
+  config:
+    limits.instances: "10"
+networks:
+- name: lxdbr0
+  type: bridge
+  config:
+    ipv6.address: auto
+- name: lxdbr1
+  type: bridge
+  config:
+    ipv4.address: 10.0.2.1/24
+`), &manifest))
+	s.Require().NoError(manifest.normalize())
+
+	state := applyTestState()
+	d := &applyTestServer{project: "default", state: state}
+
+	changes, err := applyPlan(&manifest, state, false)
+	s.Require().NoError(err)
+	s.Len(changes, 3)
+
+	for _, change := range changes {
+		s.Require().NoError(change.run(d))
+	}
+
+	// The existing subnet of lxdbr0 wasn't regenerated.
+	s.Equal("10.0.0.1/24", state.networks["default/lxdbr0"].Config["ipv4.address"])
+	s.Equal("true", state.networks["default/lxdbr0"].Config["ipv4.nat"])
+
+	changes, err = applyPlan(&manifest, state, false)
+	s.Require().NoError(err)
+	s.Empty(changes)
+}
@@ -93,6 +93,24 @@ func (c *Config) GetInstanceServer(name string) (lxd.InstanceServer, error) {
 		return d, nil
 	}
 
+	// SSH
+	if strings.HasPrefix(remote.Addr, "ssh:") {
+		d, err := lxd.ConnectLXDSSH(remote.Addr, args)
+		if err != nil {
+			return nil, err
+		}
+
+		if remote.Project != "" && remote.Project != "default" {
+			d = d.UseProject(remote.Project)
+		}
+
+		if c.ProjectOverride != "" {
+			d = d.UseProject(c.ProjectOverride)
+		}
+
+		return d, nil
+	}
+
 	// HTTPs
 	if remote.AuthType != "candid" && (args.TLSClientCert == "" || args.TLSClientKey == "") {
 		return nil, fmt.Errorf("Missing TLS client certificate and key")
@@ -151,6 +169,24 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 		return d, nil
 	}
 
+	// SSH
+	if strings.HasPrefix(remote.Addr, "ssh:") {
+		d, err := lxd.ConnectLXDSSH(remote.Addr, args)
+		if err != nil {
+			return nil, err
+		}
+
+		if remote.Project != "" && remote.Project != "default" {
+			d = d.UseProject(remote.Project)
+		}
+
+		if c.ProjectOverride != "" {
+			d = d.UseProject(c.ProjectOverride)
+		}
+
+		return d, nil
+	}
+
 	// HTTPs (simplestreams)
 	if remote.Protocol == "simplestreams" {
 		d, err := lxd.ConnectSimpleStreams(remote.Addr, args)
@@ -246,7 +282,7 @@ func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 	}
 
 	// Stop here if no TLS involved
-	if strings.HasPrefix(remote.Addr, "unix:") {
+	if strings.HasPrefix(remote.Addr, "unix:") || strings.HasPrefix(remote.Addr, "ssh:") {
 		return &args, nil
 	}
 
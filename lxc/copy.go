@@ -30,6 +30,7 @@ type cmdCopy struct {
 	flagTarget        string
 	flagTargetProject string
 	flagRefresh       bool
+	flagResume        bool
 }
 
 func (c *cmdCopy) Command() *cobra.Command {
@@ -53,6 +54,7 @@ func (c *cmdCopy) Command() *cobra.Command {
 	cmd.Flags().StringVar(&c.flagTargetProject, "target-project", "", i18n.G("Copy to a project different from the source")+"``")
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagRefresh, "refresh", false, i18n.G("Perform an incremental copy"))
+	cmd.Flags().BoolVar(&c.flagResume, "resume", false, i18n.G("Resume an interrupted copy"))
 
 	return cmd
 }
@@ -104,6 +106,11 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 		}
 	}
 
+	// Relay the transfer when the servers can't reach each other.
+	if sourceRemote != destRemote {
+		mode = migrationMode(mode, conf.Remotes[sourceRemote].Addr, source, conf.Remotes[destRemote].Addr, dest)
+	}
+
 	// Project copies
 	if c.flagTargetProject != "" {
 		dest = dest.UseProject(c.flagTargetProject)
@@ -162,6 +169,10 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			return fmt.Errorf(i18n.G("--refresh can only be used with instances"))
 		}
 
+		if c.flagResume {
+			return fmt.Errorf(i18n.G("--resume can only be used with instances"))
+		}
+
 		// Copy of a snapshot into a new instance
 		srcFields := strings.SplitN(sourceName, shared.SnapshotDelimiter, 2)
 		entry, _, err := source.GetInstanceSnapshot(srcFields[0], srcFields[1])
@@ -245,6 +256,7 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			InstanceOnly: instanceOnly,
 			Mode:         mode,
 			Refresh:      c.flagRefresh,
+			Resume:       c.flagResume,
 		}
 
 		// Copy of an instance into a new instance
@@ -449,6 +461,10 @@ func (c *cmdCopy) Run(cmd *cobra.Command, args []string) error {
 		mode = c.flagMode
 	}
 
+	if c.flagRefresh && c.flagResume {
+		return fmt.Errorf(i18n.G("--refresh and --resume can't be used together"))
+	}
+
 	stateful := !c.flagStateless && !c.flagRefresh
 	keepVolatile := c.flagRefresh
 	instanceOnly := c.flagInstanceOnly
@@ -28,7 +28,8 @@ type cmdExec struct {
 	flagForceInteractive    bool
 	flagForceNonInteractive bool
 	flagDisableStdin        bool
-	flagUser                uint32
+	flagUser                string
+	flagLogin               bool
 	flagGroup               uint32
 	flagCwd                 string
 }
@@ -47,6 +48,11 @@ executable, passing the shell commands as arguments, for example:
 
   lxc exec <instance> -- sh -c "cd /tmp && pwd"
 
+The user may be passed either as a user ID or as a user name, which is
+resolved inside the instance. With --login, the command is run through the
+login shell of the user (or the shell itself is spawned if no command is
+given), with the environment and home directory of the user.
+
 Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`))
 
 	cmd.RunE = c.Run
@@ -55,7 +61,8 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	cmd.Flags().BoolVarP(&c.flagForceInteractive, "force-interactive", "t", false, i18n.G("Force pseudo-terminal allocation"))
 	cmd.Flags().BoolVarP(&c.flagForceNonInteractive, "force-noninteractive", "T", false, i18n.G("Disable pseudo-terminal allocation"))
 	cmd.Flags().BoolVarP(&c.flagDisableStdin, "disable-stdin", "n", false, i18n.G("Disable stdin (reads from /dev/null)"))
-	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
+	cmd.Flags().StringVar(&c.flagUser, "user", "", i18n.G("User ID or name to run the command as (default 0)")+"``")
+	cmd.Flags().BoolVar(&c.flagLogin, "login", false, i18n.G("Run the command through a login shell of the user"))
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
 
@@ -83,7 +90,12 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	minArgs := 2
+	if c.flagLogin {
+		minArgs = 1
+	}
+
+	exit, err := c.global.CheckArgs(cmd, args, minArgs, -1)
 	if exit {
 		return err
 	}
@@ -107,6 +119,22 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Parse the user, either a numeric ID or a name to resolve in the instance
+	var uid uint32
+	var username string
+	if c.flagUser != "" {
+		id, err := strconv.ParseUint(c.flagUser, 10, 32)
+		if err == nil {
+			uid = uint32(id)
+		} else {
+			username = c.flagUser
+		}
+	}
+
+	if (username != "" || c.flagLogin) && !d.HasExtension("exec_username") {
+		return fmt.Errorf(i18n.G("The server doesn't support user names and login shells for exec"))
+	}
+
 	// Set the environment
 	env := map[string]string{}
 	myTerm, ok := c.getTERM()
@@ -184,7 +212,9 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		Environment: env,
 		Width:       width,
 		Height:      height,
-		User:        c.flagUser,
+		User:        uid,
+		Username:    username,
+		Login:       c.flagLogin,
 		Group:       c.flagGroup,
 		Cwd:         c.flagCwd,
 	}
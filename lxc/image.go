@@ -19,6 +19,7 @@ import (
 	"github.com/lxc/lxd/shared/api"
 	cli "github.com/lxc/lxd/shared/cmd"
 	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/signing"
 	"github.com/lxc/lxd/shared/termios"
 )
 
@@ -56,6 +57,10 @@ hash or alias name (if one is set).`))
 	imageAliasCmd := cmdImageAlias{global: c.global, image: c}
 	cmd.AddCommand(imageAliasCmd.Command())
 
+	// Build
+	imageBuildCmd := cmdImageBuild{global: c.global, image: c}
+	cmd.AddCommand(imageBuildCmd.Command())
+
 	// Copy
 	imageCopyCmd := cmdImageCopy{global: c.global, image: c}
 	cmd.AddCommand(imageCopyCmd.Command())
@@ -92,6 +97,10 @@ hash or alias name (if one is set).`))
 	imageShowCmd := cmdImageShow{global: c.global, image: c}
 	cmd.AddCommand(imageShowCmd.Command())
 
+	// Sign
+	imageSignCmd := cmdImageSign{global: c.global, image: c}
+	cmd.AddCommand(imageSignCmd.Command())
+
 	// Get-property
 	imageGetPropCmd := cmdImageGetProp{global: c.global, image: c}
 	cmd.AddCommand(imageGetPropCmd.Command())
@@ -123,6 +132,135 @@ func (c *cmdImage) dereferenceAlias(d lxd.ImageServer, imageType string, inName
 	return result.Target
 }
 
+// Build
+type cmdImageBuild struct {
+	global *cmdGlobal
+	image  *cmdImage
+
+	flagPublic  bool
+	flagAliases []string
+}
+
+func (c *cmdImageBuild) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("build", i18n.G("[<remote>:] <recipe>"))
+	cmd.Short = i18n.G("Build images from a recipe")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Build images from a recipe
+
+The recipe is a YAML file describing the base image (source) and the
+build steps (source.build) applied to it in a temporary instance,
+which is then published as the new image.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc image build web.yaml --alias web
+    Build an image from the recipe in web.yaml and alias it "web".
+
+Example of recipe:
+
+source:
+  server: https://images.linuxcontainers.org
+  protocol: simplestreams
+  alias: ubuntu/20.04
+  build:
+    packages:
+    - nginx
+    commands:
+    - systemctl enable nginx
+    cleanup:
+    - apt-get clean
+properties:
+  description: Ubuntu 20.04 with nginx`))
+
+	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Make image public"))
+	cmd.Flags().StringArrayVar(&c.flagAliases, "alias", nil, i18n.G("New aliases to add to the image")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdImageBuild) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	remote := ""
+	recipeFile := args[0]
+	if len(args) == 2 {
+		remote = args[0]
+		recipeFile = args[1]
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Load the recipe
+	content, err := ioutil.ReadFile(shared.HostPathFollow(recipeFile))
+	if err != nil {
+		return err
+	}
+
+	image := api.ImagesPost{}
+	err = yaml.Unmarshal(content, &image)
+	if err != nil {
+		return err
+	}
+
+	if image.Source == nil || image.Source.Build == nil {
+		return fmt.Errorf(i18n.G("The recipe is missing the source.build section"))
+	}
+
+	image.Source.Type = "build"
+	image.Source.Mode = "pull"
+	if c.flagPublic {
+		image.Public = true
+	}
+
+	for _, entry := range c.flagAliases {
+		image.Aliases = append(image.Aliases, api.ImageAlias{Name: entry})
+	}
+
+	progress := utils.ProgressRenderer{
+		Format: i18n.G("Building image: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	// Start the build
+	op, err := resource.server.CreateImage(image, nil)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait for operation to finish
+	err = utils.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+
+		// Show the build log to help figure out what went wrong.
+		buildLog, ok := op.Get().Metadata["build_log"].(string)
+		if ok && buildLog != "" {
+			fmt.Fprintf(os.Stderr, "%s\n", strings.TrimSpace(buildLog))
+		}
+
+		return err
+	}
+
+	opAPI := op.Get()
+
+	// Get the fingerprint
+	fingerprint := opAPI.Metadata["fingerprint"].(string)
+	progress.Done(fmt.Sprintf(i18n.G("Image built with fingerprint: %s"), fingerprint))
+
+	return nil
+}
+
 // Copy
 type cmdImageCopy struct {
 	global *cmdGlobal
@@ -614,8 +752,9 @@ type cmdImageImport struct {
 	global *cmdGlobal
 	image  *cmdImage
 
-	flagPublic  bool
-	flagAliases []string
+	flagPublic    bool
+	flagAliases   []string
+	flagSignature string
 }
 
 func (c *cmdImageImport) Command() *cobra.Command {
@@ -629,6 +768,7 @@ Directory import is only available on Linux and must be performed as root.`))
 
 	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Make image public"))
 	cmd.Flags().StringArrayVar(&c.flagAliases, "alias", nil, i18n.G("New aliases to add to the image")+"``")
+	cmd.Flags().StringVar(&c.flagSignature, "signature", "", i18n.G("File containing a detached signature of the image")+"``")
 	cmd.RunE = c.Run
 
 	return cmd
@@ -719,6 +859,20 @@ func (c *cmdImageImport) Run(cmd *cobra.Command, args []string) error {
 	image := api.ImagesPost{}
 	image.Public = c.flagPublic
 
+	// Load the signature
+	if c.flagSignature != "" {
+		if strings.HasPrefix(imageFile, "https://") {
+			return fmt.Errorf(i18n.G("Signatures can't be provided when importing from a URL"))
+		}
+
+		signature, err := ioutil.ReadFile(shared.HostPathFollow(c.flagSignature))
+		if err != nil {
+			return err
+		}
+
+		image.Signature = strings.TrimSpace(string(signature))
+	}
+
 	// Handle properties
 	for _, entry := range properties {
 		fields := strings.SplitN(entry, "=", 2)
@@ -906,6 +1060,13 @@ func (c *cmdImageInfo) Run(cmd *cobra.Command, args []string) error {
 	fmt.Printf(i18n.G("Architecture: %s")+"\n", info.Architecture)
 	fmt.Printf(i18n.G("Type: %s")+"\n", imgType)
 	fmt.Printf(i18n.G("Public: %s")+"\n", public)
+
+	if info.Signature != "" {
+		fmt.Printf(i18n.G("Signed: %s")+"\n", i18n.G("yes"))
+	} else {
+		fmt.Printf(i18n.G("Signed: %s")+"\n", i18n.G("no"))
+	}
+
 	fmt.Printf(i18n.G("Timestamps:") + "\n")
 
 	const layout = "2006/01/02 15:04 UTC"
@@ -1404,6 +1565,79 @@ func (c *cmdImageShow) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Sign
+type cmdImageSign struct {
+	global *cmdGlobal
+	image  *cmdImage
+}
+
+func (c *cmdImageSign) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("sign", i18n.G("[<remote>:]<image> <private key>"))
+	cmd.Short = i18n.G("Sign images")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Sign images
+
+The image fingerprint is signed with the provided private key, either a
+PEM encoded Ed25519 key or an armored (not passphrase protected) GPG key,
+and the resulting detached signature is stored on the image.
+
+Servers with images.trust.keys set only accept images signed by one of
+the listed public keys.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc image sign ubuntu-focal signing.key
+    Sign the image aliased "ubuntu-focal" with the Ed25519 or GPG private key in signing.key.`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdImageSign) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Image identifier missing: %s"), args[0])
+	}
+
+	// Load the key
+	key, err := ioutil.ReadFile(shared.HostPathFollow(args[1]))
+	if err != nil {
+		return err
+	}
+
+	// Get the image
+	image := c.image.dereferenceAlias(resource.server, "", resource.name)
+	info, etag, err := resource.server.GetImage(image)
+	if err != nil {
+		return err
+	}
+
+	// Sign the fingerprint
+	signature, err := signing.Sign(key, []byte(info.Fingerprint))
+	if err != nil {
+		return err
+	}
+
+	// Update image
+	writable := info.Writable()
+	writable.Signature = signature
+
+	return resource.server.UpdateImage(info.Fingerprint, writable, etag)
+}
+
 type cmdImageGetProp struct {
 	global *cmdGlobal
 	image  *cmdImage
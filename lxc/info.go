@@ -486,6 +486,10 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 	if cs.Pid != 0 {
 		fmt.Printf(i18n.G("Pid: %d")+"\n", cs.Pid)
 
+		if cs.Health != nil {
+			fmt.Printf(i18n.G("Health: %s")+"\n", cs.Health.Status)
+		}
+
 		// IP addresses
 		ipInfo := ""
 		if cs.Network != nil {
@@ -525,6 +529,25 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 			fmt.Print(diskInfo)
 		}
 
+		// Disk I/O
+		ioInfo := ""
+		if cs.Disk != nil {
+			for entry, disk := range cs.Disk {
+				if disk.ReadOps != 0 || disk.WriteOps != 0 {
+					ioInfo += fmt.Sprintf("    %s:\n", entry)
+					ioInfo += fmt.Sprintf("      %s: %s\n", i18n.G("Bytes read"), units.GetByteSizeString(disk.ReadBytes, 2))
+					ioInfo += fmt.Sprintf("      %s: %s\n", i18n.G("Bytes written"), units.GetByteSizeString(disk.WriteBytes, 2))
+					ioInfo += fmt.Sprintf("      %s: %d\n", i18n.G("Read operations"), disk.ReadOps)
+					ioInfo += fmt.Sprintf("      %s: %d\n", i18n.G("Write operations"), disk.WriteOps)
+				}
+			}
+		}
+
+		if ioInfo != "" {
+			fmt.Printf("  %s\n", i18n.G("Disk I/O:"))
+			fmt.Print(ioInfo)
+		}
+
 		// CPU usage
 		cpuInfo := ""
 		if cs.CPU.Usage != 0 {
@@ -575,6 +598,29 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 			fmt.Printf("  %s\n", i18n.G("Network usage:"))
 			fmt.Print(networkInfo)
 		}
+
+		// Pressure stall information
+		if cs.Pressure != nil {
+			pressureInfo := ""
+			renderPressure := func(name string, res *api.InstanceStatePressureResource) {
+				if res == nil {
+					return
+				}
+
+				pressureInfo += fmt.Sprintf("    %s:\n", name)
+				pressureInfo += fmt.Sprintf("      %s: %.2f%% %.2f%% %.2f%%\n", i18n.G("Some (10s, 60s, 300s)"), res.Some.Avg10, res.Some.Avg60, res.Some.Avg300)
+				pressureInfo += fmt.Sprintf("      %s: %.2f%% %.2f%% %.2f%%\n", i18n.G("Full (10s, 60s, 300s)"), res.Full.Avg10, res.Full.Avg60, res.Full.Avg300)
+			}
+
+			renderPressure(i18n.G("CPU"), cs.Pressure.CPU)
+			renderPressure(i18n.G("Memory"), cs.Pressure.Memory)
+			renderPressure(i18n.G("I/O"), cs.Pressure.IO)
+
+			if pressureInfo != "" {
+				fmt.Printf("  %s\n", i18n.G("Pressure:"))
+				fmt.Print(pressureInfo)
+			}
+		}
 	}
 
 	// List snapshots
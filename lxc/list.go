@@ -94,6 +94,7 @@ Pre-defined column shorthand chars:
   c - Creation date
   d - Description
   D - disk usage
+  i - I/O pressure (10s average)
   l - Last used date
   m - Memory usage
   M - Memory usage (%)
@@ -101,10 +102,12 @@ Pre-defined column shorthand chars:
   N - Number of Processes
   p - PID of the instance's init process
   P - Profiles
+  R - Disk bytes read
   s - State
   S - Number of snapshots
   t - Type (persistent or ephemeral)
   u - CPU usage (in seconds)
+  W - Disk bytes written
   L - Location of the instance (e.g. its cluster member)
   f - Base Image Fingerprint (short)
   F - Base Image Fingerprint (long)
@@ -489,6 +492,7 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		'd': {i18n.G("DESCRIPTION"), c.descriptionColumnData, false, false},
 		'D': {i18n.G("DISK USAGE"), c.diskUsageColumnData, true, false},
 		'f': {i18n.G("BASE IMAGE"), c.baseImageColumnData, false, false},
+		'i': {i18n.G("I/O PRESSURE"), c.ioPressureColumnData, true, false},
 		'F': {i18n.G("BASE IMAGE"), c.baseImageFullColumnData, false, false},
 		'l': {i18n.G("LAST USED AT"), c.LastUsedColumnData, false, false},
 		'm': {i18n.G("MEMORY USAGE"), c.memoryUsageColumnData, true, false},
@@ -497,10 +501,12 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		'N': {i18n.G("PROCESSES"), c.NumberOfProcessesColumnData, true, false},
 		'p': {i18n.G("PID"), c.PIDColumnData, true, false},
 		'P': {i18n.G("PROFILES"), c.ProfilesColumnData, false, false},
+		'R': {i18n.G("DISK READ"), c.diskReadColumnData, true, false},
 		'S': {i18n.G("SNAPSHOTS"), c.numberSnapshotsColumnData, false, true},
 		's': {i18n.G("STATE"), c.statusColumnData, false, false},
 		't': {i18n.G("TYPE"), c.typeColumnData, false, false},
 		'u': {i18n.G("CPU USAGE"), c.cpuUsageSecondsColumnData, true, false},
+		'W': {i18n.G("DISK WRITE"), c.diskWriteColumnData, true, false},
 	}
 
 	if c.flagFast {
@@ -762,6 +768,48 @@ func (c *cmdList) diskUsageColumnData(cInfo api.InstanceFull) string {
 	return ""
 }
 
+func (c *cmdList) diskReadColumnData(cInfo api.InstanceFull) string {
+	if !cInfo.IsActive() || cInfo.State == nil {
+		return ""
+	}
+
+	var total int64
+	for _, disk := range cInfo.State.Disk {
+		total += disk.ReadBytes
+	}
+
+	if total > 0 {
+		return units.GetByteSizeString(total, 2)
+	}
+
+	return ""
+}
+
+func (c *cmdList) diskWriteColumnData(cInfo api.InstanceFull) string {
+	if !cInfo.IsActive() || cInfo.State == nil {
+		return ""
+	}
+
+	var total int64
+	for _, disk := range cInfo.State.Disk {
+		total += disk.WriteBytes
+	}
+
+	if total > 0 {
+		return units.GetByteSizeString(total, 2)
+	}
+
+	return ""
+}
+
+func (c *cmdList) ioPressureColumnData(cInfo api.InstanceFull) string {
+	if cInfo.IsActive() && cInfo.State != nil && cInfo.State.Pressure != nil && cInfo.State.Pressure.IO != nil {
+		return fmt.Sprintf("%.2f%%", cInfo.State.Pressure.IO.Some.Avg10)
+	}
+
+	return ""
+}
+
 func (c *cmdList) typeColumnData(cInfo api.InstanceFull) string {
 	if cInfo.Type == "" {
 		cInfo.Type = "container"
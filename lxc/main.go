@@ -77,6 +77,10 @@ For help with any of those, simply call them with --help.`))
 	aliasCmd := cmdAlias{global: &globalCmd}
 	app.AddCommand(aliasCmd.Command())
 
+	// apply sub-command
+	applyCmd := cmdApply{global: &globalCmd}
+	app.AddCommand(applyCmd.Command())
+
 	// cluster sub-command
 	clusterCmd := cmdCluster{global: &globalCmd}
 	app.AddCommand(clusterCmd.Command())
@@ -205,10 +209,18 @@ For help with any of those, simply call them with --help.`))
 	stopCmd := cmdStop{global: &globalCmd}
 	app.AddCommand(stopCmd.Command())
 
+	// top sub-command
+	topCmd := cmdTop{global: &globalCmd}
+	app.AddCommand(topCmd.Command())
+
 	// version sub-command
 	versionCmd := cmdVersion{global: &globalCmd}
 	app.AddCommand(versionCmd.Command())
 
+	// wait sub-command
+	waitCmd := cmdWait{global: &globalCmd}
+	app.AddCommand(waitCmd.Command())
+
 	// warning sub-command
 	warningCmd := cmdWarning{global: &globalCmd}
 	app.AddCommand(warningCmd.Command())
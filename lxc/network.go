@@ -77,6 +77,10 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkListLeasesCmd := cmdNetworkListLeases{global: c.global, network: c}
 	cmd.AddCommand(networkListLeasesCmd.Command())
 
+	// List allocations
+	networkListAllocationsCmd := cmdNetworkListAllocations{global: c.global, network: c}
+	cmd.AddCommand(networkListAllocationsCmd.Command())
+
 	// Rename
 	networkRenameCmd := cmdNetworkRename{global: c.global, network: c}
 	cmd.AddCommand(networkRenameCmd.Command())
@@ -97,6 +101,18 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkACLCmd := cmdNetworkACL{global: c.global}
 	cmd.AddCommand(networkACLCmd.Command())
 
+	// Address set
+	networkAddressSetCmd := cmdNetworkAddressSet{global: c.global}
+	cmd.AddCommand(networkAddressSetCmd.Command())
+
+	// Load balancer
+	networkLoadBalancerCmd := cmdNetworkLoadBalancer{global: c.global}
+	cmd.AddCommand(networkLoadBalancerCmd.Command())
+
+	// DHCP reservation
+	networkReservationCmd := cmdNetworkReservation{global: c.global}
+	cmd.AddCommand(networkReservationCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
@@ -810,6 +826,50 @@ func (c *cmdNetworkInfo) Run(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  %s: %d\n", i18n.G("Packets received"), state.Counters.PacketsReceived)
 	fmt.Printf("  %s: %d\n", i18n.G("Packets sent"), state.Counters.PacketsSent)
 
+	// IPv6 prefix delegation information
+	if state.IPv6PD != nil {
+		prefix := state.IPv6PD.Prefix
+		if prefix == "" {
+			prefix = i18n.G("none")
+		}
+
+		fmt.Println("")
+		fmt.Println(i18n.G("IPv6 prefix delegation:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Interface"), state.IPv6PD.Interface)
+		fmt.Printf("  %s: %s\n", i18n.G("Delegated prefix"), prefix)
+		fmt.Printf("  %s: %s\n", i18n.G("Subnet"), state.IPv6PD.Subnet)
+	}
+
+	// WireGuard information
+	if state.Wireguard != nil {
+		const layout = "2006/01/02 15:04 UTC"
+
+		fmt.Println("")
+		fmt.Println(i18n.G("WireGuard:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Public key"), state.Wireguard.PublicKey)
+		fmt.Printf("  %s: %d\n", i18n.G("Listen port"), state.Wireguard.ListenPort)
+
+		for _, peer := range state.Wireguard.Peers {
+			name := peer.Name
+			if name == "" {
+				name = peer.PublicKey
+			}
+
+			handshake := i18n.G("never")
+			if !peer.LatestHandshake.IsZero() {
+				handshake = peer.LatestHandshake.UTC().Format(layout)
+			}
+
+			fmt.Println("")
+			fmt.Printf("  %s: %s\n", i18n.G("Peer"), name)
+			fmt.Printf("    %s: %s\n", i18n.G("Endpoint"), peer.Endpoint)
+			fmt.Printf("    %s: %s\n", i18n.G("Allowed IPs"), strings.Join(peer.AllowedIPs, ", "))
+			fmt.Printf("    %s: %s\n", i18n.G("Latest handshake"), handshake)
+			fmt.Printf("    %s: %s\n", i18n.G("Bytes received"), units.GetByteSizeString(peer.BytesReceived, 2))
+			fmt.Printf("    %s: %s\n", i18n.G("Bytes sent"), units.GetByteSizeString(peer.BytesSent, 2))
+		}
+	}
+
 	return nil
 }
 
@@ -979,6 +1039,88 @@ func (c *cmdNetworkListLeases) Run(cmd *cobra.Command, args []string) error {
 	return utils.RenderTable(c.flagFormat, header, data, leases)
 }
 
+// List allocations
+type cmdNetworkListAllocations struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagFormat      string
+	flagAllProjects bool
+}
+
+func (c *cmdNetworkListAllocations) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-allocations", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("List network address allocations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List the addresses allocated to instances and networks
+
+Addresses allocated to more than one entity on the same network are marked as conflicting.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("Show the allocations of all projects"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkListAllocations) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List allocations
+	allocations, err := resource.server.GetNetworkAllocations(c.flagAllProjects)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, allocation := range allocations {
+		conflict := ""
+		if allocation.Conflict {
+			conflict = i18n.G("YES")
+		}
+
+		entry := []string{allocation.UsedBy, allocation.Address, allocation.Network, strings.ToUpper(allocation.Type), allocation.Hwaddr, conflict}
+		if c.flagAllProjects {
+			entry = append([]string{allocation.Project}, entry...)
+		}
+
+		data = append(data, entry)
+	}
+	sort.Sort(byName(data))
+
+	header := []string{
+		i18n.G("USED BY"),
+		i18n.G("ADDRESS"),
+		i18n.G("NETWORK"),
+		i18n.G("TYPE"),
+		i18n.G("MAC ADDRESS"),
+		i18n.G("CONFLICT"),
+	}
+	if c.flagAllProjects {
+		header = append([]string{i18n.G("PROJECT")}, header...)
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, allocations)
+}
+
 // Rename
 type cmdNetworkRename struct {
 	global  *cmdGlobal
@@ -0,0 +1,768 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/termios"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+type cmdNetworkAddressSet struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkAddressSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("address-set")
+	cmd.Short = i18n.G("Manage network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network address sets"))
+
+	// List.
+	networkAddressSetListCmd := cmdNetworkAddressSetList{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetListCmd.Command())
+
+	// Show.
+	networkAddressSetShowCmd := cmdNetworkAddressSetShow{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetShowCmd.Command())
+
+	// Get.
+	networkAddressSetGetCmd := cmdNetworkAddressSetGet{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetGetCmd.Command())
+
+	// Create.
+	networkAddressSetCreateCmd := cmdNetworkAddressSetCreate{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetCreateCmd.Command())
+
+	// Set.
+	networkAddressSetSetCmd := cmdNetworkAddressSetSet{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetSetCmd.Command())
+
+	// Unset.
+	networkAddressSetUnsetCmd := cmdNetworkAddressSetUnset{global: c.global, networkAddressSet: c, networkAddressSetSet: &networkAddressSetSetCmd}
+	cmd.AddCommand(networkAddressSetUnsetCmd.Command())
+
+	// Edit.
+	networkAddressSetEditCmd := cmdNetworkAddressSetEdit{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetEditCmd.Command())
+
+	// Rename.
+	networkAddressSetRenameCmd := cmdNetworkAddressSetRename{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetRenameCmd.Command())
+
+	// Delete.
+	networkAddressSetDeleteCmd := cmdNetworkAddressSetDelete{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetDeleteCmd.Command())
+
+	// Address.
+	networkAddressSetAddressCmd := cmdNetworkAddressSetAddress{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetAddressCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkAddressSetList struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+
+	flagFormat string
+}
+
+func (c *cmdNetworkAddressSetList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network address sets"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the networks.
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	addressSets, err := resource.server.GetNetworkAddressSets()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, addressSet := range addressSets {
+		strUsedBy := fmt.Sprintf("%d", len(addressSet.UsedBy))
+		details := []string{
+			addressSet.Name,
+			addressSet.Description,
+			strUsedBy,
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(byName(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("USED BY"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, addressSets)
+}
+
+// Show.
+type cmdNetworkAddressSetShow struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<address set>"))
+	cmd.Short = i18n.G("Show network address set configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network address set configurations"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// Show the network address set config.
+	addressSet, _, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(addressSet.UsedBy)
+
+	data, err := yaml.Marshal(&addressSet)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdNetworkAddressSetGet struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<address set> <key>"))
+	cmd.Short = i18n.G("Get values for network address set configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network address set configuration keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	resp, _, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range resp.Config {
+		if k == args[1] {
+			fmt.Printf("%s\n", v)
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdNetworkAddressSetCreate struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<address set> [key=value...]"))
+	cmd.Short = i18n.G("Create new network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network address sets"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var addressSetPut api.NetworkAddressSetPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &addressSetPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network address set.
+	addressSet := api.NetworkAddressSetsPost{
+		NetworkAddressSetPost: api.NetworkAddressSetPost{
+			Name: resource.name,
+		},
+		NetworkAddressSetPut: addressSetPut,
+	}
+
+	if addressSet.Config == nil {
+		addressSet.Config = map[string]string{}
+	}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		addressSet.Config[entry[0]] = entry[1]
+	}
+
+	err = resource.server.CreateNetworkAddressSet(addressSet)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address set %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkAddressSetSet struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<address set> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network address set configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set network address set configuration keys
+
+For backward compatibility, a single configuration key may still be set with:
+    lxc network set [<remote>:]<address set> <key> <value>`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// Get the network address set.
+	addressSet, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range keys {
+		addressSet.Config[k] = v
+	}
+
+	return resource.server.UpdateNetworkAddressSet(resource.name, addressSet.Writable(), etag)
+}
+
+// Unset.
+type cmdNetworkAddressSetUnset struct {
+	global               *cmdGlobal
+	networkAddressSet    *cmdNetworkAddressSet
+	networkAddressSetSet *cmdNetworkAddressSetSet
+}
+
+func (c *cmdNetworkAddressSetUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<address set> <key>"))
+	cmd.Short = i18n.G("Unset network address set configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network address set configuration keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	args = append(args, "")
+	return c.networkAddressSetSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkAddressSetEdit struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<address set>"))
+	cmd.Short = i18n.G("Edit network address set configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network address set configurations as YAML"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network address set.
+### Any line starting with a '# will be ignored.
+###
+### A network address set consists of a list of addresses and configuration items.
+###
+### An example would look like:
+### name: office
+### description: Office and VPN networks
+### addresses:
+### - 192.0.2.0/24
+### - 2001:db8::/32
+### config:
+###  user.foo: bah
+###
+### Note that only the addresses, description and configuration keys can be changed.`)
+}
+
+func (c *cmdNetworkAddressSetEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network address-set show` command to passed in here, but only take the contents
+		// of the NetworkAddressSetPut fields when updating the address set. The other fields are silently discarded.
+		newdata := api.NetworkAddressSet{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateNetworkAddressSet(resource.name, newdata.NetworkAddressSetPut, "")
+	}
+
+	// Get the current config.
+	addressSet, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&addressSet)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.NetworkAddressSet{} // We show the full address set info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateNetworkAddressSet(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Rename.
+type cmdNetworkAddressSetRename struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetRename) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<address set> <new-name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Rename network address sets"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetRename) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// Rename the network.
+	err = resource.server.RenameNetworkAddressSet(resource.name, api.NetworkAddressSetPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address set %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkAddressSetDelete struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<address set>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network address sets"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// Delete the network address set.
+	err = resource.server.DeleteNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address set %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Add/Remove Address.
+type cmdNetworkAddressSetAddress struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+func (c *cmdNetworkAddressSetAddress) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("address")
+	cmd.Short = i18n.G("Manage network address set addresses")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network address set addresses"))
+
+	// Address Add.
+	cmd.AddCommand(c.CommandAdd())
+
+	// Address Remove.
+	cmd.AddCommand(c.CommandRemove())
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetAddress) CommandAdd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<address set> <address>..."))
+	cmd.Short = i18n.G("Add addresses to an address set")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Add addresses to an address set"))
+	cmd.RunE = c.RunAdd
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetAddress) RunAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// Get the network address set.
+	addressSet, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range args[1:] {
+		address = strings.TrimSpace(address)
+		if shared.StringInSlice(address, addressSet.Addresses) {
+			return fmt.Errorf(i18n.G("Address %q already exists in address set"), address)
+		}
+
+		addressSet.Addresses = append(addressSet.Addresses, address)
+	}
+
+	return resource.server.UpdateNetworkAddressSet(resource.name, addressSet.Writable(), etag)
+}
+
+func (c *cmdNetworkAddressSetAddress) CommandRemove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<address set> <address>..."))
+	cmd.Short = i18n.G("Remove addresses from an address set")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove addresses from an address set"))
+	cmd.RunE = c.RunRemove
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetAddress) RunRemove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network address set name"))
+	}
+
+	// Get the network address set.
+	addressSet, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	removeAddresses := make([]string, 0, len(args[1:]))
+	for _, address := range args[1:] {
+		address = strings.TrimSpace(address)
+		if !shared.StringInSlice(address, addressSet.Addresses) {
+			return fmt.Errorf(i18n.G("Address %q not found in address set"), address)
+		}
+
+		removeAddresses = append(removeAddresses, address)
+	}
+
+	// Build the new list of addresses, excluding the removed ones.
+	addresses := make([]string, 0, len(addressSet.Addresses))
+	for _, address := range addressSet.Addresses {
+		if shared.StringInSlice(address, removeAddresses) {
+			continue
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	addressSet.Addresses = addresses
+
+	return resource.server.UpdateNetworkAddressSet(resource.name, addressSet.Writable(), etag)
+}
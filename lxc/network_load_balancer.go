@@ -0,0 +1,875 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/termios"
+)
+
+type cmdNetworkLoadBalancer struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkLoadBalancer) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("load-balancer")
+	cmd.Aliases = []string{"lb"}
+	cmd.Short = i18n.G("Manage network load balancers")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network load balancers"))
+
+	// List.
+	networkLoadBalancerListCmd := cmdNetworkLoadBalancerList{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerListCmd.Command())
+
+	// Show.
+	networkLoadBalancerShowCmd := cmdNetworkLoadBalancerShow{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerShowCmd.Command())
+
+	// Create.
+	networkLoadBalancerCreateCmd := cmdNetworkLoadBalancerCreate{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerCreateCmd.Command())
+
+	// Get.
+	networkLoadBalancerGetCmd := cmdNetworkLoadBalancerGet{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerGetCmd.Command())
+
+	// Set.
+	networkLoadBalancerSetCmd := cmdNetworkLoadBalancerSet{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerSetCmd.Command())
+
+	// Unset.
+	networkLoadBalancerUnsetCmd := cmdNetworkLoadBalancerUnset{global: c.global, networkLoadBalancer: c, networkLoadBalancerSet: &networkLoadBalancerSetCmd}
+	cmd.AddCommand(networkLoadBalancerUnsetCmd.Command())
+
+	// Edit.
+	networkLoadBalancerEditCmd := cmdNetworkLoadBalancerEdit{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerEditCmd.Command())
+
+	// Delete.
+	networkLoadBalancerDeleteCmd := cmdNetworkLoadBalancerDelete{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerDeleteCmd.Command())
+
+	// Backend.
+	networkLoadBalancerBackendCmd := cmdNetworkLoadBalancerBackend{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerBackendCmd.Command())
+
+	// Port.
+	networkLoadBalancerPortCmd := cmdNetworkLoadBalancerPort{global: c.global, networkLoadBalancer: c}
+	cmd.AddCommand(networkLoadBalancerPortCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkLoadBalancerList struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+
+	flagFormat string
+}
+
+func (c *cmdNetworkLoadBalancerList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network load balancers")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network load balancers"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	loadBalancers, err := resource.server.GetNetworkLoadBalancers(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, loadBalancer := range loadBalancers {
+		details := []string{
+			loadBalancer.ListenAddress,
+			loadBalancer.Description,
+			fmt.Sprintf("%d", len(loadBalancer.Backends)),
+			fmt.Sprintf("%d", len(loadBalancer.Ports)),
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(byName(data))
+
+	header := []string{
+		i18n.G("LISTEN ADDRESS"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("BACKENDS"),
+		i18n.G("PORTS"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, loadBalancers)
+}
+
+// Show.
+type cmdNetworkLoadBalancerShow struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <listen_address>"))
+	cmd.Short = i18n.G("Show network load balancer configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network load balancer configurations"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Show the network load balancer config.
+	loadBalancer, _, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&loadBalancer)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkLoadBalancerCreate struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> [<listen_address>] [key=value...]"))
+	cmd.Short = i18n.G("Create new network load balancers")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create new network load balancers
+
+If no listen address is provided, one is allocated from the uplink network.`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var loadBalancerPut api.NetworkLoadBalancerPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &loadBalancerPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network load balancer.
+	loadBalancer := api.NetworkLoadBalancersPost{
+		NetworkLoadBalancerPut: loadBalancerPut,
+	}
+
+	configArgs := args[1:]
+	if len(configArgs) > 0 && !strings.Contains(configArgs[0], "=") {
+		loadBalancer.ListenAddress = configArgs[0]
+		configArgs = configArgs[1:]
+	}
+
+	if loadBalancer.Config == nil {
+		loadBalancer.Config = map[string]string{}
+	}
+
+	for _, arg := range configArgs {
+		entry := strings.SplitN(arg, "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), arg)
+		}
+
+		loadBalancer.Config[entry[0]] = entry[1]
+	}
+
+	listenAddress, err := resource.server.CreateNetworkLoadBalancer(resource.name, loadBalancer)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network load balancer %s created")+"\n", listenAddress)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdNetworkLoadBalancerGet struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<network> <listen_address> <key>"))
+	cmd.Short = i18n.G("Get values for network load balancer configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network load balancer configuration keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	loadBalancer, _, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	for k, v := range loadBalancer.Config {
+		if k == args[2] {
+			fmt.Printf("%s\n", v)
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkLoadBalancerSet struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<network> <listen_address> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network load balancer keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set network load balancer keys"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Get the network load balancer.
+	loadBalancer, etag, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[2:]...)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range keys {
+		if k == "description" {
+			loadBalancer.Description = v
+			continue
+		}
+
+		loadBalancer.Config[k] = v
+	}
+
+	return resource.server.UpdateNetworkLoadBalancer(resource.name, loadBalancer.ListenAddress, loadBalancer.Writable(), etag)
+}
+
+// Unset.
+type cmdNetworkLoadBalancerUnset struct {
+	global                 *cmdGlobal
+	networkLoadBalancer    *cmdNetworkLoadBalancer
+	networkLoadBalancerSet *cmdNetworkLoadBalancerSet
+}
+
+func (c *cmdNetworkLoadBalancerUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<network> <listen_address> <key>"))
+	cmd.Short = i18n.G("Unset network load balancer keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network load balancer keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	args = append(args, "")
+	return c.networkLoadBalancerSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkLoadBalancerEdit struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<network> <listen_address>"))
+	cmd.Short = i18n.G("Edit network load balancer configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network load balancer configurations as YAML"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network load balancer.
+### Any line starting with a '# will be ignored.
+###
+### A network load balancer consists of a set of target backends and port forwards for a listen address.
+###
+### An example would look like:
+### listen_address: 192.0.2.1
+### config:
+###   healthcheck: "true"
+### description: test desc
+### backends:
+### - name: backend1
+###   description: First backend server
+###   target_address: 198.51.100.2
+###   target_port: 80
+### - name: backend2
+###   description: Second backend server
+###   target_address: 198.51.100.3
+###   target_port: 80
+### ports:
+### - description: port forward
+###   protocol: tcp
+###   listen_port: 80,81,8080-8090
+###   target_backend:
+###   - backend1
+###   - backend2
+###
+### Note that the listen_address cannot be changed.`)
+}
+
+func (c *cmdNetworkLoadBalancerEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network load-balancer show` command to passed in here, but only take the
+		// contents of the NetworkLoadBalancerPut fields when updating. The other fields are silently discarded.
+		newData := api.NetworkLoadBalancer{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateNetworkLoadBalancer(resource.name, args[1], newData.Writable(), "")
+	}
+
+	// Get the current config.
+	loadBalancer, etag, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&loadBalancer)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkLoadBalancer{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			err = resource.server.UpdateNetworkLoadBalancer(resource.name, loadBalancer.ListenAddress, newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkLoadBalancerDelete struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <listen_address>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network load balancers")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network load balancers"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Delete the network load balancer.
+	err = resource.server.DeleteNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network load balancer %s deleted")+"\n", args[1])
+	}
+
+	return nil
+}
+
+// Add/Remove Backend.
+type cmdNetworkLoadBalancerBackend struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+}
+
+func (c *cmdNetworkLoadBalancerBackend) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("backend")
+	cmd.Short = i18n.G("Manage network load balancer backends")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network load balancer backends"))
+
+	// Backend Add.
+	cmd.AddCommand(c.CommandAdd())
+
+	// Backend Remove.
+	cmd.AddCommand(c.CommandRemove())
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerBackend) CommandAdd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<network> <listen_address> <backend_name> <target_address> [<target_port(s)>]"))
+	cmd.Short = i18n.G("Add backends to a load balancer")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Add backends to a load balancer"))
+	cmd.RunE = c.RunAdd
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerBackend) RunAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 4, 5)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Get the network load balancer.
+	loadBalancer, etag, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	backend := api.NetworkLoadBalancerBackend{
+		Name:          args[2],
+		TargetAddress: args[3],
+	}
+
+	if len(args) > 4 {
+		backend.TargetPort = args[4]
+	}
+
+	loadBalancer.Backends = append(loadBalancer.Backends, backend)
+
+	return resource.server.UpdateNetworkLoadBalancer(resource.name, loadBalancer.ListenAddress, loadBalancer.Writable(), etag)
+}
+
+func (c *cmdNetworkLoadBalancerBackend) CommandRemove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<network> <listen_address> <backend_name>"))
+	cmd.Short = i18n.G("Remove backends from a load balancer")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove backends from a load balancer"))
+	cmd.RunE = c.RunRemove
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerBackend) RunRemove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Get the network load balancer.
+	loadBalancer, etag, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	removed := false
+	newBackends := make([]api.NetworkLoadBalancerBackend, 0, len(loadBalancer.Backends))
+	for _, backend := range loadBalancer.Backends {
+		if backend.Name == args[2] {
+			removed = true
+			continue // Don't add removed backend to newBackends.
+		}
+
+		newBackends = append(newBackends, backend)
+	}
+
+	if !removed {
+		return fmt.Errorf(i18n.G("No matching backend found"))
+	}
+
+	loadBalancer.Backends = newBackends
+
+	return resource.server.UpdateNetworkLoadBalancer(resource.name, loadBalancer.ListenAddress, loadBalancer.Writable(), etag)
+}
+
+// Add/Remove Port.
+type cmdNetworkLoadBalancerPort struct {
+	global              *cmdGlobal
+	networkLoadBalancer *cmdNetworkLoadBalancer
+	flagRemoveForce     bool
+}
+
+func (c *cmdNetworkLoadBalancerPort) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("port")
+	cmd.Short = i18n.G("Manage network load balancer ports")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network load balancer ports"))
+
+	// Port Add.
+	cmd.AddCommand(c.CommandAdd())
+
+	// Port Remove.
+	cmd.AddCommand(c.CommandRemove())
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerPort) CommandAdd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<network> <listen_address> <protocol> <listen_port(s)> <backend_name>[,<backend_name>...]"))
+	cmd.Short = i18n.G("Add ports to a load balancer")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Add ports to a load balancer"))
+	cmd.RunE = c.RunAdd
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerPort) RunAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 5, 5)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Get the network load balancer.
+	loadBalancer, etag, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	port := api.NetworkLoadBalancerPort{
+		Protocol:      args[2],
+		ListenPort:    args[3],
+		TargetBackend: []string{},
+	}
+
+	for _, backendName := range strings.Split(args[4], ",") {
+		port.TargetBackend = append(port.TargetBackend, strings.TrimSpace(backendName))
+	}
+
+	loadBalancer.Ports = append(loadBalancer.Ports, port)
+
+	return resource.server.UpdateNetworkLoadBalancer(resource.name, loadBalancer.ListenAddress, loadBalancer.Writable(), etag)
+}
+
+func (c *cmdNetworkLoadBalancerPort) CommandRemove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<network> <listen_address> [<protocol>] [<listen_port(s)>]"))
+	cmd.Short = i18n.G("Remove ports from a load balancer")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove ports from a load balancer"))
+	cmd.Flags().BoolVar(&c.flagRemoveForce, "force", false, i18n.G("Remove all ports that match"))
+	cmd.RunE = c.RunRemove
+
+	return cmd
+}
+
+func (c *cmdNetworkLoadBalancerPort) RunRemove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 4)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Get the network load balancer.
+	loadBalancer, etag, err := resource.server.GetNetworkLoadBalancer(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	// isFilterMatch returns whether the supplied port has matching field values in the filter arguments.
+	isFilterMatch := func(port *api.NetworkLoadBalancerPort) bool {
+		if len(args) > 2 && port.Protocol != args[2] {
+			return false
+		}
+
+		if len(args) > 3 && port.ListenPort != args[3] {
+			return false
+		}
+
+		return true
+	}
+
+	removed := false
+	newPorts := make([]api.NetworkLoadBalancerPort, 0, len(loadBalancer.Ports))
+	for _, port := range loadBalancer.Ports {
+		if isFilterMatch(&port) {
+			if removed && !c.flagRemoveForce {
+				return fmt.Errorf(i18n.G("Multiple ports match. Use --force to remove them all"))
+			}
+
+			removed = true
+			continue // Don't add removed port to newPorts.
+		}
+
+		newPorts = append(newPorts, port)
+	}
+
+	if !removed {
+		return fmt.Errorf(i18n.G("No matching port(s) found"))
+	}
+
+	loadBalancer.Ports = newPorts
+
+	return resource.server.UpdateNetworkLoadBalancer(resource.name, loadBalancer.ListenAddress, loadBalancer.Writable(), etag)
+}
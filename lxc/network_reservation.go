@@ -0,0 +1,603 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/termios"
+)
+
+type cmdNetworkReservation struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkReservation) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("reservation")
+	cmd.Short = i18n.G("Manage network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network DHCP reservations"))
+
+	// List.
+	networkReservationListCmd := cmdNetworkReservationList{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationListCmd.Command())
+
+	// Show.
+	networkReservationShowCmd := cmdNetworkReservationShow{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationShowCmd.Command())
+
+	// Create.
+	networkReservationCreateCmd := cmdNetworkReservationCreate{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationCreateCmd.Command())
+
+	// Get.
+	networkReservationGetCmd := cmdNetworkReservationGet{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationGetCmd.Command())
+
+	// Set.
+	networkReservationSetCmd := cmdNetworkReservationSet{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationSetCmd.Command())
+
+	// Unset.
+	networkReservationUnsetCmd := cmdNetworkReservationUnset{global: c.global, networkReservation: c, networkReservationSet: &networkReservationSetCmd}
+	cmd.AddCommand(networkReservationUnsetCmd.Command())
+
+	// Edit.
+	networkReservationEditCmd := cmdNetworkReservationEdit{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationEditCmd.Command())
+
+	// Delete.
+	networkReservationDeleteCmd := cmdNetworkReservationDelete{global: c.global, networkReservation: c}
+	cmd.AddCommand(networkReservationDeleteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+	return cmd
+}
+
+// networkReservationSetField sets a DHCP reservation field by its key.
+func networkReservationSetField(reservation *api.NetworkReservationPut, key string, value string) error {
+	switch key {
+	case "description":
+		reservation.Description = value
+	case "ipv4_address":
+		reservation.IPv4Address = value
+	case "ipv6_address":
+		reservation.IPv6Address = value
+	case "hostname":
+		reservation.Hostname = value
+	default:
+		return fmt.Errorf(i18n.G("Invalid key %q, must be one of description, ipv4_address, ipv6_address or hostname"), key)
+	}
+
+	return nil
+}
+
+// List.
+type cmdNetworkReservationList struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+
+	flagFormat string
+}
+
+func (c *cmdNetworkReservationList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network DHCP reservations"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	reservations, err := resource.server.GetNetworkReservations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, reservation := range reservations {
+		details := []string{
+			reservation.Hwaddr,
+			reservation.IPv4Address,
+			reservation.IPv6Address,
+			reservation.Hostname,
+			reservation.Description,
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(byName(data))
+
+	header := []string{
+		i18n.G("HWADDR"),
+		i18n.G("IPV4"),
+		i18n.G("IPV6"),
+		i18n.G("HOSTNAME"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, reservations)
+}
+
+// Show.
+type cmdNetworkReservationShow struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <hwaddr>"))
+	cmd.Short = i18n.G("Show network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network DHCP reservations"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Show the network DHCP reservation.
+	reservation, _, err := resource.server.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&reservation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkReservationCreate struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <hwaddr> [key=value...]"))
+	cmd.Short = i18n.G("Create new network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create new network DHCP reservations
+
+The hardware address is either a MAC address or a DHCPv6 DUID prefixed with "id:".
+The valid keys are description, ipv4_address, ipv6_address and hostname.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc network reservation create lxdbr0 00:16:3e:2c:89:d9 ipv4_address=10.0.0.50 hostname=printer
+    Reserve 10.0.0.50 for the given MAC address on lxdbr0.`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var reservationPut api.NetworkReservationPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &reservationPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network DHCP reservation.
+	reservation := api.NetworkReservationsPost{
+		Hwaddr:                args[1],
+		NetworkReservationPut: reservationPut,
+	}
+
+	for _, arg := range args[2:] {
+		entry := strings.SplitN(arg, "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), arg)
+		}
+
+		err = networkReservationSetField(&reservation.NetworkReservationPut, entry[0], entry[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = resource.server.CreateNetworkReservation(resource.name, reservation)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network DHCP reservation %s created")+"\n", reservation.Hwaddr)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdNetworkReservationGet struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<network> <hwaddr> <key>"))
+	cmd.Short = i18n.G("Get values for network DHCP reservation keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network DHCP reservation keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	reservation, _, err := resource.server.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	switch args[2] {
+	case "description":
+		fmt.Printf("%s\n", reservation.Description)
+	case "ipv4_address":
+		fmt.Printf("%s\n", reservation.IPv4Address)
+	case "ipv6_address":
+		fmt.Printf("%s\n", reservation.IPv6Address)
+	case "hostname":
+		fmt.Printf("%s\n", reservation.Hostname)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkReservationSet struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<network> <hwaddr> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network DHCP reservation keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set network DHCP reservation keys"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Get the network DHCP reservation.
+	reservation, etag, err := resource.server.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[2:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := reservation.Writable()
+	for k, v := range keys {
+		err = networkReservationSetField(&writable, k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return resource.server.UpdateNetworkReservation(resource.name, reservation.Hwaddr, writable, etag)
+}
+
+// Unset.
+type cmdNetworkReservationUnset struct {
+	global                *cmdGlobal
+	networkReservation    *cmdNetworkReservation
+	networkReservationSet *cmdNetworkReservationSet
+}
+
+func (c *cmdNetworkReservationUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<network> <hwaddr> <key>"))
+	cmd.Short = i18n.G("Unset network DHCP reservation keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network DHCP reservation keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	args = append(args, "")
+	return c.networkReservationSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkReservationEdit struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<network> <hwaddr>"))
+	cmd.Short = i18n.G("Edit network DHCP reservations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network DHCP reservations as YAML"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network DHCP reservation.
+### Any line starting with a '# will be ignored.
+###
+### A network DHCP reservation maps a MAC address (or DHCPv6 DUID) to fixed addresses.
+###
+### An example would look like:
+### hwaddr: 00:16:3e:2c:89:d9
+### description: Office printer
+### ipv4_address: 10.0.0.50
+### ipv6_address: fd42:4242:4242:1010::50
+### hostname: printer
+###
+### Note that the hwaddr cannot be changed.`)
+}
+
+func (c *cmdNetworkReservationEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network reservation show` command to passed in here, but only take the
+		// contents of the NetworkReservationPut fields when updating. The other fields are silently discarded.
+		newData := api.NetworkReservation{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateNetworkReservation(resource.name, args[1], newData.Writable(), "")
+	}
+
+	// Get the current config.
+	reservation, etag, err := resource.server.GetNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&reservation)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkReservation{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			err = resource.server.UpdateNetworkReservation(resource.name, reservation.Hwaddr, newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkReservationDelete struct {
+	global             *cmdGlobal
+	networkReservation *cmdNetworkReservation
+}
+
+func (c *cmdNetworkReservationDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <hwaddr>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network DHCP reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network DHCP reservations"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReservationDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// Delete the network DHCP reservation.
+	err = resource.server.DeleteNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network DHCP reservation %s deleted")+"\n", args[1])
+	}
+
+	return nil
+}
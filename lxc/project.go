@@ -42,10 +42,18 @@ func (c *cmdProject) Command() *cobra.Command {
 	projectEditCmd := cmdProjectEdit{global: c.global, project: c}
 	cmd.AddCommand(projectEditCmd.Command())
 
+	// Export
+	projectExportCmd := cmdProjectExport{global: c.global, project: c}
+	cmd.AddCommand(projectExportCmd.Command())
+
 	// Get
 	projectGetCmd := cmdProjectGet{global: c.global, project: c}
 	cmd.AddCommand(projectGetCmd.Command())
 
+	// Import
+	projectImportCmd := cmdProjectImport{global: c.global, project: c}
+	cmd.AddCommand(projectImportCmd.Command())
+
 	// List
 	projectListCmd := cmdProjectList{global: c.global, project: c}
 	cmd.AddCommand(projectListCmd.Command())
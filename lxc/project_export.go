@@ -0,0 +1,801 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+// projectBundleIndex is the index of a project bundle, describing the project and the entities it contains.
+// The index is the first file of the bundle and is followed by the custom volume and instance backups.
+type projectBundleIndex struct {
+	Project     api.Project           `yaml:"project"`
+	Profiles    []api.Profile         `yaml:"profiles,omitempty"`
+	NetworkACLs []api.NetworkACL      `yaml:"network_acls,omitempty"`
+	Networks    []api.Network         `yaml:"networks,omitempty"`
+	Volumes     []projectBundleVolume `yaml:"volumes,omitempty"`
+	Instances   []api.Instance        `yaml:"instances,omitempty"`
+}
+
+// projectBundleVolume is a custom volume of a project bundle.
+type projectBundleVolume struct {
+	api.StorageVolume `yaml:",inline"`
+	Pool              string `yaml:"pool"`
+}
+
+// projectBundleBackupConfig is the content of the backup.yaml file of instance backups (also embedded in their
+// index.yaml file).
+type projectBundleBackupConfig struct {
+	Container       *api.Instance                `yaml:"container,omitempty"`
+	Snapshots       []*api.InstanceSnapshot      `yaml:"snapshots,omitempty"`
+	Pool            *api.StoragePool             `yaml:"pool,omitempty"`
+	Volume          *api.StorageVolume           `yaml:"volume,omitempty"`
+	VolumeSnapshots []*api.StorageVolumeSnapshot `yaml:"volume_snapshots,omitempty"`
+}
+
+const projectBundleIndexFile = "index.yaml"
+
+// projectFeature returns whether the project has its own entities for the feature (e.g. "networks").
+func projectFeature(project *api.Project, feature string) bool {
+	return project.Name == "default" || shared.IsTrue(project.Config[fmt.Sprintf("features.%s", feature)])
+}
+
+// Export
+type cmdProjectExport struct {
+	global  *cmdGlobal
+	project *cmdProject
+
+	flagInstanceOnly bool
+}
+
+func (c *cmdProjectExport) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export", i18n.G("[<remote>:]<project> [<path>]"))
+	cmd.Short = i18n.G("Export projects as a bundle")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Export projects as a bundle
+
+The bundle contains the project configuration, its profiles, networks and network ACLs (when
+the project has its own) as well as backups of its custom storage volumes and instances.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc project export tenant1 /tmp/tenant1.tar.gz
+    Export the "tenant1" project to /tmp/tenant1.tar.gz.`))
+
+	cmd.Flags().BoolVar(&c.flagInstanceOnly, "instance-only", false, i18n.G("Whether or not to only backup the instances and volumes (without snapshots)"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdProjectExport) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing project name"))
+	}
+
+	project, _, err := resource.server.GetProject(resource.name)
+	if err != nil {
+		return err
+	}
+
+	d := resource.server.UseProject(project.Name)
+
+	// Build the index.
+	index := projectBundleIndex{Project: *project}
+
+	if projectFeature(project, "profiles") {
+		index.Profiles, err = d.GetProfiles()
+		if err != nil {
+			return err
+		}
+	}
+
+	if projectFeature(project, "networks") {
+		index.NetworkACLs, err = d.GetNetworkACLs()
+		if err != nil {
+			return err
+		}
+
+		networks, err := d.GetNetworks()
+		if err != nil {
+			return err
+		}
+
+		for _, network := range networks {
+			if network.Managed {
+				index.Networks = append(index.Networks, network)
+			}
+		}
+	}
+
+	if projectFeature(project, "storage.volumes") {
+		pools, err := d.GetStoragePoolNames()
+		if err != nil {
+			return err
+		}
+
+		for _, pool := range pools {
+			volumes, err := d.GetStoragePoolVolumes(pool)
+			if err != nil {
+				return err
+			}
+
+			for _, volume := range volumes {
+				if volume.Type != "custom" || strings.Contains(volume.Name, "/") {
+					continue
+				}
+
+				index.Volumes = append(index.Volumes, projectBundleVolume{StorageVolume: volume, Pool: pool})
+			}
+		}
+	}
+
+	index.Instances, err = d.GetInstances(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	// Create the bundle.
+	targetName := fmt.Sprintf("%s.tar.gz", project.Name)
+	if len(args) > 1 {
+		targetName = args[1]
+	}
+
+	target, err := os.Create(shared.HostPathFollow(targetName))
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	err = c.writeBundle(d, target, &index)
+	if err != nil {
+		target.Close()
+		os.Remove(targetName)
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Project %s exported to %s")+"\n", project.Name, targetName)
+	}
+
+	return nil
+}
+
+// writeBundle writes the index and the backups of the volumes and instances to the bundle.
+func (c *cmdProjectExport) writeBundle(d lxd.InstanceServer, target io.Writer, index *projectBundleIndex) error {
+	gzWriter := gzip.NewWriter(target)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	data, err := yaml.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{Name: projectBundleIndexFile, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(data)
+	if err != nil {
+		return err
+	}
+
+	// Backups are uncompressed and not optimized so that their references can be rewritten on import.
+	for _, volume := range index.Volumes {
+		req := api.StoragePoolVolumeBackupsPost{
+			ExpiresAt:            time.Now().Add(24 * time.Hour),
+			VolumeOnly:           c.flagInstanceOnly,
+			CompressionAlgorithm: "none",
+		}
+
+		op, err := d.CreateStoragePoolVolumeBackup(volume.Pool, volume.Name, req)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create backup of storage volume %q", volume.Name)
+		}
+
+		name := path.Join("volumes", volume.Pool, fmt.Sprintf("%s.tar", volume.Name))
+		err = c.addBackup(tarWriter, name, op, i18n.G("Backing up storage volume: %s"), func(backupName string, req *lxd.BackupFileRequest) error {
+			_, err := d.GetStoragePoolVolumeBackupFile(volume.Pool, volume.Name, backupName, req)
+			return err
+		}, func(backupName string) {
+			op, err := d.DeleteStoragePoolVolumeBackup(volume.Pool, volume.Name, backupName)
+			if err == nil {
+				op.Wait()
+			}
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to export storage volume %q", volume.Name)
+		}
+	}
+
+	for _, inst := range index.Instances {
+		req := api.InstanceBackupsPost{
+			ExpiresAt:            time.Now().Add(24 * time.Hour),
+			InstanceOnly:         c.flagInstanceOnly,
+			CompressionAlgorithm: "none",
+		}
+
+		op, err := d.CreateInstanceBackup(inst.Name, req)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create backup of instance %q", inst.Name)
+		}
+
+		name := path.Join("instances", fmt.Sprintf("%s.tar", inst.Name))
+		err = c.addBackup(tarWriter, name, op, i18n.G("Backing up instance: %s"), func(backupName string, req *lxd.BackupFileRequest) error {
+			_, err := d.GetInstanceBackupFile(inst.Name, backupName, req)
+			return err
+		}, func(backupName string) {
+			op, err := d.DeleteInstanceBackup(inst.Name, backupName)
+			if err == nil {
+				op.Wait()
+			}
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to export instance %q", inst.Name)
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzWriter.Close()
+}
+
+// addBackup waits for the backup operation, downloads the backup and adds it to the bundle under the given name.
+func (c *cmdProjectExport) addBackup(tarWriter *tar.Writer, name string, op lxd.Operation, format string, download func(string, *lxd.BackupFileRequest) error, remove func(string)) error {
+	// Watch the background operation
+	progress := utils.ProgressRenderer{
+		Format: format,
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err := op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait until backup is done
+	err = utils.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+	progress.Done("")
+
+	// Get name of backup
+	backupName := strings.TrimPrefix(op.Get().Resources["backups"][0], "/1.0/backups/")
+	defer remove(backupName)
+
+	// The size of the backup must be known before adding it to the bundle, so download it to a temporary file.
+	tmpFile, err := ioutil.TempFile("", "lxc_project_export_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	progress = utils.ProgressRenderer{
+		Format: i18n.G("Exporting the backup: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	err = download(backupName, &lxd.BackupFileRequest{
+		BackupFile:      io.WriteSeeker(tmpFile),
+		ProgressHandler: progress.UpdateProgress,
+	})
+	progress.Done("")
+	if err != nil {
+		return err
+	}
+
+	size, err := tmpFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	_, err = tmpFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: size, ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(tarWriter, tmpFile, size)
+	return err
+}
+
+// Import
+type cmdProjectImport struct {
+	global  *cmdGlobal
+	project *cmdProject
+
+	flagStorage  string
+	flagNetworks []string
+	flagProfiles []string
+}
+
+func (c *cmdProjectImport) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("import", i18n.G("[<remote>:]<path> [<project>]"))
+	cmd.Short = i18n.G("Import projects from a bundle")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Import projects from a bundle
+
+The project is created (using the name from the bundle unless another one is given) along with its
+profiles, networks, network ACLs, custom storage volumes and instances.
+
+References to networks and profiles that aren't part of the bundle (such as a bridge of the default
+project) can be rewritten using --network and --profile.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc project import /tmp/tenant1.tar.gz tenant2
+    Import the bundle as the "tenant2" project.
+
+lxc project import /tmp/tenant1.tar.gz --network lxdbr0=br-tenants --storage fast
+    Import the bundle, connecting instances to "br-tenants" instead of "lxdbr0" and storing everything on the "fast" pool.`))
+
+	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", i18n.G("Storage pool name")+"``")
+	cmd.Flags().StringArrayVar(&c.flagNetworks, "network", nil, i18n.G("Network to use instead of the one referenced in the bundle (<old>=<new>)")+"``")
+	cmd.Flags().StringArrayVar(&c.flagProfiles, "profile", nil, i18n.G("Profile to use instead of the one referenced in the bundle (<old>=<new>)")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdProjectImport) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 3)
+	if exit {
+		return err
+	}
+
+	srcFilePosition := 0
+
+	// Parse remote (identify 1st argument is remote by looking for a colon at the end).
+	remote := ""
+	if len(args) > 1 && strings.HasSuffix(args[0], ":") {
+		remote = args[0]
+		srcFilePosition = 1
+	}
+
+	if len(args) <= srcFilePosition {
+		return fmt.Errorf(i18n.G("Missing bundle path"))
+	}
+
+	srcFile := args[srcFilePosition]
+
+	projectName := ""
+	if len(args) >= srcFilePosition+2 {
+		projectName = args[srcFilePosition+1]
+	}
+
+	mapping := projectImportMapping{
+		networks: map[string]string{},
+		profiles: map[string]string{},
+		pool:     c.flagStorage,
+	}
+
+	for _, entry := range c.flagNetworks {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 {
+			return fmt.Errorf(i18n.G("Bad key=value pair: %s"), entry)
+		}
+
+		mapping.networks[fields[0]] = fields[1]
+	}
+
+	for _, entry := range c.flagProfiles {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) != 2 {
+			return fmt.Errorf(i18n.G("Bad key=value pair: %s"), entry)
+		}
+
+		mapping.profiles[fields[0]] = fields[1]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	file, err := os.Open(shared.HostPathFollow(srcFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	tarReader := tar.NewReader(gzReader)
+
+	// Read the index.
+	hdr, err := tarReader.Next()
+	if err != nil || hdr.Name != projectBundleIndexFile {
+		return fmt.Errorf(i18n.G("Invalid project bundle, %s is missing"), projectBundleIndexFile)
+	}
+
+	index := projectBundleIndex{}
+	err = yaml.NewDecoder(tarReader).Decode(&index)
+	if err != nil {
+		return errors.Wrapf(err, "Failed parsing %s", projectBundleIndexFile)
+	}
+
+	if projectName == "" {
+		projectName = index.Project.Name
+	}
+
+	// Remove whatever was created if the import fails, in the reverse order of creation so that the project is
+	// empty by the time it is deleted.
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the project and the entities of the index.
+	err = resource.server.CreateProject(api.ProjectsPost{Name: projectName, ProjectPut: index.Project.Writable()})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { resource.server.DeleteProject(projectName) })
+
+	d := resource.server.UseProject(projectName)
+
+	for _, acl := range index.NetworkACLs {
+		aclName := acl.Name
+		err = d.CreateNetworkACL(api.NetworkACLsPost{NetworkACLPost: api.NetworkACLPost{Name: aclName}, NetworkACLPut: acl.Writable()})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create network ACL %q", aclName)
+		}
+
+		revert.Add(func() { d.DeleteNetworkACL(aclName) })
+	}
+
+	for _, network := range index.Networks {
+		put := network.Writable()
+		mapping.networkConfig(put.Config)
+
+		networkName := network.Name
+		err = d.CreateNetwork(api.NetworksPost{Name: networkName, Type: network.Type, NetworkPut: put})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create network %q", networkName)
+		}
+
+		revert.Add(func() { d.DeleteNetwork(networkName) })
+	}
+
+	for _, profile := range index.Profiles {
+		put := profile.Writable()
+		mapping.devices(put.Devices)
+
+		// The default profile is created along with the project (and deleted along with it).
+		profileName := profile.Name
+		if profileName == "default" {
+			err = d.UpdateProfile(profileName, put, "")
+		} else {
+			err = d.CreateProfile(api.ProfilesPost{Name: profileName, ProfilePut: put})
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create profile %q", profileName)
+		}
+
+		if profileName != "default" {
+			revert.Add(func() { d.DeleteProfile(profileName) })
+		}
+	}
+
+	// Import the backups.
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(path.Base(hdr.Name), ".tar")
+
+		switch path.Dir(hdr.Name) {
+		case "instances":
+			err = c.importInstance(d, name, tarReader, &mapping)
+			if err != nil {
+				return err
+			}
+
+			revert.Add(func() {
+				op, err := d.DeleteInstance(name)
+				if err == nil {
+					op.Wait()
+				}
+			})
+		default:
+			pool := path.Base(path.Dir(hdr.Name))
+			if mapping.pool != "" {
+				pool = mapping.pool
+			}
+
+			err = c.importVolume(d, pool, name, tarReader)
+			if err != nil {
+				return err
+			}
+
+			revert.Add(func() { d.DeleteStoragePoolVolume(pool, "custom", name) })
+		}
+	}
+
+	revert.Success()
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Project %s imported")+"\n", projectName)
+	}
+
+	return nil
+}
+
+// importVolume creates the custom volume from its backup.
+func (c *cmdProjectImport) importVolume(d lxd.InstanceServer, pool string, name string, backup io.Reader) error {
+	progress := utils.ProgressRenderer{
+		Format: fmt.Sprintf(i18n.G("Importing storage volume %s: %s"), name, "%s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	op, err := d.CreateStoragePoolVolumeFromBackup(pool, lxd.StoragePoolVolumeBackupArgs{BackupFile: backup, Name: name})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to import storage volume %q", name)
+	}
+
+	err = utils.CancelableWait(op, &progress)
+	progress.Done("")
+	if err != nil {
+		return errors.Wrapf(err, "Failed to import storage volume %q", name)
+	}
+
+	return nil
+}
+
+// importInstance creates the instance from its backup, rewriting the references of its config on the fly.
+func (c *cmdProjectImport) importInstance(d lxd.InstanceServer, name string, backup io.Reader, mapping *projectImportMapping) error {
+	progress := utils.ProgressRenderer{
+		Format: fmt.Sprintf(i18n.G("Importing instance %s: %s"), name, "%s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	reader, writer := io.Pipe()
+	rewriteErr := make(chan error, 1)
+	go func() {
+		err := mapping.backup(backup, writer)
+		writer.CloseWithError(err)
+		rewriteErr <- err
+	}()
+
+	op, err := d.CreateInstanceFromBackup(lxd.InstanceBackupArgs{BackupFile: reader, PoolName: mapping.pool, Name: name})
+	if err != nil {
+		reader.CloseWithError(err)
+		<-rewriteErr
+		return errors.Wrapf(err, "Failed to import instance %q", name)
+	}
+
+	err = <-rewriteErr
+	if err != nil {
+		return errors.Wrapf(err, "Failed to rewrite backup of instance %q", name)
+	}
+
+	err = utils.CancelableWait(op, &progress)
+	progress.Done("")
+	if err != nil {
+		return errors.Wrapf(err, "Failed to import instance %q", name)
+	}
+
+	return nil
+}
+
+// projectImportMapping rewrites the network, profile and storage pool references of the imported entities.
+type projectImportMapping struct {
+	networks map[string]string
+	profiles map[string]string
+	pool     string
+}
+
+// networkConfig rewrites the references to other networks (such as the uplink of OVN networks) in network config.
+func (m *projectImportMapping) networkConfig(config map[string]string) {
+	for _, key := range []string{"network", "parent"} {
+		newName, ok := m.networks[config[key]]
+		if ok {
+			config[key] = newName
+		}
+	}
+}
+
+// devices rewrites the network and storage pool references of the devices.
+func (m *projectImportMapping) devices(devices map[string]map[string]string) {
+	for _, device := range devices {
+		switch device["type"] {
+		case "nic":
+			for _, key := range []string{"network", "parent"} {
+				newName, ok := m.networks[device[key]]
+				if ok {
+					device[key] = newName
+				}
+			}
+
+		case "disk":
+			if m.pool != "" && device["pool"] != "" {
+				device["pool"] = m.pool
+			}
+		}
+	}
+}
+
+// profileNames rewrites the profile references of an instance.
+func (m *projectImportMapping) profileNames(profiles []string) []string {
+	result := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		newName, ok := m.profiles[profile]
+		if ok {
+			profile = newName
+		}
+
+		result = append(result, profile)
+	}
+
+	return result
+}
+
+// backupConfig rewrites the references of the instance and its snapshots in the backup config.
+func (m *projectImportMapping) backupConfig(config *projectBundleBackupConfig) {
+	if config.Container != nil {
+		m.devices(config.Container.Devices)
+		m.devices(config.Container.ExpandedDevices)
+		config.Container.Profiles = m.profileNames(config.Container.Profiles)
+	}
+
+	for _, snapshot := range config.Snapshots {
+		m.devices(snapshot.Devices)
+		m.devices(snapshot.ExpandedDevices)
+		snapshot.Profiles = m.profileNames(snapshot.Profiles)
+	}
+}
+
+// backupFile rewrites the backup.yaml file of an instance backup, or the config embedded in its index.yaml file.
+func (m *projectImportMapping) backupFile(name string, data []byte) ([]byte, error) {
+	if name == "backup/index.yaml" {
+		index := yaml.MapSlice{}
+		err := yaml.Unmarshal(data, &index)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, item := range index {
+			if item.Key != "config" {
+				continue
+			}
+
+			configData, err := yaml.Marshal(item.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			configData, err = m.backupFile("backup.yaml", configData)
+			if err != nil {
+				return nil, err
+			}
+
+			config := yaml.MapSlice{}
+			err = yaml.Unmarshal(configData, &config)
+			if err != nil {
+				return nil, err
+			}
+
+			index[i].Value = config
+		}
+
+		return yaml.Marshal(index)
+	}
+
+	config := projectBundleBackupConfig{}
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	m.backupConfig(&config)
+
+	return yaml.Marshal(&config)
+}
+
+// backup copies an (uncompressed) instance backup, rewriting the references in its backup.yaml and index.yaml files.
+func (m *projectImportMapping) backup(src io.Reader, dst io.Writer) error {
+	tarReader := tar.NewReader(src)
+	tarWriter := tar.NewWriter(dst)
+
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if hdr.Name != "backup/index.yaml" && !(path.Base(hdr.Name) == "backup.yaml" && strings.Count(hdr.Name, "/") == 2) {
+			err = tarWriter.WriteHeader(hdr)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(tarWriter, tarReader)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+
+		data, err = m.backupFile(hdr.Name, data)
+		if err != nil {
+			return errors.Wrapf(err, "Failed rewriting %q", hdr.Name)
+		}
+
+		hdr.Size = int64(len(data))
+		err = tarWriter.WriteHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		_, err = tarWriter.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tarWriter.Close()
+}
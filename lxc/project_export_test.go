@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v2"
+)
+
+type projectExportTestSuite struct {
+	suite.Suite
+}
+
+func TestProjectExportTestSuite(t *testing.T) {
+	suite.Run(t, new(projectExportTestSuite))
+}
+
+func (s *projectExportTestSuite) mapping() *projectImportMapping {
+	return &projectImportMapping{
+		networks: map[string]string{"lxdbr0": "br-tenants"},
+		profiles: map[string]string{"web": "web-tenants"},
+		pool:     "fast",
+	}
+}
+
+// The network, profile and pool references of the instance and its snapshots are rewritten in backup.yaml.
+func (s *projectExportTestSuite) Test_projectImportMapping_backupYAML() {
+	data := []byte(`container:
+  name: c1
+  profiles: [default, web]
+  devices:
+    eth0: {type: nic, network: lxdbr0, name: eth0}
+    eth1: {type: nic, network: other, name: eth1}
+    data: {type: disk, pool: default, source: data, path: /srv}
+snapshots:
+- name: snap0
+  profiles: [web]
+  devices:
+    eth0: {type: nic, network: lxdbr0, name: eth0}
+`)
+
+	data, err := s.mapping().backupFile("backup/container/backup.yaml", data)
+	s.Require().NoError(err)
+
+	config := projectBundleBackupConfig{}
+	s.Require().NoError(yaml.Unmarshal(data, &config))
+
+	s.Equal([]string{"default", "web-tenants"}, config.Container.Profiles)
+	s.Equal("br-tenants", config.Container.Devices["eth0"]["network"])
+	s.Equal("other", config.Container.Devices["eth1"]["network"])
+	s.Equal("fast", config.Container.Devices["data"]["pool"])
+	s.Equal([]string{"web-tenants"}, config.Snapshots[0].Profiles)
+	s.Equal("br-tenants", config.Snapshots[0].Devices["eth0"]["network"])
+}
+
+// The config embedded in index.yaml is rewritten while the other fields are kept.
+func (s *projectExportTestSuite) Test_projectImportMapping_indexYAML() {
+	data := []byte(`name: c1
+backend: zfs
+pool: default
+config:
+  container:
+    name: c1
+    profiles: [web]
+`)
+
+	data, err := s.mapping().backupFile("backup/index.yaml", data)
+	s.Require().NoError(err)
+
+	index := struct {
+		Name    string                    `yaml:"name"`
+		Backend string                    `yaml:"backend"`
+		Config  projectBundleBackupConfig `yaml:"config"`
+	}{}
+
+	s.Require().NoError(yaml.Unmarshal(data, &index))
+	s.Equal("c1", index.Name)
+	s.Equal("zfs", index.Backend)
+	s.Equal([]string{"web-tenants"}, index.Config.Container.Profiles)
+}
@@ -316,6 +316,7 @@ type cmdStorageVolumeCopy struct {
 	flagMode          string
 	flagVolumeOnly    bool
 	flagTargetProject string
+	flagResume        bool
 }
 
 func (c *cmdStorageVolumeCopy) Command() *cobra.Command {
@@ -330,6 +331,7 @@ func (c *cmdStorageVolumeCopy) Command() *cobra.Command {
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().BoolVar(&c.flagVolumeOnly, "volume-only", false, i18n.G("Copy the volume without its snapshots"))
 	cmd.Flags().StringVar(&c.flagTargetProject, "target-project", "", i18n.G("Copy to a project different from the source")+"``")
+	cmd.Flags().BoolVar(&c.flagResume, "resume", false, i18n.G("Resume an interrupted copy"))
 	cmd.RunE = c.Run
 
 	return cmd
@@ -381,6 +383,11 @@ func (c *cmdStorageVolumeCopy) Run(cmd *cobra.Command, args []string) error {
 		mode = c.flagMode
 	}
 
+	// Relay the transfer when the servers can't reach each other.
+	if srcResource.remote != dstResource.remote {
+		mode = migrationMode(mode, c.global.conf.Remotes[srcResource.remote].Addr, srcServer, c.global.conf.Remotes[dstResource.remote].Addr, dstServer)
+	}
+
 	if c.flagTargetProject != "" {
 		dstServer = dstServer.UseProject(c.flagTargetProject)
 	}
@@ -436,6 +443,7 @@ func (c *cmdStorageVolumeCopy) Run(cmd *cobra.Command, args []string) error {
 		args.Name = dstVolName
 		args.Mode = mode
 		args.VolumeOnly = c.flagVolumeOnly
+		args.Resume = c.flagResume
 
 		if isSnapshot {
 			srcVol.Name = srcVolName
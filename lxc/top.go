@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/units"
+)
+
+type cmdTop struct {
+	global *cmdGlobal
+
+	flagBatch    bool
+	flagCount    int
+	flagFormat   string
+	flagInterval int
+	flagSort     string
+}
+
+// topCounters holds the raw cumulative counters of an instance at a given time.
+type topCounters struct {
+	time      time.Time
+	cpu       int64
+	diskRead  int64
+	diskWrite int64
+	netRx     int64
+	netTx     int64
+}
+
+// topSample represents the resource usage of an instance over one sampling interval.
+type topSample struct {
+	Time      time.Time `json:"time" yaml:"time"`
+	Name      string    `json:"name" yaml:"name"`
+	Location  string    `json:"location,omitempty" yaml:"location,omitempty"`
+	Status    string    `json:"status" yaml:"status"`
+	CPU       float64   `json:"cpu" yaml:"cpu"`
+	Memory    int64     `json:"memory" yaml:"memory"`
+	DiskRead  int64     `json:"disk_read" yaml:"disk_read"`
+	DiskWrite int64     `json:"disk_write" yaml:"disk_write"`
+	NetRx     int64     `json:"network_received" yaml:"network_received"`
+	NetTx     int64     `json:"network_sent" yaml:"network_sent"`
+}
+
+func (c *cmdTop) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("top", i18n.G("[<remote>:] [<filter>...]"))
+	cmd.Short = i18n.G("Monitor instance resource usage")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Monitor instance resource usage
+
+Periodically samples the state of the instances and shows their CPU,
+memory, disk I/O and network usage. CPU usage is shown as a percentage
+of a single CPU, disk and network usage as rates per second.
+
+Filters are the same as for "lxc list".
+
+In batch mode, samples are printed in the requested format instead
+of refreshing the terminal, which is useful for scripting.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc top
+    Show the instances of the default remote, sorted by CPU usage.
+
+lxc top --sort=memory status=running
+    Show the running instances, sorted by memory usage.
+
+lxc top --batch --format=json --count=10 remote:
+    Print 10 samples of the instances on "remote" as JSON.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVarP(&c.flagBatch, "batch", "b", false, i18n.G("Print samples instead of refreshing the terminal"))
+	cmd.Flags().IntVarP(&c.flagCount, "count", "n", 0, i18n.G("Number of samples to take (0 for unlimited)")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "csv", i18n.G("Format for batch mode (csv|json)")+"``")
+	cmd.Flags().IntVarP(&c.flagInterval, "interval", "i", 2, i18n.G("Interval in seconds between samples")+"``")
+	cmd.Flags().StringVarP(&c.flagSort, "sort", "s", "cpu", i18n.G("Sort by (cpu|memory|disk|network)")+"``")
+
+	return cmd
+}
+
+func (c *cmdTop) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, -1)
+	if exit {
+		return err
+	}
+
+	if c.flagInterval < 1 {
+		return fmt.Errorf(i18n.G("Invalid interval %d, must be at least 1 second"), c.flagInterval)
+	}
+
+	if c.flagCount < 0 {
+		return fmt.Errorf(i18n.G("Invalid count %d"), c.flagCount)
+	}
+
+	if !shared.StringInSlice(c.flagSort, []string{"cpu", "memory", "disk", "network"}) {
+		return fmt.Errorf(i18n.G("Invalid sort key %q"), c.flagSort)
+	}
+
+	if c.flagBatch && !shared.StringInSlice(c.flagFormat, []string{utils.TableFormatCSV, utils.TableFormatJSON}) {
+		return fmt.Errorf(i18n.G("Invalid format %q"), c.flagFormat)
+	}
+
+	// Parse the remote
+	var remote string
+	var name string
+	var filters []string
+
+	if len(args) != 0 {
+		filters = args
+		if strings.Contains(args[0], ":") && !strings.Contains(args[0], "=") {
+			remote, name, err = conf.ParseRemote(args[0])
+			if err != nil {
+				return err
+			}
+
+			filters = args[1:]
+		} else if !strings.Contains(args[0], "=") {
+			remote = conf.DefaultRemote
+			name = args[0]
+		}
+	}
+
+	if name != "" {
+		filters = append(filters, name)
+	}
+
+	if remote == "" {
+		remote = conf.DefaultRemote
+	}
+
+	// Connect to LXD
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	if !d.HasExtension("container_full") {
+		return fmt.Errorf(i18n.G("The server doesn't support retrieving the full instance state"))
+	}
+
+	clustered := d.IsClustered()
+	list := cmdList{global: c.global}
+	interval := time.Duration(c.flagInterval) * time.Second
+
+	// Take the initial sample used as the base for the first deltas.
+	previous := map[string]topCounters{}
+	insts, err := d.GetInstancesFull(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range insts {
+		previous[inst.Name] = topCountersFromState(inst.State, time.Now())
+	}
+
+	for i := 0; c.flagCount == 0 || i < c.flagCount; i++ {
+		time.Sleep(interval)
+
+		insts, err := d.GetInstancesFull(api.InstanceTypeAny)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		current := map[string]topCounters{}
+		samples := []topSample{}
+		for _, inst := range insts {
+			counters := topCountersFromState(inst.State, now)
+			current[inst.Name] = counters
+
+			if !list.shouldShow(filters, &inst.Instance, inst.State, false) {
+				continue
+			}
+
+			sample := topSample{
+				Time:     now,
+				Name:     inst.Name,
+				Location: inst.Location,
+				Status:   inst.Status,
+			}
+
+			if inst.IsActive() && inst.State != nil {
+				sample.Memory = inst.State.Memory.Usage
+
+				prev, ok := previous[inst.Name]
+				if ok {
+					sample.computeRates(prev, counters)
+				}
+			}
+
+			samples = append(samples, sample)
+		}
+
+		previous = current
+		sortTopSamples(samples, c.flagSort)
+
+		err = c.render(samples, clustered)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render prints one round of samples, either as a refreshed table or in batch format.
+func (c *cmdTop) render(samples []topSample, clustered bool) error {
+	if c.flagBatch {
+		if c.flagFormat == utils.TableFormatJSON {
+			return utils.RenderTable(c.flagFormat, nil, nil, samples)
+		}
+
+		data := [][]string{}
+		for _, sample := range samples {
+			data = append(data, []string{
+				sample.Time.UTC().Format(time.RFC3339),
+				sample.Name,
+				sample.Location,
+				sample.Status,
+				fmt.Sprintf("%.2f", sample.CPU),
+				fmt.Sprintf("%d", sample.Memory),
+				fmt.Sprintf("%d", sample.DiskRead),
+				fmt.Sprintf("%d", sample.DiskWrite),
+				fmt.Sprintf("%d", sample.NetRx),
+				fmt.Sprintf("%d", sample.NetTx),
+			})
+		}
+
+		return utils.RenderTable(c.flagFormat, nil, data, samples)
+	}
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("STATE"),
+		i18n.G("CPU"),
+		i18n.G("MEMORY"),
+		i18n.G("DISK READ"),
+		i18n.G("DISK WRITE"),
+		i18n.G("NET RX"),
+		i18n.G("NET TX"),
+	}
+
+	if clustered {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	data := [][]string{}
+	for _, sample := range samples {
+		line := []string{sample.Name, strings.ToUpper(sample.Status), "", "", "", "", "", ""}
+		if sample.Memory > 0 {
+			line[2] = fmt.Sprintf("%.1f%%", sample.CPU)
+			line[3] = units.GetByteSizeString(sample.Memory, 2)
+			line[4] = topRateString(sample.DiskRead)
+			line[5] = topRateString(sample.DiskWrite)
+			line[6] = topRateString(sample.NetRx)
+			line[7] = topRateString(sample.NetTx)
+		}
+
+		if clustered {
+			line = append(line, sample.Location)
+		}
+
+		data = append(data, line)
+	}
+
+	// Clear the screen and move the cursor to the top left corner.
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf(i18n.G("%s - %d instances, sorted by %s")+"\n\n", time.Now().Format("15:04:05"), len(samples), c.flagSort)
+
+	return utils.RenderTable(utils.TableFormatTable, header, data, samples)
+}
+
+// topCountersFromState extracts the cumulative counters from an instance state.
+func topCountersFromState(state *api.InstanceState, now time.Time) topCounters {
+	counters := topCounters{time: now}
+	if state == nil {
+		return counters
+	}
+
+	counters.cpu = state.CPU.Usage
+
+	for _, disk := range state.Disk {
+		counters.diskRead += disk.ReadBytes
+		counters.diskWrite += disk.WriteBytes
+	}
+
+	for name, nic := range state.Network {
+		if nic.Type == "loopback" || name == "lo" {
+			continue
+		}
+
+		counters.netRx += nic.Counters.BytesReceived
+		counters.netTx += nic.Counters.BytesSent
+	}
+
+	return counters
+}
+
+// computeRates fills in the sample rates from two successive sets of counters.
+func (s *topSample) computeRates(prev topCounters, cur topCounters) {
+	elapsed := cur.time.Sub(prev.time).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	// Counters go backwards when an instance restarts, don't report negative rates.
+	rate := func(prev int64, cur int64) int64 {
+		if cur < prev {
+			return 0
+		}
+
+		return int64(float64(cur-prev) / elapsed)
+	}
+
+	if cur.cpu >= prev.cpu {
+		s.CPU = float64(cur.cpu-prev.cpu) / (elapsed * float64(time.Second)) * 100
+	}
+
+	s.DiskRead = rate(prev.diskRead, cur.diskRead)
+	s.DiskWrite = rate(prev.diskWrite, cur.diskWrite)
+	s.NetRx = rate(prev.netRx, cur.netRx)
+	s.NetTx = rate(prev.netTx, cur.netTx)
+}
+
+// sortTopSamples sorts the samples by decreasing usage of the given resource, then by name.
+func sortTopSamples(samples []topSample, key string) {
+	value := func(s topSample) float64 {
+		switch key {
+		case "memory":
+			return float64(s.Memory)
+		case "disk":
+			return float64(s.DiskRead + s.DiskWrite)
+		case "network":
+			return float64(s.NetRx + s.NetTx)
+		default:
+			return s.CPU
+		}
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		vi := value(samples[i])
+		vj := value(samples[j])
+		if vi != vj {
+			return vi > vj
+		}
+
+		return samples[i].Name < samples[j].Name
+	})
+}
+
+func topRateString(rate int64) string {
+	return fmt.Sprintf("%s/s", units.GetByteSizeString(rate, 2))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestTopComputeRates(t *testing.T) {
+	now := time.Now()
+
+	state := &api.InstanceState{
+		CPU: api.InstanceStateCPU{Usage: 1000000000},
+		Disk: map[string]api.InstanceStateDisk{
+			"root": {ReadBytes: 1000, WriteBytes: 2000},
+		},
+		Network: map[string]api.InstanceStateNetwork{
+			"eth0": {Type: "broadcast", Counters: api.InstanceStateNetworkCounters{BytesReceived: 4000, BytesSent: 8000}},
+			"lo":   {Type: "loopback", Counters: api.InstanceStateNetworkCounters{BytesReceived: 100000, BytesSent: 100000}},
+		},
+	}
+
+	prev := topCountersFromState(state, now)
+
+	state.CPU.Usage += 1000000000
+	state.Disk["root"] = api.InstanceStateDisk{ReadBytes: 3000, WriteBytes: 6000}
+	state.Network["eth0"] = api.InstanceStateNetwork{Type: "broadcast", Counters: api.InstanceStateNetworkCounters{BytesReceived: 8000, BytesSent: 16000}}
+
+	cur := topCountersFromState(state, now.Add(2*time.Second))
+
+	sample := topSample{}
+	sample.computeRates(prev, cur)
+
+	assert.Equal(t, float64(50), sample.CPU)
+	assert.Equal(t, int64(1000), sample.DiskRead)
+	assert.Equal(t, int64(2000), sample.DiskWrite)
+	assert.Equal(t, int64(2000), sample.NetRx)
+	assert.Equal(t, int64(4000), sample.NetTx)
+
+	// Counters going backwards (instance restart) don't produce negative rates.
+	sample = topSample{}
+	sample.computeRates(cur, prev)
+	assert.Equal(t, float64(0), sample.CPU)
+	assert.Equal(t, int64(0), sample.DiskRead)
+}
+
+func TestTopSort(t *testing.T) {
+	samples := []topSample{
+		{Name: "c1", CPU: 10, Memory: 300},
+		{Name: "c2", CPU: 50, Memory: 100},
+		{Name: "c3", CPU: 10, Memory: 200, NetRx: 10},
+	}
+
+	sortTopSamples(samples, "cpu")
+	assert.Equal(t, []string{"c2", "c1", "c3"}, []string{samples[0].Name, samples[1].Name, samples[2].Name})
+
+	sortTopSamples(samples, "memory")
+	assert.Equal(t, []string{"c1", "c3", "c2"}, []string{samples[0].Name, samples[1].Name, samples[2].Name})
+
+	sortTopSamples(samples, "network")
+	assert.Equal(t, "c3", samples[0].Name)
+}
@@ -258,6 +258,33 @@ func getConfig(args ...string) (map[string]string, error) {
 	return values, nil
 }
 
+// migrationMode returns the transfer mode to use between two servers. The transfer is relayed through the
+// client when either server is reached over SSH, as such servers are usually not reachable by others even if
+// listening on the network, or when the server which needs to be reached by the other one isn't listening
+// on the network at all.
+func migrationMode(mode string, sourceAddr string, source lxd.InstanceServer, destAddr string, dest lxd.InstanceServer) string {
+	var listener lxd.InstanceServer
+	switch mode {
+	case "pull":
+		listener = source
+	case "push":
+		listener = dest
+	default:
+		return mode
+	}
+
+	if strings.HasPrefix(sourceAddr, "ssh:") || strings.HasPrefix(destAddr, "ssh:") {
+		return "relay"
+	}
+
+	info, err := listener.GetConnectionInfo()
+	if err != nil || len(info.Addresses) > 0 {
+		return mode
+	}
+
+	return "relay"
+}
+
 func usage(name string, args ...string) string {
 	if len(args) == 0 {
 		return name
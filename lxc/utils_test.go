@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 
+	lxd "github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/shared/api"
 )
 
@@ -57,3 +58,29 @@ func (s *utilsTestSuite) TestGetExistingAliasesEmpty() {
 	aliases := GetExistingAliases([]string{"other1", "other2"}, images)
 	s.Exactly([]api.ImageAliasesEntry{}, aliases)
 }
+
+// migrationTestServer only implements the connection information used to pick the transfer mode.
+type migrationTestServer struct {
+	lxd.InstanceServer
+
+	addresses []string
+}
+
+func (m *migrationTestServer) GetConnectionInfo() (*lxd.ConnectionInfo, error) {
+	return &lxd.ConnectionInfo{Addresses: m.addresses}, nil
+}
+
+func (s *utilsTestSuite) Test_migrationMode() {
+	listening := &migrationTestServer{addresses: []string{"https://10.0.0.1:8443"}}
+	local := &migrationTestServer{}
+
+	s.Equal("pull", migrationMode("pull", "https://10.0.0.1:8443", listening, "unix://", local))
+	s.Equal("relay", migrationMode("push", "https://10.0.0.1:8443", listening, "unix://", local))
+	s.Equal("push", migrationMode("push", "unix://", local, "https://10.0.0.1:8443", listening))
+
+	// SSH remotes are always relayed to, even if they listen on the network.
+	s.Equal("relay", migrationMode("pull", "ssh://server", listening, "https://10.0.0.2:8443", listening))
+	s.Equal("relay", migrationMode("push", "https://10.0.0.2:8443", listening, "ssh://admin@server", listening))
+
+	s.Equal("relay", migrationMode("relay", "https://10.0.0.1:8443", listening, "https://10.0.0.2:8443", listening))
+}
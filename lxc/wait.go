@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+type cmdWait struct {
+	global *cmdGlobal
+
+	flagTimeout int
+}
+
+func (c *cmdWait) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("wait", i18n.G("[<remote>:]<instance> <condition>..."))
+	cmd.Short = i18n.G("Wait for instances to reach a condition")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Wait for instances to reach a condition
+
+The following conditions are supported:
+  - status=STATUS (e.g. status=running)
+  - ipv4 (a global IPv4 address is configured)
+  - ipv6 (a global IPv6 address is configured)
+  - agent (the agent of a virtual machine is ready)
+  - cloud-init (cloud-init has finished running)
+  - user.KEY or user.KEY=VALUE (a user configuration key is set)
+
+Setting user.KEY from inside the instance through /dev/lxd is only supported for containers
+(with security.devlxd.config enabled). For virtual machines, the key has to be set through
+the LXD API instead (e.g. with "lxc config set").
+
+When multiple conditions are passed, the command returns once all of them are met.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc wait c1 status=running ipv4
+    Wait for c1 to be running with an IPv4 address.
+
+lxc wait v1 agent cloud-init --timeout=300
+    Wait up to 5 minutes for the agent of v1 to be ready and cloud-init to be done.
+
+lxc wait c1 user.ready=true
+    Wait for a process in container c1 to set user.ready to true through /dev/lxd.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().IntVarP(&c.flagTimeout, "timeout", "t", -1, i18n.G("Maximum time to wait in seconds (-1 for no timeout)")+"``")
+
+	return cmd
+}
+
+func (c *cmdWait) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Connect to LXD
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(time.Duration(c.flagTimeout) * time.Second)
+
+	// Wait for the conditions one after the other, sharing the timeout.
+	for _, condition := range args[1:] {
+		timeout := -1
+		if c.flagTimeout >= 0 {
+			timeout = int(time.Until(deadline).Round(time.Second).Seconds())
+			if timeout < 0 {
+				timeout = 0
+			}
+		}
+
+		err = d.WaitInstance(name, condition, timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
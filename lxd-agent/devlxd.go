@@ -82,6 +82,11 @@ var devlxdConfigKeyGet = devLxdHandler{"/1.0/config/{key}", func(d *Daemon, w ht
 		return &devLxdResponse{"not authorized", http.StatusForbidden, "raw"}
 	}
 
+	// Setting config keys requires access to the host which the agent doesn't have.
+	if r.Method == "PUT" {
+		return &devLxdResponse{"not implemented", http.StatusNotImplemented, "raw"}
+	}
+
 	data, err := ioutil.ReadFile("instance-data")
 	if err != nil {
 		return &devLxdResponse{"internal server error", http.StatusInternalServerError, "raw"}
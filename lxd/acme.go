@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/acme"
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/node"
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/task"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// acmeIsLeader returns true if the local member is in charge of ACME certificate orders, that is if it is the
+// cluster leader or if the server isn't clustered.
+func acmeIsLeader(d *Daemon) (bool, string, error) {
+	localAddress, err := node.ClusterAddress(d.db)
+	if err != nil {
+		return false, "", err
+	}
+
+	leader, err := d.gateway.LeaderAddress()
+	if err != nil {
+		if errors.Cause(err) == cluster.ErrNodeIsNotClustered {
+			return true, "", nil
+		}
+
+		return false, "", err
+	}
+
+	return localAddress == leader, leader, nil
+}
+
+// acmeProvideChallenge serves the HTTP-01 challenge responses of ACME orders. As orders are run by the cluster
+// leader, challenge requests received by other members are forwarded to it.
+func acmeProvideChallenge(d *Daemon) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := mux.Vars(r)["token"]
+
+		keyAuth, ok := acme.ChallengeResponse(token)
+		if ok {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(keyAuth))
+			return
+		}
+
+		isLeader, leader, err := acmeIsLeader(d)
+		if err != nil || isLeader {
+			http.NotFound(w, r)
+			return
+		}
+
+		client, err := cluster.Connect(leader, d.endpoints.NetworkCert(), d.serverCert(), r, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		httpClient, err := client.GetHTTPClient()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		u := url.URL{Scheme: "https", Host: leader, Path: acme.ChallengePathPrefix + token}
+		resp, err := httpClient.Get(u.String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+	}
+}
+
+// autoRenewCertificateTask obtains or renews the server certificate through ACME when acme.domain is set.
+func autoRenewCertificateTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := autoRenewCertificate(ctx, d, false)
+		if err != nil {
+			logger.Error("Failed to renew server certificate", log.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Daily()
+}
+
+// autoRenewCertificate orders a new certificate for acme.domain if the current one isn't an ACME certificate
+// for that domain, expires soon or force is set. The new certificate replaces the network certificate of the
+// server, or of every member when clustered, without restarting.
+func autoRenewCertificate(ctx context.Context, d *Daemon, force bool) error {
+	var domain, email, caURL string
+
+	err := d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		config, err := cluster.ConfigLoad(tx)
+		if err != nil {
+			return err
+		}
+
+		domain, email, caURL = config.ACME()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if domain == "" {
+		return nil
+	}
+
+	// Only one member orders the certificate, it then gets distributed to the others.
+	isLeader, _, err := acmeIsLeader(d)
+	if err != nil {
+		return err
+	}
+
+	if !isLeader {
+		return nil
+	}
+
+	keypair := d.endpoints.NetworkCert().KeyPair()
+	if len(keypair.Certificate) > 0 && !force {
+		cert, err := x509.ParseCertificate(keypair.Certificate[0])
+		if err != nil {
+			return errors.Wrapf(err, "Failed parsing current server certificate")
+		}
+
+		if !acme.CertificateNeedsUpdate(domain, cert) {
+			return nil
+		}
+	}
+
+	opRun := func(op *operations.Operation) error {
+		accountKey, err := acme.AccountKey(filepath.Join(d.os.VarDir, "acme.key"))
+		if err != nil {
+			return err
+		}
+
+		certPEM, keyPEM, err := acme.ObtainCertificate(ctx, d.proxy, accountKey, domain, email, caURL)
+		if err != nil {
+			return err
+		}
+
+		clustered, err := cluster.Enabled(d.db)
+		if err != nil {
+			return err
+		}
+
+		if clustered {
+			req := api.ClusterCertificatePut{
+				ClusterCertificate:    string(certPEM),
+				ClusterCertificateKey: string(keyPEM),
+			}
+
+			err = updateClusterCertificate(d, nil, req)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = util.WriteCert(d.os.VarDir, "server", certPEM, keyPEM, nil)
+			if err != nil {
+				return err
+			}
+
+			cert, err := util.LoadCert(d.os.VarDir)
+			if err != nil {
+				return err
+			}
+
+			d.endpoints.NetworkUpdateCert(cert)
+		}
+
+		d.State().Events.SendLifecycle("", lifecycle.ClusterCertificateUpdated.Event("certificate", op.Requestor(), nil))
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(d.State(), "", operations.OperationClassTask, db.OperationRenewServerCertificate, nil, nil, opRun, nil, nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to start renew server certificate operation")
+	}
+
+	logger.Info("Renewing server certificate", log.Ctx{"domain": domain})
+
+	chOpErr, err := op.Run()
+	if err != nil {
+		return err
+	}
+
+	err = <-chOpErr
+	if err != nil {
+		return errors.Wrapf(err, "Failed renewing certificate for %q", domain)
+	}
+
+	logger.Info("Done renewing server certificate", log.Ctx{"domain": domain})
+
+	return nil
+}
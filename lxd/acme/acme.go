@@ -0,0 +1,230 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/acme"
+)
+
+// ChallengePathPrefix is the URL path under which HTTP-01 challenge responses are served.
+const ChallengePathPrefix = "/.well-known/acme-challenge/"
+
+// renewalPeriod is how long before expiry a certificate gets renewed.
+const renewalPeriod = 30 * 24 * time.Hour
+
+var challengesLock sync.Mutex
+var challenges = make(map[string]string)
+
+// ChallengeResponse returns the key authorization for a pending HTTP-01 challenge token.
+func ChallengeResponse(token string) (string, bool) {
+	challengesLock.Lock()
+	defer challengesLock.Unlock()
+
+	keyAuth, ok := challenges[token]
+
+	return keyAuth, ok
+}
+
+// challengeSet records the key authorization to serve for a challenge token.
+func challengeSet(token string, keyAuth string) {
+	challengesLock.Lock()
+	defer challengesLock.Unlock()
+
+	challenges[token] = keyAuth
+}
+
+// challengeClear forgets a challenge token once it has been validated (or has failed).
+func challengeClear(token string) {
+	challengesLock.Lock()
+	defer challengesLock.Unlock()
+
+	delete(challenges, token)
+}
+
+// CertificateNeedsUpdate returns true if the certificate is self-signed, isn't valid for the domain or expires
+// within the renewal period.
+func CertificateNeedsUpdate(domain string, cert *x509.Certificate) bool {
+	if bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return true
+	}
+
+	if cert.VerifyHostname(domain) != nil {
+		return true
+	}
+
+	return time.Now().Add(renewalPeriod).After(cert.NotAfter)
+}
+
+// AccountKey loads the ACME account key stored at path, generating and storing a new one if it doesn't exist.
+// Re-using the key across orders avoids registering a new account with the CA each time.
+func AccountKey(path string) (crypto.Signer, error) {
+	content, err := ioutil.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(content)
+		if block == nil || block.Type != "EC PRIVATE KEY" {
+			return nil, fmt.Errorf("Invalid ACME account key in %q", path)
+		}
+
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed parsing ACME account key in %q", path)
+		}
+
+		return key, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "Failed reading ACME account key")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed generating ACME account key")
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed encoding ACME account key")
+	}
+
+	err = ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed writing ACME account key")
+	}
+
+	return key, nil
+}
+
+// ObtainCertificate orders a certificate for domain from the ACME server at caURL using the HTTP-01 challenge,
+// which must be answered by serving ChallengeResponse() under ChallengePathPrefix on the domain.
+// The account key is registered with the CA if it isn't already.
+// Returns the PEM encoded certificate chain and private key.
+func ObtainCertificate(ctx context.Context, proxy func(req *http.Request) (*url.URL, error), accountKey crypto.Signer, domain string, email string, caURL string) ([]byte, []byte, error) {
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: caURL,
+		HTTPClient:   &http.Client{Transport: &http.Transport{Proxy: proxy}},
+	}
+
+	account := &acme.Account{}
+	if email != "" {
+		account.Contact = []string{fmt.Sprintf("mailto:%s", email)}
+	}
+
+	_, err := client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return nil, nil, errors.Wrapf(err, "Failed registering ACME account with %q", caURL)
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domain))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed creating ACME order for %q", domain)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		err = authorize(ctx, client, authzURL)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed waiting for ACME order for %q", domain)
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed generating certificate key")
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, certKey)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed creating certificate request")
+	}
+
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed retrieving certificate for %q", domain)
+	}
+
+	var certPEM bytes.Buffer
+	for _, block := range der {
+		err = pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: block})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed encoding certificate key")
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM.Bytes(), keyPEM, nil
+}
+
+// authorize completes the HTTP-01 challenge of a pending authorization.
+func authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return errors.Wrapf(err, "Failed getting ACME authorization")
+	}
+
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			challenge = c
+			break
+		}
+	}
+
+	if challenge == nil {
+		return fmt.Errorf("ACME server didn't offer an HTTP-01 challenge for %q", authz.Identifier.Value)
+	}
+
+	keyAuth, err := client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return errors.Wrapf(err, "Failed computing ACME challenge response")
+	}
+
+	challengeSet(challenge.Token, keyAuth)
+	defer challengeClear(challenge.Token)
+
+	_, err = client.Accept(ctx, challenge)
+	if err != nil {
+		return errors.Wrapf(err, "Failed accepting ACME challenge")
+	}
+
+	_, err = client.WaitAuthorization(ctx, authz.URI)
+	if err != nil {
+		return errors.Wrapf(err, "Failed ACME authorization for %q", authz.Identifier.Value)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCertificate returns a certificate for domain valid for the given duration, signed by a separate CA unless
+// selfSigned is set.
+func newCertificate(t *testing.T, domain string, validity time.Duration, selfSigned bool) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+	}
+
+	parent := template
+	parentKey := key
+
+	if !selfSigned {
+		parentKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		parent = &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "Test CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func TestCertificateNeedsUpdate(t *testing.T) {
+	cases := []struct {
+		name     string
+		cert     *x509.Certificate
+		expected bool
+	}{
+		{"valid", newCertificate(t, "lxd.example.com", 90*24*time.Hour, false), false},
+		{"expiring", newCertificate(t, "lxd.example.com", 10*24*time.Hour, false), true},
+		{"other domain", newCertificate(t, "other.example.com", 90*24*time.Hour, false), true},
+		{"self-signed", newCertificate(t, "lxd.example.com", 90*24*time.Hour, true), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, CertificateNeedsUpdate("lxd.example.com", c.cert))
+		})
+	}
+}
+
+// Challenge responses are only served while the challenge is pending.
+func TestChallengeResponse(t *testing.T) {
+	_, ok := ChallengeResponse("token")
+	assert.False(t, ok)
+
+	challengeSet("token", "token.thumbprint")
+
+	keyAuth, ok := ChallengeResponse("token")
+	assert.True(t, ok)
+	assert.Equal(t, "token.thumbprint", keyAuth)
+
+	challengeClear("token")
+
+	_, ok = ChallengeResponse("token")
+	assert.False(t, ok)
+}
+
+func TestAccountKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lxd-acme-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "acme.key")
+
+	key, err := AccountKey(path)
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+
+	// The same key is returned once it has been generated.
+	again, err := AccountKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, key.Public(), again.Public())
+
+	err = ioutil.WriteFile(path, []byte("garbage"), 0600)
+	require.NoError(t, err)
+
+	_, err = AccountKey(path)
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	log "github.com/lxc/lxd/shared/log15"
 
 	"github.com/gorilla/mux"
+	"github.com/lxc/lxd/lxd/acme"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/db"
@@ -68,6 +69,9 @@ func restServer(d *Daemon) *http.Server {
 		mux.HandleFunc(endpoint, f)
 	}
 
+	// ACME HTTP-01 challenge responses (must be reachable without authentication).
+	mux.HandleFunc(acme.ChallengePathPrefix+"{token}", acmeProvideChallenge(d))
+
 	for _, c := range api10 {
 		d.createCmd(mux, "1.0", c)
 
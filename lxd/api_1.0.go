@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -21,6 +22,7 @@ import (
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/osarch"
 	"github.com/lxc/lxd/shared/version"
@@ -57,6 +59,7 @@ var api10 = []APIEndpoint{
 	instanceSnapshotCmd,
 	instanceSnapshotsCmd,
 	instanceStateCmd,
+	instanceWaitCmd,
 	eventsCmd,
 	imageAliasCmd,
 	imageAliasesCmd,
@@ -71,6 +74,13 @@ var api10 = []APIEndpoint{
 	networkStateCmd,
 	networkACLCmd,
 	networkACLsCmd,
+	networkAddressSetCmd,
+	networkAddressSetsCmd,
+	networkAllocationsCmd,
+	networkLoadBalancerCmd,
+	networkLoadBalancersCmd,
+	networkReservationCmd,
+	networkReservationsCmd,
 	operationCmd,
 	operationsCmd,
 	operationWait,
@@ -662,9 +672,21 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 	maasChanged := false
 	candidChanged := false
 	rbacChanged := false
+	acmeChanged := false
+	acmeForce := false
+	bgpChanged := false
 
 	for key := range clusterChanged {
 		switch key {
+		case "acme.ca_url":
+			acmeForce = true
+			fallthrough
+		case "acme.domain":
+			fallthrough
+		case "acme.email":
+			acmeChanged = true
+		case "core.bgp_asn":
+			bgpChanged = true
 		case "core.https_trusted_proxy":
 			d.endpoints.NetworkUpdateTrustedProxy(clusterChanged[key])
 		case "core.proxy_http":
@@ -720,6 +742,16 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		maasChanged = true
 	}
 
+	_, ok = nodeChanged["core.bgp_address"]
+	if ok {
+		bgpChanged = true
+	}
+
+	_, ok = nodeChanged["core.bgp_routerid"]
+	if ok {
+		bgpChanged = true
+	}
+
 	value, ok := nodeChanged["core.https_address"]
 	if ok {
 		err := d.endpoints.NetworkUpdateAddress(value)
@@ -771,6 +803,17 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if bgpChanged {
+		address := nodeConfig.BGPAddress()
+		asn := clusterConfig.BGPASN()
+		routerID := nodeConfig.BGPRouterID()
+
+		err := d.bgp.Configure(address, uint32(asn), net.ParseIP(routerID))
+		if err != nil {
+			return errors.Wrapf(err, "Failed reconfiguring BGP")
+		}
+	}
+
 	if candidChanged {
 		apiURL, apiKey, expiry, domains := clusterConfig.CandidServer()
 		err := d.setupExternalAuthentication(apiURL, apiKey, expiry, domains)
@@ -779,6 +822,16 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if acmeChanged {
+		// Ordering the certificate can take a while, don't hold up the config update which is already saved.
+		go func() {
+			err := autoRenewCertificate(d.ctx, d, acmeForce)
+			if err != nil {
+				logger.Error("Failed to renew server certificate", log.Ctx{"err": err})
+			}
+		}()
+	}
+
 	if rbacChanged {
 		apiURL, apiKey, apiExpiry, agentURL, agentUsername, agentPrivateKey, agentPublicKey := clusterConfig.RBACServer()
 
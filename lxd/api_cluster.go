@@ -1680,29 +1680,40 @@ func clusterCertificatePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
-	certBytes := []byte(req.ClusterCertificate)
-	keyBytes := []byte(req.ClusterCertificateKey)
-
-	certBlock, _ := pem.Decode(certBytes)
+	certBlock, _ := pem.Decode([]byte(req.ClusterCertificate))
 	if certBlock == nil {
 		return response.BadRequest(fmt.Errorf("Certificate must be base64 encoded PEM certificate: %v", err))
 	}
 
-	keyBlock, _ := pem.Decode(keyBytes)
+	keyBlock, _ := pem.Decode([]byte(req.ClusterCertificateKey))
 	if keyBlock == nil {
 		return response.BadRequest(fmt.Errorf("Private key must be base64 encoded PEM key: %v", err))
 	}
 
+	err = updateClusterCertificate(d, r, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	d.State().Events.SendLifecycle(projectParam(r), lifecycle.ClusterCertificateUpdated.Event("certificate", requestor, nil))
+
+	return response.EmptySyncResponse
+}
+
+// updateClusterCertificate replaces the cluster certificate on all cluster members (unless r is a cluster
+// notification) and then on the local member, updating the network endpoint and gateway without restart.
+func updateClusterCertificate(d *Daemon, r *http.Request, req api.ClusterCertificatePut) error {
 	// First node forwards request to all other cluster nodes
-	if !isClusterNotification(r) {
+	if r == nil || !isClusterNotification(r) {
 		servers, err := d.gateway.NodeStore().Get(context.Background())
 		if err != nil {
-			return response.SmartError(err)
+			return err
 		}
 
 		localAddress, err := node.ClusterAddress(d.db)
 		if err != nil {
-			return response.SmartError(err)
+			return err
 		}
 
 		for _, server := range servers {
@@ -1712,35 +1723,32 @@ func clusterCertificatePut(d *Daemon, r *http.Request) response.Response {
 
 			client, err := cluster.Connect(server.Address, d.endpoints.NetworkCert(), d.serverCert(), r, true)
 			if err != nil {
-				return response.SmartError(err)
+				return err
 			}
 
 			err = client.UpdateClusterCertificate(req, "")
 			if err != nil {
-				return response.SmartError(err)
+				return err
 			}
 		}
 	}
 
-	err = util.WriteCert(d.os.VarDir, "cluster", certBytes, keyBytes, nil)
+	err := util.WriteCert(d.os.VarDir, "cluster", []byte(req.ClusterCertificate), []byte(req.ClusterCertificateKey), nil)
 	if err != nil {
-		return response.SmartError(err)
+		return err
 	}
 
 	// Get the new cluster certificate struct
 	cert, err := util.LoadClusterCert(d.os.VarDir)
 	if err != nil {
-		return response.SmartError(err)
+		return err
 	}
 
 	// Update the certificate on the network endpoint and gateway
 	d.endpoints.NetworkUpdateCert(cert)
 	d.gateway.NetworkUpdateCert(cert)
 
-	requestor := request.CreateRequestor(r)
-	d.State().Events.SendLifecycle(projectParam(r), lifecycle.ClusterCertificateUpdated.Event("certificate", requestor, nil))
-
-	return response.EmptySyncResponse
+	return nil
 }
 
 func internalClusterPostAccept(d *Daemon, r *http.Request) response.Response {
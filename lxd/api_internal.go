@@ -58,6 +58,8 @@ var apiInternal = []APIEndpoint{
 	internalImageRefreshCmd,
 	internalImageOptimizeCmd,
 	internalWarningCreateCmd,
+	internalRecoverValidateCmd,
+	internalRecoverImportCmd,
 }
 
 var internalShutdownCmd = APIEndpoint{
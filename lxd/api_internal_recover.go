@@ -0,0 +1,497 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/backup"
+	"github.com/lxc/lxd/lxd/db"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/state"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	storageDrivers "github.com/lxc/lxd/lxd/storage/drivers"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+var internalRecoverValidateCmd = APIEndpoint{
+	Path: "recover/validate",
+
+	Post: APIEndpointAction{Handler: internalRecoverValidate},
+}
+
+var internalRecoverImportCmd = APIEndpoint{
+	Path: "recover/import",
+
+	Post: APIEndpointAction{Handler: internalRecoverImport},
+}
+
+// internalRecoverValidatePost is used to initiate a recovery validation scan.
+type internalRecoverValidatePost struct {
+	Pools []api.StoragePoolsPost `json:"pools" yaml:"pools"`
+}
+
+// internalRecoverValidateVolume provides info about a missing volume that the recovery validation scan found.
+type internalRecoverValidateVolume struct {
+	Name          string `json:"name" yaml:"name"`                     // Name of volume.
+	Type          string `json:"type" yaml:"type"`                     // Same as Type from StorageVolumesPost (container, custom or virtual-machine).
+	SnapshotCount int    `json:"snapshot_count" yaml:"snapshot_count"` // Count of snapshots found for volume.
+	Project       string `json:"project" yaml:"project"`               // Project the volume belongs to.
+	Pool          string `json:"pool" yaml:"pool"`                     // Pool the volume belongs to.
+}
+
+// internalRecoverValidateResult returns the result of the validation scan.
+type internalRecoverValidateResult struct {
+	UnknownVolumes   []internalRecoverValidateVolume `json:"unknown_volumes" yaml:"unknown_volumes"`     // Volumes that could be imported.
+	DependencyErrors []string                        `json:"dependency_errors" yaml:"dependency_errors"` // Errors that are preventing import from proceeding.
+}
+
+// internalRecoverImportPost is used to initiate a recovery import.
+type internalRecoverImportPost struct {
+	Pools []api.StoragePoolsPost `json:"pools" yaml:"pools"`
+}
+
+// internalRecoverValidate validates the requested pools to be recovered.
+func internalRecoverValidate(d *Daemon, r *http.Request) response.Response {
+	// Parse the request.
+	req := &internalRecoverValidatePost{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	return internalRecoverScan(d.State(), req.Pools, true)
+}
+
+// internalRecoverImport performs the pool volume recovery.
+func internalRecoverImport(d *Daemon, r *http.Request) response.Response {
+	// Parse the request.
+	req := &internalRecoverImportPost{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	return internalRecoverScan(d.State(), req.Pools, false)
+}
+
+// internalRecoverScan provides the discovery and import functionality for both recovery validate and import steps.
+// It scans all existing pools as well as the user supplied pools (which may not be in the database yet) for
+// volumes that have no database record and checks that the projects, profiles and networks they depend on exist.
+// If validateOnly is false and no dependency errors were found, the missing pool, instance and volume records
+// are re-created.
+func internalRecoverScan(s *state.State, userPools []api.StoragePoolsPost, validateOnly bool) response.Response {
+	var err error
+	var projects map[string]*api.Project
+
+	// Retrieve all project info.
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		projectList, err := tx.GetProjects(db.ProjectFilter{})
+		if err != nil {
+			return err
+		}
+
+		projects = make(map[string]*api.Project, len(projectList))
+		for i := range projectList {
+			projects[projectList[i].Name] = &projectList[i]
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(errors.Wrapf(err, "Failed getting projects"))
+	}
+
+	// Cache of the profile and network names per project, loaded as needed.
+	projectProfiles := make(map[string][]string)
+	projectNetworks := make(map[string][]string)
+
+	// Get list of existing pools and add them to the list of pools to scan.
+	poolNames, err := s.Cluster.GetStoragePoolNames()
+	if err != nil && err != db.ErrNoSuchObject {
+		return response.SmartError(errors.Wrapf(err, "Failed getting existing pools"))
+	}
+
+	pools := make(map[string]storagePools.Pool)
+	for _, poolName := range poolNames {
+		pool, err := storagePools.GetPoolByName(s, poolName)
+		if err != nil {
+			return response.SmartError(errors.Wrapf(err, "Failed loading existing pool %q", poolName))
+		}
+
+		pools[poolName] = pool
+	}
+
+	// Load the user supplied pools that aren't in the database yet.
+	for _, p := range userPools {
+		pool, found := pools[p.Name]
+		if found {
+			if p.Driver != "" && p.Driver != pool.Driver().Info().Name {
+				return response.BadRequest(fmt.Errorf("Storage pool %q already exists with driver %q", p.Name, pool.Driver().Info().Name))
+			}
+
+			continue
+		}
+
+		if p.Driver == "" {
+			return response.BadRequest(fmt.Errorf("Storage pool %q doesn't exist and no driver was specified", p.Name))
+		}
+
+		poolInfo := &api.StoragePool{
+			StoragePoolPut: p.StoragePoolPut,
+			Name:           p.Name,
+			Driver:         p.Driver,
+		}
+
+		pool, err := storagePools.NewTemporary(s, poolInfo)
+		if err != nil {
+			return response.SmartError(errors.Wrapf(err, "Failed to initialise unknown pool %q", p.Name))
+		}
+
+		err = pool.Driver().Validate(poolInfo.Config)
+		if err != nil {
+			return response.SmartError(errors.Wrapf(err, "Failed config validation for unknown pool %q", p.Name))
+		}
+
+		// Try to mount the pool so that we can scan it for volumes.
+		ourMount, err := pool.Mount()
+		if err != nil {
+			return response.SmartError(errors.Wrapf(err, "Failed mounting pool %q", pool.Name()))
+		}
+
+		// Unmount pool when done if not existing in DB after function has finished.
+		// This way if we are dealing with an existing pool or have successfully created the DB record then
+		// we won't unmount it. As we should leave successfully imported pools mounted.
+		if ourMount {
+			defer func() {
+				cleanupPool := pools[pool.Name()]
+				if cleanupPool != nil && cleanupPool.ID() == storagePools.PoolIDTemporary {
+					cleanupPool.Unmount()
+				}
+			}()
+		}
+
+		pools[p.Name] = pool
+	}
+
+	res := internalRecoverValidateResult{}
+
+	// Unknown volumes found on each pool, keyed by pool name and then project name.
+	poolsProjectVols := make(map[string]map[string][]*backup.Config)
+
+	// Scan the pools for unknown volumes and check their dependencies.
+	for _, pool := range pools {
+		poolProjectVols, err := pool.ListUnknownVolumes(nil)
+		if err != nil {
+			if errors.Cause(err) == storageDrivers.ErrNotSupported {
+				continue // Ignore unsupported storage drivers.
+			}
+
+			return response.SmartError(errors.Wrapf(err, "Failed checking volumes on pool %q", pool.Name()))
+		}
+
+		poolsProjectVols[pool.Name()] = poolProjectVols
+
+		for projectName, poolVols := range poolProjectVols {
+			projectInfo := projects[projectName]
+			if projectInfo == nil {
+				// Missing project is a dependency error, but we can't check its volumes' dependencies.
+				dependencyError := fmt.Sprintf("Project %q", projectName)
+				if !shared.StringInSlice(dependencyError, res.DependencyErrors) {
+					res.DependencyErrors = append(res.DependencyErrors, dependencyError)
+				}
+			}
+
+			for _, poolVol := range poolVols {
+				if poolVol.Container != nil {
+					res.UnknownVolumes = append(res.UnknownVolumes, internalRecoverValidateVolume{
+						Pool:          pool.Name(),
+						Project:       projectName,
+						Type:          poolVol.Volume.Type,
+						Name:          poolVol.Container.Name,
+						SnapshotCount: len(poolVol.Snapshots),
+					})
+				} else if poolVol.Volume != nil {
+					res.UnknownVolumes = append(res.UnknownVolumes, internalRecoverValidateVolume{
+						Pool:          pool.Name(),
+						Project:       projectName,
+						Type:          poolVol.Volume.Type,
+						Name:          poolVol.Volume.Name,
+						SnapshotCount: len(poolVol.VolumeSnapshots),
+					})
+				}
+
+				if projectInfo == nil || poolVol.Container == nil {
+					continue
+				}
+
+				// Check the instance's profiles exist.
+				profileNames, found := projectProfiles[projectName]
+				if !found {
+					profileNames, err = s.Cluster.GetProfileNames(projectName)
+					if err != nil {
+						return response.SmartError(errors.Wrapf(err, "Failed getting profiles for project %q", projectName))
+					}
+
+					projectProfiles[projectName] = profileNames
+				}
+
+				for _, profileName := range poolVol.Container.Profiles {
+					if !shared.StringInSlice(profileName, profileNames) {
+						dependencyError := fmt.Sprintf("Profile %q in project %q", profileName, projectName)
+						if !shared.StringInSlice(dependencyError, res.DependencyErrors) {
+							res.DependencyErrors = append(res.DependencyErrors, dependencyError)
+						}
+					}
+				}
+
+				// Check the networks used by the instance's NIC devices exist.
+				networkNames, found := projectNetworks[projectName]
+				if !found {
+					networkProjectName := project.NetworkProjectFromRecord(projectInfo)
+					networkNames, err = s.Cluster.GetNetworks(networkProjectName)
+					if err != nil && err != db.ErrNoSuchObject {
+						return response.SmartError(errors.Wrapf(err, "Failed getting networks for project %q", projectName))
+					}
+
+					projectNetworks[projectName] = networkNames
+				}
+
+				for _, devConfig := range poolVol.Container.Devices {
+					if devConfig["type"] != "nic" || devConfig["network"] == "" {
+						continue
+					}
+
+					if !shared.StringInSlice(devConfig["network"], networkNames) {
+						dependencyError := fmt.Sprintf("Network %q in project %q", devConfig["network"], projectName)
+						if !shared.StringInSlice(dependencyError, res.DependencyErrors) {
+							res.DependencyErrors = append(res.DependencyErrors, dependencyError)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if validateOnly {
+		return response.SyncResponse(true, &res)
+	}
+
+	// If in import mode and dependency errors, then fail.
+	if len(res.DependencyErrors) > 0 {
+		return response.SmartError(fmt.Errorf("Cannot import with missing dependencies: %v", res.DependencyErrors))
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	for _, pool := range pools {
+		poolProjectVols := poolsProjectVols[pool.Name()]
+		if len(poolProjectVols) == 0 && pool.ID() == storagePools.PoolIDTemporary {
+			continue // Don't create pools that have no volumes to recover.
+		}
+
+		// Create missing storage pool DB record if needed.
+		if pool.ID() == storagePools.PoolIDTemporary {
+			poolID, err := internalRecoverPoolCreate(s, pool)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			poolName := pool.Name() // Local var for revert.
+			revert.Add(func() {
+				dbStoragePoolDeleteAndUpdateCache(s, poolName)
+			})
+
+			// Reload the pool now that it has a DB record.
+			pool, err = storagePools.GetPoolByName(s, poolName)
+			if err != nil {
+				return response.SmartError(errors.Wrapf(err, "Failed loading created pool %q", poolName))
+			}
+
+			if pool.ID() != poolID {
+				return response.SmartError(fmt.Errorf("Created pool %q has unexpected ID", poolName))
+			}
+
+			pools[poolName] = pool // Prevent the pool from being unmounted.
+		}
+
+		// Recover the custom volumes first as instances may depend on them.
+		for projectName, poolVols := range poolProjectVols {
+			for _, poolVol := range poolVols {
+				if poolVol.Container != nil || poolVol.Volume == nil {
+					continue
+				}
+
+				err = pool.ImportCustomVolume(projectName, *poolVol, nil)
+				if err != nil {
+					return response.SmartError(errors.Wrapf(err, "Failed importing custom volume %q in project %q", poolVol.Volume.Name, projectName))
+				}
+			}
+		}
+
+		// Now recover the instances.
+		for projectName, poolVols := range poolProjectVols {
+			for _, poolVol := range poolVols {
+				if poolVol.Container == nil {
+					continue
+				}
+
+				err = internalRecoverImportInstance(s, pool, projectName, poolVol, revert)
+				if err != nil {
+					return response.SmartError(errors.Wrapf(err, "Failed importing instance %q in project %q", poolVol.Container.Name, projectName))
+				}
+			}
+		}
+	}
+
+	revert.Success()
+	return response.EmptySyncResponse
+}
+
+// internalRecoverPoolCreate creates the DB record of a recovered storage pool and marks it as created on the
+// local member. Returns the ID of the created pool.
+func internalRecoverPoolCreate(s *state.State, pool storagePools.Pool) (int64, error) {
+	logger.Info("Creating storage pool DB record", log.Ctx{"pool": pool.Name()})
+
+	poolID, err := dbStoragePoolCreateAndUpdateCache(s, pool.Name(), pool.Description(), pool.Driver().Info().Name, pool.Driver().Config())
+	if err != nil {
+		return -1, errors.Wrapf(err, "Failed creating storage pool %q database entry", pool.Name())
+	}
+
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.StoragePoolNodeCreated(poolID)
+	})
+	if err != nil {
+		dbStoragePoolDeleteAndUpdateCache(s, pool.Name())
+		return -1, errors.Wrapf(err, "Failed marking storage pool %q local status as created", pool.Name())
+	}
+
+	return poolID, nil
+}
+
+// internalRecoverImportInstance recreates the DB records of an instance and its snapshots from its backup file
+// and then asks the storage pool to restore the instance's mount points and symlinks.
+func internalRecoverImportInstance(s *state.State, pool storagePools.Pool, projectName string, poolVol *backup.Config, revert *revert.Reverter) error {
+	logger.Info("Recovering instance", log.Ctx{"project": projectName, "instance": poolVol.Container.Name, "pool": pool.Name()})
+
+	instanceType, err := instancetype.New(string(poolVol.Container.Type))
+	if err != nil {
+		return err
+	}
+
+	// Recover the instance record.
+	inst, err := internalRecoverInstanceCreate(s, pool, projectName, instanceType, poolVol.Container.Name, false, &poolVol.Container.InstancePut, poolVol.Container.ExpandedDevices, poolVol.Container.CreatedAt, poolVol.Container.LastUsedAt, time.Time{}, revert)
+	if err != nil {
+		return err
+	}
+
+	// Restore the instance volume's config from the backup file, replacing the defaults used on creation.
+	volType, err := storagePools.InstanceTypeToVolumeType(instanceType)
+	if err != nil {
+		return err
+	}
+
+	volDBType, err := storagePools.VolumeTypeToDBType(volType)
+	if err != nil {
+		return err
+	}
+
+	if poolVol.Volume != nil && poolVol.Volume.Config != nil {
+		err = s.Cluster.UpdateStoragePoolVolume(projectName, inst.Name(), volDBType, pool.ID(), poolVol.Volume.Description, poolVol.Volume.Config)
+		if err != nil {
+			return errors.Wrapf(err, "Failed restoring instance volume config")
+		}
+	}
+
+	// Recover the instance snapshot records.
+	for _, snap := range poolVol.Snapshots {
+		_, snapOnlyName, _ := shared.InstanceGetParentAndSnapshotName(snap.Name)
+		if snapOnlyName == "" {
+			snapOnlyName = snap.Name
+		}
+
+		snapInstPut := api.InstancePut{
+			Architecture: snap.Architecture,
+			Config:       snap.Config,
+			Devices:      snap.Devices,
+			Ephemeral:    snap.Ephemeral,
+			Profiles:     snap.Profiles,
+			Stateful:     snap.Stateful,
+		}
+
+		fullSnapName := fmt.Sprintf("%s%s%s", inst.Name(), shared.SnapshotDelimiter, snapOnlyName)
+		_, err = internalRecoverInstanceCreate(s, pool, projectName, instanceType, fullSnapName, true, &snapInstPut, snap.ExpandedDevices, snap.CreatedAt, snap.LastUsedAt, snap.ExpiresAt, revert)
+		if err != nil {
+			return errors.Wrapf(err, "Failed creating instance snapshot %q record", snapOnlyName)
+		}
+	}
+
+	// Recreate the mount paths and symlinks of the instance.
+	err = pool.ImportInstance(inst, nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed importing instance volume")
+	}
+
+	return nil
+}
+
+// internalRecoverInstanceCreate creates the DB record of an instance or instance snapshot from its backup config.
+// The expiry date is only used for snapshots.
+func internalRecoverInstanceCreate(s *state.State, pool storagePools.Pool, projectName string, instanceType instancetype.Type, name string, snapshot bool, instPut *api.InstancePut, expandedDevices map[string]map[string]string, createdAt time.Time, lastUsedAt time.Time, expiryDate time.Time, revert *revert.Reverter) (instance.Instance, error) {
+	arch, err := osarch.ArchitectureId(instPut.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.Cluster.GetProfiles(projectName, instPut.Profiles)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading profiles")
+	}
+
+	// Add root device if needed.
+	if instPut.Devices == nil {
+		instPut.Devices = make(map[string]map[string]string)
+	}
+
+	if expandedDevices == nil {
+		expandedDevices = make(map[string]map[string]string)
+	}
+
+	internalImportRootDevicePopulate(pool.Name(), instPut.Devices, expandedDevices, profiles)
+
+	inst, err := instance.CreateInternal(s, db.InstanceArgs{
+		Project:      projectName,
+		Architecture: arch,
+		BaseImage:    instPut.Config["volatile.base_image"],
+		Config:       instPut.Config,
+		CreationDate: createdAt,
+		Type:         instanceType,
+		Snapshot:     snapshot,
+		Description:  instPut.Description,
+		Devices:      deviceConfig.NewDevices(instPut.Devices),
+		Ephemeral:    instPut.Ephemeral,
+		LastUsedDate: lastUsedAt,
+		Name:         name,
+		Profiles:     instPut.Profiles,
+		Stateful:     instPut.Stateful,
+		ExpiryDate:   expiryDate,
+	}, revert)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating instance record")
+	}
+
+	return inst, nil
+}
@@ -0,0 +1,460 @@
+package bgp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+	bgpServer "github.com/osrg/gobgp/v3/pkg/server"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/lxc/lxd/lxd/revert"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// Server represents a BGP server instance.
+// Prefixes and peers can be added while the server isn't running, they get applied when it's started.
+type Server struct {
+	bgp *bgpServer.BgpServer
+
+	address  string
+	asn      uint32
+	routerID net.IP
+
+	paths map[string]path
+	peers map[string]peer
+
+	mu sync.Mutex
+}
+
+type path struct {
+	owner   string
+	prefix  net.IPNet
+	nexthop net.IP
+	uuid    []byte
+}
+
+type peer struct {
+	address  net.IP
+	asn      uint32
+	password string
+	owners   map[string]struct{}
+}
+
+// NewServer returns a new, not yet started, BGP server.
+func NewServer() *Server {
+	return &Server{
+		paths: map[string]path{},
+		peers: map[string]peer{},
+	}
+}
+
+// Start sets up the BGP server listening on address with the given ASN and router ID.
+// If routerID is nil, the listen address is used when it's a specific IPv4 address.
+func (s *Server) Start(address string, asn uint32, routerID net.IP) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.start(address, asn, routerID)
+}
+
+func (s *Server) start(address string, asn uint32, routerID net.IP) error {
+	// Check if already running.
+	if s.bgp != nil {
+		return fmt.Errorf("BGP listener is already running")
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid BGP listen address %q", address)
+	}
+
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid BGP listen port %q", portStr)
+	}
+
+	id := routerID
+	if id == nil {
+		listenIP := net.ParseIP(host)
+		if listenIP == nil || listenIP.To4() == nil || listenIP.IsUnspecified() {
+			return fmt.Errorf("A router ID is required when the BGP listen address isn't a specific IPv4 address")
+		}
+
+		id = listenIP
+	}
+
+	listenAddresses := []string{}
+	if host != "" {
+		listenAddresses = append(listenAddresses, host)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Spawn the server.
+	s.bgp = bgpServer.NewBgpServer()
+	go s.bgp.Serve()
+
+	revert.Add(func() {
+		s.bgp.Stop()
+		s.bgp = nil
+
+		// Forget the path identifiers of the prefixes applied before the failure.
+		for pathID, p := range s.paths {
+			p.uuid = nil
+			s.paths[pathID] = p
+		}
+	})
+
+	err = s.bgp.StartBgp(context.Background(), &bgpAPI.StartBgpRequest{
+		Global: &bgpAPI.Global{
+			Asn:             asn,
+			RouterId:        id.String(),
+			ListenPort:      int32(port),
+			ListenAddresses: listenAddresses,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed starting BGP listener")
+	}
+
+	revert.Add(func() { s.bgp.StopBgp(context.Background(), &bgpAPI.StopBgpRequest{}) })
+
+	// Only announce our own routes, don't import anything from the peers.
+	err = s.bgp.SetPolicyAssignment(context.Background(), &bgpAPI.SetPolicyAssignmentRequest{
+		Assignment: &bgpAPI.PolicyAssignment{
+			Name:          "global",
+			Direction:     bgpAPI.PolicyDirection_IMPORT,
+			DefaultAction: bgpAPI.RouteAction_REJECT,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting BGP import policy")
+	}
+
+	s.address = address
+	s.asn = asn
+	s.routerID = routerID
+
+	// Apply the existing peers and prefixes.
+	for _, p := range s.peers {
+		err = s.addPeer(p.address, p.asn, p.password)
+		if err != nil {
+			return err
+		}
+	}
+
+	for pathID, p := range s.paths {
+		uuid, err := s.addPrefix(p.prefix, p.nexthop)
+		if err != nil {
+			return err
+		}
+
+		p.uuid = uuid
+		s.paths[pathID] = p
+	}
+
+	logger.Info("Started BGP server", log.Ctx{"address": address, "asn": asn, "routerID": id})
+
+	revert.Success()
+	return nil
+}
+
+// Stop stops the BGP server, keeping its peers and prefixes for the next start.
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stop()
+}
+
+func (s *Server) stop() error {
+	// Skip if no instance.
+	if s.bgp == nil {
+		return nil
+	}
+
+	err := s.bgp.StopBgp(context.Background(), &bgpAPI.StopBgpRequest{})
+	if err != nil {
+		return errors.Wrapf(err, "Failed stopping BGP listener")
+	}
+
+	s.bgp.Stop()
+	s.bgp = nil
+
+	// Forget the path identifiers, they'll be re-generated on next start.
+	for pathID, p := range s.paths {
+		p.uuid = nil
+		s.paths[pathID] = p
+	}
+
+	logger.Info("Stopped BGP server")
+
+	return nil
+}
+
+// Configure updates the listener address, ASN and router ID, restarting the server as needed.
+// An empty address or an ASN of 0 disables the server.
+func (s *Server) Configure(address string, asn uint32, routerID net.IP) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Skip if nothing changed.
+	if s.address == address && s.asn == asn && s.routerID.Equal(routerID) && (s.bgp != nil) == (address != "" && asn != 0) {
+		return nil
+	}
+
+	err := s.stop()
+	if err != nil {
+		return err
+	}
+
+	s.address = address
+	s.asn = asn
+	s.routerID = routerID
+
+	if address == "" || asn == 0 {
+		return nil
+	}
+
+	return s.start(address, asn, routerID)
+}
+
+// AddPrefix announces subnet with the given next-hop on behalf of owner.
+// A nil next-hop makes the server announce itself as the next-hop.
+func (s *Server) AddPrefix(subnet net.IPNet, nexthop net.IP, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pathID := fmt.Sprintf("%s/%s", owner, subnet.String())
+
+	// Replace any existing announcement of that prefix by the owner.
+	err := s.removePath(pathID)
+	if err != nil {
+		return err
+	}
+
+	p := path{
+		owner:   owner,
+		prefix:  subnet,
+		nexthop: nexthop,
+	}
+
+	if s.bgp != nil {
+		p.uuid, err = s.addPrefix(subnet, nexthop)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.paths[pathID] = p
+
+	return nil
+}
+
+func (s *Server) addPrefix(subnet net.IPNet, nexthop net.IP) ([]byte, error) {
+	prefixLen, _ := subnet.Mask.Size()
+
+	family := &bgpAPI.Family{Afi: bgpAPI.Family_AFI_IP, Safi: bgpAPI.Family_SAFI_UNICAST}
+	if subnet.IP.To4() == nil {
+		family.Afi = bgpAPI.Family_AFI_IP6
+	}
+
+	if nexthop == nil {
+		if family.Afi == bgpAPI.Family_AFI_IP {
+			nexthop = net.IPv4zero
+		} else {
+			nexthop = net.IPv6zero
+		}
+	}
+
+	nlri, err := anypb.New(&bgpAPI.IPAddressPrefix{
+		Prefix:    subnet.IP.String(),
+		PrefixLen: uint32(prefixLen),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	origin, err := anypb.New(&bgpAPI.OriginAttribute{Origin: 0})
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []*anypb.Any{origin}
+	if family.Afi == bgpAPI.Family_AFI_IP {
+		nexthopAttr, err := anypb.New(&bgpAPI.NextHopAttribute{NextHop: nexthop.String()})
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, nexthopAttr)
+	} else {
+		reachAttr, err := anypb.New(&bgpAPI.MpReachNLRIAttribute{
+			Family:   family,
+			NextHops: []string{nexthop.String()},
+			Nlris:    []*anypb.Any{nlri},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, reachAttr)
+	}
+
+	resp, err := s.bgp.AddPath(context.Background(), &bgpAPI.AddPathRequest{
+		Path: &bgpAPI.Path{
+			Family: family,
+			Nlri:   nlri,
+			Pattrs: attrs,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed announcing prefix %q", subnet.String())
+	}
+
+	return resp.Uuid, nil
+}
+
+// RemovePrefixByOwner withdraws all the prefixes announced on behalf of owner.
+func (s *Server) RemovePrefixByOwner(owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for pathID, p := range s.paths {
+		if p.owner != owner {
+			continue
+		}
+
+		err := s.removePath(pathID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) removePath(pathID string) error {
+	p, ok := s.paths[pathID]
+	if !ok {
+		return nil
+	}
+
+	if s.bgp != nil && p.uuid != nil {
+		err := s.bgp.DeletePath(context.Background(), &bgpAPI.DeletePathRequest{Uuid: p.uuid})
+		if err != nil {
+			return errors.Wrapf(err, "Failed withdrawing prefix %q", p.prefix.String())
+		}
+	}
+
+	delete(s.paths, pathID)
+
+	return nil
+}
+
+// AddPeer adds a BGP peer on behalf of owner. Multiple owners can share a peer so long as they use the same
+// configuration for it. Adding the same peer again for an owner is a no-op.
+func (s *Server) AddPeer(address net.IP, asn uint32, password string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.peers[address.String()]
+	if ok {
+		if p.asn == asn && p.password == password {
+			p.owners[owner] = struct{}{}
+			return nil
+		}
+
+		// Only allow changing the configuration of a peer if nobody else uses it.
+		_, owned := p.owners[owner]
+		if !owned || len(p.owners) > 1 {
+			return fmt.Errorf("A peer with address %q is already defined with a different configuration", address.String())
+		}
+
+		err := s.removePeer(address)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.bgp != nil {
+		err := s.addPeer(address, asn, password)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.peers[address.String()] = peer{
+		address:  address,
+		asn:      asn,
+		password: password,
+		owners:   map[string]struct{}{owner: {}},
+	}
+
+	return nil
+}
+
+func (s *Server) addPeer(address net.IP, asn uint32, password string) error {
+	families := []*bgpAPI.AfiSafi{}
+	for _, afi := range []bgpAPI.Family_Afi{bgpAPI.Family_AFI_IP, bgpAPI.Family_AFI_IP6} {
+		families = append(families, &bgpAPI.AfiSafi{
+			Config: &bgpAPI.AfiSafiConfig{
+				Family: &bgpAPI.Family{Afi: afi, Safi: bgpAPI.Family_SAFI_UNICAST},
+			},
+		})
+	}
+
+	err := s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{
+		Peer: &bgpAPI.Peer{
+			Conf: &bgpAPI.PeerConf{
+				NeighborAddress: address.String(),
+				PeerAsn:         asn,
+				AuthPassword:    password,
+			},
+			AfiSafis: families,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding BGP peer %q", address.String())
+	}
+
+	return nil
+}
+
+// RemovePeer removes a BGP peer on behalf of owner, the peer is removed once it has no owners left.
+func (s *Server) RemovePeer(address net.IP, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.peers[address.String()]
+	if !ok {
+		return nil
+	}
+
+	delete(p.owners, owner)
+	if len(p.owners) > 0 {
+		return nil
+	}
+
+	return s.removePeer(address)
+}
+
+func (s *Server) removePeer(address net.IP) error {
+	if s.bgp != nil {
+		err := s.bgp.DeletePeer(context.Background(), &bgpAPI.DeletePeerRequest{Address: address.String()})
+		if err != nil {
+			return errors.Wrapf(err, "Failed removing BGP peer %q", address.String())
+		}
+	}
+
+	delete(s.peers, address.String())
+
+	return nil
+}
@@ -559,3 +559,61 @@ func (cg *CGroup) SetCpuset(limit string) error {
 
 	return ErrUnknownVersion
 }
+
+// GetIOStats returns the number of bytes and operations read and written per block device (keyed by major:minor)
+func (cg *CGroup) GetIOStats() (map[string]*IOStats, error) {
+	version := cgControllers["blkio"]
+	switch version {
+	case Unavailable:
+		return nil, ErrControllerMissing
+	case V1:
+		bytes, err := cg.rw.Get(version, "blkio", "blkio.throttle.io_service_bytes_recursive")
+		if err != nil {
+			return nil, err
+		}
+
+		ops, err := cg.rw.Get(version, "blkio", "blkio.throttle.io_serviced_recursive")
+		if err != nil {
+			return nil, err
+		}
+
+		return parseBlkioStats(bytes, ops)
+	case V2:
+		val, err := cg.rw.Get(version, "io", "io.stat")
+		if err != nil {
+			return nil, err
+		}
+
+		return parseIOStat(val)
+	}
+
+	return nil, ErrUnknownVersion
+}
+
+// GetPressure returns the pressure stall information for the cpu, memory or io resource
+func (cg *CGroup) GetPressure(resource string) (*Pressure, error) {
+	controller := resource
+	if resource == "io" {
+		controller = "blkio"
+	} else if !shared.StringInSlice(resource, []string{"cpu", "memory"}) {
+		return nil, fmt.Errorf("Invalid pressure resource: %s", resource)
+	}
+
+	version := cgControllers[controller]
+	switch version {
+	case Unavailable:
+		return nil, ErrControllerMissing
+	case V1:
+		// Pressure stall information is only exposed through the unified hierarchy.
+		return nil, ErrControllerMissing
+	case V2:
+		val, err := cg.rw.Get(version, resource, fmt.Sprintf("%s.pressure", resource))
+		if err != nil {
+			return nil, err
+		}
+
+		return parsePressure(val)
+	}
+
+	return nil, ErrUnknownVersion
+}
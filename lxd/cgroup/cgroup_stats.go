@@ -0,0 +1,168 @@
+package cgroup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IOStats represents the I/O usage of a cgroup on a block device.
+type IOStats struct {
+	ReadBytes  int64
+	ReadOps    int64
+	WriteBytes int64
+	WriteOps   int64
+}
+
+// PressureStats represents one line (some or full) of a pressure stall information file.
+type PressureStats struct {
+	// Percentage of time stalled over the last 10, 60 and 300 seconds.
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+
+	// Total time stalled in microseconds.
+	Total int64
+}
+
+// Pressure represents the pressure stall information of a resource.
+type Pressure struct {
+	Some PressureStats
+	Full PressureStats
+}
+
+// parseIOStat parses the content of the cgroup2 io.stat file.
+// Lines look like "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0".
+func parseIOStat(content string) (map[string]*IOStats, error) {
+	stats := map[string]*IOStats{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		entry := &IOStats{}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			value, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid io.stat value %q for %q", parts[1], fields[0])
+			}
+
+			switch parts[0] {
+			case "rbytes":
+				entry.ReadBytes = value
+			case "wbytes":
+				entry.WriteBytes = value
+			case "rios":
+				entry.ReadOps = value
+			case "wios":
+				entry.WriteOps = value
+			}
+		}
+
+		stats[fields[0]] = entry
+	}
+
+	return stats, nil
+}
+
+// parseBlkioStats parses the content of the cgroup1 blkio.throttle.io_service_bytes and io_serviced files.
+// Lines look like "8:0 Read 1024", the trailing "Total" line is ignored.
+func parseBlkioStats(bytes string, ops string) (map[string]*IOStats, error) {
+	stats := map[string]*IOStats{}
+
+	parse := func(content string, read func(*IOStats, int64), write func(*IOStats, int64)) error {
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				continue
+			}
+
+			value, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid blkio value %q for %q", fields[2], fields[0])
+			}
+
+			entry, ok := stats[fields[0]]
+			if !ok {
+				entry = &IOStats{}
+				stats[fields[0]] = entry
+			}
+
+			switch fields[1] {
+			case "Read":
+				read(entry, value)
+			case "Write":
+				write(entry, value)
+			}
+		}
+
+		return nil
+	}
+
+	err := parse(bytes, func(s *IOStats, v int64) { s.ReadBytes = v }, func(s *IOStats, v int64) { s.WriteBytes = v })
+	if err != nil {
+		return nil, err
+	}
+
+	err = parse(ops, func(s *IOStats, v int64) { s.ReadOps = v }, func(s *IOStats, v int64) { s.WriteOps = v })
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// parsePressure parses the content of a pressure stall information file.
+// Lines look like "some avg10=0.00 avg60=0.00 avg300=0.00 total=0".
+func parsePressure(content string) (*Pressure, error) {
+	pressure := &Pressure{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 1 {
+			continue
+		}
+
+		var stats *PressureStats
+		switch fields[0] {
+		case "some":
+			stats = &pressure.Some
+		case "full":
+			stats = &pressure.Full
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			var err error
+			switch parts[0] {
+			case "avg10":
+				stats.Avg10, err = strconv.ParseFloat(parts[1], 64)
+			case "avg60":
+				stats.Avg60, err = strconv.ParseFloat(parts[1], 64)
+			case "avg300":
+				stats.Avg300, err = strconv.ParseFloat(parts[1], 64)
+			case "total":
+				stats.Total, err = strconv.ParseInt(parts[1], 10, 64)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("Invalid pressure value %q for %q", parts[1], parts[0])
+			}
+		}
+	}
+
+	return pressure, nil
+}
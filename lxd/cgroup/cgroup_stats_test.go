@@ -0,0 +1,74 @@
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIOStat(t *testing.T) {
+	stats, err := parseIOStat(`8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0
+253:1 rbytes=4096 wbytes=0 rios=4 wios=0
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*IOStats{
+		"8:0":   {ReadBytes: 1024, WriteBytes: 2048, ReadOps: 1, WriteOps: 2},
+		"253:1": {ReadBytes: 4096, ReadOps: 4},
+	}, stats)
+
+	stats, err = parseIOStat("")
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+
+	_, err = parseIOStat("8:0 rbytes=abc")
+	assert.EqualError(t, err, `Invalid io.stat value "abc" for "8:0"`)
+}
+
+func TestParseBlkioStats(t *testing.T) {
+	bytes := `8:0 Read 1024
+8:0 Write 2048
+8:0 Sync 3072
+8:0 Async 0
+8:0 Total 3072
+8:16 Read 512
+Total 3584
+`
+
+	ops := `8:0 Read 1
+8:0 Write 2
+8:0 Total 3
+8:16 Read 1
+Total 4
+`
+
+	stats, err := parseBlkioStats(bytes, ops)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*IOStats{
+		"8:0":  {ReadBytes: 1024, WriteBytes: 2048, ReadOps: 1, WriteOps: 2},
+		"8:16": {ReadBytes: 512, ReadOps: 1},
+	}, stats)
+
+	_, err = parseBlkioStats("8:0 Read abc", "")
+	assert.EqualError(t, err, `Invalid blkio value "abc" for "8:0"`)
+}
+
+func TestParsePressure(t *testing.T) {
+	pressure, err := parsePressure(`some avg10=0.52 avg60=0.21 avg300=0.05 total=1265393
+full avg10=0.00 avg60=0.01 avg300=0.00 total=4242
+`)
+	require.NoError(t, err)
+	assert.Equal(t, &Pressure{
+		Some: PressureStats{Avg10: 0.52, Avg60: 0.21, Avg300: 0.05, Total: 1265393},
+		Full: PressureStats{Avg60: 0.01, Total: 4242},
+	}, pressure)
+
+	// The CPU pressure file only has a "some" line on older kernels.
+	pressure, err = parsePressure("some avg10=1.00 avg60=0.00 avg300=0.00 total=10\n")
+	require.NoError(t, err)
+	assert.Equal(t, PressureStats{Avg10: 1, Total: 10}, pressure.Some)
+	assert.Equal(t, PressureStats{}, pressure.Full)
+
+	_, err = parsePressure("some avg10=abc")
+	assert.EqualError(t, err, `Invalid pressure value "abc" for "avg10"`)
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"net"
+	"net/mail"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,6 +17,7 @@ import (
 
 	"github.com/lxc/lxd/lxd/config"
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/shared/signing"
 	"github.com/lxc/lxd/shared/validate"
 )
 
@@ -109,6 +114,14 @@ func (c *Config) HTTPSTrustedProxy() string {
 	return c.m.GetString("core.https_trusted_proxy")
 }
 
+// ACME returns the configured ACME domain, email and CA URL.
+func (c *Config) ACME() (string, string, string) {
+	domain := c.m.GetString("acme.domain")
+	email := c.m.GetString("acme.email")
+	caURL := c.m.GetString("acme.ca_url")
+	return domain, email, caURL
+}
+
 // MAASController the configured MAAS url and key, if any.
 func (c *Config) MAASController() (string, string) {
 	url := c.m.GetString("maas.api.url")
@@ -148,6 +161,11 @@ func (c *Config) ShutdownTimeout() time.Duration {
 	return time.Duration(n) * time.Minute
 }
 
+// BGPASN returns the ASN to use for the BGP server (0 disables it).
+func (c *Config) BGPASN() int64 {
+	return c.m.GetInt64("core.bgp_asn")
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]interface{} {
@@ -237,11 +255,15 @@ func configGet(cluster *db.Cluster) (*Config, error) {
 
 // ConfigSchema defines available server configuration keys.
 var ConfigSchema = config.Schema{
+	"acme.ca_url":                    {Default: "https://acme-v02.api.letsencrypt.org/directory", Validator: acmeCAURLValidator},
+	"acme.domain":                    {Validator: validate.Optional(acmeDomainValidator)},
+	"acme.email":                     {Validator: validate.Optional(acmeEmailValidator)},
 	"backups.compression_algorithm":  {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 	"cluster.offline_threshold":      {Type: config.Int64, Default: offlineThresholdDefault(), Validator: offlineThresholdValidator},
 	"cluster.images_minimal_replica": {Type: config.Int64, Default: "3", Validator: imageMinimalReplicaValidator},
 	"cluster.max_voters":             {Type: config.Int64, Default: "3", Validator: maxVotersValidator},
 	"cluster.max_standby":            {Type: config.Int64, Default: "2", Validator: maxStandByValidator},
+	"core.bgp_asn":                   {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
 	"core.https_allowed_headers":     {},
 	"core.https_allowed_methods":     {},
 	"core.https_allowed_origin":      {},
@@ -262,6 +284,7 @@ var ConfigSchema = config.Schema{
 	"images.compression_algorithm":   {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 	"images.default_architecture":    {Validator: validate.Optional(validate.IsArchitecture)},
 	"images.remote_cache_expiry":     {Type: config.Int64, Default: "10"},
+	"images.trust.keys":              {Validator: validate.Optional(imageTrustKeysValidator)},
 	"maas.api.key":                   {},
 	"maas.api.url":                   {},
 	"rbac.agent.url":                 {},
@@ -309,6 +332,61 @@ func offlineThresholdValidator(value string) error {
 	return nil
 }
 
+func acmeCAURLValidator(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("Invalid URL")
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return fmt.Errorf("Value must be an http or https URL")
+	}
+
+	return nil
+}
+
+func acmeDomainValidator(value string) error {
+	if net.ParseIP(value) != nil {
+		return fmt.Errorf("Value must be a domain name, not an IP address")
+	}
+
+	if len(value) > 253 {
+		return fmt.Errorf("Domain name is too long")
+	}
+
+	for _, label := range strings.Split(value, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return fmt.Errorf("Invalid domain name")
+		}
+
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("Invalid domain name")
+		}
+
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return fmt.Errorf("Invalid domain name")
+			}
+		}
+	}
+
+	return nil
+}
+
+func acmeEmailValidator(value string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return fmt.Errorf("Invalid email address")
+	}
+
+	return nil
+}
+
+func imageTrustKeysValidator(value string) error {
+	_, err := signing.ParseKeyring(value)
+	return err
+}
+
 func imageMinimalReplicaValidator(value string) error {
 	count, err := strconv.Atoi(value)
 	if err != nil {
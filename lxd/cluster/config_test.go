@@ -77,6 +77,34 @@ func TestConfigLoad_MaxVotersValidator(t *testing.T) {
 
 }
 
+// The ACME keys must hold a domain name, an email address and an http(s) URL.
+func TestConfigLoad_ACMEValidators(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	config, err := cluster.ConfigLoad(tx)
+	require.NoError(t, err)
+
+	_, err = config.Patch(map[string]interface{}{
+		"acme.domain": "lxd.example.com",
+		"acme.email":  "admin@example.com",
+		"acme.ca_url": "https://acme-staging-v02.api.letsencrypt.org/directory",
+	})
+	require.NoError(t, err)
+
+	_, err = config.Patch(map[string]interface{}{"acme.domain": "10.0.0.1"})
+	require.EqualError(t, err, "cannot set 'acme.domain' to '10.0.0.1': Value must be a domain name, not an IP address")
+
+	_, err = config.Patch(map[string]interface{}{"acme.domain": "lxd..example.com"})
+	require.EqualError(t, err, "cannot set 'acme.domain' to 'lxd..example.com': Invalid domain name")
+
+	_, err = config.Patch(map[string]interface{}{"acme.email": "Admin <admin@example.com>"})
+	require.EqualError(t, err, "cannot set 'acme.email' to 'Admin <admin@example.com>': Invalid email address")
+
+	_, err = config.Patch(map[string]interface{}{"acme.ca_url": "ftp://example.com"})
+	require.EqualError(t, err, "cannot set 'acme.ca_url' to 'ftp://example.com': Value must be an http or https URL")
+}
+
 // If some previously set values are missing from the ones passed to Replace(),
 // they are deleted from the configuration.
 func TestConfig_ReplaceDeleteValues(t *testing.T) {
@@ -8,6 +8,7 @@ import (
 	sqldriver "database/sql/driver"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"gopkg.in/macaroon-bakery.v2/bakery/identchecker"
 	"gopkg.in/macaroon-bakery.v2/httpbakery"
 
+	"github.com/lxc/lxd/lxd/bgp"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/daemon"
 	"github.com/lxc/lxd/lxd/db"
@@ -68,6 +70,7 @@ type Daemon struct {
 	db           *db.Node
 	firewall     firewall.Firewall
 	maas         *maas.Controller
+	bgp          *bgp.Server
 	rbac         *rbac.Server
 	cluster      *db.Cluster
 	setupChan    chan struct{} // Closed when basic Daemon setup is completed
@@ -412,6 +415,7 @@ func (d *Daemon) State() *state.State {
 		Node:                   d.db,
 		Cluster:                d.cluster,
 		MAAS:                   d.maas,
+		BGP:                    d.bgp,
 		OS:                     d.os,
 		Endpoints:              d.endpoints,
 		Events:                 d.events,
@@ -1082,6 +1086,9 @@ func (d *Daemon) init() error {
 	d.firewall = firewall.New()
 	logger.Infof("Firewall loaded driver %q", d.firewall)
 
+	// Setup the BGP server (started once the configuration is loaded).
+	d.bgp = bgp.NewServer()
+
 	err = cluster.NotifyUpgradeCompleted(d.State(), networkCert, d.serverCert())
 	if err != nil {
 		// Ignore the error, since it's not fatal for this particular
@@ -1170,6 +1177,10 @@ func (d *Daemon) init() error {
 	maasAPIKey := ""
 	maasMachine := ""
 
+	bgpAddress := ""
+	bgpASN := int64(0)
+	bgpRouterID := ""
+
 	err = d.db.Transaction(func(tx *db.NodeTx) error {
 		config, err := node.ConfigLoad(tx)
 		if err != nil {
@@ -1177,6 +1188,8 @@ func (d *Daemon) init() error {
 		}
 
 		maasMachine = config.MAASMachine()
+		bgpAddress = config.BGPAddress()
+		bgpRouterID = config.BGPRouterID()
 		return nil
 	})
 	if err != nil {
@@ -1196,6 +1209,7 @@ func (d *Daemon) init() error {
 
 		candidAPIURL, candidAPIKey, candidExpiry, candidDomains = config.CandidServer()
 		maasAPIURL, maasAPIKey = config.MAASController()
+		bgpASN = config.BGPASN()
 		rbacAPIURL, rbacAPIKey, rbacExpiry, rbacAgentURL, rbacAgentUsername, rbacAgentPrivateKey, rbacAgentPublicKey = config.RBACServer()
 		d.gateway.HeartbeatOfflineThreshold = config.OfflineThreshold()
 
@@ -1207,6 +1221,13 @@ func (d *Daemon) init() error {
 		return err
 	}
 
+	if bgpAddress != "" && bgpASN != 0 {
+		err = d.bgp.Configure(bgpAddress, uint32(bgpASN), net.ParseIP(bgpRouterID))
+		if err != nil {
+			return err
+		}
+	}
+
 	if rbacAPIURL != "" {
 		err = d.setupRBACServer(rbacAPIURL, rbacAPIKey, rbacExpiry, rbacAgentURL, rbacAgentUsername, rbacAgentPrivateKey, rbacAgentPublicKey)
 		if err != nil {
@@ -1367,6 +1388,9 @@ func (d *Daemon) Ready() error {
 		// Log expiry (daily)
 		d.tasks.Add(expireLogsTask(d.State()))
 
+		// Bound the console log of running VMs (minutely)
+		d.tasks.Add(trimConsoleLogsTask(d.State()))
+
 		// Remove expired images (daily)
 		d.taskPruneImages = d.tasks.Add(pruneExpiredImagesTask(d))
 
@@ -1393,6 +1417,21 @@ func (d *Daemon) Ready() error {
 
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
+
+		// Run instance health checks (every 5s, honoring each instance's healthcheck.interval)
+		d.tasks.Add(instanceHealthCheckTask(d))
+
+		// Raise and resolve storage, certificate and OVN warnings (every 5 minutes)
+		d.tasks.Add(warningsCheckTask(d))
+
+		// Obtain or renew the server certificate through ACME (daily)
+		d.tasks.Add(autoRenewCertificateTask(d))
+
+		// Rotate WireGuard keys and refresh WireGuard peers (minutely)
+		d.tasks.Add(networkWireguardRefreshTask(d))
+
+		// Refresh IPv6 delegated prefixes (minutely)
+		d.tasks.Add(networkPrefixDelegationRefreshTask(d))
 	}
 
 	// Start all background tasks
@@ -1519,6 +1558,10 @@ func (d *Daemon) Stop() error {
 			"Not unmounting temporary filesystems (containers are still running)")
 	}
 
+	if d.bgp != nil {
+		trackError(d.bgp.Stop(), "Stop BGP")
+	}
+
 	if d.seccomp != nil {
 		trackError(d.seccomp.Stop(), "Stop seccomp")
 	}
@@ -24,6 +24,7 @@ import (
 	"github.com/lxc/lxd/shared/cancel"
 	"github.com/lxc/lxd/shared/ioprogress"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/signing"
 	"github.com/lxc/lxd/shared/units"
 	"github.com/lxc/lxd/shared/version"
 
@@ -74,14 +75,21 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 	// Default the fingerprint to the alias string we received
 	fp := alias
 
+	// Get the trusted image signing keys
+	trustKeys, err := cluster.ConfigGetString(d.cluster, "images.trust.keys")
+	if err != nil {
+		return nil, err
+	}
+
 	// Attempt to resolve the alias
 	if shared.StringInSlice(protocol, []string{"lxd", "simplestreams"}) {
 		clientArgs := &lxd.ConnectionArgs{
-			TLSServerCert: args.Certificate,
-			UserAgent:     version.UserAgent,
-			Proxy:         d.proxy,
-			CachePath:     d.os.CacheDir,
-			CacheExpiry:   time.Hour,
+			TLSServerCert:  args.Certificate,
+			UserAgent:      version.UserAgent,
+			Proxy:          d.proxy,
+			CachePath:      d.os.CacheDir,
+			CacheExpiry:    time.Hour,
+			ImageTrustKeys: trustKeys,
 		}
 
 		if protocol == "lxd" {
@@ -189,6 +197,11 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 				return nil, err
 			}
 
+			err = d.cluster.UpdateImageSignature(id, imgInfo.Signature)
+			if err != nil {
+				return nil, err
+			}
+
 			// Transfer image if needed (after database record has been created above).
 			if nodeAddress != "" {
 				// The image is available from another node, let's try to import it.
@@ -324,6 +337,15 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 		if info.Type == "" {
 			info.Type = "container"
 		}
+
+		// Verify the image signature (simplestreams images are covered by the signed index).
+		if protocol == "lxd" && trustKeys != "" {
+			err = imageVerifySignature(trustKeys, info.Fingerprint, info.Signature)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		if args.Budget > 0 && info.Size > args.Budget {
 			return nil, fmt.Errorf("Remote image with size %d exceeds allowed bugdget of %d", info.Size, args.Budget)
 		}
@@ -375,6 +397,10 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 			}
 		}
 	} else if protocol == "direct" {
+		if trustKeys != "" {
+			return nil, fmt.Errorf("Image signatures can't be verified with the direct protocol")
+		}
+
 		// Setup HTTP client
 		httpClient, err := util.HTTPClient(args.Certificate, d.proxy)
 		if err != nil {
@@ -470,6 +496,19 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 	// Image is in the DB now, don't wipe on-disk files on failure
 	failure = false
 
+	// Keep the image signature
+	if info.Signature != "" {
+		id, _, err := d.cluster.GetImage(args.ProjectName, fp, false)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.cluster.UpdateImageSignature(id, info.Signature)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if the image path changed (private images)
 	newDestName := filepath.Join(destDir, fp)
 	if newDestName != destName {
@@ -528,3 +567,23 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 
 	return info, nil
 }
+
+// imageVerifySignature checks that signature is a valid signature of the image fingerprint made by one of the
+// keys listed in images.trust.keys.
+func imageVerifySignature(trustKeys string, fingerprint string, signature string) error {
+	if signature == "" {
+		return fmt.Errorf("Image %q isn't signed", fingerprint)
+	}
+
+	keyring, err := signing.ParseKeyring(trustKeys)
+	if err != nil {
+		return errors.Wrap(err, "Failed parsing images.trust.keys")
+	}
+
+	err = keyring.Verify([]byte(fingerprint), signature)
+	if err != nil {
+		return errors.Wrapf(err, "Failed verifying signature of image %q", fingerprint)
+	}
+
+	return nil
+}
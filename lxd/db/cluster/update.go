@@ -88,6 +88,109 @@ var updates = map[int]schema.Update{
 	47: updateFromV46,
 	48: updateFromV47,
 	49: updateFromV48,
+	50: updateFromV49,
+	51: updateFromV50,
+	52: updateFromV51,
+	53: updateFromV52,
+}
+
+// updateFromV52 adds the images_signatures table.
+func updateFromV52(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE images_signatures (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	image_id INTEGER NOT NULL,
+	signature TEXT NOT NULL,
+	UNIQUE (image_id),
+	FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create image signatures table")
+	}
+
+	return nil
+}
+
+// updateFromV51 adds the networks_reservations table.
+func updateFromV51(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE networks_reservations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	hwaddr TEXT NOT NULL,
+	description TEXT NOT NULL,
+	ipv4_address TEXT NOT NULL,
+	ipv6_address TEXT NOT NULL,
+	hostname TEXT NOT NULL,
+	UNIQUE (network_id, hwaddr),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create network reservations table")
+	}
+
+	return nil
+}
+
+// updateFromV50 adds the networks_address_sets and networks_address_sets_config tables.
+func updateFromV50(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE networks_address_sets (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	addresses TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+
+CREATE TABLE networks_address_sets_config (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_address_set_id INTEGER NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT,
+	UNIQUE (network_address_set_id, key),
+	FOREIGN KEY (network_address_set_id) REFERENCES networks_address_sets (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create network address set tables")
+	}
+
+	return nil
+}
+
+// updateFromV49 adds the networks_load_balancers and networks_load_balancers_config tables.
+func updateFromV49(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE networks_load_balancers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	listen_address TEXT NOT NULL,
+	description TEXT NOT NULL,
+	backends TEXT NOT NULL,
+	ports TEXT NOT NULL,
+	UNIQUE (network_id, listen_address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
+
+CREATE TABLE networks_load_balancers_config (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_load_balancer_id INTEGER NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT,
+	UNIQUE (network_load_balancer_id, key),
+	FOREIGN KEY (network_load_balancer_id) REFERENCES networks_load_balancers (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create network load balancer tables")
+	}
+
+	return nil
 }
 
 // updateFromV48 renames the "pending" column to "state" in the "nodes" table.
@@ -200,6 +200,16 @@ func (c *ClusterTx) imageFill(id int, image *api.Image, create, expire, used, up
 
 	image.Aliases = aliases
 
+	// Get the signature
+	signatures, err := query.SelectStrings(c.tx, "SELECT signature FROM images_signatures WHERE image_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	if len(signatures) > 0 {
+		image.Signature = signatures[0]
+	}
+
 	_, source, err := c.GetImageSource(id)
 	if err == nil {
 		image.UpdateSource = &source
@@ -334,6 +344,26 @@ func (c *Cluster) CreateImageSource(id int, server string, protocol string, cert
 	return err
 }
 
+// UpdateImageSignature sets the detached signature of the image with the given ID.
+// An empty signature removes any existing one.
+func (c *Cluster) UpdateImageSignature(id int, signature string) error {
+	err := c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec(`DELETE FROM images_signatures WHERE image_id=?`, id)
+		if err != nil {
+			return err
+		}
+
+		if signature == "" {
+			return nil
+		}
+
+		_, err = tx.tx.Exec(`INSERT INTO images_signatures (image_id, signature) VALUES (?, ?)`, id, signature)
+		return err
+	})
+
+	return err
+}
+
 // GetCachedImageSourceFingerprint tries to find a source entry of a locally
 // cached image that matches the given remote details (server, protocol and
 // alias). Return the fingerprint linked to the matching entry, if any.
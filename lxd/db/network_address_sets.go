@@ -0,0 +1,230 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkAddressSets returns the names of existing Network address sets.
+func (c *Cluster) GetNetworkAddressSets(project string) ([]string, error) {
+	q := `SELECT name FROM networks_address_sets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1)
+		ORDER BY id
+	`
+	inargs := []interface{}{project}
+
+	var name string
+	outfmt := []interface{}{name}
+	result, err := queryScan(c, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]string, 0, len(result))
+	for _, r := range result {
+		response = append(response, r[0].(string))
+	}
+
+	return response, nil
+}
+
+// GetNetworkAddressSet returns the Network address set with the given name in the given project.
+func (c *Cluster) GetNetworkAddressSet(projectName string, name string) (int64, *api.NetworkAddressSet, error) {
+	var id int64 = int64(-1)
+	var addressesJSON string
+
+	addressSet := api.NetworkAddressSet{
+		NetworkAddressSetPost: api.NetworkAddressSetPost{
+			Name: name,
+		},
+	}
+
+	q := `
+		SELECT id, description, addresses
+		FROM networks_address_sets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+	arg1 := []interface{}{projectName, name}
+	arg2 := []interface{}{&id, &addressSet.Description, &addressesJSON}
+
+	err := dbQueryRowScan(c, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, ErrNoSuchObject
+		}
+
+		return -1, nil, err
+	}
+
+	addressSet.Addresses = []string{}
+	if addressesJSON != "" {
+		err = json.Unmarshal([]byte(addressesJSON), &addressSet.Addresses)
+		if err != nil {
+			return -1, nil, errors.Wrapf(err, "Failed unmarshalling addresses")
+		}
+	}
+
+	addressSet.Config, err = c.networkAddressSetConfig(id)
+	if err != nil {
+		return -1, nil, errors.Wrapf(err, "Failed loading config")
+	}
+
+	return id, &addressSet, nil
+}
+
+// networkAddressSetConfig returns the config map of the Network address set with the given ID.
+func (c *Cluster) networkAddressSetConfig(id int64) (map[string]string, error) {
+	var key, value string
+	query := `
+		SELECT key, value
+		FROM networks_address_sets_config
+		WHERE network_address_set_id=?
+	`
+	inargs := []interface{}{id}
+	outfmt := []interface{}{key, value}
+	results, err := queryScan(c, query, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]string, len(results))
+
+	for _, r := range results {
+		key = r[0].(string)
+		value = r[1].(string)
+
+		_, found := config[key]
+		if found {
+			return nil, fmt.Errorf("Duplicate config row found for key %q for network address set ID %d", key, id)
+		}
+
+		config[key] = value
+	}
+
+	return config, nil
+}
+
+// CreateNetworkAddressSet creates a new Network address set.
+func (c *Cluster) CreateNetworkAddressSet(projectName string, info *api.NetworkAddressSetsPost) (int64, error) {
+	var id int64
+	var err error
+	var addressesJSON []byte
+
+	if info.Addresses != nil {
+		addressesJSON, err = json.Marshal(info.Addresses)
+		if err != nil {
+			return -1, errors.Wrapf(err, "Failed marshalling addresses")
+		}
+	}
+
+	err = c.Transaction(func(tx *ClusterTx) error {
+		// Insert a new Network address set record.
+		result, err := tx.tx.Exec(`
+			INSERT INTO networks_address_sets (project_id, name, description, addresses)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?)
+		`, projectName, info.Name, info.Description, string(addressesJSON))
+		if err != nil {
+			return err
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = networkAddressSetConfigUpdate(tx.tx, id, info.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		id = -1
+	}
+
+	return id, err
+}
+
+// UpdateNetworkAddressSet updates the Network address set with the given ID.
+func (c *Cluster) UpdateNetworkAddressSet(id int64, config *api.NetworkAddressSetPut) error {
+	var err error
+	var addressesJSON []byte
+
+	if config.Addresses != nil {
+		addressesJSON, err = json.Marshal(config.Addresses)
+		if err != nil {
+			return errors.Wrapf(err, "Failed marshalling addresses")
+		}
+	}
+
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec(`
+			UPDATE networks_address_sets
+			SET description=?, addresses=?
+			WHERE id=?
+		`, config.Description, string(addressesJSON), id)
+		if err != nil {
+			return err
+		}
+
+		err = networkAddressSetConfigUpdate(tx.tx, id, config.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// networkAddressSetConfigUpdate replaces the Network address set config keys.
+func networkAddressSetConfigUpdate(tx *sql.Tx, id int64, config map[string]string) error {
+	_, err := tx.Exec("DELETE FROM networks_address_sets_config WHERE network_address_set_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO networks_address_sets_config (network_address_set_id, key, value) VALUES(?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return errors.Wrapf(err, "Failed inserting config")
+		}
+	}
+
+	return nil
+}
+
+// RenameNetworkAddressSet renames a Network address set.
+func (c *Cluster) RenameNetworkAddressSet(id int64, newName string) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("UPDATE networks_address_sets SET name=? WHERE id=?", newName, id)
+		return err
+	})
+}
+
+// DeleteNetworkAddressSet deletes the Network address set.
+func (c *Cluster) DeleteNetworkAddressSet(id int64) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("DELETE FROM networks_address_sets WHERE id=?", id)
+		return err
+	})
+}
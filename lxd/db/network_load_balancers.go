@@ -0,0 +1,278 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/db/query"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkLoadBalancerListenAddresses returns the listen addresses of the load balancers of a network.
+func (c *Cluster) GetNetworkLoadBalancerListenAddresses(networkID int64) ([]string, error) {
+	q := `SELECT listen_address FROM networks_load_balancers
+		WHERE network_id = ?
+		ORDER BY id
+	`
+	inargs := []interface{}{networkID}
+
+	var listenAddress string
+	outfmt := []interface{}{listenAddress}
+	result, err := queryScan(c, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]string, 0, len(result))
+	for _, r := range result {
+		response = append(response, r[0].(string))
+	}
+
+	return response, nil
+}
+
+// GetNetworkLoadBalancerListenAddressesOnUplink returns the listen addresses of the load balancers of all
+// networks using the given uplink network.
+func (c *ClusterTx) GetNetworkLoadBalancerListenAddressesOnUplink(uplinkNetworkName string) ([]string, error) {
+	q := `SELECT networks_load_balancers.listen_address
+		FROM networks_load_balancers
+		JOIN networks_config ON networks_config.network_id = networks_load_balancers.network_id
+		WHERE networks_config.key = "network" AND networks_config.value = ?
+		ORDER BY networks_load_balancers.id
+	`
+
+	return query.SelectStrings(c.tx, q, uplinkNetworkName)
+}
+
+// GetNetworkLoadBalancer returns the network load balancer with the given listen address on the given network.
+func (c *Cluster) GetNetworkLoadBalancer(networkID int64, listenAddress string) (int64, *api.NetworkLoadBalancer, error) {
+	var id int64 = int64(-1)
+	var backendsJSON string
+	var portsJSON string
+
+	lb := api.NetworkLoadBalancer{
+		ListenAddress: listenAddress,
+	}
+
+	q := `
+		SELECT id, description, backends, ports
+		FROM networks_load_balancers
+		WHERE network_id = ? AND listen_address = ?
+		LIMIT 1
+	`
+	arg1 := []interface{}{networkID, listenAddress}
+	arg2 := []interface{}{&id, &lb.Description, &backendsJSON, &portsJSON}
+
+	err := dbQueryRowScan(c, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, ErrNoSuchObject
+		}
+
+		return -1, nil, err
+	}
+
+	lb.Backends = []api.NetworkLoadBalancerBackend{}
+	if backendsJSON != "" {
+		err = json.Unmarshal([]byte(backendsJSON), &lb.Backends)
+		if err != nil {
+			return -1, nil, errors.Wrapf(err, "Failed unmarshalling backends")
+		}
+	}
+
+	lb.Ports = []api.NetworkLoadBalancerPort{}
+	if portsJSON != "" {
+		err = json.Unmarshal([]byte(portsJSON), &lb.Ports)
+		if err != nil {
+			return -1, nil, errors.Wrapf(err, "Failed unmarshalling ports")
+		}
+	}
+
+	lb.Config, err = c.networkLoadBalancerConfig(id)
+	if err != nil {
+		return -1, nil, errors.Wrapf(err, "Failed loading config")
+	}
+
+	return id, &lb, nil
+}
+
+// GetNetworkLoadBalancers returns all the load balancers of the given network.
+func (c *Cluster) GetNetworkLoadBalancers(networkID int64) ([]*api.NetworkLoadBalancer, error) {
+	listenAddresses, err := c.GetNetworkLoadBalancerListenAddresses(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	loadBalancers := make([]*api.NetworkLoadBalancer, 0, len(listenAddresses))
+	for _, listenAddress := range listenAddresses {
+		_, lb, err := c.GetNetworkLoadBalancer(networkID, listenAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		loadBalancers = append(loadBalancers, lb)
+	}
+
+	return loadBalancers, nil
+}
+
+// networkLoadBalancerConfig returns the config map of the network load balancer with the given ID.
+func (c *Cluster) networkLoadBalancerConfig(id int64) (map[string]string, error) {
+	var key, value string
+	query := `
+		SELECT key, value
+		FROM networks_load_balancers_config
+		WHERE network_load_balancer_id=?
+	`
+	inargs := []interface{}{id}
+	outfmt := []interface{}{key, value}
+	results, err := queryScan(c, query, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]string, len(results))
+
+	for _, r := range results {
+		key = r[0].(string)
+		value = r[1].(string)
+
+		_, found := config[key]
+		if found {
+			return nil, fmt.Errorf("Duplicate config row found for key %q for network load balancer ID %d", key, id)
+		}
+
+		config[key] = value
+	}
+
+	return config, nil
+}
+
+// CreateNetworkLoadBalancer creates a new network load balancer.
+func (c *Cluster) CreateNetworkLoadBalancer(networkID int64, info *api.NetworkLoadBalancersPost) (int64, error) {
+	var id int64
+	var err error
+	var backendsJSON, portsJSON []byte
+
+	if info.Backends != nil {
+		backendsJSON, err = json.Marshal(info.Backends)
+		if err != nil {
+			return -1, errors.Wrapf(err, "Failed marshalling backends")
+		}
+	}
+
+	if info.Ports != nil {
+		portsJSON, err = json.Marshal(info.Ports)
+		if err != nil {
+			return -1, errors.Wrapf(err, "Failed marshalling ports")
+		}
+	}
+
+	err = c.Transaction(func(tx *ClusterTx) error {
+		// Insert a new network load balancer record.
+		result, err := tx.tx.Exec(`
+			INSERT INTO networks_load_balancers (network_id, listen_address, description, backends, ports)
+			VALUES (?, ?, ?, ?, ?)
+		`, networkID, info.ListenAddress, info.Description, string(backendsJSON), string(portsJSON))
+		if err != nil {
+			return err
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = networkLoadBalancerConfigAdd(tx.tx, id, info.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		id = -1
+	}
+
+	return id, err
+}
+
+// networkLoadBalancerConfigAdd inserts network load balancer config keys.
+func networkLoadBalancerConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO networks_load_balancers_config (network_load_balancer_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return errors.Wrapf(err, "Failed inserting config")
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkLoadBalancer updates the network load balancer with the given ID.
+func (c *Cluster) UpdateNetworkLoadBalancer(id int64, config *api.NetworkLoadBalancerPut) error {
+	var err error
+	var backendsJSON, portsJSON []byte
+
+	if config.Backends != nil {
+		backendsJSON, err = json.Marshal(config.Backends)
+		if err != nil {
+			return errors.Wrapf(err, "Failed marshalling backends")
+		}
+	}
+
+	if config.Ports != nil {
+		portsJSON, err = json.Marshal(config.Ports)
+		if err != nil {
+			return errors.Wrapf(err, "Failed marshalling ports")
+		}
+	}
+
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec(`
+			UPDATE networks_load_balancers
+			SET description = ?, backends = ?, ports = ?
+			WHERE id = ?
+		`, config.Description, string(backendsJSON), string(portsJSON), id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.tx.Exec("DELETE FROM networks_load_balancers_config WHERE network_load_balancer_id=?", id)
+		if err != nil {
+			return err
+		}
+
+		err = networkLoadBalancerConfigAdd(tx.tx, id, config.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// DeleteNetworkLoadBalancer deletes the network load balancer.
+func (c *Cluster) DeleteNetworkLoadBalancer(id int64) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("DELETE FROM networks_load_balancers WHERE id=?", id)
+		return err
+	})
+}
@@ -0,0 +1,118 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db
+
+import (
+	"database/sql"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkReservations returns all the DHCP reservations of the given network.
+func (c *Cluster) GetNetworkReservations(networkID int64) ([]*api.NetworkReservation, error) {
+	q := `SELECT hwaddr, description, ipv4_address, ipv6_address, hostname
+		FROM networks_reservations
+		WHERE network_id = ?
+		ORDER BY id
+	`
+	inargs := []interface{}{networkID}
+
+	var hwaddr, description, ipv4Address, ipv6Address, hostname string
+	outfmt := []interface{}{hwaddr, description, ipv4Address, ipv6Address, hostname}
+	result, err := queryScan(c, q, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := make([]*api.NetworkReservation, 0, len(result))
+	for _, r := range result {
+		reservations = append(reservations, &api.NetworkReservation{
+			Hwaddr: r[0].(string),
+			NetworkReservationPut: api.NetworkReservationPut{
+				Description: r[1].(string),
+				IPv4Address: r[2].(string),
+				IPv6Address: r[3].(string),
+				Hostname:    r[4].(string),
+			},
+		})
+	}
+
+	return reservations, nil
+}
+
+// GetNetworkReservation returns the DHCP reservation with the given hardware address on the given network.
+func (c *Cluster) GetNetworkReservation(networkID int64, hwaddr string) (int64, *api.NetworkReservation, error) {
+	var id int64 = int64(-1)
+
+	reservation := api.NetworkReservation{
+		Hwaddr: hwaddr,
+	}
+
+	q := `
+		SELECT id, description, ipv4_address, ipv6_address, hostname
+		FROM networks_reservations
+		WHERE network_id = ? AND hwaddr = ?
+		LIMIT 1
+	`
+	arg1 := []interface{}{networkID, hwaddr}
+	arg2 := []interface{}{&id, &reservation.Description, &reservation.IPv4Address, &reservation.IPv6Address, &reservation.Hostname}
+
+	err := dbQueryRowScan(c, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, ErrNoSuchObject
+		}
+
+		return -1, nil, err
+	}
+
+	return id, &reservation, nil
+}
+
+// CreateNetworkReservation creates a new network DHCP reservation.
+func (c *Cluster) CreateNetworkReservation(networkID int64, info *api.NetworkReservationsPost) (int64, error) {
+	var id int64
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		result, err := tx.tx.Exec(`
+			INSERT INTO networks_reservations (network_id, hwaddr, description, ipv4_address, ipv6_address, hostname)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, networkID, info.Hwaddr, info.Description, info.IPv4Address, info.IPv6Address, info.Hostname)
+		if err != nil {
+			return err
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		id = -1
+	}
+
+	return id, err
+}
+
+// UpdateNetworkReservation updates the network DHCP reservation with the given ID.
+func (c *Cluster) UpdateNetworkReservation(id int64, config *api.NetworkReservationPut) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec(`
+			UPDATE networks_reservations
+			SET description = ?, ipv4_address = ?, ipv6_address = ?, hostname = ?
+			WHERE id = ?
+		`, config.Description, config.IPv4Address, config.IPv6Address, config.Hostname, id)
+		return err
+	})
+}
+
+// DeleteNetworkReservation deletes the network DHCP reservation.
+func (c *Cluster) DeleteNetworkReservation(id int64) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("DELETE FROM networks_reservations WHERE id=?", id)
+		return err
+	})
+}
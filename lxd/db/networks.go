@@ -250,6 +250,44 @@ WHERE networks.id = ? AND networks.state = ?
 	return configs, nil
 }
 
+// GetNetworkMembersConfig returns the member specific configuration of the network with the given ID, keyed by
+// cluster member name.
+func (c *ClusterTx) GetNetworkMembersConfig(networkID int64) (map[string]map[string]string, error) {
+	rows, err := c.tx.Query(`
+		SELECT nodes.name, networks_config.key, networks_config.value
+		FROM networks_config
+		JOIN nodes ON nodes.id = networks_config.node_id
+		WHERE networks_config.network_id = ?
+	`, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	configs := map[string]map[string]string{}
+	for rows.Next() {
+		var memberName, key, value string
+
+		err = rows.Scan(&memberName, &key, &value)
+		if err != nil {
+			return nil, err
+		}
+
+		if configs[memberName] == nil {
+			configs[memberName] = map[string]string{}
+		}
+
+		configs[memberName][key] = value
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
 // CreatePendingNetwork creates a new pending network on the node with the given name.
 func (c *ClusterTx) CreatePendingNetwork(node string, projectName string, name string, netType NetworkType, conf map[string]string) error {
 	// First check if a network with the given name exists, and, if so, that it's in the pending state.
@@ -492,11 +530,12 @@ type NetworkType int
 
 // Network types.
 const (
-	NetworkTypeBridge   NetworkType = iota // Network type bridge.
-	NetworkTypeMacvlan                     // Network type macvlan.
-	NetworkTypeSriov                       // Network type sriov.
-	NetworkTypeOVN                         // Network type ovn.
-	NetworkTypePhysical                    // Network type physical.
+	NetworkTypeBridge    NetworkType = iota // Network type bridge.
+	NetworkTypeMacvlan                      // Network type macvlan.
+	NetworkTypeSriov                        // Network type sriov.
+	NetworkTypeOVN                          // Network type ovn.
+	NetworkTypePhysical                     // Network type physical.
+	NetworkTypeWireguard                    // Network type wireguard.
 )
 
 // NetworkNode represents a network node.
@@ -589,6 +628,8 @@ func networkFillType(network *api.Network, netType NetworkType) {
 		network.Type = "ovn"
 	case NetworkTypePhysical:
 		network.Type = "physical"
+	case NetworkTypeWireguard:
+		network.Type = "wireguard"
 	default:
 		network.Type = "" // Unknown
 	}
@@ -843,6 +884,12 @@ func (c *Cluster) RenameNetwork(project string, oldName string, newName string)
 
 // NodeSpecificNetworkConfig lists all network config keys which are node-specific.
 var NodeSpecificNetworkConfig = []string{
+	"bgp.ipv4.nexthop",
+	"bgp.ipv6.nexthop",
 	"bridge.external_interfaces",
 	"parent",
+	"volatile.wireguard.public_key",
+	"wireguard.address",
+	"wireguard.endpoint",
+	"wireguard.routes",
 }
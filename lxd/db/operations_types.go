@@ -63,6 +63,7 @@ const (
 	OperationWarningsPruneResolved
 	OperationClusterJoinToken
 	OperationVolumeSnapshotRename
+	OperationRenewServerCertificate
 )
 
 // Description return a human-readable description of the operation type.
@@ -172,6 +173,8 @@ func (t OperationType) Description() string {
 		return "Restoring custom volume backup"
 	case OperationWarningsPruneResolved:
 		return "Pruning resolved warnings"
+	case OperationRenewServerCertificate:
+		return "Renewing server certificate"
 	default:
 		return "Executing operation"
 	}
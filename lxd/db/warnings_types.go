@@ -53,6 +53,24 @@ const (
 	WarningOfflineClusterMember
 	// WarningInstanceAutostartFailure represents the failure of instance autostart process after three retries
 	WarningInstanceAutostartFailure
+	// WarningInstanceRestartExhausted represents the instance restart policy giving up after too many restart attempts
+	WarningInstanceRestartExhausted
+	// WarningStoragePoolLowSpace represents the storage pool usage being above its warning threshold
+	WarningStoragePoolLowSpace
+	// WarningStoragePoolThinpoolMetadataLow represents the LVM thin pool metadata usage being above the warning threshold
+	WarningStoragePoolThinpoolMetadataLow
+	// WarningImageAutoUpdateFailure represents the failure of an image auto-update
+	WarningImageAutoUpdateFailure
+	// WarningSnapshotScheduleFailure represents the failure of a scheduled instance or custom volume snapshot
+	WarningSnapshotScheduleFailure
+	// WarningCertificateExpiry represents a trusted certificate nearing or past its expiry date
+	WarningCertificateExpiry
+	// WarningServerCertificateExpiry represents the server certificate nearing or past its expiry date
+	WarningServerCertificateExpiry
+	// WarningOVNNorthboundUnreachable represents the OVN northbound database being unreachable
+	WarningOVNNorthboundUnreachable
+	// WarningNetworkAddressConflict represents an address statically allocated to more than one entity on a network
+	WarningNetworkAddressConflict
 )
 
 // WarningTypeNames associates a warning code to its name.
@@ -80,6 +98,15 @@ var WarningTypeNames = map[WarningType]string{
 	WarningNetworkStartupFailure:                  "Failed to start network",
 	WarningOfflineClusterMember:                   "Offline cluster member",
 	WarningInstanceAutostartFailure:               "Failed to autostart instance",
+	WarningInstanceRestartExhausted:               "Instance restart attempts exhausted",
+	WarningStoragePoolLowSpace:                    "Storage pool usage above warning threshold",
+	WarningStoragePoolThinpoolMetadataLow:         "LVM thin pool metadata usage above warning threshold",
+	WarningImageAutoUpdateFailure:                 "Failed to auto-update image",
+	WarningSnapshotScheduleFailure:                "Failed to create scheduled snapshot",
+	WarningCertificateExpiry:                      "Trusted certificate is expiring",
+	WarningServerCertificateExpiry:                "Server certificate is expiring",
+	WarningOVNNorthboundUnreachable:               "Unable to connect to OVN northbound database",
+	WarningNetworkAddressConflict:                 "Conflicting network address allocations",
 }
 
 // WarningTypes associates a warning type to its type code.
@@ -140,6 +167,24 @@ func (t WarningType) Severity() WarningSeverity {
 		return WarningSeverityLow
 	case WarningInstanceAutostartFailure:
 		return WarningSeverityLow
+	case WarningInstanceRestartExhausted:
+		return WarningSeverityModerate
+	case WarningStoragePoolLowSpace:
+		return WarningSeverityModerate
+	case WarningStoragePoolThinpoolMetadataLow:
+		return WarningSeverityHigh
+	case WarningImageAutoUpdateFailure:
+		return WarningSeverityLow
+	case WarningSnapshotScheduleFailure:
+		return WarningSeverityModerate
+	case WarningCertificateExpiry:
+		return WarningSeverityModerate
+	case WarningServerCertificateExpiry:
+		return WarningSeverityHigh
+	case WarningOVNNorthboundUnreachable:
+		return WarningSeverityHigh
+	case WarningNetworkAddressConflict:
+		return WarningSeverityModerate
 	}
 
 	return WarningSeverityLow
@@ -3,8 +3,10 @@ package device
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -46,6 +48,32 @@ func IsBlockdev(path string) bool {
 	return false
 }
 
+// DiskParentBlocks returns the block devices (major:minor) backing the given host path. Partitions are resolved
+// to the block device they're part of, as that's what I/O is accounted against.
+func DiskParentBlocks(path string) ([]string, error) {
+	blocks, err := diskParentBlocks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		sysPath, err := filepath.EvalSymlinks(filepath.Join("/sys/dev/block", block))
+		if err == nil && shared.PathExists(filepath.Join(sysPath, "partition")) {
+			parent, err := ioutil.ReadFile(filepath.Join(filepath.Dir(sysPath), "dev"))
+			if err == nil {
+				block = strings.TrimSpace(string(parent))
+			}
+		}
+
+		if !shared.StringInSlice(block, devices) {
+			devices = append(devices, block)
+		}
+	}
+
+	return devices, nil
+}
+
 // DiskMount mounts a disk device.
 func DiskMount(srcPath string, dstPath string, readonly bool, recursive bool, propagation string, rawMountOptions string, fsName string) error {
 	var err error
@@ -15,8 +15,11 @@ import (
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/ip"
 	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/network/acl"
+	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
 	"github.com/lxc/lxd/shared/units"
@@ -542,3 +545,101 @@ func networkParsePortRange(r string) (int64, int64, error) {
 
 	return base, size, nil
 }
+
+// bgpOwner returns the owner string used for the BGP prefixes announced on behalf of an instance device.
+func bgpOwner(d *deviceCommon) string {
+	return fmt.Sprintf("instance_%d_%s", d.inst.ID(), d.name)
+}
+
+// bgpAddPrefixes announces the addresses and subnets found in the given device config keys. Single addresses
+// are announced as host routes. A nil next-hop makes the BGP server announce itself as the next-hop.
+func bgpAddPrefixes(d *deviceCommon, keys []string, nexthopV4 net.IP, nexthopV6 net.IP) error {
+	for _, key := range keys {
+		if d.config[key] == "" {
+			continue
+		}
+
+		for _, entry := range strings.Split(d.config[key], ",") {
+			entry = strings.TrimSpace(entry)
+
+			if !strings.Contains(entry, "/") {
+				if net.ParseIP(entry).To4() != nil {
+					entry = fmt.Sprintf("%s/32", entry)
+				} else {
+					entry = fmt.Sprintf("%s/128", entry)
+				}
+			}
+
+			_, subnet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return errors.Wrapf(err, "Invalid %q value %q", key, entry)
+			}
+
+			nexthop := nexthopV4
+			if subnet.IP.To4() == nil {
+				nexthop = nexthopV6
+			}
+
+			err = d.state.BGP.AddPrefix(*subnet, nexthop, bgpOwner(d))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// bgpRemovePrefixes withdraws all the prefixes announced on behalf of an instance device.
+func bgpRemovePrefixes(d *deviceCommon) error {
+	return d.state.BGP.RemovePrefixByOwner(bgpOwner(d))
+}
+
+// networkNICACLKeys are the config keys used to apply network ACLs to routed, macvlan and ipvlan NICs.
+var networkNICACLKeys = []string{
+	"security.acls",
+	"security.acls.default.ingress.action",
+	"security.acls.default.egress.action",
+	"security.acls.default.ingress.logged",
+	"security.acls.default.egress.logged",
+}
+
+// networkValidateNICACLs checks that the ACLs referenced by the NIC's "security.acls" setting exist.
+// The project is taken from instConf as d.inst is nil when validating profile devices.
+func networkValidateNICACLs(d *deviceCommon, instConf instance.ConfigReader) error {
+	if d.config["security.acls"] == "" {
+		return nil
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.Cluster, instConf.Project())
+	if err != nil {
+		return errors.Wrapf(err, "Failed loading network project name")
+	}
+
+	return acl.Exists(d.state, networkProjectName, util.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)...)
+}
+
+// networkSetupNICACLRules applies the NIC's network ACLs to the firewall chains of the specified interface.
+// If netnsPID is > 0 then interfaceName is the interface inside that process's network namespace.
+func networkSetupNICACLRules(d *deviceCommon, interfaceName string, netnsPID int) error {
+	if d.config["security.acls"] == "" {
+		return nil
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.Cluster, d.inst.Project())
+	if err != nil {
+		return errors.Wrapf(err, "Failed loading network project name")
+	}
+
+	err = acl.FirewallApplyNICACLRules(d.state, d.logger, networkProjectName, d.inst, d.name, interfaceName, netnsPID, d.config)
+	if err != nil {
+		return errors.Wrapf(err, "Failed applying network ACLs")
+	}
+
+	return nil
+}
+
+// networkClearNICACLRules removes the firewall chains used to apply the NIC's network ACLs.
+func networkClearNICACLRules(d *deviceCommon, netnsPID int) error {
+	return d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name, netnsPID)
+}
@@ -1523,7 +1523,7 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 		}
 
 		// Get the backing block devices (major:minor)
-		blocks, err := d.getParentBlocks(source)
+		blocks, err := diskParentBlocks(source)
 		if err != nil {
 			if readBps == 0 && readIops == 0 && writeBps == 0 && writeIops == 0 {
 				// If the device doesn't exist, there is no limit to clear so ignore the failure
@@ -1649,7 +1649,7 @@ func (d *disk) parseDiskLimit(readSpeed string, writeSpeed string) (int64, int64
 	return readBps, readIops, writeBps, writeIops, nil
 }
 
-func (d *disk) getParentBlocks(path string) ([]string, error) {
+func diskParentBlocks(path string) ([]string, error) {
 	var devices []string
 	var dev []string
 
@@ -1734,7 +1734,7 @@ func (d *disk) getParentBlocks(path string) ([]string, error) {
 				if shared.IsBlockdevPath(fields[0]) {
 					path = fields[0]
 				} else {
-					subDevices, err := d.getParentBlocks(fields[0])
+					subDevices, err := diskParentBlocks(fields[0])
 					if err != nil {
 						return nil, err
 					}
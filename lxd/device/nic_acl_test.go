@@ -0,0 +1,57 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/db"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// testConfigReader is a minimal instance.ConfigReader used to validate devices without an instance.
+type testConfigReader struct {
+	devices deviceConfig.Devices
+}
+
+func (c testConfigReader) Project() string                       { return project.Default }
+func (c testConfigReader) Type() instancetype.Type               { return instancetype.Container }
+func (c testConfigReader) Architecture() int                     { return 0 }
+func (c testConfigReader) ExpandedConfig() map[string]string     { return map[string]string{} }
+func (c testConfigReader) ExpandedDevices() deviceConfig.Devices { return c.devices }
+func (c testConfigReader) LocalConfig() map[string]string        { return map[string]string{} }
+func (c testConfigReader) LocalDevices() deviceConfig.Devices    { return c.devices }
+
+// Network ACLs are validated against the project of the instance config, as no instance is available when
+// validating profile devices.
+func TestValidate_NICSecurityACLs(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	_, err := cluster.CreateNetworkACL(project.Default, &api.NetworkACLsPost{NetworkACLPost: api.NetworkACLPost{Name: "web"}})
+	require.NoError(t, err)
+
+	s := &state.State{Cluster: cluster}
+
+	for _, nicType := range []string{"ipvlan", "macvlan", "routed"} {
+		t.Run(nicType, func(t *testing.T) {
+			conf := deviceConfig.Device{
+				"type":          "nic",
+				"nictype":       nicType,
+				"parent":        "eth0",
+				"security.acls": "web",
+			}
+
+			instConf := testConfigReader{devices: deviceConfig.Devices{"eth0": conf}}
+			assert.NoError(t, Validate(instConf, s, "eth0", conf.Clone()))
+
+			conf["security.acls"] = "missing"
+			assert.EqualError(t, Validate(instConf, s, "eth0", conf.Clone()), `Network ACL "missing" does not exist`)
+		})
+	}
+}
@@ -391,6 +391,24 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 	// Populate device config with volatile fields if needed.
 	networkVethFillFromVolatile(d.config, saveData)
 
+	// Use the addresses reserved for the NIC's MAC address on the managed network (if any) when not set.
+	// project.Default is used here as bridge networks don't support projects.
+	n, _ := network.LoadByName(d.state, project.Default, d.config["parent"])
+	if n != nil {
+		reservation, err := network.Reservation(d.state, n, d.config["hwaddr"])
+		if err != nil {
+			return nil, err
+		}
+
+		if reservation != nil && d.config["ipv4.address"] == "" {
+			d.config["ipv4.address"] = reservation.IPv4Address
+		}
+
+		if reservation != nil && d.config["ipv6.address"] == "" {
+			d.config["ipv6.address"] = reservation.IPv6Address
+		}
+	}
+
 	// Apply host-side routes to bridge interface.
 	err = networkNICRouteAdd(d.config["parent"], append(util.SplitNTrimSpace(d.config["ipv4.routes"], ",", -1, true), util.SplitNTrimSpace(d.config["ipv6.routes"], ",", -1, true)...)...)
 	if err != nil {
@@ -613,6 +631,22 @@ func (d *nicBridged) Remove() error {
 			return err
 		}
 
+		// Restore the dhcp-host line of the DHCP reservation for the NIC's MAC address (if any).
+		n, _ := network.LoadByName(d.state, project.Default, d.config["parent"])
+		if n != nil && shared.PathExists(shared.VarPath("networks", d.config["parent"], "dnsmasq.hosts")) {
+			reservation, err := network.Reservation(d.state, n, d.config["hwaddr"])
+			if err != nil {
+				return err
+			}
+
+			if reservation != nil {
+				err = dnsmasq.UpdateReservationEntry(d.config["parent"], n.Config(), reservation.Hwaddr, reservation.IPv4Address, reservation.IPv6Address, reservation.Hostname)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
 		// Reload dnsmasq to apply new settings if dnsmasq is running.
 		if shared.PathExists(shared.VarPath("networks", d.config["parent"], "dnsmasq.pid")) {
 			err = dnsmasq.Kill(d.config["parent"], true)
@@ -662,6 +696,33 @@ func (d *nicBridged) rebuildDnsmasqEntry() error {
 		}
 	}
 
+	// Use the addresses reserved for the NIC's MAC address (if any) when not set. The instance's dhcp-host line
+	// then replaces the reservation's own one.
+	n, err := network.LoadByName(d.state, project.Default, d.config["parent"])
+	if err != nil {
+		return err
+	}
+
+	reservation, err := network.Reservation(d.state, n, d.config["hwaddr"])
+	if err != nil {
+		return err
+	}
+
+	if reservation != nil {
+		if ipv4Address == "" {
+			ipv4Address = reservation.IPv4Address
+		}
+
+		if ipv6Address == "" {
+			ipv6Address = reservation.IPv6Address
+		}
+
+		err = dnsmasq.RemoveReservationEntry(d.config["parent"], reservation.Hwaddr)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = dnsmasq.UpdateStaticEntry(d.config["parent"], d.inst.Project(), d.inst.Name(), netConfig, d.config["hwaddr"], ipv4Address, ipv6Address)
 	if err != nil {
 		return err
@@ -1263,7 +1324,7 @@ func (d *nicBridged) State() (*api.InstanceStateNetwork, error) {
 		// Extract subnet sizes from bridge addresses if available.
 		netConfig := n.Config()
 		_, v4subnet, _ := net.ParseCIDR(netConfig["ipv4.address"])
-		_, v6subnet, _ := net.ParseCIDR(netConfig["ipv6.address"])
+		_, v6subnet, _ := net.ParseCIDR(network.IPv6Address(netConfig))
 
 		if v4subnet != nil {
 			mask, _ := v4subnet.Mask.Size()
@@ -47,6 +47,8 @@ func (d *nicIPVLAN) validateConfig(instConf instance.ConfigReader) error {
 		"gvrp",
 	}
 
+	optionalFields = append(optionalFields, networkNICACLKeys...)
+
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 	rules["gvrp"] = validate.Optional(validate.IsBool)
 	rules["ipv4.address"] = func(value string) error {
@@ -134,6 +136,12 @@ func (d *nicIPVLAN) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("host_table option cannot be used in l2 mode")
 	}
 
+	// Check Security ACLs exist.
+	err = networkValidateNICACLs(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -358,6 +366,12 @@ func (d *nicIPVLAN) setupParentSysctls(parentName string) error {
 
 // postStart is run after the instance is started.
 func (d *nicIPVLAN) postStart() error {
+	// Apply network ACLs inside the instance as the ipvlan interface has no host side.
+	err := networkSetupNICACLRules(&d.deviceCommon, d.config["name"], d.inst.InitPID())
+	if err != nil {
+		return err
+	}
+
 	if d.config["ipv4.address"] != "" {
 		// Add static routes to instance IPs to custom routing tables if specified.
 		// This is in addition to the static route added by liblxc to the main routing table.
@@ -403,6 +417,14 @@ func (d *nicIPVLAN) postStart() error {
 
 // Stop is run when the device is removed from the instance.
 func (d *nicIPVLAN) Stop() (*deviceConfig.RunConfig, error) {
+	// Remove the network ACL rules from inside the instance if the device is being detached from it.
+	if d.config["security.acls"] != "" && d.inst.IsRunning() && d.inst.InitPID() > 0 {
+		err := networkClearNICACLRules(&d.deviceCommon, d.inst.InitPID())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	v := d.volatileGet()
 	runConf := deviceConfig.RunConfig{
 		PostHooks: []func() error{d.postStop},
@@ -45,6 +45,8 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		"gvrp",
 	}
 
+	optionalFields = append(optionalFields, networkNICACLKeys...)
+
 	// Check that if network proeperty is set that conflicting keys are not present.
 	if d.config["network"] != "" {
 		requiredFields = append(requiredFields, "network")
@@ -93,6 +95,17 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	// Network ACLs are applied inside the instance, which is only possible with containers.
+	if d.config["security.acls"] != "" && instConf.Type() != instancetype.Container {
+		return fmt.Errorf("Network ACLs are only supported on macvlan NICs of containers")
+	}
+
+	// Check Security ACLs exist.
+	err = networkValidateNICACLs(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -226,12 +239,30 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 			}...)
 	}
 
+	if d.config["security.acls"] != "" {
+		runConf.PostHooks = append(runConf.PostHooks, d.postStart)
+	}
+
 	revert.Success()
 	return &runConf, nil
 }
 
+// postStart is run after the device is added to the instance.
+func (d *nicMACVLAN) postStart() error {
+	// Apply network ACLs inside the instance as the macvlan interface has no host side.
+	return networkSetupNICACLRules(&d.deviceCommon, d.config["name"], d.inst.InitPID())
+}
+
 // Stop is run when the device is removed from the instance.
 func (d *nicMACVLAN) Stop() (*deviceConfig.RunConfig, error) {
+	// Remove the network ACL rules from inside the instance if the device is being detached from it.
+	if d.config["security.acls"] != "" && d.inst.IsRunning() && d.inst.InitPID() > 0 {
+		err := networkClearNICACLRules(&d.deviceCommon, d.inst.InitPID())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	v := d.volatileGet()
 	runConf := deviceConfig.RunConfig{
 		PostHooks: []func() error{d.postStop},
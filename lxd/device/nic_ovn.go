@@ -151,7 +151,7 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 			return fmt.Errorf("Cannot specify %q when DHCP or %q are disabled on network %q", "ipv6.address", "ipv6.dhcp.stateful", d.config["network"])
 		}
 
-		_, subnet, err := net.ParseCIDR(netConfig["ipv6.address"])
+		_, subnet, err := net.ParseCIDR(network.IPv6Address(netConfig))
 		if err != nil {
 			return errors.Wrapf(err, "Invalid network ipv6.address")
 		}
@@ -338,6 +338,14 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Announce the external routes through BGP, using the OVN router's uplink address as the next-hop.
+	err = bgpAddPrefixes(&d.deviceCommon, []string{"ipv4.routes.external", "ipv6.routes.external"}, net.ParseIP(d.network.Config()["volatile.network.ipv4.address"]), net.ParseIP(d.network.Config()["volatile.network.ipv6.address"]))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed announcing BGP prefixes")
+	}
+
+	revert.Add(func() { bgpRemovePrefixes(&d.deviceCommon) })
+
 	runConf := deviceConfig.RunConfig{}
 	runConf.NetworkInterface = []deviceConfig.RunConfigItem{
 		{Key: "type", Value: "phys"},
@@ -458,6 +466,12 @@ func (d *nicOVN) Stop() (*deviceConfig.RunConfig, error) {
 		d.logger.Error("Failed to remove OVN device port", log.Ctx{"err": err})
 	}
 
+	// Withdraw the BGP prefixes.
+	err = bgpRemovePrefixes(&d.deviceCommon)
+	if err != nil {
+		d.logger.Error("Failed to withdraw BGP prefixes", log.Ctx{"err": err})
+	}
+
 	return &runConf, nil
 }
 
@@ -522,7 +536,7 @@ func (d *nicOVN) State() (*api.InstanceStateNetwork, error) {
 
 	// Extract subnet sizes from bridge addresses.
 	_, v4subnet, _ := net.ParseCIDR(netConfig["ipv4.address"])
-	_, v6subnet, _ := net.ParseCIDR(netConfig["ipv6.address"])
+	_, v6subnet, _ := net.ParseCIDR(network.IPv6Address(netConfig))
 
 	var v4mask string
 	if v4subnet != nil {
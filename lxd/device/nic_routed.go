@@ -37,7 +37,7 @@ func (d *nicRouted) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max"}
+	return append([]string{"limits.ingress", "limits.egress", "limits.max"}, networkNICACLKeys...)
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -71,6 +71,8 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"gvrp",
 	}
 
+	optionalFields = append(optionalFields, networkNICACLKeys...)
+
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 	rules["ipv4.address"] = validate.Optional(validate.IsNetworkAddressV4List)
 	rules["ipv6.address"] = validate.Optional(validate.IsNetworkAddressV6List)
@@ -97,6 +99,12 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	// Check Security ACLs exist.
+	err = networkValidateNICACLs(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -339,6 +347,17 @@ func (d *nicRouted) Update(oldDevices deviceConfig.Devices, isRunning bool) erro
 		if err != nil {
 			return err
 		}
+
+		// Apply network ACLs, removing any existing rules if none are set anymore.
+		if d.config["security.acls"] != "" {
+			err = networkSetupNICACLRules(&d.deviceCommon, d.config["host_name"], 0)
+		} else {
+			err = networkClearNICACLRules(&d.deviceCommon, 0)
+		}
+
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -375,6 +394,12 @@ func (d *nicRouted) postStart() error {
 		return errors.Wrapf(err, "Error setting up reverse path filter")
 	}
 
+	// Apply network ACLs to the host side of the veth pair.
+	err = networkSetupNICACLRules(&d.deviceCommon, d.config["host_name"], 0)
+	if err != nil {
+		return err
+	}
+
 	if d.config["ipv4.address"] != "" {
 		// Add link-local gateway IPs to the host end of the veth pair. This ensures that
 		// liveness detection of the gateways inside the instance work and ensure that traffic
@@ -445,6 +470,12 @@ func (d *nicRouted) postStart() error {
 		}
 	}
 
+	// Announce the instance addresses through BGP.
+	err = bgpAddPrefixes(&d.deviceCommon, []string{"ipv4.address", "ipv6.address"}, nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed announcing BGP prefixes")
+	}
+
 	return nil
 }
 
@@ -507,6 +538,18 @@ func (d *nicRouted) postStop() error {
 		errs = append(errs, err)
 	}
 
+	// Remove network ACL rules.
+	err = networkClearNICACLRules(&d.deviceCommon, 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// Withdraw the BGP prefixes.
+	err = bgpRemovePrefixes(&d.deviceCommon)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("%v", errs)
 	}
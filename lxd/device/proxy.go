@@ -6,8 +6,10 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -31,6 +33,14 @@ import (
 	"github.com/lxc/lxd/shared/validate"
 )
 
+// proxyKernelWaitTimeout is how long kernel mode proxies wait for the instance to get a matching address.
+const proxyKernelWaitTimeout = 2 * time.Minute
+
+// proxyKernelPending holds the cancel channels of the kernel mode proxies waiting for the instance to get a
+// matching address, keyed by project, instance and device name. The lock also serializes their setup with Stop.
+var proxyKernelPending = map[string]chan struct{}{}
+var proxyKernelPendingLock sync.Mutex
+
 type proxy struct {
 	deviceCommon
 }
@@ -78,6 +88,7 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		"bind":           validateBind,
 		"mode":           unixValidOctalFileMode,
 		"nat":            validate.Optional(validate.IsBool),
+		"kernel":         validate.Optional(validate.IsBool),
 		"gid":            unixValidUserID,
 		"uid":            unixValidUserID,
 		"security.uid":   unixValidUserID,
@@ -90,8 +101,8 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
-	if instConf.Type() == instancetype.VM && !shared.IsTrue(d.config["nat"]) {
-		return fmt.Errorf("Only NAT mode is supported for proxies on VM instances")
+	if shared.IsTrue(d.config["nat"]) && shared.IsTrue(d.config["kernel"]) {
+		return fmt.Errorf("NAT and kernel modes cannot be used together")
 	}
 
 	listenAddr, err := ProxyParseAddr(d.config["listen"])
@@ -104,13 +115,19 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	kernelMode := d.kernelMode(listenAddr, connectAddr)
+
+	if instConf.Type() == instancetype.VM && !shared.IsTrue(d.config["nat"]) && !kernelMode {
+		return fmt.Errorf("Only NAT or kernel mode is supported for proxies on VM instances")
+	}
+
 	if len(connectAddr.Addr) > len(listenAddr.Addr) {
 		// Cannot support single port -> multiple port
 		return fmt.Errorf("Cannot map a single port to multiple ports")
 	}
 
-	if shared.IsTrue(d.config["proxy_protocol"]) && (!strings.HasPrefix(d.config["connect"], "tcp") || shared.IsTrue(d.config["nat"])) {
-		return fmt.Errorf("The PROXY header can only be sent to tcp servers in non-nat mode")
+	if shared.IsTrue(d.config["proxy_protocol"]) && (!strings.HasPrefix(d.config["connect"], "tcp") || shared.IsTrue(d.config["nat"]) || kernelMode) {
+		return fmt.Errorf("The PROXY header can only be sent to tcp servers in non-nat and non-kernel mode")
 	}
 
 	if (!strings.HasPrefix(d.config["listen"], "unix:") || strings.HasPrefix(d.config["listen"], "unix:@")) &&
@@ -166,9 +183,61 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	if kernelMode {
+		if d.config["bind"] != "" && d.config["bind"] != "host" {
+			return fmt.Errorf("Only host-bound proxies can use kernel mode")
+		}
+
+		var ipVersion uint // Records which IP version we are using, as these cannot be mixed in kernel mode.
+
+		for _, addrStr := range append(listenAddr.Addr, connectAddr.Addr...) {
+			ipStr, _, err := net.SplitHostPort(addrStr)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(ipStr)
+			if ip == nil {
+				return fmt.Errorf("Only IP addresses can be used in kernel mode")
+			}
+
+			addrIPVersion := uint(4)
+			if ip.To4() == nil {
+				addrIPVersion = 6
+			}
+
+			if ipVersion != 0 && ipVersion != addrIPVersion {
+				return fmt.Errorf("Cannot mix IP versions between listen and connect in kernel mode")
+			}
+
+			ipVersion = addrIPVersion
+		}
+
+		for _, listenAddrStr := range listenAddr.Addr {
+			ipStr, _, err := net.SplitHostPort(listenAddrStr)
+			if err != nil {
+				return err
+			}
+
+			if net.ParseIP(ipStr).IsLoopback() {
+				return fmt.Errorf("Cannot listen on loopback address %q when in kernel mode", ipStr)
+			}
+		}
+	}
+
 	return nil
 }
 
+// kernelMode returns true if the proxy traffic is forwarded by the kernel rather than by forkproxy.
+// Unix sockets and proxying between different connection types always require forkproxy.
+func (d *proxy) kernelMode(listenAddr *deviceConfig.ProxyAddress, connectAddr *deviceConfig.ProxyAddress) bool {
+	if !shared.IsTrue(d.config["kernel"]) {
+		return false
+	}
+
+	return listenAddr.ConnType != "unix" && listenAddr.ConnType == connectAddr.ConnType
+}
+
 // validateEnvironment checks the runtime environment for correctness.
 func (d *proxy) validateEnvironment() error {
 	if d.name == "" {
@@ -193,6 +262,20 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 				return d.setupNAT()
 			}
 
+			listenAddr, err := ProxyParseAddr(d.config["listen"])
+			if err != nil {
+				return err
+			}
+
+			connectAddr, err := ProxyParseAddr(d.config["connect"])
+			if err != nil {
+				return err
+			}
+
+			if d.kernelMode(listenAddr, connectAddr) {
+				return d.setupKernel(listenAddr, connectAddr)
+			}
+
 			proxyValues, err := d.setupProxyProcInfo()
 			if err != nil {
 				return err
@@ -309,6 +392,15 @@ func (d *proxy) checkProcStarted(logPath string) (bool, error) {
 
 // Stop is run when the device is removed from the instance.
 func (d *proxy) Stop() (*deviceConfig.RunConfig, error) {
+	// Cancel any kernel mode setup still waiting for the instance's address.
+	proxyKernelPendingLock.Lock()
+	cancel, ok := proxyKernelPending[d.kernelPendingKey()]
+	if ok {
+		close(cancel)
+		delete(proxyKernelPending, d.kernelPendingKey())
+	}
+	proxyKernelPendingLock.Unlock()
+
 	// Remove possible iptables entries
 	err := d.state.Firewall.InstanceClearProxyNAT(d.inst.Project(), d.inst.Name(), d.name)
 	if err != nil {
@@ -414,7 +506,252 @@ func (d *proxy) setupNAT() error {
 		}
 	}
 
-	err = d.checkBridgeNetfilterEnabled(ipFamily)
+	err = d.setupBridgeHairpin(ipFamily, hostName)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.Firewall.InstanceSetupProxyNAT(d.inst.Project(), d.inst.Name(), d.name, listenAddr, connectAddr)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setupKernel forwards the proxy's listen addresses to the instance using DNAT firewall rules.
+// Unlike NAT mode, the connect address doesn't need to be a static IP of a bridged NIC, so any NIC type can be used.
+func (d *proxy) setupKernel(listenAddr *deviceConfig.ProxyAddress, connectAddr *deviceConfig.ProxyAddress) error {
+	connectHost, _, err := net.SplitHostPort(connectAddr.Addr[0])
+	if err != nil {
+		return err
+	}
+
+	ipFamily := "ipv4"
+	if strings.Contains(connectHost, ":") {
+		ipFamily = "ipv6"
+	}
+
+	connectIP, hostName, err := d.kernelConnectIP(connectHost, ipFamily)
+	if err != nil {
+		return err
+	}
+
+	// The instance doesn't have a matching address yet (e.g. still waiting for DHCP), set the forwarding up
+	// once it does rather than holding up the start of the instance.
+	if connectIP == nil {
+		d.setupKernelDeferred(listenAddr, connectAddr, connectHost, ipFamily)
+		return nil
+	}
+
+	return d.setupKernelForward(listenAddr, connectAddr, ipFamily, connectIP, hostName)
+}
+
+// kernelPendingKey returns the key of the device in proxyKernelPending.
+func (d *proxy) kernelPendingKey() string {
+	return project.Instance(d.inst.Project(), d.inst.Name()) + "/" + d.name
+}
+
+// setupKernelDeferred waits in the background for the instance to get an address matching the connect host and
+// then sets up the forwarding to it. It gives up after proxyKernelWaitTimeout or when the device is stopped.
+func (d *proxy) setupKernelDeferred(listenAddr *deviceConfig.ProxyAddress, connectAddr *deviceConfig.ProxyAddress, connectHost string, ipFamily string) {
+	key := d.kernelPendingKey()
+	cancel := make(chan struct{})
+
+	proxyKernelPendingLock.Lock()
+	previous, ok := proxyKernelPending[key]
+	if ok {
+		close(previous)
+	}
+
+	proxyKernelPending[key] = cancel
+	proxyKernelPendingLock.Unlock()
+
+	go func() {
+		defer func() {
+			proxyKernelPendingLock.Lock()
+			if proxyKernelPending[key] == cancel {
+				delete(proxyKernelPending, key)
+			}
+			proxyKernelPendingLock.Unlock()
+		}()
+
+		logCtx := log.Ctx{"project": d.inst.Project(), "instance": d.inst.Name(), "device": d.name}
+
+		deadline := time.Now().Add(proxyKernelWaitTimeout)
+		for time.Now().Before(deadline) {
+			select {
+			case <-cancel:
+				return
+			case <-time.After(time.Second):
+			}
+
+			connectIP, hostName, err := d.kernelConnectIP(connectHost, ipFamily)
+			if err != nil {
+				logCtx["err"] = err
+				logger.Error("Failed getting proxy connect IP", logCtx)
+				return
+			}
+
+			if connectIP == nil {
+				continue
+			}
+
+			// Check the device wasn't stopped in the meantime while holding the lock Stop takes.
+			proxyKernelPendingLock.Lock()
+			select {
+			case <-cancel:
+			default:
+				err = d.setupKernelForward(listenAddr, connectAddr, ipFamily, connectIP, hostName)
+			}
+			proxyKernelPendingLock.Unlock()
+
+			if err != nil {
+				logCtx["err"] = err
+				logger.Error("Failed setting up proxy forwarding", logCtx)
+			}
+
+			return
+		}
+
+		logCtx["connect"] = connectHost
+		logger.Warn("Proxy connect IP cannot be used with any of the instance's addresses", logCtx)
+	}()
+}
+
+// setupKernelForward sets up the DNAT firewall rules forwarding the listen addresses to the connect IP.
+func (d *proxy) setupKernelForward(listenAddr *deviceConfig.ProxyAddress, connectAddr *deviceConfig.ProxyAddress, ipFamily string, connectIP net.IP, hostName string) error {
+	// Override the host part of the connectAddr.Addr to the chosen connect IP.
+	for i, addr := range connectAddr.Addr {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+
+		connectAddr.Addr[i] = net.JoinHostPort(connectIP.String(), port)
+	}
+
+	// Hairpin mode is only needed when the connect IP belongs to a bridged NIC.
+	if hostName != "" {
+		err := d.setupBridgeHairpin(ipFamily, hostName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := d.state.Firewall.InstanceSetupProxyNAT(d.inst.Project(), d.inst.Name(), d.name, listenAddr, connectAddr)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// kernelConnectIP returns the instance IP that kernel mode traffic should be forwarded to, along with the bridge
+// port name of the NIC it belongs to (empty if the NIC isn't bridged). If the connect host is a wildcard or loopback
+// address then the first instance IP of the IP family is used. The statically configured NIC addresses are checked
+// first, followed by the addresses reported by the running instance. Returns a nil IP if none matches yet.
+func (d *proxy) kernelConnectIP(connectHost string, ipFamily string) (net.IP, string, error) {
+	hostIP := net.ParseIP(connectHost)
+	anyIP := hostIP.IsUnspecified() || hostIP.IsLoopback()
+
+	matchIP := func(ip net.IP) bool {
+		if ip == nil || (ip.To4() == nil) != (ipFamily == "ipv6") {
+			return false
+		}
+
+		return anyIP || ip.Equal(hostIP)
+	}
+
+	// bridgePort returns the host side interface name of the NIC if it is connected to a bridge.
+	bridgePort := func(devName string, devConfig deviceConfig.Device) (string, error) {
+		nicType, err := nictype.NICType(d.state, d.inst.Project(), devConfig)
+		if err != nil {
+			return "", err
+		}
+
+		if nicType != "bridged" {
+			return "", nil
+		}
+
+		return d.inst.ExpandedConfig()[fmt.Sprintf("volatile.%s.host_name", devName)], nil
+	}
+
+	nics := deviceConfig.Devices{}
+	for devName, devConfig := range d.inst.ExpandedDevices() {
+		if devConfig["type"] == "nic" {
+			nics[devName] = devConfig
+		}
+	}
+
+	// Check the static addresses of the instance's NICs.
+	for _, nic := range nics.Sorted() {
+		for _, addr := range util.SplitNTrimSpace(nic.Config[fmt.Sprintf("%s.address", ipFamily)], ",", -1, true) {
+			ip := net.ParseIP(addr)
+			if !matchIP(ip) {
+				continue
+			}
+
+			hostName, err := bridgePort(nic.Name, nic.Config)
+			if err != nil {
+				return nil, "", err
+			}
+
+			return ip, hostName, nil
+		}
+	}
+
+	// Check the addresses of the running instance.
+	state, err := d.inst.RenderState()
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "Failed getting instance state")
+	}
+
+	ifNames := make([]string, 0, len(state.Network))
+	for ifName := range state.Network {
+		ifNames = append(ifNames, ifName)
+	}
+
+	sort.Strings(ifNames)
+
+	for _, ifName := range ifNames {
+		network := state.Network[ifName]
+		if network.Type == "loopback" {
+			continue
+		}
+
+		for _, addr := range network.Addresses {
+			ip := net.ParseIP(addr.Address)
+			if addr.Scope != "global" || !matchIP(ip) {
+				continue
+			}
+
+			// Find the NIC that the interface belongs to using its host side interface name.
+			for _, nic := range nics.Sorted() {
+				if network.HostName == "" || d.inst.ExpandedConfig()[fmt.Sprintf("volatile.%s.host_name", nic.Name)] != network.HostName {
+					continue
+				}
+
+				hostName, err := bridgePort(nic.Name, nic.Config)
+				if err != nil {
+					return nil, "", err
+				}
+
+				return ip, hostName, nil
+			}
+
+			return ip, "", nil
+		}
+	}
+
+	return nil, "", nil
+}
+
+// setupBridgeHairpin enables hairpin mode on the instance's bridge port if bridge netfilter is enabled, so that the
+// instance can connect to the proxy's listen address and be forwarded back to itself. If bridge netfilter is not
+// enabled then a warning is recorded instead.
+func (d *proxy) setupBridgeHairpin(ipFamily string, hostName string) error {
+	err := d.checkBridgeNetfilterEnabled(ipFamily)
 	if err != nil {
 		msg := fmt.Sprintf("%v. Instances using the bridge will not be able to connect to the proxy's listen IP", err)
 
@@ -436,7 +773,7 @@ func (d *proxy) setupNAT() error {
 
 		// br_netfilter is enabled, so we need to enable hairpin mode on instance's bridge port otherwise
 		// the instances on the bridge will not be able to connect to the proxy device's listn IP and the
-		// NAT rule added by the firewall below to allow instance <-> instance traffic will also not work.
+		// NAT rule added by the firewall to allow instance <-> instance traffic will also not work.
 		link := &ip.Link{Name: hostName}
 		err = link.BridgeLinkSetHairpin(true)
 		if err != nil {
@@ -444,11 +781,6 @@ func (d *proxy) setupNAT() error {
 		}
 	}
 
-	err = d.state.Firewall.InstanceSetupProxyNAT(d.inst.Project(), d.inst.Name(), d.name, listenAddr, connectAddr)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/daemon"
+	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/project"
@@ -39,6 +41,12 @@ func devLxdServer(d *Daemon) *http.Server {
 	}
 }
 
+// devlxdConfigValueMaxSize is the maximum size of a config value set through devlxd.
+const devlxdConfigValueMaxSize = 64 * 1024
+
+// devlxdConfigMaxKeys is the maximum number of user keys an instance can have for devlxd to add a new one.
+const devlxdConfigMaxKeys = 64
+
 type devLxdResponse struct {
 	content interface{}
 	code    int
@@ -71,12 +79,16 @@ var devlxdConfigGet = devLxdHandler{"/1.0/config", func(d *Daemon, c instance.In
 	return okResponse(filtered, "json")
 }}
 
-var devlxdConfigKeyGet = devLxdHandler{"/1.0/config/{key}", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+var devlxdConfigKey = devLxdHandler{"/1.0/config/{key}", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 	key := mux.Vars(r)["key"]
 	if !strings.HasPrefix(key, "user.") {
 		return &devLxdResponse{"not authorized", http.StatusForbidden, "raw"}
 	}
 
+	if r.Method == "PUT" {
+		return devlxdConfigKeyPut(d, c, key, r)
+	}
+
 	value, ok := c.ExpandedConfig()[key]
 	if !ok {
 		return &devLxdResponse{"not found", http.StatusNotFound, "raw"}
@@ -85,6 +97,73 @@ var devlxdConfigKeyGet = devLxdHandler{"/1.0/config/{key}", func(d *Daemon, c in
 	return okResponse(value, "raw")
 }}
 
+// devlxdConfigKeyPut sets a user config key from within the instance.
+// This requires security.devlxd.config to be enabled as every write is a database update. The cloud-init keys are
+// excluded as they are read by the instance at boot.
+func devlxdConfigKeyPut(d *Daemon, c instance.Instance, key string, r *http.Request) *devLxdResponse {
+	if !shared.IsTrue(c.ExpandedConfig()["security.devlxd.config"]) {
+		return &devLxdResponse{"not authorized", http.StatusForbidden, "raw"}
+	}
+
+	if shared.StringInSlice(key, []string{"user.meta-data", "user.network-config", "user.user-data", "user.vendor-data"}) {
+		return &devLxdResponse{"not authorized", http.StatusForbidden, "raw"}
+	}
+
+	value, err := ioutil.ReadAll(io.LimitReader(r.Body, devlxdConfigValueMaxSize+1))
+	if err != nil {
+		return &devLxdResponse{"internal server error", http.StatusInternalServerError, "raw"}
+	}
+
+	if len(value) > devlxdConfigValueMaxSize {
+		return &devLxdResponse{"value too large", http.StatusRequestEntityTooLarge, "raw"}
+	}
+
+	config := map[string]string{}
+	for k, v := range c.LocalConfig() {
+		config[k] = v
+	}
+
+	// An empty value unsets the key.
+	if len(value) == 0 {
+		delete(config, key)
+	} else {
+		_, exists := config[key]
+		if !exists {
+			count := 0
+			for k := range config {
+				if strings.HasPrefix(k, "user.") {
+					count++
+				}
+			}
+
+			if count >= devlxdConfigMaxKeys {
+				return &devLxdResponse{"too many keys", http.StatusForbidden, "raw"}
+			}
+		}
+
+		config[key] = string(value)
+	}
+
+	args := db.InstanceArgs{
+		Architecture: c.Architecture(),
+		Config:       config,
+		Description:  c.Description(),
+		Devices:      c.LocalDevices(),
+		Ephemeral:    c.IsEphemeral(),
+		Profiles:     c.Profiles(),
+		Project:      c.Project(),
+		Type:         c.Type(),
+		Snapshot:     c.IsSnapshot(),
+	}
+
+	err = c.Update(args, true)
+	if err != nil {
+		return &devLxdResponse{"internal server error", http.StatusInternalServerError, "raw"}
+	}
+
+	return okResponse("", "raw")
+}
+
 var devlxdImageExport = devLxdHandler{"/1.0/images/{fingerprint}/export", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 	if !shared.IsTrue(c.ExpandedConfig()["security.devlxd.images"]) {
 		return &devLxdResponse{"not authorized", http.StatusForbidden, "raw"}
@@ -171,7 +250,7 @@ var handlers = []devLxdHandler{
 	}},
 	devlxdAPIGet,
 	devlxdConfigGet,
-	devlxdConfigKeyGet,
+	devlxdConfigKey,
 	devlxdMetadataGet,
 	devlxdEventsGet,
 	devlxdImageExport,
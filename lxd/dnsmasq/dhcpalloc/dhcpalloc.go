@@ -82,6 +82,7 @@ type Network interface {
 	Name() string
 	Type() string
 	Config() map[string]string
+	IPv6Address() string
 	DHCPv4Subnet() *net.IPNet
 	DHCPv6Subnet() *net.IPNet
 	DHCPv4Ranges() []shared.IPRange
@@ -249,7 +250,9 @@ func (t *Transaction) getDHCPFreeIPv4(usedIPs map[[4]byte]dnsmasq.DHCPAllocation
 // device's MAC address. Finally if stateful custom ranges are enabled, then a free IP is picked
 // from the ranges configured.
 func (t *Transaction) getDHCPFreeIPv6(usedIPs map[[16]byte]dnsmasq.DHCPAllocation, instName string, mac net.HardwareAddr) (net.IP, error) {
-	lxdIP, subnet, err := net.ParseCIDR(t.opts.Network.Config()["ipv6.address"])
+	netConfig := t.opts.Network.Config()
+
+	lxdIP, subnet, err := net.ParseCIDR(t.opts.Network.IPv6Address())
 	if err != nil {
 		return nil, err
 	}
@@ -266,8 +269,6 @@ func (t *Transaction) getDHCPFreeIPv6(usedIPs map[[16]byte]dnsmasq.DHCPAllocatio
 		}
 	}
 
-	netConfig := t.opts.Network.Config()
-
 	// Try using an EUI64 IP when in either SLAAC or DHCPv6 stateful mode without custom ranges.
 	if !shared.IsTrue(netConfig["ipv6.dhcp.stateful"]) || netConfig["ipv6.dhcp.ranges"] == "" {
 		IP, err := eui64.ParseMAC(subnet.IP, mac)
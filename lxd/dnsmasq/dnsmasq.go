@@ -67,6 +67,53 @@ func RemoveStaticEntry(network string, projectName string, instanceName string)
 	return nil
 }
 
+// UpdateReservationEntry writes a single dhcp-host line for a network DHCP reservation.
+// The hwaddr can either be a MAC address or a DHCPv6 DUID prefixed with "id:".
+func UpdateReservationEntry(network string, netConfig map[string]string, hwaddr string, ipv4Address string, ipv6Address string, hostname string) error {
+	hwaddr = strings.ToLower(hwaddr)
+	line := hwaddr
+
+	// Generate the dhcp-host line
+	if ipv4Address != "" {
+		line += fmt.Sprintf(",%s", ipv4Address)
+	}
+
+	if ipv6Address != "" {
+		line += fmt.Sprintf(",[%s]", ipv6Address)
+	}
+
+	if hostname != "" && (netConfig["dns.mode"] == "" || netConfig["dns.mode"] == "managed") {
+		line += fmt.Sprintf(",%s", hostname)
+	}
+
+	if line == hwaddr {
+		return nil
+	}
+
+	err := ioutil.WriteFile(shared.VarPath("networks", network, "dnsmasq.hosts", reservationFileName(hwaddr)), []byte(line+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveReservationEntry removes a single dhcp-host line for a network DHCP reservation.
+func RemoveReservationEntry(network string, hwaddr string) error {
+	err := os.Remove(shared.VarPath("networks", network, "dnsmasq.hosts", reservationFileName(hwaddr)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// reservationFileName returns the name of the dhcp-host file of a network DHCP reservation.
+// Instance names can't contain a ".", so the reservation file names can't conflict with them.
+func reservationFileName(hwaddr string) string {
+	return fmt.Sprintf("reservation.%s", strings.Replace(strings.ToLower(hwaddr), ":", "-", -1))
+}
+
 // Kill kills dnsmasq for a particular network (or optionally reloads it).
 func Kill(name string, reload bool) error {
 	pidPath := shared.VarPath("networks", name, "dnsmasq.pid")
@@ -142,6 +189,9 @@ func DHCPStaticAllocation(network, projectName, instanceName string) (net.Hardwa
 					return nil, IPv4, IPv6, fmt.Errorf("Error parsing IP address %q", field)
 				}
 				IPv6 = DHCPAllocation{Name: instanceName, Static: true, IP: IP, MAC: mac}
+			} else if strings.HasPrefix(field, "id:") {
+				// Skip DHCPv6 DUID used by DHCP reservations.
+				continue
 			} else if strings.Count(field, ":") == 5 {
 				// This field is expected to come first, so that mac variable can be used with
 				// populating the DHCPAllocation structs too.
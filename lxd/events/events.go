@@ -47,16 +47,56 @@ func (s *Server) AddListener(group string, connection *websocket.Conn, messageTy
 		id:           uuid.NewRandom().String(),
 	}
 
+	err := s.addListener(listener)
+	if err != nil {
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// AddHandler creates and returns a new event listener which passes the events to a function
+// rather than to a websocket connection. The handler must not block.
+func (s *Server) AddHandler(group string, messageTypes []string, handler func(event api.Event)) (*Listener, error) {
+	listener := &Listener{
+		group:        group,
+		handler:      handler,
+		messageTypes: messageTypes,
+		noForward:    true,
+		active:       make(chan bool, 1),
+		id:           uuid.NewRandom().String(),
+	}
+
+	err := s.addListener(listener)
+	if err != nil {
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// RemoveListener removes a listener from the server, it won't be notified of any further events.
+func (s *Server) RemoveListener(listener *Listener) {
+	s.lock.Lock()
+	delete(s.listeners, listener.id)
+	s.lock.Unlock()
+
+	listener.lock.Lock()
+	listener.done = true
+	listener.lock.Unlock()
+}
+
+func (s *Server) addListener(listener *Listener) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	if s.listeners[listener.id] != nil {
-		return nil, fmt.Errorf("A listener with id '%s' already exists", listener.id)
+		return fmt.Errorf("A listener with id '%s' already exists", listener.id)
 	}
 
 	s.listeners[listener.id] = listener
 
-	return listener, nil
+	return nil
 }
 
 // SendLifecycle broadcasts a lifecycle event.
@@ -147,6 +187,11 @@ func (s *Server) broadcast(group string, event api.Event, isForward bool) error
 				event = eventCopy
 			}
 
+			if listener.handler != nil {
+				listener.handler(event)
+				return
+			}
+
 			err := listener.connection.WriteJSON(event)
 			if err != nil {
 				// Remove the listener from the list
@@ -171,6 +216,7 @@ func (s *Server) broadcast(group string, event api.Event, isForward bool) error
 type Listener struct {
 	group        string
 	connection   *websocket.Conn
+	handler      func(event api.Event)
 	messageTypes []string
 	active       chan bool
 	id           string
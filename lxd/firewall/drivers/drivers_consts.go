@@ -16,9 +16,10 @@ type FeatureOpts struct {
 
 // SNATOpts specify how SNAT rules are setup.
 type SNATOpts struct {
-	Append      bool       // Append rules (has no effect if driver doesn't support it).
-	Subnet      *net.IPNet // Subnet of source network used to identify candidate traffic.
-	SNATAddress net.IP     // SNAT IP address to use. If nil then MASQUERADE is used.
+	Append      bool         // Append rules (has no effect if driver doesn't support it).
+	Subnet      *net.IPNet   // Subnet of source network used to identify candidate traffic.
+	SNATAddress net.IP       // SNAT IP address to use. If nil then MASQUERADE is used.
+	Exclude     []*net.IPNet // Destination subnets for which traffic should not be NATed.
 }
 
 // Opts for setting up the firewall.
@@ -44,3 +45,13 @@ type ACLRule struct {
 	ICMPType        string
 	ICMPCode        string
 }
+
+// ACLRuleSubjectAddressSetPrefix is the prefix used in ACLRule Source and Destination fields to reference an
+// AddressSet by name. An address set reference must be the only subject in the field.
+const ACLRuleSubjectAddressSetPrefix = "$"
+
+// AddressSet represents a named set of IP addresses and CIDR subnets that can be referenced by ACL rules.
+type AddressSet struct {
+	Name      string
+	Addresses []string
+}
@@ -0,0 +1,949 @@
+package drivers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
+)
+
+const firewalldInterface = "org.fedoraproject.FirewallD1"
+const firewalldPath = "/org/fedoraproject/FirewallD1"
+const firewalldConfigPath = "/org/fedoraproject/FirewallD1/config"
+
+// firewalldMinVersion We need at least 0.9.0 as this was when policy objects were added.
+const firewalldMinVersion = "0.9.0"
+
+// firewalldZone is the zone managed bridges that are allowed to forward traffic are placed in.
+const firewalldZone = "lxd"
+
+// firewalldZoneIsolated is the zone managed bridges that aren't allowed to forward traffic are placed in.
+const firewalldZoneIsolated = "lxd-isolated"
+
+// firewalldPolicyEgress is the policy allowing traffic to be forwarded from firewalldZone to any other zone.
+// It also carries the outbound NAT rules of the networks.
+const firewalldPolicyEgress = "lxd-egress"
+
+// firewalldPolicyIngress is the policy allowing traffic to be forwarded from any other zone to firewalldZone.
+const firewalldPolicyIngress = "lxd-ingress"
+
+// firewalldPolicyProxy is the policy carrying the DNAT rules of proxy devices.
+const firewalldPolicyProxy = "lxd-proxy"
+
+// firewalldPolicyProxyHost is the policy carrying the DNAT rules of proxy devices for connections from the host.
+const firewalldPolicyProxyHost = "lxd-proxy-host"
+
+// firewalldErrAlreadyEnabled and firewalldErrNotEnabled are the errors returned by firewalld when adding a rule
+// that already exists or removing one that doesn't (for instance because firewalld was reloaded since).
+const firewalldErrAlreadyEnabled = "ALREADY_ENABLED"
+const firewalldErrNotEnabled = "NOT_ENABLED"
+
+// firewalldErrZoneAlreadySet is the error returned by firewalld when placing an interface in its current zone.
+const firewalldErrZoneAlreadySet = "ZONE_ALREADY_SET"
+
+// firewalldMu used for serialising changes to the rules recorded for each network and instance device.
+var firewalldMu sync.Mutex
+
+// firewalldBus is the part of the D-Bus API used to talk to firewalld.
+// It allows the driver to be used against a local stand-in rather than the system bus.
+type firewalldBus interface {
+	// Call calls the method on the object at the path and returns the values it replied with.
+	Call(path string, method string, args ...interface{}) ([]interface{}, error)
+}
+
+// firewalldSystemBus talks to firewalld on the system bus.
+type firewalldSystemBus struct {
+	conn *dbus.Conn
+}
+
+// Call calls the method on the firewalld object at the path.
+func (b *firewalldSystemBus) Call(path string, method string, args ...interface{}) ([]interface{}, error) {
+	call := b.conn.Object(firewalldInterface, dbus.ObjectPath(path)).Call(method, 0, args...)
+	if call.Err != nil {
+		return nil, call.Err
+	}
+
+	return call.Body, nil
+}
+
+// firewalldRule is a runtime rule added to firewalld.
+// Rules are recorded per network and instance device so they can be removed again without having to regenerate them.
+type firewalldRule struct {
+	IPVersion uint `json:"ip_version"`
+
+	// Rich rule added to a policy.
+	Policy   string `json:"policy,omitempty"`
+	RichRule string `json:"rich_rule,omitempty"`
+
+	// Direct rule, used for the bridge filters firewalld has no rich rule equivalent for.
+	Table    string   `json:"table,omitempty"`
+	Chain    string   `json:"chain,omitempty"`
+	Priority int32    `json:"priority,omitempty"`
+	Args     []string `json:"args,omitempty"`
+
+	// Interface placed in a zone.
+	Zone      string `json:"zone,omitempty"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// ipv returns the protocol name firewalld uses for the direct rule.
+func (r *firewalldRule) ipv() string {
+	switch r.IPVersion {
+	case 4:
+		return "ipv4"
+	case 6:
+		return "ipv6"
+	}
+
+	return "eb"
+}
+
+var firewalldFallbackOnce sync.Once
+var firewalldFallbackDriver firewalldFallback
+
+// firewalldFallback is the part of the firewall that firewalld has no equivalent for.
+// It is implemented by the nftables and xtables drivers, which are used alongside firewalld for it.
+type firewalldFallback interface {
+	NetworkSetup(networkName string, opts Opts) error
+	NetworkClear(networkName string, delete bool, ipVersions []uint) error
+	NetworkUpdateOutboundNAT(networkName string, SNATV4 *SNATOpts, SNATV6 *SNATOpts) error
+	NetworkApplyACLRules(networkName string, rules []ACLRule, addressSets []AddressSet) error
+	NetworkDeleteACLAddressSet(addressSetName string) error
+	InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error
+	InstanceClearRPFilter(projectName string, instanceName string, deviceName string) error
+	InstanceSetupACLRules(projectName string, instanceName string, deviceName string, interfaceName string, netnsPID int, rules []ACLRule, addressSets []AddressSet) error
+	InstanceClearACLRules(projectName string, instanceName string, deviceName string, netnsPID int) error
+}
+
+// Firewalld is an implementation of LXD firewall using firewalld over D-Bus.
+// Managed bridges are placed in dedicated zones and their forwarding, outbound NAT and the proxy device DNAT rules
+// are expressed as firewalld policies and rich rules. ACLs and reverse path filtering, which firewalld can't express,
+// are left to the nftables (or xtables) driver.
+type Firewalld struct {
+	// bus is the connection to firewalld (the system bus is used if nil).
+	bus firewalldBus
+}
+
+// String returns the driver name.
+func (d Firewalld) String() string {
+	return "firewalld"
+}
+
+// Compat returns whether the driver backend is in use, and any host compatibility errors.
+// The backend is considered in use whenever firewalld is running.
+func (d Firewalld) Compat() (bool, error) {
+	bus, err := d.connect()
+	if err != nil {
+		return false, err
+	}
+
+	reply, err := bus.Call(firewalldPath, "org.freedesktop.DBus.Properties.Get", firewalldInterface, "version")
+	if err != nil || len(reply) < 1 {
+		return false, fmt.Errorf("Firewalld isn't running")
+	}
+
+	versionStr := fmt.Sprintf("%v", reply[0])
+	variant, ok := reply[0].(dbus.Variant)
+	if ok {
+		versionStr = fmt.Sprintf("%v", variant.Value())
+	}
+
+	firewalldVersion, err := version.Parse(versionStr)
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed parsing firewalld version %q", versionStr)
+	}
+
+	minVer, _ := version.NewDottedVersion(firewalldMinVersion)
+	if firewalldVersion.Compare(minVer) < 0 {
+		return false, fmt.Errorf("firewalld version %q is too low, need %q or above", firewalldVersion, firewalldMinVersion)
+	}
+
+	return true, nil
+}
+
+// connect returns the connection to firewalld.
+func (d Firewalld) connect() (firewalldBus, error) {
+	if d.bus != nil {
+		return d.bus, nil
+	}
+
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed connecting to the system bus")
+	}
+
+	return &firewalldSystemBus{conn: conn}, nil
+}
+
+// call calls a firewalld method on the runtime (or permanent when path is firewalldConfigPath) configuration.
+// The method name is relative to the firewalld D-Bus interface.
+func (d Firewalld) call(path string, method string, args ...interface{}) ([]interface{}, error) {
+	bus, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return bus.Call(path, fmt.Sprintf("%s.%s", firewalldInterface, method), args...)
+}
+
+// fallback returns the driver used for the parts of the firewall firewalld has no equivalent for.
+func (d Firewalld) fallback() firewalldFallback {
+	firewalldFallbackOnce.Do(func() {
+		// Firewalld uses nftables as its backend by default, so prefer it when compatible.
+		nftables := Nftables{}
+		_, err := nftables.Compat()
+		if err == nil {
+			firewalldFallbackDriver = nftables
+			return
+		}
+
+		logger.Debugf(`Firewall firewalld using "xtables" for ACLs due to "nftables" incompatibility: %v`, err)
+		firewalldFallbackDriver = Xtables{}
+	})
+
+	return firewalldFallbackDriver
+}
+
+// isError returns whether the error returned by firewalld has the specified error code.
+func (d Firewalld) isError(err error, code string) bool {
+	return err != nil && strings.Contains(err.Error(), code)
+}
+
+// contains returns whether the string list returned by firewalld contains the value.
+func (d Firewalld) contains(reply []interface{}, value string) bool {
+	if len(reply) < 1 {
+		return false
+	}
+
+	values, ok := reply[0].([]string)
+	if !ok {
+		return false
+	}
+
+	return shared.StringInSlice(value, values)
+}
+
+// ensureZones creates the zones and policies used by LXD in the permanent configuration if missing.
+// Firewalld can only activate new zones and policies by reloading, so it is reloaded if they aren't in the runtime
+// configuration yet. The LXD rules are then added again by the reload watcher (see WatchReload).
+func (d Firewalld) ensureZones() error {
+	reply, err := d.call(firewalldConfigPath, "config.getZoneNames")
+	if err != nil {
+		return errors.Wrapf(err, "Failed getting firewalld zones")
+	}
+
+	// Traffic from the bridges to the host (e.g. DHCP and DNS) is always accepted, matching the other drivers.
+	// Forwarding between zones is controlled by the policies below.
+	zones := map[string]string{
+		firewalldZone:         "Managed LXD networks",
+		firewalldZoneIsolated: "Managed LXD networks without forwarding",
+	}
+
+	for _, zone := range []string{firewalldZone, firewalldZoneIsolated} {
+		if d.contains(reply, zone) {
+			continue
+		}
+
+		settings := map[string]dbus.Variant{
+			"short":       dbus.MakeVariant("LXD"),
+			"description": dbus.MakeVariant(zones[zone]),
+			"target":      dbus.MakeVariant("ACCEPT"),
+		}
+
+		_, err = d.call(firewalldConfigPath, "config.addZone2", zone, settings)
+		if err != nil {
+			return errors.Wrapf(err, "Failed adding firewalld zone %q", zone)
+		}
+	}
+
+	reply, err = d.call(firewalldConfigPath, "config.getPolicyNames")
+	if err != nil {
+		return errors.Wrapf(err, "Failed getting firewalld policies")
+	}
+
+	policies := []struct {
+		name    string
+		ingress string
+		egress  string
+		target  string
+	}{
+		{name: firewalldPolicyEgress, ingress: firewalldZone, egress: "ANY", target: "ACCEPT"},
+		{name: firewalldPolicyIngress, ingress: "ANY", egress: firewalldZone, target: "ACCEPT"},
+		{name: firewalldPolicyProxy, ingress: "ANY", egress: "HOST", target: "CONTINUE"},
+		{name: firewalldPolicyProxyHost, ingress: "HOST", egress: "ANY", target: "CONTINUE"},
+	}
+
+	for _, policy := range policies {
+		if d.contains(reply, policy.name) {
+			continue
+		}
+
+		settings := map[string]dbus.Variant{
+			"short":         dbus.MakeVariant("LXD"),
+			"ingress_zones": dbus.MakeVariant([]string{policy.ingress}),
+			"egress_zones":  dbus.MakeVariant([]string{policy.egress}),
+			"target":        dbus.MakeVariant(policy.target),
+		}
+
+		_, err = d.call(firewalldConfigPath, "config.addPolicy", policy.name, settings)
+		if err != nil {
+			return errors.Wrapf(err, "Failed adding firewalld policy %q", policy.name)
+		}
+	}
+
+	// inactive returns an error for the first zone or policy which isn't in the runtime configuration.
+	inactive := func() error {
+		reply, err := d.call(firewalldPath, "zone.getZones")
+		if err != nil {
+			return errors.Wrapf(err, "Failed getting active firewalld zones")
+		}
+
+		for _, zone := range []string{firewalldZone, firewalldZoneIsolated} {
+			if !d.contains(reply, zone) {
+				return fmt.Errorf("Firewalld zone %q isn't active", zone)
+			}
+		}
+
+		reply, err = d.call(firewalldPath, "policy.getPolicies")
+		if err != nil {
+			return errors.Wrapf(err, "Failed getting active firewalld policies")
+		}
+
+		for _, policy := range policies {
+			if !d.contains(reply, policy.name) {
+				return fmt.Errorf("Firewalld policy %q isn't active", policy.name)
+			}
+		}
+
+		return nil
+	}
+
+	err = inactive()
+	if err == nil {
+		return nil
+	}
+
+	logger.Infof("Reloading firewalld to activate the LXD zones and policies: %v", err)
+
+	_, err = d.call(firewalldPath, "reload")
+	if err != nil {
+		return errors.Wrapf(err, "Failed reloading firewalld")
+	}
+
+	return inactive()
+}
+
+// rulesPath returns the path of the file recording the rules added for the key.
+func (d Firewalld) rulesPath(key string) string {
+	return shared.VarPath("firewalld", fmt.Sprintf("%s.json", key))
+}
+
+// rulesLoad returns the rules recorded for the key.
+func (d Firewalld) rulesLoad(key string) ([]firewalldRule, error) {
+	data, err := ioutil.ReadFile(d.rulesPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	rules := []firewalldRule{}
+	err = json.Unmarshal(data, &rules)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed parsing firewalld rules of %q", key)
+	}
+
+	return rules, nil
+}
+
+// rulesSave records the rules added for the key (removing the record if there are none).
+func (d Firewalld) rulesSave(key string, rules []firewalldRule) error {
+	path := d.rulesPath(key)
+
+	if len(rules) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// ruleAdd adds a runtime rule to firewalld. Rules that already exist are ignored.
+func (d Firewalld) ruleAdd(rule firewalldRule) error {
+	var err error
+	if rule.Zone != "" {
+		_, err = d.call(firewalldPath, "zone.changeZoneOfInterface", rule.Zone, rule.Interface)
+	} else if rule.Policy != "" {
+		_, err = d.call(firewalldPath, "policy.addRichRule", rule.Policy, rule.RichRule, int32(0))
+	} else {
+		_, err = d.call(firewalldPath, "direct.addRule", rule.ipv(), rule.Table, rule.Chain, rule.Priority, rule.Args)
+	}
+
+	if err != nil && !d.isError(err, firewalldErrAlreadyEnabled) && !d.isError(err, firewalldErrZoneAlreadySet) {
+		return err
+	}
+
+	return nil
+}
+
+// ruleRemove removes a runtime rule from firewalld. Rules that don't exist (anymore) are ignored.
+func (d Firewalld) ruleRemove(rule firewalldRule) error {
+	var err error
+	if rule.Zone != "" {
+		_, err = d.call(firewalldPath, "zone.removeInterface", rule.Zone, rule.Interface)
+	} else if rule.Policy != "" {
+		_, err = d.call(firewalldPath, "policy.removeRichRule", rule.Policy, rule.RichRule)
+	} else {
+		_, err = d.call(firewalldPath, "direct.removeRule", rule.ipv(), rule.Table, rule.Chain, rule.Priority, rule.Args)
+	}
+
+	if err != nil && !d.isError(err, firewalldErrNotEnabled) {
+		return err
+	}
+
+	return nil
+}
+
+// rulesApply adds the rules to firewalld and records them for the key (along with any already recorded).
+// Rules which are already recorded aren't recorded again, and placing an interface in a zone replaces the zone it
+// was recorded in before.
+func (d Firewalld) rulesApply(key string, rules []firewalldRule) error {
+	firewalldMu.Lock()
+	defer firewalldMu.Unlock()
+
+	existing, err := d.rulesLoad(key)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		err = d.ruleAdd(rule)
+		if err != nil {
+			// Record what was added so far so it is removed when clearing.
+			d.rulesSave(key, existing)
+			return err
+		}
+
+		recorded := make([]firewalldRule, 0, len(existing)+1)
+		for _, existingRule := range existing {
+			if reflect.DeepEqual(existingRule, rule) {
+				continue
+			}
+
+			if rule.Zone != "" && existingRule.Zone != "" && existingRule.Interface == rule.Interface {
+				continue
+			}
+
+			recorded = append(recorded, existingRule)
+		}
+
+		existing = append(recorded, rule)
+	}
+
+	return d.rulesSave(key, existing)
+}
+
+// rulesReapply adds all the recorded rules to firewalld again.
+// This is needed after firewalld is reloaded as the rules are only added to its runtime configuration.
+func (d Firewalld) rulesReapply() error {
+	firewalldMu.Lock()
+	defer firewalldMu.Unlock()
+
+	files, err := ioutil.ReadDir(shared.VarPath("firewalld"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	errs := []error{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		key := strings.TrimSuffix(file.Name(), ".json")
+		rules, err := d.rulesLoad(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, rule := range rules {
+			err = d.ruleAdd(rule)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "Failed adding firewalld rule of %q", key))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+
+	return nil
+}
+
+// WatchReload reapplies the rules added by LXD whenever firewalld is reloaded, as reloading drops the runtime
+// configuration they were added to.
+func (d Firewalld) WatchReload() error {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return errors.Wrapf(err, "Failed connecting to the system bus")
+	}
+
+	err = conn.AddMatchSignal(dbus.WithMatchInterface(firewalldInterface), dbus.WithMatchMember("Reloaded"))
+	if err != nil {
+		return errors.Wrapf(err, "Failed subscribing to the firewalld reload signal")
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	go func() {
+		for signal := range signals {
+			if signal.Name != fmt.Sprintf("%s.Reloaded", firewalldInterface) {
+				continue
+			}
+
+			logger.Infof("Firewalld was reloaded, reapplying the LXD rules")
+			err := d.rulesReapply()
+			if err != nil {
+				logger.Warnf("Failed reapplying the LXD rules after firewalld reload: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// rulesClear removes the rules recorded for the key from firewalld.
+// If ipVersions is not empty only the rules of those IP versions are removed.
+func (d Firewalld) rulesClear(key string, ipVersions []uint) error {
+	firewalldMu.Lock()
+	defer firewalldMu.Unlock()
+
+	rules, err := d.rulesLoad(key)
+	if err != nil {
+		return err
+	}
+
+	remaining := []firewalldRule{}
+	errs := []error{}
+	for _, rule := range rules {
+		if len(ipVersions) > 0 && !shared.Uint64InSlice(uint64(rule.IPVersion), d.ipVersions(ipVersions)) {
+			remaining = append(remaining, rule)
+			continue
+		}
+
+		err = d.ruleRemove(rule)
+		if err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, rule)
+		}
+	}
+
+	err = d.rulesSave(key, remaining)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+
+	return nil
+}
+
+// ipVersions converts the IP versions into a list usable with shared.Uint64InSlice.
+func (d Firewalld) ipVersions(ipVersions []uint) []uint64 {
+	versions := make([]uint64, 0, len(ipVersions))
+	for _, ipVersion := range ipVersions {
+		versions = append(versions, uint64(ipVersion))
+	}
+
+	return versions
+}
+
+// networkRulesKey returns the key the rules of the network are recorded under.
+func (d Firewalld) networkRulesKey(networkName string) string {
+	return fmt.Sprintf("network_%s", networkName)
+}
+
+// networkZoneKey returns the key the zone of the network's bridge is recorded under.
+func (d Firewalld) networkZoneKey(networkName string) string {
+	return fmt.Sprintf("zone_%s", networkName)
+}
+
+// instanceDeviceRulesKey returns the key the rules of the instance device are recorded under.
+func (d Firewalld) instanceDeviceRulesKey(kind string, projectName string, instanceName string, deviceName string) string {
+	return fmt.Sprintf("%s_%s_%s", kind, project.Instance(projectName, instanceName), deviceName)
+}
+
+// richRuleFamily returns the family name used in rich rules for the IP version.
+func (d Firewalld) richRuleFamily(ipVersion uint) string {
+	if ipVersion == 6 {
+		return "ipv6"
+	}
+
+	return "ipv4"
+}
+
+// networkOutboundNATRule returns the rich rule masquerading the traffic leaving the network's subnet.
+func (d Firewalld) networkOutboundNATRule(ipVersion uint, snat *SNATOpts) firewalldRule {
+	return firewalldRule{
+		IPVersion: ipVersion,
+		Policy:    firewalldPolicyEgress,
+		RichRule:  fmt.Sprintf(`rule family="%s" source address="%s" destination not address="%s" masquerade`, d.richRuleFamily(ipVersion), snat.Subnet.String(), snat.Subnet.String()),
+	}
+}
+
+// NetworkSetup configure network firewall.
+// The network's bridge is placed in firewalldZone if forwarding is allowed for all of its enabled IP versions and
+// in firewalldZoneIsolated otherwise. Outbound NAT that can't be expressed as a masquerade rich rule (a specific
+// SNAT address or excluded destinations) as well as the ACL chains are setup by the fallback driver.
+func (d Firewalld) NetworkSetup(networkName string, opts Opts) error {
+	err := d.ensureZones()
+	if err != nil {
+		return err
+	}
+
+	// Do this first before adding other network rules, so jump to ACL rules come first.
+	if opts.ACL {
+		err = d.fallback().NetworkSetup(networkName, Opts{ACL: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	zone := firewalldZone
+	if (opts.FeaturesV4 != nil && !opts.FeaturesV4.ForwardingAllow) || (opts.FeaturesV6 != nil && !opts.FeaturesV6.ForwardingAllow) {
+		zone = firewalldZoneIsolated
+	}
+
+	err = d.rulesApply(d.networkZoneKey(networkName), []firewalldRule{{Zone: zone, Interface: networkName}})
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding network %q to firewalld zone %q", networkName, zone)
+	}
+
+	rules, fallbackOpts := d.networkOutboundNATRules(opts.SNATV4, opts.SNATV6)
+	if fallbackOpts.SNATV4 != nil || fallbackOpts.SNATV6 != nil {
+		err = d.fallback().NetworkSetup(networkName, fallbackOpts)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = d.rulesApply(d.networkRulesKey(networkName), rules)
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding outbound NAT rules for network %q", networkName)
+	}
+
+	return nil
+}
+
+// networkOutboundNATRules returns the masquerade rich rules for the outbound NAT settings firewalld can express,
+// along with the fallback driver options for those it can't (a specific SNAT address or excluded destinations).
+func (d Firewalld) networkOutboundNATRules(SNATV4 *SNATOpts, SNATV6 *SNATOpts) ([]firewalldRule, Opts) {
+	rules := []firewalldRule{}
+	fallbackOpts := Opts{}
+
+	for _, snat := range []struct {
+		ipVersion uint
+		opts      *SNATOpts
+	}{{ipVersion: 4, opts: SNATV4}, {ipVersion: 6, opts: SNATV6}} {
+		if snat.opts == nil {
+			continue
+		}
+
+		if snat.opts.SNATAddress != nil || len(snat.opts.Exclude) > 0 {
+			if snat.ipVersion == 4 {
+				fallbackOpts.SNATV4 = snat.opts
+			} else {
+				fallbackOpts.SNATV6 = snat.opts
+			}
+
+			continue
+		}
+
+		rules = append(rules, d.networkOutboundNATRule(snat.ipVersion, snat.opts))
+	}
+
+	return rules, fallbackOpts
+}
+
+// NetworkUpdateOutboundNAT replaces the outbound NAT rules of the network, leaving its zone and ACL chains
+// untouched.
+func (d Firewalld) NetworkUpdateOutboundNAT(networkName string, SNATV4 *SNATOpts, SNATV6 *SNATOpts) error {
+	rules, fallbackOpts := d.networkOutboundNATRules(SNATV4, SNATV6)
+
+	err := d.fallback().NetworkUpdateOutboundNAT(networkName, fallbackOpts.SNATV4, fallbackOpts.SNATV6)
+	if err != nil {
+		return err
+	}
+
+	// The outbound NAT rules are the only rules recorded for the network besides its zone.
+	err = d.rulesClear(d.networkRulesKey(networkName), nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed clearing firewalld rules for network %q", networkName)
+	}
+
+	err = d.rulesApply(d.networkRulesKey(networkName), rules)
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding outbound NAT rules for network %q", networkName)
+	}
+
+	return nil
+}
+
+// NetworkClear removes the firewalld rules of the network (of the given IP versions if any are specified).
+// If delete is true then the network's bridge is also removed from the LXD zone.
+func (d Firewalld) NetworkClear(networkName string, delete bool, ipVersions []uint) error {
+	err := d.rulesClear(d.networkRulesKey(networkName), ipVersions)
+	if err != nil {
+		return errors.Wrapf(err, "Failed clearing firewalld rules for network %q", networkName)
+	}
+
+	if delete {
+		err = d.rulesClear(d.networkZoneKey(networkName), nil)
+		if err != nil {
+			return errors.Wrapf(err, "Failed removing network %q from its firewalld zone", networkName)
+		}
+	}
+
+	return d.fallback().NetworkClear(networkName, delete, ipVersions)
+}
+
+// NetworkApplyACLRules applies ACL rules to the existing firewall chains.
+func (d Firewalld) NetworkApplyACLRules(networkName string, rules []ACLRule, addressSets []AddressSet) error {
+	return d.fallback().NetworkApplyACLRules(networkName, rules, addressSets)
+}
+
+// NetworkDeleteACLAddressSet removes the firewall sets of an address set.
+func (d Firewalld) NetworkDeleteACLAddressSet(addressSetName string) error {
+	return d.fallback().NetworkDeleteACLAddressSet(addressSetName)
+}
+
+// bridgeFilterRules returns the direct rules used to apply bridged device IP filtering.
+// Firewalld has no rich rule equivalent for filtering at the Ethernet layer, so the same rules as the xtables
+// driver are used, added through firewalld's direct interface in order of priority.
+func (d Firewalld) bridgeFilterRules(hostName string, hwAddr string, IPv4 net.IP, IPv6 net.IP) ([]firewalldRule, error) {
+	xtables := Xtables{}
+	rules := []firewalldRule{}
+
+	// Rules have the form: ebtables -t filter -A <chain> <args>.
+	for _, ebRule := range xtables.generateFilterEbtablesRules(hostName, hwAddr, IPv4, IPv6) {
+		rules = append(rules, firewalldRule{
+			Table:    ebRule[2],
+			Chain:    ebRule[4],
+			Priority: int32(len(rules)),
+			Args:     ebRule[5:],
+		})
+	}
+
+	// Rules have the form: <ip version> <chain> <args>.
+	ipRules, err := xtables.generateFilterIptablesRules("", hostName, hwAddr, IPv6, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ipRule := range ipRules {
+		ipVersion, err := strconv.ParseUint(ipRule[0], 10, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		// The rules are generated for a specific parent which isn't needed as they already match the port.
+		args := ipRule[2:]
+		if len(args) > 1 && args[0] == "-i" && args[1] == "" {
+			args = args[2:]
+		}
+
+		rules = append(rules, firewalldRule{
+			IPVersion: uint(ipVersion),
+			Table:     "filter",
+			Chain:     ipRule[1],
+			Priority:  int32(len(rules)),
+			Args:      args,
+		})
+	}
+
+	return rules, nil
+}
+
+// InstanceSetupBridgeFilter sets up the filter rules to apply bridged device IP filtering.
+func (d Firewalld) InstanceSetupBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4 net.IP, IPv6 net.IP, _ bool) error {
+	rules, err := d.bridgeFilterRules(hostName, hwAddr, IPv4, IPv6)
+	if err != nil {
+		return err
+	}
+
+	key := d.instanceDeviceRulesKey("filter", projectName, instanceName, deviceName)
+	err = d.rulesApply(key, rules)
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding bridge filter rules for instance device %q", deviceName)
+	}
+
+	return nil
+}
+
+// InstanceClearBridgeFilter removes any filter rules that were added to apply bridged device IP filtering.
+func (d Firewalld) InstanceClearBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, _ net.IP, _ net.IP) error {
+	key := d.instanceDeviceRulesKey("filter", projectName, instanceName, deviceName)
+	err := d.rulesClear(key, nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed clearing bridge filter rules for instance device %q", deviceName)
+	}
+
+	return nil
+}
+
+// InstanceSetupProxyNAT creates DNAT rules for proxy devices.
+// The rules are added as forward port rich rules to firewalldPolicyProxy, and to firewalldPolicyProxyHost for the
+// connections from the host itself. Connections from the instance to its own listen address are masqueraded in
+// firewalldPolicyEgress so the replies go back through the host (hairpin).
+func (d Firewalld) InstanceSetupProxyNAT(projectName string, instanceName string, deviceName string, listen *deviceConfig.ProxyAddress, connect *deviceConfig.ProxyAddress) error {
+	connectAddrCount := len(connect.Addr)
+	if connectAddrCount < 1 {
+		return fmt.Errorf("At least 1 connect address must be supplied")
+	}
+
+	if len(listen.Addr) < 1 {
+		return fmt.Errorf("At least 1 listen address must be supplied")
+	}
+
+	if connectAddrCount > 1 && len(listen.Addr) != connectAddrCount {
+		return fmt.Errorf("More than 1 connect addresses have been supplied, but insufficient for listen addresses")
+	}
+
+	err := d.ensureZones()
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+	revert.Add(func() { d.InstanceClearProxyNAT(projectName, instanceName, deviceName) })
+
+	// Group the listen ports into ranges so that each range only needs a single rule.
+	portRanges, err := proxyPortRanges(listen, connect)
+	if err != nil {
+		return err
+	}
+
+	rules := []firewalldRule{}
+	hairpinHosts := []string{}
+	for _, portRange := range portRanges {
+		listenPort, connectPort := portRange.ports("-")
+
+		ipVersion := uint(4)
+		connectIP := net.ParseIP(portRange.connectHost)
+		if connectIP.To4() == nil {
+			ipVersion = 6
+		}
+
+		// A wildcard listen address matches traffic to any of the local addresses.
+		destination := ""
+		if !isWildcardAddress(portRange.listenHost) {
+			destination = fmt.Sprintf(` destination address="%s"`, portRange.listenHost)
+		}
+
+		// If each listen port is forwarded to the same port number then only the address needs rewriting.
+		toPort := fmt.Sprintf(` to-port="%s"`, connectPort)
+		if portRange.preservesPorts() {
+			toPort = ""
+		}
+
+		richRule := fmt.Sprintf(`rule family="%s"%s forward-port port="%s" protocol="%s"%s to-addr="%s"`, d.richRuleFamily(ipVersion), destination, listenPort, listen.ConnType, toPort, portRange.connectHost)
+		for _, policy := range []string{firewalldPolicyProxy, firewalldPolicyProxyHost} {
+			rules = append(rules, firewalldRule{
+				IPVersion: ipVersion,
+				Policy:    policy,
+				RichRule:  richRule,
+			})
+		}
+
+		if !shared.StringInSlice(portRange.connectHost, hairpinHosts) {
+			hairpinHosts = append(hairpinHosts, portRange.connectHost)
+			rules = append(rules, firewalldRule{
+				IPVersion: ipVersion,
+				Policy:    firewalldPolicyEgress,
+				RichRule:  fmt.Sprintf(`rule family="%s" source address="%s" destination address="%s" masquerade`, d.richRuleFamily(ipVersion), portRange.connectHost, portRange.connectHost),
+			})
+		}
+	}
+
+	key := d.instanceDeviceRulesKey("proxy", projectName, instanceName, deviceName)
+	err = d.rulesApply(key, rules)
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding proxy NAT rules for instance device %q", deviceName)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// InstanceClearProxyNAT remove DNAT rules for proxy devices.
+func (d Firewalld) InstanceClearProxyNAT(projectName string, instanceName string, deviceName string) error {
+	key := d.instanceDeviceRulesKey("proxy", projectName, instanceName, deviceName)
+	err := d.rulesClear(key, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to remove proxy NAT rules for %q: %v", deviceName, err)
+	}
+
+	return nil
+}
+
+// InstanceSetupRPFilter activates reverse path filtering for the specified instance device on the host interface.
+func (d Firewalld) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
+	return d.fallback().InstanceSetupRPFilter(projectName, instanceName, deviceName, hostName)
+}
+
+// InstanceClearRPFilter removes reverse path filtering for the specified instance device on the host interface.
+func (d Firewalld) InstanceClearRPFilter(projectName string, instanceName string, deviceName string) error {
+	return d.fallback().InstanceClearRPFilter(projectName, instanceName, deviceName)
+}
+
+// InstanceSetupACLRules applies ACL rules to the specified instance device's interface.
+func (d Firewalld) InstanceSetupACLRules(projectName string, instanceName string, deviceName string, interfaceName string, netnsPID int, rules []ACLRule, addressSets []AddressSet) error {
+	return d.fallback().InstanceSetupACLRules(projectName, instanceName, deviceName, interfaceName, netnsPID, rules, addressSets)
+}
+
+// InstanceClearACLRules removes the ACL rules of the specified instance device.
+func (d Firewalld) InstanceClearACLRules(projectName string, instanceName string, deviceName string, netnsPID int) error {
+	return d.fallback().InstanceClearACLRules(projectName, instanceName, deviceName, netnsPID)
+}
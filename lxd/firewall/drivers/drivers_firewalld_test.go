@@ -0,0 +1,303 @@
+package drivers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/shared"
+)
+
+// firewalldStandIn is a local stand-in for firewalld which keeps track of the configuration it is asked to change.
+// The zones and policies are those of the permanent configuration, the runtime ones are only updated on reload.
+type firewalldStandIn struct {
+	version         string
+	zones           []string
+	policies        []string
+	runtimeZones    []string
+	runtimePolicies []string
+	interfaces      map[string]string
+	richRules       map[string][]string
+	direct          []string
+	reloads         int
+}
+
+func newFirewalldStandIn() *firewalldStandIn {
+	return &firewalldStandIn{
+		version:      "1.0.0",
+		zones:        []string{"public", "trusted"},
+		runtimeZones: []string{"public", "trusted"},
+		interfaces:   map[string]string{},
+		richRules:    map[string][]string{},
+	}
+}
+
+// reload drops the runtime configuration and loads the permanent one, like firewalld does.
+func (f *firewalldStandIn) reload() {
+	f.runtimeZones = append([]string{}, f.zones...)
+	f.runtimePolicies = append([]string{}, f.policies...)
+	f.interfaces = map[string]string{}
+	f.richRules = map[string][]string{}
+	f.direct = nil
+	f.reloads++
+}
+
+func (f *firewalldStandIn) Call(path string, method string, args ...interface{}) ([]interface{}, error) {
+	method = strings.TrimPrefix(method, fmt.Sprintf("%s.", firewalldInterface))
+
+	switch method {
+	case "org.freedesktop.DBus.Properties.Get":
+		if f.version == "" {
+			return nil, fmt.Errorf("The name %s was not provided by any .service files", firewalldInterface)
+		}
+
+		return []interface{}{dbus.MakeVariant(f.version)}, nil
+	case "config.getZoneNames":
+		return []interface{}{f.zones}, nil
+	case "config.getPolicyNames":
+		return []interface{}{f.policies}, nil
+	case "config.addZone2":
+		f.zones = append(f.zones, args[0].(string))
+	case "config.addPolicy":
+		f.policies = append(f.policies, args[0].(string))
+	case "reload":
+		f.reload()
+	case "zone.getZones":
+		return []interface{}{f.runtimeZones}, nil
+	case "policy.getPolicies":
+		return []interface{}{f.runtimePolicies}, nil
+	case "zone.changeZoneOfInterface":
+		zone, iface := args[0].(string), args[1].(string)
+		if f.interfaces[iface] == zone {
+			return nil, fmt.Errorf("ZONE_ALREADY_SET: '%s' already bound to '%s'", iface, zone)
+		}
+
+		f.interfaces[iface] = zone
+	case "zone.removeInterface":
+		zone, iface := args[0].(string), args[1].(string)
+		if f.interfaces[iface] != zone {
+			return nil, fmt.Errorf("NOT_ENABLED: '%s' not in '%s'", iface, zone)
+		}
+
+		delete(f.interfaces, iface)
+	case "policy.addRichRule":
+		policy, rule := args[0].(string), args[1].(string)
+		if shared.StringInSlice(rule, f.richRules[policy]) {
+			return nil, fmt.Errorf("ALREADY_ENABLED: '%s' already in '%s'", rule, policy)
+		}
+
+		f.richRules[policy] = append(f.richRules[policy], rule)
+	case "policy.removeRichRule":
+		policy, rule := args[0].(string), args[1].(string)
+		rules := []string{}
+		for _, r := range f.richRules[policy] {
+			if r != rule {
+				rules = append(rules, r)
+			}
+		}
+
+		if len(rules) == len(f.richRules[policy]) {
+			return nil, fmt.Errorf("NOT_ENABLED: '%s' not in '%s'", rule, policy)
+		}
+
+		f.richRules[policy] = rules
+	case "direct.addRule":
+		f.direct = append(f.direct, fmt.Sprintf("%v", args))
+	case "direct.removeRule":
+		rule := fmt.Sprintf("%v", args)
+		rules := []string{}
+		for _, r := range f.direct {
+			if r != rule {
+				rules = append(rules, r)
+			}
+		}
+
+		f.direct = rules
+	default:
+		return nil, fmt.Errorf("Unknown method %q", method)
+	}
+
+	return nil, nil
+}
+
+// firewalldTestSetup returns a driver using a stand-in for firewalld, recording its rules in a temporary LXD_DIR.
+func firewalldTestSetup(t *testing.T) (*firewalldStandIn, Firewalld, func()) {
+	dir, err := ioutil.TempDir("", "lxd-firewalld-test-")
+	require.NoError(t, err)
+
+	oldDir := os.Getenv("LXD_DIR")
+	os.Setenv("LXD_DIR", dir)
+
+	cleanup := func() {
+		os.Setenv("LXD_DIR", oldDir)
+		os.RemoveAll(dir)
+	}
+
+	bus := newFirewalldStandIn()
+
+	return bus, Firewalld{bus: bus}, cleanup
+}
+
+// firewalldTestSetupActive returns a driver using a stand-in for firewalld which has the LXD zones active.
+func firewalldTestSetupActive(t *testing.T) (*firewalldStandIn, Firewalld, func()) {
+	bus, d, cleanup := firewalldTestSetup(t)
+	require.NoError(t, d.ensureZones())
+
+	return bus, d, cleanup
+}
+
+func TestFirewalld_Compat(t *testing.T) {
+	bus, d, cleanup := firewalldTestSetup(t)
+	defer cleanup()
+
+	inUse, err := d.Compat()
+	assert.NoError(t, err)
+	assert.True(t, inUse)
+
+	bus.version = "0.8.2"
+	inUse, err = d.Compat()
+	assert.Error(t, err)
+	assert.False(t, inUse)
+
+	bus.version = ""
+	inUse, err = d.Compat()
+	assert.Error(t, err)
+	assert.False(t, inUse)
+}
+
+func TestFirewalld_NetworkSetup(t *testing.T) {
+	bus, d, cleanup := firewalldTestSetup(t)
+	defer cleanup()
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	opts := Opts{
+		FeaturesV4: &FeatureOpts{DHCPDNSAccess: true, ForwardingAllow: true},
+		SNATV4:     &SNATOpts{Subnet: subnet},
+	}
+
+	// The zones and policies are created in the permanent configuration and firewalld is reloaded to activate them.
+	err := d.NetworkSetup("lxdbr0", opts)
+	require.NoError(t, err)
+	assert.Contains(t, bus.zones, firewalldZone)
+	assert.Contains(t, bus.zones, firewalldZoneIsolated)
+	assert.ElementsMatch(t, []string{firewalldPolicyEgress, firewalldPolicyIngress, firewalldPolicyProxy, firewalldPolicyProxyHost}, bus.policies)
+	assert.Contains(t, bus.runtimeZones, firewalldZone)
+	assert.ElementsMatch(t, bus.policies, bus.runtimePolicies)
+	assert.Equal(t, 1, bus.reloads)
+	assert.Equal(t, firewalldZone, bus.interfaces["lxdbr0"])
+	assert.Equal(t, []string{`rule family="ipv4" source address="10.0.0.0/24" destination not address="10.0.0.0/24" masquerade`}, bus.richRules[firewalldPolicyEgress])
+
+	// Setting up again doesn't create the zones again, reload firewalld nor duplicate the rules.
+	err = d.NetworkSetup("lxdbr0", opts)
+	require.NoError(t, err)
+	assert.Len(t, bus.richRules[firewalldPolicyEgress], 1)
+	assert.Equal(t, 1, bus.reloads)
+
+	rules, err := d.rulesLoad(d.networkRulesKey("lxdbr0"))
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	// Networks that don't allow forwarding are isolated.
+	opts.FeaturesV4.ForwardingAllow = false
+	err = d.NetworkSetup("lxdbr1", opts)
+	require.NoError(t, err)
+	assert.Equal(t, firewalldZoneIsolated, bus.interfaces["lxdbr1"])
+
+	// Changing the zone of a network replaces the recorded one.
+	opts.FeaturesV4.ForwardingAllow = true
+	err = d.NetworkSetup("lxdbr1", opts)
+	require.NoError(t, err)
+	assert.Equal(t, firewalldZone, bus.interfaces["lxdbr1"])
+
+	err = d.rulesClear(d.networkZoneKey("lxdbr1"), nil)
+	require.NoError(t, err)
+	assert.NotContains(t, bus.interfaces, "lxdbr1")
+}
+
+// The rules recorded by LXD are added again after firewalld drops its runtime configuration on reload.
+func TestFirewalld_rulesReapply(t *testing.T) {
+	bus, d, cleanup := firewalldTestSetupActive(t)
+	defer cleanup()
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	err := d.NetworkSetup("lxdbr0", Opts{FeaturesV4: &FeatureOpts{ForwardingAllow: true}, SNATV4: &SNATOpts{Subnet: subnet}})
+	require.NoError(t, err)
+
+	err = d.InstanceSetupBridgeFilter("default", "c1", "eth0", "lxdbr0", "veth1234", "00:16:3e:00:00:01", net.ParseIP("10.0.0.2"), nil, true)
+	require.NoError(t, err)
+
+	interfaces := bus.interfaces
+	richRules := bus.richRules
+	direct := bus.direct
+
+	bus.reload()
+	assert.Empty(t, bus.interfaces)
+
+	err = d.rulesReapply()
+	require.NoError(t, err)
+	assert.Equal(t, interfaces, bus.interfaces)
+	assert.Equal(t, richRules, bus.richRules)
+	assert.ElementsMatch(t, direct, bus.direct)
+}
+
+func TestFirewalld_InstanceSetupProxyNAT(t *testing.T) {
+	bus, d, cleanup := firewalldTestSetupActive(t)
+	defer cleanup()
+
+	listen := &deviceConfig.ProxyAddress{
+		ConnType: "tcp",
+		Addr:     []string{"0.0.0.0:80", "0.0.0.0:81", "192.0.2.1:443"},
+	}
+
+	connect := &deviceConfig.ProxyAddress{
+		ConnType: "tcp",
+		Addr:     []string{"10.0.0.2:80", "10.0.0.2:81", "10.0.0.2:8443"},
+	}
+
+	err := d.InstanceSetupProxyNAT("default", "c1", "proxy0", listen, connect)
+	require.NoError(t, err)
+
+	forwardRules := []string{
+		`rule family="ipv4" forward-port port="80-81" protocol="tcp" to-addr="10.0.0.2"`,
+		`rule family="ipv4" destination address="192.0.2.1" forward-port port="443" protocol="tcp" to-port="8443" to-addr="10.0.0.2"`,
+	}
+
+	assert.Equal(t, forwardRules, bus.richRules[firewalldPolicyProxy])
+	assert.Equal(t, forwardRules, bus.richRules[firewalldPolicyProxyHost])
+	assert.Equal(t, []string{`rule family="ipv4" source address="10.0.0.2" destination address="10.0.0.2" masquerade`}, bus.richRules[firewalldPolicyEgress])
+
+	err = d.InstanceClearProxyNAT("default", "c1", "proxy0")
+	require.NoError(t, err)
+	assert.Empty(t, bus.richRules[firewalldPolicyProxy])
+	assert.Empty(t, bus.richRules[firewalldPolicyProxyHost])
+	assert.Empty(t, bus.richRules[firewalldPolicyEgress])
+
+	// Clearing again (e.g. after firewalld was reloaded) doesn't fail.
+	err = d.InstanceClearProxyNAT("default", "c1", "proxy0")
+	assert.NoError(t, err)
+}
+
+func TestFirewalld_InstanceSetupBridgeFilter(t *testing.T) {
+	bus, d, cleanup := firewalldTestSetup(t)
+	defer cleanup()
+
+	err := d.InstanceSetupBridgeFilter("default", "c1", "eth0", "lxdbr0", "veth1234", "00:16:3e:00:00:01", net.ParseIP("10.0.0.2"), net.ParseIP("fd42::2"), true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, bus.direct)
+
+	for _, rule := range bus.direct {
+		assert.Contains(t, rule, "veth1234")
+	}
+
+	err = d.InstanceClearBridgeFilter("default", "c1", "eth0", "lxdbr0", "veth1234", "00:16:3e:00:00:01", net.ParseIP("10.0.0.2"), net.ParseIP("fd42::2"))
+	require.NoError(t, err)
+	assert.Empty(t, bus.direct)
+}
@@ -362,6 +362,20 @@ func (d Nftables) NetworkSetup(networkName string, opts Opts) error {
 	return nil
 }
 
+// NetworkUpdateOutboundNAT replaces the outbound NAT rules of the network, leaving its other rules untouched.
+func (d Nftables) NetworkUpdateOutboundNAT(networkName string, SNATV4 *SNATOpts, SNATV6 *SNATOpts) error {
+	err := d.removeChains([]string{"inet"}, networkName, "pstrt")
+	if err != nil {
+		return errors.Wrapf(err, "Failed clearing outbound NAT rules for network %q", networkName)
+	}
+
+	if SNATV4 == nil && SNATV6 == nil {
+		return nil
+	}
+
+	return d.networkSetupOutboundNAT(networkName, SNATV4, SNATV6)
+}
+
 // NetworkClear removes the LXD network related chains.
 // The delete and ipeVersions arguments have no effect for nftables driver.
 func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
@@ -372,6 +386,12 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		return errors.Wrapf(err, "Failed clearing nftables rules for network %q", networkName)
 	}
 
+	// Remove the address sets that were only used by the network's ACL rules.
+	err = d.aclAddressSetsPrune(0)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -458,44 +478,49 @@ func (d Nftables) InstanceSetupProxyNAT(projectName string, instanceName string,
 		return fmt.Errorf("More than 1 connect addresses have been supplied, but insufficient for listen addresses")
 	}
 
+	// Group the listen ports into ranges so that each range only needs a single rule.
+	portRanges, err := proxyPortRanges(listen, connect)
+	if err != nil {
+		return err
+	}
+
 	// Generate a slice of rules to add.
 	var rules []map[string]interface{}
-	for i, lAddr := range listen.Addr {
-		listenHost, listenPort, err := net.SplitHostPort(lAddr)
-		if err != nil {
-			return err
-		}
-
-		// Use the connect address that corresponds to the listen address (unless only 1 is specified).
-		connectIndex := 0
-		if connectAddrCount > 1 {
-			connectIndex = i
-		}
-
-		connectHost, connectPort, err := net.SplitHostPort(connect.Addr[connectIndex])
-		if err != nil {
-			return err
-		}
+	for i, portRange := range portRanges {
+		listenPort, connectPort := portRange.ports("-")
 
 		// Figure out which IP family we are using and format the destination host/port as appropriate.
 		ipFamily := "ip"
-		connectDest := fmt.Sprintf("%s:%s", connectHost, connectPort)
-		connectIP := net.ParseIP(connectHost)
+		nfProto := "ipv4"
+		connectDest := fmt.Sprintf("%s:%s", portRange.connectHost, connectPort)
+		connectIP := net.ParseIP(portRange.connectHost)
 		if connectIP.To4() == nil {
 			ipFamily = "ip6"
-			connectDest = fmt.Sprintf("[%s]:%s", connectHost, connectPort)
+			nfProto = "ipv6"
+			connectDest = fmt.Sprintf("[%s]:%s", portRange.connectHost, connectPort)
+		}
+
+		// If each listen port is forwarded to the same port number then only the address needs rewriting.
+		if portRange.listenStart != portRange.listenEnd && portRange.preservesPorts() {
+			connectDest = portRange.connectHost
+		}
+
+		// A wildcard listen address matches traffic to any of the local addresses of the IP family.
+		listenMatch := fmt.Sprintf("%s daddr %s", ipFamily, portRange.listenHost)
+		if isWildcardAddress(portRange.listenHost) {
+			listenMatch = fmt.Sprintf("meta nfproto %s fib daddr type local", nfProto)
 		}
 
 		rules = append(rules, map[string]interface{}{
 			"family":        "inet",
 			"ipFamily":      ipFamily,
 			"connType":      listen.ConnType,
-			"listenHost":    listenHost,
+			"listenMatch":   listenMatch,
 			"listenPort":    listenPort,
 			"connectDest":   connectDest,
-			"connectHost":   connectHost,
+			"connectHost":   portRange.connectHost,
 			"connectPort":   connectPort,
-			"addHairpinNat": connectIndex == i, // Only add >1 hairpin NAT rules if connect range used.
+			"addHairpinNat": connectAddrCount > 1 || i == 0, // Only add >1 hairpin NAT rules if connect range used.
 		})
 	}
 
@@ -508,7 +533,7 @@ func (d Nftables) InstanceSetupProxyNAT(projectName string, instanceName string,
 		"rules":          rules,
 	}
 
-	err := d.applyNftConfig(nftablesNetProxyNAT, tplFields)
+	err = d.applyNftConfig(nftablesNetProxyNAT, tplFields)
 	if err != nil {
 		return errors.Wrapf(err, "Failed adding proxy rules for instance device %q", deviceLabel)
 	}
@@ -628,13 +653,224 @@ func (d Nftables) InstanceClearRPFilter(projectName string, instanceName string,
 }
 
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
-func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule, addressSets []AddressSet) error {
+	nftRules, err := d.aclRulesToNftRules(networkName, false, rules, addressSets)
+	if err != nil {
+		return err
+	}
+
+	// Setup the address sets in the same nft command so they are in place before the rules referencing them.
+	config := &strings.Builder{}
+	err = d.aclAddressSetsConfig(config, addressSets)
+	if err != nil {
+		return err
+	}
+
+	tplFields := map[string]interface{}{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"networkName":    networkName,
+		"family":         "inet",
+		"rules":          nftRules,
+	}
+
+	err = nftablesNetACLRules.Execute(config, tplFields)
+	if err != nil {
+		return errors.Wrapf(err, "Failed running %q template", nftablesNetACLRules.Name())
+	}
+
+	_, err = shared.RunCommand("nft", config.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// aclAddressSetName returns the name of the nftables set used for the IP family of an address set.
+func (d Nftables) aclAddressSetName(addressSetName string, ipVersion uint) string {
+	return fmt.Sprintf("%s%sip%d", addressSetName, nftablesChainSeparator, ipVersion)
+}
+
+// aclAddressSetsConfig writes the nftables config needed to create the address sets (or replace their contents).
+// Each address set is represented by an IPv4 and an IPv6 nftables set.
+func (d Nftables) aclAddressSetsConfig(config *strings.Builder, addressSets []AddressSet) error {
+	if len(addressSets) <= 0 {
+		return nil
+	}
+
+	sets := make([]map[string]string, 0, len(addressSets)*2)
+	for _, addressSet := range addressSets {
+		elements := map[uint][]string{4: {}, 6: {}}
+		for _, address := range addressSet.Addresses {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				ip, _, _ = net.ParseCIDR(address)
+			}
+
+			if ip == nil {
+				return fmt.Errorf("Invalid address %q in address set %q", address, addressSet.Name)
+			}
+
+			if ip.To4() == nil {
+				elements[6] = append(elements[6], address)
+			} else {
+				elements[4] = append(elements[4], address)
+			}
+		}
+
+		for _, ipVersion := range []uint{4, 6} {
+			setType := "ipv4_addr"
+			if ipVersion == 6 {
+				setType = "ipv6_addr"
+			}
+
+			sets = append(sets, map[string]string{
+				"name":     d.aclAddressSetName(addressSet.Name, ipVersion),
+				"type":     setType,
+				"elements": strings.Join(elements[ipVersion], ", "),
+			})
+		}
+	}
+
+	tplFields := map[string]interface{}{
+		"namespace": nftablesNamespace,
+		"family":    "inet",
+		"sets":      sets,
+	}
+
+	err := nftablesACLAddressSets.Execute(config, tplFields)
+	if err != nil {
+		return errors.Wrapf(err, "Failed running %q template", nftablesACLAddressSets.Name())
+	}
+
+	return nil
+}
+
+// aclAddressSetsList returns the address sets in the LXD table (inside the network namespace of netnsPID if > 0)
+// along with whether each of them is referenced by any rules.
+func (d Nftables) aclAddressSetsList(netnsPID int) (map[string]bool, error) {
+	var output string
+	var err error
+	if netnsPID > 0 {
+		output, err = shared.RunCommand("nsenter", fmt.Sprintf("--net=/proc/%d/ns/net", netnsPID), "--", "nft", "-nn", "list", "ruleset", "inet")
+	} else {
+		output, err = shared.RunCommand("nft", "-nn", "list", "ruleset", "inet")
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed listing nftables ruleset")
+	}
+
+	// The LXD table only contains named sets for address sets, which rules reference as "@<set>".
+	sets := make(map[string]bool)
+	referenced := make(map[string]bool)
+	inTable := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		if fields[0] == "table" {
+			inTable = len(fields) > 2 && fields[2] == nftablesNamespace
+			continue
+		}
+
+		if !inTable {
+			continue
+		}
+
+		if fields[0] == "set" && len(fields) > 1 {
+			sets[fields[1]] = false
+			continue
+		}
+
+		for _, field := range fields {
+			if strings.HasPrefix(field, "@") {
+				referenced[strings.TrimPrefix(field, "@")] = true
+			}
+		}
+	}
+
+	for setName := range sets {
+		sets[setName] = referenced[setName]
+	}
+
+	return sets, nil
+}
+
+// aclAddressSetsPrune removes the address sets that are no longer referenced by any rules (inside the network
+// namespace of netnsPID if > 0).
+func (d Nftables) aclAddressSetsPrune(netnsPID int) error {
+	sets, err := d.aclAddressSetsList(netnsPID)
+	if err != nil {
+		return err
+	}
+
+	config := &strings.Builder{}
+	for setName, referenced := range sets {
+		if !referenced {
+			fmt.Fprintf(config, "delete set inet %s %s\n", nftablesNamespace, setName)
+		}
+	}
+
+	if config.Len() <= 0 {
+		return nil
+	}
+
+	err = d.runNft(netnsPID, config.String())
+	if err != nil {
+		return errors.Wrapf(err, "Failed removing unused address sets")
+	}
+
+	return nil
+}
+
+// NetworkDeleteACLAddressSet removes the nftables sets of an address set.
+func (d Nftables) NetworkDeleteACLAddressSet(addressSetName string) error {
+	sets, err := d.aclAddressSetsList(0)
+	if err != nil {
+		return err
+	}
+
+	config := &strings.Builder{}
+	for _, ipVersion := range []uint{4, 6} {
+		setName := d.aclAddressSetName(addressSetName, ipVersion)
+
+		_, found := sets[setName]
+		if found {
+			fmt.Fprintf(config, "delete set inet %s %s\n", nftablesNamespace, setName)
+		}
+	}
+
+	if config.Len() <= 0 {
+		return nil
+	}
+
+	err = d.runNft(0, config.String())
+	if err != nil {
+		return errors.Wrapf(err, "Failed removing address set %q", addressSetName)
+	}
+
+	return nil
+}
+
+// aclRulesToNftRules converts a list of ACL rules into nftables rules matching on the specified interface.
+// If instanceSide is true the interface is the instance's end of the link (so ingress traffic is coming in on
+// it), otherwise it is the host's end of the link (so ingress traffic is going out of it).
+func (d Nftables) aclRulesToNftRules(interfaceName string, instanceSide bool, rules []ACLRule, addressSets []AddressSet) ([]string, error) {
+	addressSetsByName := make(map[string]AddressSet, len(addressSets))
+	for _, addressSet := range addressSets {
+		addressSetsByName[addressSet.Name] = addressSet
+	}
+
 	nftRules := make([]string, 0)
 	for _, rule := range rules {
 		// First try generating rules with IPv4 or IP agnostic criteria.
-		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &rule)
+		nftRule, partial, err := d.aclRuleCriteriaToRules(interfaceName, instanceSide, 4, &rule, addressSetsByName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if nftRule != "" {
@@ -644,35 +880,101 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		if partial {
 			// If we couldn't fully generate the ruleset with only IPv4 or IP agnostic criteria, then
 			// fill in the remaining parts using IPv6 criteria.
-			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, &rule)
+			nftRule, _, err = d.aclRuleCriteriaToRules(interfaceName, instanceSide, 6, &rule, addressSetsByName)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			if nftRule == "" {
-				return fmt.Errorf("Invalid empty rule generated")
+				return nil, fmt.Errorf("Invalid empty rule generated")
 			}
 
 			nftRules = append(nftRules, nftRule)
 		} else if nftRule == "" {
-			return fmt.Errorf("Invalid empty rule generated")
+			return nil, fmt.Errorf("Invalid empty rule generated")
 		}
 	}
 
+	return nftRules, nil
+}
+
+// runNft runs the nft command with the supplied config, inside the network namespace of netnsPID if > 0.
+func (d Nftables) runNft(netnsPID int, config string) error {
+	var err error
+	if netnsPID > 0 {
+		_, err = shared.RunCommand("nsenter", fmt.Sprintf("--net=/proc/%d/ns/net", netnsPID), "--", "nft", config)
+	} else {
+		_, err = shared.RunCommand("nft", config)
+	}
+
+	return err
+}
+
+// InstanceSetupACLRules applies ACL rules to the specified instance device's interface.
+// If netnsPID is > 0 the rules are applied to the instance side interface inside that process's network
+// namespace, otherwise they are applied to the host side interface.
+func (d Nftables) InstanceSetupACLRules(projectName string, instanceName string, deviceName string, interfaceName string, netnsPID int, rules []ACLRule, addressSets []AddressSet) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	nftRules, err := d.aclRulesToNftRules(interfaceName, netnsPID > 0, rules, addressSets)
+	if err != nil {
+		return err
+	}
+
+	config := &strings.Builder{}
+	err = d.aclAddressSetsConfig(config, addressSets)
+	if err != nil {
+		return err
+	}
+
 	tplFields := map[string]interface{}{
 		"namespace":      nftablesNamespace,
 		"chainSeparator": nftablesChainSeparator,
-		"networkName":    networkName,
+		"deviceLabel":    deviceLabel,
+		"interfaceName":  interfaceName,
 		"family":         "inet",
 		"rules":          nftRules,
 	}
+
+	err = nftablesInstanceACLRules.Execute(config, tplFields)
+	if err != nil {
+		return errors.Wrapf(err, "Failed running %q template", nftablesInstanceACLRules.Name())
+	}
+
+	err = d.runNft(netnsPID, config.String())
+	if err != nil {
+		return errors.Wrapf(err, "Failed adding ACL rules for instance device %q", deviceLabel)
+	}
+
+	return nil
+}
+
+// InstanceClearACLRules removes the ACL rules of the specified instance device.
+// If netnsPID is > 0 the rules are removed from inside that process's network namespace.
+func (d Nftables) InstanceClearACLRules(projectName string, instanceName string, deviceName string, netnsPID int) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	tplFields := map[string]interface{}{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"deviceLabel":    deviceLabel,
+		"family":         "inet",
+		"chains":         []string{"aclin", "aclout", "aclfwd", "acl"}, // Base chains first as they jump to acl.
+	}
+
 	config := &strings.Builder{}
-	err := nftablesNetACLRules.Execute(config, tplFields)
+	err := nftablesInstanceACLClear.Execute(config, tplFields)
 	if err != nil {
-		return errors.Wrapf(err, "Failed running %q template", nftablesNetACLRules.Name())
+		return errors.Wrapf(err, "Failed running %q template", nftablesInstanceACLClear.Name())
 	}
 
-	_, err = shared.RunCommand("nft", config.String())
+	err = d.runNft(netnsPID, config.String())
+	if err != nil {
+		return errors.Wrapf(err, "Failed clearing ACL rules for instance device %q", deviceLabel)
+	}
+
+	// Remove the address sets that were only used by the device's ACL rules.
+	err = d.aclAddressSetsPrune(netnsPID)
 	if err != nil {
 		return err
 	}
@@ -681,20 +983,31 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 }
 
 // aclRuleCriteriaToRules converts an ACL rule into 1 or more nftables rules.
-func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rule *ACLRule) (string, bool, error) {
+func (d Nftables) aclRuleCriteriaToRules(interfaceName string, instanceSide bool, ipVersion uint, rule *ACLRule, addressSets map[string]AddressSet) (string, bool, error) {
 	var args []string
 
-	if rule.Direction == "ingress" {
-		args = append(args, "oifname", networkName) // Coming from host into network's interface.
+	// Address sets can contain addresses of both IP families, so for ICMP rules only the addresses of the ICMP
+	// protocol's IP family are used.
+	var icmpIPVersion uint
+	switch rule.Protocol {
+	case "icmp4":
+		icmpIPVersion = 4
+	case "icmp6":
+		icmpIPVersion = 6
+	}
+
+	// Ingress traffic leaves the host side interface and arrives on the instance side interface.
+	if (rule.Direction == "ingress") != instanceSide {
+		args = append(args, "oifname", interfaceName) // Going out of the interface.
 	} else {
-		args = append(args, "iifname", networkName) // Coming from network's interface into host.
+		args = append(args, "iifname", interfaceName) // Coming in on the interface.
 	}
 
 	// Add subject filters.
 	isPartialRule := false
 
 	if rule.Source != "" {
-		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("saddr", ipVersion, util.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("saddr", ipVersion, icmpIPVersion, addressSets, util.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return "", false, err
 		}
@@ -711,7 +1024,7 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 	}
 
 	if rule.Destination != "" {
-		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("daddr", ipVersion, util.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("daddr", ipVersion, icmpIPVersion, addressSets, util.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return "", false, err
 		}
@@ -799,11 +1112,60 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 
 // aclRuleSubjectToACLMatch converts direction (source/destination) and subject criteria list into xtables args.
 // Returns nil if none of the subjects are appropriate for the ipVersion.
-func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subjectCriteria ...string) ([]string, bool, error) {
+func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, icmpIPVersion uint, addressSets map[string]AddressSet, subjectCriteria ...string) ([]string, bool, error) {
 	fieldParts := make([]string, 0, len(subjectCriteria))
 
 	partial := false
 
+	ipFamily := "ip"
+	if ipVersion == 6 {
+		ipFamily = "ip6"
+	}
+
+	// Address set references are matched against the nftables set for the ipVersion.
+	if len(subjectCriteria) == 1 && strings.HasPrefix(subjectCriteria[0], ACLRuleSubjectAddressSetPrefix) {
+		addressSetName := strings.TrimPrefix(subjectCriteria[0], ACLRuleSubjectAddressSetPrefix)
+		addressSet, found := addressSets[addressSetName]
+		if !found {
+			return nil, false, fmt.Errorf("Unknown address set %q", addressSetName)
+		}
+
+		// Work out which IP families the address set has usable addresses for.
+		hasIPVersion := map[uint]bool{}
+		for _, address := range addressSet.Addresses {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				ip, _, _ = net.ParseCIDR(address)
+			}
+
+			if ip == nil {
+				continue
+			}
+
+			var subjectIPVersion uint = 4
+			if ip.To4() == nil {
+				subjectIPVersion = 6
+			}
+
+			if icmpIPVersion == 0 || icmpIPVersion == subjectIPVersion {
+				hasIPVersion[subjectIPVersion] = true
+			}
+		}
+
+		var otherIPVersion uint = 6
+		if ipVersion == 6 {
+			otherIPVersion = 4
+		}
+
+		// Skip the ipVersion if it cannot be used or if only the other IP family has addresses.
+		// If neither IP family has addresses then the (empty) set is still matched so the rule is generated.
+		if (icmpIPVersion > 0 && icmpIPVersion != ipVersion) || (!hasIPVersion[ipVersion] && hasIPVersion[otherIPVersion]) {
+			return nil, true, nil // Rule is not appropriate for ipVersion.
+		}
+
+		return []string{ipFamily, direction, fmt.Sprintf("@%s", d.aclAddressSetName(addressSetName, ipVersion))}, hasIPVersion[otherIPVersion], nil
+	}
+
 	// For each criterion check if value looks like IP CIDR.
 	for _, subjectCriterion := range subjectCriteria {
 		if validate.IsNetworkRange(subjectCriterion) == nil {
@@ -851,11 +1213,6 @@ func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, sub
 	}
 
 	if len(fieldParts) > 0 {
-		ipFamily := "ip"
-		if ipVersion == 6 {
-			ipFamily = "ip6"
-		}
-
 		return []string{ipFamily, direction, fmt.Sprintf("{%s}", strings.Join(fieldParts, ","))}, partial, nil
 	}
 
@@ -31,6 +31,9 @@ chain pstrt{{.chainSeparator}}{{.networkName}} {
 	type nat hook postrouting priority 100; policy accept;
 
 	{{- range $ipFamily, $config := .rules}}
+	{{- range $config.Exclude}}
+	{{$ipFamily}} saddr {{$config.Subnet}} {{$ipFamily}} daddr {{.}} return
+	{{- end}}
 	{{if $config.SNATAddress -}}
 	{{$ipFamily}} saddr {{$config.Subnet}} {{$ipFamily}} daddr != {{$config.Subnet}} snat {{$config.SNATAddress}}
 	{{else -}}
@@ -76,14 +79,14 @@ var nftablesNetProxyNAT = template.Must(template.New("nftablesNetProxyNAT").Pars
 chain prert{{.chainSeparator}}{{.deviceLabel}} {
 	type nat hook prerouting priority -100; policy accept;
 	{{- range .rules}}
-	{{.ipFamily}} daddr {{.listenHost}} {{.connType}} dport {{.listenPort}} dnat to {{.connectDest}}
+	{{.listenMatch}} {{.connType}} dport {{.listenPort}} dnat to {{.connectDest}}
 	{{- end}}
 }
 
 chain out{{.chainSeparator}}{{.deviceLabel}} {
 	type nat hook output priority -100; policy accept;
 	{{- range .rules}}
-	{{.ipFamily}} daddr {{.listenHost}} {{.connType}} dport {{.listenPort}} dnat to {{.connectDest}}
+	{{.listenMatch}} {{.connType}} dport {{.listenPort}} dnat to {{.connectDest}}
 	{{- end}}
 }
 
@@ -160,6 +163,76 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+// nftablesACLAddressSets defines the sets used for the address sets referenced by ACL rules.
+// The sets are flushed and refilled so that address set changes are applied without recreating the rules. As nft
+// applies the whole config as a single transaction, the rules never see the sets empty.
+var nftablesACLAddressSets = template.Must(template.New("nftablesACLAddressSets").Parse(`
+add table {{.family}} {{.namespace}}
+{{- range .sets}}
+add set {{$.family}} {{$.namespace}} {{.name}} {type {{.type}}; flags interval; auto-merge;}
+flush set {{$.family}} {{$.namespace}} {{.name}}
+{{- if .elements}}
+add element {{$.family}} {{$.namespace}} {{.name}} { {{- .elements -}} }
+{{- end}}
+{{- end}}
+`))
+
+// nftablesInstanceACLRules defines the chains and rules used to apply ACL rules to an instance device's interface.
+// Core ICMP and neighbour discovery traffic is always allowed so that the interface keeps working.
+var nftablesInstanceACLRules = template.Must(template.New("nftablesInstanceACLRules").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.deviceLabel}}
+add chain {{.family}} {{.namespace}} aclin{{.chainSeparator}}{{.deviceLabel}} {type filter hook input priority filter; policy accept;}
+add chain {{.family}} {{.namespace}} aclout{{.chainSeparator}}{{.deviceLabel}} {type filter hook output priority filter; policy accept;}
+add chain {{.family}} {{.namespace}} aclfwd{{.chainSeparator}}{{.deviceLabel}} {type filter hook forward priority filter; policy accept;}
+flush chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclin{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclout{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclfwd{{.chainSeparator}}{{.deviceLabel}}
+
+table {{.family}} {{.namespace}} {
+	chain aclin{{.chainSeparator}}{{.deviceLabel}} {
+		# Allow core ICMPv4 and ICMPv6.
+		iifname "{{.interfaceName}}" icmp type {3, 11, 12} accept
+		iifname "{{.interfaceName}}" icmpv6 type {1, 2, 3, 4, 133, 134, 135, 136, 143} accept
+
+		iifname "{{.interfaceName}}" jump acl{{.chainSeparator}}{{.deviceLabel}}
+	}
+
+	chain aclout{{.chainSeparator}}{{.deviceLabel}} {
+		# Allow core ICMPv4 and ICMPv6.
+		oifname "{{.interfaceName}}" icmp type {3, 11, 12} accept
+		oifname "{{.interfaceName}}" icmpv6 type {1, 2, 3, 4, 133, 134, 135, 136, 143} accept
+
+		oifname "{{.interfaceName}}" jump acl{{.chainSeparator}}{{.deviceLabel}}
+	}
+
+	chain aclfwd{{.chainSeparator}}{{.deviceLabel}} {
+		iifname "{{.interfaceName}}" jump acl{{.chainSeparator}}{{.deviceLabel}}
+		oifname "{{.interfaceName}}" jump acl{{.chainSeparator}}{{.deviceLabel}}
+	}
+
+	chain acl{{.chainSeparator}}{{.deviceLabel}} {
+		ct state established,related accept
+
+		{{- range .rules}}
+		{{.}}
+		{{- end}}
+	}
+}
+`))
+
+// nftablesInstanceACLClear removes the chains used to apply ACL rules to an instance device's interface.
+// The chains are added first so that deleting them doesn't fail if they don't exist.
+var nftablesInstanceACLClear = template.Must(template.New("nftablesInstanceACLClear").Parse(`
+add table {{.family}} {{.namespace}}
+{{- range $chain := .chains}}
+add chain {{$.family}} {{$.namespace}} {{$chain}}{{$.chainSeparator}}{{$.deviceLabel}}
+flush chain {{$.family}} {{$.namespace}} {{$chain}}{{$.chainSeparator}}{{$.deviceLabel}}
+delete chain {{$.family}} {{$.namespace}} {{$chain}}{{$.chainSeparator}}{{$.deviceLabel}}
+{{- end}}
+`))
+
 // nftablesInstanceBridgeFilter defines the rules needed for MAC, IPv4 and IPv6 bridge security filtering.
 // To prevent instances from using IPs that are different from their assigned IPs we use ARP and NDP filtering
 // to prevent neighbour advertisements that are not allowed. However in order for DHCPv4 & DHCPv6 to work back to
@@ -0,0 +1,113 @@
+package drivers
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+)
+
+// proxyPortRange represents a contiguous range of listen ports that is forwarded to a connect host either on a
+// single connect port or on the same port numbers as the listen ports.
+type proxyPortRange struct {
+	listenHost   string
+	listenStart  uint64
+	listenEnd    uint64
+	connectHost  string
+	connectStart uint64
+	connectEnd   uint64
+}
+
+// ports returns the listen and connect ports of the range formatted using the specified range separator.
+func (r *proxyPortRange) ports(separator string) (string, string) {
+	formatRange := func(start uint64, end uint64) string {
+		if start == end {
+			return fmt.Sprintf("%d", start)
+		}
+
+		return fmt.Sprintf("%d%s%d", start, separator, end)
+	}
+
+	return formatRange(r.listenStart, r.listenEnd), formatRange(r.connectStart, r.connectEnd)
+}
+
+// preservesPorts returns true if the range forwards each listen port to the same connect port number.
+func (r *proxyPortRange) preservesPorts() bool {
+	return r.listenStart == r.connectStart && r.listenEnd == r.connectEnd
+}
+
+// isWildcardAddress returns true if the address is the IPv4 or IPv6 wildcard address.
+func isWildcardAddress(host string) bool {
+	ip := net.ParseIP(host)
+
+	return ip != nil && (ip.Equal(net.IPv4zero) || ip.Equal(net.IPv6zero))
+}
+
+// proxyPortRanges groups the listen and connect addresses of a proxy device into as few port ranges as possible.
+// Consecutive listen ports are merged into a single range when they are all forwarded to the same connect port or
+// when each of them is forwarded to the same port number on the connect host.
+func proxyPortRanges(listen *deviceConfig.ProxyAddress, connect *deviceConfig.ProxyAddress) ([]*proxyPortRange, error) {
+	ranges := []*proxyPortRange{}
+	connectAddrCount := len(connect.Addr)
+
+	for i, lAddr := range listen.Addr {
+		listenHost, listenPortStr, err := net.SplitHostPort(lAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		// Use the connect address that corresponds to the listen address (unless only 1 is specified).
+		connectIndex := 0
+		if connectAddrCount > 1 {
+			connectIndex = i
+		}
+
+		connectHost, connectPortStr, err := net.SplitHostPort(connect.Addr[connectIndex])
+		if err != nil {
+			return nil, err
+		}
+
+		listenPort, err := strconv.ParseUint(listenPortStr, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid listen port %q", listenPortStr)
+		}
+
+		connectPort, err := strconv.ParseUint(connectPortStr, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid connect port %q", connectPortStr)
+		}
+
+		if len(ranges) > 0 {
+			last := ranges[len(ranges)-1]
+
+			if last.listenHost == listenHost && last.connectHost == connectHost && last.listenEnd+1 == listenPort {
+				// Many listen ports to a single connect port.
+				if last.connectStart == last.connectEnd && last.connectEnd == connectPort {
+					last.listenEnd = listenPort
+					continue
+				}
+
+				// Listen ports forwarded to the same connect port numbers.
+				if last.preservesPorts() && listenPort == connectPort {
+					last.listenEnd = listenPort
+					last.connectEnd = connectPort
+					continue
+				}
+			}
+		}
+
+		ranges = append(ranges, &proxyPortRange{
+			listenHost:   listenHost,
+			listenStart:  listenPort,
+			listenEnd:    listenPort,
+			connectHost:  connectHost,
+			connectStart: connectPort,
+			connectEnd:   connectPort,
+		})
+	}
+
+	return ranges, nil
+}
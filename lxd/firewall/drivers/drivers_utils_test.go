@@ -0,0 +1,91 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+)
+
+func Test_proxyPortRanges(t *testing.T) {
+	cases := []struct {
+		name    string
+		listen  []string
+		connect []string
+		ranges  [][2]string
+	}{
+		{
+			name:    "single port",
+			listen:  []string{"0.0.0.0:80"},
+			connect: []string{"10.0.0.2:8080"},
+			ranges:  [][2]string{{"80", "8080"}},
+		},
+		{
+			name:    "many to single port",
+			listen:  []string{"0.0.0.0:80", "0.0.0.0:81", "0.0.0.0:82"},
+			connect: []string{"10.0.0.2:8080"},
+			ranges:  [][2]string{{"80-82", "8080"}},
+		},
+		{
+			name:    "same port numbers",
+			listen:  []string{"0.0.0.0:80", "0.0.0.0:81", "0.0.0.0:82"},
+			connect: []string{"10.0.0.2:80", "10.0.0.2:81", "10.0.0.2:82"},
+			ranges:  [][2]string{{"80-82", "80-82"}},
+		},
+		{
+			name:    "shifted port numbers",
+			listen:  []string{"0.0.0.0:80", "0.0.0.0:81"},
+			connect: []string{"10.0.0.2:90", "10.0.0.2:91"},
+			ranges:  [][2]string{{"80", "90"}, {"81", "91"}},
+		},
+		{
+			name:    "non consecutive ports",
+			listen:  []string{"0.0.0.0:80", "0.0.0.0:82"},
+			connect: []string{"10.0.0.2:8080"},
+			ranges:  [][2]string{{"80", "8080"}, {"82", "8080"}},
+		},
+		{
+			name:    "different connect hosts",
+			listen:  []string{"0.0.0.0:80", "0.0.0.0:81"},
+			connect: []string{"10.0.0.2:80", "10.0.0.3:81"},
+			ranges:  [][2]string{{"80", "80"}, {"81", "81"}},
+		},
+		{
+			name:    "IPv6",
+			listen:  []string{"[::]:80", "[::]:81"},
+			connect: []string{"[fd00::2]:80", "[fd00::2]:81"},
+			ranges:  [][2]string{{"80-81", "80-81"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ranges, err := proxyPortRanges(&deviceConfig.ProxyAddress{Addr: c.listen}, &deviceConfig.ProxyAddress{Addr: c.connect})
+			require.NoError(t, err)
+			require.Len(t, ranges, len(c.ranges))
+
+			for i, r := range ranges {
+				listenPorts, connectPorts := r.ports("-")
+				assert.Equal(t, c.ranges[i][0], listenPorts)
+				assert.Equal(t, c.ranges[i][1], connectPorts)
+			}
+		})
+	}
+}
+
+func Test_proxyPortRangesInvalid(t *testing.T) {
+	cases := map[string][2]string{
+		"missing listen port":  {"0.0.0.0", "10.0.0.2:80"},
+		"invalid listen port":  {"0.0.0.0:65536", "10.0.0.2:80"},
+		"invalid connect port": {"0.0.0.0:80", "10.0.0.2:http"},
+	}
+
+	for name, addrs := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := proxyPortRanges(&deviceConfig.ProxyAddress{Addr: []string{addrs[0]}}, &deviceConfig.ProxyAddress{Addr: []string{addrs[1]}})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -27,6 +27,9 @@ const iptablesChainNICFilterPrefix = "lxd_nic"
 // iptablesChainACLFilterPrefix chain used for ACL specific filtering rules.
 const iptablesChainACLFilterPrefix = "lxd_acl"
 
+// ipsetACLAddressSetPrefix prefix used for the ipsets of the address sets referenced by ACL rules.
+const ipsetACLAddressSetPrefix = "lxd_"
+
 // ebtablesMu used for locking concurrent operations against ebtables.
 // As its own locking mechanism isn't always available.
 var ebtablesMu sync.Mutex
@@ -335,7 +338,8 @@ func (d Xtables) networkSetupForwardingPolicy(networkName string, ipVersion uint
 
 // networkSetupOutboundNAT configures outbound NAT.
 // If srcIP is non-nil then SNAT is used with the specified address, otherwise MASQUERADE mode is used.
-func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet, srcIP net.IP, appendRule bool) error {
+// Traffic destined to the excluded subnets is not NATed.
+func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet, srcIP net.IP, exclude []*net.IPNet, appendRule bool) error {
 	family := uint(4)
 	if subnet.IP.To4() == nil {
 		family = 6
@@ -368,6 +372,14 @@ func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet,
 		}
 	}
 
+	// Prepend the exclusions so that they are evaluated before the NAT rule above.
+	for _, excludeSubnet := range exclude {
+		err := d.iptablesPrepend(family, comment, "nat", "POSTROUTING", "-s", subnet.String(), "-d", excludeSubnet.String(), "-j", "RETURN")
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -420,14 +432,14 @@ func (d Xtables) networkSetupDHCPv4Checksum(networkName string) error {
 // NetworkSetup configure network firewall.
 func (d Xtables) NetworkSetup(networkName string, opts Opts) error {
 	if opts.SNATV4 != nil {
-		err := d.networkSetupOutboundNAT(networkName, opts.SNATV4.Subnet, opts.SNATV4.SNATAddress, opts.SNATV4.Append)
+		err := d.networkSetupOutboundNAT(networkName, opts.SNATV4.Subnet, opts.SNATV4.SNATAddress, opts.SNATV4.Exclude, opts.SNATV4.Append)
 		if err != nil {
 			return err
 		}
 	}
 
 	if opts.SNATV6 != nil {
-		err := d.networkSetupOutboundNAT(networkName, opts.SNATV6.Subnet, opts.SNATV6.SNATAddress, opts.SNATV6.Append)
+		err := d.networkSetupOutboundNAT(networkName, opts.SNATV6.Subnet, opts.SNATV6.SNATAddress, opts.SNATV6.Exclude, opts.SNATV6.Append)
 		if err != nil {
 			return err
 		}
@@ -487,10 +499,159 @@ func (d Xtables) NetworkSetup(networkName string, opts Opts) error {
 	return nil
 }
 
+// aclAddressSetName returns the name of the ipset used for the IP family of an address set.
+func (d Xtables) aclAddressSetName(addressSetName string, ipVersion uint) string {
+	return fmt.Sprintf("%s%s_%d", ipsetACLAddressSetPrefix, addressSetName, ipVersion)
+}
+
+// aclAddressSetsSetup creates the ipsets used for the address sets (or replaces their contents).
+// Each address set is represented by an IPv4 and an IPv6 ipset. The contents are built in a temporary ipset that
+// is then swapped in, so that the rules using the ipset never see it partially filled.
+func (d Xtables) aclAddressSetsSetup(addressSets []AddressSet) error {
+	if len(addressSets) <= 0 {
+		return nil
+	}
+
+	config := &strings.Builder{}
+	for _, addressSet := range addressSets {
+		for _, ipVersion := range []uint{4, 6} {
+			setName := d.aclAddressSetName(addressSet.Name, ipVersion)
+
+			family := "inet"
+			if ipVersion == 6 {
+				family = "inet6"
+			}
+
+			tmpSetName := fmt.Sprintf("%s_tmp", setName)
+
+			fmt.Fprintf(config, "create %s hash:net family %s -exist\n", setName, family)
+			fmt.Fprintf(config, "create %s hash:net family %s -exist\n", tmpSetName, family)
+			fmt.Fprintf(config, "flush %s\n", tmpSetName)
+
+			for _, address := range addressSet.Addresses {
+				ip := net.ParseIP(address)
+				if ip == nil {
+					ip, _, _ = net.ParseCIDR(address)
+				}
+
+				if ip == nil {
+					return fmt.Errorf("Invalid address %q in address set %q", address, addressSet.Name)
+				}
+
+				if (ip.To4() == nil) != (ipVersion == 6) {
+					continue // Skip addresses that are not for the ipset's IP family.
+				}
+
+				fmt.Fprintf(config, "add %s %s -exist\n", tmpSetName, address)
+			}
+
+			fmt.Fprintf(config, "swap %s %s\n", tmpSetName, setName)
+			fmt.Fprintf(config, "destroy %s\n", tmpSetName)
+		}
+	}
+
+	err := shared.RunCommandWithFds(strings.NewReader(config.String()), nil, "ipset", "restore")
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting up address sets")
+	}
+
+	return nil
+}
+
+// aclAddressSetsList returns the LXD address set ipsets along with the number of references to each of them.
+func (d Xtables) aclAddressSetsList() (map[string]int, error) {
+	setRefs := make(map[string]int)
+
+	// Without the ipset tool no address sets can have been created.
+	_, err := exec.LookPath("ipset")
+	if err != nil {
+		return setRefs, nil
+	}
+
+	output, err := shared.RunCommand("ipset", "list", "-t")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed listing ipsets")
+	}
+
+	setName := ""
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(fields[1])
+
+		switch fields[0] {
+		case "Name":
+			setName = ""
+			if strings.HasPrefix(value, ipsetACLAddressSetPrefix) {
+				setName = value
+			}
+		case "References":
+			if setName != "" {
+				setRefs[setName], _ = strconv.Atoi(value)
+			}
+		}
+	}
+
+	return setRefs, nil
+}
+
+// aclAddressSetsPrune destroys the address set ipsets that are no longer referenced by any rules.
+func (d Xtables) aclAddressSetsPrune() error {
+	setRefs, err := d.aclAddressSetsList()
+	if err != nil {
+		return err
+	}
+
+	for setName, refs := range setRefs {
+		if refs > 0 {
+			continue
+		}
+
+		_, err = shared.RunCommand("ipset", "destroy", setName)
+		if err != nil {
+			return errors.Wrapf(err, "Failed deleting ipset %q", setName)
+		}
+	}
+
+	return nil
+}
+
+// NetworkDeleteACLAddressSet removes the ipsets of an address set.
+func (d Xtables) NetworkDeleteACLAddressSet(addressSetName string) error {
+	setRefs, err := d.aclAddressSetsList()
+	if err != nil {
+		return err
+	}
+
+	for _, ipVersion := range []uint{4, 6} {
+		setName := d.aclAddressSetName(addressSetName, ipVersion)
+
+		_, found := setRefs[setName]
+		if !found {
+			continue
+		}
+
+		_, err = shared.RunCommand("ipset", "destroy", setName)
+		if err != nil {
+			return errors.Wrapf(err, "Failed deleting ipset %q", setName)
+		}
+	}
+
+	return nil
+}
+
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
-func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule, addressSets []AddressSet) error {
 	chain := fmt.Sprintf("%s_%s", iptablesChainACLFilterPrefix, networkName)
 
+	addressSetsByName := make(map[string]AddressSet, len(addressSets))
+	for _, addressSet := range addressSets {
+		addressSetsByName[addressSet.Name] = addressSet
+	}
+
 	// Parse rules for both IP families before applying either family of rules.
 	iptCmdRules := make(map[string][][]string)
 	for _, ipVersion := range []uint{4, 6} {
@@ -501,7 +662,7 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 
 		iptRules := make([][]string, 0)
 		for _, rule := range rules {
-			actionArgs, logArgs, err := d.aclRuleCriteriaToArgs(networkName, ipVersion, &rule)
+			actionArgs, logArgs, err := d.aclRuleCriteriaToArgs(networkName, ipVersion, &rule, addressSetsByName)
 			if err != nil {
 				return err
 			}
@@ -544,6 +705,12 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 		return nil
 	}
 
+	// Setup the address sets before the rules referencing them.
+	err := d.aclAddressSetsSetup(addressSets)
+	if err != nil {
+		return err
+	}
+
 	// Apply each family of rules.
 	for cmd, rules := range iptCmdRules {
 		err := applyACLRules(cmd, rules)
@@ -558,9 +725,19 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 // aclRuleCriteriaToArgs converts an ACL rule into an set of arguments for an xtables rule.
 // Returns the arguments to use for the action command and separately the arguments for logging if enabled.
 // Returns nil arguments if the rule is not appropriate for the ipVersion.
-func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule *ACLRule) ([]string, []string, error) {
+func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule *ACLRule, addressSets map[string]AddressSet) ([]string, []string, error) {
 	var args []string
 
+	// Address sets can contain addresses of both IP families, so for ICMP rules only the addresses of the ICMP
+	// protocol's IP family are used.
+	var icmpIPVersion uint
+	switch rule.Protocol {
+	case "icmp4":
+		icmpIPVersion = 4
+	case "icmp6":
+		icmpIPVersion = 6
+	}
+
 	if rule.Direction == "ingress" {
 		args = append(args, "-o", networkName) // Coming from host into network's interface.
 	} else {
@@ -569,7 +746,7 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 
 	// Add subject filters.
 	if rule.Source != "" {
-		matchArgs, err := d.aclRuleSubjectToACLMatch("source", ipVersion, util.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		matchArgs, err := d.aclRuleSubjectToACLMatch("source", ipVersion, icmpIPVersion, addressSets, util.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -582,7 +759,7 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 	}
 
 	if rule.Destination != "" {
-		matchArgs, err := d.aclRuleSubjectToACLMatch("destination", ipVersion, util.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		matchArgs, err := d.aclRuleSubjectToACLMatch("destination", ipVersion, icmpIPVersion, addressSets, util.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -676,9 +853,57 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 
 // aclRuleSubjectToACLMatch converts direction (source/destination) and subject criteria list into xtables args.
 // Returns nil if none of the subjects are appropriate for the ipVersion.
-func (d Xtables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subjectCriteria ...string) ([]string, error) {
+func (d Xtables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, icmpIPVersion uint, addressSets map[string]AddressSet, subjectCriteria ...string) ([]string, error) {
 	fieldParts := make([]string, 0, len(subjectCriteria))
 
+	// Address set references are matched against the ipset for the ipVersion.
+	if len(subjectCriteria) == 1 && strings.HasPrefix(subjectCriteria[0], ACLRuleSubjectAddressSetPrefix) {
+		addressSetName := strings.TrimPrefix(subjectCriteria[0], ACLRuleSubjectAddressSetPrefix)
+		addressSet, found := addressSets[addressSetName]
+		if !found {
+			return nil, fmt.Errorf("Unknown address set %q", addressSetName)
+		}
+
+		// Work out which IP families the address set has usable addresses for.
+		hasIPVersion := map[uint]bool{}
+		for _, address := range addressSet.Addresses {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				ip, _, _ = net.ParseCIDR(address)
+			}
+
+			if ip == nil {
+				continue
+			}
+
+			var subjectIPVersion uint = 4
+			if ip.To4() == nil {
+				subjectIPVersion = 6
+			}
+
+			if icmpIPVersion == 0 || icmpIPVersion == subjectIPVersion {
+				hasIPVersion[subjectIPVersion] = true
+			}
+		}
+
+		var otherIPVersion uint = 6
+		if ipVersion == 6 {
+			otherIPVersion = 4
+		}
+
+		// Skip the ipVersion if it cannot be used or if only the other IP family has addresses.
+		if (icmpIPVersion > 0 && icmpIPVersion != ipVersion) || (!hasIPVersion[ipVersion] && hasIPVersion[otherIPVersion]) {
+			return nil, nil // Address set is not appropriate for ipVersion.
+		}
+
+		setDirection := "src"
+		if direction == "destination" {
+			setDirection = "dst"
+		}
+
+		return []string{"-m", "set", "--match-set", d.aclAddressSetName(addressSetName, ipVersion), setDirection}, nil
+	}
+
 	// For each criterion check if value looks like IP CIDR.
 	for _, subjectCriterion := range subjectCriteria {
 		ip := net.ParseIP(subjectCriterion)
@@ -726,6 +951,31 @@ func (d Xtables) aclRulePortToACLMatch(direction string, portCriteria ...string)
 	return []string{"-m", "multiport", fmt.Sprintf("--%s", direction), strings.Join(fieldParts, ",")}
 }
 
+// NetworkUpdateOutboundNAT replaces the outbound NAT rules of the network, leaving its other rules untouched.
+func (d Xtables) NetworkUpdateOutboundNAT(networkName string, SNATV4 *SNATOpts, SNATV6 *SNATOpts) error {
+	for _, snat := range []struct {
+		ipVersion uint
+		opts      *SNATOpts
+	}{{ipVersion: 4, opts: SNATV4}, {ipVersion: 6, opts: SNATV6}} {
+		// The outbound NAT rules are the only rules of the network in the nat table.
+		err := d.iptablesClear(snat.ipVersion, d.networkIPTablesComment(networkName), "nat")
+		if err != nil {
+			return err
+		}
+
+		if snat.opts == nil {
+			continue
+		}
+
+		err = d.networkSetupOutboundNAT(networkName, snat.opts.Subnet, snat.opts.SNATAddress, snat.opts.Exclude, snat.opts.Append)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // NetworkClear removes network rules from filter, mangle and nat tables.
 // If delete is true then network-specific chains are also removed.
 func (d Xtables) NetworkClear(networkName string, delete bool, ipVersions []uint) error {
@@ -768,6 +1018,12 @@ func (d Xtables) NetworkClear(networkName string, delete bool, ipVersions []uint
 		}
 	}
 
+	// Remove the address sets that were only used by the network's ACL rules.
+	err := d.aclAddressSetsPrune()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -894,48 +1150,54 @@ func (d Xtables) InstanceSetupProxyNAT(projectName string, instanceName string,
 
 	comment := d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName)
 
-	for i, lAddr := range listen.Addr {
-		listenHost, listenPort, err := net.SplitHostPort(lAddr)
-		if err != nil {
-			return err
-		}
-
-		// Use the connect address that corresponds to the listen address (unless only 1 is specified).
-		connectIndex := 0
-		if connectAddrCount > 1 {
-			connectIndex = i
-		}
+	// Group the listen ports into ranges so that each range only needs a single rule.
+	portRanges, err := proxyPortRanges(listen, connect)
+	if err != nil {
+		return err
+	}
 
-		connectHost, connectPort, err := net.SplitHostPort(connect.Addr[connectIndex])
-		if err != nil {
-			return err
-		}
+	for i, portRange := range portRanges {
+		listenPort, connectPort := portRange.ports(":")
 
 		// Decide if we are using iptables/ip6tables and format the destination host/port as appropriate.
 		ipVersion := uint(4)
-		connectDest := fmt.Sprintf("%s:%s", connectHost, connectPort)
-		connectIP := net.ParseIP(connectHost)
+		connectDest := fmt.Sprintf("%s:%s", portRange.connectHost, connectPort)
+		connectIP := net.ParseIP(portRange.connectHost)
 		if connectIP.To4() == nil {
 			ipVersion = 6
-			connectDest = fmt.Sprintf("[%s]:%s", connectHost, connectPort)
+			connectDest = fmt.Sprintf("[%s]:%s", portRange.connectHost, connectPort)
 		}
 
+		// If each listen port is forwarded to the same port number then only the address needs rewriting.
+		if portRange.listenStart != portRange.listenEnd && portRange.preservesPorts() {
+			connectDest = portRange.connectHost
+		}
+
+		// A wildcard listen address matches traffic to any of the local addresses.
+		listenMatch := []string{"--destination", portRange.listenHost}
+		if isWildcardAddress(portRange.listenHost) {
+			listenMatch = []string{"-m", "addrtype", "--dst-type", "LOCAL"}
+		}
+
+		dnatArgs := append([]string{"-p", listen.ConnType}, listenMatch...)
+		dnatArgs = append(dnatArgs, "--dport", listenPort, "-j", "DNAT", "--to-destination", connectDest)
+
 		// outbound <-> instance.
-		err = d.iptablesPrepend(ipVersion, comment, "nat", "PREROUTING", "-p", listen.ConnType, "--destination", listenHost, "--dport", listenPort, "-j", "DNAT", "--to-destination", connectDest)
+		err = d.iptablesPrepend(ipVersion, comment, "nat", "PREROUTING", dnatArgs...)
 		if err != nil {
 			return err
 		}
 
 		// host <-> instance.
-		err = d.iptablesPrepend(ipVersion, comment, "nat", "OUTPUT", "-p", listen.ConnType, "--destination", listenHost, "--dport", listenPort, "-j", "DNAT", "--to-destination", connectDest)
+		err = d.iptablesPrepend(ipVersion, comment, "nat", "OUTPUT", dnatArgs...)
 		if err != nil {
 			return err
 		}
 
-		if connectIndex == i {
+		if connectAddrCount > 1 || i == 0 {
 			// instance <-> instance.
 			// Requires instance's bridge port has hairpin mode enabled when br_netfilter is loaded.
-			err = d.iptablesPrepend(ipVersion, comment, "nat", "POSTROUTING", "-p", listen.ConnType, "--source", connectHost, "--destination", connectHost, "--dport", connectPort, "-j", "MASQUERADE")
+			err = d.iptablesPrepend(ipVersion, comment, "nat", "POSTROUTING", "-p", listen.ConnType, "--source", portRange.connectHost, "--destination", portRange.connectHost, "--dport", connectPort, "-j", "MASQUERADE")
 			if err != nil {
 				return err
 			}
@@ -1277,6 +1539,16 @@ func (d Xtables) InstanceClearRPFilter(projectName string, instanceName string,
 	return nil
 }
 
+// InstanceSetupACLRules is not supported by the xtables driver.
+func (d Xtables) InstanceSetupACLRules(projectName string, instanceName string, deviceName string, interfaceName string, netnsPID int, rules []ACLRule, addressSets []AddressSet) error {
+	return fmt.Errorf("Network ACLs on instance devices require the nftables firewall driver")
+}
+
+// InstanceClearACLRules does nothing as ACL rules are never applied to instance devices by the xtables driver.
+func (d Xtables) InstanceClearACLRules(projectName string, instanceName string, deviceName string, netnsPID int) error {
+	return nil
+}
+
 // iptablesChainExists checks whether a chain exists in a table, and whether it has any rules.
 func (d Xtables) iptablesChainExists(ipVersion uint, table string, chain string) (bool, bool, error) {
 	var cmd string
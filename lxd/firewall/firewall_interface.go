@@ -14,7 +14,9 @@ type Firewall interface {
 
 	NetworkSetup(networkName string, opts drivers.Opts) error
 	NetworkClear(networkName string, delete bool, ipVersions []uint) error
-	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule) error
+	NetworkUpdateOutboundNAT(networkName string, SNATV4 *drivers.SNATOpts, SNATV6 *drivers.SNATOpts) error
+	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule, addressSets []drivers.AddressSet) error
+	NetworkDeleteACLAddressSet(addressSetName string) error
 
 	InstanceSetupBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4 net.IP, IPv6 net.IP, parentManaged bool) error
 	InstanceClearBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4 net.IP, IPv6 net.IP) error
@@ -24,4 +26,7 @@ type Firewall interface {
 
 	InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error
 	InstanceClearRPFilter(projectName string, instanceName string, deviceName string) error
+
+	InstanceSetupACLRules(projectName string, instanceName string, deviceName string, interfaceName string, netnsPID int, rules []drivers.ACLRule, addressSets []drivers.AddressSet) error
+	InstanceClearACLRules(projectName string, instanceName string, deviceName string, netnsPID int) error
 }
@@ -1,13 +1,39 @@
 package firewall
 
 import (
+	"os"
+
 	"github.com/lxc/lxd/lxd/firewall/drivers"
 	"github.com/lxc/lxd/shared/logger"
 )
 
 // New returns an appropriate firewall implementation.
-// Uses xtables if nftables isn't compatible or isn't in use already, otherwise uses nftables.
+// Uses firewalld if it is running (unless LXD_FIREWALL_DRIVER is set to another driver), otherwise uses xtables
+// if nftables isn't compatible or isn't in use already, otherwise uses nftables.
 func New() Firewall {
+	// Rules added alongside a running firewalld may conflict with its own, so use it whenever it is running.
+	// Setting LXD_FIREWALL_DRIVER to any other value keeps LXD managing its own rules.
+	driver := os.Getenv("LXD_FIREWALL_DRIVER")
+	if driver == "" || driver == "firewalld" {
+		firewalld := drivers.Firewalld{}
+
+		_, err := firewalld.Compat()
+		if err != nil {
+			if driver == "firewalld" {
+				logger.Warnf(`Firewall detected "firewalld" incompatibility, falling back to the other drivers: %v`, err)
+			} else {
+				logger.Debugf(`Firewall detected "firewalld" incompatibility: %v`, err)
+			}
+		} else {
+			err = firewalld.WatchReload()
+			if err != nil {
+				logger.Warnf("Firewall failed watching for firewalld reloads: %v", err)
+			}
+
+			return firewalld
+		}
+	}
+
 	nftables := drivers.Nftables{}
 	xtables := drivers.Xtables{}
 
@@ -31,6 +31,7 @@ import (
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
 	"github.com/lxc/lxd/lxd/filter"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
@@ -44,6 +45,7 @@ import (
 	storagePools "github.com/lxc/lxd/lxd/storage"
 	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/lxd/warnings"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/ioprogress"
@@ -504,6 +506,32 @@ func getImgPostInfo(d *Daemon, r *http.Request, builddir string, project string,
 
 	info.Public = shared.IsTrue(r.Header.Get("X-LXD-public"))
 	propHeaders := r.Header[http.CanonicalHeaderKey("X-LXD-properties")]
+
+	info.Signature = r.Header.Get("X-LXD-signature")
+	signature, ok := metadata["signature"]
+	if ok && signature.(string) != "" {
+		info.Signature = signature.(string)
+	}
+
+	// Verify the image signature before the image is moved into the image store.
+	// Images synchronized between cluster members were already verified by the receiving member.
+	verifySignature := func() error {
+		if isClusterNotification(r) {
+			return nil
+		}
+
+		trustKeys, err := cluster.ConfigGetString(d.cluster, "images.trust.keys")
+		if err != nil {
+			return err
+		}
+
+		if trustKeys == "" {
+			return nil
+		}
+
+		return imageVerifySignature(trustKeys, info.Fingerprint, info.Signature)
+	}
+
 	ctype, ctypeParams, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
 		ctype = "application/octet-stream"
@@ -584,6 +612,11 @@ func getImgPostInfo(d *Daemon, r *http.Request, builddir string, project string,
 			return nil, err
 		}
 
+		err = verifySignature()
+		if err != nil {
+			return nil, err
+		}
+
 		imageMeta, _, err = getImageMetadata(imageTarf.Name())
 		if err != nil {
 			logger.Error("Failed to get image metadata", log.Ctx{"err": err})
@@ -630,6 +663,11 @@ func getImgPostInfo(d *Daemon, r *http.Request, builddir string, project string,
 			return nil, err
 		}
 
+		err = verifySignature()
+		if err != nil {
+			return nil, err
+		}
+
 		var imageType string
 		imageMeta, imageType, err = getImageMetadata(post.Name())
 		if err != nil {
@@ -703,6 +741,18 @@ func getImgPostInfo(d *Daemon, r *http.Request, builddir string, project string,
 		if err != nil {
 			return nil, err
 		}
+
+		if info.Signature != "" {
+			id, _, err := d.cluster.GetImage(project, info.Fingerprint, false)
+			if err != nil {
+				return nil, err
+			}
+
+			err = d.cluster.UpdateImageSignature(id, info.Signature)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return &info, nil
@@ -804,6 +854,11 @@ func imageCreateInPool(d *Daemon, info *api.Image, storagePool string) error {
 //     description: Expected fingerprint when pushing a raw image
 //     schema:
 //       type: string
+//   - in: header
+//     name: X-LXD-signature
+//     description: Detached signature of the image fingerprint when pushing a raw image
+//     schema:
+//       type: string
 // responses:
 //   "200":
 //     $ref: "#/responses/Operation"
@@ -909,12 +964,13 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 			"expires_at": req.ExpiresAt,
 			"properties": req.Properties,
 			"public":     req.Public,
+			"signature":  req.Signature,
 		}
 
 		return createTokenResponse(d, r, projectName, req.Source.Fingerprint, metadata)
 	}
 
-	if !imageUpload && !shared.StringInSlice(req.Source.Type, []string{"container", "instance", "virtual-machine", "snapshot", "image", "url"}) {
+	if !imageUpload && !shared.StringInSlice(req.Source.Type, []string{"container", "instance", "virtual-machine", "snapshot", "image", "url", "build"}) {
 		cleanup(builddir, post)
 		return response.InternalError(fmt.Errorf("Invalid images JSON"))
 	}
@@ -956,6 +1012,9 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 			} else if req.Source.Type == "url" {
 				/* Processing image copy from URL */
 				info, err = imgPostURLInfo(d, r, req, op, projectName, budget)
+			} else if req.Source.Type == "build" {
+				/* Processing image build from a recipe */
+				info, err = imgPostBuildInfo(d, r, req, op, builddir, budget)
 			} else {
 				/* Processing image creation from container */
 				imagePublishLock.Lock()
@@ -975,6 +1034,12 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 				metadata["secret"] = secret.(string)
 			}
 
+			// Keep build log if available
+			buildLog, ok := op.Metadata()["build_log"]
+			if ok {
+				metadata["build_log"] = buildLog.(string)
+			}
+
 			op.UpdateMetadata(metadata)
 		}
 		if err != nil {
@@ -1529,8 +1594,18 @@ func autoUpdateImages(ctx context.Context, d *Daemon) error {
 				if err == context.Canceled {
 					return nil
 				}
+
+				err = d.cluster.UpsertWarningLocalNode(image.Project, dbCluster.TypeImage, image.ID, db.WarningImageAutoUpdateFailure, err.Error())
+				if err != nil {
+					logger.Warn("Failed to create warning", log.Ctx{"err": err})
+				}
 			} else {
 				deleteIDs = append(deleteIDs, image.ID)
+
+				err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.cluster, image.Project, db.WarningImageAutoUpdateFailure, dbCluster.TypeImage, image.ID)
+				if err != nil {
+					logger.Warn("Failed to resolve warning", log.Ctx{"err": err})
+				}
 			}
 
 			// newInfo will have the same content for each image in the list.
@@ -2530,6 +2605,14 @@ func imagePut(d *Daemon, r *http.Request) response.Response {
 		info.ExpiresAt = req.ExpiresAt
 	}
 
+	// Update the signature (clients unaware of signatures don't clear it)
+	if req.Signature != "" && req.Signature != info.Signature {
+		err = imageUpdateSignature(d, id, info.Fingerprint, req.Signature)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
 	// Get profile IDs
 	if req.Profiles == nil {
 		req.Profiles = []string{"default"}
@@ -2649,6 +2732,15 @@ func imagePatch(d *Daemon, r *http.Request) response.Response {
 		info.Properties = properties
 	}
 
+	// Get Signature
+	signature, err := reqRaw.GetString("signature")
+	if err == nil && signature != info.Signature {
+		err = imageUpdateSignature(d, id, info.Fingerprint, signature)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
 	err = d.cluster.UpdateImage(id, info.Filename, info.Size, info.Public, info.AutoUpdate, info.Architecture, info.CreatedAt, info.ExpiresAt, info.Properties, "", nil)
 	if err != nil {
 		return response.SmartError(err)
@@ -2660,6 +2752,23 @@ func imagePatch(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// imageUpdateSignature sets the signature of an image, after verifying it against images.trust.keys (if set).
+func imageUpdateSignature(d *Daemon, id int, fingerprint string, signature string) error {
+	trustKeys, err := cluster.ConfigGetString(d.cluster, "images.trust.keys")
+	if err != nil {
+		return err
+	}
+
+	if trustKeys != "" {
+		err = imageVerifySignature(trustKeys, fingerprint, signature)
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.cluster.UpdateImageSignature(id, signature)
+}
+
 // swagger:operation POST /1.0/images/aliases images images_aliases_post
 //
 // Add an image alias
@@ -0,0 +1,651 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/state"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+// imageBuildDefaultTimeout is the maximum duration of an image build when the recipe doesn't set one.
+const imageBuildDefaultTimeout = time.Hour
+
+// imageBuildReadyTimeout is how long to wait for commands to be runnable in the build instance once started
+// (waiting for lxd-agent in virtual machines).
+const imageBuildReadyTimeout = 5 * time.Minute
+
+// imageBuildLogMaxSize is the maximum size of the build log kept in the operation metadata, only the end of the
+// output of the commands is kept past that.
+const imageBuildLogMaxSize = 64 * 1024
+
+// imageBuildPackagesScript installs the packages passed as arguments with the package manager of the distribution,
+// once the network is up.
+const imageBuildPackagesScript = `set -e
+i=0
+while [ "$i" -lt 60 ]; do
+	ip -4 route show default 2>/dev/null | grep -q default && break
+	ip -6 route show default 2>/dev/null | grep -q default && break
+	i=$((i+1))
+	sleep 1
+done
+
+if command -v apt-get >/dev/null 2>&1; then
+	export DEBIAN_FRONTEND=noninteractive
+	apt-get update
+	apt-get install -y "$@"
+elif command -v dnf >/dev/null 2>&1; then
+	dnf install -y "$@"
+elif command -v yum >/dev/null 2>&1; then
+	yum install -y "$@"
+elif command -v apk >/dev/null 2>&1; then
+	apk add --no-cache "$@"
+elif command -v zypper >/dev/null 2>&1; then
+	zypper --non-interactive install "$@"
+elif command -v pacman >/dev/null 2>&1; then
+	pacman -Sy --noconfirm "$@"
+else
+	echo "No supported package manager found" >&2
+	exit 1
+fi
+`
+
+// imageBuild tracks the state of an image build.
+type imageBuild struct {
+	op       *operations.Operation
+	inst     instance.Instance
+	deadline time.Time
+
+	log      bytes.Buffer
+	progress string
+}
+
+// updateMetadata publishes the build progress in the operation metadata, along with the build log if withLog
+// is set. The log is only published once the build is done to avoid sending it over and over in events.
+func (b *imageBuild) updateMetadata(withLog bool) {
+	metadata := map[string]interface{}{
+		"build_progress": b.progress,
+	}
+
+	if withLog {
+		metadata["build_log"] = b.log.String()
+	}
+
+	err := b.op.UpdateMetadata(metadata)
+	if err != nil {
+		logger.Debug("Failed updating image build metadata", log.Ctx{"err": err})
+	}
+}
+
+// appendLog adds data to the build log, truncating it from the start past imageBuildLogMaxSize.
+func (b *imageBuild) appendLog(data []byte) {
+	b.log.Write(data)
+
+	if b.log.Len() > imageBuildLogMaxSize {
+		tail := b.log.Bytes()[b.log.Len()-imageBuildLogMaxSize:]
+
+		// Start on a new line if possible.
+		idx := bytes.IndexByte(tail, '\n')
+		if idx >= 0 && idx < len(tail)-1 {
+			tail = tail[idx+1:]
+		}
+
+		truncated := append([]byte("[...]\n"), tail...)
+		b.log.Reset()
+		b.log.Write(truncated)
+	}
+}
+
+// step records the start of a new build step.
+func (b *imageBuild) step(format string, args ...interface{}) {
+	b.progress = fmt.Sprintf(format, args...)
+	b.appendLog([]byte(fmt.Sprintf("==> %s\n", b.progress)))
+	b.updateMetadata(false)
+}
+
+// exec runs a command in the build instance through the regular exec path (lxd-agent for virtual machines),
+// appending its output to the build log, and returns its exit code.
+func (b *imageBuild) exec(command ...string) (int, error) {
+	remaining := time.Until(b.deadline)
+	if remaining <= 0 {
+		return -1, fmt.Errorf("Image build timed out")
+	}
+
+	req := api.InstanceExecPost{
+		Command:     command,
+		Environment: instanceExecDefaultEnvironment(b.inst),
+		Cwd:         "/",
+	}
+
+	outFile, err := ioutil.TempFile("", "lxd_image_build_")
+	if err != nil {
+		return -1, err
+	}
+	defer os.Remove(outFile.Name())
+	defer outFile.Close()
+
+	cmd, err := b.inst.Exec(req, nil, outFile, outFile)
+	if err != nil {
+		return -1, err
+	}
+
+	type result struct {
+		exitCode int
+		err      error
+	}
+
+	chResult := make(chan result, 1)
+	go func() {
+		exitCode, err := cmd.Wait()
+		chResult <- result{exitCode: exitCode, err: err}
+	}()
+
+	var res result
+
+	select {
+	case res = <-chResult:
+	case <-time.After(remaining):
+		cmd.Signal(unix.SIGKILL)
+		return -1, fmt.Errorf("Image build timed out")
+	}
+
+	output, err := ioutil.ReadFile(outFile.Name())
+	if err == nil {
+		b.appendLog(output)
+	}
+
+	return res.exitCode, res.err
+}
+
+// run runs a shell command in the build instance and fails if it doesn't succeed.
+func (b *imageBuild) run(command ...string) error {
+	exitCode, err := b.exec(command...)
+	if err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		return fmt.Errorf("Command %q failed with exit code %d", strings.Join(command, " "), exitCode)
+	}
+
+	return nil
+}
+
+// waitReady waits for commands to be runnable in the build instance.
+func (b *imageBuild) waitReady() error {
+	deadline := time.Now().Add(imageBuildReadyTimeout)
+	for {
+		req := api.InstanceExecPost{
+			Command:     []string{"true"},
+			Environment: instanceExecDefaultEnvironment(b.inst),
+			Cwd:         "/",
+		}
+
+		cmd, err := b.inst.Exec(req, nil, nil, nil)
+		if err == nil {
+			exitCode, err := cmd.Wait()
+			if err == nil && exitCode == 0 {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) || time.Now().After(b.deadline) {
+			return fmt.Errorf("Timed out waiting for the build instance to be ready")
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// pushFile writes a recipe file into the build instance.
+func (b *imageBuild) pushFile(file api.ImageBuildFile) error {
+	err := b.run("mkdir", "-p", filepath.Dir(file.Path))
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile("", "lxd_image_build_file_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(file.Content)
+	tmpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	mode := file.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+
+	return b.inst.FilePush("file", tmpFile.Name(), file.Path, file.UID, file.GID, mode, "overwrite")
+}
+
+// addTemplates adds the recipe templates to the metadata of the (stopped) build instance.
+func (b *imageBuild) addTemplates(s *state.State, templates []api.ImageBuildTemplate) error {
+	pool, err := storagePools.GetPoolByInstance(s, b.inst)
+	if err != nil {
+		return err
+	}
+
+	_, err = storagePools.InstanceMount(pool, b.inst, nil)
+	if err != nil {
+		return err
+	}
+	defer storagePools.InstanceUnmount(pool, b.inst, nil)
+
+	metadata := api.ImageMetadata{}
+	metadataPath := filepath.Join(b.inst.Path(), "metadata.yaml")
+	if shared.PathExists(metadataPath) {
+		data, err := ioutil.ReadFile(metadataPath)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.Unmarshal(data, &metadata)
+		if err != nil {
+			return errors.Wrap(err, "Failed parsing instance metadata")
+		}
+	}
+
+	if metadata.Templates == nil {
+		metadata.Templates = map[string]*api.ImageMetadataTemplate{}
+	}
+
+	templatesPath := filepath.Join(b.inst.Path(), "templates")
+	err = os.MkdirAll(templatesPath, 0711)
+	if err != nil {
+		return err
+	}
+
+	// Don't overwrite the templates of the base image, except for the paths the recipe replaces.
+	usedNames := map[string]bool{}
+	for path, template := range metadata.Templates {
+		if template != nil && !imageBuildHasTemplate(templates, path) {
+			usedNames[template.Template] = true
+		}
+	}
+
+	for _, template := range templates {
+		name := imageBuildTemplateName(template.Path, usedNames)
+		usedNames[name] = true
+
+		err = ioutil.WriteFile(filepath.Join(templatesPath, name), []byte(template.Content), 0644)
+		if err != nil {
+			return err
+		}
+
+		when := template.When
+		if len(when) == 0 {
+			when = []string{"create", "copy"}
+		}
+
+		metadata.Templates[template.Path] = &api.ImageMetadataTemplate{
+			When:       when,
+			CreateOnly: template.CreateOnly,
+			Template:   name,
+			Properties: template.Properties,
+		}
+	}
+
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(metadataPath, data, 0644)
+}
+
+// imageBuildHasTemplate returns whether the recipe templates include one for path.
+func imageBuildHasTemplate(templates []api.ImageBuildTemplate, path string) bool {
+	for _, template := range templates {
+		if template.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// imageBuildTemplateName returns the file name of the template for path, derived from the path and made unique
+// among the names already in use (as both "/etc/a-b" and "/etc/a/b" would otherwise map to "etc-a-b.tpl").
+func imageBuildTemplateName(path string, usedNames map[string]bool) string {
+	base := strings.ReplaceAll(strings.Trim(path, "/"), "/", "-")
+
+	name := fmt.Sprintf("%s.tpl", base)
+	for i := 1; usedNames[name]; i++ {
+		name = fmt.Sprintf("%s-%d.tpl", base, i)
+	}
+
+	return name
+}
+
+// imageBuildValidate checks an image build recipe.
+func imageBuildValidate(build *api.ImageBuild) error {
+	if build == nil {
+		return fmt.Errorf("No build recipe provided")
+	}
+
+	if build.Timeout < 0 {
+		return fmt.Errorf("Invalid build timeout %d", build.Timeout)
+	}
+
+	for _, file := range build.Files {
+		if !filepath.IsAbs(file.Path) || filepath.Clean(file.Path) == "/" {
+			return fmt.Errorf("Invalid file path %q, must be an absolute file path", file.Path)
+		}
+	}
+
+	templatePaths := map[string]bool{}
+	for _, template := range build.Templates {
+		if !filepath.IsAbs(template.Path) || filepath.Clean(template.Path) == "/" {
+			return fmt.Errorf("Invalid template path %q, must be an absolute file path", template.Path)
+		}
+
+		if templatePaths[template.Path] {
+			return fmt.Errorf("Duplicate template path %q", template.Path)
+		}
+
+		templatePaths[template.Path] = true
+
+		for _, when := range template.When {
+			if !shared.StringInSlice(when, []string{"create", "copy", "start"}) {
+				return fmt.Errorf("Invalid template trigger %q for %q, must be one of create, copy or start", when, template.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// imgPostBuildInfo builds a new image from a recipe. The recipe is applied to a temporary ephemeral instance
+// created from the base image, which is then published as the new image and deleted.
+func imgPostBuildInfo(d *Daemon, r *http.Request, req api.ImagesPost, op *operations.Operation, builddir string, budget int64) (*api.Image, error) {
+	projectName := projectParam(r)
+	recipe := req.Source.Build
+
+	err := imageBuildValidate(recipe)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := imageBuildDefaultTimeout
+	if recipe.Timeout > 0 {
+		timeout = time.Duration(recipe.Timeout) * time.Second
+	}
+
+	b := &imageBuild{
+		op:       op,
+		deadline: time.Now().Add(timeout),
+	}
+
+	// Publish the build log in the operation metadata once done (publishing the image resets it).
+	defer b.updateMetadata(true)
+
+	// Get the base image.
+	imgType, err := instancetype.New(req.Source.ImageType)
+	if err != nil {
+		return nil, err
+	}
+
+	source := api.InstanceSource{
+		Type:        "image",
+		Alias:       req.Source.Alias,
+		Fingerprint: req.Source.Fingerprint,
+		Server:      req.Source.Server,
+		Protocol:    req.Source.Protocol,
+		Certificate: req.Source.Certificate,
+		Secret:      req.Source.Secret,
+	}
+
+	if source.Alias == "" && source.Fingerprint == "" {
+		return nil, fmt.Errorf("No base image provided")
+	}
+
+	hash, err := instance.ResolveImage(d.State(), projectName, source)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed resolving base image")
+	}
+
+	b.step("Retrieving base image")
+
+	var baseImage *api.Image
+	if source.Server != "" {
+		var autoUpdate bool
+		p, err := d.cluster.GetProject(projectName)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Config["images.auto_update_cached"] != "" {
+			autoUpdate = shared.IsTrue(p.Config["images.auto_update_cached"])
+		} else {
+			autoUpdate, err = cluster.ConfigGetBool(d.cluster, "images.auto_update_cached")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		baseImage, err = d.ImageDownload(r, op, &ImageDownloadArgs{
+			Server:       source.Server,
+			Protocol:     source.Protocol,
+			Certificate:  source.Certificate,
+			Secret:       source.Secret,
+			Alias:        hash,
+			SetCached:    true,
+			Type:         imgType.String(),
+			AutoUpdate:   autoUpdate,
+			PreferCached: true,
+			ProjectName:  projectName,
+			Budget:       budget,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		_, baseImage, err = d.cluster.GetImage(projectName, hash, false)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed loading base image")
+		}
+	}
+
+	architecture, err := osarch.ArchitectureId(baseImage.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the build instance.
+	suffix, err := shared.RandomCryptoString()
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{}
+	for k, v := range recipe.Config {
+		config[k] = v
+	}
+
+	profiles := recipe.Profiles
+	if profiles == nil {
+		profiles = []string{"default"}
+	}
+
+	// The build instance is subject to the same project restrictions and limits as any other instance.
+	err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		return project.AllowInstanceCreation(tx, projectName, api.InstancesPost{
+			InstancePut: api.InstancePut{
+				Config:    config,
+				Devices:   recipe.Devices,
+				Ephemeral: true,
+				Profiles:  profiles,
+			},
+			Source: api.InstanceSource{
+				Type:        "image",
+				Fingerprint: baseImage.Fingerprint,
+			},
+			Type: api.InstanceType(imgType.String()),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	args := db.InstanceArgs{
+		Project:      projectName,
+		Name:         fmt.Sprintf("lxd-build-%s", suffix[:12]),
+		Type:         imgType,
+		Architecture: architecture,
+		Description:  "Temporary image build instance",
+		Config:       config,
+		Devices:      deviceConfig.NewDevices(recipe.Devices),
+		Ephemeral:    true,
+		Profiles:     profiles,
+	}
+
+	b.step("Creating build instance %q", args.Name)
+
+	b.inst, err = instanceCreateFromImage(d, r, args, baseImage.Fingerprint, op)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed creating build instance")
+	}
+
+	defer func() {
+		if b.inst.IsRunning() {
+			b.inst.Stop(false)
+		}
+
+		// Ephemeral instances are deleted when stopped, so check whether it's still around.
+		inst, err := instance.LoadByProjectAndName(d.State(), projectName, args.Name)
+		if err == nil {
+			err = inst.Delete(true)
+			if err != nil {
+				logger.Warn("Failed deleting image build instance", log.Ctx{"project": projectName, "instance": args.Name, "err": err})
+			}
+		}
+	}()
+
+	b.step("Starting build instance")
+
+	err = b.inst.Start(false)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed starting build instance")
+	}
+
+	err = b.waitReady()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range recipe.Files {
+		b.step("Pushing file %q (%d/%d)", file.Path, i+1, len(recipe.Files))
+
+		err = b.pushFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed pushing file %q", file.Path)
+		}
+	}
+
+	if len(recipe.Packages) > 0 {
+		b.step("Installing packages")
+
+		err = b.run(append([]string{"/bin/sh", "-c", imageBuildPackagesScript, "sh"}, recipe.Packages...)...)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed installing packages")
+		}
+	}
+
+	for i, command := range recipe.Commands {
+		b.step("Running command (%d/%d)", i+1, len(recipe.Commands))
+
+		err = b.run("/bin/sh", "-c", command)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, command := range recipe.Cleanup {
+		b.step("Running cleanup command (%d/%d)", i+1, len(recipe.Cleanup))
+
+		err = b.run("/bin/sh", "-c", command)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the instance around once stopped so it can be published.
+	err = b.inst.Update(db.InstanceArgs{
+		Architecture: b.inst.Architecture(),
+		Config:       b.inst.LocalConfig(),
+		Description:  b.inst.Description(),
+		Devices:      b.inst.LocalDevices(),
+		Ephemeral:    false,
+		Profiles:     b.inst.Profiles(),
+		Project:      b.inst.Project(),
+	}, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed updating build instance")
+	}
+
+	b.step("Stopping build instance")
+
+	err = b.inst.Shutdown(time.Minute)
+	if err != nil {
+		err = b.inst.Stop(false)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed stopping build instance")
+		}
+	}
+
+	if len(recipe.Templates) > 0 {
+		b.step("Adding templates")
+
+		err = b.addTemplates(d.State(), recipe.Templates)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed adding templates")
+		}
+	}
+
+	b.step("Publishing image")
+
+	publishReq := req
+	publishReq.Source = &api.ImagesPostSource{
+		Type: "instance",
+		Name: args.Name,
+	}
+
+	imagePublishLock.Lock()
+	info, err := imgPostInstanceInfo(d, r, publishReq, op, builddir, budget)
+	imagePublishLock.Unlock()
+	if err != nil {
+		return info, err
+	}
+
+	b.step("Image built")
+
+	return info, nil
+}
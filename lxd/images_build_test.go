@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestImageBuildValidate(t *testing.T) {
+	valid := &api.ImageBuild{
+		Files:     []api.ImageBuildFile{{Path: "/etc/motd", Content: "Hello"}},
+		Templates: []api.ImageBuildTemplate{{Path: "/etc/hostname", When: []string{"create", "start"}}},
+		Timeout:   600,
+	}
+
+	assert.NoError(t, imageBuildValidate(valid))
+	assert.NoError(t, imageBuildValidate(&api.ImageBuild{}))
+
+	tests := []struct {
+		build *api.ImageBuild
+		err   string
+	}{
+		{nil, "No build recipe provided"},
+		{&api.ImageBuild{Timeout: -1}, "Invalid build timeout -1"},
+		{&api.ImageBuild{Files: []api.ImageBuildFile{{Path: "etc/motd"}}}, `Invalid file path "etc/motd", must be an absolute file path`},
+		{&api.ImageBuild{Files: []api.ImageBuildFile{{Path: "/"}}}, `Invalid file path "/", must be an absolute file path`},
+		{&api.ImageBuild{Templates: []api.ImageBuildTemplate{{Path: "hostname"}}}, `Invalid template path "hostname", must be an absolute file path`},
+		{&api.ImageBuild{Templates: []api.ImageBuildTemplate{{Path: "/etc/hostname", When: []string{"stop"}}}}, `Invalid template trigger "stop" for "/etc/hostname", must be one of create, copy or start`},
+		{&api.ImageBuild{Templates: []api.ImageBuildTemplate{{Path: "/etc/hosts"}, {Path: "/etc/hosts"}}}, `Duplicate template path "/etc/hosts"`},
+	}
+
+	for _, test := range tests {
+		assert.EqualError(t, imageBuildValidate(test.build), test.err)
+	}
+}
+
+func TestImageBuildTemplateName(t *testing.T) {
+	used := map[string]bool{}
+
+	name := imageBuildTemplateName("/etc/a-b", used)
+	assert.Equal(t, "etc-a-b.tpl", name)
+	used[name] = true
+
+	// Paths mapping to the same name get a unique one.
+	name = imageBuildTemplateName("/etc/a/b", used)
+	assert.Equal(t, "etc-a-b-1.tpl", name)
+	used[name] = true
+
+	assert.Equal(t, "etc-a-b-2.tpl", imageBuildTemplateName("/etc/a/b/", used))
+	assert.Equal(t, "etc-hostname.tpl", imageBuildTemplateName("/etc/hostname", used))
+}
+
+func TestImageBuildAppendLog(t *testing.T) {
+	b := &imageBuild{}
+
+	b.appendLog([]byte("==> Installing packages\n"))
+	assert.Equal(t, "==> Installing packages\n", b.log.String())
+
+	// Only the end of the log is kept past the maximum size, starting on a new line.
+	line := strings.Repeat("x", 99) + "\n"
+	b.appendLog([]byte(strings.Repeat(line, 2*imageBuildLogMaxSize/len(line))))
+
+	assert.True(t, b.log.Len() <= imageBuildLogMaxSize+len("[...]\n"))
+	assert.True(t, strings.HasPrefix(b.log.String(), "[...]\n"+line))
+	assert.True(t, strings.HasSuffix(b.log.String(), line))
+	assert.NotContains(t, b.log.String(), "Installing packages")
+}
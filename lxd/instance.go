@@ -12,6 +12,7 @@ import (
 
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/operations"
@@ -20,6 +21,7 @@ import (
 	"github.com/lxc/lxd/lxd/state"
 	storagePools "github.com/lxc/lxd/lxd/storage"
 	"github.com/lxc/lxd/lxd/task"
+	"github.com/lxc/lxd/lxd/warnings"
 	"github.com/lxc/lxd/shared"
 	log "github.com/lxc/lxd/shared/log15"
 	"github.com/lxc/lxd/shared/logger"
@@ -509,6 +511,16 @@ func autoCreateContainerSnapshots(ctx context.Context, d *Daemon, instances []in
 			err = c.Snapshot(snapshotName, expiry, false)
 			if err != nil {
 				logger.Error("Error creating snapshots", log.Ctx{"err": err, "container": c})
+
+				err = d.cluster.UpsertWarningLocalNode(c.Project(), dbCluster.TypeInstance, c.ID(), db.WarningSnapshotScheduleFailure, err.Error())
+				if err != nil {
+					logger.Warn("Failed to create warning", log.Ctx{"err": err})
+				}
+			} else {
+				err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.cluster, c.Project(), db.WarningSnapshotScheduleFailure, dbCluster.TypeInstance, c.ID())
+				if err != nil {
+					logger.Warn("Failed to resolve warning", log.Ctx{"err": err})
+				}
 			}
 
 			ch <- nil
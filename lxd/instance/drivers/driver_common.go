@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,8 +18,10 @@ import (
 	deviceConfig "github.com/lxc/lxd/lxd/device/config"
 	"github.com/lxc/lxd/lxd/device/nictype"
 	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/healthcheck"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/instance/operationlock"
+	"github.com/lxc/lxd/lxd/lifecycle"
 	"github.com/lxc/lxd/lxd/maas"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/project"
@@ -896,3 +899,150 @@ func (d *common) warningsDelete() error {
 
 	return nil
 }
+
+// restartPolicyResetInterval is how long an instance must have been running for its restart attempts counter
+// to be reset when it stops again.
+const restartPolicyResetInterval = 10 * time.Minute
+
+// restartPolicyMaxDelay caps the exponential backoff between restart attempts.
+const restartPolicyMaxDelay = 5 * time.Minute
+
+// restartPolicyDelay returns the time to wait before the given restart attempt, doubling the configured
+// boot.restart_delay (default 1s) for each previous attempt.
+func restartPolicyDelay(config map[string]string, attempt int) time.Duration {
+	delay := time.Second
+	if config["boot.restart_delay"] != "" {
+		seconds, err := strconv.Atoi(config["boot.restart_delay"])
+		if err == nil {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+
+	for i := 1; i < attempt; i++ {
+		delay = delay * 2
+		if delay >= restartPolicyMaxDelay {
+			return restartPolicyMaxDelay
+		}
+	}
+
+	return delay
+}
+
+// restartPolicyApplies returns whether the boot.restart_policy setting requires an instance to be restarted.
+// The failure argument indicates whether the instance stopped because of a failure rather than a clean
+// shutdown from inside the instance.
+func restartPolicyApplies(config map[string]string, failure bool) bool {
+	switch config["boot.restart_policy"] {
+	case "always":
+		return true
+	case "on-failure":
+		return failure
+	}
+
+	return false
+}
+
+// restartPolicyHandle restarts an instance that stopped without LXD having requested it, according to its
+// boot.restart_policy setting. Restarts are retried with exponential backoff until boot.restart_max_attempts
+// (default 3, 0 for unlimited) is reached, at which point a warning is raised and the instance is left stopped.
+// The number of attempts is tracked in the volatile.restart.attempts key.
+func (d *common) restartPolicyHandle(inst instance.Instance, failure bool) {
+	if d.ephemeral || !restartPolicyApplies(d.expandedConfig, failure) {
+		return
+	}
+
+	attempts, _ := strconv.Atoi(d.localConfig["volatile.restart.attempts"])
+
+	// Reset the counter if the instance had been running fine for a while before it stopped.
+	if !d.lastUsedDate.IsZero() && time.Since(d.lastUsedDate) > restartPolicyResetInterval {
+		attempts = 0
+	}
+
+	maxAttempts := 3
+	if d.expandedConfig["boot.restart_max_attempts"] != "" {
+		maxAttempts, _ = strconv.Atoi(d.expandedConfig["boot.restart_max_attempts"])
+	}
+
+	// Create local variables from instance properties we need so as not to keep references to instance around
+	// in the go routine below.
+	s := d.state
+	projectName := d.project
+	instanceName := d.name
+	instanceID := d.id
+	logger := d.logger
+	policy := d.expandedConfig["boot.restart_policy"]
+
+	exhausted := func() bool {
+		if maxAttempts == 0 || attempts < maxAttempts {
+			return false
+		}
+
+		logger.Warn("Giving up restarting instance", log.Ctx{"policy": policy, "attempts": attempts})
+
+		err := s.Cluster.UpsertWarningLocalNode(projectName, dbCluster.TypeInstance, instanceID, db.WarningInstanceRestartExhausted, fmt.Sprintf("Instance failed to stay running after %d restart attempts", attempts))
+		if err != nil {
+			logger.Warn("Failed to create instance restart exhausted warning", log.Ctx{"err": err})
+		}
+
+		return true
+	}
+
+	if exhausted() {
+		return
+	}
+
+	go func() {
+		for {
+			attempts++
+
+			err := inst.VolatileSet(map[string]string{"volatile.restart.attempts": strconv.Itoa(attempts)})
+			if err != nil {
+				logger.Error("Failed to record instance restart attempt", log.Ctx{"err": err})
+				return
+			}
+
+			delay := restartPolicyDelay(inst.ExpandedConfig(), attempts)
+			logger.Info("Restarting instance", log.Ctx{"policy": policy, "attempt": attempts, "delay": delay})
+			time.Sleep(delay)
+
+			// Wait for the stop operation (or any other ongoing operation) to finish.
+			op := operationlock.Get(instanceID)
+			if op != nil {
+				op.Wait()
+			}
+
+			// Reload the instance to pick up any change made while waiting.
+			inst, err = instance.LoadByProjectAndName(s, projectName, instanceName)
+			if err != nil {
+				logger.Error("Failed to load instance for restart", log.Ctx{"err": err})
+				return
+			}
+
+			// Skip if the instance got started by someone else or the policy was changed in the meantime.
+			if inst.IsRunning() || !restartPolicyApplies(inst.ExpandedConfig(), failure) {
+				return
+			}
+
+			err = inst.Start(false)
+			if err == nil {
+				s.Events.SendLifecycle(projectName, lifecycle.InstanceRestarted.Event(inst, log.Ctx{"policy": policy, "attempt": attempts}))
+				return
+			}
+
+			logger.Warn("Failed restarting instance", log.Ctx{"attempt": attempts, "err": err})
+
+			if exhausted() {
+				return
+			}
+		}
+	}()
+}
+
+// healthState returns the health check state of a running instance, or nil if no health check is configured.
+func (d *common) healthState() *api.InstanceStateHealth {
+	if d.expandedConfig["healthcheck.command"] == "" {
+		return nil
+	}
+
+	return healthcheck.Get(d.id)
+}
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
@@ -33,6 +34,7 @@ import (
 	deviceConfig "github.com/lxc/lxd/lxd/device/config"
 	"github.com/lxc/lxd/lxd/device/nictype"
 	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/healthcheck"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/instance/operationlock"
 	"github.com/lxc/lxd/lxd/lifecycle"
@@ -702,7 +704,9 @@ func (d *lxc) initLXC(config bool) error {
 	logLevel := "warn"
 	if daemon.Debug {
 		logLevel = "trace"
-	} else if daemon.Verbose {
+	} else if daemon.Verbose || d.expandedConfig["boot.restart_policy"] == "on-failure" {
+		// The exit status of the container's init process is logged at info level and is needed to tell
+		// failures apart from clean shutdowns when applying the restart policy.
 		logLevel = "info"
 	}
 
@@ -2945,6 +2949,9 @@ func (d *lxc) onStop(args map[string]string) error {
 			d.state.Events.SendLifecycle(d.project, lifecycle.InstanceShutdown.Event(d, nil))
 		}
 
+		// Forget the health check state.
+		healthcheck.Reset(d.id)
+
 		// Reboot the container
 		if target == "reboot" {
 			// Start the container again
@@ -2961,6 +2968,11 @@ func (d *lxc) onStop(args map[string]string) error {
 		// Trigger a rebalance
 		cgroup.TaskSchedulerTrigger("container", d.name, "stopped")
 
+		// Restart the container if required by its restart policy.
+		if instanceInitiated {
+			d.restartPolicyHandle(d, d.initExitFailed())
+		}
+
 		// Destroy ephemeral containers
 		if d.ephemeral {
 			err = d.Delete(true)
@@ -2974,6 +2986,33 @@ func (d *lxc) onStop(args map[string]string) error {
 	return nil
 }
 
+// lxcInitExitRegex matches the line LXC logs when the container's init process exits with a non-zero status
+// or is killed by a signal, e.g. "Child <1234> ended on error (1)" or "Child <1234> ended on signal SIGKILL(9)".
+var lxcInitExitRegex = regexp.MustCompile(`ended on (error|signal)[^(]*\((\d+)\)`)
+
+// initExitFailed returns whether the container's init process exited because of a failure rather than a clean
+// shutdown from inside the container. An in-guest poweroff or halt makes the kernel kill init with SIGINT, and
+// an init process exiting with status 0 isn't logged at all, neither of which is considered a failure.
+func (d *lxc) initExitFailed() bool {
+	logContent, err := ioutil.ReadFile(d.LogFilePath())
+	if err != nil {
+		// Without the log the exit reason is unknown, so consider it a failure.
+		return true
+	}
+
+	failed := false
+	for _, line := range strings.Split(string(logContent), "\n") {
+		fields := lxcInitExitRegex.FindStringSubmatch(line)
+		if fields == nil {
+			continue
+		}
+
+		failed = fields[1] == "error" || fields[2] != strconv.Itoa(int(unix.SIGINT))
+	}
+
+	return failed
+}
+
 // cleanupDevices performs any needed device cleanup steps when container is stopped.
 // Accepts a stopHookNetnsPath argument which is required when run from the onStopNS hook before the
 // container's network namespace is unmounted (which is required for NIC device cleanup).
@@ -3267,10 +3306,16 @@ func (d *lxc) renderState(statusCode api.StatusCode) (*api.InstanceState, error)
 		status.Network = d.networkState()
 		status.Pid = int64(pid)
 		status.Processes = d.processesState()
+		status.Health = d.healthState()
+		status.Pressure = d.pressureState()
 	}
 
 	status.Disk = d.diskState()
 
+	if d.isRunningStatusCode(statusCode) {
+		d.ioState(status.Disk)
+	}
+
 	return &status, nil
 }
 
@@ -5800,6 +5845,104 @@ func (d *lxc) diskState() map[string]api.InstanceStateDisk {
 	return disk
 }
 
+// ioState adds the I/O counters of the instance to the disk devices backed by the block devices they were
+// recorded on. A block device is only accounted to the first disk device it backs to avoid counting it twice.
+func (d *lxc) ioState(disk map[string]api.InstanceStateDisk) {
+	cg, err := d.cgroup(nil)
+	if err != nil {
+		return
+	}
+
+	if !d.state.OS.CGInfo.Supports(cgroup.Blkio, cg) {
+		return
+	}
+
+	stats, err := cg.GetIOStats()
+	if err != nil {
+		d.logger.Debug("Error getting I/O statistics", log.Ctx{"err": err})
+		return
+	}
+
+	accounted := map[string]bool{}
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] != "disk" {
+			continue
+		}
+
+		var srcPath string
+		if dev.Config["path"] == "/" {
+			srcPath = d.RootfsPath()
+		} else if dev.Config["pool"] != "" {
+			storageProjectName, err := project.StorageVolumeProject(d.state.Cluster, d.Project(), db.StoragePoolVolumeTypeCustom)
+			if err != nil {
+				continue
+			}
+
+			srcPath = storageDrivers.GetVolumeMountPath(dev.Config["pool"], storageDrivers.VolumeTypeCustom, project.StorageVolume(storageProjectName, dev.Config["source"]))
+		} else if strings.HasPrefix(dev.Config["source"], "/") {
+			srcPath = dev.Config["source"]
+		} else {
+			continue
+		}
+
+		blocks, err := device.DiskParentBlocks(srcPath)
+		if err != nil {
+			continue
+		}
+
+		entry, found := disk[dev.Name]
+		for _, block := range blocks {
+			stat, ok := stats[block]
+			if !ok || accounted[block] {
+				continue
+			}
+
+			accounted[block] = true
+			found = true
+			entry.ReadBytes += stat.ReadBytes
+			entry.ReadOps += stat.ReadOps
+			entry.WriteBytes += stat.WriteBytes
+			entry.WriteOps += stat.WriteOps
+		}
+
+		if found {
+			disk[dev.Name] = entry
+		}
+	}
+}
+
+// pressureState returns the pressure stall information of the instance, nil if not supported.
+func (d *lxc) pressureState() *api.InstanceStatePressure {
+	cg, err := d.cgroup(nil)
+	if err != nil {
+		return nil
+	}
+
+	get := func(resource string) *api.InstanceStatePressureResource {
+		pressure, err := cg.GetPressure(resource)
+		if err != nil {
+			return nil
+		}
+
+		return &api.InstanceStatePressureResource{
+			Some: api.InstanceStatePressureStats(pressure.Some),
+			Full: api.InstanceStatePressureStats(pressure.Full),
+		}
+	}
+
+	pressure := api.InstanceStatePressure{
+		CPU:    get("cpu"),
+		Memory: get("memory"),
+		IO:     get("io"),
+	}
+
+	if pressure.CPU == nil && pressure.Memory == nil && pressure.IO == nil {
+		return nil
+	}
+
+	return &pressure
+}
+
 func (d *lxc) memoryState() api.InstanceStateMemory {
 	memory := api.InstanceStateMemory{}
 	cg, err := d.cgroup(nil)
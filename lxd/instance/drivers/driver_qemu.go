@@ -38,6 +38,7 @@ import (
 	"github.com/lxc/lxd/lxd/device/nictype"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/drivers/qmp"
+	"github.com/lxc/lxd/lxd/instance/healthcheck"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/instance/operationlock"
 	"github.com/lxc/lxd/lxd/lifecycle"
@@ -87,6 +88,17 @@ var errQemuAgentOffline = fmt.Errorf("LXD VM agent isn't currently running")
 var vmConsole = map[int]bool{}
 var vmConsoleLock sync.Mutex
 
+// qemuConsoleLogSize is the size of the console log kept for VMs. The log is only trimmed periodically so it can
+// temporarily grow beyond this.
+const qemuConsoleLogSize = 1024 * 1024
+
+// vmConsoleLogLock serializes the trimming, reading and clearing of VM console logs.
+var vmConsoleLogLock sync.Mutex
+
+// vmOnStop tracks the VMs whose onStop hook is running, keyed by instance ID. The channel is closed once done.
+var vmOnStop = map[int]chan struct{}{}
+var vmOnStopLock sync.Mutex
+
 type monitorHook func(m *qmp.Monitor) error
 
 // qemuLoad creates a Qemu instance from the supplied InstanceArgs.
@@ -424,15 +436,29 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]inter
 				}
 			}
 		} else if event == "SHUTDOWN" {
+			entry, ok := data["reason"]
+
+			// The monitor disconnecting without a SHUTDOWN event only means QEMU went away on its own if
+			// LXD isn't currently stopping or starting the instance and the instance wasn't already stopped.
+			if ok && entry == qmp.ShutdownReasonDisconnect {
+				if operationlock.Get(inst.ID()) != nil || inst.LocalConfig()["volatile.last_state.power"] != "RUNNING" {
+					logger.Debug("Ignoring monitor disconnection")
+					return
+				}
+			}
+
 			logger.Debug("Instance stopped")
 
 			target := "stop"
-			entry, ok := data["reason"]
 			if ok && entry == "guest-reset" {
 				target = "reboot"
 			}
 
-			err = inst.(*qemu).onStop(target)
+			// Anything other than a clean shutdown from inside the guest (such as a guest panic or
+			// QEMU exiting unexpectedly) is considered a failure for the restart policy.
+			failure := !ok || entry != "guest-shutdown"
+
+			err = inst.(*qemu).onStop(target, failure)
 			if err != nil {
 				logger.Error("Failed to cleanly stop instance", log.Ctx{"err": err})
 				return
@@ -595,7 +621,30 @@ func (d *qemu) pidWait(timeout time.Duration, op *operationlock.InstanceOperatio
 }
 
 // onStop is run when the instance stops.
-func (d *qemu) onStop(target string) error {
+// The failure argument indicates whether the VM stopped because of a failure rather than a clean shutdown.
+func (d *qemu) onStop(target string, failure bool) error {
+	// Only run the hook once if it's triggered more than once at the same time (such as by a forced stop and
+	// by the monitor event handler), waiting for the running one to finish.
+	vmOnStopLock.Lock()
+	done, running := vmOnStop[d.id]
+	if running {
+		vmOnStopLock.Unlock()
+		d.logger.Debug("Waiting for running onStop hook to finish", log.Ctx{"target": target})
+		<-done
+		return nil
+	}
+
+	done = make(chan struct{})
+	vmOnStop[d.id] = done
+	vmOnStopLock.Unlock()
+
+	defer func() {
+		vmOnStopLock.Lock()
+		delete(vmOnStop, d.id)
+		vmOnStopLock.Unlock()
+		close(done)
+	}()
+
 	d.logger.Debug("onStop hook started", log.Ctx{"target": target})
 	defer d.logger.Debug("onStop hook finished", log.Ctx{"target": target})
 
@@ -620,6 +669,7 @@ func (d *qemu) onStop(target string) error {
 	op.Reset()
 
 	// Cleanup.
+	healthcheck.Reset(d.id)
 	d.cleanupDevices() // Must be called before unmount.
 	os.Remove(d.pidFilePath())
 	os.Remove(d.monitorPath())
@@ -667,6 +717,12 @@ func (d *qemu) onStop(target string) error {
 	}
 
 	op.Done(nil)
+
+	// Restart the VM if required by its restart policy.
+	if instanceInitiated && target != "reboot" {
+		d.restartPolicyHandle(d, failure)
+	}
+
 	return nil
 }
 
@@ -994,6 +1050,13 @@ func (d *qemu) Start(stateful bool) error {
 		return err
 	}
 
+	// Bound the console log kept from the previous runs, QEMU appends to it.
+	err = d.TrimConsoleLog()
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	err = os.MkdirAll(d.DevicesPath(), 0711)
 	if err != nil {
 		op.Done(err)
@@ -2339,7 +2402,8 @@ func (d *qemu) generateQemuConfigFile(mountInfo *storagePools.MountInfo, busName
 	var monHooks []monitorHook
 
 	err := qemuBase.Execute(sb, map[string]interface{}{
-		"architecture": d.architectureName,
+		"architecture":   d.architectureName,
+		"consoleLogPath": d.ConsoleBufferLogPath(),
 	})
 	if err != nil {
 		return "", nil, err
@@ -3311,7 +3375,7 @@ func (d *qemu) forceStop() error {
 		}
 
 		// Wait for QEMU process to exit and perform device cleanup.
-		err = d.onStop("stop")
+		err = d.onStop("stop", false)
 		if err != nil {
 			return err
 		}
@@ -4966,6 +5030,129 @@ func (d *qemu) vga() (*os.File, chan error, error) {
 	return file, nil, nil
 }
 
+// ConsoleLog returns the most recent output of the instance's serial console.
+func (d *qemu) ConsoleLog() (string, error) {
+	vmConsoleLogLock.Lock()
+	defer vmConsoleLogLock.Unlock()
+
+	f, err := os.Open(d.ConsoleBufferLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		return "", err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	// Only return the content of the ring log in case it wasn't trimmed yet.
+	if fi.Size() > qemuConsoleLogSize {
+		_, err = f.Seek(fi.Size()-qemuConsoleLogSize, io.SeekStart)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	buf, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	d.state.Events.SendLifecycle(d.project, lifecycle.InstanceConsoleRetrieved.Event(d, nil))
+
+	return string(buf), nil
+}
+
+// ClearConsoleLog clears the instance's serial console log.
+func (d *qemu) ClearConsoleLog() error {
+	vmConsoleLogLock.Lock()
+	defer vmConsoleLogLock.Unlock()
+
+	err := os.Truncate(d.ConsoleBufferLogPath(), 0)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	d.state.Events.SendLifecycle(d.project, lifecycle.InstanceConsoleReset.Event(d, nil))
+
+	return nil
+}
+
+// TrimConsoleLog bounds the instance's serial console log to approximately its last qemuConsoleLogSize bytes.
+// QEMU opens the log in append mode and keeps writing to it while it's trimmed. So where the filesystem supports
+// it, the start of the log is removed in place (in whole filesystem blocks) rather than rewriting the log, as any
+// output written between reading the end of the log and rewriting it would otherwise be lost.
+func (d *qemu) TrimConsoleLog() error {
+	vmConsoleLogLock.Lock()
+	defer vmConsoleLogLock.Unlock()
+
+	f, err := os.OpenFile(d.ConsoleBufferLogPath(), os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if fi.Size() <= qemuConsoleLogSize {
+		return nil
+	}
+
+	var fs unix.Statfs_t
+	err = unix.Fstatfs(int(f.Fd()), &fs)
+	if err != nil {
+		return err
+	}
+
+	blockSize := int64(fs.Bsize)
+	if blockSize > 0 {
+		length := (fi.Size() - qemuConsoleLogSize) / blockSize * blockSize
+		if length == 0 {
+			return nil // Less than a block over the limit, trim on the next run.
+		}
+
+		err = unix.Fallocate(int(f.Fd()), unix.FALLOC_FL_COLLAPSE_RANGE, 0, length)
+		if err == nil {
+			return nil
+		}
+
+		if err != unix.EOPNOTSUPP && err != unix.EINVAL {
+			return err
+		}
+	}
+
+	// The filesystem can't collapse ranges, keep the most recent part of the log by rewriting it.
+	buf := make([]byte, qemuConsoleLogSize)
+	_, err = f.ReadAt(buf, fi.Size()-qemuConsoleLogSize)
+	if err != nil {
+		return err
+	}
+
+	err = f.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(buf)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Exec a command inside the instance.
 func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (instance.Cmd, error) {
 	revert := revert.New()
@@ -5239,6 +5426,8 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 				}
 			}
 		}
+
+		status.Health = d.healthState()
 	}
 
 	status.Pid = int64(pid)
@@ -5249,6 +5438,14 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		d.logger.Warn("Error getting disk usage", log.Ctx{"err": err})
 	}
 
+	if d.isRunningStatusCode(statusCode) {
+		if status.Disk == nil {
+			status.Disk = map[string]api.InstanceStateDisk{}
+		}
+
+		d.ioState(status.Disk)
+	}
+
 	return status, nil
 }
 
@@ -5280,6 +5477,53 @@ func (d *qemu) diskState() (map[string]api.InstanceStateDisk, error) {
 	return disk, nil
 }
 
+// ioState adds the I/O counters of the VM drives to the state of their disk devices.
+func (d *qemu) ioState(disk map[string]api.InstanceStateDisk) {
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return
+	}
+
+	stats, err := monitor.GetBlockStats()
+	if err != nil {
+		d.logger.Debug("Error getting I/O statistics", log.Ctx{"err": err})
+		return
+	}
+
+	for devName, dev := range d.ExpandedDevices() {
+		if dev["type"] != "disk" {
+			continue
+		}
+
+		// Drives are named after the device with the "lxd_" prefix, see qemuDrive.
+		stat, ok := stats[fmt.Sprintf("lxd_%s", devName)]
+		if !ok {
+			continue
+		}
+
+		entry := disk[devName]
+		entry.ReadBytes = stat.ReadBytes
+		entry.ReadOps = stat.ReadOps
+		entry.WriteBytes = stat.WriteBytes
+		entry.WriteOps = stat.WriteOps
+		disk[devName] = entry
+	}
+}
+
+// AgentReady returns whether the agent inside of the VM is connected.
+func (d *qemu) AgentReady() bool {
+	if !d.IsRunning() {
+		return false
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return false
+	}
+
+	return monitor.AgentReady()
+}
+
 // agentGetState connects to the agent inside of the VM and does
 // an API call to get the current state.
 func (d *qemu) agentGetState() (*api.InstanceState, error) {
@@ -43,6 +43,8 @@ strict = "on"
 # Console
 [chardev "console"]
 backend = "pty"
+logfile = "{{.consoleLogPath}}"
+logappend = "on"
 `))
 
 var qemuMemory = template.Must(template.New("qemuMemory").Parse(`
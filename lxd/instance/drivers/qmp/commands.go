@@ -242,6 +242,41 @@ func (m *Monitor) GetMemoryBalloonSizeBytes() (int64, error) {
 	return resp.Return.Actual, nil
 }
 
+// BlockStats represents the I/O counters of a block device.
+type BlockStats struct {
+	ReadBytes  int64 `json:"rd_bytes"`
+	ReadOps    int64 `json:"rd_operations"`
+	WriteBytes int64 `json:"wr_bytes"`
+	WriteOps   int64 `json:"wr_operations"`
+}
+
+// GetBlockStats returns the I/O counters of the block devices, keyed by drive name.
+func (m *Monitor) GetBlockStats() (map[string]BlockStats, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []struct {
+			Device string     `json:"device"`
+			Stats  BlockStats `json:"stats"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-blockstats", "", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]BlockStats{}
+	for _, entry := range resp.Return {
+		if entry.Device == "" {
+			continue
+		}
+
+		stats[entry.Device] = entry.Stats
+	}
+
+	return stats, nil
+}
+
 // SetMemoryBalloonSizeBytes sets the size of the memory in bytes (which will resize the balloon as needed).
 func (m *Monitor) SetMemoryBalloonSizeBytes(sizeBytes int64) error {
 	return m.run("balloon", fmt.Sprintf("{'value': %d}", sizeBytes), nil)
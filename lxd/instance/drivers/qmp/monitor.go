@@ -19,6 +19,10 @@ var monitorsLock sync.Mutex
 // RingbufSize is the size of the agent serial ringbuffer in bytes
 var RingbufSize = 16
 
+// ShutdownReasonDisconnect is the reason included in the SHUTDOWN event that is generated when QEMU closes
+// the monitor without having sent a SHUTDOWN event itself (for example because it crashed or was killed).
+const ShutdownReasonDisconnect = "lxd-disconnect"
+
 // Monitor represents a QMP monitor.
 type Monitor struct {
 	path string
@@ -69,6 +73,8 @@ func (m *Monitor) start() error {
 		// Initial read from the ringbuffer.
 		go checkBuffer()
 
+		shutdownReceived := false
+
 		for {
 			// Wait for an event, disconnection or timeout.
 			select {
@@ -80,8 +86,18 @@ func (m *Monitor) start() error {
 					go m.eventHandler(e.Event, e.Data)
 				}
 
+				if e.Event == "SHUTDOWN" {
+					shutdownReceived = true
+				}
+
 				// Event channel is closed, lets disconnect.
 				if !more {
+					// QEMU went away without telling us it was shutting down, let the event handler
+					// know so the instance can be cleaned up.
+					if m.eventHandler != nil && !shutdownReceived {
+						go m.eventHandler("SHUTDOWN", map[string]interface{}{"reason": ShutdownReasonDisconnect})
+					}
+
 					m.Disconnect()
 					return
 				}
@@ -0,0 +1,113 @@
+package healthcheck
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Health status values.
+const (
+	StatusStarting  = "starting"
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// maxOutputLength is the maximum number of bytes of health check output that is kept.
+const maxOutputLength = 4096
+
+var instanceHealthLock sync.Mutex
+var instanceHealth = make(map[int]*health)
+
+// health tracks the health check state of a single instance.
+type health struct {
+	state   api.InstanceStateHealth
+	running bool
+}
+
+// Get returns a copy of the current health state of an instance.
+// If no health check has completed yet, the status is reported as starting.
+func Get(instanceID int) *api.InstanceStateHealth {
+	instanceHealthLock.Lock()
+	defer instanceHealthLock.Unlock()
+
+	h := instanceHealth[instanceID]
+	if h == nil {
+		return &api.InstanceStateHealth{Status: StatusStarting}
+	}
+
+	state := h.state
+	return &state
+}
+
+// Begin marks a health check as running for an instance if one is due given the interval.
+// Returns false if a check is already running or the previous one completed less than interval ago.
+func Begin(instanceID int, interval time.Duration) bool {
+	instanceHealthLock.Lock()
+	defer instanceHealthLock.Unlock()
+
+	h := instanceHealth[instanceID]
+	if h == nil {
+		h = &health{state: api.InstanceStateHealth{Status: StatusStarting}}
+		instanceHealth[instanceID] = h
+	}
+
+	if h.running {
+		return false
+	}
+
+	if !h.state.LastCheckAt.IsZero() && time.Since(h.state.LastCheckAt) < interval {
+		return false
+	}
+
+	h.running = true
+
+	return true
+}
+
+// Record stores the result of a health check for an instance that was started with Begin().
+// The instance is considered unhealthy once retries consecutive checks have failed, and becomes healthy
+// again as soon as a check succeeds.
+// Returns the previous status and the new health state. If the state was reset while the check was running
+// the result is discarded.
+func Record(instanceID int, exitCode int, output string, retries int) (string, api.InstanceStateHealth) {
+	instanceHealthLock.Lock()
+	defer instanceHealthLock.Unlock()
+
+	h := instanceHealth[instanceID]
+	if h == nil {
+		return StatusStarting, api.InstanceStateHealth{Status: StatusStarting}
+	}
+
+	if len(output) > maxOutputLength {
+		output = output[:maxOutputLength]
+	}
+
+	oldStatus := h.state.Status
+
+	h.running = false
+	h.state.LastCheckAt = time.Now().UTC()
+	h.state.LastExitCode = exitCode
+	h.state.LastOutput = output
+
+	if exitCode == 0 {
+		h.state.FailingStreak = 0
+		h.state.Status = StatusHealthy
+	} else {
+		h.state.FailingStreak++
+		if h.state.FailingStreak >= retries {
+			h.state.Status = StatusUnhealthy
+		}
+	}
+
+	return oldStatus, h.state
+}
+
+// Reset forgets the health state of an instance (for example when it stops).
+func Reset(instanceID int) {
+	instanceHealthLock.Lock()
+	defer instanceHealthLock.Unlock()
+
+	delete(instanceHealth, instanceID)
+}
@@ -0,0 +1,57 @@
+package healthcheck_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/instance/healthcheck"
+)
+
+// An instance becomes unhealthy only after the configured number of consecutive failures and
+// recovers as soon as a check succeeds.
+func TestRecord_Transitions(t *testing.T) {
+	defer healthcheck.Reset(1)
+
+	assert.Equal(t, healthcheck.StatusStarting, healthcheck.Get(1).Status)
+
+	assert.True(t, healthcheck.Begin(1, 0))
+	oldStatus, state := healthcheck.Record(1, 1, "fail", 2)
+	assert.Equal(t, healthcheck.StatusStarting, oldStatus)
+	assert.Equal(t, healthcheck.StatusStarting, state.Status)
+	assert.Equal(t, 1, state.FailingStreak)
+
+	assert.True(t, healthcheck.Begin(1, 0))
+	_, state = healthcheck.Record(1, 1, "fail", 2)
+	assert.Equal(t, healthcheck.StatusUnhealthy, state.Status)
+	assert.Equal(t, 2, state.FailingStreak)
+
+	assert.True(t, healthcheck.Begin(1, 0))
+	oldStatus, state = healthcheck.Record(1, 0, "ok", 2)
+	assert.Equal(t, healthcheck.StatusUnhealthy, oldStatus)
+	assert.Equal(t, healthcheck.StatusHealthy, state.Status)
+	assert.Equal(t, 0, state.FailingStreak)
+	assert.Equal(t, "ok", healthcheck.Get(1).LastOutput)
+}
+
+// A new check isn't started while one is running or before the interval has elapsed.
+func TestBegin_Interval(t *testing.T) {
+	defer healthcheck.Reset(2)
+
+	assert.True(t, healthcheck.Begin(2, time.Hour))
+	assert.False(t, healthcheck.Begin(2, time.Hour))
+
+	healthcheck.Record(2, 0, "", 3)
+	assert.False(t, healthcheck.Begin(2, time.Hour))
+	assert.True(t, healthcheck.Begin(2, 0))
+}
+
+// Results of checks that were running when the state got reset are discarded.
+func TestRecord_AfterReset(t *testing.T) {
+	assert.True(t, healthcheck.Begin(3, 0))
+	healthcheck.Reset(3)
+
+	healthcheck.Record(3, 1, "", 1)
+	assert.Equal(t, healthcheck.StatusStarting, healthcheck.Get(3).Status)
+}
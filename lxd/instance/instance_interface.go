@@ -167,6 +167,16 @@ type Container interface {
 	IdmappedStorage(path string) idmap.IdmapStorageType
 }
 
+// VM interface is for VM specific functions.
+type VM interface {
+	Instance
+
+	ConsoleLog() (string, error)
+	ClearConsoleLog() error
+	TrimConsoleLog() error
+	AgentReady() bool
+}
+
 // CriuMigrationArgs arguments for CRIU migration.
 type CriuMigrationArgs struct {
 	Cmd          uint
@@ -549,21 +549,33 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
-	if !util.RuntimeLiblxcVersionAtLeast(3, 0, 0) {
-		return response.BadRequest(fmt.Errorf("Querying the console buffer requires liblxc >= 3.0"))
-	}
-
 	inst, err := instance.LoadByProjectAndName(d.State(), projectName, name)
 	if err != nil {
 		return response.SmartError(err)
 	}
 
+	ent := response.FileResponseEntry{}
+
+	// Hand back the contents of the VM's serial console log.
+	if inst.Type() == instancetype.VM {
+		logContents, err := inst.(instance.VM).ConsoleLog()
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		ent.Buffer = []byte(logContents)
+		return response.FileResponse(r, []response.FileResponseEntry{ent}, nil, false)
+	}
+
+	if !util.RuntimeLiblxcVersionAtLeast(3, 0, 0) {
+		return response.BadRequest(fmt.Errorf("Querying the console buffer requires liblxc >= 3.0"))
+	}
+
 	if inst.Type() != instancetype.Container {
 		return response.SmartError(fmt.Errorf("Instance is not container type"))
 	}
 
 	c := inst.(instance.Container)
-	ent := response.FileResponseEntry{}
 	if !c.IsRunning() {
 		// Hand back the contents of the console ringbuffer logfile.
 		consoleBufferLogPath := c.ConsoleBufferLogPath()
@@ -626,10 +638,6 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 //   "500":
 //     $ref: "#/responses/InternalServerError"
 func instanceConsoleLogDelete(d *Daemon, r *http.Request) response.Response {
-	if !util.RuntimeLiblxcVersionAtLeast(3, 0, 0) {
-		return response.BadRequest(fmt.Errorf("Clearing the console buffer requires liblxc >= 3.0"))
-	}
-
 	name := mux.Vars(r)["name"]
 	projectName := projectParam(r)
 
@@ -638,6 +646,14 @@ func instanceConsoleLogDelete(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if inst.Type() == instancetype.VM {
+		return response.SmartError(inst.(instance.VM).ClearConsoleLog())
+	}
+
+	if !util.RuntimeLiblxcVersionAtLeast(3, 0, 0) {
+		return response.BadRequest(fmt.Errorf("Clearing the console buffer requires liblxc >= 3.0"))
+	}
+
 	if inst.Type() != instancetype.Container {
 		return response.SmartError(fmt.Errorf("Instance is not container type"))
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -168,6 +169,17 @@ func (s *execWs) Do(op *operations.Operation) error {
 		stderr = ttys[2]
 	}
 
+	// Record the session into the log directory of the instance if requested.
+	var recorder *execRecorder
+	if shared.IsTrue(s.instance.ExpandedConfig()["security.exec.record"]) {
+		recorder, err = newExecRecorder(filepath.Join(s.instance.LogPath(), fmt.Sprintf("exec_%s.cast", op.ID())), s.req)
+		if err != nil {
+			return err
+		}
+
+		defer recorder.Close()
+	}
+
 	controlExit := make(chan struct{})
 	attachedChildIsDead := make(chan struct{})
 	var wgEOF sync.WaitGroup
@@ -290,6 +302,10 @@ func (s *execWs) Do(op *operations.Operation) error {
 						logger.Debug("Failed to set window size", log.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
 						continue
 					}
+
+					if recorder != nil {
+						recorder.resize(winchWidth, winchHeight)
+					}
 				} else if command.Command == "signal" {
 					err := cmd.Signal(unix.Signal(command.Signal))
 					if err != nil {
@@ -307,7 +323,14 @@ func (s *execWs) Do(op *operations.Operation) error {
 
 			logger.Debug("Started mirroring websocket")
 			defer logger.Debug("Finished mirroring websocket")
-			readDone, writeDone := netutils.WebsocketExecMirror(conn, ptys[0], ptys[0], attachedChildIsDead, int(ptys[0].Fd()))
+			var w io.WriteCloser = ptys[0]
+			var r io.ReadCloser = ptys[0]
+			if recorder != nil {
+				w = execRecordWriter{WriteCloser: ptys[0], recorder: recorder}
+				r = execRecordReader{ReadCloser: ptys[0], recorder: recorder}
+			}
+
+			readDone, writeDone := netutils.WebsocketExecMirror(conn, w, r, attachedChildIsDead, int(ptys[0].Fd()))
 
 			<-readDone
 			<-writeDone
@@ -323,14 +346,24 @@ func (s *execWs) Do(op *operations.Operation) error {
 					conn := s.conns[i]
 					s.connsLock.Unlock()
 
-					<-shared.WebsocketRecvStream(ttys[i], conn)
+					var w io.WriteCloser = ttys[i]
+					if recorder != nil {
+						w = execRecordWriter{WriteCloser: ttys[i], recorder: recorder}
+					}
+
+					<-shared.WebsocketRecvStream(w, conn)
 					ttys[i].Close()
 				} else {
 					s.connsLock.Lock()
 					conn := s.conns[i]
 					s.connsLock.Unlock()
 
-					<-shared.WebsocketSendStream(conn, ptys[i], -1)
+					var r io.ReadCloser = ptys[i]
+					if recorder != nil {
+						r = execRecordReader{ReadCloser: ptys[i], recorder: recorder}
+					}
+
+					<-shared.WebsocketSendStream(conn, r, -1)
 					ptys[i].Close()
 					wgEOF.Done()
 				}
@@ -505,6 +538,50 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		post.Environment = map[string]string{}
 	}
 
+	// Resolve the user from the instance's passwd file.
+	if post.Username != "" || post.Login {
+		user, err := instanceExecLookupUser(inst, post.Username, post.User)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		post.User = user.uid
+		if post.Group == 0 {
+			post.Group = user.gid
+		}
+
+		userEnv := map[string]string{
+			"HOME":    user.home,
+			"USER":    user.name,
+			"LOGNAME": user.name,
+		}
+
+		if post.Login {
+			userEnv["SHELL"] = user.shell
+
+			if post.Cwd == "" {
+				post.Cwd = user.home
+			}
+
+			if len(post.Command) == 0 {
+				post.Command = []string{user.shell, "-l"}
+			} else {
+				post.Command = []string{user.shell, "-l", "-c", instanceExecShellJoin(post.Command)}
+			}
+		}
+
+		for k, v := range userEnv {
+			_, ok := post.Environment[k]
+			if !ok && v != "" {
+				post.Environment[k] = v
+			}
+		}
+	}
+
+	if len(post.Command) == 0 {
+		return response.BadRequest(fmt.Errorf("No command specified"))
+	}
+
 	// Override any environment variable settings from the instance if not manually specified in post.
 	for k, v := range inst.ExpandedConfig() {
 		if strings.HasPrefix(k, "environment.") {
@@ -604,49 +681,65 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 	run := func(op *operations.Operation) error {
 		metadata := shared.Jmap{}
 
+		var err error
+		var stdout, stderr *os.File
 		if post.RecordOutput {
 			// Prepare stdout and stderr recording
-			stdout, err := os.OpenFile(filepath.Join(inst.LogPath(), fmt.Sprintf("exec_%s.stdout", op.ID())), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+			stdout, err = os.OpenFile(filepath.Join(inst.LogPath(), fmt.Sprintf("exec_%s.stdout", op.ID())), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 			if err != nil {
 				return err
 			}
 			defer stdout.Close()
 
-			stderr, err := os.OpenFile(filepath.Join(inst.LogPath(), fmt.Sprintf("exec_%s.stderr", op.ID())), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+			stderr, err = os.OpenFile(filepath.Join(inst.LogPath(), fmt.Sprintf("exec_%s.stderr", op.ID())), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 			if err != nil {
 				return err
 			}
 			defer stderr.Close()
+		}
 
-			// Run the command
-			cmd, err := inst.Exec(post, nil, stdout, stderr)
+		// Record the output of the session if enabled, on top of the optional stdout and stderr recording.
+		cmdStdout, cmdStderr := stdout, stderr
+		if shared.IsTrue(inst.ExpandedConfig()["security.exec.record"]) {
+			recorder, err := newExecRecorder(filepath.Join(inst.LogPath(), fmt.Sprintf("exec_%s.cast", op.ID())), post)
 			if err != nil {
 				return err
 			}
+			defer recorder.Close()
 
-			exitCode, err := cmd.Wait()
+			var finishStdout, finishStderr func()
+			cmdStdout, finishStdout, err = recorder.pipe(stdout)
 			if err != nil {
 				return err
 			}
+			defer finishStdout()
 
-			// Update metadata with the right URLs
-			metadata["return"] = exitCode
-			metadata["output"] = shared.Jmap{
-				"1": fmt.Sprintf("/%s/instances/%s/logs/%s", version.APIVersion, inst.Name(), filepath.Base(stdout.Name())),
-				"2": fmt.Sprintf("/%s/instances/%s/logs/%s", version.APIVersion, inst.Name(), filepath.Base(stderr.Name())),
-			}
-		} else {
-			cmd, err := inst.Exec(post, nil, nil, nil)
+			cmdStderr, finishStderr, err = recorder.pipe(stderr)
 			if err != nil {
 				return err
 			}
+			defer finishStderr()
+		}
 
-			exitCode, err := cmd.Wait()
-			if err != nil {
-				return err
-			}
+		// Run the command
+		cmd, err := inst.Exec(post, nil, cmdStdout, cmdStderr)
+		if err != nil {
+			return err
+		}
 
-			metadata["return"] = exitCode
+		exitCode, err := cmd.Wait()
+		if err != nil {
+			return err
+		}
+
+		metadata["return"] = exitCode
+
+		if post.RecordOutput {
+			// Update metadata with the right URLs
+			metadata["output"] = shared.Jmap{
+				"1": fmt.Sprintf("/%s/instances/%s/logs/%s", version.APIVersion, inst.Name(), filepath.Base(stdout.Name())),
+				"2": fmt.Sprintf("/%s/instances/%s/logs/%s", version.APIVersion, inst.Name(), filepath.Base(stderr.Name())),
+			}
 		}
 
 		err = op.UpdateMetadata(metadata)
@@ -671,3 +764,118 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 
 	return operations.OperationResponse(op)
 }
+
+// instanceExecDefaultEnvironment returns the environment used for commands run by LXD itself inside an instance,
+// including the instance's environment.* keys.
+func instanceExecDefaultEnvironment(inst instance.Instance) map[string]string {
+	env := map[string]string{
+		"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME": "/root",
+		"USER": "root",
+		"LANG": "C.UTF-8",
+	}
+
+	for k, v := range inst.ExpandedConfig() {
+		if strings.HasPrefix(k, "environment.") {
+			env[strings.TrimPrefix(k, "environment.")] = v
+		}
+	}
+
+	return env
+}
+
+// instanceExecUser represents a user entry from the passwd file of an instance.
+type instanceExecUser struct {
+	name  string
+	uid   uint32
+	gid   uint32
+	home  string
+	shell string
+}
+
+// instanceExecLookupUser finds a user in the passwd file of the instance, by name if provided or by uid otherwise.
+func instanceExecLookupUser(inst instance.Instance, username string, uid uint32) (*instanceExecUser, error) {
+	f, err := ioutil.TempFile("", "lxd_exec_passwd_")
+	if err != nil {
+		return nil, err
+	}
+
+	f.Close()
+	defer os.Remove(f.Name())
+
+	// Pulling the file goes through forkfile for containers and through the agent for VMs.
+	_, _, _, _, _, err = inst.FilePull("/etc/passwd", f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the passwd file of the instance: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return instanceExecParsePasswd(string(content), username, uid)
+}
+
+// instanceExecParsePasswd looks up a user in the content of a passwd file.
+func instanceExecParsePasswd(content string, username string, uid uint32) (*instanceExecUser, error) {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 {
+			continue
+		}
+
+		entryUID, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			continue
+		}
+
+		entryGID, err := strconv.ParseUint(fields[3], 10, 32)
+		if err != nil {
+			continue
+		}
+
+		if username != "" && fields[0] != username {
+			continue
+		}
+
+		if username == "" && uint32(entryUID) != uid {
+			continue
+		}
+
+		user := &instanceExecUser{
+			name:  fields[0],
+			uid:   uint32(entryUID),
+			gid:   uint32(entryGID),
+			home:  fields[5],
+			shell: fields[6],
+		}
+
+		if user.shell == "" {
+			user.shell = "/bin/sh"
+		}
+
+		return user, nil
+	}
+
+	if username != "" {
+		return nil, fmt.Errorf("User %q not found in the instance", username)
+	}
+
+	return nil, fmt.Errorf("User ID %d not found in the instance", uid)
+}
+
+// instanceExecShellJoin quotes the command arguments so they can be passed to a shell.
+func instanceExecShellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, fmt.Sprintf("'%s'", strings.Replace(arg, "'", `'\''`, -1)))
+	}
+
+	return strings.Join(quoted, " ")
+}
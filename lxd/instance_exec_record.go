@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// execRecordMaxSize is the maximum size of a recording, the rest of the session isn't recorded past that.
+const execRecordMaxSize = 32 * 1024 * 1024
+
+// execRecorder writes an exec session to a file in the asciicast v2 format.
+type execRecorder struct {
+	file      *os.File
+	start     time.Time
+	lock      sync.Mutex
+	size      int64
+	truncated bool
+}
+
+// execRecordHeader is the first line of an asciicast v2 file.
+type execRecordHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// newExecRecorder creates the recording file and writes the asciicast header for the exec request.
+func newExecRecorder(path string, req api.InstanceExecPost) (*execRecorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	header := execRecordHeader{
+		Version:   2,
+		Width:     req.Width,
+		Height:    req.Height,
+		Timestamp: time.Now().Unix(),
+		Command:   instanceExecShellJoin(req.Command),
+	}
+
+	if header.Width <= 0 || header.Height <= 0 {
+		header.Width = 80
+		header.Height = 24
+	}
+
+	term, ok := req.Environment["TERM"]
+	if ok {
+		header.Env = map[string]string{"TERM": term}
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	n, err := f.Write(append(headerJSON, '\n'))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &execRecorder{file: f, start: time.Now(), size: int64(n)}, nil
+}
+
+// event records data of the given kind ("o" for output, "i" for input, "r" for resize). Once the recording
+// reaches execRecordMaxSize, a final output event indicates that it was truncated and nothing else is recorded.
+func (r *execRecorder) event(kind string, data []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.truncated {
+		return
+	}
+
+	elapsed := time.Since(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, kind, string(data)})
+	if err != nil {
+		return
+	}
+
+	if r.size+int64(len(line))+1 > execRecordMaxSize {
+		r.truncated = true
+		line, _ = json.Marshal([]interface{}{elapsed, "o", "\r\n[LXD: recording size limit reached, the rest of the session isn't recorded]\r\n"})
+	}
+
+	n, _ := r.file.Write(append(line, '\n'))
+	r.size += int64(n)
+}
+
+// resize records a change of the terminal size.
+func (r *execRecorder) resize(width int, height int) {
+	r.event("r", []byte(fmt.Sprintf("%dx%d", width, height)))
+}
+
+// pipe returns the write end of a pipe to use as the output of a command. Everything written to it is recorded
+// as output and copied to dst (if set). The returned function must be called once the command exited, it waits
+// for the output to be fully recorded.
+func (r *execRecorder) pipe(dst *os.File) (*os.File, func(), error) {
+	read, write, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var w io.Writer = execRecordOutput{recorder: r}
+	if dst != nil {
+		w = io.MultiWriter(dst, w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer read.Close()
+
+		io.Copy(w, read)
+	}()
+
+	finish := func() {
+		write.Close()
+		<-done
+	}
+
+	return write, finish, nil
+}
+
+// Close closes the recording file.
+func (r *execRecorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.file.Close()
+}
+
+// execRecordOutput records everything written to it as output.
+type execRecordOutput struct {
+	recorder *execRecorder
+}
+
+func (w execRecordOutput) Write(p []byte) (int, error) {
+	w.recorder.event("o", p)
+
+	return len(p), nil
+}
+
+// execRecordReader records everything read from the wrapped reader as output.
+type execRecordReader struct {
+	io.ReadCloser
+	recorder *execRecorder
+}
+
+func (r execRecordReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.recorder.event("o", p[:n])
+	}
+
+	return n, err
+}
+
+// execRecordWriter records everything written to the wrapped writer as input.
+type execRecordWriter struct {
+	io.WriteCloser
+	recorder *execRecorder
+}
+
+func (w execRecordWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	if n > 0 {
+		w.recorder.event("i", p[:n])
+	}
+
+	return n, err
+}
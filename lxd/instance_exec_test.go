@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+const testPasswd = `root:x:0:0:root:/root:/bin/bash
+# A comment
+daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin
+ubuntu:x:1000:1000:Ubuntu:/home/ubuntu:
+invalid:x:abc:1000::/home/invalid:/bin/sh
+`
+
+func TestInstanceExecParsePasswd(t *testing.T) {
+	user, err := instanceExecParsePasswd(testPasswd, "ubuntu", 0)
+	require.NoError(t, err)
+	assert.Equal(t, &instanceExecUser{name: "ubuntu", uid: 1000, gid: 1000, home: "/home/ubuntu", shell: "/bin/sh"}, user)
+
+	user, err = instanceExecParsePasswd(testPasswd, "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "daemon", user.name)
+	assert.Equal(t, "/usr/sbin", user.home)
+
+	_, err = instanceExecParsePasswd(testPasswd, "invalid", 0)
+	assert.EqualError(t, err, `User "invalid" not found in the instance`)
+
+	_, err = instanceExecParsePasswd(testPasswd, "", 42)
+	assert.EqualError(t, err, "User ID 42 not found in the instance")
+}
+
+func TestInstanceExecShellJoin(t *testing.T) {
+	assert.Equal(t, `'echo' 'it'\''s' '$HOME'`, instanceExecShellJoin([]string{"echo", "it's", "$HOME"}))
+}
+
+func TestExecRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lxd-exec-record-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "exec.cast")
+	recorder, err := newExecRecorder(path, api.InstanceExecPost{Command: []string{"echo", "hello"}})
+	require.NoError(t, err)
+
+	// Output written to the pipe is both recorded and copied to the destination.
+	stdout, err := os.Create(filepath.Join(dir, "exec.stdout"))
+	require.NoError(t, err)
+	defer stdout.Close()
+
+	w, finish, err := recorder.pipe(stdout)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	finish()
+
+	content, err := ioutil.ReadFile(stdout.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+
+	// Nothing is recorded past the size limit.
+	recorder.event("o", make([]byte, execRecordMaxSize))
+	recorder.event("o", []byte("ignored"))
+	require.NoError(t, recorder.Close())
+
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"command":"'echo' 'hello'"`)
+	assert.Contains(t, lines[1], `"o","hello\n"`)
+	assert.Contains(t, lines[2], "recording size limit reached")
+}
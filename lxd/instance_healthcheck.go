@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/healthcheck"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/task"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/logging"
+)
+
+// Default health check settings.
+const (
+	healthCheckDefaultInterval = 30
+	healthCheckDefaultTimeout  = 10
+	healthCheckDefaultRetries  = 3
+)
+
+// instanceHealthCheckTask runs the health checks of the running local instances which have a
+// healthcheck.command configured, each according to its own healthcheck.interval.
+func instanceHealthCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		insts, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Error("Failed loading instances for health checks", log.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range insts {
+			config := inst.ExpandedConfig()
+			if config["healthcheck.command"] == "" {
+				continue
+			}
+
+			if !inst.IsRunning() || inst.IsFrozen() {
+				continue
+			}
+
+			interval := healthCheckSetting(config, "healthcheck.interval", healthCheckDefaultInterval)
+			if !healthcheck.Begin(inst.ID(), time.Duration(interval)*time.Second) {
+				continue
+			}
+
+			go instanceHealthCheck(s, inst)
+		}
+	}
+
+	return f, task.Every(5 * time.Second)
+}
+
+// healthCheckSetting returns the integer value of a health check config key or the default if unset.
+func healthCheckSetting(config map[string]string, key string, defaultValue int) int {
+	value, err := strconv.Atoi(config[key])
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// instanceHealthCheck runs the health check command of an instance, records the result and sends a lifecycle
+// event if the health status changed.
+func instanceHealthCheck(s *state.State, inst instance.Instance) {
+	config := inst.ExpandedConfig()
+	timeout := healthCheckSetting(config, "healthcheck.timeout", healthCheckDefaultTimeout)
+	retries := healthCheckSetting(config, "healthcheck.retries", healthCheckDefaultRetries)
+
+	instLogger := logging.AddContext(logger.Log, log.Ctx{"project": inst.Project(), "instance": inst.Name()})
+
+	exitCode, output, err := instanceHealthCheckRun(inst, config["healthcheck.command"], time.Duration(timeout)*time.Second)
+	if err != nil {
+		instLogger.Debug("Failed running health check", log.Ctx{"err": err})
+		exitCode = -1
+		output = err.Error()
+	}
+
+	oldStatus, health := healthcheck.Record(inst.ID(), exitCode, output, retries)
+	if health.Status == oldStatus {
+		return
+	}
+
+	instLogger.Info("Instance health changed", log.Ctx{"old": oldStatus, "new": health.Status})
+	s.Events.SendLifecycle(inst.Project(), lifecycle.InstanceHealthChanged.Event(inst, log.Ctx{"status": health.Status, "old_status": oldStatus, "failing_streak": health.FailingStreak}))
+}
+
+// instanceHealthCheckRun executes the health check command inside the instance through the regular exec path
+// (lxd-agent for virtual machines) and returns its exit code and combined output.
+func instanceHealthCheckRun(inst instance.Instance, command string, timeout time.Duration) (int, string, error) {
+	req := api.InstanceExecPost{
+		Command:     []string{"/bin/sh", "-c", command},
+		Environment: instanceExecDefaultEnvironment(inst),
+		Cwd:         "/",
+	}
+
+	outFile, err := ioutil.TempFile("", "lxd_healthcheck_")
+	if err != nil {
+		return -1, "", err
+	}
+	defer os.Remove(outFile.Name())
+	defer outFile.Close()
+
+	cmd, err := inst.Exec(req, nil, outFile, outFile)
+	if err != nil {
+		return -1, "", err
+	}
+
+	type result struct {
+		exitCode int
+		err      error
+	}
+
+	chResult := make(chan result, 1)
+	go func() {
+		exitCode, err := cmd.Wait()
+		chResult <- result{exitCode: exitCode, err: err}
+	}()
+
+	var res result
+
+	select {
+	case res = <-chResult:
+	case <-time.After(timeout):
+		// Kill the command but don't wait indefinitely for it to go away (the agent may be unresponsive).
+		cmd.Signal(unix.SIGKILL)
+		select {
+		case <-chResult:
+		case <-time.After(timeout):
+		}
+
+		return -1, fmt.Sprintf("Health check timed out after %v", timeout), nil
+	}
+
+	if res.err != nil {
+		return -1, "", res.err
+	}
+
+	output, err := ioutil.ReadFile(outFile.Name())
+	if err != nil {
+		return -1, "", err
+	}
+
+	return res.exitCode, strings.TrimSpace(string(output)), nil
+}
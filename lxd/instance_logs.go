@@ -12,6 +12,7 @@ import (
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/lifecycle"
 	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/rbac"
 	"github.com/lxc/lxd/lxd/request"
 	"github.com/lxc/lxd/lxd/response"
 	"github.com/lxc/lxd/shared"
@@ -135,6 +136,11 @@ func instanceLogsGet(d *Daemon, r *http.Request) response.Response {
 			continue
 		}
 
+		// Exec recordings may contain sensitive input, only list them for admins.
+		if execRecordingFileName(f.Name()) && !rbac.UserIsAdmin(r) {
+			continue
+		}
+
 		result = append(result, fmt.Sprintf("/%s/instances/%s/logs/%s", version.APIVersion, name, f.Name()))
 	}
 
@@ -153,6 +159,11 @@ func validLogFileName(fname string) bool {
 		strings.HasPrefix(fname, "exec_")
 }
 
+// execRecordingFileName returns whether the log file is an exec session recording (security.exec.record).
+func execRecordingFileName(fname string) bool {
+	return strings.HasPrefix(fname, "exec_") && strings.HasSuffix(fname, ".cast")
+}
+
 // swagger:operation GET /1.0/instances/{name}/logs/{filename} instances instance_log_get
 //
 // Get the log file
@@ -220,6 +231,10 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("log file name %s not valid", file))
 	}
 
+	if execRecordingFileName(file) && !rbac.UserIsAdmin(r) {
+		return response.Forbidden(fmt.Errorf("Exec recordings may only be retrieved by administrators"))
+	}
+
 	ent := response.FileResponseEntry{
 		Path:     shared.LogPath(project.Instance(projectName, name), file),
 		Filename: file,
@@ -295,6 +310,10 @@ func instanceLogDelete(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("lxc.log and lxc.conf may not be deleted"))
 	}
 
+	if execRecordingFileName(file) {
+		return response.BadRequest(fmt.Errorf("Exec recordings may not be deleted"))
+	}
+
 	err = os.Remove(shared.LogPath(project.Instance(projectName, name), file))
 	if err != nil {
 		return response.SmartError(err)
@@ -7,11 +7,15 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/db/cluster"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/warnings"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 )
@@ -166,7 +170,7 @@ func instanceStatePut(d *Daemon, r *http.Request) response.Response {
 	do := func(op *operations.Operation) error {
 		inst.SetOperation(op)
 
-		return doInstanceStatePut(inst, req)
+		return doInstanceStatePut(d.State(), inst, req)
 	}
 
 	resources := map[string][]string{}
@@ -196,7 +200,15 @@ func instanceActionToOptype(action string) (db.OperationType, error) {
 	return db.OperationUnknown, fmt.Errorf("Unknown action: '%s'", action)
 }
 
-func doInstanceStatePut(inst instance.Instance, req api.InstanceStatePut) error {
+func doInstanceStatePut(s *state.State, inst instance.Instance, req api.InstanceStatePut) error {
+	// A user requested start or restart gives the restart policy a fresh set of attempts.
+	if shared.StringInSlice(req.Action, []string{string(shared.Start), string(shared.Restart)}) {
+		err := instanceRestartPolicyReset(s, inst)
+		if err != nil {
+			return err
+		}
+	}
+
 	switch shared.InstanceAction(req.Action) {
 	case shared.Start:
 		return inst.Start(req.Stateful)
@@ -223,3 +235,21 @@ func doInstanceStatePut(inst instance.Instance, req api.InstanceStatePut) error
 
 	return fmt.Errorf("Unknown action: '%s'", req.Action)
 }
+
+// instanceRestartPolicyReset clears the restart policy attempts counter of an instance and resolves any
+// warning raised when the restart attempts got exhausted.
+func instanceRestartPolicyReset(s *state.State, inst instance.Instance) error {
+	if inst.LocalConfig()["volatile.restart.attempts"] != "" {
+		err := inst.VolatileSet(map[string]string{"volatile.restart.attempts": ""})
+		if err != nil {
+			return errors.Wrapf(err, "Failed resetting restart attempts")
+		}
+	}
+
+	err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.Cluster, inst.Project(), db.WarningInstanceRestartExhausted, cluster.TypeInstance, inst.ID())
+	if err != nil {
+		return errors.Wrapf(err, "Failed resolving instance restart warning")
+	}
+
+	return nil
+}
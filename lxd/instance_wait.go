@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// instanceWaitPollInterval is how often the conditions which aren't tied to a lifecycle event get checked.
+const instanceWaitPollInterval = 2 * time.Second
+
+// instanceWaitCloudInitResult is written by cloud-init once it has finished running.
+const instanceWaitCloudInitResult = "/run/cloud-init/result.json"
+
+var instanceWaitCmd = APIEndpoint{
+	Name: "instanceWait",
+	Path: "instances/{name}/wait",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerWait", Path: "containers/{name}/wait"},
+		{Name: "vmWait", Path: "virtual-machines/{name}/wait"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceWaitGet, AccessHandler: allowProjectPermission("containers", "view")},
+}
+
+// swagger:operation GET /1.0/instances/{name}/wait instances instance_wait_get
+//
+// Wait for a condition
+//
+// Waits for the instance to reach the requested condition (or timeout).
+//
+// Supported conditions are:
+//  - status=STATUS (instance status, e.g. running or stopped)
+//  - ipv4 (a global IPv4 address is configured)
+//  - ipv6 (a global IPv6 address is configured)
+//  - agent (the VM agent is connected)
+//  - cloud-init (cloud-init has finished running)
+//  - user.KEY or user.KEY=VALUE (a user config key is set, possibly to a specific value)
+//
+// Containers can set their own user config keys through /dev/lxd, virtual machines can't.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: condition
+//     description: Condition to wait for
+//     type: string
+//     example: status=running
+//   - in: query
+//     name: timeout
+//     description: Timeout in seconds (-1 means never)
+//     type: integer
+//     example: -1
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+//   "504":
+//     description: Timed out waiting for the condition
+func instanceWaitGet(d *Daemon, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name := mux.Vars(r)["name"]
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	condition := r.FormValue("condition")
+	err = instanceWaitValidCondition(condition)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	timeout, err := shared.AtoiEmptyDefault(r.FormValue("timeout"), -1)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(d, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(d.State(), projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if condition == "agent" && inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("The agent condition is only supported for virtual machines"))
+	}
+
+	// Get notified of the lifecycle events of the instance so changes get picked up right away.
+	source := fmt.Sprintf("/1.0/instances/%s", url.PathEscape(name))
+	changed := make(chan struct{}, 1)
+	listener, err := d.State().Events.AddHandler(projectName, []string{"lifecycle"}, func(event api.Event) {
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil || strings.SplitN(lifecycleEvent.Source, "?", 2)[0] != source {
+			return
+		}
+
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	defer d.State().Events.RemoveListener(listener)
+
+	var timer <-chan time.Time
+	if timeout >= 0 {
+		timer = time.After(time.Duration(timeout) * time.Second)
+	}
+
+	ticker := time.NewTicker(instanceWaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Reload the instance so config changes are taken into account.
+		inst, err = instance.LoadByProjectAndName(d.State(), projectName, name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if instanceWaitCheck(inst, condition) {
+			return response.EmptySyncResponse
+		}
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+		case <-timer:
+			return response.ErrorResponse(http.StatusGatewayTimeout, fmt.Sprintf("Timed out waiting for condition %q", condition))
+		case <-r.Context().Done():
+			return response.InternalError(fmt.Errorf("Request cancelled"))
+		}
+	}
+}
+
+// instanceWaitValidCondition checks that the condition is one instanceWaitCheck understands.
+func instanceWaitValidCondition(condition string) error {
+	if shared.StringInSlice(condition, []string{"ipv4", "ipv6", "agent", "cloud-init"}) {
+		return nil
+	}
+
+	if strings.HasPrefix(condition, "status=") && strings.TrimPrefix(condition, "status=") != "" {
+		return nil
+	}
+
+	if strings.HasPrefix(condition, "user.") {
+		return nil
+	}
+
+	return fmt.Errorf("Invalid condition %q", condition)
+}
+
+// instanceWaitCheck returns whether the instance currently satisfies the condition.
+func instanceWaitCheck(inst instance.Instance, condition string) bool {
+	switch {
+	case strings.HasPrefix(condition, "status="):
+		return strings.EqualFold(inst.State(), strings.TrimPrefix(condition, "status="))
+	case strings.HasPrefix(condition, "user."):
+		fields := strings.SplitN(condition, "=", 2)
+		value, ok := inst.ExpandedConfig()[fields[0]]
+		if len(fields) == 1 {
+			return ok
+		}
+
+		return ok && value == fields[1]
+	}
+
+	if !inst.IsRunning() {
+		return false
+	}
+
+	switch condition {
+	case "ipv4", "ipv6":
+		family := "inet"
+		if condition == "ipv6" {
+			family = "inet6"
+		}
+
+		state, err := inst.RenderState()
+		if err != nil {
+			return false
+		}
+
+		for nicName, nic := range state.Network {
+			if nicName == "lo" || nic.Type == "loopback" {
+				continue
+			}
+
+			for _, addr := range nic.Addresses {
+				if addr.Family == family && addr.Scope == "global" {
+					return true
+				}
+			}
+		}
+	case "agent":
+		vm, ok := inst.(instance.VM)
+		if ok {
+			return vm.AgentReady()
+		}
+	case "cloud-init":
+		if inst.Type() == instancetype.Container {
+			return inst.FileExists(instanceWaitCloudInitResult) == nil
+		}
+
+		// VMs don't support checking for a file directly, pull it through the agent instead.
+		f, err := ioutil.TempFile("", "lxd_wait_")
+		if err != nil {
+			return false
+		}
+
+		f.Close()
+		defer os.Remove(f.Name())
+
+		_, _, _, _, _, err = inst.FilePull(instanceWaitCloudInitResult, f.Name())
+		return err == nil
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/instance"
+)
+
+// testWaitInstance only implements the parts of an instance used by the status and user conditions.
+type testWaitInstance struct {
+	instance.Instance
+
+	state  string
+	config map[string]string
+}
+
+func (i *testWaitInstance) State() string                     { return i.state }
+func (i *testWaitInstance) IsRunning() bool                   { return i.state == "RUNNING" }
+func (i *testWaitInstance) ExpandedConfig() map[string]string { return i.config }
+
+func TestInstanceWaitValidCondition(t *testing.T) {
+	for _, condition := range []string{"status=running", "ipv4", "ipv6", "agent", "cloud-init", "user.foo", "user.foo=bar"} {
+		assert.NoError(t, instanceWaitValidCondition(condition), condition)
+	}
+
+	for _, condition := range []string{"", "status=", "status", "ipv5", "limits.cpu", "volatile.foo=bar"} {
+		assert.Error(t, instanceWaitValidCondition(condition), condition)
+	}
+}
+
+func TestInstanceWaitCheck(t *testing.T) {
+	inst := &testWaitInstance{
+		state:  "STOPPED",
+		config: map[string]string{"user.foo": "bar", "user.empty": ""},
+	}
+
+	assert.True(t, instanceWaitCheck(inst, "status=stopped"))
+	assert.True(t, instanceWaitCheck(inst, "status=Stopped"))
+	assert.False(t, instanceWaitCheck(inst, "status=running"))
+
+	assert.True(t, instanceWaitCheck(inst, "user.foo"))
+	assert.True(t, instanceWaitCheck(inst, "user.foo=bar"))
+	assert.False(t, instanceWaitCheck(inst, "user.foo=baz"))
+	assert.True(t, instanceWaitCheck(inst, "user.empty="))
+	assert.False(t, instanceWaitCheck(inst, "user.missing"))
+
+	// The conditions depending on the running instance aren't met while it's stopped.
+	for _, condition := range []string{"ipv4", "ipv6", "agent", "cloud-init"} {
+		assert.False(t, instanceWaitCheck(inst, condition), condition)
+	}
+
+	inst.state = "RUNNING"
+	assert.True(t, instanceWaitCheck(inst, "status=running"))
+
+	// Containers have no agent.
+	assert.False(t, instanceWaitCheck(inst, "agent"))
+}
@@ -286,12 +286,32 @@ func createFromMigration(d *Daemon, r *http.Request, projectName string, req *ap
 		}
 	}
 
+	// Early check for resume.
+	resuming := false
+	if req.Source.Resume {
+		if req.Source.Refresh {
+			return response.BadRequest(fmt.Errorf("Cannot resume and refresh an instance at the same time"))
+		}
+
+		// Check if the instance was left behind by an interrupted transfer.
+		inst, err = instance.LoadByProjectAndName(d.State(), projectName, req.Name)
+		if err == nil {
+			if !shared.IsTrue(inst.LocalConfig()["volatile.migration.resume"]) {
+				return response.Conflict(fmt.Errorf("Instance %q already exists and isn't an interrupted transfer", req.Name))
+			}
+
+			resuming = true
+		} else if err != db.ErrNoSuchObject {
+			return response.SmartError(err)
+		}
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
 	instanceOnly := req.Source.InstanceOnly || req.Source.ContainerOnly
 
-	if !req.Source.Refresh {
+	if !req.Source.Refresh && !resuming {
 		_, err := storagePools.GetPoolByName(d.State(), storagePool)
 		if err != nil {
 			return response.InternalError(err)
@@ -305,6 +325,14 @@ func createFromMigration(d *Daemon, r *http.Request, projectName string, req *ap
 		if err != nil {
 			return response.InternalError(errors.Wrap(err, "Failed creating instance record"))
 		}
+
+		// Mark the instance as a transfer which can be resumed if it gets interrupted.
+		if req.Source.Resume {
+			err = inst.VolatileSet(map[string]string{"volatile.migration.resume": "true"})
+			if err != nil {
+				return response.InternalError(err)
+			}
+		}
 	}
 
 	var cert *x509.Certificate
@@ -341,6 +369,7 @@ func createFromMigration(d *Daemon, r *http.Request, projectName string, req *ap
 		Live:         req.Source.Live,
 		InstanceOnly: instanceOnly,
 		Refresh:      req.Source.Refresh,
+		Resume:       req.Source.Resume,
 	}
 
 	sink, err := newMigrationSink(&migrationArgs)
@@ -357,6 +386,11 @@ func createFromMigration(d *Daemon, r *http.Request, projectName string, req *ap
 		// And finally run the migration.
 		err = sink.Do(d.State(), runRevert, op)
 		if err != nil {
+			// Keep what was received so far so that the transfer can be resumed.
+			if req.Source.Resume {
+				runRevert.Success()
+			}
+
 			return fmt.Errorf("Error transferring instance data: %s", err)
 		}
 
@@ -365,6 +399,13 @@ func createFromMigration(d *Daemon, r *http.Request, projectName string, req *ap
 			return err
 		}
 
+		if req.Source.Resume {
+			err = inst.VolatileSet(map[string]string{"volatile.migration.resume": ""})
+			if err != nil {
+				return err
+			}
+		}
+
 		runRevert.Success()
 		return nil
 	}
@@ -144,7 +144,7 @@ func instancesPut(d *Daemon, r *http.Request) response.Response {
 					defer wgAction.Done()
 
 					inst.SetOperation(op)
-					err := doInstanceStatePut(inst, *req.State)
+					err := doInstanceStatePut(d.State(), inst, *req.State)
 					if err != nil {
 						failuresLock.Lock()
 						failures[inst.Name()] = err
@@ -0,0 +1,11 @@
+package ip
+
+// Wireguard represents arguments for link device of type wireguard
+type Wireguard struct {
+	Link
+}
+
+// Add adds new virtual link
+func (w *Wireguard) Add() error {
+	return w.Link.add("wireguard", nil)
+}
@@ -39,6 +39,7 @@ const (
 	InstanceFileRetrieved    = InstanceAction("file-retrieved")
 	InstanceFilePushed       = InstanceAction("file-pushed")
 	InstanceFileDeleted      = InstanceAction("file-deleted")
+	InstanceHealthChanged    = InstanceAction("health-changed")
 )
 
 // Event creates the lifecycle event for an action on an instance.
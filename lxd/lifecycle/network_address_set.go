@@ -0,0 +1,43 @@
+package lifecycle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Internal copy of the network address set interface.
+type networkAddressSet interface {
+	Info() *api.NetworkAddressSet
+	Project() string
+}
+
+// NetworkAddressSetAction represents a lifecycle event action for network address sets.
+type NetworkAddressSetAction string
+
+// All supported lifecycle events for network address sets.
+const (
+	NetworkAddressSetCreated = NetworkAddressSetAction("created")
+	NetworkAddressSetDeleted = NetworkAddressSetAction("deleted")
+	NetworkAddressSetUpdated = NetworkAddressSetAction("updated")
+	NetworkAddressSetRenamed = NetworkAddressSetAction("renamed")
+)
+
+// Event creates the lifecycle event for an action on a network address set.
+func (a NetworkAddressSetAction) Event(n networkAddressSet, requestor *api.EventLifecycleRequestor, ctx map[string]interface{}) api.EventLifecycle {
+	eventType := fmt.Sprintf("network-address-set-%s", a)
+
+	u := fmt.Sprintf("/1.0/network-address-sets/%s", url.PathEscape(n.Info().Name))
+	if n.Project() != project.Default {
+		u = fmt.Sprintf("%s?project=%s", u, url.QueryEscape(n.Project()))
+	}
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
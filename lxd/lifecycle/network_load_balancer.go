@@ -0,0 +1,36 @@
+package lifecycle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// NetworkLoadBalancerAction represents a lifecycle event action for network load balancers.
+type NetworkLoadBalancerAction string
+
+// All supported lifecycle events for network load balancers.
+const (
+	NetworkLoadBalancerCreated = NetworkLoadBalancerAction("created")
+	NetworkLoadBalancerDeleted = NetworkLoadBalancerAction("deleted")
+	NetworkLoadBalancerUpdated = NetworkLoadBalancerAction("updated")
+)
+
+// Event creates the lifecycle event for an action on a network load balancer.
+func (a NetworkLoadBalancerAction) Event(n network, listenAddress string, requestor *api.EventLifecycleRequestor, ctx map[string]interface{}) api.EventLifecycle {
+	eventType := fmt.Sprintf("network-load-balancer-%s", a)
+
+	u := fmt.Sprintf("/1.0/networks/%s/load-balancers/%s", url.PathEscape(n.Name()), url.PathEscape(listenAddress))
+	if n.Project() != project.Default {
+		u = fmt.Sprintf("%s?project=%s", u, url.QueryEscape(n.Project()))
+	}
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,36 @@
+package lifecycle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// NetworkReservationAction represents a lifecycle event action for network DHCP reservations.
+type NetworkReservationAction string
+
+// All supported lifecycle events for network DHCP reservations.
+const (
+	NetworkReservationCreated = NetworkReservationAction("created")
+	NetworkReservationDeleted = NetworkReservationAction("deleted")
+	NetworkReservationUpdated = NetworkReservationAction("updated")
+)
+
+// Event creates the lifecycle event for an action on a network DHCP reservation.
+func (a NetworkReservationAction) Event(n network, hwaddr string, requestor *api.EventLifecycleRequestor, ctx map[string]interface{}) api.EventLifecycle {
+	eventType := fmt.Sprintf("network-reservation-%s", a)
+
+	u := fmt.Sprintf("/1.0/networks/%s/reservations/%s", url.PathEscape(n.Name()), url.PathEscape(hwaddr))
+	if n.Project() != project.Default {
+		u = fmt.Sprintf("%s?project=%s", u, url.QueryEscape(n.Project()))
+	}
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -45,6 +46,31 @@ func expireLogsTask(state *state.State) (task.Func, task.Schedule) {
 	return f, task.Daily()
 }
 
+// This task function bounds the console logs of the running VMs. It's started by the Daemon and will run
+// once every minute, so the bound is approximate as a VM can write more than that in between runs.
+func trimConsoleLogsTask(state *state.State) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		instances, err := instance.LoadNodeAll(state, instancetype.VM)
+		if err != nil {
+			logger.Error("Failed loading instances for console log trimming", log.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if !inst.IsRunning() {
+				continue
+			}
+
+			err = inst.(instance.VM).TrimConsoleLog()
+			if err != nil {
+				logger.Error("Failed trimming console log", log.Ctx{"project": inst.Project(), "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
 func expireLogs(ctx context.Context, state *state.State) error {
 	// List the instances.
 	instances, err := instance.LoadNodeAll(state, instancetype.Any)
@@ -60,8 +86,20 @@ func expireLogs(ctx context.Context, state *state.State) error {
 
 	// Build the expected names.
 	names := []string{}
+	vmNames := []string{}
+	recordExpiry := map[string]int{}
 	for _, inst := range instances {
 		names = append(names, project.Instance(inst.Project(), inst.Name()))
+
+		if inst.Type() == instancetype.VM {
+			vmNames = append(vmNames, project.Instance(inst.Project(), inst.Name()))
+		}
+
+		// Exec recordings are only expired when a retention is configured.
+		days, err := strconv.Atoi(inst.ExpandedConfig()["security.exec.record.expiry"])
+		if err == nil && days > 0 {
+			recordExpiry[project.Instance(inst.Project(), inst.Name())] = days
+		}
 	}
 
 	newestFile := func(path string, dir os.FileInfo) time.Time {
@@ -117,6 +155,24 @@ func expireLogs(ctx context.Context, state *state.State) error {
 					continue
 				}
 
+				// The console log of VMs is a size-bounded ring log kept for the lifetime of the instance.
+				if instDirEntry.Name() == "console.log" && shared.StringInSlice(entry.Name(), vmNames) {
+					continue
+				}
+
+				// Exec recordings are kept until the configured retention (in days) is reached.
+				if execRecordingFileName(instDirEntry.Name()) {
+					days, ok := recordExpiry[entry.Name()]
+					if ok && time.Since(instDirEntry.ModTime()).Hours() >= float64(days*24) {
+						err := os.Remove(path)
+						if err != nil {
+							return err
+						}
+					}
+
+					continue
+				}
+
 				// Only remove old log files (keep other files, such as conf, pid, monitor etc).
 				if strings.HasSuffix(instDirEntry.Name(), ".log") || strings.HasSuffix(instDirEntry.Name(), ".log.old") {
 					// Remove any log file which wasn't modified in the past 48 hours.
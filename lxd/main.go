@@ -189,6 +189,10 @@ func main() {
 	netcatCmd := cmdNetcat{global: &globalCmd}
 	app.AddCommand(netcatCmd.Command())
 
+	// recover sub-command
+	recoverCmd := cmdRecover{global: &globalCmd}
+	app.AddCommand(recoverCmd.Command())
+
 	// shutdown sub-command
 	shutdownCmd := cmdShutdown{global: &globalCmd}
 	app.AddCommand(shutdownCmd.Command())
@@ -197,6 +201,10 @@ func main() {
 	sqlCmd := cmdSql{global: &globalCmd}
 	app.AddCommand(sqlCmd.Command())
 
+	// sshproxy sub-command
+	sshproxyCmd := cmdSSHProxy{global: &globalCmd}
+	app.AddCommand(sshproxyCmd.Command())
+
 	// version sub-command
 	versionCmd := cmdVersion{global: &globalCmd}
 	app.AddCommand(versionCmd.Command())
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+)
+
+type cmdRecover struct {
+	global *cmdGlobal
+}
+
+func (c *cmdRecover) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "recover"
+	cmd.Short = "Recover missing instances and volumes from existing and unknown storage pools"
+	cmd.Long = `Description:
+  Recover missing instances and volumes from existing and unknown storage pools
+
+  This command is mostly used for disaster recovery. It will ask you about unknown storage pools and attempt to
+  access them, along with existing storage pools, and identify any missing instances and volumes that exist on the
+  pools but are unknown to LXD. It will then offer to recreate these missing entries in the database.
+`
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdRecover) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	if len(args) > 0 {
+		return fmt.Errorf("Invalid arguments")
+	}
+
+	// Only root should run this.
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("This must be run as root")
+	}
+
+	d, err := lxd.ConnectLXDUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	server, _, err := d.GetServer()
+	if err != nil {
+		return err
+	}
+
+	isClustered := d.IsClustered()
+
+	// Get list of existing storage pools to scan.
+	existingPools, err := d.GetStoragePools()
+	if err != nil {
+		return err
+	}
+
+	fmt.Print("This LXD server currently has the following storage pools:\n")
+	for _, existingPool := range existingPools {
+		fmt.Printf(" - %s (backend=%q, source=%q)\n", existingPool.Name, existingPool.Driver, existingPool.Config["source"])
+	}
+
+	supportedDriverNames := make([]string, 0, len(server.Environment.StorageSupportedDrivers))
+	for _, supportedDriver := range server.Environment.StorageSupportedDrivers {
+		supportedDriverNames = append(supportedDriverNames, supportedDriver.Name)
+	}
+
+	unknownPools := make([]api.StoragePoolsPost, 0, len(existingPools))
+
+	// Build up a list of unknown pools to scan.
+	// We don't offer this option if the server is clustered because we don't allow creating storage pools on
+	// an individual server when clustered.
+	if !isClustered {
+		for {
+			if !cli.AskBool("Would you like to recover another storage pool? (yes/no) [default=no]: ", "no") {
+				break
+			}
+
+			unknownPool := api.StoragePoolsPost{
+				StoragePoolPut: api.StoragePoolPut{
+					Config: make(map[string]string),
+				},
+			}
+
+			unknownPool.Name = cli.AskString("Name of the storage pool: ", "", func(value string) error {
+				for _, existingPool := range existingPools {
+					if value == existingPool.Name {
+						return fmt.Errorf("Storage pool %q already exists", value)
+					}
+				}
+
+				for _, pool := range unknownPools {
+					if value == pool.Name {
+						return fmt.Errorf("Storage pool %q already listed for recovery", value)
+					}
+				}
+
+				return nil
+			})
+
+			unknownPool.Driver = cli.AskString(fmt.Sprintf("Name of the storage backend (%s): ", strings.Join(supportedDriverNames, ", ")), "", func(value string) error {
+				if !shared.StringInSlice(value, supportedDriverNames) {
+					return fmt.Errorf("Invalid storage backend %q", value)
+				}
+
+				return nil
+			})
+
+			unknownPool.Config["source"] = cli.AskString("Source of the storage pool (block device, volume group, dataset, path, ... as applicable): ", "", nil)
+
+			for {
+				configLine := cli.AskString("Additional storage pool configuration property (KEY=VALUE, empty when done): ", "", func(value string) error {
+					if value == "" {
+						return nil
+					}
+
+					if !strings.Contains(value, "=") {
+						return fmt.Errorf("Property must be in the form KEY=VALUE")
+					}
+
+					return nil
+				})
+
+				if configLine == "" {
+					break
+				}
+
+				fields := strings.SplitN(configLine, "=", 2)
+				unknownPool.Config[fields[0]] = fields[1]
+			}
+
+			unknownPools = append(unknownPools, unknownPool)
+		}
+	}
+
+	fmt.Printf("The recovery process will be scanning the following storage pools:\n")
+	for _, p := range existingPools {
+		fmt.Printf(" - EXISTING: %q (backend=%q, source=%q)\n", p.Name, p.Driver, p.Config["source"])
+	}
+
+	for _, p := range unknownPools {
+		fmt.Printf(" - NEW: %q (backend=%q, source=%q)\n", p.Name, p.Driver, p.Config["source"])
+	}
+
+	if !cli.AskBool("Would you like to continue with scanning for lost volumes? (yes/no) [default=yes]: ", "yes") {
+		return nil
+	}
+
+	fmt.Printf("Scanning for unknown volumes...\n")
+
+	// Send /internal/recover/validate request to LXD.
+	reqValidate := internalRecoverValidatePost{
+		Pools: make([]api.StoragePoolsPost, 0, len(existingPools)+len(unknownPools)),
+	}
+
+	// Add existing pools to request.
+	for _, p := range existingPools {
+		reqValidate.Pools = append(reqValidate.Pools, api.StoragePoolsPost{
+			Name: p.Name, // Only send existing pool name, the rest will be looked up on server.
+		})
+	}
+
+	// Add unknown pools to request.
+	reqValidate.Pools = append(reqValidate.Pools, unknownPools...)
+
+	for {
+		resp, _, err := d.RawQuery("POST", "/internal/recover/validate", reqValidate, "")
+		if err != nil {
+			return errors.Wrapf(err, "Failed validation request")
+		}
+
+		var res internalRecoverValidateResult
+
+		err = json.Unmarshal(resp.Metadata, &res)
+		if err != nil {
+			return errors.Wrapf(err, "Failed parsing validation response")
+		}
+
+		if len(res.UnknownVolumes) > 0 {
+			fmt.Print("The following unknown volumes have been found:\n")
+			for _, unknownVol := range res.UnknownVolumes {
+				fmt.Printf(" - %s %q on pool %q in project %q (includes %d snapshots)\n", strings.Title(unknownVol.Type), unknownVol.Name, unknownVol.Pool, unknownVol.Project, unknownVol.SnapshotCount)
+			}
+		}
+
+		if len(res.DependencyErrors) > 0 {
+			fmt.Print("You are currently missing the following:\n")
+
+			for _, depErr := range res.DependencyErrors {
+				fmt.Printf(" - %s\n", depErr)
+			}
+
+			cli.AskString("Please create those missing entries and then hit ENTER: ", "", func(val string) error { return nil })
+		} else {
+			if len(res.UnknownVolumes) <= 0 {
+				fmt.Print("No unknown volumes found. Nothing to do.\n")
+				return nil
+			}
+
+			break // Dependencies met.
+		}
+	}
+
+	if !cli.AskBool("Would you like those to be recovered? (yes/no) [default=no]: ", "no") {
+		return nil
+	}
+
+	fmt.Print("Starting recovery...\n")
+
+	// Send /internal/recover/import request to LXD.
+	reqImport := internalRecoverImportPost{
+		Pools: reqValidate.Pools,
+	}
+
+	_, _, err = d.RawQuery("POST", "/internal/recover/import", reqImport, "")
+	if err != nil {
+		return errors.Wrapf(err, "Failed import request")
+	}
+
+	return nil
+}
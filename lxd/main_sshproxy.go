@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/eagain"
+)
+
+type cmdSSHProxy struct {
+	global *cmdGlobal
+}
+
+func (c *cmdSSHProxy) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "sshproxy [<socket>]"
+	cmd.Short = "Forward stdin/stdout to the LXD unix socket"
+	cmd.Long = `Description:
+  Forward stdin/stdout to the LXD unix socket
+
+  This internal command is run over SSH by clients using an ssh:// remote.
+  It connects its standard input and output to the LXD unix socket so that
+  the client can speak the LXD API (including websockets) over the SSH
+  connection.
+
+  The socket defaults to $LXD_SOCKET or $LXD_DIR/unix.socket.
+`
+	cmd.RunE = c.Run
+	cmd.Hidden = true
+
+	return cmd
+}
+
+func (c *cmdSSHProxy) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	if len(args) > 1 {
+		cmd.Help()
+		return fmt.Errorf("Too many arguments")
+	}
+
+	// Determine the socket path.
+	path := os.Getenv("LXD_SOCKET")
+	if len(args) == 1 && args[0] != "" {
+		path = args[0]
+	} else if path == "" {
+		path = shared.VarPath("unix.socket")
+	}
+
+	uAddr, err := net.ResolveUnixAddr("unix", path)
+	if err != nil {
+		return fmt.Errorf("Could not resolve unix domain socket %q: %v", path, err)
+	}
+
+	conn, err := net.DialUnix("unix", nil, uAddr)
+	if err != nil {
+		return fmt.Errorf("Could not dial unix domain socket %q: %v", path, err)
+	}
+
+	defer conn.Close()
+
+	chDone := make(chan error, 2)
+
+	go func() {
+		_, err := io.Copy(eagain.Writer{Writer: os.Stdout}, eagain.Reader{Reader: conn})
+		chDone <- err
+	}()
+
+	go func() {
+		_, err := io.Copy(eagain.Writer{Writer: conn}, eagain.Reader{Reader: os.Stdin})
+
+		// The client went away, let LXD know there won't be anything else coming.
+		conn.CloseWrite()
+		if err != nil {
+			chDone <- err
+		}
+	}()
+
+	return <-chDone
+}
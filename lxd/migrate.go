@@ -256,6 +256,7 @@ type migrationSink struct {
 	allConnected chan bool
 	push         bool
 	refresh      bool
+	resume       bool
 }
 
 type MigrationSinkArgs struct {
@@ -271,6 +272,7 @@ type MigrationSinkArgs struct {
 	Idmap        *idmap.IdmapSet
 	Live         bool
 	Refresh      bool
+	Resume       bool
 	Snapshots    []*migration.Snapshot
 
 	// Storage specific fields
@@ -342,3 +344,40 @@ func (s *migrationSink) Connect(op *operations.Operation, r *http.Request, w htt
 
 	return nil
 }
+
+// migrationResumeParent returns the most recent snapshot already received by the target of an interrupted
+// transfer which precedes the snapshots still to be sent. It is used as the parent of the resumed transfer.
+func migrationResumeParent(snapshotNames []string, sendSnapshotNames []string) string {
+	parent := ""
+	for _, snapName := range snapshotNames {
+		if len(sendSnapshotNames) > 0 && snapName == sendSnapshotNames[0] {
+			break
+		}
+
+		if !shared.StringInSlice(snapName, sendSnapshotNames) {
+			parent = snapName
+		}
+	}
+
+	return parent
+}
+
+// migrationResumeSnapshots filters out the snapshots already received by the target of an interrupted transfer
+// from the offered snapshots and snapshot names.
+func migrationResumeSnapshots(snapshots []*migration.Snapshot, snapshotNames []string, received []string) ([]*migration.Snapshot, []string) {
+	sendSnapshots := []*migration.Snapshot{}
+	for _, snap := range snapshots {
+		if !shared.StringInSlice(snap.GetName(), received) {
+			sendSnapshots = append(sendSnapshots, snap)
+		}
+	}
+
+	sendSnapshotNames := []string{}
+	for _, snapName := range snapshotNames {
+		if !shared.StringInSlice(snapName, received) {
+			sendSnapshotNames = append(sendSnapshotNames, snapName)
+		}
+	}
+
+	return sendSnapshots, sendSnapshotNames
+}
@@ -433,6 +433,9 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 
 	offerHeader.Predump = proto.Bool(offerUsePreDumps)
 
+	// Indicate that the target can resume an interrupted transfer.
+	offerHeader.Resume = proto.Bool(true)
+
 	// Send offer to target.
 	err = s.send(offerHeader)
 	if err != nil {
@@ -493,6 +496,14 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 		sendSnapshotNames = respHeader.GetSnapshotNames()
 	}
 
+	// If we are resuming an interrupted transfer, only send the snapshots the target is missing.
+	if respHeader.GetResume() {
+		sendSnapshotNames = respHeader.GetSnapshotNames()
+		volSourceArgs.Resume = true
+		volSourceArgs.ResumeToken = respHeader.GetResumeToken()
+		volSourceArgs.ResumeParent = migrationResumeParent(snapshotNames, sendSnapshotNames)
+	}
+
 	volSourceArgs.Name = s.instance.Name()
 	volSourceArgs.MigrationType = migrationTypes[0]
 	volSourceArgs.Snapshots = sendSnapshotNames
@@ -724,6 +735,7 @@ func newMigrationSink(args *MigrationSinkArgs) (*migrationSink, error) {
 		dialer:  args.Dialer,
 		push:    args.Push,
 		refresh: args.Refresh,
+		resume:  args.Resume,
 	}
 
 	if sink.push {
@@ -881,6 +893,8 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 			TrackProgress: true,            // Use a progress tracker on receiver to get in-cluster progress information.
 			Live:          args.Live,       // Indicates we will get a final rootfs sync.
 			VolumeSize:    args.VolumeSize, // Block size setting override.
+			Resume:        args.Resume,     // Indicates the volume may exist from an interrupted transfer.
+			ResumeToken:   respHeader.GetResumeToken(),
 		}
 
 		// At this point we have already figured out the parent container's root
@@ -936,7 +950,7 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 
 		// Only delete entire instance on error if the pool volume creation has succeeded to avoid
 		// deleting an existing conflicting volume.
-		if !volTargetArgs.Refresh {
+		if !volTargetArgs.Refresh && !volTargetArgs.Resume {
 			revert.Add(func() { args.Instance.Delete(true) })
 		}
 
@@ -980,6 +994,35 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 		offerHeader.SnapshotNames = snapshotNames
 	}
 
+	if c.resume {
+		if !offerHeader.GetResume() {
+			err := fmt.Errorf("Source server doesn't support resuming transfers")
+			controller(err)
+			return err
+		}
+
+		// Get what was already received by the interrupted transfer.
+		resumeState, err := pool.InstanceMigrationResumeState(c.src.instance, migrateOp)
+		if err != nil {
+			controller(err)
+			return err
+		}
+
+		// Only ask for the snapshots which weren't fully received yet.
+		snapshots, snapshotNames := migrationResumeSnapshots(offerHeader.GetSnapshots(), offerHeader.GetSnapshotNames(), resumeState.Snapshots)
+
+		respHeader.Resume = proto.Bool(true)
+		respHeader.Snapshots = snapshots
+		respHeader.SnapshotNames = snapshotNames
+		offerHeader.Snapshots = snapshots
+		offerHeader.SnapshotNames = snapshotNames
+
+		// Resume tokens are only meaningful to the storage driver which created them.
+		if resumeState.Token != "" && respTypes[0].FSType == migration.MigrationFSType_ZFS {
+			respHeader.ResumeToken = proto.String(resumeState.Token)
+		}
+	}
+
 	if offerHeader.GetPredump() == true {
 		// If the other side wants pre-dump and if this side supports it, let's use it.
 		respHeader.Predump = proto.Bool(true)
@@ -1059,6 +1102,7 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 				Idmap:         srcIdmap,
 				Live:          sendFinalFsDelta,
 				Refresh:       c.refresh,
+				Resume:        c.resume,
 				RsyncFeatures: rsyncFeatures,
 				Snapshots:     snapshots,
 				VolumeSize:    offerHeader.GetVolumeSize(), // Block size setting override.
@@ -99,6 +99,9 @@ func (s *migrationSourceWs) DoStorage(state *state.State, projectName string, po
 	offerHeader.SnapshotNames = snapshotNames
 	offerHeader.Snapshots = snapshots
 
+	// Indicate that the target can resume an interrupted transfer.
+	offerHeader.Resume = proto.Bool(true)
+
 	// Send offer to target.
 	err = s.send(offerHeader)
 	if err != nil {
@@ -131,6 +134,14 @@ func (s *migrationSourceWs) DoStorage(state *state.State, projectName string, po
 		ContentType:   vol.ContentType,
 	}
 
+	// If we are resuming an interrupted transfer, only send the snapshots the target is missing.
+	if respHeader.GetResume() {
+		volSourceArgs.Snapshots = respHeader.GetSnapshotNames()
+		volSourceArgs.Resume = true
+		volSourceArgs.ResumeToken = respHeader.GetResumeToken()
+		volSourceArgs.ResumeParent = migrationResumeParent(snapshotNames, volSourceArgs.Snapshots)
+	}
+
 	err = pool.MigrateCustomVolume(projectName, &shared.WebsocketIO{Conn: s.fsConn}, volSourceArgs, migrateOp)
 	if err != nil {
 		go s.sendControl(err)
@@ -160,6 +171,7 @@ func newStorageMigrationSink(args *MigrationSinkArgs) (*migrationSink, error) {
 		url:    args.Url,
 		dialer: args.Dialer,
 		push:   args.Push,
+		resume: args.Resume,
 	}
 
 	if sink.push {
@@ -282,6 +294,31 @@ func (c *migrationSink) DoStorage(state *state.State, projectName string, poolNa
 	respHeader.SnapshotNames = offerHeader.SnapshotNames
 	respHeader.Snapshots = offerHeader.Snapshots
 
+	if c.resume {
+		if !offerHeader.GetResume() {
+			err := fmt.Errorf("Source server doesn't support resuming transfers")
+			controller(err)
+			return err
+		}
+
+		// Get what was already received by the interrupted transfer.
+		resumeState, err := pool.CustomVolumeMigrationResumeState(projectName, req.Name, op)
+		if err != nil {
+			controller(err)
+			return err
+		}
+
+		// Only ask for the snapshots which weren't fully received yet.
+		respHeader.Snapshots, respHeader.SnapshotNames = migrationResumeSnapshots(offerHeader.GetSnapshots(), offerHeader.GetSnapshotNames(), resumeState.Snapshots)
+
+		respHeader.Resume = proto.Bool(true)
+
+		// Resume tokens are only meaningful to the storage driver which created them.
+		if resumeState.Token != "" && respTypes[0].FSType == migration.MigrationFSType_ZFS {
+			respHeader.ResumeToken = proto.String(resumeState.Token)
+		}
+	}
+
 	// Translate the legacy MigrationSinkArgs to a VolumeTargetArgs suitable for use
 	// with the new storage layer.
 	myTarget = func(conn *websocket.Conn, op *operations.Operation, args MigrationSinkArgs) error {
@@ -292,6 +329,8 @@ func (c *migrationSink) DoStorage(state *state.State, projectName string, poolNa
 			MigrationType: respTypes[0],
 			TrackProgress: true,
 			ContentType:   req.ContentType,
+			Resume:        c.resume,
+			ResumeToken:   respHeader.GetResumeToken(),
 		}
 
 		// A zero length Snapshots slice indicates volume only migration in
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/migration"
+)
+
+func TestMigrationResumeParent(t *testing.T) {
+	snapshotNames := []string{"snap0", "snap1", "snap2", "snap3"}
+
+	// The target received the first two snapshots before being interrupted.
+	assert.Equal(t, "snap1", migrationResumeParent(snapshotNames, []string{"snap2", "snap3"}))
+
+	// The target received all the snapshots, only the main volume is left.
+	assert.Equal(t, "snap3", migrationResumeParent(snapshotNames, []string{}))
+
+	// Nothing was received yet.
+	assert.Equal(t, "", migrationResumeParent(snapshotNames, snapshotNames))
+
+	// No snapshots at all.
+	assert.Equal(t, "", migrationResumeParent([]string{}, []string{}))
+
+	// Snapshots created on the source after the interruption are sent after the ones received.
+	assert.Equal(t, "snap1", migrationResumeParent([]string{"snap0", "snap1", "snap4"}, []string{"snap4"}))
+}
+
+func TestMigrationResumeSnapshots(t *testing.T) {
+	snapshots := []*migration.Snapshot{
+		{Name: proto.String("snap0")},
+		{Name: proto.String("snap1")},
+		{Name: proto.String("snap2")},
+	}
+
+	snapshotNames := []string{"snap0", "snap1", "snap2"}
+
+	sendSnapshots, sendSnapshotNames := migrationResumeSnapshots(snapshots, snapshotNames, []string{"snap0", "snap1"})
+	assert.Equal(t, []*migration.Snapshot{snapshots[2]}, sendSnapshots)
+	assert.Equal(t, []string{"snap2"}, sendSnapshotNames)
+
+	sendSnapshots, sendSnapshotNames = migrationResumeSnapshots(snapshots, snapshotNames, []string{})
+	assert.Equal(t, snapshots, sendSnapshots)
+	assert.Equal(t, snapshotNames, sendSnapshotNames)
+
+	sendSnapshots, sendSnapshotNames = migrationResumeSnapshots(snapshots, snapshotNames, snapshotNames)
+	assert.Empty(t, sendSnapshots)
+	assert.Empty(t, sendSnapshotNames)
+}
@@ -549,6 +549,8 @@ type MigrationHeader struct {
 	ZfsFeatures          *ZfsFeatures     `protobuf:"bytes,10,opt,name=zfsFeatures" json:"zfsFeatures,omitempty"`
 	VolumeSize           *int64           `protobuf:"varint,11,opt,name=volumeSize" json:"volumeSize,omitempty"`
 	BtrfsFeatures        *BtrfsFeatures   `protobuf:"bytes,12,opt,name=btrfsFeatures" json:"btrfsFeatures,omitempty"`
+	Resume               *bool            `protobuf:"varint,13,opt,name=resume" json:"resume,omitempty"`
+	ResumeToken          *string          `protobuf:"bytes,14,opt,name=resumeToken" json:"resumeToken,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
 	XXX_unrecognized     []byte           `json:"-"`
 	XXX_sizecache        int32            `json:"-"`
@@ -656,6 +658,20 @@ func (m *MigrationHeader) GetBtrfsFeatures() *BtrfsFeatures {
 	return nil
 }
 
+func (m *MigrationHeader) GetResume() bool {
+	if m != nil && m.Resume != nil {
+		return *m.Resume
+	}
+	return false
+}
+
+func (m *MigrationHeader) GetResumeToken() string {
+	if m != nil && m.ResumeToken != nil {
+		return *m.ResumeToken
+	}
+	return ""
+}
+
 type MigrationControl struct {
 	Success *bool `protobuf:"varint,1,req,name=success" json:"success,omitempty"`
 	// optional failure message if sending a failure
@@ -1004,77 +1020,78 @@ func init() {
 }
 
 var fileDescriptor_fe8772548dc4b615 = []byte{
-	// 1143 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0x5f, 0x6f, 0xdc, 0x44,
-	0x10, 0xe7, 0xfe, 0x24, 0xb9, 0x1b, 0xdf, 0x25, 0xd7, 0x6d, 0x55, 0x59, 0x2d, 0x94, 0xe0, 0x16,
-	0x91, 0x06, 0xa9, 0x2d, 0x57, 0x21, 0xf5, 0x09, 0xa9, 0xb9, 0x10, 0x5a, 0xd1, 0xa6, 0xd1, 0x5e,
-	0x2b, 0x04, 0x2f, 0xd6, 0xc6, 0x1e, 0x5f, 0x56, 0xf5, 0x3f, 0xed, 0xda, 0x69, 0x2e, 0x2f, 0x88,
-	0x0f, 0xc3, 0x47, 0xe2, 0x7b, 0xf0, 0xca, 0x1b, 0xda, 0x59, 0xdb, 0xb1, 0x13, 0x24, 0xde, 0x76,
-	0x7e, 0xf3, 0xf3, 0xcc, 0xec, 0xcc, 0x6f, 0xc7, 0x70, 0x3f, 0xbe, 0x08, 0x9f, 0x26, 0x72, 0xa5,
-	0x44, 0x21, 0xb3, 0xb4, 0x3a, 0xe1, 0x93, 0x5c, 0x65, 0x45, 0xc6, 0xc6, 0x8d, 0xc3, 0xfb, 0x1d,
-	0xc6, 0xaf, 0x0f, 0xdf, 0x8a, 0xfc, 0xfd, 0x3a, 0x47, 0x76, 0x07, 0x36, 0xa4, 0x2e, 0x65, 0xe8,
-	0xf6, 0x76, 0xfb, 0x7b, 0x23, 0x6e, 0x0d, 0x8b, 0xae, 0x64, 0xe8, 0xf6, 0x6b, 0x74, 0x25, 0x43,
-	0x76, 0x17, 0x36, 0xcf, 0x32, 0x5d, 0xc8, 0xd0, 0x1d, 0xec, 0xf6, 0xf7, 0x36, 0x78, 0x65, 0x31,
-	0x06, 0xc3, 0x54, 0xcb, 0xd0, 0x1d, 0x12, 0x4a, 0x67, 0x76, 0x0f, 0x46, 0x89, 0xc8, 0x95, 0x48,
-	0x57, 0xe8, 0x6e, 0x10, 0xde, 0xd8, 0xde, 0x33, 0xd8, 0x5c, 0x64, 0x69, 0x24, 0x57, 0x6c, 0x06,
-	0x83, 0x8f, 0xb8, 0xa6, 0xdc, 0x63, 0x6e, 0x8e, 0x26, 0xf3, 0xb9, 0x88, 0x4b, 0xa4, 0xcc, 0x63,
-	0x6e, 0x0d, 0xef, 0x27, 0xd8, 0x3c, 0xc4, 0x73, 0x19, 0x20, 0xe5, 0x12, 0x09, 0x56, 0x9f, 0xd0,
-	0x99, 0x3d, 0x86, 0xcd, 0x80, 0xe2, 0xb9, 0xfd, 0xdd, 0xc1, 0x9e, 0x33, 0xbf, 0xf5, 0xa4, 0xb9,
-	0xec, 0x13, 0x9b, 0x88, 0x57, 0x04, 0xef, 0xef, 0x3e, 0x8c, 0x96, 0xa9, 0xc8, 0xf5, 0x59, 0x56,
-	0xfc, 0x67, 0xac, 0xe7, 0xe0, 0xc4, 0x59, 0x20, 0xe2, 0xc5, 0xff, 0x04, 0x6c, 0xb3, 0xcc, 0x65,
-	0x73, 0x95, 0x45, 0x32, 0x46, 0xed, 0x0e, 0x76, 0x07, 0x7b, 0x63, 0xde, 0xd8, 0xec, 0x73, 0x18,
-	0x63, 0x7e, 0x86, 0x09, 0x2a, 0x11, 0x53, 0x87, 0x46, 0xfc, 0x0a, 0x60, 0xdf, 0xc3, 0x84, 0x02,
-	0xd9, 0xdb, 0x69, 0x77, 0xe3, 0x46, 0x3e, 0xeb, 0xe1, 0x1d, 0x1a, 0xf3, 0x60, 0x22, 0x54, 0x70,
-	0x26, 0x0b, 0x0c, 0x8a, 0x52, 0xa1, 0xbb, 0x49, 0x1d, 0xee, 0x60, 0xa6, 0x28, 0x5d, 0x88, 0x02,
-	0xa3, 0x32, 0x76, 0xb7, 0x28, 0x6f, 0x63, 0xb3, 0x87, 0x30, 0x0d, 0x14, 0x52, 0x02, 0x3f, 0x14,
-	0x05, 0xba, 0xa3, 0xdd, 0xde, 0xde, 0x80, 0x4f, 0x6a, 0xf0, 0x50, 0x14, 0xc8, 0x1e, 0xc1, 0x76,
-	0x2c, 0x74, 0xe1, 0x97, 0x1a, 0x43, 0xcb, 0x1a, 0x5b, 0x96, 0x41, 0x3f, 0x68, 0x0c, 0x89, 0xf5,
-	0x25, 0x38, 0x78, 0x91, 0x4b, 0xb5, 0xb6, 0x14, 0x20, 0x0a, 0x58, 0xc8, 0x10, 0xbc, 0x3f, 0x7a,
-	0x30, 0x55, 0x7a, 0x9d, 0x06, 0x47, 0x28, 0x4c, 0x61, 0xda, 0xe8, 0xe8, 0x42, 0x14, 0x85, 0xd2,
-	0x6e, 0x6f, 0xb7, 0xb7, 0x37, 0xe2, 0x95, 0x65, 0xf0, 0x10, 0x63, 0x2c, 0xcc, 0xf0, 0x09, 0xb7,
-	0x96, 0xb9, 0x49, 0x90, 0x25, 0xb9, 0x42, 0x6d, 0xda, 0x6b, 0x3c, 0x8d, 0xcd, 0x1e, 0xc1, 0xf4,
-	0x54, 0x86, 0x52, 0x61, 0x60, 0xea, 0xa6, 0x16, 0x1b, 0x42, 0x17, 0xf4, 0x1e, 0x83, 0x73, 0x19,
-	0xe9, 0xa6, 0x80, 0x76, 0xc0, 0x5e, 0x37, 0xa0, 0xb7, 0x82, 0xe9, 0x69, 0xa1, 0x5a, 0xe4, 0xc7,
-	0x30, 0x6b, 0xa6, 0xe1, 0x9f, 0xa1, 0x08, 0x51, 0x55, 0x1f, 0xed, 0x34, 0xf8, 0x2b, 0x82, 0xd9,
-	0xb7, 0x70, 0xcb, 0x12, 0x7c, 0x5d, 0x9e, 0x9e, 0x67, 0x71, 0x99, 0xa0, 0xae, 0xee, 0x32, 0xb3,
-	0x8e, 0x65, 0x83, 0x7b, 0xff, 0x0c, 0x60, 0xe7, 0xed, 0xb5, 0x00, 0xfb, 0xd0, 0x8f, 0x34, 0xe9,
-	0x71, 0x7b, 0x7e, 0xaf, 0x25, 0x82, 0x86, 0x77, 0xb4, 0x34, 0xaf, 0x96, 0xf7, 0x23, 0xcd, 0xbe,
-	0x81, 0x61, 0xa0, 0x64, 0x49, 0xf1, 0xb7, 0xe7, 0xb7, 0xdb, 0x12, 0xe5, 0xaf, 0x3f, 0x10, 0x8d,
-	0x08, 0x6c, 0x1f, 0x36, 0x64, 0x98, 0x88, 0x9c, 0xa4, 0xe9, 0xcc, 0xef, 0xb4, 0x98, 0xcd, 0x1e,
-	0xe0, 0x96, 0x62, 0xda, 0xa9, 0xab, 0xe7, 0x71, 0x2c, 0x4c, 0xf5, 0x43, 0x92, 0x73, 0x17, 0x64,
-	0xdf, 0xc1, 0xb8, 0x06, 0x6a, 0xc9, 0xb6, 0xf3, 0xd7, 0x0f, 0x8c, 0x5f, 0xb1, 0x98, 0x0b, 0x5b,
-	0xb9, 0xc2, 0xb0, 0x4c, 0x72, 0x77, 0x8b, 0x1a, 0x52, 0x9b, 0xec, 0x87, 0x6b, 0xf2, 0x20, 0x2d,
-	0x3a, 0x73, 0xb7, 0x15, 0xb0, 0xe3, 0xe7, 0xd7, 0xd4, 0xe4, 0xc2, 0x96, 0xc2, 0x48, 0xa1, 0x3e,
-	0x23, 0x7d, 0x8e, 0x78, 0x6d, 0xb2, 0x17, 0x9d, 0xa9, 0x93, 0x34, 0x9d, 0xf9, 0xdd, 0x56, 0xdc,
-	0x96, 0x97, 0x77, 0x04, 0xf2, 0x00, 0xc0, 0x8e, 0x69, 0x29, 0x2f, 0xd1, 0x75, 0xac, 0xa6, 0xaf,
-	0x10, 0x53, 0x73, 0x47, 0x24, 0xee, 0xe4, 0x46, 0xcd, 0x1d, 0x3f, 0xef, 0xd2, 0xbd, 0x23, 0x98,
-	0x35, 0x23, 0x5d, 0x64, 0x69, 0xa1, 0xb2, 0xd8, 0xdc, 0x43, 0x97, 0x41, 0x60, 0x35, 0x69, 0x9e,
-	0x6b, 0x6d, 0x1a, 0x4f, 0x82, 0x5a, 0x8b, 0x95, 0x7d, 0x18, 0x63, 0x5e, 0x9b, 0xde, 0x73, 0x98,
-	0x36, 0x71, 0x96, 0xeb, 0x34, 0x30, 0x8b, 0x21, 0x92, 0xa9, 0x88, 0x4f, 0x14, 0x1e, 0x9a, 0x5e,
-	0xdb, 0x48, 0x1d, 0xcc, 0xfb, 0x73, 0x00, 0x33, 0xd3, 0x79, 0xdf, 0xac, 0x03, 0xed, 0x63, 0x5a,
-	0xa8, 0xb5, 0xd9, 0x08, 0x91, 0x42, 0xbc, 0x94, 0xe9, 0xca, 0x2f, 0x64, 0xb5, 0x14, 0xa7, 0x7c,
-	0x52, 0x83, 0xef, 0x65, 0x42, 0x6f, 0x3d, 0x52, 0xd9, 0x25, 0xa6, 0x96, 0xd2, 0x27, 0x0a, 0x58,
-	0x88, 0x08, 0x5f, 0xc1, 0x24, 0xc1, 0x84, 0x82, 0x13, 0x63, 0x40, 0x0c, 0xa7, 0xc2, 0x88, 0xf2,
-	0x10, 0xa6, 0x09, 0x26, 0x9f, 0x94, 0x2c, 0xd0, 0x72, 0x86, 0x36, 0x51, 0x0d, 0xd6, 0xa4, 0x5c,
-	0xac, 0x50, 0xfb, 0x3a, 0x10, 0x69, 0x8a, 0x21, 0xfd, 0x42, 0x86, 0x7c, 0x42, 0xe0, 0xd2, 0x62,
-	0xec, 0x19, 0xdc, 0xa9, 0x48, 0x1f, 0x65, 0x9e, 0x63, 0xe8, 0xe7, 0x42, 0x61, 0x5a, 0xd0, 0x32,
-	0x1c, 0x72, 0x66, 0xb9, 0xd6, 0x75, 0x42, 0x9e, 0xab, 0xb0, 0x26, 0x53, 0x81, 0x29, 0xed, 0xc5,
-	0x3a, 0xec, 0x2f, 0x16, 0x33, 0x24, 0xa9, 0x12, 0x91, 0xfb, 0x0a, 0x75, 0x16, 0x9f, 0xdb, 0xdd,
-	0x38, 0xe5, 0x13, 0x02, 0xb9, 0xc5, 0xd8, 0x17, 0x00, 0x36, 0x52, 0x2c, 0x2e, 0xd7, 0xee, 0x98,
-	0xc2, 0x8c, 0x09, 0x79, 0x23, 0x2e, 0xd7, 0xb5, 0xdb, 0xcf, 0x65, 0x5e, 0x09, 0xaf, 0x72, 0x9f,
-	0x18, 0xc0, 0x6c, 0xd6, 0xc6, 0xed, 0x9f, 0x96, 0x91, 0x26, 0x89, 0x55, 0x85, 0x18, 0xca, 0x41,
-	0x19, 0x69, 0xef, 0xaf, 0x1e, 0xdc, 0x56, 0xa8, 0x8b, 0x4c, 0x61, 0x67, 0x54, 0x5f, 0xdb, 0xaf,
-	0xb5, 0x6f, 0x76, 0x96, 0x50, 0x68, 0xff, 0xdd, 0x43, 0x6e, 0xef, 0xb6, 0xa8, 0x40, 0xb6, 0x0f,
-	0xb7, 0xba, 0xed, 0x09, 0xb2, 0x4f, 0x34, 0xb2, 0x21, 0xdf, 0x69, 0xf7, 0x66, 0x91, 0x7d, 0x32,
-	0x73, 0x8b, 0x32, 0xf5, 0xb1, 0x19, 0x7e, 0x35, 0xb7, 0x0a, 0xab, 0x47, 0x5b, 0x17, 0xd3, 0x1a,
-	0x9b, 0x53, 0x61, 0x44, 0x69, 0x0a, 0xab, 0x40, 0x33, 0xb6, 0x5e, 0x53, 0x18, 0xaf, 0x40, 0xef,
-	0x02, 0x9c, 0xf6, 0x75, 0x9e, 0xc2, 0x30, 0xb4, 0x52, 0x35, 0x4f, 0xe8, 0x7e, 0xeb, 0x09, 0x5d,
-	0x17, 0x29, 0x27, 0x22, 0x7b, 0x61, 0x1e, 0x3c, 0xc5, 0xa2, 0xe7, 0xe0, 0xcc, 0x1f, 0xb4, 0x57,
-	0xc5, 0xcd, 0x86, 0xf1, 0x9a, 0xbe, 0x7f, 0xdc, 0xda, 0xb8, 0x76, 0x93, 0xb2, 0x31, 0x6c, 0xf0,
-	0xe5, 0xaf, 0xc7, 0x8b, 0xd9, 0x67, 0xe6, 0x78, 0xf0, 0x9e, 0x1f, 0x2d, 0x67, 0x3d, 0xb6, 0x05,
-	0x83, 0xdf, 0x8e, 0x96, 0xb3, 0xbe, 0x39, 0xf0, 0x83, 0xc3, 0xd9, 0x80, 0xdd, 0x86, 0x9d, 0x83,
-	0x37, 0xef, 0x16, 0x3f, 0xfb, 0x2f, 0x8f, 0x0f, 0x7d, 0xfb, 0xc5, 0x70, 0xff, 0x29, 0x8c, 0xea,
-	0x5d, 0xcb, 0xb6, 0x01, 0xcc, 0xd9, 0x6f, 0x45, 0x3b, 0x79, 0xf5, 0xf2, 0xc3, 0x9b, 0x59, 0x8f,
-	0x8d, 0x60, 0x78, 0xfc, 0xee, 0xf8, 0xc7, 0x59, 0xff, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x77,
-	0x67, 0x3e, 0xf5, 0xa3, 0x09, 0x00, 0x00,
+	// 1168 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0x5d, 0x6e, 0xdb, 0x46,
+	0x10, 0xae, 0x24, 0xda, 0x16, 0x87, 0x92, 0xad, 0x6c, 0x82, 0x80, 0x48, 0xda, 0x54, 0x65, 0x52,
+	0x54, 0x71, 0x81, 0x24, 0x55, 0x50, 0x20, 0x4f, 0x01, 0x62, 0xb9, 0x6e, 0x82, 0x26, 0x8e, 0xb1,
+	0x72, 0x50, 0xb4, 0x2f, 0xc4, 0x9a, 0x1c, 0xca, 0x0b, 0xf3, 0x0f, 0xbb, 0xa4, 0x63, 0xf9, 0xa5,
+	0xe8, 0x61, 0x7a, 0x82, 0x9e, 0xa5, 0xf7, 0xe8, 0x11, 0x8a, 0xdd, 0x25, 0x69, 0x52, 0x09, 0xd0,
+	0xb7, 0x9d, 0x6f, 0x3e, 0xce, 0xcc, 0xce, 0x7c, 0x3b, 0x84, 0xfb, 0xf1, 0x55, 0xf8, 0x34, 0xe1,
+	0x2b, 0xc1, 0x0a, 0x9e, 0xa5, 0xd5, 0x09, 0x9f, 0xe4, 0x22, 0x2b, 0x32, 0x62, 0x37, 0x0e, 0xef,
+	0x0f, 0xb0, 0xdf, 0x1c, 0xbe, 0x63, 0xf9, 0xe9, 0x3a, 0x47, 0x72, 0x07, 0xb6, 0xb8, 0x2c, 0x79,
+	0xe8, 0xf6, 0xa6, 0xfd, 0xd9, 0x90, 0x1a, 0xc3, 0xa0, 0x2b, 0x1e, 0xba, 0xfd, 0x1a, 0x5d, 0xf1,
+	0x90, 0xdc, 0x85, 0xed, 0xf3, 0x4c, 0x16, 0x3c, 0x74, 0x07, 0xd3, 0xfe, 0x6c, 0x8b, 0x56, 0x16,
+	0x21, 0x60, 0xa5, 0x92, 0x87, 0xae, 0xa5, 0x51, 0x7d, 0x26, 0xf7, 0x60, 0x98, 0xb0, 0x5c, 0xb0,
+	0x74, 0x85, 0xee, 0x96, 0xc6, 0x1b, 0xdb, 0x7b, 0x06, 0xdb, 0x8b, 0x2c, 0x8d, 0xf8, 0x8a, 0x4c,
+	0x60, 0x70, 0x81, 0x6b, 0x9d, 0xdb, 0xa6, 0xea, 0xa8, 0x32, 0x5f, 0xb2, 0xb8, 0x44, 0x9d, 0xd9,
+	0xa6, 0xc6, 0xf0, 0x7e, 0x86, 0xed, 0x43, 0xbc, 0xe4, 0x01, 0xea, 0x5c, 0x2c, 0xc1, 0xea, 0x13,
+	0x7d, 0x26, 0x8f, 0x61, 0x3b, 0xd0, 0xf1, 0xdc, 0xfe, 0x74, 0x30, 0x73, 0xe6, 0xb7, 0x9e, 0x34,
+	0x97, 0x7d, 0x62, 0x12, 0xd1, 0x8a, 0xe0, 0xfd, 0xdb, 0x87, 0xe1, 0x32, 0x65, 0xb9, 0x3c, 0xcf,
+	0x8a, 0xcf, 0xc6, 0x7a, 0x0e, 0x4e, 0x9c, 0x05, 0x2c, 0x5e, 0xfc, 0x4f, 0xc0, 0x36, 0x4b, 0x5d,
+	0x36, 0x17, 0x59, 0xc4, 0x63, 0x94, 0xee, 0x60, 0x3a, 0x98, 0xd9, 0xb4, 0xb1, 0xc9, 0x97, 0x60,
+	0x63, 0x7e, 0x8e, 0x09, 0x0a, 0x16, 0xeb, 0x0e, 0x0d, 0xe9, 0x0d, 0x40, 0x7e, 0x84, 0x91, 0x0e,
+	0x64, 0x6e, 0x27, 0xdd, 0xad, 0x4f, 0xf2, 0x19, 0x0f, 0xed, 0xd0, 0x88, 0x07, 0x23, 0x26, 0x82,
+	0x73, 0x5e, 0x60, 0x50, 0x94, 0x02, 0xdd, 0x6d, 0xdd, 0xe1, 0x0e, 0xa6, 0x8a, 0x92, 0x05, 0x2b,
+	0x30, 0x2a, 0x63, 0x77, 0x47, 0xe7, 0x6d, 0x6c, 0xf2, 0x10, 0xc6, 0x81, 0x40, 0x9d, 0xc0, 0x0f,
+	0x59, 0x81, 0xee, 0x70, 0xda, 0x9b, 0x0d, 0xe8, 0xa8, 0x06, 0x0f, 0x59, 0x81, 0xe4, 0x11, 0xec,
+	0xc6, 0x4c, 0x16, 0x7e, 0x29, 0x31, 0x34, 0x2c, 0xdb, 0xb0, 0x14, 0xfa, 0x41, 0x62, 0xa8, 0x59,
+	0x5f, 0x83, 0x83, 0x57, 0x39, 0x17, 0x6b, 0x43, 0x01, 0x4d, 0x01, 0x03, 0x29, 0x82, 0xf7, 0x67,
+	0x0f, 0xc6, 0x42, 0xae, 0xd3, 0xe0, 0x08, 0x99, 0x2a, 0x4c, 0x2a, 0x1d, 0x5d, 0xb1, 0xa2, 0x10,
+	0xd2, 0xed, 0x4d, 0x7b, 0xb3, 0x21, 0xad, 0x2c, 0x85, 0x87, 0x18, 0x63, 0xa1, 0x86, 0xaf, 0x71,
+	0x63, 0xa9, 0x9b, 0x04, 0x59, 0x92, 0x0b, 0x94, 0xaa, 0xbd, 0xca, 0xd3, 0xd8, 0xe4, 0x11, 0x8c,
+	0xcf, 0x78, 0xc8, 0x05, 0x06, 0xaa, 0x6e, 0xdd, 0x62, 0x45, 0xe8, 0x82, 0xde, 0x63, 0x70, 0xae,
+	0x23, 0xd9, 0x14, 0xd0, 0x0e, 0xd8, 0xeb, 0x06, 0xf4, 0x56, 0x30, 0x3e, 0x2b, 0x44, 0x8b, 0xfc,
+	0x18, 0x26, 0xcd, 0x34, 0xfc, 0x73, 0x64, 0x21, 0x8a, 0xea, 0xa3, 0xbd, 0x06, 0x7f, 0xad, 0x61,
+	0xf2, 0x3d, 0xdc, 0x32, 0x04, 0x5f, 0x96, 0x67, 0x97, 0x59, 0x5c, 0x26, 0x28, 0xab, 0xbb, 0x4c,
+	0x8c, 0x63, 0xd9, 0xe0, 0xde, 0xdf, 0x16, 0xec, 0xbd, 0xdb, 0x08, 0xb0, 0x0f, 0xfd, 0x48, 0x6a,
+	0x3d, 0xee, 0xce, 0xef, 0xb5, 0x44, 0xd0, 0xf0, 0x8e, 0x96, 0xea, 0xd5, 0xd2, 0x7e, 0x24, 0xc9,
+	0x77, 0x60, 0x05, 0x82, 0x97, 0x3a, 0xfe, 0xee, 0xfc, 0x76, 0x5b, 0xa2, 0xf4, 0xcd, 0x07, 0x4d,
+	0xd3, 0x04, 0xb2, 0x0f, 0x5b, 0x3c, 0x4c, 0x58, 0xae, 0xa5, 0xe9, 0xcc, 0xef, 0xb4, 0x98, 0xcd,
+	0x1e, 0xa0, 0x86, 0xa2, 0xda, 0x29, 0xab, 0xe7, 0x71, 0xcc, 0x54, 0xf5, 0x96, 0x96, 0x73, 0x17,
+	0x24, 0x3f, 0x80, 0x5d, 0x03, 0xb5, 0x64, 0xdb, 0xf9, 0xeb, 0x07, 0x46, 0x6f, 0x58, 0xc4, 0x85,
+	0x9d, 0x5c, 0x60, 0x58, 0x26, 0xb9, 0xbb, 0xa3, 0x1b, 0x52, 0x9b, 0xe4, 0xe5, 0x86, 0x3c, 0xb4,
+	0x16, 0x9d, 0xb9, 0xdb, 0x0a, 0xd8, 0xf1, 0xd3, 0x0d, 0x35, 0xb9, 0xb0, 0x23, 0x30, 0x12, 0x28,
+	0xcf, 0xb5, 0x3e, 0x87, 0xb4, 0x36, 0xc9, 0x8b, 0xce, 0xd4, 0xb5, 0x34, 0x9d, 0xf9, 0xdd, 0x56,
+	0xdc, 0x96, 0x97, 0x76, 0x04, 0xf2, 0x00, 0xc0, 0x8c, 0x69, 0xc9, 0xaf, 0xd1, 0x75, 0x8c, 0xa6,
+	0x6f, 0x10, 0xf2, 0x72, 0x43, 0x24, 0xee, 0xe8, 0x93, 0x9a, 0x3b, 0x7e, 0xba, 0xa1, 0xa9, 0xbb,
+	0xb0, 0x2d, 0x50, 0x96, 0x09, 0xba, 0x63, 0xa3, 0x74, 0x63, 0x91, 0x29, 0x38, 0xe6, 0x74, 0x9a,
+	0x5d, 0x60, 0xea, 0xee, 0x4e, 0x7b, 0x33, 0x9b, 0xb6, 0x21, 0xef, 0x08, 0x26, 0x8d, 0x18, 0x16,
+	0x59, 0x5a, 0x88, 0x2c, 0x56, 0x1d, 0x90, 0x65, 0x10, 0x18, 0x35, 0xab, 0x87, 0x5e, 0x9b, 0xca,
+	0x93, 0xa0, 0x94, 0x6c, 0x65, 0x9e, 0x94, 0x4d, 0x6b, 0xd3, 0x7b, 0x0e, 0xe3, 0x26, 0xce, 0x72,
+	0x9d, 0x06, 0x6a, 0xa5, 0x44, 0x3c, 0x65, 0xf1, 0x89, 0xc0, 0x43, 0x35, 0x25, 0x13, 0xa9, 0x83,
+	0x79, 0x7f, 0x0d, 0x60, 0xa2, 0x66, 0xe6, 0xab, 0x45, 0x22, 0x7d, 0x4c, 0x0b, 0xb1, 0x56, 0xbb,
+	0x24, 0x12, 0x88, 0xd7, 0x3c, 0x5d, 0xf9, 0x05, 0xaf, 0xd6, 0xe9, 0x98, 0x8e, 0x6a, 0xf0, 0x94,
+	0x27, 0x7a, 0x4b, 0x44, 0x22, 0xbb, 0xc6, 0xd4, 0x50, 0xfa, 0x9a, 0x02, 0x06, 0xd2, 0x84, 0x6f,
+	0x60, 0x94, 0x60, 0xa2, 0x83, 0x6b, 0xc6, 0x40, 0x33, 0x9c, 0x0a, 0xd3, 0x94, 0x87, 0x30, 0x4e,
+	0x30, 0xf9, 0x28, 0x78, 0x81, 0x86, 0x63, 0x99, 0x44, 0x35, 0x58, 0x93, 0x72, 0xb6, 0x42, 0xe9,
+	0xcb, 0x80, 0xa5, 0x29, 0x86, 0xfa, 0xe7, 0x63, 0xd1, 0x91, 0x06, 0x97, 0x06, 0x23, 0xcf, 0xe0,
+	0x4e, 0x45, 0xba, 0xe0, 0x79, 0x8e, 0xa1, 0x9f, 0x33, 0x81, 0x69, 0xa1, 0xd7, 0xa8, 0x45, 0x89,
+	0xe1, 0x1a, 0xd7, 0x89, 0xf6, 0xdc, 0x84, 0x55, 0x99, 0x0a, 0x4c, 0xdd, 0x9d, 0x56, 0xd8, 0x5f,
+	0x0d, 0xa6, 0x48, 0x5c, 0x24, 0x2c, 0xf7, 0x05, 0xca, 0x2c, 0xbe, 0x34, 0x5b, 0x75, 0x4c, 0x47,
+	0x1a, 0xa4, 0x06, 0x23, 0x5f, 0x01, 0x98, 0x48, 0x31, 0xbb, 0x5e, 0xbb, 0xb6, 0x0e, 0x63, 0x6b,
+	0xe4, 0x2d, 0xbb, 0x5e, 0xd7, 0x6e, 0x3f, 0xe7, 0x79, 0x25, 0xd9, 0xca, 0x7d, 0xa2, 0x00, 0xb5,
+	0x93, 0x1b, 0xb7, 0x7f, 0x56, 0x46, 0x52, 0x8b, 0xb3, 0x2a, 0x44, 0x51, 0x0e, 0xca, 0x48, 0x7a,
+	0xff, 0xf4, 0xe0, 0xb6, 0x40, 0x59, 0x64, 0x02, 0x3b, 0xa3, 0xfa, 0xd6, 0x7c, 0x2d, 0x7d, 0xb5,
+	0xed, 0x98, 0x40, 0xf3, 0xd7, 0xb7, 0xa8, 0xb9, 0xdb, 0xa2, 0x02, 0xc9, 0x3e, 0xdc, 0xea, 0xb6,
+	0x27, 0xc8, 0x3e, 0xea, 0x91, 0x59, 0x74, 0xaf, 0xdd, 0x9b, 0x45, 0xf6, 0x51, 0xcd, 0x2d, 0xca,
+	0xc4, 0x45, 0x33, 0xfc, 0x6a, 0x6e, 0x15, 0x56, 0x8f, 0xb6, 0x2e, 0xa6, 0x35, 0x36, 0xa7, 0xc2,
+	0x34, 0xa5, 0x29, 0xac, 0x02, 0xd5, 0xd8, 0x7a, 0x4d, 0x61, 0xb4, 0x02, 0xbd, 0x2b, 0x70, 0xda,
+	0xd7, 0x79, 0x0a, 0x56, 0x68, 0xa4, 0xaa, 0x1e, 0xdf, 0xfd, 0xd6, 0xe3, 0xdb, 0x14, 0x29, 0xd5,
+	0x44, 0xf2, 0x42, 0xad, 0x0a, 0x1d, 0x4b, 0x3f, 0x07, 0x67, 0xfe, 0xa0, 0xf5, 0xcd, 0x67, 0x1a,
+	0x46, 0x6b, 0xfa, 0xfe, 0x31, 0xec, 0x6d, 0xec, 0x60, 0x62, 0xc3, 0x16, 0x5d, 0xfe, 0x76, 0xbc,
+	0x98, 0x7c, 0xa1, 0x8e, 0x07, 0xa7, 0xf4, 0x68, 0x39, 0xe9, 0x91, 0x1d, 0x18, 0xfc, 0x7e, 0xb4,
+	0x9c, 0xf4, 0xd5, 0x81, 0x1e, 0x1c, 0x4e, 0x06, 0xe4, 0x36, 0xec, 0x1d, 0xbc, 0x7d, 0xbf, 0xf8,
+	0xc5, 0x7f, 0x75, 0x7c, 0xe8, 0x9b, 0x2f, 0xac, 0xfd, 0xa7, 0x30, 0xac, 0xb7, 0x34, 0xd9, 0x05,
+	0x50, 0x67, 0xbf, 0x15, 0xed, 0xe4, 0xf5, 0xab, 0x0f, 0x6f, 0x27, 0x3d, 0x32, 0x04, 0xeb, 0xf8,
+	0xfd, 0xf1, 0x4f, 0x93, 0xfe, 0x7f, 0x03, 0x00, 0x91, 0xca, 0x13, 0xf6, 0xdd, 0x09, 0x00, 0x00,
 }
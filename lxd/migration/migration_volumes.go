@@ -27,6 +27,9 @@ type VolumeSourceArgs struct {
 	FinalSync     bool
 	Data          interface{} // Optional store to persist storage driver state between MultiSync phases.
 	ContentType   string
+	Resume        bool   // Keep the state needed to resume the transfer if it gets interrupted.
+	ResumeParent  string // Last snapshot already received by the target, usable as parent for differential transfers.
+	ResumeToken   string // Storage driver specific token to resume an interrupted transfer.
 }
 
 // VolumeTargetArgs represents the arguments needed to setup a volume migration sink.
@@ -41,6 +44,14 @@ type VolumeTargetArgs struct {
 	Live          bool
 	VolumeSize    int64
 	ContentType   string
+	Resume        bool   // Keep partially received data on failure and continue from any existing one.
+	ResumeToken   string // Storage driver specific token of the interrupted transfer being resumed.
+}
+
+// ResumeState represents what was already received by the target of an interrupted volume migration.
+type ResumeState struct {
+	Snapshots []string // Snapshots fully received.
+	Token     string   // Storage driver specific token to resume the interrupted transfer (if supported).
 }
 
 // TypesToHeader converts one or more Types to a MigrationHeader. It uses the first type argument
@@ -2,10 +2,14 @@ package acl
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/device/nictype"
 	firewallDrivers "github.com/lxc/lxd/lxd/firewall/drivers"
+	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
@@ -15,10 +19,153 @@ import (
 
 // FirewallApplyACLRules applies ACL rules to network firewall.
 func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName string, aclNet NetworkACLUsage) error {
+	rules, addressSets, err := firewallACLRules(s, aclProjectName, aclNet.Name, aclNet.Config)
+	if err != nil {
+		return errors.Wrapf(err, "Failed generating ACL rules for network %q", aclNet.Name)
+	}
+
+	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules, addressSets)
+}
+
+// firewallNICTypes are the NIC types that have ACL rules applied to their own firewall chains.
+var firewallNICTypes = []string{"routed", "macvlan", "ipvlan"}
+
+// FirewallApplyNICACLRules applies ACL rules to the firewall of a routed, macvlan or ipvlan instance NIC.
+// If netnsPID is > 0 then interfaceName is the instance side interface inside that process's network namespace,
+// otherwise it is the host side interface.
+func FirewallApplyNICACLRules(s *state.State, logger logger.Logger, aclProjectName string, inst instance.Instance, deviceName string, interfaceName string, netnsPID int, nicConfig map[string]string) error {
+	rules, addressSets, err := firewallACLRules(s, aclProjectName, interfaceName, nicConfig)
+	if err != nil {
+		return errors.Wrapf(err, "Failed generating ACL rules for instance device %q", deviceName)
+	}
+
+	return s.Firewall.InstanceSetupACLRules(inst.Project(), inst.Name(), deviceName, interfaceName, netnsPID, rules, addressSets)
+}
+
+// firewallApplyInstanceNICACLRules re-applies ACL rules to the running routed, macvlan and ipvlan instance NICs on
+// this member that use any of the specified ACLs. Returns whether any such NICs exist across the cluster.
+func firewallApplyInstanceNICACLRules(s *state.State, logger logger.Logger, aclProjectName string, aclNames ...string) (bool, error) {
+	var localNodeName string
+	err := s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		var err error
+		localNodeName, err = tx.GetLocalNodeName()
+		return err
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed getting local member name")
+	}
+
+	found := false
+	err = UsedBy(s, aclProjectName, func(_ []string, usageType interface{}, nicName string, nicConfig map[string]string) error {
+		dbInst, isInst := usageType.(db.Instance)
+		if !isInst {
+			return nil
+		}
+
+		nicType, err := nictype.NICType(s, dbInst.Project, nicConfig)
+		if err != nil {
+			return err
+		}
+
+		if !shared.StringInSlice(nicType, firewallNICTypes) {
+			return nil
+		}
+
+		found = true
+
+		if dbInst.Node != localNodeName {
+			return nil
+		}
+
+		inst, err := instance.LoadByProjectAndName(s, dbInst.Project, dbInst.Name)
+		if err != nil {
+			return errors.Wrapf(err, "Failed loading instance %q in project %q", dbInst.Name, dbInst.Project)
+		}
+
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		// Routed NICs are filtered on the host side of the veth pair, macvlan and ipvlan NICs are filtered
+		// inside the instance as they have no host side interface. When the NIC has no name set, the
+		// instance uses the name it generated for it at start.
+		interfaceName := inst.LocalConfig()[fmt.Sprintf("volatile.%s.host_name", nicName)]
+		netnsPID := 0
+		if nicType != "routed" {
+			interfaceName = nicConfig["name"]
+			if interfaceName == "" {
+				interfaceName = inst.LocalConfig()[fmt.Sprintf("volatile.%s.name", nicName)]
+			}
+
+			netnsPID = inst.InitPID()
+		}
+
+		if interfaceName == "" {
+			return nil
+		}
+
+		return FirewallApplyNICACLRules(s, logger, aclProjectName, inst, nicName, interfaceName, netnsPID, nicConfig)
+	}, aclNames...)
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// firewallACLRules converts the ACLs specified in the "security.acls" setting of config into firewall ACL rules,
+// followed by the default rules for each direction. The logPrefix is used for the log name of logged rules.
+// Also returns the address sets referenced by the rules.
+func firewallACLRules(s *state.State, aclProjectName string, logPrefix string, config map[string]string) ([]firewallDrivers.ACLRule, []firewallDrivers.AddressSet, error) {
 	var dropRules []firewallDrivers.ACLRule
 	var rejectRules []firewallDrivers.ACLRule
 	var allowRules []firewallDrivers.ACLRule
 
+	addressSets := make(map[string]firewallDrivers.AddressSet)
+
+	// subjectGroups splits the rule subjects into groups that can each be used as a firewall rule's subjects.
+	// Each referenced address set is placed in its own group (with the address set reference replaced by its
+	// firewall name) as firewalls can only match against a single named set per field, and all other subjects
+	// are kept together in a single group.
+	subjectGroups := func(subjects string) ([]string, error) {
+		if subjects == "" {
+			return []string{""}, nil
+		}
+
+		groups := []string{}
+		otherSubjects := []string{}
+		for _, subject := range util.SplitNTrimSpace(subjects, ",", -1, false) {
+			if !isAddressSetSubject(subject) {
+				otherSubjects = append(otherSubjects, subject)
+				continue
+			}
+
+			addressSetName := strings.TrimPrefix(subject, ruleSubjectAddressSetPrefix)
+			addressSet, found := addressSets[addressSetName]
+			if !found {
+				id, addressSetInfo, err := s.Cluster.GetNetworkAddressSet(aclProjectName, addressSetName)
+				if err != nil {
+					return nil, errors.Wrapf(err, "Failed loading network address set %q", addressSetName)
+				}
+
+				addressSet = firewallDrivers.AddressSet{
+					Name:      firewallAddressSetName(id),
+					Addresses: addressSetInfo.Addresses,
+				}
+
+				addressSets[addressSetName] = addressSet
+			}
+
+			groups = append(groups, fmt.Sprintf("%s%s", ruleSubjectAddressSetPrefix, addressSet.Name))
+		}
+
+		if len(otherSubjects) > 0 {
+			groups = append(groups, strings.Join(otherSubjects, ","))
+		}
+
+		return groups, nil
+	}
+
 	// convertACLRules converts the ACL rules to Firewall ACL rules.
 	convertACLRules := func(direction string, logPrefix string, rules ...api.NetworkACLRule) error {
 		for ruleIndex, rule := range rules {
@@ -26,56 +173,69 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 				continue
 			}
 
-			firewallACLRule := firewallDrivers.ACLRule{
-				Direction:       direction,
-				Action:          rule.Action,
-				Source:          rule.Source,
-				Destination:     rule.Destination,
-				Protocol:        rule.Protocol,
-				SourcePort:      rule.SourcePort,
-				DestinationPort: rule.DestinationPort,
-				ICMPType:        rule.ICMPType,
-				ICMPCode:        rule.ICMPCode,
+			sourceGroups, err := subjectGroups(rule.Source)
+			if err != nil {
+				return err
 			}
 
-			if rule.State == "logged" {
-				firewallACLRule.Log = true
-				// Max 29 chars.
-				firewallACLRule.LogName = fmt.Sprintf("%s-%s-%d", logPrefix, direction, ruleIndex)
+			destinationGroups, err := subjectGroups(rule.Destination)
+			if err != nil {
+				return err
 			}
 
-			switch {
-			case rule.Action == "drop":
-				dropRules = append(dropRules, firewallACLRule)
-			case rule.Action == "reject":
-				rejectRules = append(rejectRules, firewallACLRule)
-			case rule.Action == "allow":
-				allowRules = append(allowRules, firewallACLRule)
-			default:
-				return fmt.Errorf("Unrecognised action %q", rule.Action)
+			// Generate a firewall rule for each combination of source and destination subject groups.
+			for _, source := range sourceGroups {
+				for _, destination := range destinationGroups {
+					firewallACLRule := firewallDrivers.ACLRule{
+						Direction:       direction,
+						Action:          rule.Action,
+						Source:          source,
+						Destination:     destination,
+						Protocol:        rule.Protocol,
+						SourcePort:      rule.SourcePort,
+						DestinationPort: rule.DestinationPort,
+						ICMPType:        rule.ICMPType,
+						ICMPCode:        rule.ICMPCode,
+					}
+
+					if rule.State == "logged" {
+						firewallACLRule.Log = true
+						// Max 29 chars.
+						firewallACLRule.LogName = fmt.Sprintf("%s-%s-%d", logPrefix, direction, ruleIndex)
+					}
+
+					switch {
+					case rule.Action == "drop":
+						dropRules = append(dropRules, firewallACLRule)
+					case rule.Action == "reject":
+						rejectRules = append(rejectRules, firewallACLRule)
+					case rule.Action == "allow":
+						allowRules = append(allowRules, firewallACLRule)
+					default:
+						return fmt.Errorf("Unrecognised action %q", rule.Action)
+					}
+				}
 			}
 		}
 
 		return nil
 	}
 
-	logPrefix := aclNet.Name
-
-	// Load ACLs specified by network.
-	for _, aclName := range util.SplitNTrimSpace(aclNet.Config["security.acls"], ",", -1, true) {
+	// Load ACLs specified by config.
+	for _, aclName := range util.SplitNTrimSpace(config["security.acls"], ",", -1, true) {
 		_, aclInfo, err := s.Cluster.GetNetworkACL(aclProjectName, aclName)
 		if err != nil {
-			return errors.Wrapf(err, "Failed loading ACL %q for network %q", aclName, aclNet.Name)
+			return nil, nil, errors.Wrapf(err, "Failed loading ACL %q", aclName)
 		}
 
 		err = convertACLRules("ingress", logPrefix, aclInfo.Ingress...)
 		if err != nil {
-			return errors.Wrapf(err, "Failed converting ACL %q ingress rules for network %q", aclInfo.Name, aclNet.Name)
+			return nil, nil, errors.Wrapf(err, "Failed converting ACL %q ingress rules", aclInfo.Name)
 		}
 
 		err = convertACLRules("egress", logPrefix, aclInfo.Egress...)
 		if err != nil {
-			return errors.Wrapf(err, "Failed converting ACL %q egress rules for network %q", aclInfo.Name, aclNet.Name)
+			return nil, nil, errors.Wrapf(err, "Failed converting ACL %q egress rules", aclInfo.Name)
 		}
 	}
 
@@ -84,9 +244,9 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 	rules = append(rules, rejectRules...)
 	rules = append(rules, allowRules...)
 
-	// Add the automatic default ACL rule for the network.
-	egressAction, egressLogged := firewallACLDefaults(aclNet.Config, "egress")
-	ingressAction, ingressLogged := firewallACLDefaults(aclNet.Config, "ingress")
+	// Add the automatic default ACL rules.
+	egressAction, egressLogged := firewallACLDefaults(config, "egress")
+	ingressAction, ingressLogged := firewallACLDefaults(config, "ingress")
 
 	rules = append(rules, firewallDrivers.ACLRule{
 		Direction: "egress",
@@ -102,12 +262,17 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 		LogName:   fmt.Sprintf("%s-ingress", logPrefix),
 	})
 
-	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules)
+	ruleAddressSets := make([]firewallDrivers.AddressSet, 0, len(addressSets))
+	for _, addressSet := range addressSets {
+		ruleAddressSets = append(ruleAddressSets, addressSet)
+	}
+
+	return rules, ruleAddressSets, nil
 }
 
 // firewallACLDefaults returns the action and logging mode to use for the specified direction's default rule.
 // If the security.acls.default.{in,e}gress.action or security.acls.default.{in,e}gress.logged settings are not
-// specified in the network config, then it returns "reject" and false respectively.
+// specified in the network or NIC config, then it returns "reject" and false respectively.
 func firewallACLDefaults(netConfig map[string]string, direction string) (string, bool) {
 	defaults := map[string]string{
 		fmt.Sprintf("security.acls.default.%s.action", direction): "reject",
@@ -234,8 +234,8 @@ func UsedBy(s *state.State, aclProjectName string, usageFunc func(matchedACLName
 func isInUseByDevice(d deviceConfig.Device, matchACLNames ...string) []string {
 	matchedACLNames := []string{}
 
-	// Only NICs linked to managed networks can use network ACLs.
-	if d["type"] != "nic" || d["network"] == "" {
+	// Only NICs linked to managed networks or with their own ACL firewall chains can use network ACLs.
+	if d["type"] != "nic" || (d["network"] == "" && !shared.StringInSlice(d["nictype"], firewallNICTypes)) {
 		return matchedACLNames
 	}
 
@@ -264,6 +264,11 @@ func NetworkUsage(s *state.State, aclProjectName string, aclNames []string, aclN
 	err := UsedBy(s, aclProjectName, func(matchedACLNames []string, usageType interface{}, _ string, nicConfig map[string]string) error {
 		switch u := usageType.(type) {
 		case db.Instance, db.Profile:
+			// NICs not linked to a managed network have the ACLs applied to their own firewall chains.
+			if nicConfig["network"] == "" {
+				return nil
+			}
+
 			networkID, network, _, err := s.Cluster.GetNetworkInAnyState(aclProjectName, nicConfig["network"])
 			if err != nil {
 				return errors.Wrapf(err, "Failed to load network %q", nicConfig["network"])
@@ -204,6 +204,23 @@ func OVNEnsureACLs(s *state.State, logger logger.Logger, client *openvswitch.OVN
 		}
 	}
 
+	// Ensure the address sets referenced in the rules we are going to apply exist in OVN and are up to date.
+	applyACLInfos := make([]*api.NetworkACL, 0, len(createACLPortGroups)+len(existingACLPortGroups))
+	for _, aclStatus := range createACLPortGroups {
+		applyACLInfos = append(applyACLInfos, aclStatus.aclInfo)
+	}
+
+	for _, aclStatus := range existingACLPortGroups {
+		if aclStatus.aclInfo != nil {
+			applyACLInfos = append(applyACLInfos, aclStatus.aclInfo)
+		}
+	}
+
+	addressSetIDs, err := ovnEnsureAddressSets(s, client, projectID, aclProjectName, applyACLInfos...)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the needed port groups and then apply ACL rules to new port groups.
 	for _, aclStatus := range createACLPortGroups {
 		portGroupName := OVNACLPortGroupName(aclNameIDs[aclStatus.name])
@@ -230,7 +247,7 @@ func OVNEnsureACLs(s *state.State, logger logger.Logger, client *openvswitch.OVN
 		}
 
 		// Now apply our ACL rules to port group (and any per-ACL-per-network port groups needed).
-		err = ovnApplyToPortGroup(logger, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets)
+		err = ovnApplyToPortGroup(logger, client, aclStatus.aclInfo, portGroupName, aclNameIDs, addressSetIDs, aclNets)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Failed applying ACL rules to port group %q for security ACL %q setup", portGroupName, aclStatus.name)
 		}
@@ -260,7 +277,7 @@ func OVNEnsureACLs(s *state.State, logger logger.Logger, client *openvswitch.OVN
 		if aclStatus.aclInfo != nil {
 			logger.Debug("Applying ACL rules to OVN port group", log.Ctx{"networkACL": aclStatus.name, "portGroup": portGroupName})
 
-			err := ovnApplyToPortGroup(logger, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets)
+			err := ovnApplyToPortGroup(logger, client, aclStatus.aclInfo, portGroupName, aclNameIDs, addressSetIDs, aclNets)
 			if err != nil {
 				return nil, errors.Wrapf(err, "Failed applying ACL rules to port group %q for security ACL %q setup", portGroupName, aclStatus.name)
 			}
@@ -288,6 +305,10 @@ func ovnAddReferencedACLs(info *api.NetworkACL, referencedACLNames map[string]st
 				continue // Skip if the subject is an IP CIDR or IP range.
 			}
 
+			if isAddressSetSubject(subject) {
+				continue // Skip address set references.
+			}
+
 			// Anything else must be a referenced ACL name.
 			// Record newly seen referenced ACL into authoriative list.
 			referencedACLNames[subject] = struct{}{}
@@ -304,7 +325,8 @@ func ovnAddReferencedACLs(info *api.NetworkACL, referencedACLNames map[string]st
 }
 
 // ovnApplyToPortGroup applies the rules in the specified ACL to the specified port group.
-func ovnApplyToPortGroup(logger logger.Logger, client *openvswitch.OVN, aclInfo *api.NetworkACL, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, aclNets map[string]NetworkACLUsage) error {
+// The addressSetIDs map must contain the IDs of the address sets referenced in the ACL's rules.
+func ovnApplyToPortGroup(logger logger.Logger, client *openvswitch.OVN, aclInfo *api.NetworkACL, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, addressSetIDs map[string]int64, aclNets map[string]NetworkACLUsage) error {
 	// Create slice for port group rules that has the capacity for ingress and egress rules, plus default rule.
 	portGroupRules := make([]openvswitch.OVNACLRule, 0, len(aclInfo.Ingress)+len(aclInfo.Egress)+1)
 	networkRules := make([]openvswitch.OVNACLRule, 0)
@@ -316,7 +338,7 @@ func ovnApplyToPortGroup(logger logger.Logger, client *openvswitch.OVN, aclInfo
 				continue
 			}
 
-			ovnACLRule, networkSpecific, err := ovnRuleCriteriaToOVNACLRule(direction, &rule, portGroupName, aclNameIDs)
+			ovnACLRule, networkSpecific, err := ovnRuleCriteriaToOVNACLRule(direction, &rule, portGroupName, aclNameIDs, addressSetIDs)
 			if err != nil {
 				return err
 			}
@@ -389,7 +411,7 @@ func ovnApplyToPortGroup(logger logger.Logger, client *openvswitch.OVN, aclInfo
 
 // ovnRuleCriteriaToOVNACLRule converts a LXD ACL rule into an OVNACLRule for an OVN port group or network.
 // Returns a bool indicating if any of the rule subjects are network specific.
-func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64) (openvswitch.OVNACLRule, bool, error) {
+func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, addressSetIDs map[string]int64) (openvswitch.OVNACLRule, bool, error) {
 	networkSpecific := false
 	portGroupRule := openvswitch.OVNACLRule{
 		Direction: "to-lport", // Always use this so that outport is available to Match.
@@ -422,7 +444,7 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 
 	// Add subject filters.
 	if rule.Source != "" {
-		match, netSpecificMatch, err := ovnRuleSubjectToOVNACLMatch("src", aclNameIDs, util.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		match, netSpecificMatch, err := ovnRuleSubjectToOVNACLMatch("src", aclNameIDs, addressSetIDs, util.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return openvswitch.OVNACLRule{}, false, err
 		}
@@ -435,7 +457,7 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 	}
 
 	if rule.Destination != "" {
-		match, netSpecificMatch, err := ovnRuleSubjectToOVNACLMatch("dst", aclNameIDs, util.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		match, netSpecificMatch, err := ovnRuleSubjectToOVNACLMatch("dst", aclNameIDs, addressSetIDs, util.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return openvswitch.OVNACLRule{}, false, err
 		}
@@ -495,13 +517,23 @@ func ovnRulePortToOVNACLMatch(protocol string, direction string, portCriteria ..
 
 // ovnRuleSubjectToOVNACLMatch converts direction (src/dst) and subject criteria list into an OVN match statement.
 // Returns a bool indicating if any of the subjects are network specific.
-func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64, subjectCriteria ...string) (string, bool, error) {
+func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64, addressSetIDs map[string]int64, subjectCriteria ...string) (string, bool, error) {
 	fieldParts := make([]string, 0, len(subjectCriteria))
 	networkSpecific := false
 
 	// For each criterion check if value looks like an IP range or IP CIDR, and if not use it as an ACL name.
 	for _, subjectCriterion := range subjectCriteria {
-		if validate.IsNetworkRange(subjectCriterion) == nil {
+		if isAddressSetSubject(subjectCriterion) {
+			// Match against both of the per IP family OVN address sets.
+			addressSetName := strings.TrimPrefix(subjectCriterion, ruleSubjectAddressSetPrefix)
+			addressSetID, found := addressSetIDs[addressSetName]
+			if !found {
+				return "", false, fmt.Errorf("Cannot find network address set ID for %q", addressSetName)
+			}
+
+			ovnAddressSetName := OVNAddressSetName(addressSetID)
+			fieldParts = append(fieldParts, fmt.Sprintf("ip4.%s == $%s_ip4 || ip6.%s == $%s_ip6", direction, ovnAddressSetName, direction, ovnAddressSetName))
+		} else if validate.IsNetworkRange(subjectCriterion) == nil {
 			criterionParts := strings.SplitN(subjectCriterion, "-", 2)
 			if len(criterionParts) > 1 {
 				ip := net.ParseIP(criterionParts[0])
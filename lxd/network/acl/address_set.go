@@ -0,0 +1,513 @@
+package acl
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/network/openvswitch"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/logging"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// ruleSubjectAddressSetPrefix is the prefix used to reference an address set in ACL rule subjects.
+const ruleSubjectAddressSetPrefix = "$"
+
+// ovnAddressSetPrefix prefix used when naming address sets in OVN.
+const ovnAddressSetPrefix = "lxd_addrset"
+
+// OVNAddressSetName returns the OVN address set name for a Network address set ID.
+func OVNAddressSetName(addressSetID int64) openvswitch.OVNAddressSet {
+	// OVN address set names must match: [a-zA-Z_.][a-zA-Z_.0-9]*.
+	return openvswitch.OVNAddressSet(fmt.Sprintf("%s%d", ovnAddressSetPrefix, addressSetID))
+}
+
+// firewallAddressSetName returns the firewall address set name for a Network address set ID.
+func firewallAddressSetName(addressSetID int64) string {
+	return fmt.Sprintf("addrset%d", addressSetID)
+}
+
+// AddressSet represents a Network address set.
+type AddressSet struct {
+	logger      logger.Logger
+	state       *state.State
+	id          int64
+	projectName string
+	info        *api.NetworkAddressSet
+}
+
+// LoadAddressSetByName loads and initialises a Network address set from the database by project and name.
+func LoadAddressSetByName(s *state.State, projectName string, name string) (*AddressSet, error) {
+	id, info, err := s.Cluster.GetNetworkAddressSet(projectName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	addressSet := &AddressSet{}
+	addressSet.init(s, id, projectName, info)
+
+	return addressSet, nil
+}
+
+// CreateAddressSet validates supplied record and creates new Network address set record in the database.
+func CreateAddressSet(s *state.State, projectName string, info *api.NetworkAddressSetsPost) error {
+	addressSet := &AddressSet{}
+	addressSet.init(s, -1, projectName, nil)
+
+	err := addressSet.validateName(info.Name)
+	if err != nil {
+		return err
+	}
+
+	err = addressSet.validateConfig(&info.NetworkAddressSetPut)
+	if err != nil {
+		return err
+	}
+
+	// Insert DB record.
+	_, err = s.Cluster.CreateNetworkAddressSet(projectName, info)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// init initialise internal variables.
+func (d *AddressSet) init(state *state.State, id int64, projectName string, info *api.NetworkAddressSet) {
+	if info == nil {
+		d.info = &api.NetworkAddressSet{}
+	} else {
+		d.info = info
+	}
+
+	d.logger = logging.AddContext(logger.Log, log.Ctx{"project": projectName, "networkAddressSet": d.info.Name})
+	d.id = id
+	d.projectName = projectName
+	d.state = state
+
+	if d.info.Addresses == nil {
+		d.info.Addresses = []string{}
+	}
+
+	if d.info.Config == nil {
+		d.info.Config = make(map[string]string)
+	}
+}
+
+// ID returns the Network address set ID.
+func (d *AddressSet) ID() int64 {
+	return d.id
+}
+
+// Project returns the project.
+func (d *AddressSet) Project() string {
+	return d.projectName
+}
+
+// Info returns copy of internal info for the Network address set.
+func (d *AddressSet) Info() *api.NetworkAddressSet {
+	// Copy internal info to prevent modification externally.
+	info := api.NetworkAddressSet{}
+	info.Name = d.info.Name
+	info.Description = d.info.Description
+	info.Addresses = append(make([]string, 0, len(d.info.Addresses)), d.info.Addresses...)
+	info.Config = util.CopyConfig(d.info.Config)
+	info.UsedBy = nil // To indicate its not populated (use UsedBy() function to populate).
+
+	return &info
+}
+
+// Etag returns the values used for etag generation.
+func (d *AddressSet) Etag() []interface{} {
+	return []interface{}{d.info.Name, d.info.Description, d.info.Addresses, d.info.Config}
+}
+
+// usedByACLs returns the names of the ACLs whose rules reference the address set.
+// If firstOnly is true then search stops at first result.
+func (d *AddressSet) usedByACLs(firstOnly bool) ([]string, error) {
+	aclNames, err := d.state.Cluster.GetNetworkACLs(d.projectName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading network ACLs")
+	}
+
+	usedBy := []string{}
+	for _, aclName := range aclNames {
+		_, aclInfo, err := d.state.Cluster.GetNetworkACL(d.projectName, aclName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed loading network ACL %q", aclName)
+		}
+
+		_, found := aclAddressSetNames(aclInfo)[d.info.Name]
+		if !found {
+			continue
+		}
+
+		usedBy = append(usedBy, aclName)
+
+		if firstOnly {
+			break
+		}
+	}
+
+	return usedBy, nil
+}
+
+// UsedBy returns a list of API endpoints referencing this address set.
+func (d *AddressSet) UsedBy() ([]string, error) {
+	aclNames, err := d.usedByACLs(false)
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make([]string, 0, len(aclNames))
+	for _, aclName := range aclNames {
+		uri := fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, aclName)
+		if d.projectName != project.Default {
+			uri += fmt.Sprintf("?project=%s", d.projectName)
+		}
+
+		usedBy = append(usedBy, uri)
+	}
+
+	return usedBy, nil
+}
+
+// isUsed returns whether or not the address set is in use.
+func (d *AddressSet) isUsed() (bool, error) {
+	usedBy, err := d.usedByACLs(true)
+	if err != nil {
+		return false, err
+	}
+
+	return len(usedBy) > 0, nil
+}
+
+// validateName checks name is valid.
+func (d *AddressSet) validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	// Ensures the name can be referenced in ACL rule subjects after the address set prefix.
+	err := shared.ValidHostname(name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateConfig checks the config and addresses are valid.
+func (d *AddressSet) validateConfig(info *api.NetworkAddressSetPut) error {
+	for k := range info.Config {
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid config option %q", k)
+	}
+
+	checkedAddresses := make(map[string]struct{}, len(info.Addresses))
+
+	for i := range info.Addresses {
+		info.Addresses[i] = strings.TrimSpace(info.Addresses[i])
+		address := info.Addresses[i]
+
+		// Only IPs and CIDR subnets are supported, as not all firewall drivers support IP ranges in sets.
+		if net.ParseIP(address) == nil {
+			_, _, err := net.ParseCIDR(address)
+			if err != nil {
+				return fmt.Errorf("Invalid address %q", address)
+			}
+		}
+
+		_, found := checkedAddresses[address]
+		if found {
+			return fmt.Errorf("Duplicate address %q", address)
+		}
+
+		checkedAddresses[address] = struct{}{}
+	}
+
+	return nil
+}
+
+// Update applies the supplied config to the address set and re-applies the ACLs that reference it.
+func (d *AddressSet) Update(config *api.NetworkAddressSetPut) error {
+	err := d.validateConfig(config)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	oldConfig := d.info.NetworkAddressSetPut
+
+	// Update database. Its important this occurs before we re-apply the ACLs using the address set as the
+	// firewall and OVN rules are generated from the database.
+	err = d.state.Cluster.UpdateNetworkAddressSet(d.id, config)
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally and reinitialise.
+	d.info.NetworkAddressSetPut = *config
+	d.init(d.state, d.id, d.projectName, d.info)
+
+	revert.Add(func() {
+		d.state.Cluster.UpdateNetworkAddressSet(d.id, &oldConfig)
+		d.info.NetworkAddressSetPut = oldConfig
+		d.init(d.state, d.id, d.projectName, d.info)
+	})
+
+	aclNames, err := d.usedByACLs(false)
+	if err != nil {
+		return err
+	}
+
+	// Re-apply each ACL that uses the address set. This updates the address sets in OVN and notifies the other
+	// cluster members to update their firewalls for the networks and instance NICs using the ACLs.
+	for _, aclName := range aclNames {
+		netACL, err := LoadByName(d.state, d.projectName, aclName)
+		if err != nil {
+			return errors.Wrapf(err, "Failed loading network ACL %q", aclName)
+		}
+
+		d.logger.Debug("Re-applying network ACL using address set", log.Ctx{"networkACL": aclName})
+
+		aclConfig := netACL.Info().NetworkACLPut
+		err = netACL.Update(&aclConfig, request.ClientTypeNormal)
+		if err != nil {
+			return errors.Wrapf(err, "Failed applying address set changes to network ACL %q", aclName)
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Rename renames the address set if not in use.
+func (d *AddressSet) Rename(newName string) error {
+	_, err := LoadAddressSetByName(d.state, d.projectName, newName)
+	if err == nil {
+		return fmt.Errorf("An address set by that name exists already")
+	}
+
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot rename an address set that is in use")
+	}
+
+	err = d.validateName(newName)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.Cluster.RenameNetworkAddressSet(d.id, newName)
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally.
+	d.info.Name = newName
+
+	return nil
+}
+
+// Delete deletes the address set if not in use.
+// When clientType is request.ClientTypeNotifier, only the firewall sets on this member are removed.
+func (d *AddressSet) Delete(clientType request.ClientType) error {
+	if clientType == request.ClientTypeNotifier {
+		return d.state.Firewall.NetworkDeleteACLAddressSet(firewallAddressSetName(d.id))
+	}
+
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot delete an address set that is in use")
+	}
+
+	// Remove the address set from OVN if the project has any OVN networks that could have been using it.
+	hasOVNNetworks, err := d.projectHasOVNNetworks()
+	if err != nil {
+		return err
+	}
+
+	if hasOVNNetworks {
+		client, err := openvswitch.NewOVN(d.state)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to get OVN client")
+		}
+
+		err = client.AddressSetDelete(OVNAddressSetName(d.id))
+		if err != nil {
+			return errors.Wrapf(err, "Failed removing OVN address set")
+		}
+	}
+
+	// Remove the firewall sets on all members before removing the DB record the other members load it from.
+	notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+	if err != nil {
+		return err
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		return client.UseProject(d.projectName).DeleteNetworkAddressSet(d.info.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = d.state.Firewall.NetworkDeleteACLAddressSet(firewallAddressSetName(d.id))
+	if err != nil {
+		return errors.Wrapf(err, "Failed removing firewall address set")
+	}
+
+	return d.state.Cluster.DeleteNetworkAddressSet(d.id)
+}
+
+// projectHasOVNNetworks returns whether the address set's project contains any OVN networks.
+func (d *AddressSet) projectHasOVNNetworks() (bool, error) {
+	networkNames, err := d.state.Cluster.GetNetworks(d.projectName)
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed loading networks")
+	}
+
+	for _, networkName := range networkNames {
+		_, netInfo, _, err := d.state.Cluster.GetNetworkInAnyState(d.projectName, networkName)
+		if err != nil {
+			return false, errors.Wrapf(err, "Failed loading network %q", networkName)
+		}
+
+		if netInfo.Type == "ovn" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// AddressSetExists checks the address set name(s) provided exist in the project.
+func AddressSetExists(s *state.State, projectName string, name ...string) error {
+	existingNames, err := s.Cluster.GetNetworkAddressSets(projectName)
+	if err != nil {
+		return err
+	}
+
+	for _, addressSetName := range name {
+		if !shared.StringInSlice(addressSetName, existingNames) {
+			return fmt.Errorf("Network address set %q does not exist", addressSetName)
+		}
+	}
+
+	return nil
+}
+
+// isAddressSetSubject returns whether the rule subject is an address set reference.
+func isAddressSetSubject(subject string) bool {
+	return strings.HasPrefix(subject, ruleSubjectAddressSetPrefix)
+}
+
+// aclAddressSetNames returns the names of the address sets referenced by the rules of the ACL.
+func aclAddressSetNames(info *api.NetworkACL) map[string]struct{} {
+	addressSetNames := make(map[string]struct{})
+
+	addFrom := func(ruleSubjects string) {
+		for _, subject := range util.SplitNTrimSpace(ruleSubjects, ",", -1, true) {
+			if isAddressSetSubject(subject) {
+				addressSetNames[strings.TrimPrefix(subject, ruleSubjectAddressSetPrefix)] = struct{}{}
+			}
+		}
+	}
+
+	for _, rule := range append(append([]api.NetworkACLRule{}, info.Ingress...), info.Egress...) {
+		addFrom(rule.Source)
+		addFrom(rule.Destination)
+	}
+
+	return addressSetNames
+}
+
+// addressSetIPNets parses the addresses of an address set into subnets (single IPs become host subnets).
+func addressSetIPNets(addresses []string) ([]*net.IPNet, error) {
+	ipNets := make([]*net.IPNet, 0, len(addresses))
+	for _, address := range addresses {
+		if !strings.Contains(address, "/") {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid address %q", address)
+			}
+
+			address = fmt.Sprintf("%s/128", ip.String())
+			if ip.To4() != nil {
+				address = fmt.Sprintf("%s/32", ip.String())
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, err
+		}
+
+		ipNets = append(ipNets, ipNet)
+	}
+
+	return ipNets, nil
+}
+
+// ovnEnsureAddressSets creates or updates the OVN address sets referenced by the rules of the supplied ACLs and
+// returns a map of the referenced address set names to their IDs.
+func ovnEnsureAddressSets(s *state.State, client *openvswitch.OVN, projectID int64, aclProjectName string, aclInfos ...*api.NetworkACL) (map[string]int64, error) {
+	addressSetIDs := make(map[string]int64)
+
+	for _, aclInfo := range aclInfos {
+		for addressSetName := range aclAddressSetNames(aclInfo) {
+			_, found := addressSetIDs[addressSetName]
+			if found {
+				continue
+			}
+
+			id, addressSetInfo, err := s.Cluster.GetNetworkAddressSet(aclProjectName, addressSetName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed loading network address set %q", addressSetName)
+			}
+
+			ipNets, err := addressSetIPNets(addressSetInfo.Addresses)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed parsing network address set %q", addressSetName)
+			}
+
+			err = client.AddressSetApply(projectID, OVNAddressSetName(id), ipNets...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed applying OVN address set for network address set %q", addressSetName)
+			}
+
+			addressSetIDs[addressSetName] = id
+		}
+	}
+
+	return addressSetIDs, nil
+}
@@ -323,12 +323,18 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 		validSubjectNames = append(validSubjectNames, aclName)
 	}
 
+	// Get list of address set names (referenced in rules with the address set prefix).
+	addressSetNames, err := d.state.Cluster.GetNetworkAddressSets(d.Project())
+	if err != nil {
+		return errors.Wrapf(err, "Failed getting network address sets for security ACL subject validation")
+	}
+
 	var srcHasName, srcHasIPv4, srcHasIPv6 bool
 	var dstHasName, dstHasIPv4, dstHasIPv6 bool
 
 	// Validate Source field.
 	if rule.Source != "" {
-		srcHasName, srcHasIPv4, srcHasIPv6, err = d.validateRuleSubjects("Source", direction, util.SplitNTrimSpace(rule.Source, ",", -1, false), validSubjectNames)
+		srcHasName, srcHasIPv4, srcHasIPv6, err = d.validateRuleSubjects("Source", direction, util.SplitNTrimSpace(rule.Source, ",", -1, false), validSubjectNames, addressSetNames)
 		if err != nil {
 			return errors.Wrapf(err, "Invalid Source")
 		}
@@ -336,7 +342,7 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 
 	// Validate Destination field.
 	if rule.Destination != "" {
-		dstHasName, dstHasIPv4, dstHasIPv6, err = d.validateRuleSubjects("Destination", direction, util.SplitNTrimSpace(rule.Destination, ",", -1, false), validSubjectNames)
+		dstHasName, dstHasIPv4, dstHasIPv6, err = d.validateRuleSubjects("Destination", direction, util.SplitNTrimSpace(rule.Destination, ",", -1, false), validSubjectNames, addressSetNames)
 		if err != nil {
 			return errors.Wrapf(err, "Invalid Destination")
 		}
@@ -449,9 +455,10 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 }
 
 // validateRuleSubjects checks that the source or destination subjects for a rule are valid.
-// Accepts a validSubjectNames list of valid ACL or special classifier names.
-// Returns whether the subjects include names, IPv4 and IPv6 addresses respectively.
-func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection, subjects []string, validSubjectNames []string) (bool, bool, bool, error) {
+// Accepts a validSubjectNames list of valid ACL or special classifier names and a validAddressSetNames list of
+// address set names that can be referenced using the address set prefix.
+// Returns whether the subjects include names (or address sets), IPv4 and IPv6 addresses respectively.
+func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection, subjects []string, validSubjectNames []string, validAddressSetNames []string) (bool, bool, bool, error) {
 	// Check if named subjects are allowed in field/direction combination.
 	allowSubjectNames := false
 	if (fieldName == "Source" && direction == ruleDirectionIngress) || (fieldName == "Destination" && direction == ruleDirectionEgress) {
@@ -523,6 +530,16 @@ func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection,
 			}
 		}
 
+		// Check if it is a reference to an existing address set. As these contain addresses (which may be
+		// of either IP family) they are allowed in both the Source and Destination fields.
+		if isAddressSetSubject(subject) {
+			if !shared.StringInSlice(strings.TrimPrefix(subject, ruleSubjectAddressSetPrefix), validAddressSetNames) {
+				return 0, fmt.Errorf("Network address set %q does not exist", strings.TrimPrefix(subject, ruleSubjectAddressSetPrefix))
+			}
+
+			return 0, nil // Found valid subject.
+		}
+
 		// Check if it is one of the valid subject names.
 		for _, n := range validSubjectNames {
 			if subject == n {
@@ -648,6 +665,12 @@ func (d *common) Update(config *api.NetworkACLPut, clientType request.ClientType
 		}
 	}
 
+	// Apply ACL changes to the routed, macvlan and ipvlan NICs on this member.
+	aclNICsFound, err := firewallApplyInstanceNICACLRules(d.state, d.logger, d.projectName, d.info.Name)
+	if err != nil {
+		return errors.Wrapf(err, "Failed applying ACL to instance NICs")
+	}
+
 	// If there are affected OVN networks, then apply the changes, but only if the request type is normal.
 	// This way we won't apply the same changes multiple times for each LXD cluster member.
 	if len(aclOVNNets) > 0 && clientType == request.ClientTypeNormal {
@@ -682,8 +705,8 @@ func (d *common) Update(config *api.NetworkACLPut, clientType request.ClientType
 		}
 	}
 
-	// Apply ACL changes to non-OVN networks on cluster members.
-	if clientType == request.ClientTypeNormal && len(aclNets) > 0 {
+	// Apply ACL changes to non-OVN networks and instance NICs on cluster members.
+	if clientType == request.ClientTypeNormal && (len(aclNets) > 0 || aclNICsFound) {
 		// Notify all other nodes to update the network if no target specified.
 		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
 		if err != nil {
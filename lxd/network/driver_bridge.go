@@ -16,6 +16,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	lxd "github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/apparmor"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/cluster/request"
@@ -29,6 +30,7 @@ import (
 	"github.com/lxc/lxd/lxd/network/acl"
 	"github.com/lxc/lxd/lxd/network/openvswitch"
 	"github.com/lxc/lxd/lxd/node"
+	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/lxd/warnings"
@@ -186,6 +188,9 @@ func (n *bridge) ValidateName(name string) error {
 func (n *bridge) Validate(config map[string]string) error {
 	// Build driver specific rules dynamically.
 	rules := map[string]func(value string) error{
+		"bgp.ipv4.nexthop": validate.Optional(validate.IsNetworkAddressV4),
+		"bgp.ipv6.nexthop": validate.Optional(validate.IsNetworkAddressV6),
+
 		"bridge.driver": validate.Optional(func(value string) error {
 			return validate.IsOneOf(value, []string{"native", "openvswitch"})
 		}),
@@ -239,7 +244,7 @@ func (n *bridge) Validate(config map[string]string) error {
 		"ipv4.ovn.ranges":   validate.Optional(validate.IsNetworkRangeV4List),
 
 		"ipv6.address": validate.Optional(func(value string) error {
-			if validate.IsOneOf(value, []string{"none", "auto"}) == nil {
+			if validate.IsOneOf(value, []string{"none", "auto", pdAddressAuto}) == nil {
 				return nil
 			}
 
@@ -250,16 +255,22 @@ func (n *bridge) Validate(config map[string]string) error {
 		"ipv6.nat.order": validate.Optional(func(value string) error {
 			return validate.IsOneOf(value, []string{"before", "after"})
 		}),
-		"ipv6.nat.address":   validate.Optional(validate.IsNetworkAddressV6),
-		"ipv6.dhcp":          validate.Optional(validate.IsBool),
-		"ipv6.dhcp.expiry":   validate.IsAny,
-		"ipv6.dhcp.stateful": validate.Optional(validate.IsBool),
-		"ipv6.dhcp.ranges":   validate.Optional(validate.IsNetworkRangeV6List),
-		"ipv6.routes":        validate.Optional(validate.IsNetworkV6List),
-		"ipv6.routing":       validate.Optional(validate.IsBool),
-		"ipv6.ovn.ranges":    validate.Optional(validate.IsNetworkRangeV6List),
-		"dns.domain":         validate.IsAny,
-		"dns.search":         validate.IsAny,
+		"ipv6.nat.address":      validate.Optional(validate.IsNetworkAddressV6),
+		"ipv6.dhcp":             validate.Optional(validate.IsBool),
+		"ipv6.dhcp.expiry":      validate.IsAny,
+		"ipv6.dhcp.stateful":    validate.Optional(validate.IsBool),
+		"ipv6.dhcp.ranges":      validate.Optional(validate.IsNetworkRangeV6List),
+		"ipv6.routes":           validate.Optional(validate.IsNetworkV6List),
+		"ipv6.routing":          validate.Optional(validate.IsBool),
+		"ipv6.ovn.ranges":       validate.Optional(validate.IsNetworkRangeV6List),
+		"ipv6.pd.interface":     validate.Optional(validate.IsInterfaceName),
+		"ipv6.pd.prefix_length": validate.Optional(pdValidatePrefixLength),
+		"ipv6.pd.subnet_id":     validate.Optional(validate.IsUint32),
+		pdVolatilePrefix:        validate.Optional(validate.IsNetworkV6),
+		pdVolatileSubnetID:      validate.Optional(validate.IsUint32),
+		pdVolatileAddress:       validate.Optional(validate.IsNetworkAddressCIDRV6),
+		"dns.domain":            validate.IsAny,
+		"dns.search":            validate.IsAny,
 		"dns.mode": validate.Optional(func(value string) error {
 			return validate.IsOneOf(value, []string{"dynamic", "managed", "none"})
 		}),
@@ -275,6 +286,17 @@ func (n *bridge) Validate(config map[string]string) error {
 		}),
 		"security.acls.default.ingress.logged": validate.Optional(validate.IsBool),
 		"security.acls.default.egress.logged":  validate.Optional(validate.IsBool),
+		"wireguard.network":                    validate.Optional(validate.IsInterfaceName),
+	}
+
+	// Add the BGP validation rules.
+	bgpRules, err := n.bgpValidationRules(config)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range bgpRules {
+		rules[k] = v
 	}
 
 	// Add dynamic validation rules.
@@ -317,7 +339,7 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
-	err := n.validate(config, rules)
+	err = n.validate(config, rules)
 	if err != nil {
 		return err
 	}
@@ -461,6 +483,34 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check IPv6 prefix delegation settings.
+	if config["ipv6.address"] == pdAddressAuto {
+		if config["ipv6.pd.interface"] == "" {
+			return fmt.Errorf(`"ipv6.pd.interface" must be set when "ipv6.address" is %q`, pdAddressAuto)
+		}
+
+		clustered, err := cluster.Enabled(n.state.Node)
+		if err != nil {
+			return err
+		}
+
+		if clustered {
+			return fmt.Errorf("IPv6 prefix delegation isn't supported on clustered servers")
+		}
+	}
+
+	// Check the WireGuard network exists.
+	if config["wireguard.network"] != "" {
+		wgNet, err := LoadByName(n.state, project.Default, config["wireguard.network"])
+		if err != nil {
+			return errors.Wrapf(err, "Failed loading WireGuard network %q", config["wireguard.network"])
+		}
+
+		if wgNet.Type() != "wireguard" {
+			return fmt.Errorf("Network %q is not of type wireguard", config["wireguard.network"])
+		}
+	}
+
 	return nil
 }
 
@@ -617,8 +667,16 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	// Get a list of tunnels.
 	tunnels := n.getTunnels()
 
+	// Derive the IPv6 subnet from the delegated prefix if needed.
+	if n.config["ipv6.address"] == pdAddressAuto {
+		err := n.pdSetup()
+		if err != nil {
+			return errors.Wrapf(err, "Failed setting up IPv6 prefix delegation")
+		}
+	}
+
 	// IPv6 bridge configuration.
-	if !shared.StringInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if !shared.StringInSlice(IPv6Address(n.config), []string{"", "none"}) {
 		if !shared.PathExists("/proc/sys/net/ipv6") {
 			return fmt.Errorf("Network has ipv6.address but kernel IPv6 support is missing")
 		}
@@ -952,24 +1010,6 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			return err
 		}
 
-		// Configure NAT.
-		if shared.IsTrue(n.config["ipv4.nat"]) {
-			//If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
-			var srcIP net.IP
-			if n.config["ipv4.nat.address"] != "" {
-				srcIP = net.ParseIP(n.config["ipv4.nat.address"])
-			}
-
-			fwOpts.SNATV4 = &firewallDrivers.SNATOpts{
-				SNATAddress: srcIP,
-				Subnet:      subnet,
-			}
-
-			if n.config["ipv4.nat.order"] == "after" {
-				fwOpts.SNATV4.Append = true
-			}
-		}
-
 		// Add additional routes.
 		if n.config["ipv4.routes"] != "" {
 			for _, route := range strings.Split(n.config["ipv4.routes"], ",") {
@@ -1020,7 +1060,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	}
 
 	// Configure IPv6.
-	if !shared.StringInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if !shared.StringInSlice(IPv6Address(n.config), []string{"", "none"}) {
 		// Enable IPv6 for the subnet.
 		err := util.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/disable_ipv6", n.name), "0")
 		if err != nil {
@@ -1028,7 +1068,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 
 		// Parse the subnet.
-		ipAddress, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+		ipAddress, subnet, err := net.ParseCIDR(IPv6Address(n.config))
 		if err != nil {
 			return errors.Wrapf(err, "Failed parsing ipv6.address")
 		}
@@ -1118,7 +1158,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		// Add the address.
 		addr := &ip.Addr{
 			DevName: n.name,
-			Address: n.config["ipv6.address"],
+			Address: IPv6Address(n.config),
 			Family:  ip.FamilyV6,
 		}
 		err = addr.Add()
@@ -1126,24 +1166,6 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			return err
 		}
 
-		// Configure NAT.
-		if shared.IsTrue(n.config["ipv6.nat"]) {
-			//If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
-			var srcIP net.IP
-			if n.config["ipv6.nat.address"] != "" {
-				srcIP = net.ParseIP(n.config["ipv6.nat.address"])
-			}
-
-			fwOpts.SNATV6 = &firewallDrivers.SNATOpts{
-				SNATAddress: srcIP,
-				Subnet:      subnet,
-			}
-
-			if n.config["ipv6.nat.order"] == "after" {
-				fwOpts.SNATV6.Append = true
-			}
-		}
-
 		// Add additional routes.
 		if n.config["ipv6.routes"] != "" {
 			for _, route := range strings.Split(n.config["ipv6.routes"], ",") {
@@ -1161,6 +1183,14 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
+		// Route the delegated prefix subnets of the OVN networks using the bridge as uplink.
+		if n.config["ipv6.address"] == pdAddressAuto {
+			err = n.pdSetupRoutes()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Restore container specific IPv6 routes to interface.
 		n.applyBootRoutesV6(ctRoutes)
 	}
@@ -1326,19 +1356,6 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
-		// Configure NAT.
-		if shared.IsTrue(n.config["ipv4.nat"]) {
-			fwOpts.SNATV4 = &firewallDrivers.SNATOpts{
-				SNATAddress: nil, // Use MASQUERADE mode.
-				Subnet:      overlaySubnet,
-			}
-
-			if n.config["ipv4.nat.order"] == "after" {
-				fwOpts.SNATV4.Append = true
-
-			}
-		}
-
 		// Setup clustered DNS.
 		clusterAddress, err := node.ClusterAddress(n.state.Node)
 		if err != nil {
@@ -1482,7 +1499,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	}
 
 	// Configure dnsmasq.
-	if n.config["bridge.mode"] == "fan" || !shared.StringInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.StringInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if n.config["bridge.mode"] == "fan" || !shared.StringInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.StringInSlice(IPv6Address(n.config), []string{"", "none"}) {
 		// Setup the dnsmasq domain.
 		dnsDomain := n.config["dns.domain"]
 		if dnsDomain == "" {
@@ -1631,6 +1648,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 	}
 
+	// Configure NAT.
+	fwOpts.SNATV4, fwOpts.SNATV6, err = n.snatOpts()
+	if err != nil {
+		return err
+	}
+
 	// Setup firewall.
 	n.logger.Debug("Setting up firewall")
 	err = n.state.Firewall.NetworkSetup(n.name, fwOpts)
@@ -1653,10 +1676,62 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 	}
 
+	// Setup BGP.
+	err = n.bgpSetup(oldConfig)
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
 
+// bgpSetup sets up the BGP peers of the bridge and announces its routed subnets.
+func (n *bridge) bgpSetup(oldConfig map[string]string) error {
+	err := n.bgpSetupPeers(oldConfig)
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting up BGP peers")
+	}
+
+	subnets := []*net.IPNet{}
+
+	// Announce the bridge subnets when they aren't NATed.
+	for _, ipVersion := range []uint{4, 6} {
+		address := n.config[fmt.Sprintf("ipv%d.address", ipVersion)]
+		if ipVersion == 6 {
+			address = IPv6Address(n.config)
+		}
+
+		if validate.IsOneOf(address, []string{"", "none"}) != nil && !shared.IsTrue(n.config[fmt.Sprintf("ipv%d.nat", ipVersion)]) {
+			_, subnet, err := net.ParseCIDR(address)
+			if err == nil {
+				subnets = append(subnets, subnet)
+			}
+		}
+
+		routes := n.config[fmt.Sprintf("ipv%d.routes", ipVersion)]
+		if routes == "" {
+			continue
+		}
+
+		for _, route := range strings.Split(routes, ",") {
+			_, subnet, err := net.ParseCIDR(strings.TrimSpace(route))
+			if err != nil {
+				return err
+			}
+
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	err = n.bgpSetupPrefixes(subnets, n.bgpNextHopAddress(4), n.bgpNextHopAddress(6))
+	if err != nil {
+		return errors.Wrapf(err, "Failed announcing BGP prefixes")
+	}
+
+	return nil
+}
+
 // Stop stops the network.
 func (n *bridge) Stop() error {
 	n.logger.Debug("Stop")
@@ -1733,6 +1808,20 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Clear BGP.
+	err = n.bgpClear(n.config)
+	if err != nil {
+		return err
+	}
+
+	// Stop the DHCPv6-PD client if no other network needs it.
+	if n.config["ipv6.address"] == pdAddressAuto {
+		err = n.pdClientRelease(n.config["ipv6.pd.interface"])
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1746,6 +1835,16 @@ func (n *bridge) Update(newNetwork api.NetworkPut, targetNode string, clientType
 		return errors.Wrapf(err, "Failed generating auto config")
 	}
 
+	// Keep the prefix delegation state if still using the same delegated prefix, otherwise clear it.
+	samePD := newNetwork.Config["ipv6.address"] == pdAddressAuto && n.config["ipv6.address"] == pdAddressAuto && newNetwork.Config["ipv6.pd.interface"] == n.config["ipv6.pd.interface"] && newNetwork.Config["ipv6.pd.subnet_id"] == n.config["ipv6.pd.subnet_id"]
+	for _, k := range []string{pdVolatilePrefix, pdVolatileSubnetID, pdVolatileAddress} {
+		if !samePD {
+			delete(newNetwork.Config, k)
+		} else if newNetwork.Config[k] == "" && n.config[k] != "" {
+			newNetwork.Config[k] = n.config[k]
+		}
+	}
+
 	dbUpdateNeeeded, changedKeys, oldNetwork, err := n.common.configChanged(newNetwork)
 	if err != nil {
 		return err
@@ -1822,7 +1921,22 @@ func (n *bridge) Update(newNetwork api.NetworkPut, targetNode string, clientType
 		}
 	}
 
+	// Stop the DHCPv6-PD client of the previous interface if no longer needed.
+	if oldNetwork.Config["ipv6.address"] == pdAddressAuto && !samePD && oldNetwork.Config["ipv6.pd.interface"] != n.config["ipv6.pd.interface"] {
+		err = n.pdClientRelease(oldNetwork.Config["ipv6.pd.interface"])
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
+
+	// Notify the OVN networks using the bridge as uplink of delegated prefix changes.
+	changedPDKeys := pdChangedKeys(oldNetwork.Config, n.config)
+	if len(changedPDKeys) > 0 {
+		n.common.notifyDependentNetworks(changedPDKeys)
+	}
+
 	return nil
 }
 
@@ -1872,6 +1986,301 @@ func (n *bridge) spawnForkDNS(listenAddress string) error {
 	return nil
 }
 
+// pdSetup starts the DHCPv6-PD client on ipv6.pd.interface and records the subnet derived from the delegated
+// prefix into the volatile config.
+func (n *bridge) pdSetup() error {
+	err := pdClientStart(n.config["ipv6.pd.interface"], n.config["ipv6.pd.prefix_length"])
+	if err != nil {
+		return err
+	}
+
+	prefix, err := pdPrefix(n.config["ipv6.pd.interface"])
+	if err != nil {
+		return err
+	}
+
+	pdKeys, err := pdConfig(n.state, n.config["ipv6.pd.interface"], prefix, n.project, n.name, n.config)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for k, v := range pdKeys {
+		if n.config[k] != v {
+			n.config[k] = v
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.UpdateNetwork(n.id, n.description, n.config)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed saving volatile config")
+	}
+
+	return nil
+}
+
+// pdRefresh reconfigures the network and the OVN networks using it as uplink when the delegated prefix changes.
+func (n *bridge) pdRefresh() error {
+	prefix, err := pdPrefix(n.config["ipv6.pd.interface"])
+	if err != nil {
+		return err
+	}
+
+	pdKeys, err := pdConfig(n.state, n.config["ipv6.pd.interface"], prefix, n.project, n.name, n.config)
+	if err != nil {
+		return err
+	}
+
+	newConfig := make(map[string]string, len(n.config))
+	for k, v := range n.config {
+		newConfig[k] = v
+	}
+
+	for k, v := range pdKeys {
+		newConfig[k] = v
+	}
+
+	changedKeys := pdChangedKeys(n.config, newConfig)
+	if len(changedKeys) == 0 {
+		return nil
+	}
+
+	n.logger.Info("Delegated prefix has changed", log.Ctx{"prefix": pdKeys[pdVolatilePrefix]})
+
+	// Re-setup the bridge, this records the new subnet and reconfigures dnsmasq router advertisements.
+	err = n.setup(n.config)
+	if err != nil {
+		return err
+	}
+
+	n.common.notifyDependentNetworks(changedKeys)
+
+	// Now that the OVN networks have picked up their new subnet, route them.
+	return n.pdSetupRoutes()
+}
+
+// pdSetupRoutes routes the delegated prefix subnets of the OVN networks using the bridge as uplink to their
+// router's address on the bridge.
+func (n *bridge) pdSetupRoutes() error {
+	_, bridgeSubnet, err := net.ParseCIDR(IPv6Address(n.config))
+	if err != nil {
+		return nil // No prefix delegated yet.
+	}
+
+	routes, err := pdUplinkRoutes(n.state, n.name, n.config[pdVolatilePrefix], bridgeSubnet)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		r := &ip.Route{
+			DevName: n.name,
+			Proto:   "static",
+			Family:  ip.FamilyV6,
+		}
+
+		err = r.Replace([]string{route.subnet.String(), "via", route.router.String()})
+		if err != nil {
+			return errors.Wrapf(err, "Failed adding route for OVN network %q", route.network)
+		}
+	}
+
+	return nil
+}
+
+// pdClientRelease stops the DHCPv6-PD client running on the interface, unless another running bridge or a physical
+// network still uses it.
+func (n *bridge) pdClientRelease(iface string) error {
+	networks, err := n.state.Cluster.GetCreatedNetworks(project.Default)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range networks {
+		if name == n.name {
+			continue
+		}
+
+		_, netInfo, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, name)
+		if err != nil {
+			return err
+		}
+
+		if netInfo.Type == "physical" && netInfo.Config["ipv6.pd.interface"] == iface {
+			return nil // Still in use.
+		}
+
+		if netInfo.Type == "bridge" && netInfo.Config["ipv6.address"] == pdAddressAuto && netInfo.Config["ipv6.pd.interface"] == iface && InterfaceExists(name) {
+			return nil // Still in use.
+		}
+	}
+
+	return pdClientStop(iface)
+}
+
+// State returns the network state, including the delegated prefix when using IPv6 prefix delegation.
+func (n *bridge) State() (*api.NetworkState, error) {
+	state, err := n.common.State()
+	if err != nil {
+		return nil, err
+	}
+
+	if n.config["ipv6.address"] == pdAddressAuto {
+		state.IPv6PD = &api.NetworkStateIPv6PD{
+			Interface: n.config["ipv6.pd.interface"],
+			Prefix:    n.config[pdVolatilePrefix],
+			Subnet:    IPv6Address(n.config),
+		}
+	}
+
+	return state, nil
+}
+
+// handleDependencyChange refreshes the outbound NAT rules when the routes over the WireGuard network used by the
+// bridge change, so that the NAT exclusions are kept up to date.
+func (n *bridge) handleDependencyChange(netName string, netConfig map[string]string, changedKeys []string) error {
+	if netName != n.config["wireguard.network"] || !n.isRunning() {
+		return nil
+	}
+
+	// The routes over the WireGuard network only affect the outbound NAT exclusions, so only refresh those.
+	if !shared.StringInSlice("wireguard.peers", changedKeys) {
+		return nil
+	}
+
+	snatV4, snatV6, err := n.snatOpts()
+	if err != nil {
+		return err
+	}
+
+	err = n.state.Firewall.NetworkUpdateOutboundNAT(n.name, snatV4, snatV6)
+	if err != nil {
+		return errors.Wrapf(err, "Failed refreshing outbound NAT rules")
+	}
+
+	return nil
+}
+
+// snatOpts returns the outbound NAT settings of the network's IPv4 and IPv6 subnets (nil if not NATed). The subnets
+// reachable over the WireGuard network (if any) are excluded, so that instance addresses are preserved across the
+// tunnel.
+func (n *bridge) snatOpts() (*firewallDrivers.SNATOpts, *firewallDrivers.SNATOpts, error) {
+	var snatV4, snatV6 *firewallDrivers.SNATOpts
+
+	wireguardSubnetsV4, wireguardSubnetsV6, err := n.wireguardSubnets()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if shared.IsTrue(n.config["ipv4.nat"]) {
+		if n.config["bridge.mode"] == "fan" {
+			overlay := n.config["fan.overlay_subnet"]
+			if overlay == "" {
+				overlay = "240.0.0.0/8"
+			}
+
+			_, overlaySubnet, err := net.ParseCIDR(overlay)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "Failed parsing fan.overlay_subnet")
+			}
+
+			snatV4 = &firewallDrivers.SNATOpts{
+				SNATAddress: nil, // Use MASQUERADE mode.
+				Subnet:      overlaySubnet,
+				Exclude:     wireguardSubnetsV4,
+			}
+		} else if !shared.StringInSlice(n.config["ipv4.address"], []string{"", "none"}) {
+			_, subnet, err := net.ParseCIDR(n.config["ipv4.address"])
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "Failed parsing ipv4.address")
+			}
+
+			// If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
+			var srcIP net.IP
+			if n.config["ipv4.nat.address"] != "" {
+				srcIP = net.ParseIP(n.config["ipv4.nat.address"])
+			}
+
+			snatV4 = &firewallDrivers.SNATOpts{
+				SNATAddress: srcIP,
+				Subnet:      subnet,
+				Exclude:     wireguardSubnetsV4,
+			}
+		}
+
+		if snatV4 != nil && n.config["ipv4.nat.order"] == "after" {
+			snatV4.Append = true
+		}
+	}
+
+	if shared.IsTrue(n.config["ipv6.nat"]) && !shared.StringInSlice(IPv6Address(n.config), []string{"", "none"}) {
+		_, subnet, err := net.ParseCIDR(IPv6Address(n.config))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Failed parsing ipv6.address")
+		}
+
+		// If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
+		var srcIP net.IP
+		if n.config["ipv6.nat.address"] != "" {
+			srcIP = net.ParseIP(n.config["ipv6.nat.address"])
+		}
+
+		snatV6 = &firewallDrivers.SNATOpts{
+			SNATAddress: srcIP,
+			Subnet:      subnet,
+			Exclude:     wireguardSubnetsV6,
+		}
+
+		if n.config["ipv6.nat.order"] == "after" {
+			snatV6.Append = true
+		}
+	}
+
+	return snatV4, snatV6, nil
+}
+
+// wireguardSubnets returns the IPv4 and IPv6 subnets routed over the WireGuard network used by the bridge.
+func (n *bridge) wireguardSubnets() ([]*net.IPNet, []*net.IPNet, error) {
+	subnetsV4 := []*net.IPNet{}
+	subnetsV6 := []*net.IPNet{}
+
+	if n.config["wireguard.network"] == "" {
+		return subnetsV4, subnetsV6, nil
+	}
+
+	wgNet, err := LoadByName(n.state, project.Default, n.config["wireguard.network"])
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed loading WireGuard network %q", n.config["wireguard.network"])
+	}
+
+	wireguardNet, ok := wgNet.(*wireguard)
+	if !ok {
+		return nil, nil, fmt.Errorf("Network %q is not of type wireguard", wgNet.Name())
+	}
+
+	subnets, err := wireguardNet.peerSubnets()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed getting WireGuard peer subnets")
+	}
+
+	for _, subnet := range subnets {
+		if subnet.IP.To4() != nil {
+			subnetsV4 = append(subnetsV4, subnet)
+		} else {
+			subnetsV6 = append(subnetsV6, subnet)
+		}
+	}
+
+	return subnetsV4, subnetsV6, nil
+}
+
 // HandleHeartbeat refreshes forkdns servers. Retrieves the IPv4 address of each cluster node (excluding ourselves)
 // for this network. It then updates the forkdns server list file if there are changes.
 func (n *bridge) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
@@ -2160,7 +2569,7 @@ func (n *bridge) hasIPv4Firewall() bool {
 // hasIPv6Firewall indicates whether the network has IPv6 firewall enabled.
 func (n *bridge) hasIPv6Firewall() bool {
 	// IPv6 firewall is only enabled if there is a bridge ipv6.address and ipv6.firewall enabled.
-	if !shared.StringInSlice(n.config["ipv6.address"], []string{"", "none"}) && (n.config["ipv6.firewall"] == "" || shared.IsTrue(n.config["ipv6.firewall"])) {
+	if !shared.StringInSlice(IPv6Address(n.config), []string{"", "none"}) && (n.config["ipv6.firewall"] == "" || shared.IsTrue(n.config["ipv6.firewall"])) {
 		return true
 	}
 
@@ -2237,10 +2646,152 @@ func (n *bridge) DHCPv6Subnet() *net.IPNet {
 		return nil
 	}
 
-	_, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+	_, subnet, err := net.ParseCIDR(IPv6Address(n.config))
 	if err != nil {
 		return nil
 	}
 
 	return subnet
 }
+
+// ReservationCreate creates a DHCP reservation and rebuilds the dnsmasq static allocations.
+// When called from a cluster notification, only the local dnsmasq static allocations are rebuilt.
+func (n *bridge) ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	n.logger.Debug("ReservationCreate", log.Ctx{"clientType": clientType, "reservation": reservation})
+
+	if clientType == request.ClientTypeNotifier {
+		return UpdateDNSMasqStatic(n.state, n.name)
+	}
+
+	reservation.Hwaddr = strings.ToLower(reservation.Hwaddr)
+
+	err := reservationValidate(n.state, n, reservation.Hwaddr, &reservation.NetworkReservationPut, true)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = n.state.Cluster.GetNetworkReservation(n.id, reservation.Hwaddr)
+	if err == nil {
+		return fmt.Errorf("A DHCP reservation for %q already exists", reservation.Hwaddr)
+	} else if err != db.ErrNoSuchObject {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	id, err := n.state.Cluster.CreateNetworkReservation(n.id, &reservation)
+	if err != nil {
+		return errors.Wrapf(err, "Failed creating DHCP reservation record")
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.DeleteNetworkReservation(id)
+		UpdateDNSMasqStatic(n.state, n.name)
+	})
+
+	err = UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	// Notify the other cluster members so they update their dnsmasq static allocations too.
+	err = n.reservationNotify(func(client lxd.InstanceServer) error {
+		return client.UseProject(n.project).CreateNetworkReservation(n.name, reservation)
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationUpdate updates a DHCP reservation and rebuilds the dnsmasq static allocations.
+// When called from a cluster notification, only the local dnsmasq static allocations are rebuilt.
+func (n *bridge) ReservationUpdate(hwaddr string, newReservation api.NetworkReservationPut, clientType request.ClientType) error {
+	n.logger.Debug("ReservationUpdate", log.Ctx{"clientType": clientType, "hwaddr": hwaddr, "newReservation": newReservation})
+
+	if clientType == request.ClientTypeNotifier {
+		return UpdateDNSMasqStatic(n.state, n.name)
+	}
+
+	id, curReservation, err := n.state.Cluster.GetNetworkReservation(n.id, hwaddr)
+	if err != nil {
+		return err
+	}
+
+	err = reservationValidate(n.state, n, hwaddr, &newReservation, true)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.Cluster.UpdateNetworkReservation(id, &newReservation)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.UpdateNetworkReservation(id, &curReservation.NetworkReservationPut)
+		UpdateDNSMasqStatic(n.state, n.name)
+	})
+
+	err = UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	// Notify the other cluster members so they update their dnsmasq static allocations too.
+	err = n.reservationNotify(func(client lxd.InstanceServer) error {
+		return client.UseProject(n.project).UpdateNetworkReservation(n.name, hwaddr, newReservation, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationDelete deletes a DHCP reservation and rebuilds the dnsmasq static allocations.
+// When called from a cluster notification, only the local dnsmasq static allocations are rebuilt.
+func (n *bridge) ReservationDelete(hwaddr string, clientType request.ClientType) error {
+	n.logger.Debug("ReservationDelete", log.Ctx{"clientType": clientType, "hwaddr": hwaddr})
+
+	if clientType == request.ClientTypeNotifier {
+		return UpdateDNSMasqStatic(n.state, n.name)
+	}
+
+	id, _, err := n.state.Cluster.GetNetworkReservation(n.id, hwaddr)
+	if err != nil {
+		return err
+	}
+
+	err = n.state.Cluster.DeleteNetworkReservation(id)
+	if err != nil {
+		return err
+	}
+
+	err = UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	// Notify the other cluster members so they update their dnsmasq static allocations too.
+	return n.reservationNotify(func(client lxd.InstanceServer) error {
+		return client.UseProject(n.project).DeleteNetworkReservation(n.name, hwaddr)
+	})
+}
+
+// reservationNotify sends a DHCP reservation change to the other cluster members.
+func (n *bridge) reservationNotify(hook func(client lxd.InstanceServer) error) error {
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+	if err != nil {
+		return err
+	}
+
+	return notifier(hook)
+}
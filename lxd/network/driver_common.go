@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ import (
 	"github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/resources"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
@@ -159,6 +161,12 @@ func (n *common) Config() map[string]string {
 	return n.config
 }
 
+// IPv6Address returns the IPv6 address (in CIDR notation) of the network, resolving ipv6.address=auto-pd to the
+// address derived from the currently delegated prefix.
+func (n *common) IPv6Address() string {
+	return IPv6Address(n.config)
+}
+
 func (n *common) IsManaged() bool {
 	return n.managed
 }
@@ -231,6 +239,11 @@ func (n *common) DHCPv6Ranges() []shared.IPRange {
 	return dhcpRanges
 }
 
+// State returns the state of the network interface.
+func (n *common) State() (*api.NetworkState, error) {
+	return resources.GetNetworkState(n.name)
+}
+
 // update the internal config variables, and if not cluster notification, notifies all nodes and updates database.
 func (n *common) update(applyNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
 	// Update internal config before database has been updated (so that if update is a notification we apply
@@ -374,6 +387,36 @@ func (n *common) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
 	return nil
 }
 
+// LoadBalancerCreate returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) (net.IP, error) {
+	return nil, ErrNotImplemented
+}
+
+// LoadBalancerUpdate returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerUpdate(listenAddress string, newLoadBalancer api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LoadBalancerDelete returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationCreate returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationUpdate returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) ReservationUpdate(hwaddr string, newReservation api.NetworkReservationPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationDelete returns ErrNotImplemented for drivers that do not support DHCP reservations.
+func (n *common) ReservationDelete(hwaddr string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
 // notifyDependentNetworks allows any dependent networks to apply changes to themselves when this network changes.
 func (n *common) notifyDependentNetworks(changedKeys []string) {
 	if n.Project() != project.Default {
@@ -408,7 +451,7 @@ func (n *common) notifyDependentNetworks(changedKeys []string) {
 				continue // Continue to next network.
 			}
 
-			if depNet.Config()["network"] != n.Name() {
+			if depNet.Config()["network"] != n.Name() && depNet.Config()["wireguard.network"] != n.Name() {
 				continue // Skip network, as does not depend on our network.
 			}
 
@@ -425,3 +468,152 @@ func (n *common) notifyDependentNetworks(changedKeys []string) {
 func (n *common) handleDependencyChange(netName string, netConfig map[string]string, changedKeys []string) error {
 	return nil
 }
+
+// bgpValidationRules returns the validation rules for the BGP peer keys found in config.
+func (n *common) bgpValidationRules(config map[string]string) (map[string]func(value string) error, error) {
+	rules := map[string]func(value string) error{}
+	for k := range config {
+		// BGP peer keys have the peer name in their name, so extract the real key.
+		if !strings.HasPrefix(k, "bgp.peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("Invalid network configuration key: %s", k)
+		}
+
+		// Add the correct validation rule for the dynamic field based on last part of key.
+		switch fields[3] {
+		case "address":
+			rules[k] = validate.Optional(validate.IsNetworkAddress)
+		case "asn":
+			rules[k] = validate.Optional(validate.IsUint32)
+		case "password":
+			rules[k] = validate.Optional(validate.IsAny)
+		}
+	}
+
+	return rules, nil
+}
+
+// bgpPeer represents a BGP peer defined in a network config.
+type bgpPeer struct {
+	address  net.IP
+	asn      uint32
+	password string
+}
+
+// bgpPeers returns the BGP peers defined in config, ignoring those missing an address or ASN.
+func (n *common) bgpPeers(config map[string]string) []bgpPeer {
+	peerNames := []string{}
+	for k := range config {
+		if !strings.HasPrefix(k, "bgp.peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 || shared.StringInSlice(fields[2], peerNames) {
+			continue
+		}
+
+		peerNames = append(peerNames, fields[2])
+	}
+
+	peers := []bgpPeer{}
+	for _, peerName := range peerNames {
+		address := net.ParseIP(config[fmt.Sprintf("bgp.peers.%s.address", peerName)])
+		asn, err := strconv.ParseUint(config[fmt.Sprintf("bgp.peers.%s.asn", peerName)], 10, 32)
+		if address == nil || err != nil || asn == 0 {
+			continue
+		}
+
+		peers = append(peers, bgpPeer{
+			address:  address,
+			asn:      uint32(asn),
+			password: config[fmt.Sprintf("bgp.peers.%s.password", peerName)],
+		})
+	}
+
+	return peers
+}
+
+// bgpSetupPeers adds the BGP peers defined in the network config to the BGP server and removes those that were
+// only defined in oldConfig.
+func (n *common) bgpSetupPeers(oldConfig map[string]string) error {
+	newPeers := n.bgpPeers(n.config)
+
+	if oldConfig != nil {
+		for _, oldPeer := range n.bgpPeers(oldConfig) {
+			found := false
+			for _, newPeer := range newPeers {
+				if newPeer.address.Equal(oldPeer.address) {
+					found = true
+					break
+				}
+			}
+
+			if found {
+				continue
+			}
+
+			err := n.state.BGP.RemovePeer(oldPeer.address, n.bgpOwner())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range newPeers {
+		err := n.state.BGP.AddPeer(p.address, p.asn, p.password, n.bgpOwner())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bgpOwner returns the owner string used for the peers and prefixes of the network.
+func (n *common) bgpOwner() string {
+	return fmt.Sprintf("network_%d", n.id)
+}
+
+// bgpNextHopAddress returns the next-hop override for the IP version from the network config, or nil if unset.
+func (n *common) bgpNextHopAddress(ipVersion uint) net.IP {
+	return net.ParseIP(n.config[fmt.Sprintf("bgp.ipv%d.nexthop", ipVersion)])
+}
+
+// bgpSetupPrefixes announces subnets on behalf of the network, replacing any previously announced prefix.
+func (n *common) bgpSetupPrefixes(subnets []*net.IPNet, nexthopV4 net.IP, nexthopV6 net.IP) error {
+	err := n.state.BGP.RemovePrefixByOwner(n.bgpOwner())
+	if err != nil {
+		return err
+	}
+
+	for _, subnet := range subnets {
+		nexthop := nexthopV4
+		if subnet.IP.To4() == nil {
+			nexthop = nexthopV6
+		}
+
+		err = n.state.BGP.AddPrefix(*subnet, nexthop, n.bgpOwner())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bgpClear removes the BGP peers defined in config and withdraws the prefixes announced on behalf of the network.
+func (n *common) bgpClear(config map[string]string) error {
+	for _, p := range n.bgpPeers(config) {
+		err := n.state.BGP.RemovePeer(p.address, n.bgpOwner())
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.state.BGP.RemovePrefixByOwner(n.bgpOwner())
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/mdlayher/netx/eui64"
 	"github.com/pkg/errors"
 
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/db"
 	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
@@ -199,12 +201,13 @@ func (n *ovn) Validate(config map[string]string) error {
 		}),
 		"ipv4.dhcp": validate.Optional(validate.IsBool),
 		"ipv6.address": validate.Optional(func(value string) error {
-			if validate.IsOneOf(value, []string{"none", "auto"}) == nil {
+			if validate.IsOneOf(value, []string{"none", "auto", pdAddressAuto}) == nil {
 				return nil
 			}
 
 			return validate.IsNetworkAddressCIDRV6(value)
 		}),
+		"ipv6.pd.subnet_id":  validate.Optional(validate.IsUint32),
 		"ipv6.dhcp":          validate.Optional(validate.IsBool),
 		"ipv6.dhcp.stateful": validate.Optional(validate.IsBool),
 		"ipv4.nat":           validate.Optional(validate.IsBool),
@@ -224,6 +227,9 @@ func (n *ovn) Validate(config map[string]string) error {
 		// Volatile keys populated automatically as needed.
 		ovnVolatileUplinkIPv4: validate.Optional(validate.IsNetworkAddressV4),
 		ovnVolatileUplinkIPv6: validate.Optional(validate.IsNetworkAddressV6),
+		pdVolatilePrefix:      validate.Optional(validate.IsNetworkV6),
+		pdVolatileSubnetID:    validate.Optional(validate.IsUint32),
+		pdVolatileAddress:     validate.Optional(validate.IsNetworkAddressCIDRV6),
 	}
 
 	err := n.validate(config, rules)
@@ -259,6 +265,11 @@ func (n *ovn) Validate(config map[string]string) error {
 		return errors.Wrapf(err, "Failed to load uplink network %q", uplinkNetworkName)
 	}
 
+	// Check the uplink can delegate a prefix if needed.
+	if config["ipv6.address"] == pdAddressAuto && pdUplinkInterface(uplink.Type, uplink.Config) == "" {
+		return fmt.Errorf(`Uplink network %q must be a bridge network using "ipv6.address=%s" or a physical network with "ipv6.pd.interface" set`, uplinkNetworkName, pdAddressAuto)
+	}
+
 	uplinkRoutes, err := n.uplinkRoutes(uplink)
 	if err != nil {
 		return err
@@ -274,7 +285,7 @@ func (n *ovn) Validate(config map[string]string) error {
 	var externalSubnets []*net.IPNet
 	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
 		addressKey := fmt.Sprintf("%s.address", keyPrefix)
-		if !shared.IsTrue(config[fmt.Sprintf("%s.nat", keyPrefix)]) && validate.IsOneOf(config[addressKey], []string{"", "none", "auto"}) != nil {
+		if !shared.IsTrue(config[fmt.Sprintf("%s.nat", keyPrefix)]) && validate.IsOneOf(config[addressKey], []string{"", "none", "auto", pdAddressAuto}) != nil {
 			_, ipNet, err := net.ParseCIDR(config[addressKey])
 			if err != nil {
 				return errors.Wrapf(err, "Failed parsing %s", addressKey)
@@ -527,7 +538,53 @@ func (n *ovn) getRouterIntPortIPv4Net() string {
 
 // getRouterIntPortIPv4Net returns OVN logical router internal port IPv6 address and subnet.
 func (n *ovn) getRouterIntPortIPv6Net() string {
-	return n.config["ipv6.address"]
+	return IPv6Address(n.config)
+}
+
+// logicalSwitchSetIPAllocation sets up the IP allocation config of the internal logical switch. The router's
+// internal IPv4 address and the IPv4 addresses of the network's DHCP reservations are excluded from the dynamic
+// allocations.
+func (n *ovn) logicalSwitchSetIPAllocation(client *openvswitch.OVN) error {
+	var err error
+	var routerIntPortIPv4 net.IP
+	var routerIntPortIPv4Net, routerIntPortIPv6Net *net.IPNet
+
+	if validate.IsOneOf(n.getRouterIntPortIPv4Net(), []string{"none", ""}) != nil {
+		routerIntPortIPv4, routerIntPortIPv4Net, err = net.ParseCIDR(n.getRouterIntPortIPv4Net())
+		if err != nil {
+			return errors.Wrapf(err, "Failed parsing router's internal port IPv4 Net")
+		}
+	}
+
+	if validate.IsOneOf(n.getRouterIntPortIPv6Net(), []string{"none", ""}) != nil {
+		_, routerIntPortIPv6Net, err = net.ParseCIDR(n.getRouterIntPortIPv6Net())
+		if err != nil {
+			return errors.Wrapf(err, "Failed parsing router's internal port IPv6 Net")
+		}
+	}
+
+	var excludeIPV4 []shared.IPRange
+	if routerIntPortIPv4 != nil {
+		excludeIPV4 = []shared.IPRange{{Start: routerIntPortIPv4}}
+	}
+
+	reservations, err := n.state.Cluster.GetNetworkReservations(n.id)
+	if err != nil {
+		return errors.Wrapf(err, "Failed loading DHCP reservations")
+	}
+
+	for _, reservation := range reservations {
+		ip := net.ParseIP(reservation.IPv4Address)
+		if ip != nil {
+			excludeIPV4 = append(excludeIPV4, shared.IPRange{Start: ip.To4()})
+		}
+	}
+
+	return client.LogicalSwitchSetIPAllocation(n.getIntSwitchName(), &openvswitch.OVNIPAllocationOpts{
+		PrefixIPv4:  routerIntPortIPv4Net,
+		PrefixIPv6:  routerIntPortIPv6Net,
+		ExcludeIPv4: excludeIPV4,
+	})
 }
 
 // getDomainName returns OVN DHCP domain name.
@@ -578,6 +635,11 @@ func (n *ovn) getIntSwitchInstancePortPrefix() string {
 	return fmt.Sprintf("%s-instance", n.getNetworkPrefix())
 }
 
+// getLoadBalancerName returns OVN load balancer name to use for a listen address.
+func (n *ovn) getLoadBalancerName(listenAddress string) openvswitch.OVNLoadBalancer {
+	return openvswitch.OVNLoadBalancer(fmt.Sprintf("%s-lb-%s", n.getNetworkPrefix(), listenAddress))
+}
+
 // setupUplinkPort initialises the uplink connection. Returns the derived ovnUplinkVars settings used
 // during the initial creation of the logical network.
 func (n *ovn) setupUplinkPort(routerMAC net.HardwareAddr) (*ovnUplinkVars, error) {
@@ -615,6 +677,14 @@ func (n *ovn) setupUplinkPortBridge(uplinkNet Network, routerMAC net.HardwareAdd
 		return nil, errors.Wrapf(err, "Failed allocating uplink port IPs on network %q", uplinkNet.Name())
 	}
 
+	// Route the subnet derived from the delegated prefix to the router's uplink address.
+	if n.config["ipv6.address"] == pdAddressAuto && bridgeNet.isRunning() {
+		err = bridgeNet.pdSetupRoutes()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed adding delegated prefix routes on network %q", uplinkNet.Name())
+		}
+	}
+
 	return v, nil
 }
 
@@ -626,6 +696,16 @@ func (n *ovn) setupUplinkPortPhysical(uplinkNet Network, routerMAC net.HardwareA
 		return nil, errors.Wrapf(err, "Failed allocating uplink port IPs on network %q", uplinkNet.Name())
 	}
 
+	// Route the subnet derived from the delegated prefix to the router's uplink address if the prefix is
+	// delegated on this member, otherwise the delegating member adds the route on its next refresh.
+	physicalNet, ok := uplinkNet.(*physical)
+	if ok && n.config["ipv6.address"] == pdAddressAuto && physicalNet.pdDelegating() {
+		err = physicalNet.pdSetupRoutes()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed adding delegated prefix routes on network %q", uplinkNet.Name())
+		}
+	}
+
 	return v, nil
 }
 
@@ -645,7 +725,7 @@ func (n *ovn) allocateUplinkPortIPs(uplinkNet Network, routerMAC net.HardwareAdd
 		uplinkIPv4CIDR = uplinkNetConf["ipv4.gateway"]
 	}
 
-	uplinkIPv6CIDR := uplinkNetConf["ipv6.address"]
+	uplinkIPv6CIDR := IPv6Address(uplinkNetConf)
 	if uplinkIPv6CIDR == "" {
 		uplinkIPv6CIDR = uplinkNetConf["ipv6.gateway"]
 	}
@@ -802,6 +882,25 @@ func (n *ovn) uplinkAllAllocatedIPs(tx *db.ClusterTx, uplinkNetName string) ([]n
 		}
 	}
 
+	// Load balancer listen addresses are allocated from the same ranges.
+	listenAddresses, err := tx.GetNetworkLoadBalancerListenAddressesOnUplink(uplinkNetName)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed to load network load balancers")
+	}
+
+	for _, listenAddress := range listenAddresses {
+		ip := net.ParseIP(listenAddress)
+		if ip == nil {
+			continue
+		}
+
+		if ip.To4() != nil {
+			v4IPs = append(v4IPs, ip)
+		} else {
+			v6IPs = append(v6IPs, ip)
+		}
+	}
+
 	return v4IPs, v6IPs, nil
 }
 
@@ -1526,6 +1625,26 @@ func (n *ovn) setup(update bool) error {
 		updatedConfig["network"] = uplinkNetwork
 	}
 
+	// Derive the IPv6 subnet from the prefix delegated to the uplink network if needed.
+	if n.config["ipv6.address"] == pdAddressAuto {
+		_, uplink, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, uplinkNetwork)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to load uplink network %q", uplinkNetwork)
+		}
+
+		// Use the prefix recorded in the uplink's config, so that all the cluster members agree on it.
+		pdKeys, err := pdConfig(n.state, pdUplinkInterface(uplink.Type, uplink.Config), pdUplinkPrefix(uplink.Config), n.project, n.name, n.config)
+		if err != nil {
+			return errors.Wrapf(err, "Failed getting delegated prefix subnet")
+		}
+
+		for k, v := range pdKeys {
+			if n.config[k] != v {
+				updatedConfig[k] = v
+			}
+		}
+	}
+
 	// Get bridge MTU to use.
 	bridgeMTU := n.getBridgeMTU()
 	if bridgeMTU == 0 {
@@ -1755,17 +1874,8 @@ func (n *ovn) setup(update bool) error {
 		revert.Add(func() { client.LogicalSwitchDelete(n.getIntSwitchName()) })
 	}
 
-	var excludeIPV4 []shared.IPRange
-	if routerIntPortIPv4 != nil {
-		excludeIPV4 = []shared.IPRange{{Start: routerIntPortIPv4}}
-	}
-
 	// Setup IP allocation config on logical switch.
-	err = client.LogicalSwitchSetIPAllocation(n.getIntSwitchName(), &openvswitch.OVNIPAllocationOpts{
-		PrefixIPv4:  routerIntPortIPv4Net,
-		PrefixIPv6:  routerIntPortIPv6Net,
-		ExcludeIPv4: excludeIPV4,
-	})
+	err = n.logicalSwitchSetIPAllocation(client)
 	if err != nil {
 		return errors.Wrapf(err, "Failed setting IP allocation settings on internal switch")
 	}
@@ -2091,6 +2201,22 @@ func (n *ovn) Delete(clientType request.ClientType) error {
 			return errors.Wrapf(err, "Failed to get OVN client")
 		}
 
+		// Delete the load balancers (the database records are removed with the network).
+		listenAddresses, err := n.state.Cluster.GetNetworkLoadBalancerListenAddresses(n.id)
+		if err != nil {
+			return errors.Wrapf(err, "Failed loading network load balancers")
+		}
+
+		loadBalancers := make([]openvswitch.OVNLoadBalancer, 0, len(listenAddresses))
+		for _, listenAddress := range listenAddresses {
+			loadBalancers = append(loadBalancers, n.getLoadBalancerName(listenAddress))
+		}
+
+		err = client.LoadBalancerDelete(loadBalancers...)
+		if err != nil {
+			return err
+		}
+
 		err = client.LogicalRouterDelete(n.getRouterName())
 		if err != nil {
 			return err
@@ -2211,6 +2337,61 @@ func (n *ovn) start() error {
 		return err
 	}
 
+	// Setup BGP.
+	err = n.bgpSetup()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bgpSetup announces the subnets of the network which aren't NATed and the load balancer listen addresses, using
+// the OVN router's uplink address as the next-hop.
+func (n *ovn) bgpSetup() error {
+	subnets := []*net.IPNet{}
+	for _, ipVersion := range []uint{4, 6} {
+		address := n.config[fmt.Sprintf("ipv%d.address", ipVersion)]
+		if ipVersion == 6 {
+			address = IPv6Address(n.config)
+		}
+
+		if validate.IsOneOf(address, []string{"", "none"}) == nil || shared.IsTrue(n.config[fmt.Sprintf("ipv%d.nat", ipVersion)]) {
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(address)
+		if err != nil {
+			continue
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	// Announce the load balancer listen addresses.
+	listenAddresses, err := n.state.Cluster.GetNetworkLoadBalancerListenAddresses(n.id)
+	if err != nil {
+		return errors.Wrapf(err, "Failed loading network load balancers")
+	}
+
+	for _, listenAddress := range listenAddresses {
+		ip := net.ParseIP(listenAddress)
+		if ip == nil {
+			continue
+		}
+
+		if ip.To4() != nil {
+			subnets = append(subnets, &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)})
+		} else {
+			subnets = append(subnets, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+		}
+	}
+
+	err = n.bgpSetupPrefixes(subnets, net.ParseIP(n.config[ovnVolatileUplinkIPv4]), net.ParseIP(n.config[ovnVolatileUplinkIPv6]))
+	if err != nil {
+		return errors.Wrapf(err, "Failed announcing BGP prefixes")
+	}
+
 	return nil
 }
 
@@ -2231,6 +2412,12 @@ func (n *ovn) Stop() error {
 		return err
 	}
 
+	// Withdraw the BGP prefixes.
+	err = n.bgpClear(n.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2288,6 +2475,16 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		delete(newNetwork.Config, ovnVolatileUplinkIPv6)
 	}
 
+	// Keep the prefix delegation state if still using the same delegated prefix, otherwise clear it.
+	samePD := newNetwork.Config["ipv6.address"] == pdAddressAuto && oldNetwork.Config["ipv6.address"] == pdAddressAuto && !shared.StringInSlice("network", changedKeys) && newNetwork.Config["ipv6.pd.subnet_id"] == oldNetwork.Config["ipv6.pd.subnet_id"]
+	for _, k := range []string{pdVolatilePrefix, pdVolatileSubnetID, pdVolatileAddress} {
+		if !samePD {
+			delete(newNetwork.Config, k)
+		} else if newNetwork.Config[k] == "" && oldNetwork.Config[k] != "" {
+			newNetwork.Config[k] = oldNetwork.Config[k]
+		}
+	}
+
 	// Apply changes to all nodes and databse.
 	err = n.common.update(newNetwork, targetNode, clientType)
 	if err != nil {
@@ -2469,6 +2666,12 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		}
 	}
 
+	// Update the BGP announcements.
+	err = n.bgpSetup()
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -2638,15 +2841,32 @@ func (n *ovn) InstanceDevicePortSetup(opts *OVNInstanceNICSetupOpts, securityACL
 		return "", err
 	}
 
+	// Use the addresses reserved for the NIC's MAC address (if any) when not statically configured.
+	reservation, err := Reservation(n.state, n, mac.String())
+	if err != nil {
+		return "", err
+	}
+
+	reserved := map[string]string{}
+	if reservation != nil {
+		reserved["ipv4.address"] = reservation.IPv4Address
+		reserved["ipv6.address"] = reservation.IPv6Address
+	}
+
 	ips := []net.IP{}
 	for _, key := range []string{"ipv4.address", "ipv6.address"} {
-		if opts.DeviceConfig[key] == "" {
+		address := opts.DeviceConfig[key]
+		if address == "" {
+			address = reserved[key]
+		}
+
+		if address == "" {
 			continue
 		}
 
-		ip := net.ParseIP(opts.DeviceConfig[key])
+		ip := net.ParseIP(address)
 		if ip == nil {
-			return "", fmt.Errorf("Invalid %s value %q", key, opts.DeviceConfig[key])
+			return "", fmt.Errorf("Invalid %s value %q", key, address)
 		}
 		ips = append(ips, ip)
 	}
@@ -3270,10 +3490,16 @@ func (n *ovn) uplinkHasIngressRoutedAnycastIPv6(uplink *api.Network) bool {
 // handleDependencyChange applies changes from uplink network if specific watched keys have changed.
 func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]string, changedKeys []string) error {
 	// Detect changes that need to be applied to the network.
-	for _, k := range []string{"dns.nameservers"} {
+	for _, k := range []string{"dns.nameservers", pdVolatilePrefix} {
 		if shared.StringInSlice(k, changedKeys) {
 			n.logger.Debug("Applying changes from uplink network", log.Ctx{"uplink": uplinkName})
 
+			// The subnet of a bridge uplink changes along with its delegated prefix, so a new uplink
+			// address needs allocating for the router.
+			if shared.StringInSlice(pdVolatilePrefix, changedKeys) && uplinkConfig["ipv6.address"] == pdAddressAuto {
+				n.config[ovnVolatileUplinkIPv6] = ""
+			}
+
 			// Re-setup logical network in order to apply uplink changes.
 			err := n.setup(true)
 			if err != nil {
@@ -3366,3 +3592,649 @@ func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]
 
 	return nil
 }
+
+// loadBalancerListenAddress validates the requested load balancer listen address against the uplink network.
+// If no listen address is requested, a free IPv4 address is allocated from the uplink's "ipv4.ovn.ranges".
+// The uplink network operation lock must be held by the caller.
+func (n *ovn) loadBalancerListenAddress(uplinkNet Network, listenAddress string) (net.IP, error) {
+	var err error
+	var p *api.Project
+	var projectNetworks map[string]map[int64]api.Network
+	var allAllocatedIPv4, allAllocatedIPv6 []net.IP
+
+	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		allAllocatedIPv4, allAllocatedIPv6, err = n.uplinkAllAllocatedIPs(tx, uplinkNet.Name())
+		if err != nil {
+			return errors.Wrapf(err, "Failed to get all allocated IPs for uplink")
+		}
+
+		// Load the project to get uplink network restrictions.
+		p, err = tx.GetProject(n.project)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to load network restrictions from project %q", n.project)
+		}
+
+		// Get all managed networks across all projects.
+		projectNetworks, err = tx.GetCreatedNetworks()
+		if err != nil {
+			return errors.Wrapf(err, "Failed to load all networks")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uplinkConfig := uplinkNet.Config()
+
+	// Allocate an address from the uplink's OVN ranges if none requested.
+	if listenAddress == "" {
+		if uplinkConfig["ipv4.ovn.ranges"] == "" {
+			return nil, fmt.Errorf(`A listen address must be specified as the uplink network has no "ipv4.ovn.ranges"`)
+		}
+
+		ipRanges, err := parseIPRanges(uplinkConfig["ipv4.ovn.ranges"], uplinkNet.DHCPv4Subnet())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse uplink IPv4 OVN ranges")
+		}
+
+		ip, err := n.uplinkAllocateIP(ipRanges, allAllocatedIPv4)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to allocate load balancer listen address")
+		}
+
+		return ip, nil
+	}
+
+	ip := net.ParseIP(listenAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("Invalid listen address %q", listenAddress)
+	}
+
+	allAllocated := allAllocatedIPv4
+	if ip.To4() == nil {
+		allAllocated = allAllocatedIPv6
+	}
+
+	for _, allocatedIP := range allAllocated {
+		if ip.Equal(allocatedIP) {
+			return nil, fmt.Errorf("Listen address %q is already in use", ip.String())
+		}
+	}
+
+	// Addresses within the uplink's OVN ranges are directly reachable on the uplink network.
+	for _, k := range []string{"ipv4.ovn.ranges", "ipv6.ovn.ranges"} {
+		if uplinkConfig[k] == "" {
+			continue
+		}
+
+		ipRanges, err := parseIPRanges(uplinkConfig[k])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse uplink %q", k)
+		}
+
+		for _, ipRange := range ipRanges {
+			if ipRange.ContainsIP(ip) {
+				return ip, nil
+			}
+		}
+	}
+
+	// Otherwise the address must be within the external routes allowed on the uplink and not overlap with
+	// the external subnets and routes used by other OVN networks and NICs on the same uplink.
+	_, uplink, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, uplinkNet.Name())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load uplink network %q", uplinkNet.Name())
+	}
+
+	uplinkRoutes, err := n.uplinkRoutes(uplink)
+	if err != nil {
+		return nil, err
+	}
+
+	projectRestrictedSubnets, err := n.projectRestrictedSubnets(p, uplinkNet.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	ipNet := &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+	if ip.To4() != nil {
+		ipNet = &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+	}
+
+	err = n.validateExternalSubnet(uplinkRoutes, projectRestrictedSubnets, ipNet)
+	if err != nil {
+		return nil, err
+	}
+
+	ovnProjectNetworksWithOurUplink := n.ovnProjectNetworksWithUplink(uplinkNet.Name(), projectNetworks)
+
+	ovnNetworkExternalSubnets, err := n.ovnNetworkExternalSubnets("", "", ovnProjectNetworksWithOurUplink, uplinkRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	ovnNICExternalRoutes, err := n.ovnNICExternalRoutes(nil, "", ovnProjectNetworksWithOurUplink)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, externalSubnet := range append(ovnNetworkExternalSubnets, ovnNICExternalRoutes...) {
+		if externalSubnet.Contains(ip) {
+			// This error is purposefully vague so that it doesn't reveal any names of resources
+			// potentially outside of the network's project.
+			return nil, fmt.Errorf("Listen address %q overlaps with another OVN network or NIC", ip.String())
+		}
+	}
+
+	return ip, nil
+}
+
+// loadBalancerVIPs validates the load balancer configuration, backends and ports, and returns the OVN virtual IPs.
+func (n *ovn) loadBalancerVIPs(listenAddress net.IP, loadBalancer *api.NetworkLoadBalancerPut) ([]openvswitch.OVNLoadBalancerVIP, error) {
+	rules := map[string]func(value string) error{
+		"healthcheck":               validate.Optional(validate.IsBool),
+		"healthcheck.interval":      validate.Optional(validate.IsUint32),
+		"healthcheck.timeout":       validate.Optional(validate.IsUint32),
+		"healthcheck.failure_count": validate.Optional(validate.IsUint32),
+		"healthcheck.success_count": validate.Optional(validate.IsUint32),
+	}
+
+	for k, v := range loadBalancer.Config {
+		if strings.HasPrefix(k, "user.") {
+			continue
+		}
+
+		validator, ok := rules[k]
+		if !ok {
+			return nil, fmt.Errorf("Invalid load balancer configuration key %q", k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid value for load balancer configuration key %q", k)
+		}
+	}
+
+	if shared.IsTrue(loadBalancer.Config["healthcheck"]) && listenAddress.To4() == nil {
+		return nil, fmt.Errorf("Health checking is only supported for IPv4 load balancers")
+	}
+
+	// Get the network subnet matching the listen address family, backends must be within it.
+	subnetKey := "ipv4.address"
+	if listenAddress.To4() == nil {
+		subnetKey = "ipv6.address"
+	}
+
+	subnetAddress := n.config[subnetKey]
+	if subnetKey == "ipv6.address" {
+		subnetAddress = IPv6Address(n.config)
+	}
+
+	_, subnet, _ := net.ParseCIDR(subnetAddress)
+
+	backendPorts := make(map[string][]uint64, len(loadBalancer.Backends))
+	for _, backend := range loadBalancer.Backends {
+		if backend.Name == "" {
+			return nil, fmt.Errorf("Load balancer backend name is required")
+		}
+
+		_, found := backendPorts[backend.Name]
+		if found {
+			return nil, fmt.Errorf("Duplicate load balancer backend name %q", backend.Name)
+		}
+
+		targetAddress := net.ParseIP(backend.TargetAddress)
+		if targetAddress == nil {
+			return nil, fmt.Errorf("Invalid target address %q for backend %q", backend.TargetAddress, backend.Name)
+		}
+
+		if subnet == nil || !subnet.Contains(targetAddress) {
+			return nil, fmt.Errorf("Target address %q for backend %q isn't within the network's %q subnet", targetAddress.String(), backend.Name, subnetKey)
+		}
+
+		targetPorts, err := parsePortList(backend.TargetPort)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid target port for backend %q", backend.Name)
+		}
+
+		backendPorts[backend.Name] = targetPorts
+	}
+
+	vips := []openvswitch.OVNLoadBalancerVIP{}
+	listenPortsUsed := map[string]struct{}{}
+	for portIndex, port := range loadBalancer.Ports {
+		if !shared.StringInSlice(port.Protocol, []string{"tcp", "udp"}) {
+			return nil, fmt.Errorf("Invalid protocol %q for port %d, must be tcp or udp", port.Protocol, portIndex)
+		}
+
+		listenPorts, err := parsePortList(port.ListenPort)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid listen port for port %d", portIndex)
+		}
+
+		if len(listenPorts) <= 0 {
+			return nil, fmt.Errorf("Listen port is required for port %d", portIndex)
+		}
+
+		if len(port.TargetBackend) <= 0 {
+			return nil, fmt.Errorf("At least one target backend is required for port %d", portIndex)
+		}
+
+		for _, backendName := range port.TargetBackend {
+			targetPorts, found := backendPorts[backendName]
+			if !found {
+				return nil, fmt.Errorf("Unknown target backend %q for port %d", backendName, portIndex)
+			}
+
+			if len(targetPorts) > 1 && len(targetPorts) != len(listenPorts) {
+				return nil, fmt.Errorf("Target backend %q must have a single target port or as many as the listen ports of port %d", backendName, portIndex)
+			}
+		}
+
+		for i, listenPort := range listenPorts {
+			portKey := fmt.Sprintf("%s/%d", port.Protocol, listenPort)
+			_, found := listenPortsUsed[portKey]
+			if found {
+				return nil, fmt.Errorf("Duplicate listen port %d/%s", listenPort, port.Protocol)
+			}
+
+			listenPortsUsed[portKey] = struct{}{}
+
+			vip := openvswitch.OVNLoadBalancerVIP{
+				Protocol:      port.Protocol,
+				ListenAddress: listenAddress,
+				ListenPort:    listenPort,
+			}
+
+			for _, backend := range loadBalancer.Backends {
+				if !shared.StringInSlice(backend.Name, port.TargetBackend) {
+					continue
+				}
+
+				// Default to the listen port if no target port is specified.
+				targetPort := listenPort
+				targetPorts := backendPorts[backend.Name]
+				if len(targetPorts) == 1 {
+					targetPort = targetPorts[0]
+				} else if len(targetPorts) > 1 {
+					targetPort = targetPorts[i]
+				}
+
+				vip.Targets = append(vip.Targets, openvswitch.OVNLoadBalancerTarget{
+					Address: net.ParseIP(backend.TargetAddress),
+					Port:    targetPort,
+				})
+			}
+
+			vips = append(vips, vip)
+		}
+	}
+
+	return vips, nil
+}
+
+// loadBalancerApply programs the OVN load balancer for the listen address.
+func (n *ovn) loadBalancerApply(listenAddress net.IP, loadBalancer *api.NetworkLoadBalancerPut) error {
+	vips, err := n.loadBalancerVIPs(listenAddress, loadBalancer)
+	if err != nil {
+		return err
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get OVN client")
+	}
+
+	var healthCheck *openvswitch.OVNLoadBalancerHealthCheck
+	if shared.IsTrue(loadBalancer.Config["healthcheck"]) {
+		// Health check probes are sent from the router's internal address.
+		routerIntPortIPv4, _, err := net.ParseCIDR(n.getRouterIntPortIPv4Net())
+		if err != nil {
+			return fmt.Errorf("Health checking requires the network to have an IPv4 address")
+		}
+
+		configValue := func(key string, defaultValue uint64) uint64 {
+			value, err := strconv.ParseUint(loadBalancer.Config[key], 10, 64)
+			if err != nil {
+				return defaultValue
+			}
+
+			return value
+		}
+
+		healthCheck = &openvswitch.OVNLoadBalancerHealthCheck{
+			SourceAddress: routerIntPortIPv4,
+			Interval:      configValue("healthcheck.interval", 10),
+			Timeout:       configValue("healthcheck.timeout", 30),
+			FailureCount:  configValue("healthcheck.failure_count", 3),
+			SuccessCount:  configValue("healthcheck.success_count", 3),
+		}
+
+		// OVN needs to know which logical switch port each target is on to probe it.
+		portIPs, err := client.LogicalSwitchPortIPs(n.getIntSwitchName())
+		if err != nil {
+			return errors.Wrapf(err, "Failed getting logical switch port IPs")
+		}
+
+		for vipIndex := range vips {
+			for targetIndex, target := range vips[vipIndex].Targets {
+				for portName, ips := range portIPs {
+					for _, ip := range ips {
+						if ip.Equal(target.Address) {
+							vips[vipIndex].Targets[targetIndex].SwitchPort = portName
+						}
+					}
+				}
+			}
+		}
+	}
+
+	routers := []openvswitch.OVNRouter{n.getRouterName()}
+	switches := []openvswitch.OVNSwitch{n.getIntSwitchName()}
+
+	err = client.LoadBalancerApply(n.getLoadBalancerName(listenAddress.String()), routers, switches, healthCheck, vips...)
+	if err != nil {
+		return errors.Wrapf(err, "Failed applying OVN load balancer")
+	}
+
+	return nil
+}
+
+// LoadBalancerCreate creates a network load balancer and returns its listen address.
+// When called from a cluster notification, only the BGP announcements are refreshed.
+func (n *ovn) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) (net.IP, error) {
+	n.logger.Debug("LoadBalancerCreate", log.Ctx{"clientType": clientType, "loadBalancer": loadBalancer})
+
+	if clientType == request.ClientTypeNotifier {
+		return net.ParseIP(loadBalancer.ListenAddress), n.bgpSetup()
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Uplink network must be in default project.
+	uplinkNet, err := LoadByName(n.state, project.Default, n.config["network"])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading uplink network %q", n.config["network"])
+	}
+
+	// Lock uplink network so that concurrent requests don't allocate the same listen address.
+	unlock := locking.Lock(n.uplinkOperationLockName(uplinkNet))
+	defer unlock()
+
+	listenAddress, err := n.loadBalancerListenAddress(uplinkNet, loadBalancer.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	loadBalancer.ListenAddress = listenAddress.String()
+
+	_, err = n.loadBalancerVIPs(listenAddress, &loadBalancer.NetworkLoadBalancerPut)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := n.state.Cluster.CreateNetworkLoadBalancer(n.id, &loadBalancer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating load balancer record")
+	}
+
+	revert.Add(func() { n.state.Cluster.DeleteNetworkLoadBalancer(id) })
+
+	err = n.loadBalancerApply(listenAddress, &loadBalancer.NetworkLoadBalancerPut)
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Add(func() {
+		client, err := openvswitch.NewOVN(n.state)
+		if err == nil {
+			client.LoadBalancerDelete(n.getLoadBalancerName(listenAddress.String()))
+		}
+	})
+
+	err = n.bgpSetup()
+	if err != nil {
+		return nil, err
+	}
+
+	// Notify the other cluster members so they announce the listen address too.
+	err = n.loadBalancerNotify(func(client lxd.InstanceServer) error {
+		_, err := client.UseProject(n.project).CreateNetworkLoadBalancer(n.name, loadBalancer)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Success()
+	return listenAddress, nil
+}
+
+// LoadBalancerUpdate updates a network load balancer.
+// When called from a cluster notification, only the BGP announcements are refreshed.
+func (n *ovn) LoadBalancerUpdate(listenAddress string, newLoadBalancer api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	n.logger.Debug("LoadBalancerUpdate", log.Ctx{"clientType": clientType, "listenAddress": listenAddress, "newLoadBalancer": newLoadBalancer})
+
+	if clientType == request.ClientTypeNotifier {
+		return n.bgpSetup()
+	}
+
+	id, curLoadBalancer, err := n.state.Cluster.GetNetworkLoadBalancer(n.id, listenAddress)
+	if err != nil {
+		return err
+	}
+
+	listenIP := net.ParseIP(curLoadBalancer.ListenAddress)
+
+	_, err = n.loadBalancerVIPs(listenIP, &newLoadBalancer)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.Cluster.UpdateNetworkLoadBalancer(id, &newLoadBalancer)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.UpdateNetworkLoadBalancer(id, &curLoadBalancer.NetworkLoadBalancerPut)
+		n.loadBalancerApply(listenIP, &curLoadBalancer.NetworkLoadBalancerPut)
+	})
+
+	err = n.loadBalancerApply(listenIP, &newLoadBalancer)
+	if err != nil {
+		return err
+	}
+
+	err = n.bgpSetup()
+	if err != nil {
+		return err
+	}
+
+	// Notify the other cluster members so they refresh their view of the load balancer too.
+	err = n.loadBalancerNotify(func(client lxd.InstanceServer) error {
+		return client.UseProject(n.project).UpdateNetworkLoadBalancer(n.name, curLoadBalancer.ListenAddress, newLoadBalancer, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// LoadBalancerDelete deletes a network load balancer.
+// When called from a cluster notification, only the BGP announcements are refreshed.
+func (n *ovn) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	n.logger.Debug("LoadBalancerDelete", log.Ctx{"clientType": clientType, "listenAddress": listenAddress})
+
+	if clientType == request.ClientTypeNotifier {
+		return n.bgpSetup()
+	}
+
+	id, loadBalancer, err := n.state.Cluster.GetNetworkLoadBalancer(n.id, listenAddress)
+	if err != nil {
+		return err
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get OVN client")
+	}
+
+	err = client.LoadBalancerDelete(n.getLoadBalancerName(loadBalancer.ListenAddress))
+	if err != nil {
+		return errors.Wrapf(err, "Failed deleting OVN load balancer")
+	}
+
+	err = n.state.Cluster.DeleteNetworkLoadBalancer(id)
+	if err != nil {
+		return err
+	}
+
+	err = n.bgpSetup()
+	if err != nil {
+		return err
+	}
+
+	// Notify the other cluster members so they withdraw the listen address too.
+	return n.loadBalancerNotify(func(client lxd.InstanceServer) error {
+		return client.UseProject(n.project).DeleteNetworkLoadBalancer(n.name, loadBalancer.ListenAddress)
+	})
+}
+
+// loadBalancerNotify sends a load balancer change to the other cluster members.
+func (n *ovn) loadBalancerNotify(hook func(client lxd.InstanceServer) error) error {
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+	if err != nil {
+		return err
+	}
+
+	return notifier(hook)
+}
+
+// ReservationCreate creates a DHCP reservation and excludes its IPv4 address from the dynamic allocations.
+// The reserved addresses are used by the instance NICs with the reservation's MAC address when next started.
+func (n *ovn) ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	n.logger.Debug("ReservationCreate", log.Ctx{"clientType": clientType, "reservation": reservation})
+
+	if clientType == request.ClientTypeNotifier {
+		return nil
+	}
+
+	reservation.Hwaddr = strings.ToLower(reservation.Hwaddr)
+
+	err := reservationValidate(n.state, n, reservation.Hwaddr, &reservation.NetworkReservationPut, false)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = n.state.Cluster.GetNetworkReservation(n.id, reservation.Hwaddr)
+	if err == nil {
+		return fmt.Errorf("A DHCP reservation for %q already exists", reservation.Hwaddr)
+	} else if err != db.ErrNoSuchObject {
+		return err
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get OVN client")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	id, err := n.state.Cluster.CreateNetworkReservation(n.id, &reservation)
+	if err != nil {
+		return errors.Wrapf(err, "Failed creating DHCP reservation record")
+	}
+
+	revert.Add(func() { n.state.Cluster.DeleteNetworkReservation(id) })
+
+	err = n.logicalSwitchSetIPAllocation(client)
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting IP allocation settings on internal switch")
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationUpdate updates a DHCP reservation and the dynamic allocation exclusions.
+func (n *ovn) ReservationUpdate(hwaddr string, newReservation api.NetworkReservationPut, clientType request.ClientType) error {
+	n.logger.Debug("ReservationUpdate", log.Ctx{"clientType": clientType, "hwaddr": hwaddr, "newReservation": newReservation})
+
+	if clientType == request.ClientTypeNotifier {
+		return nil
+	}
+
+	id, curReservation, err := n.state.Cluster.GetNetworkReservation(n.id, hwaddr)
+	if err != nil {
+		return err
+	}
+
+	err = reservationValidate(n.state, n, hwaddr, &newReservation, false)
+	if err != nil {
+		return err
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get OVN client")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.Cluster.UpdateNetworkReservation(id, &newReservation)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { n.state.Cluster.UpdateNetworkReservation(id, &curReservation.NetworkReservationPut) })
+
+	err = n.logicalSwitchSetIPAllocation(client)
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting IP allocation settings on internal switch")
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationDelete deletes a DHCP reservation and its dynamic allocation exclusion.
+func (n *ovn) ReservationDelete(hwaddr string, clientType request.ClientType) error {
+	n.logger.Debug("ReservationDelete", log.Ctx{"clientType": clientType, "hwaddr": hwaddr})
+
+	if clientType == request.ClientTypeNotifier {
+		return nil
+	}
+
+	id, _, err := n.state.Cluster.GetNetworkReservation(n.id, hwaddr)
+	if err != nil {
+		return err
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get OVN client")
+	}
+
+	err = n.state.Cluster.DeleteNetworkReservation(id)
+	if err != nil {
+		return err
+	}
+
+	err = n.logicalSwitchSetIPAllocation(client)
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting IP allocation settings on internal switch")
+	}
+
+	return nil
+}
@@ -55,10 +55,25 @@ func (n *physical) Validate(config map[string]string) error {
 		"ovn.ingress_mode": validate.Optional(func(value string) error {
 			return validate.IsOneOf(value, []string{"l2proxy", "routed"})
 		}),
+		"ipv6.pd.interface":           validate.Optional(validate.IsInterfaceName),
+		"ipv6.pd.prefix_length":       validate.Optional(pdValidatePrefixLength),
 		"volatile.last_state.created": validate.Optional(validate.IsBool),
+
+		// Volatile keys populated automatically as needed.
+		pdVolatilePrefix: validate.Optional(validate.IsNetworkV6),
 	}
 
-	err := n.validate(config, rules)
+	// Add the BGP validation rules.
+	bgpRules, err := n.bgpValidationRules(config)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range bgpRules {
+		rules[k] = v
+	}
+
+	err = n.validate(config, rules)
 	if err != nil {
 		return err
 	}
@@ -132,6 +147,13 @@ func (n *physical) Delete(clientType request.ClientType) error {
 		return err
 	}
 
+	if n.config["ipv6.pd.interface"] != "" {
+		err = n.pdRelease(n.config["ipv6.pd.interface"])
+		if err != nil {
+			return err
+		}
+	}
+
 	return n.common.delete(clientType)
 }
 
@@ -202,6 +224,12 @@ func (n *physical) start() error {
 		}
 	}
 
+	// Setup BGP.
+	err = n.bgpSetupPeers(nil)
+	if err != nil {
+		return errors.Wrapf(err, "Failed setting up BGP peers")
+	}
+
 	revert.Success()
 	return nil
 }
@@ -230,9 +258,15 @@ func (n *physical) Stop() error {
 		}
 	}
 
+	// Clear BGP.
+	err := n.bgpClear(n.config)
+	if err != nil {
+		return err
+	}
+
 	// Remove last state config.
 	delete(n.config, "volatile.last_state.created")
-	err := n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
 		return tx.UpdateNetwork(n.id, n.description, n.config)
 	})
 	if err != nil {
@@ -247,6 +281,15 @@ func (n *physical) Stop() error {
 func (n *physical) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
 	n.logger.Debug("Update", log.Ctx{"clientType": clientType, "newNetwork": newNetwork})
 
+	// Keep the delegated prefix if still delegated on the same interface, otherwise clear it.
+	if newNetwork.Config["ipv6.pd.interface"] != "" && newNetwork.Config["ipv6.pd.interface"] == n.config["ipv6.pd.interface"] {
+		if newNetwork.Config[pdVolatilePrefix] == "" && n.config[pdVolatilePrefix] != "" {
+			newNetwork.Config[pdVolatilePrefix] = n.config[pdVolatilePrefix]
+		}
+	} else {
+		delete(newNetwork.Config, pdVolatilePrefix)
+	}
+
 	dbUpdateNeeeded, changedKeys, oldNetwork, err := n.common.configChanged(newNetwork)
 	if err != nil {
 		return err
@@ -296,6 +339,14 @@ func (n *physical) Update(newNetwork api.NetworkPut, targetNode string, clientTy
 		delete(newNetwork.Config, "volatile.last_state.created")
 	}
 
+	// Stop delegating the prefix on the old interface, the leader requests a new one on the next refresh.
+	if oldNetwork.Config["ipv6.pd.interface"] != "" && shared.StringInSlice("ipv6.pd.interface", changedKeys) {
+		err = n.pdRelease(oldNetwork.Config["ipv6.pd.interface"])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Define a function which reverts everything.
 	revert.Add(func() {
 		// Reset changes to all nodes and database.
@@ -313,6 +364,12 @@ func (n *physical) Update(newNetwork api.NetworkPut, targetNode string, clientTy
 		return err
 	}
 
+	// Remove the BGP peers which are no longer defined.
+	err = n.bgpSetupPeers(oldNetwork.Config)
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 
 	// Notify dependent networks (those using this network as their uplink) of the changes.
@@ -344,3 +401,103 @@ func (n *physical) DHCPv6Subnet() *net.IPNet {
 
 	return subnet
 }
+
+// pdRefresh runs the DHCPv6-PD client on ipv6.pd.interface and records the delegated prefix into the volatile
+// config, shared by all the cluster members, so that the OVN networks using the network as uplink can take their
+// subnet from it. The dependent OVN networks are reconfigured when the prefix changes and their subnets are routed.
+func (n *physical) pdRefresh() error {
+	iface := n.config["ipv6.pd.interface"]
+
+	err := pdClientStart(iface, n.config["ipv6.pd.prefix_length"])
+	if err != nil {
+		return err
+	}
+
+	prefix, err := pdPrefix(iface)
+	if err != nil {
+		return err
+	}
+
+	// Keep the last delegated prefix until a new one is received, for instance after the leader has changed.
+	if prefix != nil && prefix.String() != n.config[pdVolatilePrefix] {
+		n.logger.Info("Delegated prefix has changed", log.Ctx{"prefix": prefix.String()})
+
+		n.config[pdVolatilePrefix] = prefix.String()
+		err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			return tx.UpdateNetwork(n.id, n.description, n.config)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Failed saving volatile config")
+		}
+
+		n.common.notifyDependentNetworks([]string{pdVolatilePrefix})
+	}
+
+	return n.pdSetupRoutes()
+}
+
+// pdDelegating returns true if the DHCPv6-PD client of the network runs on the local member.
+func (n *physical) pdDelegating() bool {
+	return n.config["ipv6.pd.interface"] != "" && shared.PathExists(pdPath(n.config["ipv6.pd.interface"], "dhclient.pid"))
+}
+
+// pdSetupRoutes routes the delegated prefix subnets of the OVN networks using the network as uplink to their
+// router's address, through ipv6.pd.interface.
+func (n *physical) pdSetupRoutes() error {
+	_, gatewaySubnet, err := net.ParseCIDR(n.config["ipv6.gateway"])
+	if err != nil {
+		return nil // The OVN routers don't have an IPv6 uplink address.
+	}
+
+	routes, err := pdUplinkRoutes(n.state, n.name, n.config[pdVolatilePrefix], gatewaySubnet)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		r := &ip.Route{
+			DevName: n.config["ipv6.pd.interface"],
+			Proto:   "static",
+			Family:  ip.FamilyV6,
+		}
+
+		err = r.Replace([]string{route.subnet.String(), "via", route.router.String()})
+		if err != nil {
+			return errors.Wrapf(err, "Failed adding route for OVN network %q", route.network)
+		}
+	}
+
+	return nil
+}
+
+// pdRelease removes the delegated prefix subnet routes and stops the DHCPv6-PD client running on the interface, if
+// the local member was delegating the prefix.
+func (n *physical) pdRelease(iface string) error {
+	if !shared.PathExists(pdPath(iface, "dhclient.pid")) {
+		return nil // Not delegating on this member.
+	}
+
+	_, gatewaySubnet, err := net.ParseCIDR(n.config["ipv6.gateway"])
+	if err == nil && InterfaceExists(iface) {
+		routes, err := pdUplinkRoutes(n.state, n.name, n.config[pdVolatilePrefix], gatewaySubnet)
+		if err != nil {
+			return err
+		}
+
+		for _, route := range routes {
+			r := &ip.Route{
+				DevName: iface,
+				Route:   route.subnet.String(),
+				Proto:   "static",
+				Family:  ip.FamilyV6,
+			}
+
+			err = r.Flush()
+			if err != nil {
+				return errors.Wrapf(err, "Failed removing route for OVN network %q", route.network)
+			}
+		}
+	}
+
+	return pdClientStop(iface)
+}
@@ -0,0 +1,946 @@
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
+	"github.com/lxc/lxd/lxd/ip"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/lxd/warnings"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/validate"
+)
+
+// wireguardDefaultPort is the UDP port used when wireguard.port isn't set.
+const wireguardDefaultPort = 51820
+
+// wireguardMemberKeepalive is the persistent keepalive interval (in seconds) used for cluster member peers.
+const wireguardMemberKeepalive = "25"
+
+// wireguardPeer represents a WireGuard peer, either another cluster member or an external endpoint.
+type wireguardPeer struct {
+	name                string
+	publicKey           string
+	endpoint            string
+	allowedIPs          []string
+	persistentKeepalive string
+}
+
+// wireguard represents a LXD WireGuard network.
+type wireguard struct {
+	common
+}
+
+// Type returns the network type.
+func (n *wireguard) Type() string {
+	return "wireguard"
+}
+
+// DBType returns the network type DB ID.
+func (n *wireguard) DBType() db.NetworkType {
+	return db.NetworkTypeWireguard
+}
+
+// Info returns the network driver info.
+func (n *wireguard) Info() Info {
+	return Info{
+		Projects:           false,
+		NodeSpecificConfig: true,
+	}
+}
+
+// ValidateName validates network name.
+func (n *wireguard) ValidateName(name string) error {
+	err := validate.IsInterfaceName(name)
+	if err != nil {
+		return err
+	}
+
+	// Apply common name validation that applies to all network types.
+	return n.common.ValidateName(name)
+}
+
+// Validate network config.
+func (n *wireguard) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		"mtu":            validate.Optional(validate.IsNetworkMTU),
+		"wireguard.port": validate.Optional(validate.IsNetworkPort),
+		"wireguard.address": validate.Optional(func(value string) error {
+			for _, address := range strings.Split(value, ",") {
+				err := validate.IsNetworkAddressCIDR(strings.TrimSpace(address))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}),
+		"wireguard.endpoint": validate.Optional(func(value string) error {
+			if validate.IsNetworkAddress(value) == nil {
+				return nil
+			}
+
+			return wireguardValidEndpoint(value)
+		}),
+		"wireguard.routes":              validate.Optional(validate.IsNetworkList),
+		"wireguard.key_rotation":        validate.Optional(validate.IsUint32),
+		"volatile.wireguard.public_key": validate.Optional(wireguardValidKey),
+	}
+
+	// Add the peer validation rules.
+	for k := range config {
+		// Peer keys have the peer name in their name, so extract the real key.
+		if !strings.HasPrefix(k, "wireguard.peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 {
+			return fmt.Errorf("Invalid network configuration key: %s", k)
+		}
+
+		// Add the correct validation rule for the dynamic field based on last part of key.
+		switch fields[3] {
+		case "public_key":
+			rules[k] = validate.Optional(wireguardValidKey)
+		case "endpoint":
+			rules[k] = validate.Optional(wireguardValidEndpoint)
+		case "allowed_ips":
+			rules[k] = validate.Optional(validate.IsNetworkList)
+		case "persistent_keepalive":
+			rules[k] = validate.Optional(validate.IsUint32)
+		}
+	}
+
+	err := n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	// Check that external peers are complete.
+	for _, peer := range n.externalPeers(config) {
+		if peer.publicKey == "" {
+			return fmt.Errorf("Peer %q is missing a public key", peer.name)
+		}
+
+		if len(peer.allowedIPs) == 0 {
+			return fmt.Errorf("Peer %q is missing allowed IPs", peer.name)
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes a network.
+func (n *wireguard) Delete(clientType request.ClientType) error {
+	n.logger.Debug("Delete", log.Ctx{"clientType": clientType})
+
+	// We only need to check in the database once, not on every clustered node.
+	if clientType == request.ClientTypeNormal {
+		bridges, err := n.usedByBridges()
+		if err != nil {
+			return err
+		}
+
+		if len(bridges) > 0 {
+			return fmt.Errorf("Network is used by bridge network(s) %s through %q", strings.Join(bridges, ", "), "wireguard.network")
+		}
+	}
+
+	err := n.Stop()
+	if err != nil {
+		return err
+	}
+
+	return n.common.delete(clientType)
+}
+
+// usedByBridges returns the names of the bridge networks (in any state) using the network as wireguard.network.
+func (n *wireguard) usedByBridges() ([]string, error) {
+	networks, err := n.state.Cluster.GetNetworks(project.Default)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading networks")
+	}
+
+	bridges := []string{}
+	for _, name := range networks {
+		_, netInfo, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed loading network %q", name)
+		}
+
+		if netInfo.Type == "bridge" && netInfo.Config["wireguard.network"] == n.name {
+			bridges = append(bridges, name)
+		}
+	}
+
+	return bridges, nil
+}
+
+// Rename renames a network.
+func (n *wireguard) Rename(newName string) error {
+	n.logger.Debug("Rename", log.Ctx{"newName": newName})
+
+	if InterfaceExists(newName) {
+		return fmt.Errorf("Network interface %q already exists", newName)
+	}
+
+	// Bring the network down.
+	if InterfaceExists(n.name) {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename common steps.
+	err := n.common.rename(newName)
+	if err != nil {
+		return err
+	}
+
+	// Bring the network up.
+	err = n.Start()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the network.
+func (n *wireguard) Start() error {
+	n.logger.Debug("Start")
+
+	err := n.setup()
+	if err != nil {
+		err := n.state.Cluster.UpsertWarningLocalNode(n.project, dbCluster.TypeNetwork, int(n.id), db.WarningNetworkStartupFailure, err.Error())
+		if err != nil {
+			n.logger.Warn("Failed to create warning", log.Ctx{"err": err})
+		}
+	} else {
+		err := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(n.state.Cluster, n.project, db.WarningNetworkStartupFailure, dbCluster.TypeNetwork, int(n.id))
+		if err != nil {
+			n.logger.Warn("Failed to resolve warning", log.Ctx{"err": err})
+		}
+	}
+
+	return err
+}
+
+// setup creates the WireGuard interface (if needed) and configures its addresses, keys and peers.
+func (n *wireguard) setup() error {
+	// If we are in mock mode, just no-op.
+	if n.state.OS.MockMode {
+		return nil
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the interface.
+	if !InterfaceExists(n.name) {
+		link := &ip.Wireguard{Link: ip.Link{Name: n.name}}
+		err := link.Add()
+		if err != nil {
+			return errors.Wrapf(err, "Failed creating WireGuard interface %q", n.name)
+		}
+
+		revert.Add(func() { InterfaceRemove(n.name) })
+	}
+
+	link := &ip.Link{Name: n.name}
+
+	// Set the MTU.
+	if n.config["mtu"] != "" {
+		err := link.SetMTU(n.config["mtu"])
+		if err != nil {
+			return errors.Wrapf(err, "Failed setting MTU %q on %q", n.config["mtu"], n.name)
+		}
+	}
+
+	// Configure the interface addresses.
+	addr := &ip.Addr{DevName: n.name}
+	err := addr.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, address := range util.SplitNTrimSpace(n.config["wireguard.address"], ",", -1, true) {
+		family := ip.FamilyV4
+		if validate.IsNetworkAddressCIDRV6(address) == nil {
+			family = ip.FamilyV6
+		}
+
+		addr := &ip.Addr{
+			DevName: n.name,
+			Address: address,
+			Family:  family,
+		}
+
+		err = addr.Add()
+		if err != nil {
+			return errors.Wrapf(err, "Failed adding address %q to %q", address, n.name)
+		}
+	}
+
+	// Allow forwarding so that instance traffic can be routed over the tunnel.
+	err = util.SysctlSet("net/ipv4/ip_forward", "1")
+	if err != nil {
+		return err
+	}
+
+	if shared.PathExists("/proc/sys/net/ipv6") {
+		err = util.SysctlSet("net/ipv6/conf/all/forwarding", "1")
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = n.refresh(true)
+	if err != nil {
+		return err
+	}
+
+	err = link.SetUp()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Stop stops the network.
+func (n *wireguard) Stop() error {
+	n.logger.Debug("Stop")
+
+	if InterfaceExists(n.name) {
+		err := InterfaceRemove(n.name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *wireguard) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	n.logger.Debug("Update", log.Ctx{"clientType": clientType, "newNetwork": newNetwork})
+
+	dbUpdateNeeeded, _, oldNetwork, err := n.common.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	if !dbUpdateNeeeded {
+		return nil // Nothing changed.
+	}
+
+	// If the network as a whole has not had any previous creation attempts, or the node itself is still
+	// pending, then don't apply the new settings to the node, just to the database record (ready for the
+	// actual global create request to be initiated).
+	if n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return n.common.update(newNetwork, targetNode, clientType)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Record the routed subnets, so that dependent networks are only notified if they change.
+	oldSubnets, err := n.peerSubnets()
+	if err != nil {
+		return err
+	}
+
+	// Define a function which reverts everything.
+	revert.Add(func() {
+		// Reset changes to all nodes and database.
+		n.common.update(oldNetwork, targetNode, clientType)
+	})
+
+	// Apply changes to all nodes and database.
+	err = n.common.update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	err = n.Start()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	newSubnets, err := n.peerSubnets()
+	if err != nil {
+		return err
+	}
+
+	// Notify dependent networks (bridges routing over this network) if the routed subnets have changed. As they
+	// only apply local changes, do this on every member rather than only on the one that received the request.
+	if !wireguardSubnetsEqual(oldSubnets, newSubnets) {
+		n.common.notifyDependentNetworks([]string{"wireguard.peers"})
+	}
+
+	return nil
+}
+
+// wireguardSubnetsEqual returns true if both lists contain the same subnets, regardless of their order.
+func wireguardSubnetsEqual(a []*net.IPNet, b []*net.IPNet) bool {
+	subnets := map[string]int{}
+	for _, subnet := range a {
+		subnets[subnet.String()]++
+	}
+
+	for _, subnet := range b {
+		subnets[subnet.String()]--
+	}
+
+	for _, count := range subnets {
+		if count != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// State returns the network state, including the WireGuard peer status.
+func (n *wireguard) State() (*api.NetworkState, error) {
+	state, err := n.common.State()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := shared.RunCommand("wg", "show", n.name, "dump")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed getting WireGuard state of %q", n.name)
+	}
+
+	peers, err := n.peers()
+	if err != nil {
+		return nil, err
+	}
+
+	peerNames := make(map[string]string, len(peers))
+	for _, peer := range peers {
+		peerNames[peer.publicKey] = peer.name
+	}
+
+	state.Wireguard = &api.NetworkStateWireguard{
+		Peers: []api.NetworkStateWireguardPeer{},
+	}
+
+	for i, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+
+		// The first line describes the interface itself.
+		if i == 0 {
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("Failed parsing WireGuard interface line %q", line)
+			}
+
+			state.Wireguard.PublicKey = fields[1]
+			state.Wireguard.ListenPort, _ = strconv.ParseInt(fields[2], 10, 64)
+			continue
+		}
+
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("Failed parsing WireGuard peer line %q", line)
+		}
+
+		peer := api.NetworkStateWireguardPeer{
+			Name:       peerNames[fields[0]],
+			PublicKey:  fields[0],
+			AllowedIPs: []string{},
+		}
+
+		if fields[2] != "(none)" {
+			peer.Endpoint = fields[2]
+		}
+
+		if fields[3] != "(none)" {
+			peer.AllowedIPs = strings.Split(fields[3], ",")
+		}
+
+		handshake, _ := strconv.ParseInt(fields[4], 10, 64)
+		if handshake > 0 {
+			peer.LatestHandshake = time.Unix(handshake, 0).UTC()
+		}
+
+		peer.BytesReceived, _ = strconv.ParseInt(fields[5], 10, 64)
+		peer.BytesSent, _ = strconv.ParseInt(fields[6], 10, 64)
+
+		state.Wireguard.Peers = append(state.Wireguard.Peers, peer)
+	}
+
+	return state, nil
+}
+
+// keyPath returns the path of the file holding the private key of the local member.
+func (n *wireguard) keyPath() string {
+	return shared.VarPath("networks", n.name, "wireguard.key")
+}
+
+// privateKey returns the private key of the local member, generating a new one if none exists yet or if the
+// existing one is older than wireguard.key_rotation days. The matching public key is recorded in the
+// volatile.wireguard.public_key member specific config key so that other cluster members can use it.
+func (n *wireguard) privateKey() (string, error) {
+	var privateKey string
+
+	rotate := true
+	fi, err := os.Stat(n.keyPath())
+	if err == nil {
+		rotate = false
+
+		if n.config["wireguard.key_rotation"] != "" {
+			days, err := strconv.ParseUint(n.config["wireguard.key_rotation"], 10, 32)
+			if err != nil {
+				return "", err
+			}
+
+			if days > 0 && time.Since(fi.ModTime()) > time.Duration(days)*24*time.Hour {
+				rotate = true
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if rotate {
+		if !shared.PathExists(shared.VarPath("networks", n.name)) {
+			err = os.MkdirAll(shared.VarPath("networks", n.name), 0711)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		privateKey, err = wireguardGenerateKey()
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed generating WireGuard key")
+		}
+
+		err = ioutil.WriteFile(n.keyPath(), []byte(privateKey+"\n"), 0600)
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed writing WireGuard key")
+		}
+
+		n.logger.Info("Generated new WireGuard key")
+	} else {
+		content, err := ioutil.ReadFile(n.keyPath())
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed reading WireGuard key")
+		}
+
+		privateKey = strings.TrimSpace(string(content))
+	}
+
+	publicKey, err := wireguardPublicKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	if n.config["volatile.wireguard.public_key"] != publicKey {
+		n.config["volatile.wireguard.public_key"] = publicKey
+		err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			return tx.UpdateNetwork(n.id, n.description, n.config)
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "Failed saving volatile config")
+		}
+	}
+
+	return privateKey, nil
+}
+
+// externalPeers returns the external peers defined in config.
+func (n *wireguard) externalPeers(config map[string]string) []wireguardPeer {
+	peerNames := []string{}
+	for k := range config {
+		if !strings.HasPrefix(k, "wireguard.peers.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 || shared.StringInSlice(fields[2], peerNames) {
+			continue
+		}
+
+		peerNames = append(peerNames, fields[2])
+	}
+
+	sort.Strings(peerNames)
+
+	peers := make([]wireguardPeer, 0, len(peerNames))
+	for _, peerName := range peerNames {
+		prefix := fmt.Sprintf("wireguard.peers.%s.", peerName)
+
+		peers = append(peers, wireguardPeer{
+			name:                peerName,
+			publicKey:           config[prefix+"public_key"],
+			endpoint:            config[prefix+"endpoint"],
+			allowedIPs:          util.SplitNTrimSpace(config[prefix+"allowed_ips"], ",", -1, true),
+			persistentKeepalive: config[prefix+"persistent_keepalive"],
+		})
+	}
+
+	return peers
+}
+
+// peers returns the external peers followed by the other cluster members which have the network set up.
+func (n *wireguard) peers() ([]wireguardPeer, error) {
+	peers := n.externalPeers(n.config)
+
+	var err error
+	var localMember string
+	var members []db.NodeInfo
+	var membersConfig map[string]map[string]string
+
+	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		localMember, err = tx.GetLocalNodeName()
+		if err != nil {
+			return err
+		}
+
+		members, err = tx.GetNodes()
+		if err != nil {
+			return err
+		}
+
+		membersConfig, err = tx.GetNetworkMembersConfig(n.id)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading cluster members")
+	}
+
+	for _, member := range members {
+		memberConfig := membersConfig[member.Name]
+		if member.Name == localMember || memberConfig["volatile.wireguard.public_key"] == "" {
+			continue // Skip ourselves and members which haven't set up the network yet.
+		}
+
+		port := n.config["wireguard.port"]
+		if port == "" {
+			port = fmt.Sprintf("%d", wireguardDefaultPort)
+		}
+
+		endpoint := memberConfig["wireguard.endpoint"]
+		if endpoint == "" {
+			host, _, err := net.SplitHostPort(member.Address)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed parsing address of cluster member %q", member.Name)
+			}
+
+			endpoint = net.JoinHostPort(host, port)
+		} else if validate.IsNetworkAddress(endpoint) == nil {
+			endpoint = net.JoinHostPort(endpoint, port)
+		}
+
+		// Route the tunnel addresses of the member and the subnets it announces to it.
+		allowedIPs := []string{}
+		for _, address := range util.SplitNTrimSpace(memberConfig["wireguard.address"], ",", -1, true) {
+			memberIP, _, err := net.ParseCIDR(address)
+			if err != nil {
+				return nil, err
+			}
+
+			allowedIPs = append(allowedIPs, wireguardHostNet(memberIP).String())
+		}
+
+		allowedIPs = append(allowedIPs, util.SplitNTrimSpace(memberConfig["wireguard.routes"], ",", -1, true)...)
+
+		peers = append(peers, wireguardPeer{
+			name:                member.Name,
+			publicKey:           memberConfig["volatile.wireguard.public_key"],
+			endpoint:            endpoint,
+			allowedIPs:          allowedIPs,
+			persistentKeepalive: wireguardMemberKeepalive,
+		})
+	}
+
+	return peers, nil
+}
+
+// peerSubnets returns the subnets routed to the peers of the network.
+func (n *wireguard) peerSubnets() ([]*net.IPNet, error) {
+	peers, err := n.peers()
+	if err != nil {
+		return nil, err
+	}
+
+	subnets := []*net.IPNet{}
+	for _, peer := range peers {
+		for _, allowedIP := range peer.allowedIPs {
+			_, subnet, err := net.ParseCIDR(allowedIP)
+			if err != nil {
+				return nil, err
+			}
+
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	return subnets, nil
+}
+
+// refresh rotates the key if needed and applies the current peers to the WireGuard interface and routing table.
+// Returns true if the routes over the interface have changed. Dependent networks are notified of such changes,
+// unless starting up, as dependent networks will pick up the routes themselves when they start.
+func (n *wireguard) refresh(starting bool) (bool, error) {
+	privateKey, err := n.privateKey()
+	if err != nil {
+		return false, err
+	}
+
+	peers, err := n.peers()
+	if err != nil {
+		return false, err
+	}
+
+	port := n.config["wireguard.port"]
+	if port == "" {
+		port = fmt.Sprintf("%d", wireguardDefaultPort)
+	}
+
+	conf := bytes.NewBufferString(wireguardConfig(privateKey, port, peers))
+	err = shared.RunCommandWithFds(conf, nil, "wg", "syncconf", n.name, "/dev/stdin")
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed applying WireGuard configuration to %q", n.name)
+	}
+
+	// Route the allowed IPs of the peers over the interface.
+	changed, err := n.applyRoutes(peers)
+	if err != nil {
+		return false, err
+	}
+
+	if changed && !starting {
+		n.common.notifyDependentNetworks([]string{"wireguard.peers"})
+	}
+
+	return changed, nil
+}
+
+// applyRoutes adds the routes to the allowed IPs of the peers and removes any stale ones.
+// Returns true if any route was added or removed.
+func (n *wireguard) applyRoutes(peers []wireguardPeer) (bool, error) {
+	wantRoutes := map[string]string{}
+	for _, peer := range peers {
+		for _, allowedIP := range peer.allowedIPs {
+			_, subnet, err := net.ParseCIDR(allowedIP)
+			if err != nil {
+				return false, err
+			}
+
+			family := ip.FamilyV4
+			if subnet.IP.To4() == nil {
+				family = ip.FamilyV6
+			}
+
+			wantRoutes[subnet.String()] = family
+		}
+	}
+
+	changed := false
+	for _, family := range []string{ip.FamilyV4, ip.FamilyV6} {
+		r := &ip.Route{
+			DevName: n.name,
+			Proto:   "static",
+			Family:  family,
+		}
+
+		routes, err := r.Show()
+		if err != nil {
+			return false, err
+		}
+
+		for _, route := range routes {
+			// Host routes are listed without their prefix length.
+			dest := strings.Fields(route)[0]
+			if !strings.Contains(dest, "/") {
+				dest = wireguardHostNet(net.ParseIP(dest)).String()
+			}
+
+			_, ok := wantRoutes[dest]
+			if ok {
+				delete(wantRoutes, dest)
+				continue
+			}
+
+			r := &ip.Route{
+				DevName: n.name,
+				Route:   dest,
+				Table:   "main",
+				Family:  family,
+			}
+
+			err = r.Delete()
+			if err != nil {
+				return false, err
+			}
+
+			changed = true
+		}
+	}
+
+	for dest, family := range wantRoutes {
+		r := &ip.Route{
+			DevName: n.name,
+			Route:   dest,
+			Proto:   "static",
+			Family:  family,
+		}
+
+		err := r.Add()
+		if err != nil {
+			return false, errors.Wrapf(err, "Failed adding route %q to %q", dest, n.name)
+		}
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// RefreshWireguard rotates the keys and refreshes the peers of the WireGuard networks running on the local member.
+func RefreshWireguard(s *state.State) error {
+	// Use project.Default here as WireGuard networks don't support projects.
+	projectName := project.Default
+
+	networks, err := s.Cluster.GetCreatedNetworks(projectName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range networks {
+		n, err := LoadByName(s, projectName, name)
+		if err != nil {
+			logger.Error("Failed to load network for WireGuard refresh", log.Ctx{"project": projectName, "network": name, "err": err})
+			continue
+		}
+
+		wgNet, ok := n.(*wireguard)
+		if !ok || !InterfaceExists(wgNet.name) {
+			continue
+		}
+
+		_, err = wgNet.refresh(false)
+		if err != nil {
+			wgNet.logger.Error("Failed refreshing WireGuard network", log.Ctx{"err": err})
+		}
+	}
+
+	return nil
+}
+
+// wireguardGenerateKey generates a new base64 encoded WireGuard private key.
+func wireguardGenerateKey() (string, error) {
+	key := make([]byte, curve25519.ScalarSize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+
+	// Clamp the key as per RFC 7748.
+	key[0] &= 248
+	key[31] = (key[31] & 127) | 64
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// wireguardPublicKey returns the base64 encoded public key matching the base64 encoded private key.
+func wireguardPublicKey(privateKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", errors.Wrapf(err, "Invalid WireGuard private key")
+	}
+
+	publicKey, err := curve25519.X25519(key, curve25519.Basepoint)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed deriving WireGuard public key")
+	}
+
+	return base64.StdEncoding.EncodeToString(publicKey), nil
+}
+
+// wireguardConfig generates the WireGuard configuration of the interface in the format used by "wg syncconf".
+func wireguardConfig(privateKey string, port string, peers []wireguardPeer) string {
+	var conf bytes.Buffer
+	fmt.Fprintf(&conf, "[Interface]\nPrivateKey = %s\nListenPort = %s\n", privateKey, port)
+
+	for _, peer := range peers {
+		fmt.Fprintf(&conf, "\n[Peer]\nPublicKey = %s\n", peer.publicKey)
+
+		if len(peer.allowedIPs) > 0 {
+			fmt.Fprintf(&conf, "AllowedIPs = %s\n", strings.Join(peer.allowedIPs, ", "))
+		}
+
+		if peer.endpoint != "" {
+			fmt.Fprintf(&conf, "Endpoint = %s\n", peer.endpoint)
+		}
+
+		if peer.persistentKeepalive != "" {
+			fmt.Fprintf(&conf, "PersistentKeepalive = %s\n", peer.persistentKeepalive)
+		}
+	}
+
+	return conf.String()
+}
+
+// wireguardHostNet returns the single address subnet of the IP.
+func wireguardHostNet(ip net.IP) *net.IPNet {
+	if ip.To4() != nil {
+		return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// wireguardValidKey validates a base64 encoded WireGuard key.
+func wireguardValidKey(value string) error {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != curve25519.PointSize {
+		return fmt.Errorf("Invalid WireGuard key %q", value)
+	}
+
+	return nil
+}
+
+// wireguardValidEndpoint validates a WireGuard endpoint in the form host:port.
+func wireguardValidEndpoint(value string) error {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return fmt.Errorf("Invalid endpoint %q: %v", value, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("Invalid endpoint %q: Missing host", value)
+	}
+
+	return validate.IsNetworkPort(port)
+}
@@ -0,0 +1,147 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireguard_Info(t *testing.T) {
+	n := &wireguard{}
+
+	// The addresses, endpoint, routes and public key are specific to each cluster member.
+	assert.True(t, n.Info().NodeSpecificConfig)
+	assert.False(t, n.Info().Projects)
+}
+
+func TestWireguard_Validate(t *testing.T) {
+	privateKey, err := wireguardGenerateKey()
+	require.NoError(t, err)
+
+	publicKey, err := wireguardPublicKey(privateKey)
+	require.NoError(t, err)
+
+	n := &wireguard{}
+	n.name = "wg0"
+
+	valid := []map[string]string{
+		{},
+		{"wireguard.port": "51821", "mtu": "1420"},
+		{"wireguard.address": "10.1.0.1/24, fd42::1/64", "wireguard.routes": "10.2.0.0/24"},
+		{"wireguard.endpoint": "192.0.2.1"},
+		{"wireguard.endpoint": "wg.example.com:51820"},
+		{"volatile.wireguard.public_key": publicKey},
+		{
+			"wireguard.peers.office.public_key":           publicKey,
+			"wireguard.peers.office.allowed_ips":          "10.3.0.0/24",
+			"wireguard.peers.office.endpoint":             "198.51.100.1:51820",
+			"wireguard.peers.office.persistent_keepalive": "25",
+		},
+	}
+
+	for _, config := range valid {
+		assert.NoError(t, n.Validate(config), "%v", config)
+	}
+
+	invalid := []map[string]string{
+		{"wireguard.port": "70000"},
+		{"wireguard.address": "10.1.0.1"},
+		{"wireguard.endpoint": ":51820"},
+		{"wireguard.routes": "not-a-subnet"},
+		{"volatile.wireguard.public_key": "invalid"},
+		{"wireguard.peers.office": "foo"},
+		{"wireguard.peers.office.public_key": publicKey},
+		{"wireguard.peers.office.allowed_ips": "10.3.0.0/24"},
+		{"wireguard.peers.office.public_key": publicKey, "wireguard.peers.office.allowed_ips": "10.3.0.0/24", "wireguard.peers.office.endpoint": "198.51.100.1"},
+		{"wireguard.peers.office.public_key": publicKey, "wireguard.peers.office.allowed_ips": "10.3.0.0/24", "wireguard.peers.office.unknown": "1"},
+	}
+
+	for _, config := range invalid {
+		assert.Error(t, n.Validate(config), "%v", config)
+	}
+}
+
+func TestWireguard_externalPeers(t *testing.T) {
+	n := &wireguard{}
+
+	peers := n.externalPeers(map[string]string{
+		"wireguard.port":                         "51820",
+		"wireguard.peers.b.public_key":           "keyB",
+		"wireguard.peers.b.allowed_ips":          "10.3.0.0/24, 10.4.0.0/24",
+		"wireguard.peers.a.public_key":           "keyA",
+		"wireguard.peers.a.allowed_ips":          "10.5.0.0/24",
+		"wireguard.peers.a.endpoint":             "198.51.100.1:51820",
+		"wireguard.peers.a.persistent_keepalive": "25",
+	})
+
+	assert.Equal(t, []wireguardPeer{
+		{name: "a", publicKey: "keyA", endpoint: "198.51.100.1:51820", allowedIPs: []string{"10.5.0.0/24"}, persistentKeepalive: "25"},
+		{name: "b", publicKey: "keyB", allowedIPs: []string{"10.3.0.0/24", "10.4.0.0/24"}},
+	}, peers)
+}
+
+func TestWireguard_config(t *testing.T) {
+	peers := []wireguardPeer{
+		{name: "a", publicKey: "keyA", endpoint: "198.51.100.1:51820", allowedIPs: []string{"10.5.0.0/24", "10.6.0.1/32"}, persistentKeepalive: "25"},
+		{name: "b", publicKey: "keyB"},
+	}
+
+	assert.Equal(t, `[Interface]
+PrivateKey = private
+ListenPort = 51820
+
+[Peer]
+PublicKey = keyA
+AllowedIPs = 10.5.0.0/24, 10.6.0.1/32
+Endpoint = 198.51.100.1:51820
+PersistentKeepalive = 25
+
+[Peer]
+PublicKey = keyB
+`, wireguardConfig("private", "51820", peers))
+}
+
+func TestWireguard_keys(t *testing.T) {
+	privateKey, err := wireguardGenerateKey()
+	require.NoError(t, err)
+	assert.NoError(t, wireguardValidKey(privateKey))
+
+	publicKey, err := wireguardPublicKey(privateKey)
+	require.NoError(t, err)
+	assert.NoError(t, wireguardValidKey(publicKey))
+	assert.NotEqual(t, privateKey, publicKey)
+
+	// The public key is derived from the private one.
+	again, err := wireguardPublicKey(privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, again)
+
+	_, err = wireguardPublicKey("invalid!")
+	assert.Error(t, err)
+}
+
+func TestWireguard_hostNet(t *testing.T) {
+	assert.Equal(t, "10.1.0.1/32", wireguardHostNet(net.ParseIP("10.1.0.1")).String())
+	assert.Equal(t, "fd42::1/128", wireguardHostNet(net.ParseIP("fd42::1")).String())
+}
+
+func TestWireguard_subnetsEqual(t *testing.T) {
+	parse := func(subnets ...string) []*net.IPNet {
+		ipNets := []*net.IPNet{}
+		for _, subnet := range subnets {
+			_, ipNet, err := net.ParseCIDR(subnet)
+			require.NoError(t, err)
+
+			ipNets = append(ipNets, ipNet)
+		}
+
+		return ipNets
+	}
+
+	assert.True(t, wireguardSubnetsEqual(parse(), parse()))
+	assert.True(t, wireguardSubnetsEqual(parse("10.1.0.0/24", "fd42::/64"), parse("fd42::/64", "10.1.0.0/24")))
+	assert.False(t, wireguardSubnetsEqual(parse("10.1.0.0/24"), parse("10.1.0.0/24", "10.2.0.0/24")))
+	assert.False(t, wireguardSubnetsEqual(parse("10.1.0.0/24"), parse("10.1.0.0/16")))
+}
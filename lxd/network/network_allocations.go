@@ -0,0 +1,386 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/db"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// Allocation types.
+const (
+	AllocationTypeInstance     = "instance"
+	AllocationTypeNetwork      = "network"
+	AllocationTypeNAT          = "nat"
+	AllocationTypeLoadBalancer = "load-balancer"
+	AllocationTypeUplink       = "uplink"
+	AllocationTypeReservation  = "reservation"
+)
+
+// allocationURL returns the URL of an entity in a project, adding the project query parameter when needed.
+func allocationURL(projectName string, path string, args ...interface{}) string {
+	escapedArgs := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		escapedArgs = append(escapedArgs, url.PathEscape(fmt.Sprintf("%v", arg)))
+	}
+
+	u := fmt.Sprintf("/%s/%s", version.APIVersion, fmt.Sprintf(path, escapedArgs...))
+	if projectName != project.Default {
+		u += fmt.Sprintf("?project=%s", url.QueryEscape(projectName))
+	}
+
+	return u
+}
+
+// allocationIP returns the IP of an allocation address (which may be in CIDR notation), or nil if invalid.
+func allocationIP(address string) net.IP {
+	ip, _, err := net.ParseCIDR(address)
+	if err == nil {
+		return ip
+	}
+
+	return net.ParseIP(address)
+}
+
+// allocation is an address allocation along with the project of the network it is allocated on.
+type allocation struct {
+	api.NetworkAllocation
+
+	networkProject string
+}
+
+// allocationsMarkConflicts sets the Conflict field on the allocations whose address is also allocated to another
+// entity on the same network. Addresses which aren't on a managed network (such as routed NIC addresses) are
+// considered to be on the host network. NAT addresses are ignored as they are commonly shared.
+func allocationsMarkConflicts(allocations []allocation) {
+	allocationKey := func(a allocation) string {
+		ip := allocationIP(a.Address)
+		if ip == nil || a.Type == AllocationTypeNAT {
+			return ""
+		}
+
+		if a.Network == "" {
+			return ip.String()
+		}
+
+		return fmt.Sprintf("%s/%s/%s", a.networkProject, a.Network, ip.String())
+	}
+
+	// Map of network and IP to the entities using it. Allocations with the same MAC address (such as an instance
+	// NIC and the DHCP reservation for it) are considered to be the same entity.
+	usedBy := map[string]map[string]struct{}{}
+	for _, a := range allocations {
+		key := allocationKey(a)
+		if key == "" {
+			continue
+		}
+
+		if usedBy[key] == nil {
+			usedBy[key] = map[string]struct{}{}
+		}
+
+		entity := a.UsedBy
+		if a.Hwaddr != "" {
+			entity = a.Hwaddr
+		}
+
+		usedBy[key][entity] = struct{}{}
+	}
+
+	for i := range allocations {
+		key := allocationKey(allocations[i])
+		if key != "" && len(usedBy[key]) > 1 {
+			allocations[i].Conflict = true
+		}
+	}
+}
+
+// networkAllocations returns the addresses allocated by a managed network itself.
+func networkAllocations(s *state.State, projectName string, networkID int64, network api.Network) ([]allocation, error) {
+	allocations := []allocation{}
+	networkURL := allocationURL(projectName, "networks/%s", network.Name)
+
+	add := func(address string, allocationType string, networkName string, nat bool) {
+		if allocationIP(address) == nil {
+			return
+		}
+
+		// Uplink networks are always in the default project.
+		networkProject := projectName
+		if networkName != network.Name {
+			networkProject = project.Default
+		}
+
+		allocations = append(allocations, allocation{
+			NetworkAllocation: api.NetworkAllocation{
+				Address: address,
+				UsedBy:  networkURL,
+				Type:    allocationType,
+				Network: networkName,
+				Project: projectName,
+				NAT:     nat,
+			},
+			networkProject: networkProject,
+		})
+	}
+
+	// Network addresses (or gateways of physical networks).
+	add(network.Config["ipv4.address"], AllocationTypeNetwork, network.Name, shared.IsTrue(network.Config["ipv4.nat"]))
+	add(IPv6Address(network.Config), AllocationTypeNetwork, network.Name, shared.IsTrue(network.Config["ipv6.nat"]))
+
+	if network.Type == "physical" {
+		add(network.Config["ipv4.gateway"], AllocationTypeNetwork, network.Name, false)
+		add(network.Config["ipv6.gateway"], AllocationTypeNetwork, network.Name, false)
+	}
+
+	// NAT source addresses.
+	add(network.Config["ipv4.nat.address"], AllocationTypeNAT, network.Name, true)
+	add(network.Config["ipv6.nat.address"], AllocationTypeNAT, network.Name, true)
+
+	if !shared.StringInSlice(network.Type, []string{"bridge", "ovn"}) {
+		return allocations, nil
+	}
+
+	// DHCP reservations.
+	reservations, err := s.Cluster.GetNetworkReservations(networkID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading DHCP reservations of network %q", network.Name)
+	}
+
+	for _, reservation := range reservations {
+		for _, address := range []string{reservation.IPv4Address, reservation.IPv6Address} {
+			if allocationIP(address) == nil {
+				continue
+			}
+
+			allocations = append(allocations, allocation{
+				NetworkAllocation: api.NetworkAllocation{
+					Address: address,
+					UsedBy:  allocationURL(projectName, "networks/%s/reservations/%s", network.Name, reservation.Hwaddr),
+					Type:    AllocationTypeReservation,
+					Network: network.Name,
+					Project: projectName,
+					Hwaddr:  reservation.Hwaddr,
+				},
+				networkProject: projectName,
+			})
+		}
+	}
+
+	if network.Type != "ovn" {
+		return allocations, nil
+	}
+
+	// OVN router addresses on the uplink network.
+	uplinkName := network.Config["network"]
+	add(network.Config[ovnVolatileUplinkIPv4], AllocationTypeUplink, uplinkName, false)
+	add(network.Config[ovnVolatileUplinkIPv6], AllocationTypeUplink, uplinkName, false)
+
+	// Load balancer listen addresses, which are routed from the uplink network.
+	listenAddresses, err := s.Cluster.GetNetworkLoadBalancerListenAddresses(networkID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed loading load balancers of network %q", network.Name)
+	}
+
+	for _, listenAddress := range listenAddresses {
+		allocations = append(allocations, allocation{
+			NetworkAllocation: api.NetworkAllocation{
+				Address: listenAddress,
+				UsedBy:  allocationURL(projectName, "networks/%s/load-balancers/%s", network.Name, listenAddress),
+				Type:    AllocationTypeLoadBalancer,
+				Network: uplinkName,
+				Project: projectName,
+			},
+			networkProject: project.Default,
+		})
+	}
+
+	return allocations, nil
+}
+
+// allocationsAll returns the addresses allocated across all projects to the managed networks and to the instance
+// NICs, with the Conflict field set on addresses allocated to more than one entity on the same network.
+func allocationsAll(s *state.State) ([]allocation, error) {
+	var err error
+	var projectNetworks map[string]map[int64]api.Network
+
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		projectNetworks, err = tx.GetCreatedNetworks()
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load all networks")
+	}
+
+	allocations := []allocation{}
+
+	for netProjectName, networks := range projectNetworks {
+		for networkID, network := range networks {
+			netAllocations, err := networkAllocations(s, netProjectName, networkID, network)
+			if err != nil {
+				return nil, err
+			}
+
+			allocations = append(allocations, netAllocations...)
+		}
+	}
+
+	// Instance NIC addresses.
+	err = s.Cluster.InstanceList(nil, func(inst db.Instance, p api.Project, profiles []api.Profile) error {
+		instNetworkProject := project.NetworkProjectFromRecord(&p)
+
+		devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(inst.Devices), profiles)
+		for devName, devConfig := range devices {
+			if devConfig["type"] != "nic" {
+				continue
+			}
+
+			// Find the managed network the NIC is connected to (if any).
+			networkName := devConfig["network"]
+			if networkName == "" {
+				for _, network := range projectNetworks[instNetworkProject] {
+					if network.Name == devConfig["parent"] {
+						networkName = network.Name
+						break
+					}
+				}
+			}
+
+			var netConfig map[string]string
+			for _, network := range projectNetworks[instNetworkProject] {
+				if network.Name == networkName {
+					netConfig = network.Config
+					break
+				}
+			}
+
+			hwaddr := devConfig["hwaddr"]
+			if hwaddr == "" {
+				hwaddr = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+			}
+
+			for _, family := range []string{"ipv4", "ipv6"} {
+				for _, address := range util.SplitNTrimSpace(devConfig[fmt.Sprintf("%s.address", family)], ",", -1, true) {
+					if allocationIP(address) == nil {
+						continue
+					}
+
+					allocations = append(allocations, allocation{
+						NetworkAllocation: api.NetworkAllocation{
+							Address: address,
+							UsedBy:  allocationURL(inst.Project, "instances/%s", inst.Name),
+							Type:    AllocationTypeInstance,
+							Network: networkName,
+							Project: inst.Project,
+							NAT:     shared.IsTrue(netConfig[fmt.Sprintf("%s.nat", family)]),
+							Hwaddr:  hwaddr,
+						},
+						networkProject: instNetworkProject,
+					})
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load instance NIC addresses")
+	}
+
+	allocationsMarkConflicts(allocations)
+
+	return allocations, nil
+}
+
+// Allocations returns the addresses allocated across all projects to the managed networks (network addresses,
+// NAT addresses, OVN router uplink addresses, load balancers and DHCP reservations) and to the instance NICs
+// (statically configured addresses, including routed and ipvlan NIC addresses). The Conflict field is set on
+// addresses allocated to more than one entity on the same network. If projectName is not empty, only the
+// allocations of the instances in that project and of the networks of its effective network project are returned.
+func Allocations(s *state.State, projectName string) ([]api.NetworkAllocation, error) {
+	allocations, err := allocationsAll(s)
+	if err != nil {
+		return nil, err
+	}
+
+	networkProjectName := ""
+	if projectName != "" {
+		networkProjectName, _, err = project.NetworkProject(s.Cluster, projectName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]api.NetworkAllocation, 0, len(allocations))
+	for _, a := range allocations {
+		if projectName != "" {
+			if a.Type == AllocationTypeInstance && a.Project != projectName {
+				continue
+			}
+
+			if a.Type != AllocationTypeInstance && a.Project != networkProjectName {
+				continue
+			}
+		}
+
+		result = append(result, a.NetworkAllocation)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Network != result[j].Network {
+			return result[i].Network < result[j].Network
+		}
+
+		return result[i].Address < result[j].Address
+	})
+
+	return result, nil
+}
+
+// AllocationConflicts returns a description of each address allocated to more than one entity on the same network,
+// sorted by network and address.
+func AllocationConflicts(allocations []api.NetworkAllocation) []string {
+	conflicts := map[string][]string{}
+	keys := []string{}
+	for _, a := range allocations {
+		if !a.Conflict {
+			continue
+		}
+
+		ip := allocationIP(a.Address)
+		key := fmt.Sprintf("%s on network %q", ip.String(), a.Network)
+		if a.Network == "" {
+			key = fmt.Sprintf("%s on host", ip.String())
+		}
+
+		_, found := conflicts[key]
+		if !found {
+			keys = append(keys, key)
+		}
+
+		if !shared.StringInSlice(a.UsedBy, conflicts[key]) {
+			conflicts[key] = append(conflicts[key], a.UsedBy)
+		}
+	}
+
+	sort.Strings(keys)
+
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, fmt.Sprintf("Address %s used by %s", key, strings.Join(conflicts[key], ", ")))
+	}
+
+	return result
+}
@@ -36,12 +36,14 @@ type Network interface {
 	Status() string
 	LocalStatus() string
 	Config() map[string]string
+	IPv6Address() string
 	IsUsed() (bool, error)
 	IsManaged() bool
 	DHCPv4Subnet() *net.IPNet
 	DHCPv6Subnet() *net.IPNet
 	DHCPv4Ranges() []shared.IPRange
 	DHCPv6Ranges() []shared.IPRange
+	State() (*api.NetworkState, error)
 
 	// Actions.
 	Create(clientType request.ClientType) error
@@ -52,4 +54,14 @@ type Network interface {
 	HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error
 	Delete(clientType request.ClientType) error
 	handleDependencyChange(netName string, netConfig map[string]string, changedKeys []string) error
+
+	// Load balancers.
+	LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) (net.IP, error)
+	LoadBalancerUpdate(listenAddress string, newLoadBalancer api.NetworkLoadBalancerPut, clientType request.ClientType) error
+	LoadBalancerDelete(listenAddress string, clientType request.ClientType) error
+
+	// DHCP reservations.
+	ReservationCreate(reservation api.NetworkReservationsPost, clientType request.ClientType) error
+	ReservationUpdate(hwaddr string, newReservation api.NetworkReservationPut, clientType request.ClientType) error
+	ReservationDelete(hwaddr string, clientType request.ClientType) error
 }
@@ -0,0 +1,158 @@
+package network
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/dnsmasq/dhcpalloc"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/validate"
+)
+
+// reservationDUIDPrefix is the prefix used to identify DHCPv6 DUID reservations (as used by dnsmasq).
+const reservationDUIDPrefix = "id:"
+
+// reservationIsDUID returns whether the hardware address of a DHCP reservation is a DHCPv6 DUID.
+func reservationIsDUID(hwaddr string) bool {
+	return strings.HasPrefix(hwaddr, reservationDUIDPrefix)
+}
+
+// reservationValidateHwaddr validates the hardware address of a DHCP reservation, which must either be a MAC
+// address or (if allowDUID is true) a DHCPv6 DUID prefixed with "id:".
+func reservationValidateHwaddr(hwaddr string, allowDUID bool) error {
+	if !reservationIsDUID(hwaddr) {
+		return validate.IsNetworkMAC(hwaddr)
+	}
+
+	if !allowDUID {
+		return fmt.Errorf("DHCPv6 DUID reservations aren't supported by this network type")
+	}
+
+	duid := strings.TrimPrefix(hwaddr, reservationDUIDPrefix)
+	parts := strings.Split(duid, ":")
+	if len(parts) < 2 {
+		return fmt.Errorf("Invalid DUID %q, must be at least 2 bytes of hex separated by colons", duid)
+	}
+
+	for _, part := range parts {
+		_, err := hex.DecodeString(part)
+		if err != nil || len(part) != 2 {
+			return fmt.Errorf("Invalid DUID %q, must be at least 2 bytes of hex separated by colons", duid)
+		}
+	}
+
+	return nil
+}
+
+// reservationValidate validates a DHCP reservation against the network's DHCP subnets and ranges, and checks that
+// its addresses aren't already allocated to another entity on the network.
+func reservationValidate(s *state.State, n Network, hwaddr string, info *api.NetworkReservationPut, allowDUID bool) error {
+	err := reservationValidateHwaddr(hwaddr, allowDUID)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid hardware address")
+	}
+
+	if info.IPv4Address == "" && info.IPv6Address == "" {
+		return fmt.Errorf("At least one of IPv4 or IPv6 address must be specified")
+	}
+
+	if reservationIsDUID(hwaddr) && info.IPv4Address != "" {
+		return fmt.Errorf("DHCPv6 DUID reservations can only contain an IPv6 address")
+	}
+
+	if info.Hostname != "" {
+		err = shared.ValidHostname(info.Hostname)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid hostname")
+		}
+	}
+
+	ips := []net.IP{}
+
+	if info.IPv4Address != "" {
+		ip := net.ParseIP(info.IPv4Address)
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("Invalid IPv4 address %q", info.IPv4Address)
+		}
+
+		subnet := n.DHCPv4Subnet()
+		if subnet == nil {
+			return fmt.Errorf("DHCPv4 isn't enabled on network %q", n.Name())
+		}
+
+		if ip.Equal(subnet.IP) || !dhcpalloc.DHCPValidIP(subnet, n.DHCPv4Ranges(), ip) {
+			return fmt.Errorf("IPv4 address %q isn't within the DHCPv4 subnet or ranges of network %q", info.IPv4Address, n.Name())
+		}
+
+		ips = append(ips, ip)
+	}
+
+	if info.IPv6Address != "" {
+		ip := net.ParseIP(info.IPv6Address)
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("Invalid IPv6 address %q", info.IPv6Address)
+		}
+
+		subnet := n.DHCPv6Subnet()
+		if subnet == nil || !shared.IsTrue(n.Config()["ipv6.dhcp.stateful"]) {
+			return fmt.Errorf("Stateful DHCPv6 isn't enabled on network %q", n.Name())
+		}
+
+		if ip.Equal(subnet.IP) || !dhcpalloc.DHCPValidIP(subnet, n.DHCPv6Ranges(), ip) {
+			return fmt.Errorf("IPv6 address %q isn't within the DHCPv6 subnet or ranges of network %q", info.IPv6Address, n.Name())
+		}
+
+		ips = append(ips, ip)
+	}
+
+	// Check the addresses aren't used by another entity on the network. Allocations with the same MAC address
+	// (the reservation itself or the instance NIC it is for) are ignored.
+	allocations, err := allocationsAll(s)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range allocations {
+		if a.networkProject != n.Project() || a.Network != n.Name() || a.Type == AllocationTypeNAT {
+			continue
+		}
+
+		if strings.EqualFold(a.Hwaddr, hwaddr) {
+			continue
+		}
+
+		aIP := allocationIP(a.Address)
+		for _, ip := range ips {
+			if ip.Equal(aIP) {
+				return fmt.Errorf("Address %q is already allocated to %s", ip.String(), a.UsedBy)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reservation returns the DHCP reservation of the network for a MAC address (nil if there is no reservation for it).
+func Reservation(s *state.State, n Network, hwaddr string) (*api.NetworkReservation, error) {
+	if hwaddr == "" {
+		return nil, nil
+	}
+
+	_, reservation, err := s.Cluster.GetNetworkReservation(n.ID(), strings.ToLower(hwaddr))
+	if err != nil {
+		if err == db.ErrNoSuchObject {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "Failed loading DHCP reservation for %q", hwaddr)
+	}
+
+	return reservation, nil
+}
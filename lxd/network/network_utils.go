@@ -31,6 +31,7 @@ import (
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/validate"
 	"github.com/lxc/lxd/shared/version"
 )
 
@@ -123,8 +124,9 @@ func UsedBy(s *state.State, networkProjectName string, networkName string, first
 				}
 
 				// The network's config references the network we are searching for. Either by
-				// directly referencing our network or by referencing our interface as its parent.
-				if network.Config["network"] == networkName || network.Config["parent"] == networkName {
+				// directly referencing our network (as uplink or WireGuard network) or by referencing
+				// our interface as its parent.
+				if network.Config["network"] == networkName || network.Config["wireguard.network"] == networkName || network.Config["parent"] == networkName {
 					uri := fmt.Sprintf("/%s/networks/%s", version.APIVersion, network.Name)
 					if projectName != project.Default {
 						uri += fmt.Sprintf("?project=%s", projectName)
@@ -460,6 +462,32 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 
 		config := n.Config()
 
+		// Use the addresses reserved for the MAC addresses of instance NICs without static addresses. The
+		// reservations used by instance NICs are covered by the instance's own dhcp-host line.
+		reservations, err := s.Cluster.GetNetworkReservations(n.ID())
+		if err != nil {
+			return errors.Wrapf(err, "Failed loading DHCP reservations of network %q", network)
+		}
+
+		usedReservations := map[string]struct{}{}
+		for _, entry := range entries {
+			for _, reservation := range reservations {
+				if !strings.EqualFold(entry[0], reservation.Hwaddr) {
+					continue
+				}
+
+				if entry[3] == "" {
+					entry[3] = reservation.IPv4Address
+				}
+
+				if entry[4] == "" {
+					entry[4] = reservation.IPv6Address
+				}
+
+				usedReservations[reservation.Hwaddr] = struct{}{}
+			}
+		}
+
 		// Wipe everything clean.
 		files, err := ioutil.ReadDir(shared.VarPath("networks", network, "dnsmasq.hosts"))
 		if err != nil {
@@ -522,6 +550,19 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 		}
 
+		// Generate the dhcp-host lines of the other reservations.
+		for _, reservation := range reservations {
+			_, found := usedReservations[reservation.Hwaddr]
+			if found {
+				continue
+			}
+
+			err := dnsmasq.UpdateReservationEntry(network, config, reservation.Hwaddr, reservation.IPv4Address, reservation.IPv6Address, reservation.Hostname)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Signal dnsmasq.
 		err = dnsmasq.Kill(network, true)
 		if err != nil {
@@ -1250,6 +1291,40 @@ func IPRangesOverlap(r1, r2 *shared.IPRange) bool {
 	return r1.ContainsIP(r2.Start) || r1.ContainsIP(r2.End)
 }
 
+// parsePortList parses a comma separated list of ports and port ranges (e.g. "80,8000-8010") into a list of
+// individual ports.
+func parsePortList(portList string) ([]uint64, error) {
+	ports := []uint64{}
+	for _, portRange := range util.SplitNTrimSpace(portList, ",", -1, true) {
+		if strings.Contains(portRange, "-") {
+			err := validate.IsNetworkPortRange(portRange)
+			if err != nil {
+				return nil, err
+			}
+
+			rangeParts := strings.SplitN(portRange, "-", 2)
+			startPort, _ := strconv.ParseUint(rangeParts[0], 10, 64)
+			endPort, _ := strconv.ParseUint(rangeParts[1], 10, 64)
+
+			for port := startPort; port <= endPort; port++ {
+				ports = append(ports, port)
+			}
+
+			continue
+		}
+
+		err := validate.IsNetworkPort(portRange)
+		if err != nil {
+			return nil, err
+		}
+
+		port, _ := strconv.ParseUint(portRange, 10, 64)
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
 // InterfaceStatus returns the global unicast IP addresses configured on an interface and whether it is up or not.
 func InterfaceStatus(nicName string) ([]net.IP, bool, error) {
 	iface, err := net.InterfaceByName(nicName)
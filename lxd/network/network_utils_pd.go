@@ -0,0 +1,464 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/subprocess"
+)
+
+// pdAddressAuto is the ipv6.address value requesting the network subnet to be taken from a delegated prefix.
+const pdAddressAuto = "auto-pd"
+
+// pdSubnetSize is the size of the sub-prefixes allocated to networks from the delegated prefix.
+const pdSubnetSize = 64
+
+// Volatile keys recording the prefix delegation state of a network.
+const pdVolatilePrefix = "volatile.ipv6.pd.prefix"
+const pdVolatileSubnetID = "volatile.ipv6.pd.subnet_id"
+const pdVolatileAddress = "volatile.ipv6.pd.address"
+
+// pdClientScript is the dhclient script recording the delegated prefix into a file.
+const pdClientScript = `#!/bin/sh
+# Generated by LXD, records the IPv6 prefix delegated on %s.
+case "${reason}" in
+    BOUND6|RENEW6|REBIND6|REBOOT6)
+        if [ -n "${new_ip6_prefix}" ]; then
+            echo "${new_ip6_prefix}" > "%s.tmp" && mv "%s.tmp" "%s"
+        fi
+        ;;
+    EXPIRE6|RELEASE6|STOP6)
+        rm -f "%s"
+        ;;
+esac
+`
+
+// IPv6Address returns the IPv6 address (in CIDR notation) of a network from its config. When ipv6.address is
+// set to auto-pd, the address derived from the currently delegated prefix is returned (empty if no prefix has
+// been delegated yet).
+func IPv6Address(netConfig map[string]string) string {
+	if netConfig["ipv6.address"] == pdAddressAuto {
+		return netConfig[pdVolatileAddress]
+	}
+
+	return netConfig["ipv6.address"]
+}
+
+// pdPath returns the path of the DHCPv6-PD client files for the interface.
+func pdPath(iface string, file string) string {
+	return shared.VarPath("dhcpv6-pd", iface, file)
+}
+
+// pdClientStart starts the DHCPv6-PD client on the interface if not already running.
+func pdClientStart(iface string, prefixLength string) error {
+	pidPath := pdPath(iface, "dhclient.pid")
+
+	if shared.PathExists(pidPath) {
+		p, err := subprocess.ImportProcess(pidPath)
+		if err == nil {
+			_, err = p.GetPid()
+			if err == nil {
+				return nil // Already running.
+			}
+		}
+	}
+
+	err := os.MkdirAll(shared.VarPath("dhcpv6-pd", iface), 0711)
+	if err != nil {
+		return err
+	}
+
+	prefixPath := pdPath(iface, "prefix")
+	scriptPath := pdPath(iface, "dhclient.script")
+	err = ioutil.WriteFile(scriptPath, []byte(fmt.Sprintf(pdClientScript, iface, prefixPath, prefixPath, prefixPath, prefixPath)), 0700)
+	if err != nil {
+		return errors.Wrapf(err, "Failed writing DHCPv6-PD client script")
+	}
+
+	args := []string{"-6", "-P", "-d",
+		"-lf", pdPath(iface, "dhclient.leases"),
+		"-pf", pdPath(iface, "dhclient.pidfile"),
+		"-sf", scriptPath,
+	}
+
+	if prefixLength != "" {
+		args = append(args, "--prefix-len-hint", prefixLength)
+	}
+
+	args = append(args, iface)
+
+	logPath := shared.LogPath(fmt.Sprintf("dhcpv6-pd.%s.log", iface))
+
+	p, err := subprocess.NewProcess("dhclient", args, logPath, logPath)
+	if err != nil {
+		return fmt.Errorf("Failed to create subprocess: %s", err)
+	}
+
+	err = p.Start()
+	if err != nil {
+		return fmt.Errorf("Failed to run: dhclient %s: %v", strings.Join(args, " "), err)
+	}
+
+	err = p.Save(pidPath)
+	if err != nil {
+		// Kill Process if started, but could not save the file.
+		err2 := p.Stop()
+		if err2 != nil {
+			return fmt.Errorf("Could not kill subprocess while handling saving error: %s: %s", err, err2)
+		}
+
+		return fmt.Errorf("Failed to save subprocess details: %s", err)
+	}
+
+	return nil
+}
+
+// pdClientStop stops the DHCPv6-PD client on the interface. The lease file is kept so that the same prefix is
+// requested when the client is started again.
+func pdClientStop(iface string) error {
+	pidPath := pdPath(iface, "dhclient.pid")
+
+	// If the pid file doesn't exist, there is no process to kill.
+	if !shared.PathExists(pidPath) {
+		return nil
+	}
+
+	p, err := subprocess.ImportProcess(pidPath)
+	if err != nil {
+		return fmt.Errorf("Could not read pid file: %s", err)
+	}
+
+	err = p.Stop()
+	if err != nil && err != subprocess.ErrNotRunning {
+		return fmt.Errorf("Unable to kill dhclient: %s", err)
+	}
+
+	os.Remove(pidPath)
+	os.Remove(pdPath(iface, "prefix"))
+
+	return nil
+}
+
+// pdPrefix returns the prefix currently delegated on the interface, or nil if none has been delegated yet.
+func pdPrefix(iface string) (*net.IPNet, error) {
+	content, err := ioutil.ReadFile(pdPath(iface, "prefix"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	_, prefix, err := net.ParseCIDR(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid delegated prefix")
+	}
+
+	return prefix, nil
+}
+
+// pdSubnet returns the sub-prefix with the given index within the delegated prefix.
+func pdSubnet(prefix *net.IPNet, subnetID uint64) (*net.IPNet, error) {
+	ones, bits := prefix.Mask.Size()
+	if bits != 128 || ones > pdSubnetSize {
+		return nil, fmt.Errorf("Delegated prefix %q is too small, must be at least a /%d", prefix.String(), pdSubnetSize)
+	}
+
+	if ones > 0 && subnetID >= uint64(1)<<uint(pdSubnetSize-ones) {
+		return nil, fmt.Errorf("Subnet ID %d doesn't fit in delegated prefix %q", subnetID, prefix.String())
+	}
+
+	subnetIP := make(net.IP, net.IPv6len)
+	copy(subnetIP, prefix.IP.To16())
+	binary.BigEndian.PutUint64(subnetIP[:8], binary.BigEndian.Uint64(subnetIP[:8])|subnetID)
+
+	return &net.IPNet{IP: subnetIP, Mask: net.CIDRMask(pdSubnetSize, 128)}, nil
+}
+
+// pdNetworkInterface returns the interface on which the prefix used by the network is delegated, or an empty
+// string if the network doesn't use prefix delegation. Bridge networks use their own ipv6.pd.interface while OVN
+// networks use the one of their uplink network.
+func pdNetworkInterface(network api.Network, uplinks map[string]api.Network) string {
+	if network.Config["ipv6.address"] != pdAddressAuto {
+		return ""
+	}
+
+	switch network.Type {
+	case "bridge":
+		return network.Config["ipv6.pd.interface"]
+	case "ovn":
+		uplink, ok := uplinks[network.Config["network"]]
+		if ok {
+			return pdUplinkInterface(uplink.Type, uplink.Config)
+		}
+	}
+
+	return ""
+}
+
+// pdUplinkInterface returns the interface on which the prefix shared with the OVN networks using the uplink network
+// is delegated, or an empty string if the uplink network doesn't delegate a prefix. Bridge uplinks need to use
+// ipv6.address=auto-pd themselves while physical uplinks only need ipv6.pd.interface set.
+func pdUplinkInterface(uplinkType string, uplinkConfig map[string]string) string {
+	switch uplinkType {
+	case "bridge":
+		if uplinkConfig["ipv6.address"] == pdAddressAuto {
+			return uplinkConfig["ipv6.pd.interface"]
+		}
+	case "physical":
+		return uplinkConfig["ipv6.pd.interface"]
+	}
+
+	return ""
+}
+
+// pdUplinkPrefix returns the prefix delegated to the uplink network as recorded in its volatile config, or nil if
+// none has been delegated yet. Unlike the prefix file of the DHCPv6-PD client, this is the same on all the cluster
+// members.
+func pdUplinkPrefix(uplinkConfig map[string]string) *net.IPNet {
+	_, prefix, err := net.ParseCIDR(uplinkConfig[pdVolatilePrefix])
+	if err != nil {
+		return nil
+	}
+
+	return prefix
+}
+
+// pdValidatePrefixLength validates the ipv6.pd.prefix_length setting.
+func pdValidatePrefixLength(value string) error {
+	prefixLength, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || prefixLength > pdSubnetSize {
+		return fmt.Errorf("Invalid prefix length %q, must be between 0 and %d", value, pdSubnetSize)
+	}
+
+	return nil
+}
+
+// pdSubnetID returns the index of the sub-prefix used by the network within the prefix delegated on the
+// interface. If the network doesn't have one set yet, the lowest index not used by the other networks using a
+// prefix delegated on the same interface is allocated.
+func pdSubnetID(s *state.State, iface string, projectName string, networkName string, config map[string]string) (uint64, error) {
+	for _, k := range []string{"ipv6.pd.subnet_id", pdVolatileSubnetID} {
+		if config[k] != "" {
+			return strconv.ParseUint(config[k], 10, 32)
+		}
+	}
+
+	var err error
+	var projectNetworks map[string]map[int64]api.Network
+
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		projectNetworks, err = tx.GetCreatedNetworks()
+		return err
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to load all networks")
+	}
+
+	// Uplink networks are always in the default project.
+	uplinks := map[string]api.Network{}
+	for _, network := range projectNetworks[project.Default] {
+		uplinks[network.Name] = network
+	}
+
+	usedIDs := map[uint64]struct{}{}
+	for netProjectName, networks := range projectNetworks {
+		for _, network := range networks {
+			if netProjectName == projectName && network.Name == networkName {
+				continue // Skip ourselves.
+			}
+
+			if pdNetworkInterface(network, uplinks) != iface {
+				continue
+			}
+
+			for _, k := range []string{"ipv6.pd.subnet_id", pdVolatileSubnetID} {
+				if network.Config[k] == "" {
+					continue
+				}
+
+				subnetID, err := strconv.ParseUint(network.Config[k], 10, 32)
+				if err == nil {
+					usedIDs[subnetID] = struct{}{}
+				}
+
+				break
+			}
+		}
+	}
+
+	subnetID := uint64(0)
+	for {
+		_, used := usedIDs[subnetID]
+		if !used {
+			return subnetID, nil
+		}
+
+		subnetID++
+	}
+}
+
+// pdConfig returns the volatile prefix delegation keys of a network using the given prefix, delegated on the
+// interface. The prefix and address keys are empty if no prefix has been delegated yet (nil prefix).
+func pdConfig(s *state.State, iface string, prefix *net.IPNet, projectName string, networkName string, config map[string]string) (map[string]string, error) {
+	pdKeys := map[string]string{
+		pdVolatilePrefix:   "",
+		pdVolatileSubnetID: config[pdVolatileSubnetID],
+		pdVolatileAddress:  "",
+	}
+
+	if prefix == nil {
+		return pdKeys, nil
+	}
+
+	subnetID, err := pdSubnetID(s, iface, projectName, networkName, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed allocating delegated prefix subnet ID")
+	}
+
+	subnet, err := pdSubnet(prefix, subnetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the first address of the subnet for the network.
+	address := make(net.IP, net.IPv6len)
+	copy(address, subnet.IP)
+	address[net.IPv6len-1] = 1
+
+	pdKeys[pdVolatilePrefix] = prefix.String()
+	pdKeys[pdVolatileAddress] = fmt.Sprintf("%s/%d", address.String(), pdSubnetSize)
+
+	if config["ipv6.pd.subnet_id"] == "" {
+		pdKeys[pdVolatileSubnetID] = fmt.Sprintf("%d", subnetID)
+	}
+
+	return pdKeys, nil
+}
+
+// RefreshPrefixDelegation checks the prefixes delegated to the bridge networks using ipv6.address=auto-pd and to
+// the physical networks with ipv6.pd.interface set, and reconfigures the networks (and their dependent OVN
+// networks) whose prefix has changed. As the prefix of a physical network is shared by the whole cluster, it is
+// only requested by the cluster leader (isLeader) and the other members release it.
+func RefreshPrefixDelegation(s *state.State, isLeader bool) error {
+	// Use project.Default here as bridge and physical networks don't support projects.
+	projectName := project.Default
+
+	networks, err := s.Cluster.GetCreatedNetworks(projectName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range networks {
+		n, err := LoadByName(s, projectName, name)
+		if err != nil {
+			logger.Error("Failed to load network for prefix delegation refresh", log.Ctx{"project": projectName, "network": name, "err": err})
+			continue
+		}
+
+		switch netDriver := n.(type) {
+		case *bridge:
+			if netDriver.config["ipv6.address"] != pdAddressAuto || !netDriver.isRunning() {
+				continue
+			}
+
+			err = netDriver.pdRefresh()
+		case *physical:
+			if netDriver.config["ipv6.pd.interface"] == "" {
+				continue
+			}
+
+			if isLeader {
+				err = netDriver.pdRefresh()
+			} else {
+				err = netDriver.pdRelease(netDriver.config["ipv6.pd.interface"])
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			logger.Error("Failed refreshing delegated prefix", log.Ctx{"project": projectName, "network": name, "err": err})
+		}
+	}
+
+	return nil
+}
+
+// pdRoute is a route of the delegated prefix subnet of an OVN network towards its router's uplink address.
+type pdRoute struct {
+	network string
+	subnet  *net.IPNet
+	router  net.IP
+}
+
+// pdUplinkRoutes returns the routes needed for the delegated prefix subnets of the OVN networks using the uplink
+// network. Only the OVN networks which have picked up the given prefix and whose router's address is within the
+// uplink subnet are included.
+func pdUplinkRoutes(s *state.State, uplinkName string, prefix string, uplinkSubnet *net.IPNet) ([]pdRoute, error) {
+	var err error
+	var projectNetworks map[string]map[int64]api.Network
+
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		projectNetworks, err = tx.GetCreatedNetworks()
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load all networks")
+	}
+
+	routes := []pdRoute{}
+	for _, networks := range projectNetworks {
+		for _, network := range networks {
+			if network.Type != "ovn" || network.Config["network"] != uplinkName || network.Config["ipv6.address"] != pdAddressAuto {
+				continue
+			}
+
+			// Skip networks which haven't picked up the current prefix yet.
+			if prefix == "" || network.Config[pdVolatilePrefix] != prefix {
+				continue
+			}
+
+			_, subnet, err := net.ParseCIDR(network.Config[pdVolatileAddress])
+			if err != nil {
+				continue
+			}
+
+			routerIP := net.ParseIP(network.Config[ovnVolatileUplinkIPv6])
+			if routerIP == nil || !uplinkSubnet.Contains(routerIP) {
+				continue
+			}
+
+			routes = append(routes, pdRoute{network: network.Name, subnet: subnet, router: routerIP})
+		}
+	}
+
+	return routes, nil
+}
+
+// pdChangedKeys returns the volatile prefix delegation keys which differ between the two configs.
+func pdChangedKeys(oldConfig map[string]string, newConfig map[string]string) []string {
+	changedKeys := []string{}
+	for _, k := range []string{pdVolatilePrefix, pdVolatileSubnetID, pdVolatileAddress} {
+		if oldConfig[k] != newConfig[k] {
+			changedKeys = append(changedKeys, k)
+		}
+	}
+
+	return changedKeys
+}
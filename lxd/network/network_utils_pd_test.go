@@ -0,0 +1,102 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/state"
+)
+
+func Test_pdSubnet(t *testing.T) {
+	cases := []struct {
+		prefix   string
+		subnetID uint64
+		subnet   string
+		err      string
+	}{
+		{"2001:db8:1200::/56", 0, "2001:db8:1200::/64", ""},
+		{"2001:db8:1200::/56", 5, "2001:db8:1200:5::/64", ""},
+		{"2001:db8:1200::/56", 255, "2001:db8:1200:ff::/64", ""},
+		{"2001:db8:1200::/56", 256, "", `Subnet ID 256 doesn't fit in delegated prefix "2001:db8:1200::/56"`},
+		{"2001:db8:1200::/48", 0x1234, "2001:db8:1200:1234::/64", ""},
+		{"2001:db8:1200:1::/64", 0, "2001:db8:1200:1::/64", ""},
+		{"2001:db8:1200:1::/64", 1, "", `Subnet ID 1 doesn't fit in delegated prefix "2001:db8:1200:1::/64"`},
+		{"2001:db8:1200:1::/72", 0, "", `Delegated prefix "2001:db8:1200:1::/72" is too small, must be at least a /64`},
+	}
+
+	for _, c := range cases {
+		_, prefix, err := net.ParseCIDR(c.prefix)
+		require.NoError(t, err)
+
+		subnet, err := pdSubnet(prefix, c.subnetID)
+		if c.err != "" {
+			assert.EqualError(t, err, c.err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, c.subnet, subnet.String())
+	}
+}
+
+func Test_pdSubnetID(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	s := &state.State{Cluster: cluster}
+
+	networks := []struct {
+		name    string
+		netType db.NetworkType
+		config  map[string]string
+	}{
+		{"lxdbr0", db.NetworkTypeBridge, map[string]string{"ipv6.address": pdAddressAuto, "ipv6.pd.interface": "eth0", pdVolatileSubnetID: "0"}},
+		{"lxdbr1", db.NetworkTypeBridge, map[string]string{"ipv6.address": pdAddressAuto, "ipv6.pd.interface": "eth0", "ipv6.pd.subnet_id": "1"}},
+		{"lxdbr2", db.NetworkTypeBridge, map[string]string{"ipv6.address": pdAddressAuto, "ipv6.pd.interface": "eth1", pdVolatileSubnetID: "2"}},
+		{"lxdbr3", db.NetworkTypeBridge, map[string]string{"ipv6.address": "fd00::1/64", "ipv6.pd.interface": "eth0", pdVolatileSubnetID: "2"}},
+		{"ovn0", db.NetworkTypeOVN, map[string]string{"ipv6.address": pdAddressAuto, "network": "lxdbr0", pdVolatileSubnetID: "3"}},
+		{"phys0", db.NetworkTypePhysical, map[string]string{"parent": "eth3", "ipv6.pd.interface": "eth2"}},
+		{"ovn1", db.NetworkTypeOVN, map[string]string{"ipv6.address": pdAddressAuto, "network": "phys0", pdVolatileSubnetID: "0"}},
+	}
+
+	for _, network := range networks {
+		_, err := cluster.CreateNetwork(project.Default, network.name, "", network.netType, network.config)
+		require.NoError(t, err)
+	}
+
+	// Configured subnet IDs are used as is.
+	subnetID, err := pdSubnetID(s, "eth0", project.Default, "lxdbr4", map[string]string{"ipv6.pd.subnet_id": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), subnetID)
+
+	subnetID, err = pdSubnetID(s, "eth0", project.Default, "lxdbr4", map[string]string{pdVolatileSubnetID: "7"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), subnetID)
+
+	// The lowest ID not used by the networks using a prefix delegated on the same interface is allocated.
+	subnetID, err = pdSubnetID(s, "eth0", project.Default, "lxdbr4", map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), subnetID)
+
+	subnetID, err = pdSubnetID(s, "eth1", project.Default, "lxdbr4", map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), subnetID)
+
+	// OVN networks using a physical uplink use the prefix delegated on its ipv6.pd.interface.
+	subnetID, err = pdSubnetID(s, "eth2", project.Default, "ovn2", map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), subnetID)
+
+	// The network's own ID isn't considered as used.
+	subnetID, err = pdSubnetID(s, "eth0", project.Default, "lxdbr0", map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), subnetID)
+
+	_, err = pdSubnetID(s, "eth0", project.Default, "lxdbr4", map[string]string{"ipv6.pd.subnet_id": "foo"})
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"net"
 
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
 )
 
 func Example_parseIPRange() {
@@ -137,3 +138,83 @@ func Example_ipRangesOverlap() {
 	// Range1: 10.1.1.8-10.1.1.9, Range2: 10.1.1.4, overlapped: false
 
 }
+
+func Example_parsePortList() {
+	portLists := []string{
+		"80",
+		"80,443",
+		" 80 , 8000-8003 ",
+		"",
+		"8003-8000",
+		"65536",
+		"http",
+	}
+
+	for _, portList := range portLists {
+		ports, err := parsePortList(portList)
+		if err != nil {
+			fmt.Printf("Err: %v\n", err)
+			continue
+		}
+
+		fmt.Printf("Ports: %v\n", ports)
+	}
+
+	// Output: Ports: [80]
+	// Ports: [80 443]
+	// Ports: [80 8000 8001 8002 8003]
+	// Ports: []
+	// Err: Start port 8003 must be lower than end port 8000
+	// Err: Out of port number range (0-65535) "65536"
+	// Err: Invalid port number "http"
+}
+
+func Example_allocationsMarkConflicts() {
+	newAllocation := func(address string, usedBy string, allocationType string, networkName string) allocation {
+		return allocation{
+			NetworkAllocation: api.NetworkAllocation{
+				Address: address,
+				UsedBy:  usedBy,
+				Type:    allocationType,
+				Network: networkName,
+			},
+			networkProject: "default",
+		}
+	}
+
+	allocations := []allocation{
+		newAllocation("10.0.0.1/24", "/1.0/networks/lxdbr0", AllocationTypeNetwork, "lxdbr0"),
+		newAllocation("10.0.0.1", "/1.0/instances/c1", AllocationTypeInstance, "lxdbr0"),
+		newAllocation("10.0.0.1", "/1.0/instances/c2", AllocationTypeInstance, "lxdbr1"),
+		newAllocation("10.0.0.2", "/1.0/instances/c3", AllocationTypeInstance, "lxdbr0"),
+		newAllocation("10.0.0.2", "/1.0/instances/c3", AllocationTypeInstance, "lxdbr0"),
+		newAllocation("192.0.2.1", "/1.0/networks/lxdbr0", AllocationTypeNAT, "lxdbr0"),
+		newAllocation("192.0.2.1", "/1.0/networks/lxdbr1", AllocationTypeNAT, "lxdbr1"),
+		newAllocation("192.0.2.10", "/1.0/instances/c4", AllocationTypeInstance, ""),
+		newAllocation("192.0.2.10", "/1.0/instances/c5", AllocationTypeInstance, ""),
+	}
+
+	allocationsMarkConflicts(allocations)
+
+	result := make([]api.NetworkAllocation, 0, len(allocations))
+	for _, a := range allocations {
+		fmt.Printf("%s %s %v\n", a.UsedBy, a.Address, a.Conflict)
+		result = append(result, a.NetworkAllocation)
+	}
+
+	for _, conflict := range AllocationConflicts(result) {
+		fmt.Println(conflict)
+	}
+
+	// Output: /1.0/networks/lxdbr0 10.0.0.1/24 true
+	// /1.0/instances/c1 10.0.0.1 true
+	// /1.0/instances/c2 10.0.0.1 false
+	// /1.0/instances/c3 10.0.0.2 false
+	// /1.0/instances/c3 10.0.0.2 false
+	// /1.0/networks/lxdbr0 192.0.2.1 false
+	// /1.0/networks/lxdbr1 192.0.2.1 false
+	// /1.0/instances/c4 192.0.2.10 true
+	// /1.0/instances/c5 192.0.2.10 true
+	// Address 10.0.0.1 on network "lxdbr0" used by /1.0/networks/lxdbr0, /1.0/instances/c1
+	// Address 192.0.2.10 on host used by /1.0/instances/c4, /1.0/instances/c5
+}
@@ -1,6 +1,7 @@
 package openvswitch
 
 import (
+	"encoding/csv"
 	"fmt"
 	"net"
 	"strconv"
@@ -45,6 +46,12 @@ type OVNPortGroup string
 // OVNPortGroupUUID OVN port group UUID.
 type OVNPortGroupUUID string
 
+// OVNLoadBalancer OVN load balancer name.
+type OVNLoadBalancer string
+
+// OVNAddressSet OVN address set name.
+type OVNAddressSet string
+
 // OVNIPAllocationOpts defines IP allocation settings that can be applied to a logical switch.
 type OVNIPAllocationOpts struct {
 	PrefixIPv4  *net.IPNet
@@ -126,6 +133,32 @@ type OVNACLRule struct {
 	LogName   string // Log label name (requires Log be true).
 }
 
+// OVNLoadBalancerTarget represents an OVN load balancer Virtual IP target.
+type OVNLoadBalancerTarget struct {
+	Address net.IP
+	Port    uint64
+
+	// Logical switch port owning the target address, used for health checking.
+	SwitchPort OVNSwitchPort
+}
+
+// OVNLoadBalancerVIP represents an OVN load balancer Virtual IP entry.
+type OVNLoadBalancerVIP struct {
+	Protocol      string // Either "tcp" or "udp".
+	ListenAddress net.IP
+	ListenPort    uint64
+	Targets       []OVNLoadBalancerTarget
+}
+
+// OVNLoadBalancerHealthCheck represents the health check settings of an OVN load balancer.
+type OVNLoadBalancerHealthCheck struct {
+	SourceAddress net.IP // Address the probes are sent from, must be in the same subnet as the targets.
+	Interval      uint64
+	Timeout       uint64
+	SuccessCount  uint64
+	FailureCount  uint64
+}
+
 // NewOVN initialises new OVN client wrapper with the connection set in network.ovn.northbound_connection config.
 func NewOVN(s *state.State) (*OVN, error) {
 	nbConnection, err := cluster.ConfigGetString(s.Cluster, "network.ovn.northbound_connection")
@@ -168,6 +201,16 @@ func (o *OVN) nbctl(args ...string) (string, error) {
 	return shared.RunCommand("ovn-nbctl", append([]string{"--db", dbAddr}, args...)...)
 }
 
+// NorthboundPing checks that the northbound database can be reached within timeout seconds.
+func (o *OVN) NorthboundPing(timeout int) error {
+	_, err := o.nbctl(fmt.Sprintf("--timeout=%d", timeout), "--bare", "--columns=_uuid", "list", "NB_Global")
+	if err != nil {
+		return errors.Wrapf(err, "Failed connecting to OVN northbound database %q", o.getNorthboundDB())
+	}
+
+	return nil
+}
+
 // LogicalRouterAdd adds a named logical router.
 func (o *OVN) LogicalRouterAdd(routerName OVNRouter, mayExist bool) error {
 	args := []string{}
@@ -1525,3 +1568,237 @@ func (o *OVN) PortGroupPortClearACLRules(portGroupName OVNPortGroup, portName OV
 
 	return nil
 }
+
+// LogicalSwitchPortIPs returns a map of the static and dynamic IPs of each port of a logical switch.
+func (o *OVN) LogicalSwitchPortIPs(switchName OVNSwitch) (map[OVNSwitchPort][]net.IP, error) {
+	switchPorts, err := o.LogicalSwitchPorts(switchName)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--columns=name,addresses,dynamic_addresses", "list", "logical_switch_port")
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed parsing logical switch ports")
+	}
+
+	portIPs := make(map[OVNSwitchPort][]net.IP, len(switchPorts))
+	for _, record := range records {
+		if len(record) != 3 {
+			continue
+		}
+
+		portName := OVNSwitchPort(record[0])
+		_, found := switchPorts[portName]
+		if !found {
+			continue
+		}
+
+		for _, address := range strings.Fields(record[1] + " " + record[2]) {
+			ip := net.ParseIP(address)
+			if ip != nil {
+				portIPs[portName] = append(portIPs[portName], ip)
+			}
+		}
+	}
+
+	return portIPs, nil
+}
+
+// loadBalancerProtocolName returns the name of the per-protocol OVN load balancer.
+func (o *OVN) loadBalancerProtocolName(loadBalancerName OVNLoadBalancer, protocol string) OVNLoadBalancer {
+	return OVNLoadBalancer(fmt.Sprintf("%s-%s", loadBalancerName, protocol))
+}
+
+// LoadBalancerApply creates a new load balancer (or replaces an existing one) and assigns it to the specified
+// routers and switches. As OVN load balancers only support a single protocol, one load balancer is created per
+// protocol in use. If healthCheck is not nil, health checking of the IPv4 targets is enabled.
+func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNRouter, switches []OVNSwitch, healthCheck *OVNLoadBalancerHealthCheck, vips ...OVNLoadBalancerVIP) error {
+	args := []string{}
+
+	// Remove the existing load balancers (and their associations) so they are re-created from scratch.
+	for _, protocol := range []string{"tcp", "udp"} {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		args = append(args, "--if-exists", "lb-del", string(o.loadBalancerProtocolName(loadBalancerName, protocol)))
+	}
+
+	ipPort := func(ip net.IP, port uint64) string {
+		if ip.To4() == nil {
+			return fmt.Sprintf("[%s]:%d", ip.String(), port)
+		}
+
+		return fmt.Sprintf("%s:%d", ip.String(), port)
+	}
+
+	lbNames := []OVNLoadBalancer{}
+	lbUsed := map[OVNLoadBalancer]struct{}{}
+	for i, vip := range vips {
+		if len(vip.Targets) <= 0 {
+			continue
+		}
+
+		if !shared.StringInSlice(vip.Protocol, []string{"tcp", "udp"}) {
+			return fmt.Errorf("Invalid load balancer protocol %q", vip.Protocol)
+		}
+
+		lbName := o.loadBalancerProtocolName(loadBalancerName, vip.Protocol)
+		_, found := lbUsed[lbName]
+		if !found {
+			lbUsed[lbName] = struct{}{}
+			lbNames = append(lbNames, lbName)
+		}
+
+		targets := make([]string, 0, len(vip.Targets))
+		for _, target := range vip.Targets {
+			targets = append(targets, ipPort(target.Address, target.Port))
+		}
+
+		listen := ipPort(vip.ListenAddress, vip.ListenPort)
+		args = append(args, "--", "lb-add", string(lbName), listen, strings.Join(targets, ","), vip.Protocol)
+
+		// OVN only supports health checking IPv4 targets.
+		if healthCheck == nil || vip.ListenAddress.To4() == nil {
+			continue
+		}
+
+		hcID := fmt.Sprintf("@hc%d", i)
+		args = append(args, "--", fmt.Sprintf("--id=%s", hcID), "create", "load_balancer_health_check",
+			fmt.Sprintf("vip=%q", listen),
+			fmt.Sprintf("options:interval=%d", healthCheck.Interval),
+			fmt.Sprintf("options:timeout=%d", healthCheck.Timeout),
+			fmt.Sprintf("options:success_count=%d", healthCheck.SuccessCount),
+			fmt.Sprintf("options:failure_count=%d", healthCheck.FailureCount),
+		)
+		args = append(args, "--", "add", "load_balancer", string(lbName), "health_check", hcID)
+
+		for _, target := range vip.Targets {
+			if target.SwitchPort == "" {
+				continue
+			}
+
+			args = append(args, "--", "set", "load_balancer", string(lbName),
+				fmt.Sprintf("ip_port_mappings:%q=%q", target.Address.String(), fmt.Sprintf("%s:%s", target.SwitchPort, healthCheck.SourceAddress.String())),
+			)
+		}
+	}
+
+	// Associate the load balancers with the routers and switches.
+	for _, lbName := range lbNames {
+		for _, router := range routers {
+			args = append(args, "--", "lr-lb-add", string(router), string(lbName))
+		}
+
+		for _, switchName := range switches {
+			args = append(args, "--", "ls-lb-add", string(switchName), string(lbName))
+		}
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadBalancerDelete deletes the specified load balancers (and their associations).
+func (o *OVN) LoadBalancerDelete(loadBalancerNames ...OVNLoadBalancer) error {
+	args := []string{}
+
+	for _, loadBalancerName := range loadBalancerNames {
+		for _, protocol := range []string{"tcp", "udp"} {
+			if len(args) > 0 {
+				args = append(args, "--")
+			}
+
+			args = append(args, "--if-exists", "lb-del", string(o.loadBalancerProtocolName(loadBalancerName, protocol)))
+		}
+	}
+
+	if len(args) <= 0 {
+		return nil
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addressSetFamilyName returns the name of the per IP family OVN address set.
+func (o *OVN) addressSetFamilyName(addressSetName OVNAddressSet, ipVersion uint) OVNAddressSet {
+	return OVNAddressSet(fmt.Sprintf("%s_ip%d", addressSetName, ipVersion))
+}
+
+// AddressSetApply creates a new address set (or replaces an existing one) with the specified addresses.
+// As OVN address sets must contain a single IP family, one address set is created per IP family (with "_ip4" and
+// "_ip6" suffixes), and both are always created so they can be referenced in ACL rules even if empty.
+func (o *OVN) AddressSetApply(projectID int64, addressSetName OVNAddressSet, addresses ...*net.IPNet) error {
+	familyAddresses := map[uint][]string{4: {}, 6: {}}
+	for _, address := range addresses {
+		var ipVersion uint = 4
+		if address.IP.To4() == nil {
+			ipVersion = 6
+		}
+
+		familyAddresses[ipVersion] = append(familyAddresses[ipVersion], fmt.Sprintf("%q", address.String()))
+	}
+
+	args := []string{}
+	for _, ipVersion := range []uint{4, 6} {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		familyName := o.addressSetFamilyName(addressSetName, ipVersion)
+
+		// Address sets are referenced by name in ACL rules, so they can be safely re-created.
+		args = append(args, "--if-exists", "destroy", "address_set", string(familyName), "--",
+			"create", "address_set", fmt.Sprintf("name=%s", familyName),
+			fmt.Sprintf("addresses=[%s]", strings.Join(familyAddresses[ipVersion], ",")),
+			fmt.Sprintf("external_ids:%s=%d", ovnExtIDLXDProjectID, projectID),
+		)
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddressSetDelete deletes the specified address sets (for both IP families).
+func (o *OVN) AddressSetDelete(addressSetNames ...OVNAddressSet) error {
+	args := []string{}
+
+	for _, addressSetName := range addressSetNames {
+		for _, ipVersion := range []uint{4, 6} {
+			if len(args) > 0 {
+				args = append(args, "--")
+			}
+
+			args = append(args, "--if-exists", "destroy", "address_set", string(o.addressSetFamilyName(addressSetName, ipVersion)))
+		}
+	}
+
+	if len(args) <= 0 {
+		return nil
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,529 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/network/acl"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var networkAddressSetsCmd = APIEndpoint{
+	Path: "network-address-sets",
+
+	Get:  APIEndpointAction{Handler: networkAddressSetsGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Post: APIEndpointAction{Handler: networkAddressSetsPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+var networkAddressSetCmd = APIEndpoint{
+	Path: "network-address-sets/{name}",
+
+	Delete: APIEndpointAction{Handler: networkAddressSetDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Get:    APIEndpointAction{Handler: networkAddressSetGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Put:    APIEndpointAction{Handler: networkAddressSetPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Patch:  APIEndpointAction{Handler: networkAddressSetPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Post:   APIEndpointAction{Handler: networkAddressSetPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-address-sets network-address-sets network_address_sets_get
+//
+// Get the network address sets
+//
+// Returns a list of network address sets (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/network-address-sets/foo",
+//               "/1.0/network-address-sets/bar"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/network-address-sets?recursion=1 network-address-sets network_address_sets_get_recursion1
+//
+// Get the network address sets
+//
+// Returns a list of network address sets (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network address sets
+//           items:
+//             $ref: "#/definitions/NetworkAddressSet"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAddressSetsGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	// Get list of Network address sets.
+	addressSetNames, err := d.cluster.GetNetworkAddressSets(projectName)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.NetworkAddressSet{}
+	for _, addressSetName := range addressSetNames {
+		if !recursion {
+			resultString = append(resultString, fmt.Sprintf("/%s/network-address-sets/%s", version.APIVersion, addressSetName))
+		} else {
+			addressSet, err := acl.LoadAddressSetByName(d.State(), projectName, addressSetName)
+			if err != nil {
+				continue
+			}
+
+			addressSetInfo := addressSet.Info()
+			addressSetInfo.UsedBy, _ = addressSet.UsedBy() // Ignore errors in UsedBy, will return nil.
+
+			resultMap = append(resultMap, *addressSetInfo)
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/network-address-sets network-address-sets network_address_sets_post
+//
+// Add a network address set
+//
+// Creates a new network address set.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: address set
+//     description: Address set
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkAddressSetsPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAddressSetsPost(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkAddressSetsPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = acl.LoadAddressSetByName(d.State(), projectName, req.Name)
+	if err == nil {
+		return response.BadRequest(fmt.Errorf("The network address set already exists"))
+	}
+
+	err = acl.CreateAddressSet(d.State(), projectName, &req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	addressSet, err := acl.LoadAddressSetByName(d.State(), projectName, req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkAddressSetCreated.Event(addressSet, request.CreateRequestor(r), nil))
+
+	url := fmt.Sprintf("/%s/network-address-sets/%s", version.APIVersion, req.Name)
+	return response.SyncResponseLocation(true, nil, url)
+}
+
+// swagger:operation DELETE /1.0/network-address-sets/{name} network-address-sets network_address_sets_delete
+//
+// Delete the network address set
+//
+// Removes the network address set.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAddressSetDelete(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	addressSet, err := acl.LoadAddressSetByName(d.State(), projectName, mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = addressSet.Delete(clientType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clientType == clusterRequest.ClientTypeNotifier {
+		return response.EmptySyncResponse
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkAddressSetDeleted.Event(addressSet, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/network-address-sets/{name} network-address-sets network_address_sets_get
+//
+// Get the network address set
+//
+// Gets a specific network address set.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: Address set
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/NetworkAddressSet"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAddressSetGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	addressSet, err := acl.LoadAddressSetByName(d.State(), projectName, mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := addressSet.Info()
+	info.UsedBy, err = addressSet.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, info, addressSet.Etag())
+}
+
+// swagger:operation PATCH /1.0/network-address-sets/{name} network-address-sets network_address_sets_patch
+//
+// Partially update the network address set
+//
+// Updates a subset of the network address set configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: address set
+//     description: Address set configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkAddressSetPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/network-address-sets/{name} network-address-sets network_address_sets_put
+//
+// Update the network address set
+//
+// Updates the entire network address set configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: address set
+//     description: Address set configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkAddressSetPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAddressSetPut(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the existing Network address set.
+	addressSet, err := acl.LoadAddressSetByName(d.State(), projectName, mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, addressSet.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkAddressSetPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range addressSet.Info().Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+	}
+
+	err = addressSet.Update(&req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkAddressSetUpdated.Event(addressSet, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/network-address-sets/{name} network-address-sets network_address_sets_post
+//
+// Rename the network address set
+//
+// Renames an existing network address set.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: address set
+//     description: Address set rename request
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkAddressSetPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAddressSetPost(d *Daemon, r *http.Request) response.Response {
+	name := mux.Vars(r)["name"]
+
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkAddressSetPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing Network address set.
+	addressSet, err := acl.LoadAddressSetByName(d.State(), projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = addressSet.Rename(req.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkAddressSetRenamed.Event(addressSet, request.CreateRequestor(r), log.Ctx{"old_name": name}))
+
+	url := fmt.Sprintf("/%s/network-address-sets/%s", version.APIVersion, req.Name)
+	return response.SyncResponseLocation(true, nil, url)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/rbac"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/shared"
+)
+
+var networkAllocationsCmd = APIEndpoint{
+	Path: "network-allocations",
+
+	Get: APIEndpointAction{Handler: networkAllocationsGet, AccessHandler: allowProjectPermission("networks", "view")},
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-allocations network-allocations network_allocations_get
+//
+// Get the network allocations
+//
+// Returns a list of the addresses allocated to instances and networks.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: all-projects
+//     description: Retrieve the allocations of all projects
+//     type: boolean
+//     example: true
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network allocations
+//           items:
+//             $ref: "#/definitions/NetworkAllocation"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAllocationsGet(d *Daemon, r *http.Request) response.Response {
+	projectName := projectParam(r)
+
+	// Listing the allocations of all projects requires access to all of them.
+	if shared.IsTrue(queryParam(r, "all-projects")) {
+		if !rbac.UserIsAdmin(r) {
+			return response.Forbidden(nil)
+		}
+
+		projectName = ""
+	}
+
+	allocations, err := network.Allocations(d.State(), projectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, allocations)
+}
@@ -0,0 +1,522 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var networkLoadBalancersCmd = APIEndpoint{
+	Path: "networks/{networkName}/load-balancers",
+
+	Get:  APIEndpointAction{Handler: networkLoadBalancersGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Post: APIEndpointAction{Handler: networkLoadBalancersPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+var networkLoadBalancerCmd = APIEndpoint{
+	Path: "networks/{networkName}/load-balancers/{listenAddress}",
+
+	Delete: APIEndpointAction{Handler: networkLoadBalancerDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Get:    APIEndpointAction{Handler: networkLoadBalancerGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Put:    APIEndpointAction{Handler: networkLoadBalancerPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Patch:  APIEndpointAction{Handler: networkLoadBalancerPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+// networkLoadBalancerLoadNetwork loads the network a load balancer request refers to.
+func networkLoadBalancerLoadNetwork(d *Daemon, r *http.Request) (network.Network, error) {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return nil, err
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return nil, err
+	}
+
+	return network.LoadByName(d.State(), projectName, networkName)
+}
+
+// networkLoadBalancerListenAddress returns the normalised listen address from the request URL.
+func networkLoadBalancerListenAddress(r *http.Request) (string, error) {
+	listenAddress, err := url.PathUnescape(mux.Vars(r)["listenAddress"])
+	if err != nil {
+		return "", err
+	}
+
+	ip := net.ParseIP(listenAddress)
+	if ip == nil {
+		return "", fmt.Errorf("Invalid listen address %q", listenAddress)
+	}
+
+	return ip.String(), nil
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/networks/{networkName}/load-balancers network-load-balancers network_load_balancers_get
+//
+// Get the network load balancers
+//
+// Returns a list of network load balancers (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/networks/ovn0/load-balancers/192.0.2.1",
+//               "/1.0/networks/ovn0/load-balancers/192.0.2.2"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/load-balancers?recursion=1 network-load-balancers network_load_balancers_get_recursion1
+//
+// Get the network load balancers
+//
+// Returns a list of network load balancers (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network load balancers
+//           items:
+//             $ref: "#/definitions/NetworkLoadBalancer"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkLoadBalancersGet(d *Daemon, r *http.Request) response.Response {
+	n, err := networkLoadBalancerLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	loadBalancers, err := d.cluster.GetNetworkLoadBalancers(n.ID())
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	if !recursion {
+		resultString := make([]string, 0, len(loadBalancers))
+		for _, loadBalancer := range loadBalancers {
+			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s/load-balancers/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(loadBalancer.ListenAddress)))
+		}
+
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, loadBalancers)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/load-balancers network-load-balancers network_load_balancers_post
+//
+// Add a network load balancer
+//
+// Creates a new network load balancer.
+// If no listen address is specified, one is allocated from the uplink network.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: loadBalancer
+//     description: Load balancer
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkLoadBalancersPost"
+// responses:
+//   "200":
+//     description: Load balancer
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/NetworkLoadBalancer"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkLoadBalancersPost(d *Daemon, r *http.Request) response.Response {
+	n, err := networkLoadBalancerLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkLoadBalancersPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	listenAddress, err := n.LoadBalancerCreate(req, clientType)
+	if err == network.ErrNotImplemented {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support load balancers", n.Type()))
+	} else if err != nil {
+		return response.SmartError(err)
+	}
+
+	loadBalancer := api.NetworkLoadBalancer{
+		NetworkLoadBalancerPut: req.NetworkLoadBalancerPut,
+		ListenAddress:          listenAddress.String(),
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		d.State().Events.SendLifecycle(n.Project(), lifecycle.NetworkLoadBalancerCreated.Event(n, loadBalancer.ListenAddress, request.CreateRequestor(r), nil))
+	}
+
+	u := fmt.Sprintf("/%s/networks/%s/load-balancers/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(loadBalancer.ListenAddress))
+	return response.SyncResponseLocation(true, loadBalancer, u)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/load-balancers/{listenAddress} network-load-balancers network_load_balancer_delete
+//
+// Delete the network load balancer
+//
+// Removes the network load balancer.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkLoadBalancerDelete(d *Daemon, r *http.Request) response.Response {
+	n, err := networkLoadBalancerLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	listenAddress, err := networkLoadBalancerListenAddress(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.LoadBalancerDelete(listenAddress, clientType)
+	if err == network.ErrNotImplemented {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support load balancers", n.Type()))
+	} else if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		d.State().Events.SendLifecycle(n.Project(), lifecycle.NetworkLoadBalancerDeleted.Event(n, listenAddress, request.CreateRequestor(r), nil))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/load-balancers/{listenAddress} network-load-balancers network_load_balancer_get
+//
+// Get the network load balancer
+//
+// Gets a specific network load balancer.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: Load balancer
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/NetworkLoadBalancer"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkLoadBalancerGet(d *Daemon, r *http.Request) response.Response {
+	n, err := networkLoadBalancerLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	listenAddress, err := networkLoadBalancerListenAddress(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, loadBalancer, err := d.cluster.GetNetworkLoadBalancer(n.ID(), listenAddress)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, loadBalancer, loadBalancer.Etag())
+}
+
+// swagger:operation PATCH /1.0/networks/{networkName}/load-balancers/{listenAddress} network-load-balancers network_load_balancer_patch
+//
+// Partially update the network load balancer
+//
+// Updates a subset of the network load balancer configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: loadBalancer
+//     description: Load balancer configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkLoadBalancerPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/networks/{networkName}/load-balancers/{listenAddress} network-load-balancers network_load_balancer_put
+//
+// Update the network load balancer
+//
+// Updates the entire network load balancer configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: loadBalancer
+//     description: Load balancer configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkLoadBalancerPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkLoadBalancerPut(d *Daemon, r *http.Request) response.Response {
+	n, err := networkLoadBalancerLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	listenAddress, err := networkLoadBalancerListenAddress(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing load balancer.
+	_, loadBalancer, err := d.cluster.GetNetworkLoadBalancer(n.ID(), listenAddress)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, loadBalancer.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkLoadBalancerPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config, and keep the existing backends and ports if not provided.
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		for k, v := range loadBalancer.Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+
+		if req.Backends == nil {
+			req.Backends = loadBalancer.Backends
+		}
+
+		if req.Ports == nil {
+			req.Ports = loadBalancer.Ports
+		}
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.LoadBalancerUpdate(listenAddress, req, clientType)
+	if err == network.ErrNotImplemented {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support load balancers", n.Type()))
+	} else if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		d.State().Events.SendLifecycle(n.Project(), lifecycle.NetworkLoadBalancerUpdated.Event(n, listenAddress, request.CreateRequestor(r), nil))
+	}
+
+	return response.EmptySyncResponse
+}
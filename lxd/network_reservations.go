@@ -0,0 +1,478 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var networkReservationsCmd = APIEndpoint{
+	Path: "networks/{networkName}/reservations",
+
+	Get:  APIEndpointAction{Handler: networkReservationsGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Post: APIEndpointAction{Handler: networkReservationsPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+var networkReservationCmd = APIEndpoint{
+	Path: "networks/{networkName}/reservations/{hwaddr}",
+
+	Delete: APIEndpointAction{Handler: networkReservationDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Get:    APIEndpointAction{Handler: networkReservationGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Put:    APIEndpointAction{Handler: networkReservationPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Patch:  APIEndpointAction{Handler: networkReservationPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+// networkReservationLoadNetwork loads the network a DHCP reservation request refers to.
+func networkReservationLoadNetwork(d *Daemon, r *http.Request) (network.Network, error) {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return nil, err
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return nil, err
+	}
+
+	return network.LoadByName(d.State(), projectName, networkName)
+}
+
+// networkReservationHwaddr returns the normalised hardware address from the request URL.
+func networkReservationHwaddr(r *http.Request) (string, error) {
+	hwaddr, err := url.PathUnescape(mux.Vars(r)["hwaddr"])
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(hwaddr), nil
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/networks/{networkName}/reservations network-reservations network_reservations_get
+//
+// Get the network DHCP reservations
+//
+// Returns a list of network DHCP reservations (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/networks/lxdbr0/reservations/00:16:3e:2c:89:d9",
+//               "/1.0/networks/lxdbr0/reservations/00:16:3e:2c:89:da"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/reservations?recursion=1 network-reservations network_reservations_get_recursion1
+//
+// Get the network DHCP reservations
+//
+// Returns a list of network DHCP reservations (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network DHCP reservations
+//           items:
+//             $ref: "#/definitions/NetworkReservation"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationsGet(d *Daemon, r *http.Request) response.Response {
+	n, err := networkReservationLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	reservations, err := d.cluster.GetNetworkReservations(n.ID())
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	if !recursion {
+		resultString := make([]string, 0, len(reservations))
+		for _, reservation := range reservations {
+			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s/reservations/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(reservation.Hwaddr)))
+		}
+
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, reservations)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/reservations network-reservations network_reservations_post
+//
+// Add a network DHCP reservation
+//
+// Creates a new network DHCP reservation.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: reservation
+//     description: DHCP reservation
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkReservationsPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationsPost(d *Daemon, r *http.Request) response.Response {
+	n, err := networkReservationLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkReservationsPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Hwaddr = strings.ToLower(req.Hwaddr)
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.ReservationCreate(req, clientType)
+	if err == network.ErrNotImplemented {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support DHCP reservations", n.Type()))
+	} else if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		d.State().Events.SendLifecycle(n.Project(), lifecycle.NetworkReservationCreated.Event(n, req.Hwaddr, request.CreateRequestor(r), nil))
+	}
+
+	u := fmt.Sprintf("/%s/networks/%s/reservations/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(req.Hwaddr))
+	return response.SyncResponseLocation(true, nil, u)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/reservations/{hwaddr} network-reservations network_reservation_delete
+//
+// Delete the network DHCP reservation
+//
+// Removes the network DHCP reservation.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationDelete(d *Daemon, r *http.Request) response.Response {
+	n, err := networkReservationLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	hwaddr, err := networkReservationHwaddr(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.ReservationDelete(hwaddr, clientType)
+	if err == network.ErrNotImplemented {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support DHCP reservations", n.Type()))
+	} else if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		d.State().Events.SendLifecycle(n.Project(), lifecycle.NetworkReservationDeleted.Event(n, hwaddr, request.CreateRequestor(r), nil))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/reservations/{hwaddr} network-reservations network_reservation_get
+//
+// Get the network DHCP reservation
+//
+// Gets a specific network DHCP reservation.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: DHCP reservation
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/NetworkReservation"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationGet(d *Daemon, r *http.Request) response.Response {
+	n, err := networkReservationLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	hwaddr, err := networkReservationHwaddr(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, reservation, err := d.cluster.GetNetworkReservation(n.ID(), hwaddr)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, reservation, reservation.Etag())
+}
+
+// swagger:operation PATCH /1.0/networks/{networkName}/reservations/{hwaddr} network-reservations network_reservation_patch
+//
+// Partially update the network DHCP reservation
+//
+// Updates a subset of the network DHCP reservation fields.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: reservation
+//     description: DHCP reservation
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkReservationPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/networks/{networkName}/reservations/{hwaddr} network-reservations network_reservation_put
+//
+// Update the network DHCP reservation
+//
+// Updates the entire network DHCP reservation.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: reservation
+//     description: DHCP reservation
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkReservationPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationPut(d *Daemon, r *http.Request) response.Response {
+	n, err := networkReservationLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	hwaddr, err := networkReservationHwaddr(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing reservation.
+	_, reservation, err := d.cluster.GetNetworkReservation(n.ID(), hwaddr)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, reservation.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkReservationPut{}
+
+	// If being updated via "patch" method, then start from the existing fields so that only the fields present
+	// in the request are changed.
+	if r.Method == http.MethodPatch {
+		req = reservation.Writable()
+	}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.ReservationUpdate(hwaddr, req, clientType)
+	if err == network.ErrNotImplemented {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support DHCP reservations", n.Type()))
+	} else if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clientType != clusterRequest.ClientTypeNotifier {
+		d.State().Events.SendLifecycle(n.Project(), lifecycle.NetworkReservationUpdated.Event(n, hwaddr, request.CreateRequestor(r), nil))
+	}
+
+	return response.EmptySyncResponse
+}
@@ -1649,9 +1649,22 @@ func networkStateGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	name := mux.Vars(r)["name"]
 
-	state, err := resources.GetNetworkState(name)
+	// Managed networks can add driver specific information to the interface state.
+	var state *api.NetworkState
+	n, err := network.LoadByName(d.State(), projectName, name)
+	if err == nil {
+		state, err = n.State()
+	} else if err == db.ErrNoSuchObject {
+		state, err = resources.GetNetworkState(name)
+	}
+
 	if err != nil {
 		return response.SmartError(err)
 	}
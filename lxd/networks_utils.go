@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/node"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/task"
+	log "github.com/lxc/lxd/shared/log15"
 	"github.com/lxc/lxd/shared/logger"
 )
 
@@ -49,3 +57,47 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 
 	return nil
 }
+
+// networkWireguardRefreshTask runs every minute and rotates the keys and refreshes the peers of the local WireGuard
+// networks, picking up changes made by the other cluster members.
+func networkWireguardRefreshTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := network.RefreshWireguard(d.State())
+		if err != nil {
+			logger.Error("Failed refreshing WireGuard networks", log.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// networkPrefixDelegationRefreshTask runs every minute and reconfigures the networks using IPv6 prefix delegation
+// whose delegated prefix has changed. The prefixes shared by the whole cluster are only requested by the leader.
+func networkPrefixDelegationRefreshTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		isLeader := true
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && errors.Cause(err) != cluster.ErrNodeIsNotClustered {
+			logger.Error("Failed to get cluster leader address", log.Ctx{"err": err})
+			return
+		}
+
+		if err == nil {
+			localAddress, err := node.ClusterAddress(d.db)
+			if err != nil {
+				logger.Error("Failed to get local cluster address", log.Ctx{"err": err})
+				return
+			}
+
+			isLeader = localAddress == leader
+		}
+
+		err = network.RefreshPrefixDelegation(d.State(), isLeader)
+		if err != nil {
+			logger.Error("Failed refreshing IPv6 delegated prefixes", log.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
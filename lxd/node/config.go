@@ -7,6 +7,7 @@ import (
 	"github.com/lxc/lxd/lxd/config"
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/validate"
 	"github.com/pkg/errors"
 )
 
@@ -51,6 +52,16 @@ func (c *Config) DebugAddress() string {
 	return c.m.GetString("core.debug_address")
 }
 
+// BGPAddress returns the address and port to setup the BGP listener on.
+func (c *Config) BGPAddress() string {
+	return c.m.GetString("core.bgp_address")
+}
+
+// BGPRouterID returns the address to use as a router ID for the BGP server.
+func (c *Config) BGPRouterID() string {
+	return c.m.GetString("core.bgp_routerid")
+}
+
 // MAASMachine returns the MAAS machine this instance is associated with, if
 // any.
 func (c *Config) MAASMachine() string {
@@ -161,6 +172,12 @@ var ConfigSchema = config.Schema{
 	// Network address for the debug server
 	"core.debug_address": {},
 
+	// Network address for the BGP server
+	"core.bgp_address": {Validator: validateBGPAddress},
+
+	// Unique router ID for the BGP server
+	"core.bgp_routerid": {Validator: validate.Optional(validate.IsNetworkAddressV4)},
+
 	// MAAS machine this LXD instance is associated with
 	"maas.machine": {},
 
@@ -169,6 +186,19 @@ var ConfigSchema = config.Schema{
 	"storage.images_volume":  {},
 }
 
+func validateBGPAddress(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	_, _, err := net.SplitHostPort(value)
+	if err != nil {
+		return errors.Wrap(err, "Address not in form of <HOST>:<PORT>")
+	}
+
+	return nil
+}
+
 func validateClusterHTTPSAddress(value string) error {
 	if value == "" {
 		return nil // Deleting entry
@@ -57,8 +57,10 @@ func AllowInstanceCreation(tx *db.ClusterTx, projectName string, req api.Instanc
 	// Add the instance being created.
 	info.Instances = append(info.Instances, db.Instance{
 		Name:     req.Name,
+		Type:     instanceType,
 		Profiles: req.Profiles,
 		Config:   req.Config,
+		Devices:  req.Devices,
 		Project:  projectName,
 	})
 
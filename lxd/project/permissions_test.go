@@ -159,3 +159,44 @@ func TestAllowInstanceCreation_AboveInstances(t *testing.T) {
 	err = project.AllowInstanceCreation(tx, "p1", req)
 	assert.EqualError(t, err, `Reached maximum number of instances in project "p1"`)
 }
+
+// If the project is restricted, the config and devices of the new instance
+// are checked against the restrictions.
+func TestAllowInstanceCreation_Restricted(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	_, err := tx.CreateProject(api.ProjectsPost{
+		Name: "p1",
+		ProjectPut: api.ProjectPut{
+			Config: map[string]string{
+				"restricted": "true",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	req := api.InstancesPost{
+		Name: "c1",
+		Type: api.InstanceTypeContainer,
+		InstancePut: api.InstancePut{
+			Config: map[string]string{"security.privileged": "true"},
+		},
+	}
+
+	err = project.AllowInstanceCreation(tx, "p1", req)
+	assert.EqualError(t, err, `Invalid value "true" for config "security.privileged" on container "c1" of project "p1": Privileged containers are forbidden`)
+
+	req = api.InstancesPost{
+		Name: "c1",
+		Type: api.InstanceTypeContainer,
+		InstancePut: api.InstancePut{
+			Devices: map[string]map[string]string{
+				"tty": {"type": "unix-char", "path": "/dev/ttyS0"},
+			},
+		},
+	}
+
+	err = project.AllowInstanceCreation(tx, "p1", req)
+	assert.EqualError(t, err, `Invalid device "tty" on instance "c1" of project "p1": Unix character devices are forbidden`)
+}
@@ -341,6 +341,272 @@ func (b *lxdBackend) ApplyPatch(name string) error {
 	return nil
 }
 
+// ListUnknownVolumes returns volumes that exist on the storage pool but don't have records in the database.
+// Returns the unknown volumes parsed/generated backup config in a slice (keyed on project name).
+func (b *lxdBackend) ListUnknownVolumes(op *operations.Operation) (map[string][]*backup.Config, error) {
+	logger := logging.AddContext(b.logger, nil)
+	logger.Debug("ListUnknownVolumes started")
+	defer logger.Debug("ListUnknownVolumes finished")
+
+	// Get a list of volumes on the storage pool. We only expect to get 1 volume per logical LXD volume.
+	// So for VMs we only expect to get the block volume for a VM and not its filesystem one too.
+	poolVols, err := b.driver.ListVolumes()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed getting pool volumes")
+	}
+
+	projectVols := make(map[string][]*backup.Config)
+
+	for _, poolVol := range poolVols {
+		volType := poolVol.Type()
+
+		// If the storage driver has returned a filesystem volume for a VM, this is a break of protocol.
+		if volType == drivers.VolumeTypeVM && poolVol.ContentType() != drivers.ContentTypeBlock {
+			return nil, fmt.Errorf("Storage driver returned unexpected VM volume with filesystem content type (%q)", poolVol.Name())
+		}
+
+		if volType == drivers.VolumeTypeVM || volType == drivers.VolumeTypeContainer {
+			err = b.detectUnknownInstanceVolume(&poolVol, projectVols, op)
+			if err != nil {
+				return nil, err
+			}
+		} else if volType == drivers.VolumeTypeCustom {
+			err = b.detectUnknownCustomVolume(&poolVol, projectVols, op)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return projectVols, nil
+}
+
+// detectUnknownInstanceVolume detects if a volume is unknown and if so attempts to mount the volume and parse the
+// backup stored on it. It then runs a series of consistency checks that compare the contents of the backup file to
+// the state of the volume on disk, and if all checks out, it adds the parsed backup file contents to projectVols.
+func (b *lxdBackend) detectUnknownInstanceVolume(vol *drivers.Volume, projectVols map[string][]*backup.Config, op *operations.Operation) error {
+	volType := vol.Type()
+
+	volDBType, err := VolumeTypeToDBType(volType)
+	if err != nil {
+		return err
+	}
+
+	projectName, instName := project.InstanceParts(vol.Name())
+
+	// Check if an entry for the instance already exists in the DB.
+	instID, err := b.state.Cluster.GetInstanceID(projectName, instName)
+	if err != nil && err != db.ErrNoSuchObject {
+		return err
+	}
+
+	instSnapshots, err := b.state.Cluster.GetInstanceSnapshotsNames(projectName, instName)
+	if err != nil {
+		return err
+	}
+
+	// Check if any entry for the instance volume already exists in the DB.
+	// This will return no record for any temporary pool structs being used (as ID is -1).
+	volID, _, err := b.state.Cluster.GetLocalStoragePoolVolume(projectName, instName, volDBType, b.ID())
+	if err != nil && err != db.ErrNoSuchObject {
+		return err
+	}
+
+	if instID > 0 && volID > 0 {
+		return nil // Instance record and storage record already exists in DB, no recovery needed.
+	} else if instID > 0 {
+		return fmt.Errorf("Instance %q in project %q already has instance DB record", instName, projectName)
+	} else if volID > 0 {
+		return fmt.Errorf("Instance %q in project %q already has storage DB record", instName, projectName)
+	}
+
+	backupYamlPath := filepath.Join(vol.MountPath(), "backup.yaml")
+	var backupConf *backup.Config
+
+	// If the instance is running, it should already be mounted, so check if the backup file
+	// is already accessible, and if so parse it directly, without disturbing the mount count.
+	if shared.PathExists(backupYamlPath) {
+		backupConf, err = backup.ParseConfigYamlFile(backupYamlPath)
+		if err != nil {
+			return errors.Wrapf(err, "Failed parsing backup file %q", backupYamlPath)
+		}
+	} else {
+		// We don't know what filesystem block backed volumes are using, so assume the pool's default.
+		err = b.driver.FillVolumeConfig(*vol)
+		if err != nil {
+			return err
+		}
+
+		// If backup file not accessible, we take this to mean the instance isn't running
+		// and so we need to mount the volume to access the backup file and then unmount.
+		// This will also create the mount path if needed.
+		err = vol.MountTask(func(_ string, _ *operations.Operation) error {
+			backupConf, err = backup.ParseConfigYamlFile(backupYamlPath)
+			if err != nil {
+				return errors.Wrapf(err, "Failed parsing backup file %q", backupYamlPath)
+			}
+
+			return nil
+		}, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Run some consistency checks on the backup file contents.
+	if backupConf.Pool != nil {
+		if backupConf.Pool.Name != b.name {
+			return fmt.Errorf("Instance %q in project %q has pool name mismatch in its backup file (%q doesn't match pool's %q)", instName, projectName, backupConf.Pool.Name, b.name)
+		}
+
+		if backupConf.Pool.Driver != b.Driver().Info().Name {
+			return fmt.Errorf("Instance %q in project %q has pool driver mismatch in its backup file (%q doesn't match pool's %q)", instName, projectName, backupConf.Pool.Driver, b.Driver().Info().Name)
+		}
+	}
+
+	if backupConf.Container == nil {
+		return fmt.Errorf("Instance %q in project %q has no instance information in its backup file", instName, projectName)
+	}
+
+	if instName != backupConf.Container.Name {
+		return fmt.Errorf("Instance %q in project %q has a different instance name in its backup file (%q)", instName, projectName, backupConf.Container.Name)
+	}
+
+	instType, err := instancetype.New(string(backupConf.Container.Type))
+	if err != nil {
+		return errors.Wrapf(err, "Failed checking instance type for instance %q in project %q", instName, projectName)
+	}
+
+	instTypeVolType, err := InstanceTypeToVolumeType(instType)
+	if err != nil || instTypeVolType != volType {
+		return fmt.Errorf("Instance %q in project %q has a different instance type in its backup file (%q)", instName, projectName, backupConf.Container.Type)
+	}
+
+	if backupConf.Volume == nil {
+		return fmt.Errorf("Instance %q in project %q has no volume information in its backup file", instName, projectName)
+	}
+
+	if instName != backupConf.Volume.Name {
+		return fmt.Errorf("Instance %q in project %q has a different volume name in its backup file (%q)", instName, projectName, backupConf.Volume.Name)
+	}
+
+	instVolDBType, err := VolumeTypeNameToDBType(backupConf.Volume.Type)
+	if err != nil {
+		return errors.Wrapf(err, "Failed checking instance volume type for instance %q in project %q", instName, projectName)
+	}
+
+	instVolType, err := VolumeDBTypeToType(instVolDBType)
+	if err != nil {
+		return errors.Wrapf(err, "Failed checking instance volume type for instance %q in project %q", instName, projectName)
+	}
+
+	if volType != instVolType {
+		return fmt.Errorf("Instance %q in project %q has a different volume type in its backup file (%q)", instName, projectName, backupConf.Volume.Type)
+	}
+
+	// Check snapshots are consistent between storage layer and backup config file.
+	_, err = b.CheckInstanceBackupFileSnapshots(backupConf, projectName, false, op)
+	if err != nil {
+		return errors.Wrapf(err, "Instance %q in project %q has snapshot inconsistency", instName, projectName)
+	}
+
+	// Check there are no existing DB records present for snapshots.
+	for _, snapshot := range backupConf.Snapshots {
+		_, snapOnlyName, _ := shared.InstanceGetParentAndSnapshotName(snapshot.Name)
+		if snapOnlyName == "" {
+			snapOnlyName = snapshot.Name
+		}
+
+		fullSnapshotName := drivers.GetSnapshotVolumeName(instName, snapOnlyName)
+
+		// Check if an entry for the instance snapshot already exists in the DB.
+		if shared.StringInSlice(fullSnapshotName, instSnapshots) {
+			return fmt.Errorf("Instance %q snapshot %q in project %q already has instance DB record", instName, snapOnlyName, projectName)
+		}
+
+		// Check if any entry for the instance snapshot volume already exists in the DB.
+		// This will return no record for any temporary pool structs being used (as ID is -1).
+		volID, _, err := b.state.Cluster.GetLocalStoragePoolVolume(projectName, fullSnapshotName, volDBType, b.ID())
+		if err != nil && err != db.ErrNoSuchObject {
+			return err
+		} else if volID > 0 {
+			return fmt.Errorf("Instance %q snapshot %q in project %q already has storage DB record", instName, snapOnlyName, projectName)
+		}
+	}
+
+	// Add the volume to the unknown volumes list for the project.
+	projectVols[projectName] = append(projectVols[projectName], backupConf)
+
+	return nil
+}
+
+// detectUnknownCustomVolume detects if a volume is unknown and if so attempts to discover the filesystem of the
+// volume snapshots. As custom volumes have no backup file, it generates a backup config for the volume using the
+// default volume config and adds it to projectVols.
+func (b *lxdBackend) detectUnknownCustomVolume(vol *drivers.Volume, projectVols map[string][]*backup.Config, op *operations.Operation) error {
+	volType := vol.Type()
+
+	volDBType, err := VolumeTypeToDBType(volType)
+	if err != nil {
+		return err
+	}
+
+	// Custom volume names are always project prefixed, ignore anything else.
+	if !strings.Contains(vol.Name(), "_") {
+		return nil
+	}
+
+	projectName, volName := project.StorageVolumeParts(vol.Name())
+
+	// Check if any entry for the custom volume already exists in the DB.
+	// This will return no record for any temporary pool structs being used (as ID is -1).
+	volID, _, err := b.state.Cluster.GetLocalStoragePoolVolume(projectName, volName, volDBType, b.ID())
+	if err != nil && err != db.ErrNoSuchObject {
+		return err
+	} else if volID > 0 {
+		return nil // Custom volume record already exists in DB, no recovery needed.
+	}
+
+	// Get a list of snapshots that exist on storage device.
+	snapshots, err := b.driver.VolumeSnapshots(*vol, op)
+	if err != nil {
+		return err
+	}
+
+	// Fill in the default config for the volume (such as the filesystem of block backed volumes).
+	err = b.driver.FillVolumeConfig(*vol)
+	if err != nil {
+		return errors.Wrapf(err, "Failed filling config for custom volume %q in project %q", volName, projectName)
+	}
+
+	// This may not always be the correct thing to do, but seeing as we don't know what the volume's config
+	// was lets take a best guess that it was the default config.
+	backupConf := &backup.Config{
+		Volume: &api.StorageVolume{
+			StorageVolumePut: api.StorageVolumePut{
+				Config: vol.Config(),
+			},
+			Name:        volName,
+			Type:        db.StoragePoolVolumeTypeNameCustom,
+			ContentType: string(vol.ContentType()),
+		},
+	}
+
+	// Populate snapshot volumes.
+	for _, snapOnlyName := range snapshots {
+		backupConf.VolumeSnapshots = append(backupConf.VolumeSnapshots, &api.StorageVolumeSnapshot{
+			Name:        snapOnlyName, // Snapshot only name, not full name.
+			Config:      vol.Config(), // Have to assume the snapshot volume config is same as parent.
+			ContentType: string(vol.ContentType()),
+		})
+	}
+
+	// Add the volume to the unknown volumes list for the project.
+	projectVols[projectName] = append(projectVols[projectName], backupConf)
+
+	return nil
+}
+
 // ensureInstanceSymlink creates a symlink in the instance directory to the instance's mount path
 // if doesn't exist already.
 func (b *lxdBackend) ensureInstanceSymlink(instanceType instancetype.Type, projectName string, instanceName string, mountPath string) error {
@@ -1235,7 +1501,7 @@ func (b *lxdBackend) CreateInstanceFromMigration(inst instance.Instance, conn io
 	volExists := b.driver.HasVolume(vol)
 	if args.Refresh && !volExists {
 		return fmt.Errorf("Cannot refresh volume, doesn't exist on target")
-	} else if !args.Refresh && volExists {
+	} else if !args.Refresh && !args.Resume && volExists {
 		return fmt.Errorf("Cannot create volume, already exists on target")
 	}
 
@@ -1244,18 +1510,21 @@ func (b *lxdBackend) CreateInstanceFromMigration(inst instance.Instance, conn io
 	revert := true
 
 	if !args.Refresh {
-		defer func() {
-			if !revert {
-				return
-			}
-			b.DeleteInstance(inst, op)
-		}()
+		// When resuming, keep whatever was received so far so the transfer can be resumed again.
+		if !args.Resume {
+			defer func() {
+				if !revert {
+					return
+				}
+				b.DeleteInstance(inst, op)
+			}()
+		}
 
 		// If the negotiated migration method is rsync and the instance's base image is
 		// already on the host then setup a pre-filler that will unpack the local image
 		// to try and speed up the rsync of the incoming volume by avoiding the need to
 		// transfer the base image files too.
-		if args.MigrationType.FSType == migration.MigrationFSType_RSYNC {
+		if args.MigrationType.FSType == migration.MigrationFSType_RSYNC && !volExists {
 			fingerprint := inst.ExpandedConfig()["volatile.base_image"]
 
 			// Confirm that the image is present in the project.
@@ -1650,6 +1919,52 @@ func (b *lxdBackend) MigrateInstance(inst instance.Instance, conn io.ReadWriteCl
 	return nil
 }
 
+// InstanceMigrationResumeState returns what was already received of the instance's volume by an
+// interrupted migration.
+func (b *lxdBackend) InstanceMigrationResumeState(inst instance.Instance, op *operations.Operation) (*migration.ResumeState, error) {
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the root disk device config.
+	rootDiskConf, err := b.instanceRootVolumeConfig(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.Instance(inst.Project(), inst.Name())
+
+	vol := b.newVolume(volType, InstanceContentType(inst), volStorageName, rootDiskConf)
+
+	return b.migrationResumeState(vol, op)
+}
+
+// migrationResumeState returns the snapshots and the resume token left behind by an interrupted
+// migration of the volume. An empty state is returned if nothing was received yet.
+func (b *lxdBackend) migrationResumeState(vol drivers.Volume, op *operations.Operation) (*migration.ResumeState, error) {
+	state := &migration.ResumeState{}
+
+	if !b.driver.HasVolume(vol) {
+		return state, nil
+	}
+
+	snapshots, err := b.driver.VolumeSnapshots(vol, op)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Snapshots = snapshots
+
+	state.Token, err = b.driver.MigrationResumeToken(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
 // BackupInstance creates an instance backup.
 func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
 	logger := logging.AddContext(b.logger, log.Ctx{"project": inst.Project(), "instance": inst.Name(), "optimized": optimized, "snapshots": snapshots})
@@ -2877,18 +3192,50 @@ func (b *lxdBackend) CreateCustomVolumeFromMigration(projectName string, conn io
 		return err
 	}
 
-	// Create database entry for new storage volume.
-	err = VolumeDBCreate(b.state, b, projectName, args.Name, args.Description, vol.Type(), false, vol.Config(), time.Time{}, vol.ContentType())
+	// volDBExists returns whether the database entry of a volume was left behind by an interrupted transfer.
+	volDBExists := func(volName string) (bool, error) {
+		if !args.Resume {
+			return false, nil
+		}
+
+		_, _, err := b.state.Cluster.GetLocalStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, b.id)
+		if err == db.ErrNoSuchObject {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	exists, err := volDBExists(args.Name)
 	if err != nil {
 		return err
 	}
 
-	revertDBVolumes = append(revertDBVolumes, args.Name)
+	if !exists {
+		// Create database entry for new storage volume.
+		err = VolumeDBCreate(b.state, b, projectName, args.Name, args.Description, vol.Type(), false, vol.Config(), time.Time{}, vol.ContentType())
+		if err != nil {
+			return err
+		}
+
+		revertDBVolumes = append(revertDBVolumes, args.Name)
+	}
 
 	if len(args.Snapshots) > 0 {
 		for _, snapName := range args.Snapshots {
 			newSnapshotName := drivers.GetSnapshotVolumeName(args.Name, snapName)
 
+			exists, err := volDBExists(newSnapshotName)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				continue
+			}
+
 			// Create database entry for new storage volume snapshot.
 			err = VolumeDBCreate(b.state, b, projectName, newSnapshotName, args.Description, vol.Type(), true, vol.Config(), time.Time{}, vol.ContentType())
 			if err != nil {
@@ -2902,6 +3249,12 @@ func (b *lxdBackend) CreateCustomVolumeFromMigration(projectName string, conn io
 	err = b.driver.CreateVolumeFromMigration(vol, conn, args, nil, op)
 	if err != nil {
 		conn.Close()
+
+		// Keep the records of what was received so far so the transfer can be resumed again.
+		if args.Resume {
+			revertDBVolumes = nil
+		}
+
 		return err
 	}
 
@@ -2911,6 +3264,23 @@ func (b *lxdBackend) CreateCustomVolumeFromMigration(projectName string, conn io
 	return nil
 }
 
+// CustomVolumeMigrationResumeState returns what was already received of a custom volume by an
+// interrupted migration.
+func (b *lxdBackend) CustomVolumeMigrationResumeState(projectName string, volName string, op *operations.Operation) (*migration.ResumeState, error) {
+	_, volume, err := b.state.Cluster.GetLocalStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, b.id)
+	if err == db.ErrNoSuchObject {
+		return &migration.ResumeState{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	vol := b.newVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	return b.migrationResumeState(vol, op)
+}
+
 // RenameCustomVolume renames a custom volume and its snapshots.
 func (b *lxdBackend) RenameCustomVolume(projectName string, volName string, newVolName string, op *operations.Operation) error {
 	logger := logging.AddContext(b.logger, log.Ctx{"project": projectName, "volName": volName, "newVolName": newVolName})
@@ -3806,6 +4176,62 @@ func (b *lxdBackend) CheckInstanceBackupFileSnapshots(backupConf *backup.Config,
 	return existingSnapshots, nil
 }
 
+// ImportInstance takes an existing instance volume on the storage pool and ensures that the mount points and
+// symlinks needed by the instance are recreated. It is used when recovering instances whose DB records have
+// been recreated from the backup file stored on their volume.
+func (b *lxdBackend) ImportInstance(inst instance.Instance, op *operations.Operation) error {
+	logger := logging.AddContext(b.logger, log.Ctx{"project": inst.Project(), "instance": inst.Name()})
+	logger.Debug("ImportInstance started")
+	defer logger.Debug("ImportInstance finished")
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.Instance(inst.Project(), inst.Name())
+
+	contentType := InstanceContentType(inst)
+
+	volDBType, err := VolumeTypeToDBType(volType)
+	if err != nil {
+		return err
+	}
+
+	// Get local node storage record for the instance.
+	_, volume, err := b.state.Cluster.GetLocalStoragePoolVolume(inst.Project(), inst.Name(), volDBType, b.ID())
+	if err != nil {
+		return err
+	}
+
+	vol := b.newVolume(volType, contentType, volStorageName, volume.Config)
+
+	err = vol.EnsureMountPath()
+	if err != nil {
+		return err
+	}
+
+	err = b.ensureInstanceSymlink(inst.Type(), inst.Project(), inst.Name(), vol.MountPath())
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := vol.Snapshots(op)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) > 0 {
+		err = b.ensureInstanceSnapshotSymlink(inst.Type(), inst.Project(), inst.Name())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (b *lxdBackend) BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
 	logger := logging.AddContext(b.logger, log.Ctx{"project": projectName, "volume": volName, "optimized": optimized, "snapshots": snapshots})
 	logger.Debug("BackupCustomVolume started")
@@ -3942,3 +4368,76 @@ func (b *lxdBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData
 	revert.Success()
 	return nil
 }
+
+// ImportCustomVolume takes an existing custom volume on the storage backend and ensures that the DB records,
+// volume directories and symlinks are restored as needed to make it operational with LXD.
+// Used during the recovery import stage.
+func (b *lxdBackend) ImportCustomVolume(projectName string, poolVol backup.Config, op *operations.Operation) error {
+	if poolVol.Volume == nil {
+		return fmt.Errorf("Invalid pool volume config supplied")
+	}
+
+	logger := logging.AddContext(b.logger, log.Ctx{"project": projectName, "volName": poolVol.Volume.Name})
+	logger.Debug("ImportCustomVolume started")
+	defer logger.Debug("ImportCustomVolume finished")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the storage volume DB records.
+	err := VolumeDBCreate(b.state, b, projectName, poolVol.Volume.Name, poolVol.Volume.Description, drivers.VolumeTypeCustom, false, poolVol.Volume.Config, time.Time{}, drivers.ContentType(poolVol.Volume.ContentType))
+	if err != nil {
+		return errors.Wrapf(err, "Failed creating custom volume %q record in project %q", poolVol.Volume.Name, projectName)
+	}
+
+	revert.Add(func() {
+		b.state.Cluster.RemoveStoragePoolVolume(projectName, poolVol.Volume.Name, db.StoragePoolVolumeTypeCustom, b.ID())
+	})
+
+	// Create the storage volume snapshot DB records.
+	for _, poolVolSnap := range poolVol.VolumeSnapshots {
+		fullSnapName := drivers.GetSnapshotVolumeName(poolVol.Volume.Name, poolVolSnap.Name) // Local var for revert.
+
+		expiryDate := time.Time{}
+		if poolVolSnap.ExpiresAt != nil {
+			expiryDate = *poolVolSnap.ExpiresAt
+		}
+
+		err = VolumeDBCreate(b.state, b, projectName, fullSnapName, poolVolSnap.Description, drivers.VolumeTypeCustom, true, poolVolSnap.Config, expiryDate, drivers.ContentType(poolVolSnap.ContentType))
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			b.state.Cluster.RemoveStoragePoolVolume(projectName, fullSnapName, db.StoragePoolVolumeTypeCustom, b.ID())
+		})
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, poolVol.Volume.Name)
+	vol := b.newVolume(drivers.VolumeTypeCustom, drivers.ContentType(poolVol.Volume.ContentType), volStorageName, poolVol.Volume.Config)
+
+	// Create the mount path if needed.
+	err = vol.EnsureMountPath()
+	if err != nil {
+		return err
+	}
+
+	// Create snapshot mount paths and snapshot parent directory if needed.
+	for _, poolVolSnap := range poolVol.VolumeSnapshots {
+		logger.Debug("Ensuring custom volume snapshot mount path", log.Ctx{"snapshot": poolVolSnap.Name})
+
+		snapVol, err := vol.NewSnapshot(poolVolSnap.Name)
+		if err != nil {
+			return err
+		}
+
+		err = snapVol.EnsureMountPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
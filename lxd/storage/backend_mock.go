@@ -89,6 +89,10 @@ func (b *mockBackend) ApplyPatch(name string) error {
 	return nil
 }
 
+func (b *mockBackend) ListUnknownVolumes(op *operations.Operation) (map[string][]*backup.Config, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) FillInstanceConfig(inst instance.Instance, config map[string]string) error {
 	return nil
 }
@@ -133,10 +137,18 @@ func (b *mockBackend) CheckInstanceBackupFileSnapshots(backupConf *backup.Config
 	return nil, nil
 }
 
+func (b *mockBackend) ImportInstance(inst instance.Instance, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) MigrateInstance(inst instance.Instance, conn io.ReadWriteCloser, args *migration.VolumeSourceArgs, op *operations.Operation) error {
 	return nil
 }
 
+func (b *mockBackend) InstanceMigrationResumeState(inst instance.Instance, op *operations.Operation) (*migration.ResumeState, error) {
+	return &migration.ResumeState{}, nil
+}
+
 func (b *mockBackend) RefreshInstance(i instance.Instance, src instance.Instance, srcSnapshots []instance.Instance, op *operations.Operation) error {
 	return nil
 }
@@ -229,6 +241,10 @@ func (b *mockBackend) CreateCustomVolumeFromMigration(projectName string, conn i
 	return nil
 }
 
+func (b *mockBackend) CustomVolumeMigrationResumeState(projectName string, volName string, op *operations.Operation) (*migration.ResumeState, error) {
+	return &migration.ResumeState{}, nil
+}
+
 func (b *mockBackend) GetCustomVolumeDisk(projectName string, volName string) (string, error) {
 	return "", nil
 }
@@ -272,3 +288,7 @@ func (b *mockBackend) BackupCustomVolume(projectName string, volName string, tar
 func (b *mockBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error {
 	return nil
 }
+
+func (b *mockBackend) ImportCustomVolume(projectName string, poolVol backup.Config, op *operations.Operation) error {
+	return nil
+}
@@ -483,6 +483,14 @@ func (d *btrfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, v
 				return err
 			}
 
+			// Clear any subvolume left over by the interrupted transfer being resumed.
+			if volTargetArgs.Resume && btrfsIsSubVolume(subVolTargetPath) {
+				err = d.deleteSubvolume(subVolTargetPath, true)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Clear the target for the subvol to use.
 			os.Remove(subVolTargetPath)
 
@@ -612,6 +620,11 @@ func (d *btrfs) HasVolume(vol Volume) bool {
 	return genericVFSHasVolume(vol)
 }
 
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *btrfs) ListVolumes() ([]Volume, error) {
+	return genericVFSListVolumes(d)
+}
+
 // ValidateVolume validates the supplied volume config.
 func (d *btrfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	return d.validateVolume(vol, nil, removeUnknownKeys)
@@ -940,6 +953,13 @@ func (d *btrfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *m
 
 	// Transfer the snapshots (and any subvolumes if supported) to target first.
 	lastVolPath := "" // Used as parent for differential transfers.
+
+	// When resuming, the differential transfer starts from the last snapshot the target received.
+	if volSrcArgs.ResumeParent != "" {
+		parentVol, _ := vol.NewSnapshot(volSrcArgs.ResumeParent)
+		lastVolPath = parentVol.MountPath()
+	}
+
 	for _, snapName := range volSrcArgs.Snapshots {
 		snapVol, _ := vol.NewSnapshot(snapName)
 		err = sendVolume(snapVol, snapVol.MountPath(), lastVolPath)
@@ -718,6 +718,72 @@ func (d *ceph) HasVolume(vol Volume) bool {
 	return d.hasVolume(d.getRBDVolumeName(vol, "", false, false))
 }
 
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *ceph) ListVolumes() ([]Volume, error) {
+	msg, err := shared.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--format", "json",
+		"--cluster", d.config["ceph.cluster_name"],
+		"--pool", d.config["ceph.osd.pool_name"],
+		"ls",
+		"-l")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed listing RBD volumes in OSD pool %q", d.config["ceph.osd.pool_name"])
+	}
+
+	var data []struct {
+		Image    string `json:"image"`
+		Snapshot string `json:"snapshot"`
+		Size     int64  `json:"size"`
+	}
+
+	err = json.Unmarshal([]byte(msg), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	volTypes := map[string]VolumeType{
+		db.StoragePoolVolumeTypeNameContainer: VolumeTypeContainer,
+		db.StoragePoolVolumeTypeNameVM:        VolumeTypeVM,
+		db.StoragePoolVolumeTypeNameCustom:    VolumeTypeCustom,
+	}
+
+	var vols []Volume
+	for _, entry := range data {
+		if entry.Snapshot != "" {
+			continue
+		}
+
+		// Volume names are in the form <volume type>_<volume name>[.block], anything else (zombie volumes,
+		// images or the pool placeholder volume) isn't recovered.
+		fields := strings.SplitN(entry.Image, "_", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		volType, found := volTypes[fields[0]]
+		if !found {
+			continue
+		}
+
+		volName := fields[1]
+		contentType := ContentTypeFS
+		volConfig := make(map[string]string)
+		if strings.HasSuffix(volName, ".block") {
+			contentType = ContentTypeBlock
+			volName = strings.TrimSuffix(volName, ".block")
+			volConfig["size"] = fmt.Sprintf("%d", entry.Size)
+		} else if volType == VolumeTypeVM {
+			continue // Filesystem volume associated to a VM block volume.
+		}
+
+		vols = append(vols, NewVolume(d, d.name, volType, contentType, volName, volConfig, d.config))
+	}
+
+	return vols, nil
+}
+
 // FillVolumeConfig populate volume with default config.
 func (d *ceph) FillVolumeConfig(vol Volume) error {
 	// Only validate filesystem config keys for filesystem volumes or VM block volumes (which have an
@@ -284,6 +284,11 @@ func (d *cephfs) HasVolume(vol Volume) bool {
 	return genericVFSHasVolume(vol)
 }
 
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *cephfs) ListVolumes() ([]Volume, error) {
+	return genericVFSListVolumes(d)
+}
+
 // ValidateVolume validates the supplied volume config. Optionally removes invalid keys from the volume's config.
 func (d *cephfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	return d.validateVolume(vol, nil, removeUnknownKeys)
@@ -161,6 +161,12 @@ func (d *common) MigrationTypes(contentType ContentType, refresh bool) []migrati
 	}
 }
 
+// MigrationResumeToken returns the token needed to resume the interrupted receive of a volume.
+// Drivers that can't resume their own transfers return an empty token.
+func (d *common) MigrationResumeToken(vol Volume) (string, error) {
+	return "", nil
+}
+
 // Name returns the pool name.
 func (d *common) Name() string {
 	return d.name
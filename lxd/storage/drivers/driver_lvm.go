@@ -583,3 +583,24 @@ func (d *lvm) GetResources() (*api.ResourcesStoragePool, error) {
 
 	return &res, nil
 }
+
+// ThinpoolMetadataUsage returns the percentage of the thin pool's metadata space that is in use.
+// Returns -1 if the pool doesn't use a thin pool.
+func (d *lvm) ThinpoolMetadataUsage() (float64, error) {
+	if !d.usesThinpool() {
+		return -1, nil
+	}
+
+	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], "", "", d.thinpoolName())
+	out, err := shared.RunCommand("lvs", volDevPath, "--noheadings", "-o", "metadata_percent")
+	if err != nil {
+		return -1, err
+	}
+
+	metaPerc, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+	if err != nil {
+		return -1, errors.Wrapf(err, "Failed parsing thin pool metadata usage %q", strings.TrimSpace(out))
+	}
+
+	return metaPerc, nil
+}
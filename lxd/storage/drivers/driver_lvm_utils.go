@@ -746,6 +746,50 @@ func (d *lvm) parseLogicalVolumeSnapshot(parent Volume, lvmVolName string) strin
 	return ""
 }
 
+// parseLogicalVolume parses a logical volume name into its volume type, content type and unescaped volume
+// name. Returns an empty volume type if the logical volume isn't an LXD instance or custom volume or if it is a
+// snapshot volume (which is recognised by the presence of an unescaped snapshot separator in its name).
+func (d *lvm) parseLogicalVolume(lvmVolName string) (VolumeType, ContentType, string) {
+	var volType VolumeType
+	for _, t := range []VolumeType{VolumeTypeContainer, VolumeTypeVM, VolumeTypeCustom} {
+		if strings.HasPrefix(lvmVolName, fmt.Sprintf("%s_", t)) {
+			volType = t
+			break
+		}
+	}
+
+	if volType == "" {
+		return "", "", ""
+	}
+
+	lvName := strings.TrimPrefix(lvmVolName, fmt.Sprintf("%s_", volType))
+
+	contentType := ContentTypeFS
+	if strings.HasSuffix(lvName, lvmBlockVolSuffix) {
+		contentType = ContentTypeBlock
+		lvName = strings.TrimSuffix(lvName, lvmBlockVolSuffix)
+	} else if volType == VolumeTypeVM {
+		return "", "", "" // Filesystem volume associated to a VM block volume.
+	}
+
+	var volName strings.Builder
+	for i := 0; i < len(lvName); i++ {
+		if strings.HasPrefix(lvName[i:], lvmEscapedHyphen) {
+			volName.WriteString("-")
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(lvName[i:], lvmSnapshotSeparator) {
+			return "", "", "" // Snapshot volume.
+		}
+
+		volName.WriteByte(lvName[i])
+	}
+
+	return volType, contentType, volName.String()
+}
+
 // activateVolume activates an LVM logical volume if not already present. Returns true if activated, false if not.
 func (d *lvm) activateVolume(volDevPath string) (bool, error) {
 	if !shared.PathExists(volDevPath) {
@@ -66,3 +66,35 @@ func Example_lvm_parseLogicalVolumeName() {
 	// custom_proj_testvol--with--hyphens.block: Unrecognised
 	// custom_proj_testvol--with--hyphens.block-snap1--with--hyphens.block: snap1-with-hyphens.block
 }
+
+func Example_lvm_parseLogicalVolume() {
+	d := &lvm{}
+	d.name = "pool"
+
+	tests := []string{
+		"containers_proj_testct--with--hyphens",
+		"containers_proj_testct--with--hyphens-snap1",
+		"virtual-machines_proj_testvm.block",
+		"virtual-machines_proj_testvm",
+		"custom_proj_testvol.block",
+		"images_a5e4f5d1b8c9",
+		"LXDThinPool",
+	}
+
+	for _, lvName := range tests {
+		volType, contentType, volName := d.parseLogicalVolume(lvName)
+		if volType == "" {
+			fmt.Printf("%s: Unrecognised\n", lvName)
+		} else {
+			fmt.Printf("%s: %s %s %s\n", lvName, volType, contentType, volName)
+		}
+	}
+
+	// Output: containers_proj_testct--with--hyphens: containers filesystem proj_testct-with-hyphens
+	// containers_proj_testct--with--hyphens-snap1: Unrecognised
+	// virtual-machines_proj_testvm.block: virtual-machines block proj_testvm
+	// virtual-machines_proj_testvm: Unrecognised
+	// custom_proj_testvol.block: custom block proj_testvol
+	// images_a5e4f5d1b8c9: Unrecognised
+	// LXDThinPool: Unrecognised
+}
@@ -239,6 +239,38 @@ func (d *lvm) HasVolume(vol Volume) bool {
 	return volExists
 }
 
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *lvm) ListVolumes() ([]Volume, error) {
+	vgName := d.config["lvm.vg_name"]
+
+	out, err := shared.RunCommand("lvs", "--noheadings", "--units", "b", "--nosuffix", "-o", "lv_name,lv_size", vgName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed listing logical volumes in LVM volume group %q", vgName)
+	}
+
+	var vols []Volume
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		volType, contentType, volName := d.parseLogicalVolume(fields[0])
+		if volType == "" || strings.HasSuffix(volName, tmpVolSuffix) {
+			continue
+		}
+
+		volConfig := make(map[string]string)
+		if contentType == ContentTypeBlock {
+			volConfig["size"] = fields[1]
+		}
+
+		vols = append(vols, NewVolume(d, d.name, volType, contentType, volName, volConfig, d.config))
+	}
+
+	return vols, nil
+}
+
 // FillVolumeConfig populate volume with default config.
 func (d *lvm) FillVolumeConfig(vol Volume) error {
 	// Only validate filesystem config keys for filesystem volumes or VM block volumes (which have an
@@ -105,6 +105,11 @@ func (d *mock) HasVolume(vol Volume) bool {
 	return true
 }
 
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *mock) ListVolumes() ([]Volume, error) {
+	return nil, nil
+}
+
 // ValidateVolume validates the supplied volume config. Optionally removes invalid keys from the volume's config.
 func (d *mock) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	return nil
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -25,6 +26,17 @@ var zfsLoaded bool
 var zfsDirectIO bool
 var zfsTrim bool
 
+// zfsResumeSnapshotPrefix is the prefix of the temporary snapshots kept on the source of resumable transfers.
+const zfsResumeSnapshotPrefix = "migration-resume-"
+
+// zfsResumeSnapshotTargetProperty is the user property recording which transfer a resume snapshot belongs to.
+// The ID is kept when the transfer is resumed so that all the snapshots of a transfer can be found.
+const zfsResumeSnapshotTargetProperty = "lxd:migration_target"
+
+// zfsResumeSnapshotExpiry is how long the resume snapshots of other transfers are kept before being considered
+// abandoned.
+const zfsResumeSnapshotExpiry = 7 * 24 * time.Hour
+
 var zfsDefaultSettings = map[string]string{
 	"mountpoint": "none",
 	"setuid":     "on",
@@ -517,3 +529,8 @@ func (d *zfs) MigrationTypes(contentType ContentType, refresh bool) []migration.
 		},
 	}
 }
+
+// MigrationResumeToken returns the token to resume the interrupted receive of a volume (the block volume for VMs).
+func (d *zfs) MigrationResumeToken(vol Volume) (string, error) {
+	return d.receiveResumeToken(d.dataset(vol, false))
+}
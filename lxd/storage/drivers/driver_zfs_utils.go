@@ -6,7 +6,9 @@ import (
 	"io/ioutil"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pborman/uuid"
 
@@ -251,6 +253,17 @@ func (d *zfs) sendDataset(dataset string, parent string, volSrcArgs *migration.V
 		args = append(args, "-i", parent)
 	}
 	args = append(args, dataset)
+
+	return d.runSend(args, conn, tracker)
+}
+
+// resumeSendDataset resumes an interrupted zfs send from the resume token provided by the recipient.
+func (d *zfs) resumeSendDataset(token string, conn io.ReadWriteCloser, tracker *ioprogress.ProgressTracker) error {
+	return d.runSend([]string{"send", "-t", token}, conn, tracker)
+}
+
+// runSend runs a zfs send command and forwards its output to the connection.
+func (d *zfs) runSend(args []string, conn io.ReadWriteCloser, tracker *ioprogress.ProgressTracker) error {
 	cmd := exec.Command("zfs", args...)
 
 	// Prepare stdout/stderr.
@@ -310,13 +323,21 @@ func (d *zfs) sendDataset(dataset string, parent string, volSrcArgs *migration.V
 	return nil
 }
 
-func (d *zfs) receiveDataset(vol Volume, conn io.ReadWriteCloser, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
+func (d *zfs) receiveDataset(vol Volume, conn io.ReadWriteCloser, resumable bool, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
 	// Assemble zfs receive command.
-	cmd := exec.Command("zfs", "receive", "-x", "mountpoint", "-F", "-u", d.dataset(vol, false))
-	if vol.ContentType() == ContentTypeBlock {
-		cmd = exec.Command("zfs", "receive", "-F", "-u", d.dataset(vol, false))
+	args := []string{"receive"}
+	if resumable {
+		// Save the partially received state if interrupted so that the transfer can be resumed.
+		args = append(args, "-s")
 	}
 
+	if vol.ContentType() != ContentTypeBlock {
+		args = append(args, "-x", "mountpoint")
+	}
+
+	args = append(args, "-F", "-u", d.dataset(vol, false))
+	cmd := exec.Command("zfs", args...)
+
 	// Prepare stdin/stderr.
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -364,3 +385,144 @@ func (d *zfs) receiveDataset(vol Volume, conn io.ReadWriteCloser, writeWrapper f
 
 	return nil
 }
+
+// canResumeReceive returns whether interrupted receives can be resumed (added in ZFS 0.7).
+func (d *zfs) canResumeReceive() bool {
+	return len(zfsVersion) >= 3 && zfsVersion[0:3] != "0.6"
+}
+
+// receiveResumeToken returns the token to resume the interrupted receive of a dataset (empty if none).
+func (d *zfs) receiveResumeToken(dataset string) (string, error) {
+	if !d.canResumeReceive() || !d.checkDataset(dataset) {
+		return "", nil
+	}
+
+	token, err := d.getDatasetProperty(dataset, "receive_resume_token")
+	if err != nil {
+		return "", err
+	}
+
+	if token == "-" {
+		return "", nil
+	}
+
+	return token, nil
+}
+
+// abortReceive discards the partially received state of a dataset (if any).
+func (d *zfs) abortReceive(dataset string) error {
+	token, err := d.receiveResumeToken(dataset)
+	if err != nil || token == "" {
+		return err
+	}
+
+	_, err = shared.RunCommand("zfs", "receive", "-A", dataset)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resumeTokenSnapshot returns the snapshot dataset whose send was interrupted, as recorded in a resume token.
+func (d *zfs) resumeTokenSnapshot(token string) (string, error) {
+	out, err := shared.RunCommand("zfs", "send", "-nvt", token)
+	if err != nil {
+		return "", err
+	}
+
+	return zfsParseResumeToken(out)
+}
+
+// zfsParseResumeToken returns the snapshot dataset recorded in the output of "zfs send -nvt <token>".
+func zfsParseResumeToken(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " = ", 2)
+		if len(fields) == 2 && fields[0] == "toname" {
+			return fields[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("Failed to find the snapshot in the resume token")
+}
+
+// zfsResumeSnapshot is a temporary snapshot kept on the source by a resumable transfer.
+type zfsResumeSnapshot struct {
+	name    string    // Snapshot dataset.
+	target  string    // ID of the transfer the snapshot belongs to.
+	created time.Time // Creation time of the snapshot.
+}
+
+// getResumeSnapshots returns the resume snapshots of dataset.
+func (d *zfs) getResumeSnapshots(dataset string) ([]zfsResumeSnapshot, error) {
+	out, err := shared.RunCommand("zfs", "list", "-H", "-p", "-t", "snapshot", "-d", "1", "-o", fmt.Sprintf("name,creation,%s", zfsResumeSnapshotTargetProperty), dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	return zfsParseResumeSnapshots(dataset, out), nil
+}
+
+// zfsParseResumeSnapshots returns the resume snapshots of dataset listed in the output of
+// "zfs list -H -p -o name,creation,<target property>".
+func zfsParseResumeSnapshots(dataset string, out string) []zfsResumeSnapshot {
+	snapshots := []zfsResumeSnapshot{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) != 3 || !strings.HasPrefix(fields[0], dataset+"@"+zfsResumeSnapshotPrefix) {
+			continue
+		}
+
+		snapshot := zfsResumeSnapshot{name: fields[0]}
+
+		// Snapshots created before the target was recorded have the "-" value of unset properties.
+		if fields[2] != "-" {
+			snapshot.target = fields[2]
+		}
+
+		created, err := strconv.ParseInt(fields[1], 10, 64)
+		if err == nil {
+			snapshot.created = time.Unix(created, 0)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+// deleteResumeSnapshots deletes the temporary snapshots kept on the source by the resumable transfer identified
+// by target, along with those of other transfers which were abandoned for longer than zfsResumeSnapshotExpiry.
+// The snapshot of the transfer being resumed (keep) is never deleted.
+func (d *zfs) deleteResumeSnapshots(dataset string, target string, keep string) error {
+	snapshots, err := d.getResumeSnapshots(dataset)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range zfsStaleResumeSnapshots(snapshots, target, keep, time.Now()) {
+		_, err := shared.RunCommand("zfs", "destroy", snapshot)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// zfsStaleResumeSnapshots returns the snapshots which belong to the target transfer or are older than
+// zfsResumeSnapshotExpiry, other than keep. An empty target only selects the expired snapshots.
+func zfsStaleResumeSnapshots(snapshots []zfsResumeSnapshot, target string, keep string, now time.Time) []string {
+	stale := []string{}
+	for _, snapshot := range snapshots {
+		if snapshot.name == keep {
+			continue
+		}
+
+		if (target != "" && snapshot.target == target) || now.Sub(snapshot.created) >= zfsResumeSnapshotExpiry {
+			stale = append(stale, snapshot.name)
+		}
+	}
+
+	return stale
+}
@@ -0,0 +1,63 @@
+package drivers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZFSParseResumeToken(t *testing.T) {
+	out := `resume token contents:
+nvlist version: 0
+	object = 0x6
+	offset = 0x2f3c000
+	bytes = 0x2f4a1e0
+	toguid = 0x2b7e6a8d4c1e7f35
+	toname = lxd/containers/c1@migration-resume-7f0a2b6e-1111-2222-3333-444455556666
+full send of lxd/containers/c1@migration-resume-7f0a2b6e-1111-2222-3333-444455556666 estimated size is 97.5M
+`
+
+	snapshot, err := zfsParseResumeToken(out)
+	require.NoError(t, err)
+	assert.Equal(t, "lxd/containers/c1@migration-resume-7f0a2b6e-1111-2222-3333-444455556666", snapshot)
+
+	_, err = zfsParseResumeToken("resume token contents:\nnvlist version: 0\n")
+	assert.EqualError(t, err, "Failed to find the snapshot in the resume token")
+}
+
+func TestZFSParseResumeSnapshots(t *testing.T) {
+	out := "lxd/containers/c1@snapshot-snap0\t1600000000\t-\n" +
+		"lxd/containers/c1@migration-resume-aaaa\t1600000100\top1\n" +
+		"lxd/containers/c1@migration-cccc\t1600000200\t-\n" +
+		"lxd/containers/c1@migration-resume-bbbb\t1600000300\t-\n"
+
+	snapshots := zfsParseResumeSnapshots("lxd/containers/c1", out)
+	assert.Equal(t, []zfsResumeSnapshot{
+		{name: "lxd/containers/c1@migration-resume-aaaa", target: "op1", created: time.Unix(1600000100, 0)},
+		{name: "lxd/containers/c1@migration-resume-bbbb", target: "", created: time.Unix(1600000300, 0)},
+	}, snapshots)
+}
+
+func TestZFSStaleResumeSnapshots(t *testing.T) {
+	now := time.Now()
+	snapshots := []zfsResumeSnapshot{
+		{name: "lxd/containers/c1@migration-resume-aaaa", target: "op1", created: now.Add(-time.Hour)},
+		{name: "lxd/containers/c1@migration-resume-bbbb", target: "op1", created: now.Add(-2 * time.Hour)},
+		{name: "lxd/containers/c1@migration-resume-cccc", target: "op2", created: now.Add(-time.Hour)},
+		{name: "lxd/containers/c1@migration-resume-dddd", target: "op3", created: now.Add(-zfsResumeSnapshotExpiry)},
+		{name: "lxd/containers/c1@migration-resume-eeee", target: "", created: now.Add(-zfsResumeSnapshotExpiry - time.Hour)},
+	}
+
+	// Without a target, only the expired snapshots are selected.
+	assert.Equal(t, []string{"lxd/containers/c1@migration-resume-dddd", "lxd/containers/c1@migration-resume-eeee"}, zfsStaleResumeSnapshots(snapshots, "", "", now))
+
+	// The snapshots of the target's transfer are selected, those of concurrent transfers are kept.
+	assert.Equal(t, []string{"lxd/containers/c1@migration-resume-aaaa", "lxd/containers/c1@migration-resume-bbbb", "lxd/containers/c1@migration-resume-dddd", "lxd/containers/c1@migration-resume-eeee"}, zfsStaleResumeSnapshots(snapshots, "op1", "", now))
+
+	// The snapshot of the transfer being resumed is kept.
+	assert.Equal(t, []string{"lxd/containers/c1@migration-resume-dddd"}, zfsStaleResumeSnapshots(snapshots, "", "lxd/containers/c1@migration-resume-eeee", now))
+
+	assert.Equal(t, []string{}, zfsStaleResumeSnapshots(nil, "op1", "", now))
+}
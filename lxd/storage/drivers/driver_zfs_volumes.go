@@ -654,11 +654,37 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 	}
 
 	if vol.IsVMBlock() {
+		// The resume token only applies to the block volume, the filesystem volume is transferred again
+		// from its last received snapshot.
+		fsVolTargetArgs := volTargetArgs
+		fsVolTargetArgs.ResumeToken = ""
+
 		fsVol := vol.NewVMBlockFilesystemVolume()
-		err := d.CreateVolumeFromMigration(fsVol, conn, volTargetArgs, preFiller, op)
+		err := d.CreateVolumeFromMigration(fsVol, conn, fsVolTargetArgs, preFiller, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	resumable := volTargetArgs.Resume && d.canResumeReceive()
+
+	// Snapshots received by an interrupted transfer are kept when resuming it.
+	keepSnapshots := volTargetArgs.Snapshots
+	if volTargetArgs.Resume && d.HasVolume(vol) {
+		receivedSnapshots, err := d.VolumeSnapshots(vol, op)
 		if err != nil {
 			return err
 		}
+
+		keepSnapshots = append(receivedSnapshots, keepSnapshots...)
+
+		// Discard any partially received state which isn't going to be resumed.
+		if volTargetArgs.ResumeToken == "" {
+			err = d.abortReceive(d.dataset(vol, false))
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Handle zfs send/receive migration.
@@ -674,7 +700,7 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 			fullSnapshotName := GetSnapshotVolumeName(vol.name, snapName)
 			wrapper := migration.ProgressWriter(op, "fs_progress", fullSnapshotName)
 
-			err = d.receiveDataset(vol, conn, wrapper)
+			err = d.receiveDataset(vol, conn, resumable, wrapper)
 			if err != nil {
 				return err
 			}
@@ -683,7 +709,17 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 
 	// Transfer the main volume.
 	wrapper := migration.ProgressWriter(op, "fs_progress", vol.name)
-	err := d.receiveDataset(vol, conn, wrapper)
+
+	// When resuming the interrupted transfer of the main volume, the rest of the interrupted stream is
+	// received first, followed by the changes made since on the source.
+	if volTargetArgs.ResumeToken != "" && len(volTargetArgs.Snapshots) == 0 {
+		err := d.receiveDataset(vol, conn, resumable, wrapper)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := d.receiveDataset(vol, conn, resumable, wrapper)
 	if err != nil {
 		return err
 	}
@@ -695,7 +731,7 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 	}
 
 	// keepDataset returns whether to keep the data set or delete it. Data sets that are non-snapshots or
-	// snapshots that match the requested snapshots in volTargetArgs.Snapshots are kept. Any other snapshot
+	// snapshots that match the requested snapshots in keepSnapshots are kept. Any other snapshot
 	// data sets should be removed.
 	keepDataset := func(dataSetName string) bool {
 		// Keep non-snapshot data sets and snapshots that don't have the LXD snapshot prefix indicator.
@@ -704,10 +740,10 @@ func (d *zfs) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vol
 			return false
 		}
 
-		// Check if snapshot data set matches one of the requested snapshots in volTargetArgs.Snapshots.
+		// Check if snapshot data set matches one of the requested snapshots in keepSnapshots.
 		// If so, then keep it, otherwise request it be removed.
 		entrySnapName := strings.TrimPrefix(dataSetName, dataSetSnapshotPrefix)
-		for _, snapName := range volTargetArgs.Snapshots {
+		for _, snapName := range keepSnapshots {
 			if entrySnapName == snapName {
 				return true // Keep snapshot data set if present in the requested snapshots list.
 			}
@@ -811,6 +847,60 @@ func (d *zfs) HasVolume(vol Volume) bool {
 	return d.checkDataset(d.dataset(vol, false))
 }
 
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *zfs) ListVolumes() ([]Volume, error) {
+	vols := make(map[string]Volume)
+
+	for _, volType := range []VolumeType{VolumeTypeContainer, VolumeTypeVM, VolumeTypeCustom} {
+		parent := filepath.Join(d.config["zfs.pool_name"], string(volType))
+		if !d.checkDataset(parent) {
+			continue
+		}
+
+		// Get just the direct children of the volume type dataset, snapshots are not included.
+		out, err := shared.RunCommand("zfs", "list", "-H", "-p", "-r", "-d", "1", "-t", "filesystem,volume", "-o", "name,type,volsize", parent)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed getting ZFS datasets for volume type %q", volType)
+		}
+
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 3 || fields[0] == parent {
+				continue
+			}
+
+			volName := strings.TrimPrefix(fields[0], parent+"/")
+			if strings.HasSuffix(volName, tmpVolSuffix) {
+				continue
+			}
+
+			contentType := ContentTypeFS
+			volConfig := make(map[string]string)
+			if fields[1] == "volume" {
+				contentType = ContentTypeBlock
+				volName = strings.TrimSuffix(volName, ".block")
+				volConfig["size"] = fields[2]
+			}
+
+			// Virtual machines have both a filesystem dataset and a block volume, prefer the latter.
+			key := fmt.Sprintf("%s/%s", volType, volName)
+			existing, found := vols[key]
+			if found && existing.contentType == ContentTypeBlock {
+				continue
+			}
+
+			vols[key] = NewVolume(d, d.name, volType, contentType, volName, volConfig, d.config)
+		}
+	}
+
+	volList := make([]Volume, 0, len(vols))
+	for _, vol := range vols {
+		volList = append(volList, vol)
+	}
+
+	return volList, nil
+}
+
 // ValidateVolume validates the supplied volume config.
 func (d *zfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	rules := map[string]func(value string) error{
@@ -1269,22 +1359,57 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *mig
 	}
 
 	if vol.IsVMBlock() {
+		// The resume token only applies to the block volume, the filesystem volume is transferred again
+		// from its last received snapshot.
+		fsVolSrcArgs := volSrcArgs
+		if volSrcArgs.ResumeToken != "" {
+			argsCopy := *volSrcArgs
+			argsCopy.ResumeToken = ""
+			fsVolSrcArgs = &argsCopy
+		}
+
 		fsVol := vol.NewVMBlockFilesystemVolume()
-		err := d.MigrateVolume(fsVol, conn, volSrcArgs, op)
+		err := d.MigrateVolume(fsVol, conn, fsVolSrcArgs, op)
 		if err != nil {
 			return err
 		}
 	}
 
+	// Find out what the interrupted transfer being resumed was sending.
+	resumeSnapshot := ""
+	if volSrcArgs.ResumeToken != "" && !volSrcArgs.FinalSync {
+		var err error
+		resumeSnapshot, err = d.resumeTokenSnapshot(volSrcArgs.ResumeToken)
+		if err != nil {
+			return errors.Wrapf(err, "Failed reading resume token")
+		}
+
+		expected := fmt.Sprintf("%s@migration-", d.dataset(vol, false))
+		if len(volSrcArgs.Snapshots) > 0 {
+			firstSnapshot, _ := vol.NewSnapshot(volSrcArgs.Snapshots[0])
+			expected = d.dataset(firstSnapshot, false)
+		}
+
+		if !strings.HasPrefix(resumeSnapshot, expected) {
+			return fmt.Errorf("Cannot resume the interrupted transfer as the source volume changed since, delete the partially transferred target to start over")
+		}
+	}
+
 	// Handle zfs send/receive migration.
 	var finalParent string
 	if !volSrcArgs.FinalSync {
+		// When resuming, the differential transfer starts from the last snapshot the target received.
+		if volSrcArgs.ResumeParent != "" {
+			parentSnapshot, _ := vol.NewSnapshot(volSrcArgs.ResumeParent)
+			finalParent = d.dataset(parentSnapshot, false)
+		}
+
 		// Transfer the snapshots first.
 		for i, snapName := range volSrcArgs.Snapshots {
 			snapshot, _ := vol.NewSnapshot(snapName)
 
 			// Figure out parent and current subvolumes.
-			parent := ""
+			parent := finalParent
 			if i > 0 {
 				oldSnapshot, _ := vol.NewSnapshot(volSrcArgs.Snapshots[i-1])
 				parent = d.dataset(oldSnapshot, false)
@@ -1297,7 +1422,13 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *mig
 			}
 
 			// Send snapshot to recipient (ensure local snapshot volume is mounted if needed).
-			err := d.sendDataset(d.dataset(snapshot, false), parent, volSrcArgs, conn, wrapper)
+			var err error
+			if i == 0 && resumeSnapshot != "" {
+				err = d.resumeSendDataset(volSrcArgs.ResumeToken, conn, wrapper)
+			} else {
+				err = d.sendDataset(d.dataset(snapshot, false), parent, volSrcArgs, conn, wrapper)
+			}
+
 			if err != nil {
 				return err
 			}
@@ -1312,11 +1443,60 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *mig
 		wrapper = migration.ProgressTracker(op, "fs_progress", vol.name)
 	}
 
+	// Temporary migration snapshots kept for a resumable transfer, deleted once it succeeded.
+	resumeCleanup := []string{}
+	resumeTarget := ""
+
 	srcSnapshot := d.dataset(vol, false)
 	if !vol.IsSnapshot() {
-		// Create a temporary read-only snapshot.
+		// Delete the snapshots kept by resumable transfers abandoned for too long, they'd otherwise pin
+		// space forever. Those of other transfers still within their expiry may be resumed concurrently.
+		if !volSrcArgs.FinalSync {
+			err := d.deleteResumeSnapshots(d.dataset(vol, false), "", resumeSnapshot)
+			if err != nil {
+				return errors.Wrapf(err, "Failed deleting expired migration snapshots")
+			}
+		}
+
+		// Identify the transfer in its resume snapshots, keeping the ID of the interrupted transfer when
+		// resuming it.
+		if volSrcArgs.Resume && !volSrcArgs.MultiSync {
+			if resumeSnapshot != "" {
+				target, err := d.getDatasetProperty(resumeSnapshot, zfsResumeSnapshotTargetProperty)
+				if err == nil && target != "-" {
+					resumeTarget = target
+				}
+			}
+
+			if resumeTarget == "" {
+				resumeTarget = uuid.NewRandom().String()
+				if op != nil {
+					resumeTarget = op.ID()
+				}
+			}
+		}
+
+		// Complete the interrupted transfer of the previous migration snapshot first, the changes made
+		// since are then sent on top of it.
+		if resumeSnapshot != "" && len(volSrcArgs.Snapshots) == 0 {
+			err := d.resumeSendDataset(volSrcArgs.ResumeToken, conn, wrapper)
+			if err != nil {
+				return err
+			}
+
+			finalParent = resumeSnapshot
+			resumeCleanup = append(resumeCleanup, resumeSnapshot)
+		}
+
+		// Create a temporary read-only snapshot, those kept for resuming are named so they can be found later.
+		args := []string{"snapshot"}
 		srcSnapshot = fmt.Sprintf("%s@migration-%s", d.dataset(vol, false), uuid.NewRandom().String())
-		_, err := shared.RunCommand("zfs", "snapshot", srcSnapshot)
+		if resumeTarget != "" {
+			args = append(args, "-o", fmt.Sprintf("%s=%s", zfsResumeSnapshotTargetProperty, resumeTarget))
+			srcSnapshot = fmt.Sprintf("%s@%s%s", d.dataset(vol, false), zfsResumeSnapshotPrefix, uuid.NewRandom().String())
+		}
+
+		_, err := shared.RunCommand("zfs", append(args, srcSnapshot)...)
 		if err != nil {
 			return err
 		}
@@ -1335,6 +1515,9 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *mig
 				}
 				volSrcArgs.Data.(map[ContentType]string)[vol.ContentType()] = srcSnapshot // Persist parent state for final sync.
 			}
+		} else if volSrcArgs.Resume {
+			// Keep the snapshot if the transfer gets interrupted so that it can be resumed.
+			resumeCleanup = append(resumeCleanup, srcSnapshot)
 		} else {
 			defer shared.RunCommand("zfs", "destroy", srcSnapshot)
 		}
@@ -1346,6 +1529,21 @@ func (d *zfs) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *mig
 		return err
 	}
 
+	for _, snapshot := range resumeCleanup {
+		_, err := shared.RunCommand("zfs", "destroy", snapshot)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Delete the snapshots left by earlier interrupted attempts of the transfer.
+	if resumeTarget != "" {
+		err = d.deleteResumeSnapshots(d.dataset(vol, false), resumeTarget, "")
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
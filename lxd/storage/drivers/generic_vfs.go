@@ -276,14 +276,17 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 	revert := revert.New()
 	defer revert.Fail()
 
-	// Create the main volume if not refreshing.
-	if !volTargetArgs.Refresh {
+	// Create the main volume if not refreshing or resuming the transfer into the partially received volume.
+	if !volTargetArgs.Refresh && !(volTargetArgs.Resume && d.HasVolume(vol)) {
 		err := d.CreateVolume(vol, preFiller, op)
 		if err != nil {
 			return err
 		}
 
-		revert.Add(func() { d.DeleteVolume(vol, op) })
+		// Partially received data is kept for resumable transfers.
+		if !volTargetArgs.Resume {
+			revert.Add(func() { d.DeleteVolume(vol, op) })
+		}
 	}
 
 	recvFSVol := func(volName string, conn io.ReadWriteCloser, path string) error {
@@ -370,9 +373,11 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 			}
 
 			// Setup the revert.
-			revert.Add(func() {
-				d.DeleteVolumeSnapshot(snapVol, op)
-			})
+			if !volTargetArgs.Resume {
+				revert.Add(func() {
+					d.DeleteVolumeSnapshot(snapVol, op)
+				})
+			}
 		}
 
 		// Run volume-specific init logic.
@@ -434,6 +439,55 @@ func genericVFSHasVolume(vol Volume) bool {
 	return false
 }
 
+// genericVFSListVolumes is a generic ListVolumes implementation for VFS-only drivers.
+// It returns the instance and custom volumes found in the pool's volume type directories. Volumes that have a
+// disk file inside their mount path are considered block volumes and get their size from it.
+func genericVFSListVolumes(d Driver) ([]Volume, error) {
+	var vols []Volume
+	poolName := d.Name()
+
+	for _, volType := range d.Info().VolumeTypes {
+		if volType == VolumeTypeImage {
+			continue // Image volumes are not recovered.
+		}
+
+		if len(BaseDirectories[volType]) < 1 {
+			return nil, fmt.Errorf("Cannot get base directory name for volume type %q", volType)
+		}
+
+		volTypePath := filepath.Join(GetPoolMountPath(poolName), BaseDirectories[volType][0])
+		ents, err := ioutil.ReadDir(volTypePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, errors.Wrapf(err, "Failed to list directory %q for volume type %q", volTypePath, volType)
+		}
+
+		for _, ent := range ents {
+			if !ent.IsDir() || strings.HasSuffix(ent.Name(), tmpVolSuffix) {
+				continue
+			}
+
+			contentType := ContentTypeFS
+			volConfig := make(map[string]string)
+
+			fi, err := os.Stat(filepath.Join(volTypePath, ent.Name(), genericVolumeDiskFile))
+			if err == nil {
+				contentType = ContentTypeBlock
+				volConfig["size"] = fmt.Sprintf("%d", fi.Size())
+			} else if volType == VolumeTypeVM {
+				contentType = ContentTypeBlock
+			}
+
+			vols = append(vols, NewVolume(d, poolName, volType, contentType, ent.Name(), volConfig, d.Config()))
+		}
+	}
+
+	return vols, nil
+}
+
 // genericVFSGetVolumeDiskPath is a generic GetVolumeDiskPath implementation for VFS-only drivers.
 func genericVFSGetVolumeDiskPath(vol Volume) (string, error) {
 	if vol.contentType != ContentTypeBlock {
@@ -58,6 +58,7 @@ type Driver interface {
 	GetVolumeUsage(vol Volume) (int64, error)
 	SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error
 	GetVolumeDiskPath(vol Volume) (string, error)
+	ListVolumes() ([]Volume, error)
 
 	// MountVolume mounts a storage volume (if not mounted) and increments reference counter.
 	MountVolume(vol Volume, op *operations.Operation) error
@@ -82,6 +83,7 @@ type Driver interface {
 
 	// Migration.
 	MigrationTypes(contentType ContentType, refresh bool) []migration.Type
+	MigrationResumeToken(vol Volume) (string, error)
 	MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, op *operations.Operation) error
 	CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, volTargetArgs migration.VolumeTargetArgs, preFiller *VolumeFiller, op *operations.Operation) error
 
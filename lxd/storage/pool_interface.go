@@ -41,6 +41,9 @@ type Pool interface {
 
 	ApplyPatch(name string) error
 
+	// ListUnknownVolumes returns the volumes on the storage pool that don't have DB records, keyed by project.
+	ListUnknownVolumes(op *operations.Operation) (map[string][]*backup.Config, error)
+
 	// Instances.
 	FillInstanceConfig(inst instance.Instance, config map[string]string) error
 	CreateInstance(inst instance.Instance, op *operations.Operation) error
@@ -53,8 +56,10 @@ type Pool interface {
 	UpdateInstance(inst instance.Instance, newDesc string, newConfig map[string]string, op *operations.Operation) error
 	UpdateInstanceBackupFile(inst instance.Instance, op *operations.Operation) error
 	CheckInstanceBackupFileSnapshots(backupConf *backup.Config, projectName string, deleteMissing bool, op *operations.Operation) ([]*api.InstanceSnapshot, error)
+	ImportInstance(inst instance.Instance, op *operations.Operation) error
 
 	MigrateInstance(inst instance.Instance, conn io.ReadWriteCloser, args *migration.VolumeSourceArgs, op *operations.Operation) error
+	InstanceMigrationResumeState(inst instance.Instance, op *operations.Operation) (*migration.ResumeState, error)
 	RefreshInstance(inst instance.Instance, src instance.Instance, srcSnapshots []instance.Instance, op *operations.Operation) error
 	BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error
 
@@ -100,8 +105,12 @@ type Pool interface {
 	MigrationTypes(contentType drivers.ContentType, refresh bool) []migration.Type
 	CreateCustomVolumeFromMigration(projectName string, conn io.ReadWriteCloser, args migration.VolumeTargetArgs, op *operations.Operation) error
 	MigrateCustomVolume(projectName string, conn io.ReadWriteCloser, args *migration.VolumeSourceArgs, op *operations.Operation) error
+	CustomVolumeMigrationResumeState(projectName string, volName string, op *operations.Operation) (*migration.ResumeState, error)
 
 	// Custom volume backups.
 	BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error
 	CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error
+
+	// Custom volume recovery.
+	ImportCustomVolume(projectName string, poolVol backup.Config, op *operations.Operation) error
 }
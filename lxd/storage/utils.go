@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -364,9 +365,20 @@ func validatePoolCommonRules() map[string]func(string) error {
 		"size":                    validate.Optional(validate.IsSize),
 		"rsync.bwlimit":           validate.IsAny,
 		"rsync.compression":       validate.Optional(validate.IsBool),
+		"usage.warning_threshold": validate.Optional(validatePercentage),
 	}
 }
 
+// validatePercentage validates whether the value is an integer percentage between 0 and 100.
+func validatePercentage(value string) error {
+	percentage, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || percentage > 100 {
+		return fmt.Errorf("Invalid percentage %q", value)
+	}
+
+	return nil
+}
+
 // validateVolumeCommonRules returns a map of volume config rules common to all drivers.
 func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error {
 	rules := map[string]func(string) error{
@@ -405,6 +417,11 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["security.unmapped"] = validate.Optional(validate.IsBool)
 	}
 
+	// volatile.migration.resume marks custom volumes being received by a resumable transfer.
+	if vol.Type() == drivers.VolumeTypeCustom {
+		rules["volatile.migration.resume"] = validate.Optional(validate.IsBool)
+	}
+
 	// volatile.rootfs.size is only used for image volumes.
 	if vol.Type() == drivers.VolumeTypeImage {
 		rules["volatile.rootfs.size"] = validate.Optional(validate.IsInt64)
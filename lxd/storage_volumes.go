@@ -519,13 +519,17 @@ func storagePoolVolumesTypePost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Check if destination volume exists.
-	_, _, err = d.cluster.GetLocalStoragePoolVolume(projectName, req.Name, db.StoragePoolVolumeTypeCustom, poolID)
+	_, vol, err := d.cluster.GetLocalStoragePoolVolume(projectName, req.Name, db.StoragePoolVolumeTypeCustom, poolID)
 	if err != db.ErrNoSuchObject {
 		if err != nil {
 			return response.SmartError(err)
 		}
 
-		return response.Conflict(fmt.Errorf("Volume by that name already exists"))
+		// A volume left behind by an interrupted transfer can be reused to resume it.
+		resuming := req.Source.Type == "migration" && req.Source.Resume && shared.IsTrue(vol.Config["volatile.migration.resume"])
+		if !resuming {
+			return response.Conflict(fmt.Errorf("Volume by that name already exists"))
+		}
 	}
 
 	err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
@@ -684,13 +688,17 @@ func storagePoolVolumesPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Check if destination volume exists.
-	_, _, err = d.cluster.GetLocalStoragePoolVolume(projectName, req.Name, db.StoragePoolVolumeTypeCustom, poolID)
+	_, vol, err := d.cluster.GetLocalStoragePoolVolume(projectName, req.Name, db.StoragePoolVolumeTypeCustom, poolID)
 	if err != db.ErrNoSuchObject {
 		if err != nil {
 			return response.SmartError(err)
 		}
 
-		return response.Conflict(fmt.Errorf("Volume by that name already exists"))
+		// A volume left behind by an interrupted transfer can be reused to resume it.
+		resuming := req.Source.Type == "migration" && req.Source.Resume && shared.IsTrue(vol.Config["volatile.migration.resume"])
+		if !resuming {
+			return response.Conflict(fmt.Errorf("Volume by that name already exists"))
+		}
 	}
 
 	switch req.Source.Type {
@@ -747,6 +755,16 @@ func doVolumeMigration(d *Daemon, r *http.Request, requestProjectName string, pr
 		Secrets:    req.Source.Websockets,
 		Push:       push,
 		VolumeOnly: req.Source.VolumeOnly,
+		Resume:     req.Source.Resume,
+	}
+
+	// Mark the volume as a transfer which can be resumed if it gets interrupted.
+	if req.Source.Resume {
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		req.Config["volatile.migration.resume"] = "true"
 	}
 
 	sink, err := newStorageMigrationSink(&migrationArgs)
@@ -765,6 +783,38 @@ func doVolumeMigration(d *Daemon, r *http.Request, requestProjectName string, pr
 			return fmt.Errorf("Error transferring storage volume: %s", err)
 		}
 
+		// The transfer is complete, clear the resume marker from the volume and its snapshots.
+		if req.Source.Resume {
+			poolID, err := d.cluster.GetStoragePoolID(poolName)
+			if err != nil {
+				return err
+			}
+
+			snapshots, err := storagePools.VolumeSnapshotsGet(d.State(), projectName, poolName, req.Name, db.StoragePoolVolumeTypeCustom)
+			if err != nil {
+				return err
+			}
+
+			volNames := []string{req.Name}
+			for _, snapshot := range snapshots {
+				volNames = append(volNames, snapshot.Name)
+			}
+
+			for _, volName := range volNames {
+				_, vol, err := d.cluster.GetLocalStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, poolID)
+				if err != nil {
+					return err
+				}
+
+				delete(vol.Config, "volatile.migration.resume")
+
+				err = d.cluster.UpdateStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, poolID, vol.Description, vol.Config)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
 		return nil
 	}
 
@@ -15,6 +15,7 @@ import (
 
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/project"
@@ -22,6 +23,7 @@ import (
 	storagePools "github.com/lxc/lxd/lxd/storage"
 	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/lxd/warnings"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	log "github.com/lxc/lxd/shared/log15"
@@ -1207,6 +1209,16 @@ func autoCreateCustomVolumeSnapshots(ctx context.Context, d *Daemon, volumes []d
 			err = pool.CreateCustomVolumeSnapshot(v.ProjectName, v.Name, snapshotName, expiry, nil)
 			if err != nil {
 				logger.Error("Error creating volume snapshot", log.Ctx{"err": err, "volume": v})
+
+				err = d.cluster.UpsertWarningLocalNode(v.ProjectName, dbCluster.TypeStorageVolume, int(v.ID), db.WarningSnapshotScheduleFailure, err.Error())
+				if err != nil {
+					logger.Warn("Failed to create warning", log.Ctx{"err": err})
+				}
+			} else {
+				err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(d.cluster, v.ProjectName, db.WarningSnapshotScheduleFailure, dbCluster.TypeStorageVolume, int(v.ID))
+				if err != nil {
+					logger.Warn("Failed to resolve warning", log.Ctx{"err": err})
+				}
 			}
 
 			ch <- struct{}{}
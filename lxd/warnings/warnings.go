@@ -121,7 +121,7 @@ func ResolveWarningsByNodeAndProjectAndTypeAndEntity(cluster *db.Cluster, nodeNa
 		}
 
 		for _, w := range warnings {
-			if w.Node != nodeName || w.Project != projectName || w.EntityTypeCode != entityTypeCode || entityID != entityID {
+			if w.Node != nodeName || w.Project != projectName || w.EntityTypeCode != entityTypeCode || w.EntityID != entityID {
 				continue
 			}
 
@@ -172,7 +172,7 @@ func DeleteWarningsByNodeAndProjectAndEntity(cluster *db.Cluster, nodeName strin
 		}
 
 		for _, w := range warnings {
-			if w.Node != nodeName || w.Project != projectName || w.EntityTypeCode != entityTypeCode || entityID != entityID {
+			if w.Node != nodeName || w.Project != projectName || w.EntityTypeCode != entityTypeCode || w.EntityID != entityID {
 				continue
 			}
 
@@ -223,7 +223,7 @@ func DeleteWarningsByNodeAndProjectAndTypeAndEntity(cluster *db.Cluster, nodeNam
 		}
 
 		for _, w := range warnings {
-			if w.Node != nodeName || w.Project != projectName || w.EntityTypeCode != entityTypeCode || entityID != entityID {
+			if w.Node != nodeName || w.Project != projectName || w.EntityTypeCode != entityTypeCode || w.EntityID != entityID {
 				continue
 			}
 
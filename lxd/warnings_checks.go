@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
+	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/network/openvswitch"
+	"github.com/lxc/lxd/lxd/node"
+	"github.com/lxc/lxd/lxd/state"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/lxd/task"
+	"github.com/lxc/lxd/lxd/warnings"
+	"github.com/lxc/lxd/shared/api"
+	log "github.com/lxc/lxd/shared/log15"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/units"
+)
+
+// Settings used by the periodic warning checks.
+const (
+	warningPoolUsageThresholdDefault = 90 // Percentage of the pool in use.
+	warningCertificateExpiryDays     = 30 // Days before expiry at which certificates are reported.
+	warningOVNNorthboundTimeout      = 10 // Seconds to wait for the OVN northbound database.
+)
+
+// thinpoolMetadataUsageDriver is implemented by storage drivers which can report thin pool metadata usage.
+type thinpoolMetadataUsageDriver interface {
+	ThinpoolMetadataUsage() (float64, error)
+}
+
+// warningsCheckTask periodically looks for conditions on the local member that should be reported as warnings
+// (storage pool usage, LVM thin pool metadata usage, certificate expiry, OVN northbound connectivity and conflicting
+// network address allocations) and resolves those warnings once the condition has cleared.
+func warningsCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		warningsCheckStoragePools(s)
+		warningsCheckCertificates(d)
+		warningsCheckOVN(s)
+		warningsCheckNetworkAllocations(d)
+	}
+
+	return f, task.Every(5 * time.Minute)
+}
+
+// warningSet raises the warning on the local member if msg is not empty, otherwise resolves it.
+func warningSet(s *state.State, projectName string, entityTypeCode int, entityID int, typeCode db.WarningType, msg string) {
+	var err error
+
+	if msg != "" {
+		err = s.Cluster.UpsertWarningLocalNode(projectName, entityTypeCode, entityID, typeCode, msg)
+	} else {
+		err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.Cluster, projectName, typeCode, entityTypeCode, entityID)
+	}
+
+	if err != nil {
+		logger.Warn("Failed to update warning", log.Ctx{"type": db.WarningTypeNames[typeCode], "err": err})
+	}
+}
+
+// warningsCheckStoragePools checks the usage of the storage pools created on the local member against their
+// usage.warning_threshold setting.
+func warningsCheckStoragePools(s *state.State) {
+	poolNames, err := s.Cluster.GetCreatedStoragePoolNames()
+	if err != nil {
+		if err != db.ErrNoSuchObject {
+			logger.Error("Failed loading storage pools for usage check", log.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	for _, poolName := range poolNames {
+		pool, err := storagePools.GetPoolByName(s, poolName)
+		if err != nil {
+			logger.Error("Failed loading storage pool for usage check", log.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		if pool.LocalStatus() != api.StoragePoolStatusCreated {
+			continue
+		}
+
+		threshold := float64(warningPoolUsageThresholdDefault)
+		if pool.Driver().Config()["usage.warning_threshold"] != "" {
+			threshold, err = strconv.ParseFloat(pool.Driver().Config()["usage.warning_threshold"], 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		// A threshold of 0 disables the checks.
+		if threshold <= 0 {
+			warningSet(s, "", dbCluster.TypeStoragePool, int(pool.ID()), db.WarningStoragePoolLowSpace, "")
+			warningSet(s, "", dbCluster.TypeStoragePool, int(pool.ID()), db.WarningStoragePoolThinpoolMetadataLow, "")
+			continue
+		}
+
+		res, err := pool.GetResources()
+		if err != nil {
+			logger.Debug("Failed getting storage pool usage", log.Ctx{"pool": poolName, "err": err})
+		} else if res.Space.Total > 0 {
+			msg := ""
+			usage := float64(res.Space.Used) * 100 / float64(res.Space.Total)
+			if usage >= threshold {
+				msg = fmt.Sprintf("Storage pool is %.1f%% full (%s of %s used)", usage, units.GetByteSizeString(int64(res.Space.Used), 2), units.GetByteSizeString(int64(res.Space.Total), 2))
+			}
+
+			warningSet(s, "", dbCluster.TypeStoragePool, int(pool.ID()), db.WarningStoragePoolLowSpace, msg)
+		}
+
+		metaDriver, ok := pool.Driver().(thinpoolMetadataUsageDriver)
+		if !ok {
+			continue
+		}
+
+		metaUsage, err := metaDriver.ThinpoolMetadataUsage()
+		if err != nil {
+			logger.Debug("Failed getting thin pool metadata usage", log.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		msg := ""
+		if metaUsage >= threshold {
+			msg = fmt.Sprintf("Thin pool metadata is %.1f%% full", metaUsage)
+		}
+
+		warningSet(s, "", dbCluster.TypeStoragePool, int(pool.ID()), db.WarningStoragePoolThinpoolMetadataLow, msg)
+	}
+}
+
+// warningsCertificateExpiry returns a warning message if the certificate expires within the warning period.
+func warningsCertificateExpiry(cert *x509.Certificate) string {
+	if time.Now().After(cert.NotAfter) {
+		return fmt.Sprintf("Certificate expired on %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	if time.Now().Add(warningCertificateExpiryDays * 24 * time.Hour).After(cert.NotAfter) {
+		return fmt.Sprintf("Certificate expires on %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return ""
+}
+
+// warningsIsLeader returns true if the local member is the cluster leader or if the server isn't clustered.
+// When it isn't, the warnings of the given types previously raised by the local member are resolved, so that
+// cluster wide warnings are only reported once, by the current leader.
+func warningsIsLeader(d *Daemon, typeCodes ...db.WarningType) bool {
+	leader, err := d.gateway.LeaderAddress()
+	if err != nil {
+		if errors.Cause(err) == cluster.ErrNodeIsNotClustered {
+			return true
+		}
+
+		return false
+	}
+
+	localAddress, err := node.ClusterAddress(d.db)
+	if err != nil {
+		return false
+	}
+
+	if localAddress == leader {
+		return true
+	}
+
+	for _, typeCode := range typeCodes {
+		err = warnings.ResolveWarningsByLocalNodeAndType(d.cluster, typeCode)
+		if err != nil {
+			logger.Warn("Failed to resolve warnings", log.Ctx{"type": db.WarningTypeNames[typeCode], "err": err})
+		}
+	}
+
+	return false
+}
+
+// warningsCheckCertificates checks the expiry date of the server certificate and of the trusted certificates.
+// As both are shared by all cluster members, the check is only run by the cluster leader.
+func warningsCheckCertificates(d *Daemon) {
+	s := d.State()
+
+	if !warningsIsLeader(d, db.WarningServerCertificateExpiry, db.WarningCertificateExpiry) {
+		return
+	}
+
+	keypair := d.endpoints.NetworkCert().KeyPair()
+	if len(keypair.Certificate) > 0 {
+		serverCert, err := x509.ParseCertificate(keypair.Certificate[0])
+		if err != nil {
+			logger.Error("Failed parsing server certificate for expiry check", log.Ctx{"err": err})
+		} else {
+			warningSet(s, "", -1, -1, db.WarningServerCertificateExpiry, warningsCertificateExpiry(serverCert))
+		}
+	}
+
+	var dbCerts []db.Certificate
+	err := s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		var err error
+
+		dbCerts, err = tx.GetCertificates(db.CertificateFilter{})
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed loading trusted certificates for expiry check", log.Ctx{"err": err})
+		return
+	}
+
+	for _, dbCert := range dbCerts {
+		certBlock, _ := pem.Decode([]byte(dbCert.Certificate))
+		if certBlock == nil {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			continue
+		}
+
+		warningSet(s, "", dbCluster.TypeCertificate, dbCert.ID, db.WarningCertificateExpiry, warningsCertificateExpiry(cert))
+	}
+}
+
+// warningsCheckOVN checks that the OVN northbound database is reachable if any OVN network exists.
+func warningsCheckOVN(s *state.State) {
+	var ovnNetworks bool
+	err := s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		projectNetworks, err := tx.GetCreatedNetworks()
+		if err != nil {
+			return err
+		}
+
+		for _, networks := range projectNetworks {
+			for _, network := range networks {
+				if network.Type == "ovn" {
+					ovnNetworks = true
+					return nil
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed loading networks for OVN connectivity check", log.Ctx{"err": err})
+		return
+	}
+
+	msg := ""
+	if ovnNetworks {
+		client, err := openvswitch.NewOVN(s)
+		if err != nil {
+			logger.Error("Failed getting OVN client for connectivity check", log.Ctx{"err": err})
+			return
+		}
+
+		err = client.NorthboundPing(warningOVNNorthboundTimeout)
+		if err != nil {
+			msg = err.Error()
+		}
+	}
+
+	warningSet(s, "", -1, -1, db.WarningOVNNorthboundUnreachable, msg)
+}
+
+// warningsCheckNetworkAllocations checks for addresses statically allocated to more than one entity on the same
+// network. As allocations are cluster wide, the check is only run by the cluster leader.
+func warningsCheckNetworkAllocations(d *Daemon) {
+	s := d.State()
+
+	if !warningsIsLeader(d, db.WarningNetworkAddressConflict) {
+		return
+	}
+
+	allocations, err := network.Allocations(s, "")
+	if err != nil {
+		logger.Error("Failed loading network allocations for conflict check", log.Ctx{"err": err})
+		return
+	}
+
+	warningSet(s, "", -1, -1, db.WarningNetworkAddressConflict, strings.Join(network.AllocationConflicts(allocations), "; "))
+}
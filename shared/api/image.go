@@ -62,7 +62,7 @@ type ImagesPostSource struct {
 	// Example: pull
 	Mode string `json:"mode" yaml:"mode"`
 
-	// Type of image source (instance, snapshot, image or url)
+	// Type of image source (instance, snapshot, image, url or build)
 	// Example: instance
 	Type string `json:"type" yaml:"type"`
 
@@ -81,6 +81,11 @@ type ImagesPostSource struct {
 	// Source image server secret token (when downloading private images)
 	// Example: RANDOM-STRING
 	Secret string `json:"secret" yaml:"secret"`
+
+	// Build recipe (for type "build")
+	//
+	// API extension: image_builds
+	Build *ImageBuild `json:"build" yaml:"build"`
 }
 
 // ImagePut represents the modifiable fields of a LXD image
@@ -110,6 +115,12 @@ type ImagePut struct {
 	//
 	// API extension: image_profiles
 	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Detached signature of the image fingerprint (Ed25519 or armored GPG)
+	// Example: ed25519:lSgjJ1Dl1qzg0JfqzOp6ZY4ss7Afd7eX0ExJzqSxMJ9ntA6QQSCfzKfhDFD0jM2dy2Vg0y+FkUStuExHIa9YBg==
+	//
+	// API extension: image_signatures
+	Signature string `json:"signature" yaml:"signature"`
 }
 
 // Image represents a LXD image
@@ -0,0 +1,96 @@
+package api
+
+// ImageBuild represents an image build recipe, executed in a temporary instance created from the base image
+//
+// swagger:model
+//
+// API extension: image_builds
+type ImageBuild struct {
+	// Instance configuration used during the build
+	// Example: {"security.nesting": "true"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Instance devices used during the build
+	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}}
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+
+	// Profiles applied to the build instance
+	// Example: ["default"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Files to push into the instance
+	Files []ImageBuildFile `json:"files" yaml:"files"`
+
+	// Packages to install with the distribution package manager
+	// Example: ["nginx", "curl"]
+	Packages []string `json:"packages" yaml:"packages"`
+
+	// Commands to run (through /bin/sh) after the files are pushed and the packages installed
+	// Example: ["systemctl enable nginx"]
+	Commands []string `json:"commands" yaml:"commands"`
+
+	// Templates to add to the image metadata
+	Templates []ImageBuildTemplate `json:"templates" yaml:"templates"`
+
+	// Commands to run (through /bin/sh) at the end of the build
+	// Example: ["apt-get clean", "rm -rf /var/lib/apt/lists/*"]
+	Cleanup []string `json:"cleanup" yaml:"cleanup"`
+
+	// Maximum duration of the build, in seconds (0 for the default of one hour)
+	// Example: 1800
+	Timeout int64 `json:"timeout" yaml:"timeout"`
+}
+
+// ImageBuildFile represents a file pushed into the instance during an image build
+//
+// swagger:model
+//
+// API extension: image_builds
+type ImageBuildFile struct {
+	// Path of the file inside the instance
+	// Example: /etc/nginx/sites-available/default
+	Path string `json:"path" yaml:"path"`
+
+	// Content of the file
+	// Example: server { listen 80; }
+	Content string `json:"content" yaml:"content"`
+
+	// File owner UID
+	// Example: 0
+	UID int64 `json:"uid" yaml:"uid"`
+
+	// File owner GID
+	// Example: 0
+	GID int64 `json:"gid" yaml:"gid"`
+
+	// File mode (0644 if not set)
+	// Example: 420
+	Mode int `json:"mode" yaml:"mode"`
+}
+
+// ImageBuildTemplate represents a template added to the image metadata during an image build
+//
+// swagger:model
+//
+// API extension: image_builds
+type ImageBuildTemplate struct {
+	// Path of the file the template is rendered to
+	// Example: /etc/hostname
+	Path string `json:"path" yaml:"path"`
+
+	// Template content (pongo2)
+	// Example: {{ container.name }}
+	Content string `json:"content" yaml:"content"`
+
+	// When to render the template (create, copy or start)
+	// Example: ["create", "copy"]
+	When []string `json:"when" yaml:"when"`
+
+	// Whether to only render the template if the file is missing
+	// Example: false
+	CreateOnly bool `json:"create_only" yaml:"create_only"`
+
+	// Key/value properties to pass to the template
+	// Example: {"foo": "bar"}
+	Properties map[string]string `json:"properties" yaml:"properties"`
+}
@@ -302,6 +302,12 @@ type InstanceSource struct {
 	// Example: false
 	Refresh bool `json:"refresh,omitempty" yaml:"refresh,omitempty"`
 
+	// Whether to resume an interrupted transfer of the instance (for migration)
+	// Example: false
+	//
+	// API extension: migration_resume
+	Resume bool `json:"resume,omitempty" yaml:"resume,omitempty"`
+
 	// Source project name (for copy and local image)
 	// Example: blah
 	Project string `json:"project,omitempty" yaml:"project,omitempty"`
@@ -53,4 +53,16 @@ type InstanceExecPost struct {
 	// Current working directory for the command
 	// Example: /home/foo/
 	Cwd string `json:"cwd" yaml:"cwd"`
+
+	// Name of the user to spawn the command as, resolved inside the instance (overrides user)
+	// Example: foo
+	//
+	// API extension: exec_username
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+
+	// Whether to spawn the command (or the user's shell if no command is given) as a login shell
+	// Example: true
+	//
+	// API extension: exec_username
+	Login bool `json:"login,omitempty" yaml:"login,omitempty"`
 }
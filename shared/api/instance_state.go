@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // InstanceStatePut represents the modifiable fields of a LXD instance's state.
 //
 // swagger:model
@@ -56,6 +58,16 @@ type InstanceState struct {
 
 	// CPU usage information
 	CPU InstanceStateCPU `json:"cpu" yaml:"cpu"`
+
+	// Health check information (only set when healthcheck.command is configured)
+	//
+	// API extension: instance_restart_policy
+	Health *InstanceStateHealth `json:"health,omitempty" yaml:"health,omitempty"`
+
+	// Pressure stall information (only set when supported by the host)
+	//
+	// API extension: instance_state_io_pressure
+	Pressure *InstanceStatePressure `json:"pressure,omitempty" yaml:"pressure,omitempty"`
 }
 
 // InstanceStateDisk represents the disk information section of a LXD instance's state.
@@ -67,6 +79,109 @@ type InstanceStateDisk struct {
 	// Disk usage in bytes
 	// Example: 502239232
 	Usage int64 `json:"usage" yaml:"usage"`
+
+	// Number of bytes read
+	// Example: 13312000
+	//
+	// API extension: instance_state_io_pressure
+	ReadBytes int64 `json:"read_bytes,omitempty" yaml:"read_bytes,omitempty"`
+
+	// Number of read operations
+	// Example: 1040
+	//
+	// API extension: instance_state_io_pressure
+	ReadOps int64 `json:"read_ops,omitempty" yaml:"read_ops,omitempty"`
+
+	// Number of bytes written
+	// Example: 4096000
+	//
+	// API extension: instance_state_io_pressure
+	WriteBytes int64 `json:"write_bytes,omitempty" yaml:"write_bytes,omitempty"`
+
+	// Number of write operations
+	// Example: 320
+	//
+	// API extension: instance_state_io_pressure
+	WriteOps int64 `json:"write_ops,omitempty" yaml:"write_ops,omitempty"`
+}
+
+// InstanceStatePressure represents the pressure stall information section of a LXD instance's state.
+//
+// swagger:model
+//
+// API extension: instance_state_io_pressure
+type InstanceStatePressure struct {
+	// CPU pressure
+	CPU *InstanceStatePressureResource `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+
+	// Memory pressure
+	Memory *InstanceStatePressureResource `json:"memory,omitempty" yaml:"memory,omitempty"`
+
+	// I/O pressure
+	IO *InstanceStatePressureResource `json:"io,omitempty" yaml:"io,omitempty"`
+}
+
+// InstanceStatePressureResource represents the pressure stall information of a single resource.
+//
+// swagger:model
+//
+// API extension: instance_state_io_pressure
+type InstanceStatePressureResource struct {
+	// Stalls affecting some of the tasks
+	Some InstanceStatePressureStats `json:"some" yaml:"some"`
+
+	// Stalls affecting all of the tasks
+	Full InstanceStatePressureStats `json:"full" yaml:"full"`
+}
+
+// InstanceStatePressureStats represents a set of pressure stall averages and total.
+//
+// swagger:model
+//
+// API extension: instance_state_io_pressure
+type InstanceStatePressureStats struct {
+	// Percentage of time stalled over the last 10 seconds
+	// Example: 0.52
+	Avg10 float64 `json:"avg10" yaml:"avg10"`
+
+	// Percentage of time stalled over the last 60 seconds
+	// Example: 0.21
+	Avg60 float64 `json:"avg60" yaml:"avg60"`
+
+	// Percentage of time stalled over the last 300 seconds
+	// Example: 0.05
+	Avg300 float64 `json:"avg300" yaml:"avg300"`
+
+	// Total time stalled in microseconds
+	// Example: 1265393
+	Total int64 `json:"total" yaml:"total"`
+}
+
+// InstanceStateHealth represents the health check section of a LXD instance's state.
+//
+// swagger:model
+//
+// API extension: instance_restart_policy
+type InstanceStateHealth struct {
+	// Health status (starting, healthy or unhealthy)
+	// Example: healthy
+	Status string `json:"status" yaml:"status"`
+
+	// Number of consecutive failed health checks
+	// Example: 0
+	FailingStreak int `json:"failing_streak" yaml:"failing_streak"`
+
+	// When the last health check was run
+	// Example: 2021-03-23T20:00:00-04:00
+	LastCheckAt time.Time `json:"last_check_at" yaml:"last_check_at"`
+
+	// Exit code of the last health check
+	// Example: 0
+	LastExitCode int `json:"last_exit_code" yaml:"last_exit_code"`
+
+	// Output of the last health check (truncated)
+	// Example: OK
+	LastOutput string `json:"last_output" yaml:"last_output"`
 }
 
 // InstanceStateCPU represents the cpu information section of a LXD instance's state.
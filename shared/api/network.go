@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // NetworksPost represents the fields of a new LXD network
 //
 // swagger:model
@@ -174,6 +176,16 @@ type NetworkState struct {
 	//
 	// API extension: network_state_vlan
 	VLAN *NetworkStateVLAN `json:"vlan" yaml:"vlan"`
+
+	// Additional WireGuard interface information
+	//
+	// API extension: network_wireguard
+	Wireguard *NetworkStateWireguard `json:"wireguard" yaml:"wireguard"`
+
+	// Additional IPv6 prefix delegation information
+	//
+	// API extension: network_ipv6_pd
+	IPv6PD *NetworkStateIPv6PD `json:"ipv6_pd" yaml:"ipv6_pd"`
 }
 
 // NetworkStateAddress represents a network address
@@ -298,3 +310,75 @@ type NetworkStateVLAN struct {
 	// Example: 100
 	VID uint64 `json:"vid" yaml:"vid"`
 }
+
+// NetworkStateWireguard represents WireGuard specific state
+//
+// swagger:model
+//
+// API extension: network_wireguard
+type NetworkStateWireguard struct {
+	// Public key of the interface
+	// Example: 4Vu0JnRzP9DFJw1mVcRh3vG4RLn4mXsLxB/m6SImzBU=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// UDP port the interface listens on
+	// Example: 51820
+	ListenPort int64 `json:"listen_port" yaml:"listen_port"`
+
+	// List of peers
+	Peers []NetworkStateWireguardPeer `json:"peers" yaml:"peers"`
+}
+
+// NetworkStateWireguardPeer represents the state of a WireGuard peer
+//
+// swagger:model
+//
+// API extension: network_wireguard
+type NetworkStateWireguardPeer struct {
+	// Name of the peer (cluster member name or external peer name)
+	// Example: dc2
+	Name string `json:"name" yaml:"name"`
+
+	// Public key of the peer
+	// Example: hlr0BbCwZ7lGa1wVH/oY0MSKzQqbDlVzKl9x0pRGVgU=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// Current endpoint of the peer
+	// Example: 198.51.100.10:51820
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// List of addresses and subnets routed to the peer
+	// Example: ["10.20.0.0/24"]
+	AllowedIPs []string `json:"allowed_ips" yaml:"allowed_ips"`
+
+	// Time of the latest handshake (zero if none)
+	// Example: 2021-06-01T10:00:00Z
+	LatestHandshake time.Time `json:"latest_handshake" yaml:"latest_handshake"`
+
+	// Number of bytes received from the peer
+	// Example: 250542118
+	BytesReceived int64 `json:"bytes_received" yaml:"bytes_received"`
+
+	// Number of bytes sent to the peer
+	// Example: 17524040140
+	BytesSent int64 `json:"bytes_sent" yaml:"bytes_sent"`
+}
+
+// NetworkStateIPv6PD represents the IPv6 prefix delegation state of a network
+//
+// swagger:model
+//
+// API extension: network_ipv6_pd
+type NetworkStateIPv6PD struct {
+	// Interface the prefix is delegated on
+	// Example: eth0
+	Interface string `json:"interface" yaml:"interface"`
+
+	// Currently delegated prefix (empty if none delegated yet)
+	// Example: 2001:db8:1200::/56
+	Prefix string `json:"prefix" yaml:"prefix"`
+
+	// Network address and subnet derived from the delegated prefix
+	// Example: 2001:db8:1200::1/64
+	Subnet string `json:"subnet" yaml:"subnet"`
+}
@@ -0,0 +1,61 @@
+package api
+
+// NetworkAddressSetPost used for renaming an address set.
+//
+// swagger:model
+//
+// API extension: network_address_set
+type NetworkAddressSetPost struct {
+	// The new name for the address set
+	// Example: bar
+	Name string `json:"name" yaml:"name"` // Name of address set.
+}
+
+// NetworkAddressSetPut used for updating an address set.
+//
+// swagger:model
+//
+// API extension: network_address_set
+type NetworkAddressSetPut struct {
+	// Description of the address set
+	// Example: Office and VPN networks
+	Description string `json:"description" yaml:"description"`
+
+	// List of IP addresses and CIDR subnets in the address set
+	// Example: ["192.0.2.0/24", "2001:db8::/32"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// Address set configuration map (refer to doc/network-address-sets.md)
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// NetworkAddressSet used for displaying an address set.
+//
+// swagger:model
+//
+// API extension: network_address_set
+type NetworkAddressSet struct {
+	NetworkAddressSetPost `yaml:",inline"`
+	NetworkAddressSetPut  `yaml:",inline"`
+
+	// List of URLs of objects using this address set
+	// Read only: true
+	// Example: ["/1.0/network-acls/web"]
+	UsedBy []string `json:"used_by" yaml:"used_by"` // ACLs that use the address set.
+}
+
+// Writable converts a full NetworkAddressSet struct into a NetworkAddressSetPut struct (filters read-only fields).
+func (as *NetworkAddressSet) Writable() NetworkAddressSetPut {
+	return as.NetworkAddressSetPut
+}
+
+// NetworkAddressSetsPost used for creating an address set.
+//
+// swagger:model
+//
+// API extension: network_address_set
+type NetworkAddressSetsPost struct {
+	NetworkAddressSetPost `yaml:",inline"`
+	NetworkAddressSetPut  `yaml:",inline"`
+}
@@ -0,0 +1,40 @@
+package api
+
+// NetworkAllocation represents an IP address allocated to an entity on a network
+//
+// swagger:model
+//
+// API extension: network_allocations
+type NetworkAllocation struct {
+	// The allocated address (CIDR notation for network subnets)
+	// Example: 10.0.0.98
+	Address string `json:"address" yaml:"address"`
+
+	// URL of the entity the address is allocated to
+	// Example: /1.0/instances/c1
+	UsedBy string `json:"used_by" yaml:"used_by"`
+
+	// Type of the entity the address is allocated to (instance, network, nat, load-balancer, uplink or reservation)
+	// Example: instance
+	Type string `json:"type" yaml:"type"`
+
+	// Name of the network the address is allocated on (empty if not on a managed network)
+	// Example: lxdbr0
+	Network string `json:"network" yaml:"network"`
+
+	// Project of the entity the address is allocated to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Whether the address is NATed by the network
+	// Example: true
+	NAT bool `json:"nat" yaml:"nat"`
+
+	// The MAC address of the instance NIC or DHCP reservation (empty for other types)
+	// Example: 00:16:3e:2c:89:d9
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+
+	// Whether the address is also statically allocated to another entity
+	// Example: false
+	Conflict bool `json:"conflict" yaml:"conflict"`
+}
@@ -0,0 +1,106 @@
+package api
+
+// NetworkLoadBalancerBackend represents a target backend of a load balancer.
+// Refer to doc/network-load-balancers.md for details.
+//
+// swagger:model
+//
+// API extension: network_load_balancer
+type NetworkLoadBalancerBackend struct {
+	// Name of the backend
+	// Example: c1-http
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the backend
+	// Example: C1 webserver
+	Description string `json:"description" yaml:"description"`
+
+	// Target address of the backend (an instance NIC address on the network)
+	// Example: 10.0.0.2
+	TargetAddress string `json:"target_address" yaml:"target_address"`
+
+	// Target port(s) of the backend (e.g. 70,80-90 or 90), defaults to the listen port(s)
+	// Example: 80
+	TargetPort string `json:"target_port" yaml:"target_port"`
+}
+
+// NetworkLoadBalancerPort represents a port specification of a load balancer.
+// Refer to doc/network-load-balancers.md for details.
+//
+// swagger:model
+//
+// API extension: network_load_balancer
+type NetworkLoadBalancerPort struct {
+	// Description of the port
+	// Example: Webserver
+	Description string `json:"description" yaml:"description"`
+
+	// Protocol for the port (tcp or udp)
+	// Example: tcp
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// Listen port(s) (e.g. 80,81-82 or 80)
+	// Example: 80
+	ListenPort string `json:"listen_port" yaml:"listen_port"`
+
+	// Names of the backends the port forwards to
+	// Example: ["c1-http","c2-http"]
+	TargetBackend []string `json:"target_backend" yaml:"target_backend"`
+}
+
+// NetworkLoadBalancersPost represents the fields of a new LXD network load balancer.
+//
+// swagger:model
+//
+// API extension: network_load_balancer
+type NetworkLoadBalancersPost struct {
+	NetworkLoadBalancerPut `yaml:",inline"`
+
+	// The listen address of the load balancer (allocated from the uplink network when empty)
+	// Example: 192.0.2.1
+	ListenAddress string `json:"listen_address" yaml:"listen_address"`
+}
+
+// NetworkLoadBalancerPut represents the modifiable fields of a LXD network load balancer.
+//
+// swagger:model
+//
+// API extension: network_load_balancer
+type NetworkLoadBalancerPut struct {
+	// Description of the load balancer
+	// Example: My public IP load balancer
+	Description string `json:"description" yaml:"description"`
+
+	// Load balancer configuration map (refer to doc/network-load-balancers.md)
+	// Example: {"healthcheck": "true"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Backends (optional)
+	Backends []NetworkLoadBalancerBackend `json:"backends" yaml:"backends"`
+
+	// Port forwards (optional)
+	Ports []NetworkLoadBalancerPort `json:"ports" yaml:"ports"`
+}
+
+// NetworkLoadBalancer used for displaying a network load balancer.
+//
+// swagger:model
+//
+// API extension: network_load_balancer
+type NetworkLoadBalancer struct {
+	NetworkLoadBalancerPut `yaml:",inline"`
+
+	// The listen address of the load balancer
+	// Example: 192.0.2.1
+	ListenAddress string `json:"listen_address" yaml:"listen_address"`
+}
+
+// Etag returns the values used for etag generation.
+func (lb *NetworkLoadBalancer) Etag() []interface{} {
+	return []interface{}{lb.ListenAddress, lb.Description, lb.Config, lb.Backends, lb.Ports}
+}
+
+// Writable converts a full NetworkLoadBalancer struct into a NetworkLoadBalancerPut struct (filters read-only fields).
+func (lb *NetworkLoadBalancer) Writable() NetworkLoadBalancerPut {
+	return lb.NetworkLoadBalancerPut
+}
@@ -0,0 +1,61 @@
+package api
+
+// NetworkReservationsPost represents the fields of a new LXD network DHCP reservation.
+// Refer to doc/network-reservations.md for details.
+//
+// swagger:model
+//
+// API extension: network_reservations
+type NetworkReservationsPost struct {
+	NetworkReservationPut `yaml:",inline"`
+
+	// The MAC address or DHCPv6 DUID (prefixed with "id:") the reservation applies to
+	// Example: 00:16:3e:2c:89:d9
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+}
+
+// NetworkReservationPut represents the modifiable fields of a LXD network DHCP reservation.
+//
+// swagger:model
+//
+// API extension: network_reservations
+type NetworkReservationPut struct {
+	// Description of the reservation
+	// Example: Office printer
+	Description string `json:"description" yaml:"description"`
+
+	// Reserved IPv4 address
+	// Example: 10.0.0.50
+	IPv4Address string `json:"ipv4_address" yaml:"ipv4_address"`
+
+	// Reserved IPv6 address
+	// Example: fd42:4242:4242:1010::50
+	IPv6Address string `json:"ipv6_address" yaml:"ipv6_address"`
+
+	// Hostname handed out with the lease (optional)
+	// Example: printer
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// NetworkReservation used for displaying a network DHCP reservation.
+//
+// swagger:model
+//
+// API extension: network_reservations
+type NetworkReservation struct {
+	NetworkReservationPut `yaml:",inline"`
+
+	// The MAC address or DHCPv6 DUID (prefixed with "id:") the reservation applies to
+	// Example: 00:16:3e:2c:89:d9
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+}
+
+// Etag returns the values used for etag generation.
+func (r *NetworkReservation) Etag() []interface{} {
+	return []interface{}{r.Hwaddr, r.Description, r.IPv4Address, r.IPv6Address, r.Hostname}
+}
+
+// Writable converts a full NetworkReservation struct into a NetworkReservationPut struct (filters read-only fields).
+func (r *NetworkReservation) Writable() NetworkReservationPut {
+	return r.NetworkReservationPut
+}
@@ -190,4 +190,10 @@ type StorageVolumeSource struct {
 	//
 	// API extension: storage_api_project
 	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+
+	// Whether to resume an interrupted transfer of the volume (for migration)
+	// Example: false
+	//
+	// API extension: migration_resume
+	Resume bool `json:"resume,omitempty" yaml:"resume,omitempty"`
 }
@@ -82,6 +82,16 @@ var KnownInstanceConfigKeys = map[string]func(value string) error{
 	"boot.autostart.priority":    validate.Optional(validate.IsInt64),
 	"boot.stop.priority":         validate.Optional(validate.IsInt64),
 	"boot.host_shutdown_timeout": validate.Optional(validate.IsInt64),
+	"boot.restart_policy": validate.Optional(func(value string) error {
+		return validate.IsOneOf(value, []string{"never", "on-failure", "always"})
+	}),
+	"boot.restart_delay":        validate.Optional(validate.IsUint32),
+	"boot.restart_max_attempts": validate.Optional(validate.IsUint32),
+
+	"healthcheck.command":  validate.IsAny,
+	"healthcheck.interval": validate.Optional(validate.IsUint32),
+	"healthcheck.timeout":  validate.Optional(validate.IsUint32),
+	"healthcheck.retries":  validate.Optional(validate.IsUint32),
 
 	"limits.cpu": func(value string) error {
 		if value == "" {
@@ -192,10 +202,13 @@ var KnownInstanceConfigKeys = map[string]func(value string) error{
 	"nvidia.require.cuda":        validate.IsAny,
 	"nvidia.require.driver":      validate.IsAny,
 
-	"security.nesting":       validate.Optional(validate.IsBool),
-	"security.privileged":    validate.Optional(validate.IsBool),
-	"security.devlxd":        validate.Optional(validate.IsBool),
-	"security.devlxd.images": validate.Optional(validate.IsBool),
+	"security.nesting":            validate.Optional(validate.IsBool),
+	"security.privileged":         validate.Optional(validate.IsBool),
+	"security.devlxd":             validate.Optional(validate.IsBool),
+	"security.devlxd.config":      validate.Optional(validate.IsBool),
+	"security.devlxd.images":      validate.Optional(validate.IsBool),
+	"security.exec.record":        validate.Optional(validate.IsBool),
+	"security.exec.record.expiry": validate.Optional(validate.IsUint32),
 
 	"security.protection.delete": validate.Optional(validate.IsBool),
 	"security.protection.shift":  validate.Optional(validate.IsBool),
@@ -247,6 +260,8 @@ var KnownInstanceConfigKeys = map[string]func(value string) error{
 	"volatile.idmap.current":    validate.IsAny,
 	"volatile.idmap.next":       validate.IsAny,
 	"volatile.apply_quota":      validate.IsAny,
+	"volatile.migration.resume": validate.Optional(validate.IsBool),
+	"volatile.restart.attempts": validate.Optional(validate.IsUint32),
 	"volatile.uuid":             validate.Optional(validate.IsUUID),
 }
 
@@ -0,0 +1,194 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	pgpErrors "golang.org/x/crypto/openpgp/errors"
+)
+
+// ed25519Prefix is the prefix of Ed25519 signatures, followed by the base64 encoded signature.
+const ed25519Prefix = "ed25519:"
+
+// pgpPublicKeyBlock is the first line of an armored GPG public key block.
+const pgpPublicKeyBlock = "-----BEGIN " + openpgp.PublicKeyType + "-----"
+
+// pgpPrivateKeyBlock is the first line of an armored GPG private key block.
+const pgpPrivateKeyBlock = "-----BEGIN " + openpgp.PrivateKeyType + "-----"
+
+// ErrUntrusted is returned when a valid signature wasn't made by any of the keys in the keyring.
+var ErrUntrusted = fmt.Errorf("Signature wasn't made by a trusted key")
+
+// Keyring is a set of trusted public keys.
+type Keyring struct {
+	ed25519 []ed25519.PublicKey
+	pgp     openpgp.EntityList
+}
+
+// ParseKeyring parses a list of PEM encoded Ed25519 public keys and armored GPG public key blocks.
+func ParseKeyring(keys string) (*Keyring, error) {
+	keyring := &Keyring{}
+
+	rest := []byte(keys)
+	for len(bytes.TrimSpace(rest)) > 0 {
+		var block []byte
+
+		block, rest = nextBlock(rest)
+		if block == nil {
+			return nil, fmt.Errorf("Unexpected data outside of a key block")
+		}
+
+		if bytes.HasPrefix(block, []byte(pgpPublicKeyBlock)) {
+			entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(block))
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed parsing GPG public key")
+			}
+
+			keyring.pgp = append(keyring.pgp, entities...)
+			continue
+		}
+
+		pemBlock, _ := pem.Decode(block)
+		if pemBlock == nil || pemBlock.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("Unsupported key block, expected an Ed25519 public key or a GPG public key")
+		}
+
+		key, err := x509.ParsePKIXPublicKey(pemBlock.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed parsing public key")
+		}
+
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("Unsupported public key type %T, only Ed25519 keys are supported", key)
+		}
+
+		keyring.ed25519 = append(keyring.ed25519, edKey)
+	}
+
+	return keyring, nil
+}
+
+// nextBlock returns the first "-----BEGIN" to "-----END" block in data and what follows it.
+// A nil block is returned if data doesn't start with a block (ignoring whitespaces).
+func nextBlock(data []byte) ([]byte, []byte) {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if !bytes.HasPrefix(data, []byte("-----BEGIN ")) {
+		return nil, data
+	}
+
+	end := bytes.Index(data, []byte("-----END "))
+	if end < 0 {
+		return nil, data
+	}
+
+	// Include the whole end line.
+	lineEnd := bytes.IndexByte(data[end:], '\n')
+	if lineEnd < 0 {
+		return data, nil
+	}
+
+	return data[:end+lineEnd+1], data[end+lineEnd+1:]
+}
+
+// Verify checks that signature is a valid detached signature of message made by one of the keys in the keyring.
+// The signature is either an Ed25519 signature ("ed25519:<base64>") or an armored GPG signature.
+func (k *Keyring) Verify(message []byte, signature string) error {
+	if strings.HasPrefix(signature, ed25519Prefix) {
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(signature, ed25519Prefix))
+		if err != nil {
+			return errors.Wrap(err, "Invalid Ed25519 signature")
+		}
+
+		for _, key := range k.ed25519 {
+			if ed25519.Verify(key, message, sig) {
+				return nil
+			}
+		}
+
+		return ErrUntrusted
+	}
+
+	_, err := openpgp.CheckArmoredDetachedSignature(k.pgp, bytes.NewReader(message), strings.NewReader(signature))
+	if err != nil {
+		if err == pgpErrors.ErrUnknownIssuer {
+			return ErrUntrusted
+		}
+
+		return errors.Wrap(err, "Invalid GPG signature")
+	}
+
+	return nil
+}
+
+// VerifyClearsigned checks that data is a GPG clearsigned message signed by one of the keys in the keyring
+// and returns the message it contains.
+func (k *Keyring) VerifyClearsigned(data []byte) ([]byte, error) {
+	block, _ := clearsign.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("No clearsigned message found")
+	}
+
+	_, err := openpgp.CheckDetachedSignature(k.pgp, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body)
+	if err != nil {
+		if err == pgpErrors.ErrUnknownIssuer {
+			return nil, ErrUntrusted
+		}
+
+		return nil, errors.Wrap(err, "Invalid GPG signature")
+	}
+
+	return block.Plaintext, nil
+}
+
+// Sign returns a detached signature of message made with privateKey, which is either a PEM encoded
+// Ed25519 private key (PKCS #8) or an armored GPG private key.
+func Sign(privateKey []byte, message []byte) (string, error) {
+	if bytes.Contains(privateKey, []byte(pgpPrivateKeyBlock)) {
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(privateKey))
+		if err != nil {
+			return "", errors.Wrap(err, "Failed parsing GPG private key")
+		}
+
+		if len(entities) == 0 || entities[0].PrivateKey == nil {
+			return "", fmt.Errorf("No GPG private key found")
+		}
+
+		if entities[0].PrivateKey.Encrypted {
+			return "", fmt.Errorf("Passphrase protected GPG private keys aren't supported")
+		}
+
+		buf := bytes.Buffer{}
+		err = openpgp.ArmoredDetachSign(&buf, entities[0], bytes.NewReader(message), nil)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed signing with GPG key")
+		}
+
+		return buf.String(), nil
+	}
+
+	block, _ := pem.Decode(privateKey)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return "", fmt.Errorf("Unsupported private key, expected a PEM encoded Ed25519 key or a GPG key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed parsing private key")
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("Unsupported private key type %T, only Ed25519 keys are supported", key)
+	}
+
+	return ed25519Prefix + base64.StdEncoding.EncodeToString(ed25519.Sign(edKey, message)), nil
+}
@@ -0,0 +1,131 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+type signingTestSuite struct {
+	suite.Suite
+}
+
+func TestSigningTestSuite(t *testing.T) {
+	suite.Run(t, new(signingTestSuite))
+}
+
+// ed25519Keys returns a new PEM encoded Ed25519 key pair.
+func (s *signingTestSuite) ed25519Keys() ([]byte, []byte) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	s.Require().NoError(err)
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	s.Require().NoError(err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+}
+
+// pgpKeys returns a new GPG entity along with its armored public and private keys.
+func (s *signingTestSuite) pgpKeys() (*openpgp.Entity, []byte, []byte) {
+	entity, err := openpgp.NewEntity("LXD", "test", "lxd@example.com", nil)
+	s.Require().NoError(err)
+
+	pub := bytes.Buffer{}
+	w, err := armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(entity.Serialize(w))
+	s.Require().NoError(w.Close())
+
+	priv := bytes.Buffer{}
+	w, err = armor.Encode(&priv, openpgp.PrivateKeyType, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(entity.SerializePrivate(w, nil))
+	s.Require().NoError(w.Close())
+
+	return entity, pub.Bytes(), priv.Bytes()
+}
+
+func (s *signingTestSuite) TestEd25519() {
+	pub, priv := s.ed25519Keys()
+	otherPub, _ := s.ed25519Keys()
+	message := []byte("06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb")
+
+	signature, err := Sign(priv, message)
+	s.Require().NoError(err)
+
+	keyring, err := ParseKeyring(string(otherPub) + "\n" + string(pub))
+	s.Require().NoError(err)
+	s.NoError(keyring.Verify(message, signature))
+	s.Error(keyring.Verify([]byte("tampered"), signature))
+
+	keyring, err = ParseKeyring(string(otherPub))
+	s.Require().NoError(err)
+	s.Equal(ErrUntrusted, keyring.Verify(message, signature))
+}
+
+func (s *signingTestSuite) TestPGP() {
+	_, pub, priv := s.pgpKeys()
+	_, otherPub, _ := s.pgpKeys()
+	message := []byte("06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb")
+
+	signature, err := Sign(priv, message)
+	s.Require().NoError(err)
+
+	keyring, err := ParseKeyring(string(pub))
+	s.Require().NoError(err)
+	s.NoError(keyring.Verify(message, signature))
+	s.Error(keyring.Verify([]byte("tampered"), signature))
+
+	keyring, err = ParseKeyring(string(otherPub))
+	s.Require().NoError(err)
+	s.Equal(ErrUntrusted, keyring.Verify(message, signature))
+}
+
+func (s *signingTestSuite) TestVerifyClearsigned() {
+	entity, pub, _ := s.pgpKeys()
+	message := []byte(`{"format": "index:1.0"}`)
+
+	signed := bytes.Buffer{}
+	w, err := clearsign.Encode(&signed, entity.PrivateKey, nil)
+	s.Require().NoError(err)
+	_, err = w.Write(message)
+	s.Require().NoError(err)
+	s.Require().NoError(w.Close())
+
+	keyring, err := ParseKeyring(string(pub))
+	s.Require().NoError(err)
+
+	content, err := keyring.VerifyClearsigned(signed.Bytes())
+	s.Require().NoError(err)
+	s.Equal(message, bytes.TrimSpace(content))
+
+	_, err = keyring.VerifyClearsigned(bytes.Replace(signed.Bytes(), []byte("index:1.0"), []byte("index:2.0"), 1))
+	s.Error(err)
+
+	_, err = keyring.VerifyClearsigned(message)
+	s.Error(err)
+}
+
+func (s *signingTestSuite) TestParseKeyringInvalid() {
+	_, err := ParseKeyring("not a key")
+	s.Error(err)
+
+	_, priv := s.ed25519Keys()
+	_, err = ParseKeyring(string(priv))
+	s.Error(err)
+
+	keyring, err := ParseKeyring("")
+	s.NoError(err)
+	s.Equal(ErrUntrusted, keyring.Verify([]byte("message"), "ed25519:AAAA"))
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/osarch"
+	"github.com/lxc/lxd/shared/signing"
 )
 
 var urlDefaultOS = map[string]string{
@@ -53,6 +54,8 @@ type SimpleStreams struct {
 
 	cachePath   string
 	cacheExpiry time.Duration
+
+	keyring *signing.Keyring
 }
 
 // SetCache configures the on-disk cache
@@ -61,6 +64,12 @@ func (s *SimpleStreams) SetCache(path string, expiry time.Duration) {
 	s.cacheExpiry = expiry
 }
 
+// SetKeyring configures the keyring used to verify the signed index and products (.sjson).
+// Once set, unsigned or untrusted streams are rejected.
+func (s *SimpleStreams) SetKeyring(keyring *signing.Keyring) {
+	s.keyring = keyring
+}
+
 func (s *SimpleStreams) readCache(path string) ([]byte, bool) {
 	cacheName := filepath.Join(s.cachePath, path)
 
@@ -149,13 +158,38 @@ func (s *SimpleStreams) cachedDownload(path string) ([]byte, error) {
 	return body, nil
 }
 
+// download returns the content of the stream file at path. When a keyring is configured, the signed
+// version of the file (.sjson) is retrieved instead and its signature verified.
+func (s *SimpleStreams) download(path string) ([]byte, error) {
+	if s.keyring == nil {
+		return s.cachedDownload(path)
+	}
+
+	signedPath := path
+	if strings.HasSuffix(path, ".json") {
+		signedPath = strings.TrimSuffix(path, ".json") + ".sjson"
+	}
+
+	body, err := s.cachedDownload(signedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.keyring.VerifyClearsigned(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed verifying signature of %q", signedPath)
+	}
+
+	return content, nil
+}
+
 func (s *SimpleStreams) parseStream() (*Stream, error) {
 	if s.cachedStream != nil {
 		return s.cachedStream, nil
 	}
 
 	path := "streams/v1/index.json"
-	body, err := s.cachedDownload(path)
+	body, err := s.download(path)
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +211,7 @@ func (s *SimpleStreams) parseProducts(path string) (*Products, error) {
 		return s.cachedProducts[path], nil
 	}
 
-	body, err := s.cachedDownload(path)
+	body, err := s.download(path)
 	if err != nil {
 		return nil, err
 	}
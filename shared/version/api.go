@@ -276,6 +276,26 @@ var APIExtensions = []string{
 	"storage_api_project",
 	"server_instance_driver_operational",
 	"server_supported_storage_drivers",
+	"instance_restart_policy",
+	"storage_usage_warning",
+	"server_acme",
+	"instance_state_io_pressure",
+	"network_bgp",
+	"network_load_balancer",
+	"network_acl_nic_firewall",
+	"network_address_set",
+	"proxy_kernel",
+	"network_wireguard",
+	"network_ipv6_pd",
+	"network_allocations",
+	"network_reservations",
+	"image_signatures",
+	"image_builds",
+	"migration_resume",
+	"console_vm_log",
+	"instance_wait",
+	"exec_username",
+	"exec_recording",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
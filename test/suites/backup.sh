@@ -175,6 +175,52 @@ test_container_import() {
   kill_lxd "${LXD_IMPORT_DIR}"
 }
 
+test_container_recover() {
+  LXD_IMPORT_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_IMPORT_DIR}"
+  spawn_lxd "${LXD_IMPORT_DIR}" true
+  (
+    set -e
+
+    # shellcheck disable=SC2030
+    LXD_DIR=${LXD_IMPORT_DIR}
+    poolName=$(lxc profile device get default root pool)
+
+    ensure_import_testimage
+
+    lxc init testimage ctRecover
+    lxc config set ctRecover snapshots.expiry 1d
+    lxc snapshot ctRecover
+    lxc storage volume create "${poolName}" volRecover
+    lxc storage volume snapshot "${poolName}" volRecover
+
+    # Nothing to recover while the database is intact.
+    printf 'no\nyes\n' | lxd recover | grep "No unknown volumes found. Nothing to do."
+
+    # Remove the database records and check the volumes are reported as unknown.
+    lxd sql global "PRAGMA foreign_keys=ON; DELETE FROM instances WHERE name='ctRecover'"
+    lxd sql global "PRAGMA foreign_keys=ON; DELETE FROM storage_volumes WHERE name LIKE 'ctRecover%'"
+    lxd sql global "PRAGMA foreign_keys=ON; DELETE FROM storage_volumes WHERE name LIKE 'volRecover%'"
+    ! lxc info ctRecover || false
+
+    printf 'no\nyes\nno\n' | lxd recover | grep 'Container "ctRecover" on pool "'"${poolName}"'" in project "default" (includes 1 snapshots)'
+    printf 'no\nyes\nno\n' | lxd recover | grep 'Custom "volRecover" on pool "'"${poolName}"'" in project "default" (includes 1 snapshots)'
+
+    # Recover the volumes.
+    printf 'no\nyes\nyes\n' | lxd recover
+    lxc info ctRecover | grep snap0
+    ! lxc config show ctRecover/snap0 | grep -q 'expires_at: 0001-01-01T00:00:00Z' || false
+    lxc storage volume show "${poolName}" volRecover
+    lxc storage volume show "${poolName}" volRecover/snap0
+    lxc start ctRecover
+    lxc delete --force ctRecover
+    lxc storage volume delete "${poolName}" volRecover
+  )
+  # shellcheck disable=SC2031
+  LXD_DIR=${LXD_DIR}
+  kill_lxd "${LXD_IMPORT_DIR}"
+}
+
 test_backup_import() {
   test_backup_import_with_project
   test_backup_import_with_project fooproject
@@ -33,6 +33,17 @@ test_network() {
   lxc network set lxdt$$ ipv6.address auto
   lxc network show lxdt$$ | grep ipv6.address
 
+  # check BGP peer and next-hop keys.
+  lxc network set lxdt$$ bgp.peers.foo.address 192.0.2.1
+  lxc network set lxdt$$ bgp.peers.foo.asn 65001
+  lxc network set lxdt$$ bgp.ipv4.nexthop 192.0.2.10
+  ! lxc network set lxdt$$ bgp.peers.foo.asn foo || false
+  ! lxc network set lxdt$$ bgp.peers.foo.bar baz || false
+  ! lxc network set lxdt$$ bgp.ipv4.nexthop 2001:db8::1 || false
+  lxc network unset lxdt$$ bgp.peers.foo.address
+  lxc network unset lxdt$$ bgp.peers.foo.asn
+  lxc network unset lxdt$$ bgp.ipv4.nexthop
+
   # delete the network
   lxc network delete lxdt$$
 
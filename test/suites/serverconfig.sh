@@ -6,6 +6,7 @@ test_server_config() {
   test_server_config_password
   test_server_config_access
   test_server_config_storage
+  test_server_config_bgp
 
   kill_lxd "${LXD_SERVERCONFIG_DIR}"
 }
@@ -107,3 +108,21 @@ test_server_config_storage() {
   lxc storage volume delete "${pool}" images
   lxc delete -f foo
 }
+
+test_server_config_bgp() {
+  # Invalid values are rejected.
+  ! lxc config set core.bgp_address 127.0.0.1 || false
+  ! lxc config set core.bgp_routerid foo || false
+  ! lxc config set core.bgp_asn -1 || false
+
+  # Start and stop the BGP server.
+  port=$(local_tcp_port)
+  lxc config set core.bgp_routerid 127.0.0.1
+  lxc config set core.bgp_address "127.0.0.1:${port}"
+  lxc config set core.bgp_asn 65000
+  [ "$(lxc config get core.bgp_asn)" = "65000" ]
+
+  lxc config unset core.bgp_asn
+  lxc config unset core.bgp_address
+  lxc config unset core.bgp_routerid
+}
@@ -28,6 +28,12 @@ test_storage() {
   lxc storage set "$storage_pool" user.abc def
   [ "$(lxc storage get "$storage_pool" user.abc)" = "def" ]
 
+  # Validate the usage warning threshold
+  lxc storage set "$storage_pool" usage.warning_threshold 80
+  [ "$(lxc storage get "$storage_pool" usage.warning_threshold)" = "80" ]
+  ! lxc storage set "$storage_pool" usage.warning_threshold 101 || false
+  lxc storage unset "$storage_pool" usage.warning_threshold
+
   lxc storage volume set "$storage_pool" "$storage_volume" user.abc def
   [ "$(lxc storage volume get "$storage_pool" "$storage_volume" user.abc)" = "def" ]
 